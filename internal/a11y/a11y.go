@@ -0,0 +1,96 @@
+// Package a11y makes Crush usable with terminal screen readers: it strips
+// the box drawing from the screen, and announces state changes as plain
+// text lines in a log a screen reader can follow, like with tail -f.
+package a11y
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+var enabled atomic.Bool
+
+// SetEnabled turns the screen reader mode on or off.
+func SetEnabled(v bool) {
+	enabled.Store(v)
+}
+
+// Enabled reports whether the screen reader mode is on.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Linearize replaces box drawing and block characters in view with spaces,
+// so screen readers don't read borders and separators aloud. The layout is
+// kept as is.
+func Linearize(view string) string {
+	if !strings.ContainsFunc(view, decorative) {
+		return view
+	}
+	return strings.Map(func(r rune) rune {
+		if decorative(r) {
+			return ' '
+		}
+		return r
+	}, view)
+}
+
+// decorative reports whether r is a box drawing or block element character.
+func decorative(r rune) bool {
+	return r >= 0x2500 && r <= 0x259f
+}
+
+// Log is where state changes are announced.
+type Log struct {
+	mu   sync.Mutex
+	f    *os.File
+	last string
+	now  func() time.Time
+}
+
+// Open opens the announcement log at path, appending to it.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{f: f, now: time.Now}, nil
+}
+
+// Announce writes text to the log as a single plain line, prefixed with
+// the time. Styling is stripped, and an announcement repeating the one
+// before it is skipped. A nil log announces nothing.
+func (l *Log) Announce(text string) {
+	if l == nil {
+		return
+	}
+	text = strings.Join(strings.Fields(ansi.Strip(text)), " ")
+	if text == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if text == l.last {
+		return
+	}
+	l.last = text
+	fmt.Fprintf(l.f, "%s %s\n", l.now().Format(time.TimeOnly), text)
+}
+
+// Close closes the log.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.f.Close()
+}
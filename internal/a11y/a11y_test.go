@@ -0,0 +1,57 @@
+package a11y
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinearize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		view string
+		want string
+	}{
+		{"plain", "Hello", "Hello"},
+		{"rounded border", "╭──╮\n│hi│\n╰──╯", "    \n hi \n    "},
+		{"block", "▌ You", "  You"},
+		{"styled", "\x1b[31m│\x1b[0mhi", "\x1b[31m \x1b[0mhi"},
+		{"other symbols", "✓ done …", "✓ done …"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, Linearize(tt.view))
+		})
+	}
+}
+
+func TestAnnounce(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "accessibility.log")
+	l, err := Open(path)
+	require.NoError(t, err)
+	l.now = func() time.Time {
+		return time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	}
+
+	l.Announce("\x1b[1mPermission required\x1b[0m:\n  bash")
+	l.Announce("Permission required: bash")
+	l.Announce("   ")
+	l.Announce("Crush finished")
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "15:04:05 Permission required: bash\n15:04:05 Crush finished\n", string(data))
+
+	var nilLog *Log
+	nilLog.Announce("ignored")
+	require.NoError(t, nilLog.Close())
+}
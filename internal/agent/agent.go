@@ -35,6 +35,8 @@ import (
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/redact"
 	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/stringext"
 )
@@ -45,6 +47,15 @@ var titlePrompt []byte
 //go:embed templates/summary.md
 var summaryPrompt []byte
 
+//go:embed templates/commit.md
+var commitPrompt []byte
+
+//go:embed templates/pull_request.md
+var pullRequestPrompt []byte
+
+//go:embed templates/plan_mode.md
+var planModePrompt []byte
+
 type SessionAgentCall struct {
 	SessionID        string
 	Prompt           string
@@ -56,8 +67,26 @@ type SessionAgentCall struct {
 	TopK             *int64
 	FrequencyPenalty *float64
 	PresencePenalty  *float64
+	// MaxRetries is how many times failed requests are retried; the
+	// default of fantasy when nil.
+	MaxRetries *int
+	// PlanMode asks the agent to propose a plan for review before making
+	// any changes.
+	PlanMode bool
+	// SystemPrompt replaces the system prompt of the agent when set.
+	SystemPrompt string
+	// DroppedTurns are the turns of the history left out, by the ID of the
+	// message starting them.
+	DroppedTurns []string
 }
 
+// todoReminder is the message reminding the agent of the todos tool.
+var todoReminder = fmt.Sprintf("<system_reminder>%s</system_reminder>",
+	`This is a reminder that your todo list is currently empty. DO NOT mention this to the user explicitly because they are already aware.
+If you are working on tasks that would benefit from a todo list please use the "todos" tool to create one.
+If not, please feel free to ignore. Again do not mention this message to the user.`,
+)
+
 type SessionAgent interface {
 	Run(context.Context, SessionAgentCall) (*fantasy.AgentResult, error)
 	SetModels(large Model, small Model)
@@ -70,6 +99,9 @@ type SessionAgent interface {
 	QueuedPromptsList(sessionID string) []string
 	ClearQueue(sessionID string)
 	Summarize(context.Context, string, fantasy.ProviderOptions) error
+	Preview(ctx context.Context, call SessionAgentCall) ([]Section, error)
+	GenerateCommitMessage(ctx context.Context, diff, instructions string) (string, error)
+	GeneratePullRequest(ctx context.Context, changes string) (string, error)
 	Model() Model
 }
 
@@ -90,9 +122,12 @@ type sessionAgent struct {
 	messages             message.Service
 	disableAutoSummarize bool
 	isYolo               bool
+	redactor             *redact.Redactor
 
 	messageQueue   *csync.Map[string, []SessionAgentCall]
 	activeRequests *csync.Map[string, context.CancelFunc]
+	// redacted holds the secrets already reported as masked, by session.
+	redacted *csync.Map[string, struct{}]
 }
 
 type SessionAgentOptions struct {
@@ -106,6 +141,8 @@ type SessionAgentOptions struct {
 	Sessions             session.Service
 	Messages             message.Service
 	Tools                []fantasy.AgentTool
+	// Redactor masks the secrets of what is sent; nil masks nothing.
+	Redactor *redact.Redactor
 }
 
 func NewSessionAgent(
@@ -122,8 +159,10 @@ func NewSessionAgent(
 		disableAutoSummarize: opts.DisableAutoSummarize,
 		tools:                opts.Tools,
 		isYolo:               opts.IsYolo,
+		redactor:             opts.Redactor,
 		messageQueue:         csync.NewMap[string, []SessionAgentCall](),
 		activeRequests:       csync.NewMap[string, context.CancelFunc](),
+		redacted:             csync.NewMap[string, struct{}](),
 	}
 }
 
@@ -153,7 +192,7 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 
 	agent := fantasy.NewAgent(
 		a.largeModel.Model,
-		fantasy.WithSystemPrompt(a.systemPrompt),
+		fantasy.WithSystemPrompt(cmp.Or(call.SystemPrompt, a.systemPrompt)),
 		fantasy.WithTools(a.tools...),
 	)
 
@@ -168,15 +207,22 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 		return nil, fmt.Errorf("failed to get session messages: %w", err)
 	}
 
-	var wg sync.WaitGroup
-	// Generate title if first message.
+	// The session is named after its first exchange, once the agent
+	// answered or failed to.
+	var currentAssistant *message.Message
 	if len(msgs) == 0 {
 		titleCtx := ctx // Copy to avoid race with ctx reassignment below.
-		wg.Go(func() {
-			a.generateTitle(titleCtx, call.SessionID, call.Prompt)
-		})
+		defer func() {
+			var response string
+			if currentAssistant != nil {
+				response = currentAssistant.Content().Text
+			}
+			a.generateTitle(titleCtx, call.SessionID, call.Prompt, response)
+		}()
 	}
 
+	msgs = dropTurns(msgs, call.DroppedTurns)
+
 	// Add the user message to the session.
 	_, err = a.createUserMessage(ctx, call)
 	if err != nil {
@@ -197,8 +243,10 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 	startTime := time.Now()
 	a.eventPromptSent(call.SessionID)
 
-	var currentAssistant *message.Message
+	// stepStart is when the current step was sent, to time the response.
+	var stepStart time.Time
 	var shouldSummarize bool
+	var retries int
 	result, err := agent.Stream(genCtx, fantasy.AgentStreamCall{
 		Prompt:           message.PromptWithTextAttachments(call.Prompt, call.Attachments),
 		Files:            files,
@@ -210,6 +258,7 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 		PresencePenalty:  call.PresencePenalty,
 		TopK:             call.TopK,
 		FrequencyPenalty: call.FrequencyPenalty,
+		MaxRetries:       call.MaxRetries,
 		PrepareStep: func(callContext context.Context, options fantasy.PrepareStepFunctionOptions) (_ context.Context, prepared fantasy.PrepareStepResult, err error) {
 			prepared.Messages = options.Messages
 			for i := range prepared.Messages {
@@ -227,6 +276,7 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 			}
 
 			prepared.Messages = a.workaroundProviderMediaLimitations(prepared.Messages)
+			a.redactMessages(call.SessionID, prepared.Messages)
 
 			lastSystemRoleInx := 0
 			systemMessageUpdated := false
@@ -238,10 +288,14 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 					prepared.Messages[lastSystemRoleInx].ProviderOptions = a.getCacheControlOptions()
 					systemMessageUpdated = true
 				}
-				// Than add cache control to the last 2 messages.
-				if i > len(prepared.Messages)-3 {
-					prepared.Messages[i].ProviderOptions = a.getCacheControlOptions()
-				}
+			}
+			// Than add cache control to the conversation.
+			for _, i := range cacheBreakpoints(prepared.Messages) {
+				prepared.Messages[i].ProviderOptions = a.getCacheControlOptions()
+			}
+
+			if call.PlanMode {
+				prepared.Messages = append(prepared.Messages, fantasy.NewSystemMessage(string(planModePrompt)))
 			}
 
 			if promptPrefix := a.promptPrefix(); promptPrefix != "" {
@@ -262,6 +316,7 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 			callContext = context.WithValue(callContext, tools.SupportsImagesContextKey, a.largeModel.CatwalkCfg.SupportsImages)
 			callContext = context.WithValue(callContext, tools.ModelNameContextKey, a.largeModel.CatwalkCfg.Name)
 			currentAssistant = &assistantMsg
+			stepStart = time.Now()
 			return callContext, prepared, err
 		},
 		OnReasoningStart: func(id string, reasoning fantasy.ReasoningContent) error {
@@ -314,7 +369,15 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 			return a.messages.Update(genCtx, *currentAssistant)
 		},
 		OnRetry: func(err *fantasy.ProviderError, delay time.Duration) {
-			// TODO: implement
+			retries++
+			failoverBroker.Publish(pubsub.UpdatedEvent, FailoverEvent{
+				SessionID: call.SessionID,
+				Provider:  a.largeModel.ModelCfg.Provider,
+				Model:     a.largeModel.CatwalkCfg.Name,
+				Attempt:   retries,
+				Delay:     delay,
+				Reason:    retryReason(err),
+			})
 		},
 		OnToolCall: func(tc fantasy.ToolCallContent) error {
 			toolCall := message.ToolCall{
@@ -334,6 +397,7 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 				Parts: []message.ContentPart{
 					toolResult,
 				},
+				ParentMessageID: currentAssistant.ID,
 			})
 			return createMsgErr
 		},
@@ -348,6 +412,9 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 				finishReason = message.FinishReasonToolUse
 			}
 			currentAssistant.AddFinish(finishReason, "", "")
+			currentAssistant.PromptTokens = stepResult.Usage.InputTokens + stepResult.Usage.CacheCreationTokens + stepResult.Usage.CacheReadTokens
+			currentAssistant.CompletionTokens = stepResult.Usage.OutputTokens
+			currentAssistant.Latency = time.Since(stepStart)
 			sessionLock.Lock()
 			updatedSession, getSessionErr := a.sessions.Get(genCtx, call.SessionID)
 			if getSessionErr != nil {
@@ -443,6 +510,7 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 				Parts: []message.ContentPart{
 					toolResult,
 				},
+				ParentMessageID: currentAssistant.ID,
 			})
 			if createErr != nil {
 				return nil, createErr
@@ -484,7 +552,6 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 		}
 		return nil, err
 	}
-	wg.Wait()
 
 	if shouldSummarize {
 		a.activeRequests.Del(call.SessionID)
@@ -537,6 +604,14 @@ func (a *sessionAgent) Summarize(ctx context.Context, sessionID string, opts fan
 
 	aiMsgs, _ := a.preparePrompt(msgs)
 
+	// The pinned messages of the whole session are kept, including the ones
+	// an earlier summary already replaced.
+	allMsgs, err := a.messages.List(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list messages: %w", err)
+	}
+	pinned := pinnedText(allMsgs)
+
 	genCtx, cancel := context.WithCancel(ctx)
 	a.activeRequests.Set(sessionID, cancel)
 	defer a.activeRequests.Del(sessionID)
@@ -564,6 +639,9 @@ func (a *sessionAgent) Summarize(ctx context.Context, sessionID string, opts fan
 		summaryPromptText += "\nInclude these tasks and their statuses in your summary. "
 		summaryPromptText += "Instruct the resuming assistant to use the `todos` tool to continue tracking progress on these tasks."
 	}
+	if pinned != "" {
+		summaryPromptText += "\n\nThe messages the user pinned are added after your summary as they are, don't repeat them."
+	}
 
 	resp, err := agent.Stream(genCtx, fantasy.AgentStreamCall{
 		Prompt:          summaryPromptText,
@@ -571,6 +649,7 @@ func (a *sessionAgent) Summarize(ctx context.Context, sessionID string, opts fan
 		ProviderOptions: opts,
 		PrepareStep: func(callContext context.Context, options fantasy.PrepareStepFunctionOptions) (_ context.Context, prepared fantasy.PrepareStepResult, err error) {
 			prepared.Messages = options.Messages
+			a.redactMessages(sessionID, prepared.Messages)
 			if a.systemPromptPrefix != "" {
 				prepared.Messages = append([]fantasy.Message{fantasy.NewSystemMessage(a.systemPromptPrefix)}, prepared.Messages...)
 			}
@@ -605,6 +684,9 @@ func (a *sessionAgent) Summarize(ctx context.Context, sessionID string, opts fan
 		return err
 	}
 
+	if pinned != "" {
+		summaryMessage.AppendContent("\n\n" + pinned)
+	}
 	summaryMessage.AddFinish(message.FinishReasonEndTurn, "", "")
 	err = a.messages.Update(genCtx, summaryMessage)
 	if err != nil {
@@ -634,6 +716,32 @@ func (a *sessionAgent) Summarize(ctx context.Context, sessionID string, opts fan
 	return err
 }
 
+// pinnedText returns the pinned messages of msgs as they are, to add to a
+// summary, or an empty string when there are none.
+func pinnedText(msgs []message.Message) string {
+	var b strings.Builder
+	for _, msg := range msgs {
+		if !msg.Pinned || msg.IsSummaryMessage {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("## Pinned Messages\n\nThe user pinned these messages to keep them word for word.\n")
+		}
+		role := "User"
+		if msg.Role == message.Assistant {
+			role = "Assistant"
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", role)
+		if text := strings.TrimSpace(msg.Content().Text); text != "" {
+			b.WriteString(text + "\n")
+		}
+		for _, tc := range msg.ToolCalls() {
+			fmt.Fprintf(&b, "\nTool call `%s`:\n\n```json\n%s\n```\n", tc.Name, tc.Input)
+		}
+	}
+	return b.String()
+}
+
 func (a *sessionAgent) getCacheControlOptions() fantasy.ProviderOptions {
 	if t, _ := strconv.ParseBool(os.Getenv("CRUSH_DISABLE_ANTHROPIC_CACHE")); t {
 		return fantasy.ProviderOptions{}
@@ -648,6 +756,41 @@ func (a *sessionAgent) getCacheControlOptions() fantasy.ProviderOptions {
 	}
 }
 
+// cacheBreakpoints returns the messages to add cache control to, besides
+// the system prompt and the tools: the last one, so the next step reads the
+// whole conversation from the cache, and the latest one with attached
+// files, which are large and don't change, or else the one before the last.
+// Anthropic allows four of them at most.
+func cacheBreakpoints(msgs []fantasy.Message) []int {
+	last := len(msgs) - 1
+	if last < 0 {
+		return nil
+	}
+	prev := last - 1
+	for i := last - 1; i >= 0; i-- {
+		if hasAttachments(msgs[i]) {
+			prev = i
+			break
+		}
+	}
+	if prev < 0 {
+		return []int{last}
+	}
+	return []int{prev, last}
+}
+
+func hasAttachments(msg fantasy.Message) bool {
+	for _, part := range msg.Content {
+		if _, ok := fantasy.AsMessagePart[fantasy.FilePart](part); ok {
+			return true
+		}
+		if text, ok := fantasy.AsMessagePart[fantasy.TextPart](part); ok && strings.Contains(text.Text, message.AttachmentsNote) {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *sessionAgent) createUserMessage(ctx context.Context, call SessionAgentCall) (message.Message, error) {
 	parts := []message.ContentPart{message.TextContent{Text: call.Prompt}}
 	var attachmentParts []message.ContentPart
@@ -668,13 +811,7 @@ func (a *sessionAgent) createUserMessage(ctx context.Context, call SessionAgentC
 func (a *sessionAgent) preparePrompt(msgs []message.Message, attachments ...message.Attachment) ([]fantasy.Message, []fantasy.FilePart) {
 	var history []fantasy.Message
 	if !a.isSubAgent {
-		history = append(history, fantasy.NewUserMessage(
-			fmt.Sprintf("<system_reminder>%s</system_reminder>",
-				`This is a reminder that your todo list is currently empty. DO NOT mention this to the user explicitly because they are already aware.
-If you are working on tasks that would benefit from a todo list please use the "todos" tool to create one.
-If not, please feel free to ignore. Again do not mention this message to the user.`,
-			),
-		))
+		history = append(history, fantasy.NewUserMessage(todoReminder))
 	}
 	for _, m := range msgs {
 		if len(m.Parts) == 0 {
@@ -725,10 +862,21 @@ func (a *sessionAgent) getSessionMessages(ctx context.Context, session session.S
 	return msgs, nil
 }
 
-func (a *sessionAgent) generateTitle(ctx context.Context, sessionID string, prompt string) {
+// maxTitleResponse is how much of the first response is used to name the
+// session.
+const maxTitleResponse = 2000
+
+func (a *sessionAgent) generateTitle(ctx context.Context, sessionID, prompt, response string) {
 	if prompt == "" {
 		return
 	}
+	content := "<user>\n" + prompt + "\n</user>"
+	if response != "" {
+		if r := []rune(response); len(r) > maxTitleResponse {
+			response = string(r[:maxTitleResponse]) + "…"
+		}
+		content += "\n<assistant>\n" + response + "\n</assistant>"
+	}
 
 	var maxOutput int64 = 40
 	if a.smallModel.CatwalkCfg.CanReason {
@@ -741,9 +889,10 @@ func (a *sessionAgent) generateTitle(ctx context.Context, sessionID string, prom
 	)
 
 	resp, err := agent.Stream(ctx, fantasy.AgentStreamCall{
-		Prompt: fmt.Sprintf("Generate a concise title for the following content:\n\n%s\n <think>\n\n</think>", prompt),
+		Prompt: fmt.Sprintf("Generate a concise title for the following conversation:\n\n%s\n <think>\n\n</think>", content),
 		PrepareStep: func(callContext context.Context, options fantasy.PrepareStepFunctionOptions) (_ context.Context, prepared fantasy.PrepareStepResult, err error) {
 			prepared.Messages = options.Messages
+			a.redactMessages(sessionID, prepared.Messages)
 			if a.systemPromptPrefix != "" {
 				prepared.Messages = append([]fantasy.Message{fantasy.NewSystemMessage(a.systemPromptPrefix)}, prepared.Messages...)
 			}
@@ -810,6 +959,60 @@ func (a *sessionAgent) generateTitle(ctx context.Context, sessionID string, prom
 	}
 }
 
+// GenerateCommitMessage drafts a commit message for the diff with the small
+// model, following the style described by instructions.
+func (a *sessionAgent) GenerateCommitMessage(ctx context.Context, diff, instructions string) (string, error) {
+	return a.generateText(ctx,
+		string(commitPrompt)+"\n"+instructions,
+		"Write a commit message for these staged changes:\n\n"+diff,
+	)
+}
+
+// GeneratePullRequest drafts the title and body of a pull request for the
+// changes with the small model. The title is the first line.
+func (a *sessionAgent) GeneratePullRequest(ctx context.Context, changes string) (string, error) {
+	return a.generateText(ctx,
+		string(pullRequestPrompt),
+		"Write a pull request for these changes:\n\n"+changes,
+	)
+}
+
+// generateText runs a single prompt through the small model and returns
+// the text of the answer.
+func (a *sessionAgent) generateText(ctx context.Context, systemPrompt, prompt string) (string, error) {
+	agent := fantasy.NewAgent(a.smallModel.Model,
+		fantasy.WithSystemPrompt(systemPrompt+"\n /no_think"),
+	)
+	resp, err := agent.Generate(ctx, fantasy.AgentCall{
+		Prompt: prompt + "\n <think>\n\n</think>",
+		PrepareStep: func(callContext context.Context, options fantasy.PrepareStepFunctionOptions) (_ context.Context, prepared fantasy.PrepareStepResult, err error) {
+			prepared.Messages = options.Messages
+			a.redactMessages("", prepared.Messages)
+			if a.systemPromptPrefix != "" {
+				prepared.Messages = append([]fantasy.Message{fantasy.NewSystemMessage(a.systemPromptPrefix)}, prepared.Messages...)
+			}
+			return callContext, prepared, nil
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	text := resp.Response.Content.Text()
+	// Remove thinking tags if present.
+	if idx := strings.Index(text, "</think>"); idx >= 0 {
+		text = text[idx+len("</think>"):]
+	}
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("the model returned an empty answer")
+	}
+	return text, nil
+}
+
 func (a *sessionAgent) openrouterCost(metadata fantasy.ProviderMetadata) *float64 {
 	openrouterMetadata, ok := metadata[openrouter.Name]
 	if !ok {
@@ -830,8 +1033,10 @@ func (a *sessionAgent) updateSessionUsage(model Model, session *session.Session,
 		modelConfig.CostPer1MIn/1e6*float64(usage.InputTokens) +
 		modelConfig.CostPer1MOut/1e6*float64(usage.OutputTokens)
 
+	savings := cacheSavings(modelConfig, usage)
 	if a.isClaudeCode() {
 		cost = 0
+		savings = 0
 	}
 
 	a.eventTokensUsed(session.ID, model, usage, cost)
@@ -842,10 +1047,22 @@ func (a *sessionAgent) updateSessionUsage(model Model, session *session.Session,
 		session.Cost += cost
 	}
 
+	session.CacheReadTokens += usage.CacheReadTokens
+	session.CacheWriteTokens += usage.CacheCreationTokens
+	session.CacheSavings += savings
+
 	session.CompletionTokens = usage.OutputTokens + usage.CacheReadTokens
 	session.PromptTokens = usage.InputTokens + usage.CacheCreationTokens
 }
 
+// cacheSavings returns what reading from the cache saved compared to
+// sending the tokens again, net of what writing to it cost on top.
+func cacheSavings(model catwalk.Model, usage fantasy.Usage) float64 {
+	read := max(0, model.CostPer1MIn-model.CostPer1MOutCached) / 1e6 * float64(usage.CacheReadTokens)
+	write := max(0, model.CostPer1MInCached-model.CostPer1MIn) / 1e6 * float64(usage.CacheCreationTokens)
+	return read - write
+}
+
 func (a *sessionAgent) Cancel(sessionID string) {
 	// Cancel regular requests.
 	if cancel, ok := a.activeRequests.Take(sessionID); ok && cancel != nil {
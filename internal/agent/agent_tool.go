@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"strings"
 
 	"charm.land/fantasy"
 
@@ -18,6 +19,7 @@ var agentToolDescription []byte
 
 type AgentParams struct {
 	Prompt string `json:"prompt" description:"The task for the agent to perform"`
+	Agent  string `json:"agent,omitempty" description:"The name of the sub-agent to delegate to, leave empty to use the default task agent"`
 }
 
 const (
@@ -38,14 +40,44 @@ func (c *coordinator) agentTool(ctx context.Context) (fantasy.AgentTool, error)
 	if err != nil {
 		return nil, err
 	}
+
+	subAgents := make(map[string]SessionAgent)
+	description := string(agentToolDescription)
+	if names := c.cfg.SubAgentNames(); len(names) > 0 {
+		var sb strings.Builder
+		sb.WriteString(description)
+		sb.WriteString("\n<agents>\nUse the `agent` parameter to delegate to one of these specialized agents:\n")
+		for _, name := range names {
+			subAgent, err := c.buildSubAgent(ctx, c.cfg.Agents[name])
+			if err != nil {
+				return nil, fmt.Errorf("building sub-agent %q: %w", name, err)
+			}
+			subAgents[name] = subAgent
+			fmt.Fprintf(&sb, "- %s: %s\n", name, c.cfg.Agents[name].Description)
+		}
+		sb.WriteString("</agents>\n")
+		description = sb.String()
+	}
+
 	return fantasy.NewParallelAgentTool(
 		AgentToolName,
-		string(agentToolDescription),
+		description,
 		func(ctx context.Context, params AgentParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
 			if params.Prompt == "" {
 				return fantasy.NewTextErrorResponse("prompt is required"), nil
 			}
 
+			agent := agent
+			sessionTitle := "New Agent Session"
+			if params.Agent != "" {
+				subAgent, ok := subAgents[params.Agent]
+				if !ok {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("unknown agent %q", params.Agent)), nil
+				}
+				agent = subAgent
+				sessionTitle = fmt.Sprintf("Agent Session: %s", params.Agent)
+			}
+
 			sessionID := tools.GetSessionFromContext(ctx)
 			if sessionID == "" {
 				return fantasy.ToolResponse{}, errors.New("session id missing from context")
@@ -57,7 +89,7 @@ func (c *coordinator) agentTool(ctx context.Context) (fantasy.AgentTool, error)
 			}
 
 			agentToolSessionID := c.sessions.CreateAgentToolSessionID(agentMessageID, call.ID)
-			session, err := c.sessions.CreateTaskSession(ctx, agentToolSessionID, sessionID, "New Agent Session")
+			session, err := c.sessions.CreateTaskSession(ctx, agentToolSessionID, sessionID, sessionTitle)
 			if err != nil {
 				return fantasy.ToolResponse{}, fmt.Errorf("error creating session: %s", err)
 			}
@@ -103,3 +135,29 @@ func (c *coordinator) agentTool(ctx context.Context) (fantasy.AgentTool, error)
 			return fantasy.NewTextResponse(result.Response.Content.Text()), nil
 		}), nil
 }
+
+// buildSubAgent builds a user-defined sub-agent, falling back to the task
+// prompt when the config doesn't provide one. A sub-agent asking for the
+// small model runs on it as its main model too.
+func (c *coordinator) buildSubAgent(ctx context.Context, agentCfg config.Agent) (SessionAgent, error) {
+	var (
+		p   *prompt.Prompt
+		err error
+	)
+	if agentCfg.Prompt != "" {
+		p, err = prompt.NewPrompt(agentCfg.ID, agentCfg.Prompt, prompt.WithWorkingDir(c.cfg.WorkingDir()))
+	} else {
+		p, err = taskPrompt(prompt.WithWorkingDir(c.cfg.WorkingDir()))
+	}
+	if err != nil {
+		return nil, err
+	}
+	large, small, err := c.buildAgentModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if agentCfg.Model == config.SelectedModelTypeSmall {
+		large = small
+	}
+	return c.buildAgentWithModels(ctx, p, agentCfg, true, large, small)
+}
@@ -12,6 +12,7 @@ import (
 	"maps"
 	"net/http"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -20,13 +21,24 @@ import (
 	"github.com/charmbracelet/crush/internal/agent/hyper"
 	"github.com/charmbracelet/crush/internal/agent/prompt"
 	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/agent/transform"
+	"github.com/charmbracelet/crush/internal/commit"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/gh"
 	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/hooks"
 	"github.com/charmbracelet/crush/internal/log"
 	"github.com/charmbracelet/crush/internal/lsp"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/plan"
+	"github.com/charmbracelet/crush/internal/ratelimit"
+	"github.com/charmbracelet/crush/internal/redact"
+	"github.com/charmbracelet/crush/internal/remote"
+	"github.com/charmbracelet/crush/internal/requestlog"
+	"github.com/charmbracelet/crush/internal/semantic"
 	"github.com/charmbracelet/crush/internal/session"
 	"golang.org/x/sync/errgroup"
 
@@ -53,20 +65,51 @@ type Coordinator interface {
 	QueuedPromptsList(sessionID string) []string
 	ClearQueue(sessionID string)
 	Summarize(context.Context, string) error
+	// GenerateCommitMessage drafts a commit message for the staged changes
+	// of the repository in dir, with the configured attribution.
+	GenerateCommitMessage(ctx context.Context, dir string) (string, error)
+	// GeneratePullRequest drafts the title and body of a pull request for
+	// the current branch of the repository in dir, noting the files changed
+	// in the session.
+	GeneratePullRequest(ctx context.Context, dir, sessionID string) (gh.PullRequest, error)
 	Model() Model
 	UpdateModels(ctx context.Context) error
+	// PinProvider makes the session only use the model of provider, out of
+	// the large model and its failover ones; an empty provider unpins it.
+	PinProvider(sessionID, provider string) error
+	// EndSessions runs the session_end hooks for the sessions prompted
+	// since the start.
+	EndSessions(ctx context.Context)
+	// Preview returns the sections of the next request of the session,
+	// sending prompt and attachments. The sections dropped from it are
+	// passed to Run with WithDroppedSections.
+	Preview(ctx context.Context, sessionID, prompt string, attachments ...message.Attachment) ([]Section, error)
 }
 
 type coordinator struct {
-	cfg         *config.Config
-	sessions    session.Service
-	messages    message.Service
-	permissions permission.Service
-	history     history.Service
-	lspClients  *csync.Map[string, *lsp.Client]
+	cfg           *config.Config
+	sessions      session.Service
+	messages      message.Service
+	permissions   permission.Service
+	history       history.Service
+	plans         plan.Service
+	lspClients    *csync.Map[string, *lsp.Client]
+	semanticIndex *semantic.Index
 
 	currentAgent SessionAgent
 	agents       map[string]SessionAgent
+	// prompt is the system prompt of the current agent.
+	prompt *prompt.Prompt
+	// pins maps sessions to the provider they are pinned to.
+	pins       *csync.Map[string, string]
+	requestLog *requestlog.Log
+	limiter    *ratelimit.Limiter
+	redactor   *redact.Redactor
+	// prompted are the sessions prompted since the start, that the
+	// session_end hooks run for.
+	prompted *csync.Map[string, struct{}]
+	// toolResults keeps the results truncated for being too long.
+	toolResults *tools.ToolResults
 
 	readyWg errgroup.Group
 }
@@ -78,17 +121,37 @@ func NewCoordinator(
 	messages message.Service,
 	permissions permission.Service,
 	history history.Service,
+	plans plan.Service,
 	lspClients *csync.Map[string, *lsp.Client],
+	semanticIndex *semantic.Index,
 ) (Coordinator, error) {
 	c := &coordinator{
-		cfg:         cfg,
-		sessions:    sessions,
-		messages:    messages,
-		permissions: permissions,
-		history:     history,
-		lspClients:  lspClients,
-		agents:      make(map[string]SessionAgent),
+		cfg:           cfg,
+		sessions:      sessions,
+		messages:      messages,
+		permissions:   permissions,
+		history:       history,
+		plans:         plans,
+		lspClients:    lspClients,
+		semanticIndex: semanticIndex,
+		agents:        make(map[string]SessionAgent),
+		pins:          csync.NewMap[string, string](),
+		requestLog:    requestlog.New(cfg.Options.DataDirectory),
+		limiter:       ratelimit.New(),
+		prompted:      csync.NewMap[string, struct{}](),
+		toolResults:   tools.NewToolResults(cfg.Options.DataDirectory),
+	}
+	go func() {
+		if err := c.toolResults.Prune(tools.ToolResultsTTL); err != nil {
+			slog.Warn("Failed to prune the results of past tool calls", "error", err)
+		}
+	}()
+
+	redactor, err := redact.New(cfg)
+	if err != nil {
+		return nil, err
 	}
+	c.redactor = redactor
 
 	agentCfg, ok := cfg.Agents[config.AgentCoder]
 	if !ok {
@@ -107,16 +170,41 @@ func NewCoordinator(
 	}
 	c.currentAgent = agent
 	c.agents[config.AgentCoder] = agent
+	c.prompt = prompt
 	return c, nil
 }
 
 // Run implements Coordinator.
 func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string, attachments ...message.Attachment) (*fantasy.AgentResult, error) {
+	c.prompted.Set(sessionID, struct{}{})
+	result, err := c.run(ctx, sessionID, prompt, attachments...)
+	if result == nil && err == nil {
+		// The prompt was queued: the turn completes with the run in
+		// progress.
+		return nil, nil
+	}
+	event := hooks.Event{
+		Event:      hooks.TurnComplete,
+		SessionID:  sessionID,
+		WorkingDir: c.cfg.WorkingDir(),
+	}
+	if result != nil {
+		event.Response = result.Response.Content.Text()
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	_ = hooks.Run(context.WithoutCancel(ctx), c.cfg.Hooks, event)
+	return result, err
+}
+
+func (c *coordinator) run(ctx context.Context, sessionID string, prompt string, attachments ...message.Attachment) (*fantasy.AgentResult, error) {
 	if err := c.readyWg.Wait(); err != nil {
 		return nil, err
 	}
 
 	model := c.currentAgent.Model()
+	c.tagSession(ctx, sessionID, model)
 	maxTokens := model.CatwalkCfg.DefaultMaxTokens
 	if model.ModelCfg.MaxTokens != 0 {
 		maxTokens = model.ModelCfg.MaxTokens
@@ -139,6 +227,7 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 	}
 
 	mergedOptions, temp, topP, topK, freqPenalty, presPenalty := mergeCallOptions(model, providerCfg)
+	setPromptCacheKey(mergedOptions, sessionID)
 
 	if providerCfg.OAuthToken != nil && providerCfg.OAuthToken.IsExpired() {
 		slog.Info("Token needs to be refreshed", "provider", providerCfg.ID)
@@ -147,6 +236,26 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 		}
 	}
 
+	var maxRetries *int
+	if c.cfg.Options.Failover != nil {
+		// The failover model retries the requests itself.
+		maxRetries = new(int)
+	}
+
+	if dropped := droppedIDs(ctx, SectionAttachment); len(dropped) > 0 {
+		attachments = slices.DeleteFunc(slices.Clone(attachments), func(att message.Attachment) bool {
+			return slices.Contains(dropped, att.FilePath)
+		})
+	}
+	var systemPrompt string
+	if dropped := droppedIDs(ctx, SectionInstructions); len(dropped) > 0 {
+		var err error
+		systemPrompt, err = c.prompt.Without(dropped...).Build(ctx, model.Model.Provider(), model.Model.Model(), *c.cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	run := func() (*fantasy.AgentResult, error) {
 		return c.currentAgent.Run(ctx, SessionAgentCall{
 			SessionID:        sessionID,
@@ -159,6 +268,10 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 			TopK:             topK,
 			FrequencyPenalty: freqPenalty,
 			PresencePenalty:  presPenalty,
+			MaxRetries:       maxRetries,
+			PlanMode:         c.plans.Enabled(),
+			SystemPrompt:     systemPrompt,
+			DroppedTurns:     droppedIDs(ctx, SectionTurn),
 		})
 	}
 	result, originalErr := run()
@@ -172,7 +285,7 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 			}
 			slog.Info("Retrying request with refreshed OAuth token", "provider", providerCfg.ID)
 			return run()
-		case strings.Contains(providerCfg.APIKeyTemplate, "$"):
+		case strings.Contains(providerCfg.APIKeyTemplate, "$"), strings.HasPrefix(providerCfg.APIKeyTemplate, config.CredentialPrefix):
 			slog.Info("Received 401. Refreshing API Key template and retrying", "provider", providerCfg.ID)
 			if err := c.refreshApiKeyTemplate(ctx, providerCfg); err != nil {
 				return nil, originalErr
@@ -185,6 +298,22 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 	return result, originalErr
 }
 
+// tagSession tags the session after the repository and the model it runs
+// in, keeping the tags it already has.
+func (c *coordinator) tagSession(ctx context.Context, sessionID string, model Model) {
+	sess, err := c.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return
+	}
+	tags := session.NormalizeTags(append(slices.Clone(sess.Tags), filepath.Base(c.cfg.WorkingDir()), model.ModelCfg.Model))
+	if slices.Equal(tags, sess.Tags) {
+		return
+	}
+	if _, err := c.sessions.SetTags(ctx, sessionID, tags); err != nil {
+		slog.Error("Failed to tag session", "session_id", sessionID, "error", err)
+	}
+}
+
 func getProviderOptions(model Model, providerCfg config.ProviderConfig) fantasy.ProviderOptions {
 	options := fantasy.ProviderOptions{}
 
@@ -305,6 +434,22 @@ func getProviderOptions(model Model, providerCfg config.ProviderConfig) fantasy.
 	return options
 }
 
+// setPromptCacheKey keys the OpenAI prompt cache by session, unless the
+// configuration already does, so the requests of a session hit the same
+// cache.
+func setPromptCacheKey(options fantasy.ProviderOptions, sessionID string) {
+	switch opts := options[openai.Name].(type) {
+	case *openai.ProviderOptions:
+		if opts.PromptCacheKey == nil {
+			opts.PromptCacheKey = &sessionID
+		}
+	case *openai.ResponsesProviderOptions:
+		if opts.PromptCacheKey == nil {
+			opts.PromptCacheKey = &sessionID
+		}
+	}
+}
+
 func mergeCallOptions(model Model, cfg config.ProviderConfig) (fantasy.ProviderOptions, *float64, *float64, *int64, *float64, *float64) {
 	modelOptions := getProviderOptions(model, cfg)
 	temp := cmp.Or(model.ModelCfg.Temperature, model.CatwalkCfg.Options.Temperature)
@@ -320,7 +465,10 @@ func (c *coordinator) buildAgent(ctx context.Context, prompt *prompt.Prompt, age
 	if err != nil {
 		return nil, err
 	}
+	return c.buildAgentWithModels(ctx, prompt, agent, isSubAgent, large, small)
+}
 
+func (c *coordinator) buildAgentWithModels(ctx context.Context, prompt *prompt.Prompt, agent config.Agent, isSubAgent bool, large, small Model) (SessionAgent, error) {
 	systemPrompt, err := prompt.Build(ctx, large.Model.Provider(), large.Model.Model(), *c.cfg)
 	if err != nil {
 		return nil, err
@@ -338,6 +486,7 @@ func (c *coordinator) buildAgent(ctx context.Context, prompt *prompt.Prompt, age
 		c.sessions,
 		c.messages,
 		nil,
+		c.redactor,
 	})
 	c.readyWg.Go(func() error {
 		tools, err := c.buildTools(ctx, agent)
@@ -351,7 +500,46 @@ func (c *coordinator) buildAgent(ctx context.Context, prompt *prompt.Prompt, age
 	return result, nil
 }
 
+// supportsTools reports whether the model of agent can call tools. Local
+// models sometimes can't, and fail when they are given any.
+func (c *coordinator) supportsTools(agent config.Agent) bool {
+	modelCfg, ok := c.cfg.Models[agent.Model]
+	if !ok {
+		return true
+	}
+	providerCfg, ok := c.cfg.Providers.Get(modelCfg.Provider)
+	return !ok || providerCfg.SupportsTools(modelCfg.Model)
+}
+
+// localFileTools are the tools working on local files, left out in remote
+// workspaces.
+var localFileTools = []string{
+	tools.BashToolName,
+	tools.JobOutputToolName,
+	tools.JobKillToolName,
+	tools.JobInputToolName,
+	tools.DownloadToolName,
+	tools.EditToolName,
+	tools.MultiEditToolName,
+	tools.PatchToolName,
+	tools.ReplaceToolName,
+	tools.GlobToolName,
+	tools.GrepToolName,
+	tools.LSToolName,
+	tools.SymbolsToolName,
+	tools.ViewToolName,
+	tools.WriteToolName,
+	tools.DiagnosticsToolName,
+	tools.ReferencesToolName,
+	tools.SemanticSearchToolName,
+}
+
 func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fantasy.AgentTool, error) {
+	if !c.supportsTools(agent) {
+		slog.Info("Model can't call tools, running the agent without them", "agent", agent.Name)
+		return nil, nil
+	}
+
 	var allTools []fantasy.AgentTool
 	if slices.Contains(agent.AllowedTools, AgentToolName) {
 		agentTool, err := c.agentTool(ctx)
@@ -381,15 +569,24 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 		tools.NewBashTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Options.Attribution, modelName),
 		tools.NewJobOutputTool(),
 		tools.NewJobKillTool(),
+		tools.NewJobInputTool(c.permissions, c.cfg.WorkingDir()),
 		tools.NewDownloadTool(c.permissions, c.cfg.WorkingDir(), nil),
 		tools.NewEditTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir()),
 		tools.NewMultiEditTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir()),
-		tools.NewFetchTool(c.permissions, c.cfg.WorkingDir(), nil),
+		tools.NewPatchTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir()),
+		tools.NewReplaceTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir()),
+		tools.NewFetchTool(c.permissions, c.cfg.WorkingDir(), tools.FetchOptions{
+			Cache:          tools.NewFetchCache(filepath.Join(c.cfg.Options.DataDirectory, "fetch"), tools.FetchCacheTTL),
+			AllowedDomains: c.cfg.AllowedDomains(),
+		}, nil),
 		tools.NewGlobTool(c.cfg.WorkingDir()),
 		tools.NewGrepTool(c.cfg.WorkingDir()),
 		tools.NewLsTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Tools.Ls),
+		tools.NewPlanTool(c.plans, c.sessions),
 		tools.NewSourcegraphTool(nil),
+		tools.NewSymbolsTool(c.cfg.WorkingDir()),
 		tools.NewTodosTool(c.sessions),
+		tools.NewToolOutputTool(c.toolResults),
 		tools.NewViewTool(c.lspClients, c.permissions, c.cfg.WorkingDir()),
 		tools.NewWriteTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir()),
 	)
@@ -398,6 +595,25 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 		allTools = append(allTools, tools.NewDiagnosticsTool(c.lspClients), tools.NewReferencesTool(c.lspClients))
 	}
 
+	if host := remote.New(c.cfg); host != nil {
+		// The files of a remote workspace are on its host, where the tools
+		// working on local files give way to remote ones.
+		allTools = slices.DeleteFunc(allTools, func(tool fantasy.AgentTool) bool {
+			return slices.Contains(localFileTools, tool.Info().Name)
+		})
+		allTools = append(allTools, tools.NewRemoteTools(host, c.permissions, c.history)...)
+	}
+
+	if c.semanticIndex != nil {
+		allTools = append(allTools, tools.NewSemanticSearchTool(c.semanticIndex, c.cfg.WorkingDir()))
+	}
+
+	if searchOpts, err := c.webSearchOptions(); err == nil {
+		allTools = append(allTools, tools.NewWebSearchTool(searchOpts, nil))
+	} else {
+		slog.Warn("Web search is unavailable", "error", err)
+	}
+
 	var filteredTools []fantasy.AgentTool
 	for _, tool := range allTools {
 		if slices.Contains(agent.AllowedTools, tool.Info().Name) {
@@ -436,7 +652,7 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 	slices.SortFunc(filteredTools, func(a, b fantasy.AgentTool) int {
 		return strings.Compare(a.Info().Name, b.Info().Name)
 	})
-	return filteredTools, nil
+	return withHooks(c.cfg, withTruncation(c.toolResults, withPlanMode(c.plans, filteredTools))), nil
 }
 
 // TODO: when we support multiple agents we need to change this so that we pass in the agent specific model config
@@ -512,15 +728,15 @@ func (c *coordinator) buildAgentModels(ctx context.Context) (Model, Model, error
 		return Model{}, Model{}, err
 	}
 
-	return Model{
-			Model:      largeModel,
-			CatwalkCfg: *largeCatwalkModel,
-			ModelCfg:   largeModelCfg,
-		}, Model{
-			Model:      smallModel,
-			CatwalkCfg: *smallCatwalkModel,
-			ModelCfg:   smallModelCfg,
-		}, nil
+	return c.withFailover(ctx, Model{
+		Model:      largeModel,
+		CatwalkCfg: *largeCatwalkModel,
+		ModelCfg:   largeModelCfg,
+	}), Model{
+		Model:      smallModel,
+		CatwalkCfg: *smallCatwalkModel,
+		ModelCfg:   smallModelCfg,
+	}, nil
 }
 
 func (c *coordinator) buildAnthropicProvider(baseURL, apiKey string, headers map[string]string, isOauth bool) (fantasy.Provider, error) {
@@ -543,8 +759,7 @@ func (c *coordinator) buildAnthropicProvider(baseURL, apiKey string, headers map
 		opts = append(opts, anthropic.WithBaseURL(baseURL))
 	}
 
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	if httpClient := c.httpClient(); httpClient != nil {
 		opts = append(opts, anthropic.WithHTTPClient(httpClient))
 	}
 	return anthropic.New(opts...)
@@ -555,8 +770,7 @@ func (c *coordinator) buildOpenaiProvider(baseURL, apiKey string, headers map[st
 		openai.WithAPIKey(apiKey),
 		openai.WithUseResponsesAPI(),
 	}
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	if httpClient := c.httpClient(); httpClient != nil {
 		opts = append(opts, openai.WithHTTPClient(httpClient))
 	}
 	if len(headers) > 0 {
@@ -572,8 +786,7 @@ func (c *coordinator) buildOpenrouterProvider(_, apiKey string, headers map[stri
 	opts := []openrouter.Option{
 		openrouter.WithAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	if httpClient := c.httpClient(); httpClient != nil {
 		opts = append(opts, openrouter.WithHTTPClient(httpClient))
 	}
 	if len(headers) > 0 {
@@ -582,13 +795,16 @@ func (c *coordinator) buildOpenrouterProvider(_, apiKey string, headers map[stri
 	return openrouter.New(opts...)
 }
 
-func (c *coordinator) buildOpenaiCompatProvider(baseURL, apiKey string, headers map[string]string, extraBody map[string]any) (fantasy.Provider, error) {
+func (c *coordinator) buildOpenaiCompatProvider(baseURL, apiKey string, headers map[string]string, extraBody map[string]any, requestTransform, responseTransform *config.ProviderTransform) (fantasy.Provider, error) {
 	opts := []openaicompat.Option{
 		openaicompat.WithBaseURL(baseURL),
 		openaicompat.WithAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	httpClient := c.httpClient()
+	if requestTransform != nil || responseTransform != nil {
+		httpClient = transform.NewClient(httpClient, requestTransform, responseTransform)
+	}
+	if httpClient != nil {
 		opts = append(opts, openaicompat.WithHTTPClient(httpClient))
 	}
 	if len(headers) > 0 {
@@ -608,8 +824,7 @@ func (c *coordinator) buildAzureProvider(baseURL, apiKey string, headers map[str
 		azure.WithAPIKey(apiKey),
 		azure.WithUseResponsesAPI(),
 	}
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	if httpClient := c.httpClient(); httpClient != nil {
 		opts = append(opts, azure.WithHTTPClient(httpClient))
 	}
 	if options == nil {
@@ -627,8 +842,7 @@ func (c *coordinator) buildAzureProvider(baseURL, apiKey string, headers map[str
 
 func (c *coordinator) buildBedrockProvider(headers map[string]string) (fantasy.Provider, error) {
 	var opts []bedrock.Option
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	if httpClient := c.httpClient(); httpClient != nil {
 		opts = append(opts, bedrock.WithHTTPClient(httpClient))
 	}
 	if len(headers) > 0 {
@@ -646,8 +860,7 @@ func (c *coordinator) buildGoogleProvider(baseURL, apiKey string, headers map[st
 		google.WithBaseURL(baseURL),
 		google.WithGeminiAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	if httpClient := c.httpClient(); httpClient != nil {
 		opts = append(opts, google.WithHTTPClient(httpClient))
 	}
 	if len(headers) > 0 {
@@ -658,8 +871,7 @@ func (c *coordinator) buildGoogleProvider(baseURL, apiKey string, headers map[st
 
 func (c *coordinator) buildGoogleVertexProvider(headers map[string]string, options map[string]string) (fantasy.Provider, error) {
 	opts := []google.Option{}
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	if httpClient := c.httpClient(); httpClient != nil {
 		opts = append(opts, google.WithHTTPClient(httpClient))
 	}
 	if len(headers) > 0 {
@@ -679,13 +891,29 @@ func (c *coordinator) buildHyperProvider(baseURL, apiKey string) (fantasy.Provid
 		hyper.WithBaseURL(baseURL),
 		hyper.WithAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
-		httpClient := log.NewHTTPClient()
+	if httpClient := c.httpClient(); httpClient != nil {
 		opts = append(opts, hyper.WithHTTPClient(httpClient))
 	}
 	return hyper.New(opts...)
 }
 
+// httpClient returns the client providers send requests with, logging or
+// recording them as configured, and holding them back when near the rate
+// limits of the provider.
+func (c *coordinator) httpClient() *http.Client {
+	var client *http.Client
+	if c.cfg.Options.Debug {
+		client = log.NewHTTPClient()
+	}
+	if c.cfg.Options.LogRequests {
+		client = requestlog.NewClient(client, c.requestLog, tools.GetSessionFromContext)
+	}
+	// Each request sent again after being rate limited is logged on its own.
+	// With failover configured, the failover model retries them, or falls
+	// back to another provider rather than waiting on this one.
+	return ratelimit.NewClient(client, c.limiter, c.cfg.Options.Failover == nil)
+}
+
 func (c *coordinator) isAnthropicThinking(model config.SelectedModel) bool {
 	if model.Think {
 		return true
@@ -745,7 +973,7 @@ func (c *coordinator) buildProvider(providerCfg config.ProviderConfig, model con
 			}
 			providerCfg.ExtraBody["tool_stream"] = true
 		}
-		return c.buildOpenaiCompatProvider(baseURL, apiKey, headers, providerCfg.ExtraBody)
+		return c.buildOpenaiCompatProvider(baseURL, apiKey, headers, providerCfg.ExtraBody, providerCfg.RequestTransform, providerCfg.ResponseTransform)
 	case hyper.Name:
 		return c.buildHyperProvider(baseURL, apiKey)
 	default:
@@ -825,6 +1053,51 @@ func (c *coordinator) Summarize(ctx context.Context, sessionID string) error {
 	return c.currentAgent.Summarize(ctx, sessionID, getProviderOptions(c.currentAgent.Model(), providerCfg))
 }
 
+func (c *coordinator) GenerateCommitMessage(ctx context.Context, dir string) (string, error) {
+	diff, err := commit.StagedDiff(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	msg, err := c.currentAgent.GenerateCommitMessage(ctx, diff, commit.Instructions(c.cfg.Options.Commit))
+	if err != nil {
+		return "", err
+	}
+	modelName := ""
+	if modelCfg, ok := c.cfg.Models[config.SelectedModelTypeSmall]; ok {
+		if model := c.cfg.GetModel(modelCfg.Provider, modelCfg.Model); model != nil {
+			modelName = model.Name
+		}
+	}
+	return commit.WithAttribution(msg, c.cfg.Options.Attribution, modelName), nil
+}
+
+func (c *coordinator) GeneratePullRequest(ctx context.Context, dir, sessionID string) (gh.PullRequest, error) {
+	changes, err := gh.BranchChanges(ctx, dir)
+	if err != nil {
+		return gh.PullRequest{}, err
+	}
+	if sessionID != "" {
+		files, err := c.history.ListLatestSessionFiles(ctx, sessionID)
+		if err == nil && len(files) > 0 {
+			var sb strings.Builder
+			sb.WriteString("\nFiles changed in the current session, which the pull request is mostly about:\n")
+			for _, file := range files {
+				fmt.Fprintf(&sb, "- %s\n", fsext.PrettyPath(file.Path))
+			}
+			changes = sb.String() + "\n" + changes
+		}
+	}
+	text, err := c.currentAgent.GeneratePullRequest(ctx, changes)
+	if err != nil {
+		return gh.PullRequest{}, err
+	}
+	title, body := gh.SplitPullRequest(text)
+	if attribution := c.cfg.Options.Attribution; attribution != nil && attribution.GeneratedWith {
+		body += "\n\n💘 Generated with Crush"
+	}
+	return gh.PullRequest{Title: title, Body: strings.TrimSpace(body)}, nil
+}
+
 func (c *coordinator) isUnauthorized(err error) bool {
 	var providerErr *fantasy.ProviderError
 	return errors.As(err, &providerErr) && providerErr.StatusCode == http.StatusUnauthorized
@@ -0,0 +1,337 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"charm.land/fantasy"
+	"charm.land/fantasy/providers/openrouter"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+var failoverBroker = pubsub.NewBroker[FailoverEvent]()
+
+// FailoverEvent is published when a request to a model failed and is about
+// to be retried, with the same model or the next fallback one.
+type FailoverEvent struct {
+	SessionID string
+	// Provider and Model are what the request is retried with.
+	Provider string
+	Model    string
+	// Fallback is whether the model differs from the one that failed.
+	Fallback bool
+	Attempt  int
+	Delay    time.Duration
+	Reason   string
+}
+
+// SubscribeFailoverEvents returns a channel receiving the retries of failed
+// requests.
+func SubscribeFailoverEvents(ctx context.Context) <-chan pubsub.Event[FailoverEvent] {
+	return failoverBroker.Subscribe(ctx)
+}
+
+// RetryPolicy is how failed requests are retried.
+type RetryPolicy struct {
+	MaxRetries   int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// NewRetryPolicy returns the policy cfg configures, which may be nil.
+func NewRetryPolicy(cfg *config.Failover) RetryPolicy {
+	p := RetryPolicy{
+		MaxRetries:   2,
+		InitialDelay: 2 * time.Second,
+		MaxDelay:     30 * time.Second,
+	}
+	if cfg == nil {
+		return p
+	}
+	if cfg.MaxRetries != nil {
+		p.MaxRetries = max(0, *cfg.MaxRetries)
+	}
+	if cfg.InitialDelay > 0 {
+		p.InitialDelay = time.Duration(cfg.InitialDelay) * time.Second
+	}
+	if cfg.MaxDelay > 0 {
+		p.MaxDelay = time.Duration(cfg.MaxDelay) * time.Second
+	}
+	return p
+}
+
+// Delay returns how long to wait before the given retry, counted from 0,
+// after err. It doubles with each retry, unless the provider said how long
+// to wait, and never exceeds the maximum delay.
+func (p RetryPolicy) Delay(retry int, err error) time.Duration {
+	delay := p.InitialDelay
+	for range retry {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			break
+		}
+	}
+	var providerErr *fantasy.ProviderError
+	if errors.As(err, &providerErr) {
+		if seconds, parseErr := strconv.ParseFloat(providerErr.ResponseHeaders["retry-after"], 64); parseErr == nil && seconds > 0 {
+			delay = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// ShouldRetry reports whether err is worth retrying, or falling back to
+// another model for: the provider is rate limiting, failing, or can't be
+// reached.
+func ShouldRetry(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var providerErr *fantasy.ProviderError
+	if errors.As(err, &providerErr) {
+		switch code := providerErr.StatusCode; {
+		case code == http.StatusRequestTimeout, code == http.StatusConflict, code == http.StatusTooManyRequests:
+			return true
+		default:
+			return code >= http.StatusInternalServerError
+		}
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryReason describes why a request is retried.
+func retryReason(err error) string {
+	var providerErr *fantasy.ProviderError
+	if errors.As(err, &providerErr) && providerErr.StatusCode != 0 {
+		if providerErr.StatusCode == http.StatusTooManyRequests {
+			return "rate limited"
+		}
+		return fmt.Sprintf("%d %s", providerErr.StatusCode, http.StatusText(providerErr.StatusCode))
+	}
+	return "unreachable"
+}
+
+// fallbackModel is a model to fall back to, with the options of its
+// provider.
+type fallbackModel struct {
+	Model
+	options fantasy.ProviderOptions
+}
+
+// failoverModel is a language model retrying the requests failing before
+// anything was streamed, falling back to the next model once the retries of
+// one are exhausted. Sessions pinned to a provider only use its model.
+type failoverModel struct {
+	models []fallbackModel
+	policy RetryPolicy
+	pins   *csync.Map[string, string]
+}
+
+var errStopped = errors.New("stopped")
+
+// candidates returns the models to try, in order, for the session of ctx.
+func (m *failoverModel) candidates(ctx context.Context) []fallbackModel {
+	provider, ok := m.pins.Get(tools.GetSessionFromContext(ctx))
+	if !ok {
+		return m.models
+	}
+	for _, model := range m.models {
+		if model.ModelCfg.Provider == provider {
+			return []fallbackModel{model}
+		}
+	}
+	return m.models[:1]
+}
+
+// try calls fn with each model, retrying while it fails with errors worth
+// retrying, and returns the last error.
+func (m *failoverModel) try(ctx context.Context, call fantasy.Call, fn func(fantasy.LanguageModel, fantasy.Call) error) error {
+	sessionID := tools.GetSessionFromContext(ctx)
+	candidates := m.candidates(ctx)
+	primary := m.models[0].ModelCfg
+	var err error
+	for i, model := range candidates {
+		// The call was built with the options of the primary model; any
+		// other one, even first for a pinned session, needs its own.
+		if model.ModelCfg.Provider != primary.Provider || model.ModelCfg.Model != primary.Model {
+			call.ProviderOptions = model.options
+		}
+		for retry := 0; ; retry++ {
+			err = fn(model.Model.Model, call)
+			if !ShouldRetry(err) {
+				return err
+			}
+			next, fallback := model, false
+			switch {
+			case retry < m.policy.MaxRetries:
+			case i+1 < len(candidates):
+				next, fallback = candidates[i+1], true
+			default:
+				return err
+			}
+			delay := m.policy.Delay(retry, err)
+			if fallback {
+				// The next model isn't rate limited, so don't wait for this one.
+				delay = 0
+			}
+			failoverBroker.Publish(pubsub.UpdatedEvent, FailoverEvent{
+				SessionID: sessionID,
+				Provider:  next.ModelCfg.Provider,
+				Model:     next.CatwalkCfg.Name,
+				Fallback:  fallback,
+				Attempt:   retry + 1,
+				Delay:     delay,
+				Reason:    retryReason(err),
+			})
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if fallback {
+				break
+			}
+		}
+	}
+	return err
+}
+
+func (m *failoverModel) Generate(ctx context.Context, call fantasy.Call) (*fantasy.Response, error) {
+	var resp *fantasy.Response
+	err := m.try(ctx, call, func(model fantasy.LanguageModel, call fantasy.Call) (err error) {
+		resp, err = model.Generate(ctx, call)
+		return err
+	})
+	return resp, err
+}
+
+func (m *failoverModel) Stream(ctx context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+	return func(yield func(fantasy.StreamPart) bool) {
+		err := m.try(ctx, call, func(model fantasy.LanguageModel, call fantasy.Call) error {
+			stream, err := model.Stream(ctx, call)
+			if err != nil {
+				return err
+			}
+			started := false
+			for part := range stream {
+				if part.Type == fantasy.StreamPartTypeError && !started {
+					// Nothing was streamed yet, so the request can be retried.
+					return part.Error
+				}
+				if part.Type != fantasy.StreamPartTypeWarnings {
+					started = true
+				}
+				if !yield(part) {
+					return errStopped
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopped) {
+			yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeError, Error: err})
+		}
+	}, nil
+}
+
+func (m *failoverModel) GenerateObject(ctx context.Context, call fantasy.ObjectCall) (*fantasy.ObjectResponse, error) {
+	return m.models[0].Model.Model.GenerateObject(ctx, call)
+}
+
+func (m *failoverModel) StreamObject(ctx context.Context, call fantasy.ObjectCall) (fantasy.ObjectStreamResponse, error) {
+	return m.models[0].Model.Model.StreamObject(ctx, call)
+}
+
+func (m *failoverModel) Provider() string {
+	return m.models[0].Model.Model.Provider()
+}
+
+func (m *failoverModel) Model() string {
+	return m.models[0].Model.Model.Model()
+}
+
+// withFailover returns large retrying its failed requests, and falling back
+// to the failover models, when failover is configured.
+func (c *coordinator) withFailover(ctx context.Context, large Model) Model {
+	failover := c.cfg.Options.Failover
+	if failover == nil {
+		return large
+	}
+	models := []fallbackModel{{Model: large}}
+	for _, selected := range failover.Models {
+		model, err := c.buildFallbackModel(ctx, selected)
+		if err != nil {
+			slog.Warn("Skipping failover model", "provider", selected.Provider, "model", selected.Model, "error", err)
+			continue
+		}
+		models = append(models, model)
+	}
+	large.Model = &failoverModel{
+		models: models,
+		policy: NewRetryPolicy(failover),
+		pins:   c.pins,
+	}
+	return large
+}
+
+func (c *coordinator) buildFallbackModel(ctx context.Context, selected config.SelectedModel) (fallbackModel, error) {
+	providerCfg, ok := c.cfg.Providers.Get(selected.Provider)
+	if !ok {
+		return fallbackModel{}, fmt.Errorf("provider %q not configured", selected.Provider)
+	}
+	i := slices.IndexFunc(providerCfg.Models, func(m catwalk.Model) bool {
+		return m.ID == selected.Model
+	})
+	if i < 0 {
+		return fallbackModel{}, fmt.Errorf("model %q not found in provider config", selected.Model)
+	}
+	provider, err := c.buildProvider(providerCfg, selected)
+	if err != nil {
+		return fallbackModel{}, err
+	}
+	modelID := selected.Model
+	if selected.Provider == openrouter.Name && isExactoSupported(modelID) {
+		modelID += ":exacto"
+	}
+	languageModel, err := provider.LanguageModel(ctx, modelID)
+	if err != nil {
+		return fallbackModel{}, err
+	}
+	model := Model{
+		Model:      languageModel,
+		CatwalkCfg: providerCfg.Models[i],
+		ModelCfg:   selected,
+	}
+	options, _, _, _, _, _ := mergeCallOptions(model, providerCfg)
+	return fallbackModel{Model: model, options: options}, nil
+}
+
+// PinProvider implements Coordinator.
+func (c *coordinator) PinProvider(sessionID, provider string) error {
+	if provider == "" {
+		c.pins.Del(sessionID)
+		return nil
+	}
+	providers := []string{c.currentAgent.Model().ModelCfg.Provider}
+	if failover := c.cfg.Options.Failover; failover != nil {
+		for _, model := range failover.Models {
+			providers = append(providers, model.Provider)
+		}
+	}
+	if !slices.Contains(providers, provider) {
+		return fmt.Errorf("%q is neither the provider of the large model nor of a failover one", provider)
+	}
+	c.pins.Set(sessionID, provider)
+	return nil
+}
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/stretchr/testify/require"
+)
+
+// stubModel streams its next error, if any, or else its provider as text.
+type stubModel struct {
+	fantasy.LanguageModel
+	provider string
+	errs     []error
+	calls    int
+	options  fantasy.ProviderOptions
+}
+
+func (m *stubModel) Stream(_ context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+	m.calls++
+	m.options = call.ProviderOptions
+	var err error
+	if len(m.errs) > 0 {
+		err, m.errs = m.errs[0], m.errs[1:]
+	}
+	return func(yield func(fantasy.StreamPart) bool) {
+		if err != nil {
+			yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeError, Error: err})
+			return
+		}
+		yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, Delta: m.provider})
+	}, nil
+}
+
+func (m *stubModel) Provider() string { return m.provider }
+
+func (m *stubModel) Model() string { return m.provider }
+
+func streamText(t *testing.T, ctx context.Context, model fantasy.LanguageModel) (string, error) {
+	t.Helper()
+	stream, err := model.Stream(ctx, fantasy.Call{})
+	require.NoError(t, err)
+	var text string
+	for part := range stream {
+		switch part.Type {
+		case fantasy.StreamPartTypeError:
+			return text, part.Error
+		case fantasy.StreamPartTypeTextDelta:
+			text += part.Delta
+		}
+	}
+	return text, nil
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	maxRetries := 3
+	p := NewRetryPolicy(&config.Failover{MaxRetries: &maxRetries, InitialDelay: 1, MaxDelay: 5})
+	require.Equal(t, 3, p.MaxRetries)
+	require.Equal(t, time.Second, p.Delay(0, nil))
+	require.Equal(t, 4*time.Second, p.Delay(2, nil))
+	require.Equal(t, 5*time.Second, p.Delay(10, nil))
+
+	rateLimited := &fantasy.ProviderError{
+		StatusCode:      http.StatusTooManyRequests,
+		ResponseHeaders: map[string]string{"retry-after": "3"},
+	}
+	require.Equal(t, 3*time.Second, p.Delay(0, rateLimited))
+
+	require.True(t, ShouldRetry(rateLimited))
+	require.True(t, ShouldRetry(&fantasy.ProviderError{StatusCode: http.StatusBadGateway}))
+	require.False(t, ShouldRetry(&fantasy.ProviderError{StatusCode: http.StatusBadRequest}))
+	require.False(t, ShouldRetry(context.Canceled))
+	require.False(t, ShouldRetry(errors.New("boom")))
+}
+
+func TestFailoverModel(t *testing.T) {
+	t.Parallel()
+
+	unavailable := &fantasy.ProviderError{StatusCode: http.StatusServiceUnavailable}
+	newModel := func(primaryErrs ...error) (*failoverModel, *stubModel, *stubModel) {
+		primary := &stubModel{provider: "primary", errs: primaryErrs}
+		secondary := &stubModel{provider: "secondary"}
+		return &failoverModel{
+			models: []fallbackModel{
+				{Model: Model{Model: primary, ModelCfg: config.SelectedModel{Provider: "primary"}}},
+				{
+					Model:   Model{Model: secondary, ModelCfg: config.SelectedModel{Provider: "secondary"}},
+					options: fantasy.ProviderOptions{"secondary": nil},
+				},
+			},
+			policy: RetryPolicy{MaxRetries: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond},
+			pins:   csync.NewMap[string, string](),
+		}, primary, secondary
+	}
+
+	t.Run("retries the same model first", func(t *testing.T) {
+		t.Parallel()
+		model, primary, secondary := newModel(unavailable)
+		text, err := streamText(t, t.Context(), model)
+		require.NoError(t, err)
+		require.Equal(t, "primary", text)
+		require.Equal(t, 2, primary.calls)
+		require.Equal(t, 0, secondary.calls)
+	})
+
+	t.Run("falls back once retries are exhausted", func(t *testing.T) {
+		t.Parallel()
+		model, primary, _ := newModel(unavailable, unavailable)
+		text, err := streamText(t, t.Context(), model)
+		require.NoError(t, err)
+		require.Equal(t, "secondary", text)
+		require.Equal(t, 2, primary.calls)
+	})
+
+	t.Run("doesn't retry other errors", func(t *testing.T) {
+		t.Parallel()
+		badRequest := &fantasy.ProviderError{StatusCode: http.StatusBadRequest}
+		model, primary, _ := newModel(badRequest)
+		_, err := streamText(t, t.Context(), model)
+		require.ErrorIs(t, err, badRequest)
+		require.Equal(t, 1, primary.calls)
+	})
+
+	t.Run("pinned sessions don't fall back", func(t *testing.T) {
+		t.Parallel()
+		model, _, secondary := newModel(unavailable, unavailable)
+		model.pins.Set("session", "primary")
+		ctx := context.WithValue(t.Context(), tools.SessionIDContextKey, "session")
+		_, err := streamText(t, ctx, model)
+		require.ErrorIs(t, err, unavailable)
+		require.Equal(t, 0, secondary.calls)
+	})
+	t.Run("sessions pinned to a fallback use its options", func(t *testing.T) {
+		t.Parallel()
+		model, primary, secondary := newModel()
+		model.pins.Set("session", "secondary")
+		ctx := context.WithValue(t.Context(), tools.SessionIDContextKey, "session")
+		text, err := streamText(t, ctx, model)
+		require.NoError(t, err)
+		require.Equal(t, "secondary", text)
+		require.Equal(t, 0, primary.calls)
+		require.Equal(t, fantasy.ProviderOptions{"secondary": nil}, secondary.options)
+	})
+}
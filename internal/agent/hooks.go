@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/hooks"
+)
+
+// hookedTool runs the pre_tool_call hooks before the tool, and the
+// post_edit ones on the files it changed.
+type hookedTool struct {
+	fantasy.AgentTool
+	cfg *config.Config
+}
+
+// withHooks wraps the tools to run the hooks configured around them.
+func withHooks(cfg *config.Config, agentTools []fantasy.AgentTool) []fantasy.AgentTool {
+	if len(cfg.Hooks.PreToolCall) == 0 && len(cfg.Hooks.PostEdit) == 0 {
+		return agentTools
+	}
+	wrapped := make([]fantasy.AgentTool, len(agentTools))
+	for i, tool := range agentTools {
+		wrapped[i] = &hookedTool{AgentTool: tool, cfg: cfg}
+	}
+	return wrapped
+}
+
+func (t *hookedTool) Run(ctx context.Context, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	event := hooks.Event{
+		Event:      hooks.PreToolCall,
+		SessionID:  tools.GetSessionFromContext(ctx),
+		WorkingDir: t.cfg.WorkingDir(),
+		ToolName:   call.Name,
+		ToolCallID: call.ID,
+	}
+	if json.Valid([]byte(call.Input)) {
+		event.ToolInput = json.RawMessage(call.Input)
+	}
+	var blocked *hooks.BlockedError
+	if err := hooks.Run(ctx, t.cfg.Hooks, event); errors.As(err, &blocked) {
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("A hook blocked this tool call: %s", blockedReason(blocked))), nil
+	}
+
+	resp, err := t.AgentTool.Run(ctx, call)
+	if err != nil || resp.IsError {
+		return resp, err
+	}
+	var changed []string
+	for _, path := range editedFiles(t.cfg.WorkingDir(), call, resp) {
+		before := modTime(path)
+		event.Event = hooks.PostEdit
+		event.FilePath = path
+		if err := hooks.Run(ctx, t.cfg.Hooks, event); err != nil {
+			slog.Warn("Failed to run the post_edit hooks", "path", path, "error", err)
+		}
+		if !modTime(path).Equal(before) {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) > 0 {
+		resp.Content += fmt.Sprintf("\n\nHooks changed %s after the edit, as formatters do: view the changed files before editing them again.", strings.Join(changed, ", "))
+	}
+	return resp, nil
+}
+
+// blockedReason returns what the hook wrote, or the hook when it wrote nothing.
+func blockedReason(e *hooks.BlockedError) string {
+	if e.Reason == "" {
+		return fmt.Sprintf("%q exited with an error", e.Command)
+	}
+	return e.Reason
+}
+
+// editedFiles returns the files the call of an editing tool changed.
+func editedFiles(workingDir string, call fantasy.ToolCall, resp fantasy.ToolResponse) []string {
+	switch call.Name {
+	case tools.EditToolName, tools.MultiEditToolName, tools.WriteToolName:
+		var params struct {
+			FilePath string `json:"file_path"`
+		}
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil || params.FilePath == "" {
+			return nil
+		}
+		return []string{filepath.Clean(filepathext.SmartJoin(workingDir, params.FilePath))}
+	case tools.PatchToolName:
+		var params tools.PatchParams
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil || params.FilePath == "" || params.DryRun {
+			return nil
+		}
+		return []string{filepath.Clean(filepathext.SmartJoin(workingDir, params.FilePath))}
+	case tools.ReplaceToolName:
+		var metadata tools.ReplaceResponseMetadata
+		if err := json.Unmarshal([]byte(resp.Metadata), &metadata); err != nil || metadata.DryRun {
+			return nil
+		}
+		return metadata.Paths
+	}
+	return nil
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// EndSessions implements Coordinator.
+func (c *coordinator) EndSessions(ctx context.Context) {
+	if len(c.cfg.Hooks.SessionEnd) == 0 {
+		return
+	}
+	for sessionID := range c.prompted.Seq2() {
+		// Sub-agent sessions end with the sessions they belong to.
+		if sess, err := c.sessions.Get(ctx, sessionID); err == nil && sess.ParentSessionID != "" {
+			continue
+		}
+		_ = hooks.Run(ctx, c.cfg.Hooks, hooks.Event{
+			Event:      hooks.SessionEnd,
+			SessionID:  sessionID,
+			WorkingDir: c.cfg.WorkingDir(),
+		})
+	}
+}
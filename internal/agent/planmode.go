@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/plan"
+)
+
+// planModeTools are the tools changing the workspace, held back in plan
+// mode until the plan of the session is approved. MCP tools are held back
+// too, as there's no telling what they change.
+var planModeTools = []string{
+	tools.BashToolName,
+	tools.JobInputToolName,
+	tools.JobKillToolName,
+	tools.DownloadToolName,
+	tools.EditToolName,
+	tools.MultiEditToolName,
+	tools.PatchToolName,
+	tools.ReplaceToolName,
+	tools.WriteToolName,
+}
+
+// plannedTool refuses to run while plan mode is enabled and the session has
+// no approved plan, rather than trusting the agent to wait for one.
+type plannedTool struct {
+	fantasy.AgentTool
+	plans plan.Service
+}
+
+// withPlanMode wraps the tools changing the workspace to enforce plan mode.
+func withPlanMode(plans plan.Service, agentTools []fantasy.AgentTool) []fantasy.AgentTool {
+	wrapped := make([]fantasy.AgentTool, len(agentTools))
+	for i, tool := range agentTools {
+		_, isMCP := tool.(*tools.Tool)
+		if !isMCP && !slices.Contains(planModeTools, tool.Info().Name) {
+			wrapped[i] = tool
+			continue
+		}
+		wrapped[i] = &plannedTool{AgentTool: tool, plans: plans}
+	}
+	return wrapped
+}
+
+func (t *plannedTool) Run(ctx context.Context, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	if !t.plans.Enabled() || t.plans.Approved(tools.GetSessionFromContext(ctx)) || readOnlyCommand(call) {
+		return t.AgentTool.Run(ctx, call)
+	}
+	return fantasy.NewTextErrorResponse("Plan mode is enabled: propose a plan with the plan tool, and wait for the user to approve it, before making any changes."), nil
+}
+
+// readOnlyCommand reports whether call runs a read-only command, which is
+// part of the research done before proposing a plan.
+func readOnlyCommand(call fantasy.ToolCall) bool {
+	if call.Name != tools.BashToolName {
+		return false
+	}
+	var params tools.BashParams
+	return json.Unmarshal([]byte(call.Input), &params) == nil && tools.IsSafeReadOnly(params.Command)
+}
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanMode(t *testing.T) {
+	t.Parallel()
+
+	plans := plan.NewService()
+	plans.SetEnabled(true)
+	write := fantasy.NewAgentTool(tools.WriteToolName, "", func(context.Context, struct{}, fantasy.ToolCall) (fantasy.ToolResponse, error) {
+		return fantasy.NewTextResponse("written"), nil
+	})
+	wrapped := withPlanMode(plans, []fantasy.AgentTool{write})[0]
+	ctx := context.WithValue(t.Context(), tools.SessionIDContextKey, "session")
+	call := fantasy.ToolCall{Name: tools.WriteToolName, Input: "{}"}
+
+	resp, err := wrapped.Run(ctx, call)
+	require.NoError(t, err)
+	require.True(t, resp.IsError, "nothing is written before a plan is approved")
+
+	_, err = plans.Propose(ctx, "session", "call", []string{"write"})
+	require.NoError(t, err)
+	resp, err = wrapped.Run(ctx, call)
+	require.NoError(t, err)
+	require.Equal(t, "written", resp.Content)
+}
+
+func TestPlanModeTools(t *testing.T) {
+	t.Parallel()
+
+	plans := plan.NewService()
+	readOnly := fantasy.NewAgentTool(tools.ViewToolName, "", func(context.Context, struct{}, fantasy.ToolCall) (fantasy.ToolResponse, error) {
+		return fantasy.NewTextResponse(""), nil
+	})
+	wrapped := withPlanMode(plans, []fantasy.AgentTool{readOnly, tools.NewJobKillTool(), &tools.Tool{}})
+	require.Same(t, readOnly, wrapped[0], "read-only tools are left alone")
+	require.IsType(t, &plannedTool{}, wrapped[1], "killing jobs waits for the plan")
+	require.IsType(t, &plannedTool{}, wrapped[2], "MCP tools may change anything")
+}
@@ -0,0 +1,289 @@
+package agent
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/instructions"
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// SectionKind is the kind of a section of a request.
+type SectionKind string
+
+const (
+	SectionSystem       SectionKind = "system"
+	SectionInstructions SectionKind = "instructions"
+	SectionTools        SectionKind = "tools"
+	SectionReminders    SectionKind = "reminders"
+	SectionSummary      SectionKind = "summary"
+	SectionTurn         SectionKind = "turn"
+	SectionAttachment   SectionKind = "attachment"
+	SectionPrompt       SectionKind = "prompt"
+)
+
+// imageTokens is about what an attached image costs, whatever its size.
+const imageTokens = 1500
+
+// Section is a part of the next request of a session, shown for the user to
+// check what gets sent and drop what shouldn't be.
+type Section struct {
+	// ID identifies the section, to drop it.
+	ID    string
+	Kind  SectionKind
+	Title string
+	// Content is the text sent, binary content being described.
+	Content string
+	// Tokens is an estimate of the tokens of the section.
+	Tokens int64
+	// Droppable tells whether the section can be left out.
+	Droppable bool
+}
+
+type droppedSectionsKey struct{}
+
+// WithDroppedSections returns a context leaving the sections with ids out
+// of the request of the run it's given to.
+func WithDroppedSections(ctx context.Context, ids []string) context.Context {
+	if len(ids) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, droppedSectionsKey{}, ids)
+}
+
+func droppedSections(ctx context.Context) []string {
+	ids, _ := ctx.Value(droppedSectionsKey{}).([]string)
+	return ids
+}
+
+// droppedIDs returns the ids of the dropped sections of kind, without the
+// kind.
+func droppedIDs(ctx context.Context, kind SectionKind) []string {
+	var ids []string
+	for _, id := range droppedSections(ctx) {
+		if rest, ok := strings.CutPrefix(id, string(kind)+":"); ok {
+			ids = append(ids, rest)
+		}
+	}
+	return ids
+}
+
+// EstimateTokens estimates the tokens of text, at about four characters a
+// token.
+func EstimateTokens(text string) int64 {
+	return int64(len(text)+3) / 4
+}
+
+// Preview implements Coordinator.
+func (c *coordinator) Preview(ctx context.Context, sessionID, prompt string, attachments ...message.Attachment) ([]Section, error) {
+	if err := c.readyWg.Wait(); err != nil {
+		return nil, err
+	}
+	model := c.currentAgent.Model()
+	files := instructions.Load(c.cfg)
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	system, err := c.prompt.Without(paths...).Build(ctx, model.Model.Provider(), model.Model.Model(), *c.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if providerCfg, ok := c.cfg.Providers.Get(model.ModelCfg.Provider); ok && providerCfg.SystemPromptPrefix != "" {
+		system = providerCfg.SystemPromptPrefix + "\n\n" + system
+	}
+
+	sections := []Section{{
+		ID:      string(SectionSystem),
+		Kind:    SectionSystem,
+		Title:   "System prompt",
+		Content: system,
+		Tokens:  EstimateTokens(system),
+	}}
+	for _, f := range files {
+		sections = append(sections, Section{
+			ID:        string(SectionInstructions) + ":" + f.Path,
+			Kind:      SectionInstructions,
+			Title:     c.cfg.RelPath(f.Path),
+			Content:   f.Content,
+			Tokens:    EstimateTokens(f.Content),
+			Droppable: true,
+		})
+	}
+	if !model.CatwalkCfg.SupportsImages {
+		attachments = slices.DeleteFunc(slices.Clone(attachments), func(att message.Attachment) bool {
+			return !att.IsText()
+		})
+	}
+	rest, err := c.currentAgent.Preview(ctx, SessionAgentCall{
+		SessionID:   sessionID,
+		Prompt:      prompt,
+		Attachments: attachments,
+		PlanMode:    c.plans.Enabled(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(sections, rest...), nil
+}
+
+// Preview returns the sections of the request of call but for the system
+// prompt: the tools, the reminders, the turns of the history, the
+// attachments and the prompt.
+func (a *sessionAgent) Preview(ctx context.Context, call SessionAgentCall) ([]Section, error) {
+	var sections []Section
+
+	var tools strings.Builder
+	var toolTokens int64
+	for _, tool := range a.tools {
+		info := tool.Info()
+		definition, _ := json.Marshal(info)
+		toolTokens += EstimateTokens(string(definition))
+		summary, _, _ := strings.Cut(strings.TrimSpace(info.Description), "\n")
+		fmt.Fprintf(&tools, "%s: %s\n", info.Name, summary)
+	}
+	if len(a.tools) > 0 {
+		sections = append(sections, Section{
+			ID:      string(SectionTools),
+			Kind:    SectionTools,
+			Title:   fmt.Sprintf("%d tools", len(a.tools)),
+			Content: tools.String(),
+			Tokens:  toolTokens,
+		})
+	}
+
+	var reminders []string
+	if !a.isSubAgent {
+		reminders = append(reminders, todoReminder)
+	}
+	if call.PlanMode {
+		reminders = append(reminders, string(planModePrompt))
+	}
+	if len(reminders) > 0 {
+		content := strings.Join(reminders, "\n\n")
+		sections = append(sections, Section{
+			ID:      string(SectionReminders),
+			Kind:    SectionReminders,
+			Title:   "Reminders",
+			Content: content,
+			Tokens:  EstimateTokens(content),
+		})
+	}
+
+	if call.SessionID != "" {
+		currentSession, err := a.sessions.Get(ctx, call.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session: %w", err)
+		}
+		msgs, err := a.getSessionMessages(ctx, currentSession)
+		if err != nil {
+			return nil, err
+		}
+		for _, turn := range turns(msgs) {
+			sections = append(sections, turnSection(turn))
+		}
+	}
+
+	for _, attachment := range call.Attachments {
+		section := Section{
+			ID:        string(SectionAttachment) + ":" + attachment.FilePath,
+			Kind:      SectionAttachment,
+			Title:     cmp.Or(attachment.FilePath, attachment.FileName),
+			Droppable: true,
+		}
+		if attachment.IsText() {
+			section.Content = string(attachment.Content)
+			section.Tokens = EstimateTokens(section.Content)
+		} else {
+			section.Content = fmt.Sprintf("[%s, %d bytes]", attachment.MimeType, len(attachment.Content))
+			section.Tokens = imageTokens
+		}
+		sections = append(sections, section)
+	}
+
+	sections = append(sections, Section{
+		ID:      string(SectionPrompt),
+		Kind:    SectionPrompt,
+		Title:   "Prompt",
+		Content: call.Prompt,
+		Tokens:  EstimateTokens(call.Prompt),
+	})
+	return sections, nil
+}
+
+// turns splits msgs into turns, each starting with a message of the user,
+// so leaving one out keeps the tool calls and their results together.
+func turns(msgs []message.Message) [][]message.Message {
+	var result [][]message.Message
+	for _, msg := range msgs {
+		if msg.Role == message.User || len(result) == 0 {
+			result = append(result, nil)
+		}
+		result[len(result)-1] = append(result[len(result)-1], msg)
+	}
+	return result
+}
+
+// dropTurns returns msgs without the turns starting with the messages with
+// ids.
+func dropTurns(msgs []message.Message, ids []string) []message.Message {
+	if len(ids) == 0 {
+		return msgs
+	}
+	var kept []message.Message
+	for _, turn := range turns(msgs) {
+		if !slices.Contains(ids, turn[0].ID) {
+			kept = append(kept, turn...)
+		}
+	}
+	return kept
+}
+
+// turnSection describes a turn of the history as it's sent.
+func turnSection(turn []message.Message) Section {
+	first := turn[0]
+	section := Section{
+		ID:        string(SectionTurn) + ":" + first.ID,
+		Kind:      SectionTurn,
+		Droppable: true,
+	}
+	if first.IsSummaryMessage {
+		section.Kind = SectionSummary
+		section.Title = "Summary of the earlier conversation"
+	} else {
+		title, _, _ := strings.Cut(strings.TrimSpace(first.Content().Text), "\n")
+		section.Title = cmp.Or(title, "(no text)")
+	}
+
+	var b strings.Builder
+	for _, msg := range turn {
+		fmt.Fprintf(&b, "[%s]\n", msg.Role)
+		if text := msg.Content().Text; text != "" {
+			b.WriteString(text + "\n")
+		}
+		for _, tc := range msg.ToolCalls() {
+			fmt.Fprintf(&b, "%s(%s)\n", tc.Name, tc.Input)
+		}
+		for _, tr := range msg.ToolResults() {
+			b.WriteString(tr.Content + "\n")
+			if tr.Data != "" {
+				section.Tokens += imageTokens
+			}
+		}
+		for _, bin := range msg.BinaryContent() {
+			if strings.HasPrefix(bin.MIMEType, "text/") {
+				fmt.Fprintf(&b, "<file path=%q>\n%s\n</file>\n", bin.Path, bin.Data)
+				continue
+			}
+			fmt.Fprintf(&b, "[%s, %d bytes]\n", bin.MIMEType, len(bin.Data))
+			section.Tokens += imageTokens
+		}
+	}
+	section.Content = b.String()
+	section.Tokens += EstimateTokens(section.Content)
+	return section
+}
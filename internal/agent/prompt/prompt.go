@@ -7,12 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/config"
-	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/instructions"
+	"github.com/charmbracelet/crush/internal/remote"
 	"github.com/charmbracelet/crush/internal/shell"
 )
 
@@ -23,6 +25,8 @@ type Prompt struct {
 	now        func() time.Time
 	platform   string
 	workingDir string
+	// without are the context files left out.
+	without []string
 }
 
 type PromptDat struct {
@@ -30,6 +34,8 @@ type PromptDat struct {
 	Model        string
 	Config       config.Config
 	WorkingDir   string
+	Roots        []string
+	Remote       string
 	IsGitRepo    bool
 	Platform     string
 	Date         string
@@ -74,6 +80,14 @@ func NewPrompt(name, promptTemplate string, opts ...Option) (*Prompt, error) {
 	return p, nil
 }
 
+// Without returns a copy of the prompt leaving out the context files at
+// paths.
+func (p *Prompt) Without(paths ...string) *Prompt {
+	c := *p
+	c.without = append(slices.Clone(p.without), paths...)
+	return &c
+}
+
 func (p *Prompt) Build(ctx context.Context, provider, model string, cfg config.Config) (string, error) {
 	t, err := template.New(p.name).Parse(p.template)
 	if err != nil {
@@ -91,78 +105,17 @@ func (p *Prompt) Build(ctx context.Context, provider, model string, cfg config.C
 	return sb.String(), nil
 }
 
-func processFile(filePath string) *ContextFile {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil
-	}
-	return &ContextFile{
-		Path:    filePath,
-		Content: string(content),
-	}
-}
-
-func processContextPath(p string, cfg config.Config) []ContextFile {
-	var contexts []ContextFile
-	fullPath := p
-	if !filepath.IsAbs(p) {
-		fullPath = filepath.Join(cfg.WorkingDir(), p)
-	}
-	info, err := os.Stat(fullPath)
-	if err != nil {
-		return contexts
-	}
-	if info.IsDir() {
-		filepath.WalkDir(fullPath, func(path string, d os.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			if !d.IsDir() {
-				if result := processFile(path); result != nil {
-					contexts = append(contexts, *result)
-				}
-			}
-			return nil
-		})
-	} else {
-		result := processFile(fullPath)
-		if result != nil {
-			contexts = append(contexts, *result)
-		}
-	}
-	return contexts
-}
-
-// expandPath expands ~ and environment variables in file paths
-func expandPath(path string, cfg config.Config) string {
-	path = home.Long(path)
-	// Handle environment variable expansion using the same pattern as config
-	if strings.HasPrefix(path, "$") {
-		if expanded, err := cfg.Resolver().ResolveValue(path); err == nil {
-			path = expanded
-		}
-	}
-
-	return path
-}
-
 func (p *Prompt) promptData(ctx context.Context, provider, model string, cfg config.Config) (PromptDat, error) {
 	workingDir := cmp.Or(p.workingDir, cfg.WorkingDir())
 	platform := cmp.Or(p.platform, runtime.GOOS)
 
-	files := map[string][]ContextFile{}
-
-	for _, pth := range cfg.Options.ContextPaths {
-		expanded := expandPath(pth, cfg)
-		pathKey := strings.ToLower(expanded)
-		if _, ok := files[pathKey]; ok {
-			continue
-		}
-		content := processContextPath(expanded, cfg)
-		files[pathKey] = content
-	}
-
 	isGit := isGitRepo(cfg.WorkingDir())
+	host := remote.New(&cfg)
+	if host != nil {
+		workingDir = host.Dir
+		_, _, err := host.Run(ctx, host.Dir, "git rev-parse --is-inside-work-tree", nil)
+		isGit = err == nil
+	}
 	data := PromptDat{
 		Provider:   provider,
 		Model:      model,
@@ -172,7 +125,13 @@ func (p *Prompt) promptData(ctx context.Context, provider, model string, cfg con
 		Platform:   platform,
 		Date:       p.now().Format("1/2/2006"),
 	}
-	if isGit {
+	for _, root := range cfg.Roots()[1:] {
+		data.Roots = append(data.Roots, filepath.ToSlash(root))
+	}
+	if host != nil {
+		// The git status of the host is left for the agent to check.
+		data.Remote = host.Target
+	} else if isGit {
 		var err error
 		data.GitStatus, err = getGitStatus(ctx, cfg.WorkingDir())
 		if err != nil {
@@ -180,8 +139,11 @@ func (p *Prompt) promptData(ctx context.Context, provider, model string, cfg con
 		}
 	}
 
-	for _, contextFiles := range files {
-		data.ContextFiles = append(data.ContextFiles, contextFiles...)
+	for _, f := range instructions.Load(&cfg) {
+		if slices.Contains(p.without, f.Path) {
+			continue
+		}
+		data.ContextFiles = append(data.ContextFiles, ContextFile{Path: f.Path, Content: f.Content})
 	}
 	return data, nil
 }
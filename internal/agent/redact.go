@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/redact"
+)
+
+// redactMessages masks the secrets in what is about to be sent for the
+// session, leaving what the model wrote itself as is, and tells about the
+// ones it didn't mask before.
+func (a *sessionAgent) redactMessages(sessionID string, msgs []fantasy.Message) {
+	if a.redactor == nil {
+		return
+	}
+	var findings []redact.Finding
+	redactText := func(text string) string {
+		text, found := a.redactor.Redact(text)
+		findings = append(findings, found...)
+		return text
+	}
+	for i, msg := range msgs {
+		if msg.Role == fantasy.MessageRoleAssistant {
+			continue
+		}
+		for j, part := range msg.Content {
+			switch part := part.(type) {
+			case fantasy.TextPart:
+				part.Text = redactText(part.Text)
+				msgs[i].Content[j] = part
+			case fantasy.FilePart:
+				if strings.HasPrefix(part.MediaType, "text/") {
+					part.Data = []byte(redactText(string(part.Data)))
+					msgs[i].Content[j] = part
+				}
+			case fantasy.ToolResultPart:
+				switch output := part.Output.(type) {
+				case fantasy.ToolResultOutputContentText:
+					output.Text = redactText(output.Text)
+					part.Output = output
+				case fantasy.ToolResultOutputContentMedia:
+					output.Text = redactText(output.Text)
+					part.Output = output
+				case fantasy.ToolResultOutputContentError:
+					if output.Error != nil {
+						if text := redactText(output.Error.Error()); text != output.Error.Error() {
+							output.Error = errors.New(text)
+						}
+					}
+					part.Output = output
+				}
+				msgs[i].Content[j] = part
+			}
+		}
+	}
+	a.reportRedactions(sessionID, findings)
+}
+
+// reportRedactions publishes the secrets masked for the first time in the
+// session, as every request of the session masks them again.
+func (a *sessionAgent) reportRedactions(sessionID string, findings []redact.Finding) {
+	var kinds []string
+	count := 0
+	for _, f := range findings {
+		key := sessionID + "\x00" + f.Secret
+		if _, ok := a.redacted.Get(key); ok {
+			continue
+		}
+		a.redacted.Set(key, struct{}{})
+		count++
+		if !slices.Contains(kinds, f.Kind) {
+			kinds = append(kinds, f.Kind)
+		}
+	}
+	if count == 0 {
+		return
+	}
+	slices.Sort(kinds)
+	redact.Publish(redact.Event{SessionID: sessionID, Kinds: kinds, Count: count})
+}
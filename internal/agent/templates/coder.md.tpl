@@ -342,10 +342,12 @@ Adapt verbosity to match the work completed:
 </final_answers>
 
 <env>
-Working directory: {{.WorkingDir}}
+Working directory: {{.WorkingDir}}{{if .Remote}}
+Remote host: {{.Remote}}, reached over SSH; the tools work on its files{{end}}
 Is directory a git repo: {{if .IsGitRepo}}yes{{else}}no{{end}}
 Platform: {{.Platform}}
-Today's date: {{.Date}}
+Today's date: {{.Date}}{{range .Roots}}
+Other root of the workspace: {{.}}{{end}}
 {{if .GitStatus}}
 
 Git status (snapshot at conversation start - may be outdated):
@@ -363,6 +365,7 @@ Diagnostics (lint/typecheck) included in tool output.
 
 {{if .ContextFiles}}
 <memory>
+Files closer to the working directory come later and take precedence when they disagree. Files in subdirectories are given with the files you view there, and apply to that directory.
 {{range .ContextFiles}}
 <file path="{{.Path}}">
 {{.Content}}
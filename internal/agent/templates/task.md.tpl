@@ -7,9 +7,11 @@ You are an agent for Crush. Given the user's prompt, you should use the tools av
 </rules>
 
 <env>
-Working directory: {{.WorkingDir}}
+Working directory: {{.WorkingDir}}{{if .Remote}}
+Remote host: {{.Remote}}, reached over SSH; the tools work on its files{{end}}
 Is directory a git repo: {{if .IsGitRepo}} yes {{else}} no {{end}}
 Platform: {{.Platform}}
-Today's date: {{.Date}}
+Today's date: {{.Date}}{{range .Roots}}
+Other root of the workspace: {{.}}{{end}}
 </env>
 
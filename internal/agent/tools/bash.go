@@ -15,8 +15,10 @@ import (
 
 	"charm.land/fantasy"
 	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/filepathext"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/shell"
+	"mvdan.cc/sh/v3/syntax"
 )
 
 type BashParams struct {
@@ -198,23 +200,14 @@ func NewBashTool(permissions permission.Service, workingDir string, attribution
 			// Determine working directory
 			execWorkingDir := cmp.Or(params.WorkingDir, workingDir)
 
-			isSafeReadOnly := false
-			cmdLower := strings.ToLower(params.Command)
-
-			for _, safe := range safeCommands {
-				if strings.HasPrefix(cmdLower, safe) {
-					if len(cmdLower) == len(safe) || cmdLower[len(safe)] == ' ' || cmdLower[len(safe)] == '-' {
-						isSafeReadOnly = true
-						break
-					}
-				}
-			}
-
 			sessionID := GetSessionFromContext(ctx)
 			if sessionID == "" {
 				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for executing shell command")
 			}
-			if !isSafeReadOnly {
+			if resp, ok := crushIgnoredArgs(execWorkingDir, params.Command); ok {
+				return resp, nil
+			}
+			if !IsSafeReadOnly(params.Command) {
 				p := permissions.Request(
 					permission.CreatePermissionRequest{
 						SessionID:   sessionID,
@@ -234,6 +227,7 @@ func NewBashTool(permissions permission.Service, workingDir string, attribution
 			// If explicitly requested as background, start immediately with detached context
 			if params.RunInBackground {
 				startTime := time.Now()
+				defer recordCommandWrites(startTime)
 				bgManager := shell.GetBackgroundShellManager()
 				bgManager.Cleanup()
 				// Use background context so it continues after tool returns
@@ -274,6 +268,8 @@ func NewBashTool(permissions permission.Service, workingDir string, attribution
 				}
 
 				// Still running after fast-failure check - return as background job
+				bgShell.NotifyExit()
+				go recordJobWrites(bgShell, startTime)
 				metadata := BashResponseMetadata{
 					StartTime:        startTime.UnixMilli(),
 					EndTime:          time.Now().UnixMilli(),
@@ -288,6 +284,7 @@ func NewBashTool(permissions permission.Service, workingDir string, attribution
 
 			// Start synchronous execution with auto-background support
 			startTime := time.Now()
+			defer recordCommandWrites(startTime)
 
 			// Start with detached context so it can survive if moved to background
 			bgManager := shell.GetBackgroundShellManager()
@@ -355,6 +352,8 @@ func NewBashTool(permissions permission.Service, workingDir string, attribution
 			}
 
 			// Still running - keep as background job
+			bgShell.NotifyExit()
+			go recordJobWrites(bgShell, startTime)
 			metadata := BashResponseMetadata{
 				StartTime:        startTime.UnixMilli(),
 				EndTime:          time.Now().UnixMilli(),
@@ -368,14 +367,71 @@ func NewBashTool(permissions permission.Service, workingDir string, attribution
 		})
 }
 
+// recordJobWrites records the files the job changed as written by the agent
+// once it's done. Until then, they are taken for changes made outside Crush.
+func recordJobWrites(job *shell.BackgroundShell, start time.Time) {
+	job.Wait()
+	recordCommandWrites(start)
+}
+
+// crushIgnoredArgs returns the response refusing to run command when one of
+// its arguments names an existing path a .crushignore excludes, or one of its
+// redirections any such path. Only literal words are checked, against
+// workingDir: paths the command builds or changes into as it runs get
+// through.
+func crushIgnoredArgs(workingDir, command string) (fantasy.ToolResponse, bool) {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return fantasy.ToolResponse{}, false
+	}
+	var resp fantasy.ToolResponse
+	var ignored bool
+	check := func(word *syntax.Word, mustExist bool) {
+		arg := word.Lit()
+		if ignored || arg == "" || strings.HasPrefix(arg, "-") {
+			return
+		}
+		path := filepathext.SmartJoin(workingDir, arg)
+		if _, err := os.Stat(path); err != nil && mustExist {
+			return
+		}
+		resp, ignored = crushIgnored(workingDir, path)
+	}
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.CallExpr:
+			for _, word := range node.Args {
+				check(word, true)
+			}
+		case *syntax.Redirect:
+			if node.Word != nil && node.Op != syntax.Hdoc && node.Op != syntax.DashHdoc {
+				check(node.Word, false)
+			}
+		}
+		return !ignored
+	})
+	return resp, ignored
+}
+
+// IsSafeReadOnly reports whether command is one of the read-only commands
+// run without asking.
+func IsSafeReadOnly(command string) bool {
+	cmdLower := strings.ToLower(command)
+	for _, safe := range safeCommands {
+		if strings.HasPrefix(cmdLower, safe) {
+			if len(cmdLower) == len(safe) || cmdLower[len(safe)] == ' ' || cmdLower[len(safe)] == '-' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // formatOutput formats the output of a completed command with error handling
 func formatOutput(stdout, stderr string, execErr error) string {
 	interrupted := shell.IsInterrupt(execErr)
 	exitCode := shell.ExitCode(execErr)
 
-	stdout = truncateOutput(stdout)
-	stderr = truncateOutput(stderr)
-
 	errorMessage := stderr
 	if errorMessage == "" && execErr != nil {
 		errorMessage = execErr.Error()
@@ -406,19 +462,6 @@ func formatOutput(stdout, stderr string, execErr error) string {
 	return stdout
 }
 
-func truncateOutput(content string) string {
-	if len(content) <= MaxOutputLength {
-		return content
-	}
-
-	halfLength := MaxOutputLength / 2
-	start := content[:halfLength]
-	end := content[len(content)-halfLength:]
-
-	truncatedLinesCount := countLines(content[halfLength : len(content)-halfLength])
-	return fmt.Sprintf("%s\n\n... [%d lines truncated] ...\n\n%s", start, truncatedLinesCount, end)
-}
-
 func countLines(s string) int {
 	if s == "" {
 		return 0
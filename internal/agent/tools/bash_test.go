@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrushIgnoredArgs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".crushignore"), []byte(".env\nsecrets/\n"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "secrets"), 0o755))
+	for _, name := range []string{".env", "main.go", filepath.Join("secrets", "key.pem")} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	for command, want := range map[string]bool{
+		"cat .env":                          true,
+		"cat main.go && cat .env":           true,
+		"head -n 1 secrets/key.pem":         true,
+		"cat " + filepath.Join(dir, ".env"): true,
+		"echo KEY=1 > .env":                 true,
+		"wc -l < secrets/new.pem":           true,
+		"cat main.go":                       false,
+		"grep -r password .":                false,
+		"cat $FILE":                         false,
+		"cat <<EOF\n.env\nEOF":              false,
+	} {
+		_, got := crushIgnoredArgs(dir, command)
+		require.Equal(t, want, got, command)
+	}
+}
@@ -64,6 +64,10 @@ func NewEditTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 
 			params.FilePath = filepathext.SmartJoin(workingDir, params.FilePath)
 
+			if resp, ok := crushIgnored(workingDir, params.FilePath); ok {
+				return resp, nil
+			}
+
 			var response fantasy.ToolResponse
 			var err error
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -21,7 +22,15 @@ const FetchToolName = "fetch"
 //go:embed fetch.md
 var fetchDescription []byte
 
-func NewFetchTool(permissions permission.Service, workingDir string, client *http.Client) fantasy.AgentTool {
+// FetchOptions configures the fetch tool.
+type FetchOptions struct {
+	// Cache keeps fetched pages; nothing is cached when nil.
+	Cache *FetchCache
+	// AllowedDomains are fetched from without asking for permission.
+	AllowedDomains []string
+}
+
+func NewFetchTool(permissions permission.Service, workingDir string, opts FetchOptions, client *http.Client) fantasy.AgentTool {
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
@@ -49,26 +58,36 @@ func NewFetchTool(permissions permission.Service, workingDir string, client *htt
 			if !strings.HasPrefix(params.URL, "http://") && !strings.HasPrefix(params.URL, "https://") {
 				return fantasy.NewTextErrorResponse("URL must start with http:// or https://"), nil
 			}
+			u, err := url.Parse(params.URL)
+			if err != nil {
+				return fantasy.NewTextErrorResponse("Invalid URL: " + err.Error()), nil
+			}
 
 			sessionID := GetSessionFromContext(ctx)
 			if sessionID == "" {
 				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for creating a new file")
 			}
 
-			p := permissions.Request(
-				permission.CreatePermissionRequest{
-					SessionID:   sessionID,
-					Path:        workingDir,
-					ToolCallID:  call.ID,
-					ToolName:    FetchToolName,
-					Action:      "fetch",
-					Description: fmt.Sprintf("Fetch content from URL: %s", params.URL),
-					Params:      FetchPermissionsParams(params),
-				},
-			)
-
-			if !p {
-				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			if !DomainAllowed(u.Hostname(), opts.AllowedDomains) {
+				p := permissions.Request(
+					permission.CreatePermissionRequest{
+						SessionID:   sessionID,
+						Path:        workingDir,
+						ToolCallID:  call.ID,
+						ToolName:    FetchToolName,
+						Action:      "fetch",
+						Description: fmt.Sprintf("Fetch content from URL: %s", params.URL),
+						Params:      FetchPermissionsParams(params),
+					},
+				)
+
+				if !p {
+					return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+				}
+			}
+
+			if content, ok := opts.Cache.Get(params.URL, format); ok {
+				return fantasy.NewTextResponse(truncateFetched(content)), nil
 			}
 
 			// Handle timeout with context
@@ -117,7 +136,7 @@ func NewFetchTool(permissions permission.Service, workingDir string, client *htt
 			switch format {
 			case "text":
 				if strings.Contains(contentType, "text/html") {
-					text, err := extractTextFromHTML(content)
+					text, err := extractTextFromHTML(readable(content))
 					if err != nil {
 						return fantasy.NewTextErrorResponse("Failed to extract text from HTML: " + err.Error()), nil
 					}
@@ -126,7 +145,7 @@ func NewFetchTool(permissions permission.Service, workingDir string, client *htt
 
 			case "markdown":
 				if strings.Contains(contentType, "text/html") {
-					markdown, err := convertHTMLToMarkdown(content)
+					markdown, err := convertHTMLToMarkdown(readable(content))
 					if err != nil {
 						return fantasy.NewTextErrorResponse("Failed to convert HTML to Markdown: " + err.Error()), nil
 					}
@@ -152,17 +171,21 @@ func NewFetchTool(permissions permission.Service, workingDir string, client *htt
 					content = "<html>\n<body>\n" + body + "\n</body>\n</html>"
 				}
 			}
-			// calculate byte size of content
-			contentSize := int64(len(content))
-			if contentSize > MaxReadSize {
-				content = content[:MaxReadSize]
-				content += fmt.Sprintf("\n\n[Content truncated to %d bytes]", MaxReadSize)
-			}
+			_ = opts.Cache.Set(params.URL, format, content)
 
-			return fantasy.NewTextResponse(content), nil
+			return fantasy.NewTextResponse(truncateFetched(content)), nil
 		})
 }
 
+// truncateFetched cuts content longer than MaxReadSize.
+func truncateFetched(content string) string {
+	if int64(len(content)) > MaxReadSize {
+		content = content[:MaxReadSize]
+		content += fmt.Sprintf("\n\n[Content truncated to %d bytes]", MaxReadSize)
+	}
+	return content
+}
+
 func extractTextFromHTML(html string) (string, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
@@ -185,3 +208,16 @@ func convertHTMLToMarkdown(html string) (string, error) {
 
 	return markdown, nil
 }
+
+// DomainAllowed reports whether host is one of the domains, or a subdomain
+// of one.
+func DomainAllowed(host string, domains []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "*."))
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FetchCacheTTL is how long fetched pages are kept.
+const FetchCacheTTL = time.Hour
+
+// FetchCache keeps fetched pages on disk, so fetching them again doesn't
+// download them again until they expire. A nil cache keeps nothing.
+type FetchCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+
+	mu sync.Mutex
+	// pruned is when expired pages were last removed.
+	pruned time.Time
+}
+
+// NewFetchCache returns a cache keeping pages in dir for ttl.
+func NewFetchCache(dir string, ttl time.Duration) *FetchCache {
+	return &FetchCache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+func (c *FetchCache) path(url, format string) string {
+	sum := sha256.Sum256([]byte(format + "\n" + url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get returns the cached content of url in format, when it hasn't expired.
+func (c *FetchCache) Get(url, format string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	path := c.path(url, format)
+	info, err := os.Stat(path)
+	if err != nil || c.now().Sub(info.ModTime()) > c.ttl {
+		return "", false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// Set caches the content of url in format.
+func (c *FetchCache) Set(url, format, content string) error {
+	if c == nil {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	c.prune()
+	path := c.path(url, format)
+	// Written aside and renamed, so a concurrent Get never reads half of it;
+	// each write has its own file, so concurrent Sets don't mix either.
+	tmp, err := os.CreateTemp(c.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// prune removes the pages that expired, and the temporary files of writes
+// that never finished, at most once per ttl.
+func (c *FetchCache) prune() {
+	now := c.now()
+	c.mu.Lock()
+	if now.Sub(c.pruned) < c.ttl {
+		c.mu.Unlock()
+		return
+	}
+	c.pruned = now
+	c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && info.Mode().IsRegular() && now.Sub(info.ModTime()) > c.ttl {
+			_ = os.Remove(filepath.Join(c.dir, entry.Name()))
+		}
+	}
+}
+
+// Fetch returns the main content of the page at url as Markdown, from the
+// cache when it's there.
+func (c *FetchCache) Fetch(ctx context.Context, client *http.Client, url string) (string, error) {
+	if content, ok := c.Get(url, "readable"); ok {
+		return content, nil
+	}
+	content, err := FetchURLAndConvert(ctx, client, url)
+	if err != nil {
+		return "", err
+	}
+	_ = c.Set(url, "readable", content)
+	return content, nil
+}
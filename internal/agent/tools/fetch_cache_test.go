@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	c := NewFetchCache(t.TempDir(), time.Hour)
+	c.now = func() time.Time { return now }
+
+	_, ok := c.Get("https://example.com", "markdown")
+	require.False(t, ok)
+
+	require.NoError(t, c.Set("https://example.com", "markdown", "# Example"))
+	content, ok := c.Get("https://example.com", "markdown")
+	require.True(t, ok)
+	require.Equal(t, "# Example", content)
+
+	_, ok = c.Get("https://example.com", "text")
+	require.False(t, ok, "formats are cached apart")
+
+	now = now.Add(2 * time.Hour)
+	_, ok = c.Get("https://example.com", "markdown")
+	require.False(t, ok, "expired pages are fetched again")
+
+	var nilCache *FetchCache
+	require.NoError(t, nilCache.Set("https://example.com", "markdown", "# Example"))
+	_, ok = nilCache.Get("https://example.com", "markdown")
+	require.False(t, ok)
+}
+
+func TestFetchCacheConcurrentSet(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c := NewFetchCache(dir, time.Hour)
+	contents := []string{strings.Repeat("a", 1<<16), strings.Repeat("b", 1<<16)}
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			require.NoError(t, c.Set("https://example.com", "markdown", contents[i%2]))
+		})
+	}
+	wg.Wait()
+
+	content, ok := c.Get("https://example.com", "markdown")
+	require.True(t, ok)
+	require.Contains(t, contents, content, "one write wins whole")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestReadable(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("The main content of the page. ", 10)
+	page := `<html><body>
+<nav><a href="/">Home</a></nav>
+<div class="sidebar"><p>Related links</p></div>
+<div class="content"><p>` + text + `</p><p>` + text + `</p></div>
+<script>track()</script>
+</body></html>`
+	got := readable(page)
+	require.Contains(t, got, `<div class="content">`)
+	require.NotContains(t, got, "Related links")
+	require.NotContains(t, got, "Home")
+
+	article := `<html><body><p>Intro</p><article><h1>Title</h1><p>` + text + `</p></article></body></html>`
+	require.True(t, strings.HasPrefix(readable(article), "<article>"))
+
+	short := `<html><body><p>Short page</p></body></html>`
+	require.Contains(t, readable(short), "Short page")
+}
+
+func TestFetchCachePrune(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Now()
+	c := NewFetchCache(dir, time.Hour)
+	c.now = func() time.Time { return now }
+
+	require.NoError(t, c.Set("https://example.com/old", "markdown", "old"))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(c.path("https://example.com/old", "markdown"), old, old))
+
+	require.NoError(t, c.Set("https://example.com/new", "markdown", "new"))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "expired pages wait a ttl after the last pruning")
+
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, c.Set("https://example.com/newer", "markdown", "newer"))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "expired pages are removed")
+	require.Equal(t, filepath.Base(c.path("https://example.com/newer", "markdown")), entries[0].Name())
+}
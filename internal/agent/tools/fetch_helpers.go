@@ -13,6 +13,7 @@ import (
 	"unicode/utf8"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
 )
 
@@ -57,9 +58,8 @@ func FetchURLAndConvert(ctx context.Context, client *http.Client, url string) (s
 
 	// Convert HTML to markdown for better AI processing.
 	if strings.Contains(contentType, "text/html") {
-		// Remove noisy elements before conversion.
-		cleanedHTML := removeNoisyElements(content)
-		markdown, err := ConvertHTMLToMarkdown(cleanedHTML)
+		// Keep only the main content before conversion.
+		markdown, err := ConvertHTMLToMarkdown(readable(content))
 		if err != nil {
 			return "", fmt.Errorf("failed to convert HTML to markdown: %w", err)
 		}
@@ -125,6 +125,59 @@ func removeNoisyElements(htmlContent string) string {
 	return buf.String()
 }
 
+// minReadableText is the length of text an element needs to be taken as the
+// main content of a page.
+const minReadableText = 200
+
+// readable returns the HTML of the main content of a page, without
+// navigation, scripts and the like: its article or main element, or else
+// the element holding the most paragraph text. The whole page is returned
+// when neither has enough text.
+func readable(htmlContent string) string {
+	cleaned := removeNoisyElements(htmlContent)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(cleaned))
+	if err != nil {
+		return cleaned
+	}
+	for _, selector := range []string{"article", "main", "[role=main]"} {
+		sel := doc.Find(selector).First()
+		if sel.Length() == 0 || len(strings.TrimSpace(sel.Text())) < minReadableText {
+			continue
+		}
+		if main, err := goquery.OuterHtml(sel); err == nil {
+			return main
+		}
+	}
+
+	var parents []*html.Node
+	scores := make(map[*html.Node]int)
+	doc.Find("p").Each(func(_ int, p *goquery.Selection) {
+		parent := p.Parent()
+		if parent.Length() == 0 {
+			return
+		}
+		node := parent.Get(0)
+		if _, ok := scores[node]; !ok {
+			parents = append(parents, node)
+		}
+		scores[node] += len(strings.TrimSpace(p.Text()))
+	})
+	var best *html.Node
+	for _, node := range parents {
+		if best == nil || scores[node] > scores[best] {
+			best = node
+		}
+	}
+	if best == nil || scores[best] < minReadableText {
+		return cleaned
+	}
+	main, err := goquery.OuterHtml(goquery.NewDocumentFromNode(best).Selection)
+	if err != nil {
+		return cleaned
+	}
+	return main
+}
+
 // cleanupMarkdown removes excessive whitespace and blank lines from markdown.
 func cleanupMarkdown(content string) string {
 	// Collapse multiple blank lines into at most two.
@@ -0,0 +1,20 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainAllowed(t *testing.T) {
+	t.Parallel()
+
+	domains := []string{"go.dev", "*.github.com"}
+	require.True(t, DomainAllowed("go.dev", domains))
+	require.True(t, DomainAllowed("pkg.go.dev", domains))
+	require.True(t, DomainAllowed("GITHUB.com.", domains))
+	require.True(t, DomainAllowed("api.github.com", domains))
+	require.False(t, DomainAllowed("notgo.dev", domains))
+	require.False(t, DomainAllowed("go.dev.example.com", domains))
+	require.False(t, DomainAllowed("go.dev", nil))
+}
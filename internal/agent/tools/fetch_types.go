@@ -6,7 +6,7 @@ const AgenticFetchToolName = "agentic_fetch"
 // WebFetchToolName is the name of the web_fetch tool.
 const WebFetchToolName = "web_fetch"
 
-// WebSearchToolName is the name of the web_search tool for sub-agents.
+// WebSearchToolName is the name of the web_search tool.
 const WebSearchToolName = "web_search"
 
 // LargeContentThreshold is the size threshold for saving content to a file.
@@ -48,3 +48,18 @@ type FetchPermissionsParams struct {
 	Format  string `json:"format"`
 	Timeout int    `json:"timeout,omitempty"`
 }
+
+// WebSearchPermissionsParams defines the permission parameters for the web_search tool.
+type WebSearchPermissionsParams struct {
+	Query    string `json:"query"`
+	Provider string `json:"provider"`
+}
+
+// WebSearchResponseMetadata is the metadata of the response of the web_search tool.
+type WebSearchResponseMetadata struct {
+	Provider string         `json:"provider"`
+	Query    string         `json:"query"`
+	Results  []SearchResult `json:"results"`
+	// Filtered is the number of results dropped by the domain rules.
+	Filtered int `json:"filtered,omitempty"`
+}
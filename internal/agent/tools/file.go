@@ -1,8 +1,18 @@
 package tools
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/instructions"
 )
 
 // File record to track when files were read/written
@@ -51,3 +61,79 @@ func recordFileWrite(path string) {
 	record.writeTime = time.Now()
 	fileRecords[path] = record
 }
+
+// recordCommandWrites records the files the agent has read that changed
+// since start as written by it, e.g. by a command it ran then. A change made
+// outside Crush while the command ran is taken for the agent's too.
+func recordCommandWrites(start time.Time) {
+	fileRecordMutex.Lock()
+	defer fileRecordMutex.Unlock()
+
+	now := time.Now()
+	for path, record := range fileRecords {
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Before(start) {
+			continue
+		}
+		record.writeTime = now
+		fileRecords[path] = record
+	}
+}
+
+// ReadFiles returns the files the agent has read, with the time each one was
+// last read. Files written by the agent, with its tools or its commands,
+// count as read at the time of writing.
+func ReadFiles() map[string]time.Time {
+	fileRecordMutex.RLock()
+	defer fileRecordMutex.RUnlock()
+
+	files := make(map[string]time.Time, len(fileRecords))
+	for path, record := range fileRecords {
+		if !record.readTime.IsZero() {
+			files[path] = maxTime(record.readTime, record.writeTime)
+		}
+	}
+	return files
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// crushIgnored returns the response refusing to touch path when a
+// .crushignore excludes it.
+func crushIgnored(workingDir, path string) (fantasy.ToolResponse, bool) {
+	root := workingDir
+	if cfg := config.Get(); cfg != nil {
+		root = cfg.RootOf(path)
+	}
+	if !fsext.CrushIgnored(root, path) {
+		return fantasy.ToolResponse{}, false
+	}
+	return fantasy.NewTextErrorResponse(fmt.Sprintf("%s is excluded by a .crushignore file, and can't be read or changed", path)), true
+}
+
+// nestedInstructions returns the instruction files of the subdirectories
+// path is in that the session wasn't given yet, for the agent to follow
+// them there.
+func nestedInstructions(ctx context.Context, workingDir, path string) string {
+	cfg := config.Get()
+	if cfg == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, f := range instructions.Deliver(GetSessionFromContext(ctx), cfg.RootOf(path), path, instructions.Names(cfg.Options.ContextPaths)) {
+		rel, err := filepath.Rel(workingDir, f.Path)
+		if err != nil {
+			rel = f.Path
+		}
+		fmt.Fprintf(&sb, "\n<instructions path=%q>\n%s\n</instructions>\n", filepath.ToSlash(rel), strings.TrimSpace(f.Content))
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return sb.String() + "Follow these instructions for the files in their directory, over the ones of your memory.\n"
+}
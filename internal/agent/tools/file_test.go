@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordCommandWrites(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "changed.go")
+	untouched := filepath.Join(dir, "untouched.go")
+	past := time.Now().Add(-time.Hour)
+	for _, path := range []string{changed, untouched} {
+		require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0o644))
+		require.NoError(t, os.Chtimes(path, past, past))
+		recordFileRead(path)
+	}
+	readTime := ReadFiles()[untouched]
+
+	start := time.Now()
+	require.NoError(t, os.WriteFile(changed, []byte("package main\n\nfunc main() {}\n"), 0o644))
+	recordCommandWrites(start)
+
+	info, err := os.Stat(changed)
+	require.NoError(t, err)
+	files := ReadFiles()
+	require.False(t, files[changed].Before(info.ModTime()))
+	require.Equal(t, readTime, files[untouched])
+}
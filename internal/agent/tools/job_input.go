@@ -0,0 +1,226 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+const (
+	JobInputToolName = "job_input"
+
+	// defaultInputWait and maxInputWait bound how long job_input waits for
+	// the output of the input.
+	defaultInputWait = 3 * time.Second
+	maxInputWait     = time.Minute
+	// settleTime is how long the output has to stay the same to be
+	// considered complete.
+	settleTime = 500 * time.Millisecond
+)
+
+//go:embed job_input.md
+var jobInputDescription []byte
+
+type JobInputParams struct {
+	ShellID     string `json:"shell_id,omitempty" description:"The ID of the interactive background shell to send input to"`
+	Command     string `json:"command,omitempty" description:"The command to start as an interactive background shell, instead of giving shell_id"`
+	Description string `json:"description,omitempty" description:"A brief description of what the command does, when starting one"`
+	WorkingDir  string `json:"working_dir,omitempty" description:"The working directory to start the command in (defaults to current directory)"`
+	Input       string `json:"input,omitempty" description:"The input to send, followed by a newline"`
+	CloseInput  bool   `json:"close_input,omitempty" description:"Set to true (boolean) to close the input after sending it, like ctrl+d"`
+	WaitFor     string `json:"wait_for,omitempty" description:"A regular expression to wait for in the output, like a prompt, instead of waiting for the output to settle"`
+	WaitSeconds int    `json:"wait_seconds,omitempty" description:"How long to wait for the output at most, 3 seconds by default, 60 at most"`
+}
+
+type JobInputResponseMetadata struct {
+	ShellID     string `json:"shell_id"`
+	Command     string `json:"command"`
+	Description string `json:"description"`
+	Done        bool   `json:"done"`
+}
+
+func NewJobInputTool(permissions permission.Service, workingDir string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		JobInputToolName,
+		string(jobInputDescription),
+		func(ctx context.Context, params JobInputParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.ShellID == "" && params.Command == "" {
+				return fantasy.NewTextErrorResponse("missing shell_id or command"), nil
+			}
+			if params.ShellID != "" && params.Input == "" && !params.CloseInput {
+				return fantasy.NewTextErrorResponse("missing input"), nil
+			}
+			var waitFor *regexp.Regexp
+			if params.WaitFor != "" {
+				var err error
+				if waitFor, err = regexp.Compile(params.WaitFor); err != nil {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("invalid wait_for: %s", err)), nil
+				}
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for sending input to a job")
+			}
+
+			if params.ShellID == "" {
+				return startInteractive(ctx, permissions, cmp.Or(params.WorkingDir, workingDir), sessionID, params, waitFor, call)
+			}
+
+			bgShell, ok := shell.GetBackgroundShellManager().Get(params.ShellID)
+			if !ok {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("background shell not found: %s", params.ShellID)), nil
+			}
+			if !bgShell.Interactive() {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("background shell %s doesn't take input, start it with job_input", params.ShellID)), nil
+			}
+			// The input runs in whatever the job is, a shell over ssh as
+			// much as a REPL, so it's asked for like a command.
+			description := fmt.Sprintf("Send to job %s (%s): %s", bgShell.ID, bgShell.Command, params.Input)
+			if params.CloseInput {
+				description += " and close its input"
+			}
+			p := permissions.Request(
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        bgShell.WorkingDir,
+					ToolCallID:  call.ID,
+					ToolName:    JobInputToolName,
+					Action:      "execute",
+					Description: description,
+				},
+			)
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			stdout, stderr, _, _ := bgShell.GetOutput()
+			stdoutLen, stderrLen := len(stdout), len(stderr)
+
+			if params.Input != "" {
+				input := params.Input
+				if !strings.HasSuffix(input, "\n") {
+					input += "\n"
+				}
+				if err := bgShell.Send(input); err != nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
+				}
+			}
+			if params.CloseInput {
+				if err := bgShell.CloseInput(); err != nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
+				}
+			}
+
+			return respond(ctx, bgShell, waitFor, params.WaitSeconds, stdoutLen, stderrLen), nil
+		})
+}
+
+// startInteractive starts the command of params as an interactive
+// background shell, asking for it like bash does, and returns what it
+// writes first, a banner or a prompt usually.
+func startInteractive(ctx context.Context, permissions permission.Service, execWorkingDir, sessionID string, params JobInputParams, waitFor *regexp.Regexp, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	if !IsSafeReadOnly(params.Command) {
+		p := permissions.Request(
+			permission.CreatePermissionRequest{
+				SessionID:   sessionID,
+				Path:        execWorkingDir,
+				ToolCallID:  call.ID,
+				ToolName:    JobInputToolName,
+				Action:      "execute",
+				Description: fmt.Sprintf("Start interactive command: %s", params.Command),
+			},
+		)
+		if !p {
+			return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+		}
+	}
+
+	bgManager := shell.GetBackgroundShellManager()
+	bgManager.Cleanup()
+	// Use background context so it continues after tool returns
+	bgShell, err := bgManager.StartInteractive(context.Background(), execWorkingDir, blockFuncs(), params.Command, params.Description)
+	if err != nil {
+		return fantasy.ToolResponse{}, fmt.Errorf("error starting interactive shell: %w", err)
+	}
+	return respond(ctx, bgShell, waitFor, params.WaitSeconds, 0, 0), nil
+}
+
+// respond waits for the output of the shell written past the given lengths,
+// until waitFor matches it when given, and returns it with the status of the
+// shell.
+func respond(ctx context.Context, bgShell *shell.BackgroundShell, waitFor *regexp.Regexp, waitSeconds, stdoutLen, stderrLen int) fantasy.ToolResponse {
+	wait := defaultInputWait
+	if waitSeconds > 0 {
+		wait = min(time.Duration(waitSeconds)*time.Second, maxInputWait)
+	}
+	var note string
+	if waitFor != nil {
+		waitCtx, cancel := context.WithTimeout(ctx, wait)
+		if _, err := bgShell.WaitFor(waitCtx, waitFor, stdoutLen, stderrLen); errors.Is(err, context.DeadlineExceeded) {
+			note = fmt.Sprintf("\n\n%q wasn't written within %s", waitFor, wait)
+		}
+		cancel()
+	} else {
+		waitForOutput(ctx, bgShell, wait)
+	}
+	done := bgShell.IsDone()
+
+	stdout, stderr, _, execErr := bgShell.GetOutput()
+	output := strings.TrimRight(formatOutput(stdout[min(stdoutLen, len(stdout)):], stderr[min(stderrLen, len(stderr)):], nil), "\n")
+	if output == "" {
+		output = BashNoOutput
+	}
+	status := "running"
+	if done {
+		status = "completed"
+		if code := shell.ExitCode(execErr); code != 0 {
+			status = fmt.Sprintf("exited with code %d", code)
+		}
+	}
+
+	metadata := JobInputResponseMetadata{
+		ShellID:     bgShell.ID,
+		Command:     bgShell.Command,
+		Description: bgShell.Description,
+		Done:        done,
+	}
+	result := fmt.Sprintf("Shell ID: %s\nStatus: %s\n\n%s%s", bgShell.ID, status, output, note)
+	return fantasy.WithResponseMetadata(fantasy.NewTextResponse(result), metadata)
+}
+
+// waitForOutput waits until the output of the shell stops changing for
+// settleTime, the shell exits or wait passes.
+func waitForOutput(ctx context.Context, bgShell *shell.BackgroundShell, wait time.Duration) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(wait)
+
+	stdout, stderr, done, _ := bgShell.GetOutput()
+	initial := len(stdout) + len(stderr)
+	size, changed := initial, time.Now()
+	for !done {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			return
+		case <-ticker.C:
+		}
+		stdout, stderr, done, _ = bgShell.GetOutput()
+		if n := len(stdout) + len(stderr); n != size {
+			size, changed = n, time.Now()
+		} else if size != initial && time.Since(changed) >= settleTime {
+			return
+		}
+	}
+}
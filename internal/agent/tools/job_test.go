@@ -2,9 +2,13 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/shell"
 	"github.com/stretchr/testify/require"
 )
@@ -327,3 +331,51 @@ func TestBackgroundShell_AutoBackground(t *testing.T) {
 		require.Equal(t, bgShell.ID, retrieved.ID)
 	})
 }
+
+func TestJobInput(t *testing.T) {
+	t.Parallel()
+
+	tool := NewJobInputTool(&mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()}, t.TempDir())
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "session")
+	run := func(params JobInputParams) (fantasy.ToolResponse, JobInputResponseMetadata) {
+		t.Helper()
+		input, err := json.Marshal(params)
+		require.NoError(t, err)
+		resp, err := tool.Run(ctx, fantasy.ToolCall{ID: "call", Name: JobInputToolName, Input: string(input)})
+		require.NoError(t, err)
+		var meta JobInputResponseMetadata
+		if resp.Metadata != "" {
+			require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+		}
+		return resp, meta
+	}
+
+	resp, meta := run(JobInputParams{Command: `echo ready; while read line; do echo "= $line"; done`, WaitSeconds: 1})
+	require.False(t, resp.IsError, resp.Content)
+	require.NotEmpty(t, meta.ShellID)
+	defer shell.GetBackgroundShellManager().Kill(meta.ShellID)
+	require.Equal(t, "Shell ID: "+meta.ShellID+"\nStatus: running\n\nready", resp.Content)
+
+	resp, _ = run(JobInputParams{ShellID: meta.ShellID, Input: "1+1"})
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, "Shell ID: "+meta.ShellID+"\nStatus: running\n\n= 1+1", resp.Content)
+
+	resp, _ = run(JobInputParams{ShellID: meta.ShellID, Input: "slow", WaitFor: `= \w+`, WaitSeconds: 5})
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, "Shell ID: "+meta.ShellID+"\nStatus: running\n\n= slow", resp.Content)
+
+	resp, _ = run(JobInputParams{ShellID: meta.ShellID, Input: "quiet", WaitFor: `never`, WaitSeconds: 1})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, `"never" wasn't written within 1s`)
+
+	resp, _ = run(JobInputParams{ShellID: meta.ShellID, Input: "bad", WaitFor: `(`})
+	require.True(t, resp.IsError)
+
+	resp, meta = run(JobInputParams{ShellID: meta.ShellID, Input: "again", CloseInput: true})
+	require.False(t, resp.IsError, resp.Content)
+	require.True(t, meta.Done)
+	require.Equal(t, "Shell ID: "+meta.ShellID+"\nStatus: completed\n\n= again", resp.Content, "only the output of the input is returned")
+
+	resp, _ = run(JobInputParams{ShellID: meta.ShellID, Input: "late"})
+	require.True(t, resp.IsError)
+}
@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/keyring"
+	"github.com/charmbracelet/crush/internal/oauth/mcpauth"
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+// ErrAuthRequired is returned when an MCP server rejects the request
+// because the user has not authorized Crush yet, or the token was revoked.
+var ErrAuthRequired = errors.New("authorization required")
+
+const keyringService = "crush-mcp"
+
+var (
+	credentialsOnce sync.Once
+	credentials     keyring.Keyring
+
+	// cachedCredentials avoids hitting the keyring on every request. A nil
+	// value means the server has no stored credentials.
+	cachedCredentials = csync.NewMap[string, *mcpauth.Credentials]()
+)
+
+func credentialStore() keyring.Keyring {
+	credentialsOnce.Do(func() {
+		fallback := filepath.Join(filepath.Dir(config.GlobalConfigData()), "mcp-credentials.json")
+		credentials = keyring.New(keyringService, fallback)
+	})
+	return credentials
+}
+
+// Authorize runs the OAuth flow for the given MCP server, calling open with
+// the URL the user needs to visit. On success the credentials are stored in
+// the keyring and the server is restarted.
+func Authorize(ctx context.Context, name string, open func(string) error) error {
+	m, ok := config.Get().MCP[name]
+	if !ok {
+		return fmt.Errorf("mcp '%s' not configured", name)
+	}
+	if m.Type == config.MCPStdio {
+		return fmt.Errorf("mcp '%s' does not use http and cannot be authorized", name)
+	}
+
+	var client mcpauth.Client
+	if m.OAuth != nil {
+		client = mcpauth.Client{
+			ClientID:         m.OAuth.ClientID,
+			ClientSecret:     m.OAuth.ClientSecret,
+			AuthorizationURL: m.OAuth.AuthorizationURL,
+			TokenURL:         m.OAuth.TokenURL,
+			Scopes:           m.OAuth.Scopes,
+		}
+	}
+
+	appendLog(name, "info", "starting authorization")
+	creds, err := mcpauth.Authorize(ctx, m.URL, client, open)
+	if err != nil {
+		appendLog(name, "error", "authorization failed: %v", err)
+		return err
+	}
+	if err := saveCredentials(name, creds); err != nil {
+		return err
+	}
+	return Restart(ctx, name)
+}
+
+// Logout removes the stored credentials for the given MCP server.
+func Logout(name string) error {
+	cachedCredentials.Del(name)
+	if err := credentialStore().Delete(name); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// HasCredentials reports whether credentials are stored for the given MCP
+// server.
+func HasCredentials(name string) bool {
+	creds, ok := cachedCredentials.Get(name)
+	if !ok {
+		creds = readCredentials(name)
+		cachedCredentials.Set(name, creds)
+	}
+	return creds != nil
+}
+
+func saveCredentials(name string, creds *mcpauth.Credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	cachedCredentials.Set(name, creds)
+	return credentialStore().Set(name, string(data))
+}
+
+// loadCredentials returns the stored credentials for the given server,
+// refreshing the access token when it is about to expire.
+func loadCredentials(ctx context.Context, name string) (*mcpauth.Credentials, error) {
+	cached, ok := cachedCredentials.Get(name)
+	if !ok {
+		cached = readCredentials(name)
+		cachedCredentials.Set(name, cached)
+	}
+	if cached == nil {
+		return nil, keyring.ErrNotFound
+	}
+	creds := *cached
+	if !creds.NeedsRefresh() || creds.Token.RefreshToken == "" {
+		return &creds, nil
+	}
+
+	token, err := creds.Client.RefreshToken(ctx, creds.Token.RefreshToken)
+	if err != nil {
+		appendLog(name, "warn", "refreshing token: %v", err)
+		return nil, err
+	}
+	creds.Token = token
+	if err := saveCredentials(name, &creds); err != nil {
+		slog.Warn("Failed to save refreshed mcp token", "error", err, "name", name)
+	}
+	return &creds, nil
+}
+
+func readCredentials(name string) *mcpauth.Credentials {
+	data, err := credentialStore().Get(name)
+	if err != nil {
+		return nil
+	}
+	var creds mcpauth.Credentials
+	if err := json.Unmarshal([]byte(data), &creds); err != nil || creds.Token == nil {
+		slog.Warn("Ignoring invalid mcp credentials", "error", err, "name", name)
+		return nil
+	}
+	return &creds
+}
+
+// authRoundTripper adds the stored bearer token to requests and asks the
+// user to authorize when the server answers with 401 Unauthorized.
+type authRoundTripper struct {
+	name string
+	next http.RoundTripper
+}
+
+func (rt authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if creds, err := loadCredentials(req.Context(), rt.name); err == nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+creds.Token.AccessToken)
+	}
+	resp, err := rt.next.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		appendLog(rt.name, "warn", "server requires authorization")
+		broker.Publish(pubsub.UpdatedEvent, Event{
+			Type:  EventAuthRequired,
+			Name:  rt.name,
+			Error: ErrAuthRequired,
+		})
+	}
+	return resp, err
+}
@@ -64,6 +64,7 @@ const (
 	EventStateChanged EventType = iota
 	EventToolsListChanged
 	EventPromptsListChanged
+	EventAuthRequired
 )
 
 // Event represents an event in the MCP system
@@ -77,8 +78,9 @@ type Event struct {
 
 // Counts number of available tools, prompts, etc.
 type Counts struct {
-	Tools   int
-	Prompts int
+	Tools     int
+	Prompts   int
+	Resources int
 }
 
 // ClientInfo holds information about an MCP client's state
@@ -149,56 +151,117 @@ func Initialize(ctx context.Context, permissions permission.Service, cfg *config
 
 		wg.Add(1)
 		go func(name string, m config.MCPConfig) {
-			defer func() {
-				wg.Done()
-				if r := recover(); r != nil {
-					var err error
-					switch v := r.(type) {
-					case error:
-						err = v
-					case string:
-						err = fmt.Errorf("panic: %s", v)
-					default:
-						err = fmt.Errorf("panic: %v", v)
-					}
-					updateState(name, StateError, err, nil, Counts{})
-					slog.Error("panic in mcp client initialization", "error", err, "name", name)
-				}
-			}()
+			defer wg.Done()
+			initClient(ctx, name, m, cfg.Resolver())
+		}(name, m)
+	}
+	wg.Wait()
+}
 
-			// createSession handles its own timeout internally.
-			session, err := createSession(ctx, name, m, cfg.Resolver())
-			if err != nil {
-				return
+// initClient connects to the given MCP server and loads its tools, prompts,
+// and resources.
+func initClient(ctx context.Context, name string, m config.MCPConfig, resolver config.VariableResolver) {
+	defer func() {
+		if r := recover(); r != nil {
+			var err error
+			switch v := r.(type) {
+			case error:
+				err = v
+			case string:
+				err = fmt.Errorf("panic: %s", v)
+			default:
+				err = fmt.Errorf("panic: %v", v)
 			}
+			updateState(name, StateError, err, nil, Counts{})
+			slog.Error("panic in mcp client initialization", "error", err, "name", name)
+		}
+	}()
 
-			tools, err := getTools(ctx, session)
-			if err != nil {
-				slog.Error("error listing tools", "error", err)
-				updateState(name, StateError, err, nil, Counts{})
-				session.Close()
-				return
-			}
+	// createSession handles its own timeout internally.
+	session, err := createSession(ctx, name, m, resolver)
+	if err != nil {
+		return
+	}
 
-			prompts, err := getPrompts(ctx, session)
-			if err != nil {
-				slog.Error("error listing prompts", "error", err)
-				updateState(name, StateError, err, nil, Counts{})
-				session.Close()
-				return
-			}
+	tools, err := getTools(ctx, session)
+	if err != nil {
+		slog.Error("error listing tools", "error", err)
+		updateState(name, StateError, err, nil, Counts{})
+		session.Close()
+		return
+	}
 
-			updateTools(name, tools)
-			updatePrompts(name, prompts)
-			sessions.Set(name, session)
+	prompts, err := getPrompts(ctx, session)
+	if err != nil {
+		slog.Error("error listing prompts", "error", err)
+		updateState(name, StateError, err, nil, Counts{})
+		session.Close()
+		return
+	}
 
-			updateState(name, StateConnected, nil, session, Counts{
-				Tools:   len(tools),
-				Prompts: len(prompts),
-			})
-		}(name, m)
+	resources, err := getResources(ctx, session)
+	if err != nil {
+		// Resources are optional, so don't fail the whole client.
+		slog.Warn("error listing resources", "error", err, "name", name)
+		appendLog(name, "warn", "listing resources: %v", err)
 	}
-	wg.Wait()
+	templates, err := getResourceTemplates(ctx, session)
+	if err != nil {
+		slog.Warn("error listing resource templates", "error", err, "name", name)
+		appendLog(name, "warn", "listing resource templates: %v", err)
+	}
+
+	updateTools(name, tools)
+	updatePrompts(name, prompts)
+	updateResources(name, resources)
+	updateResourceTemplates(name, templates)
+	sessions.Set(name, session)
+
+	updateState(name, StateConnected, nil, session, Counts{
+		Tools:     len(tools),
+		Prompts:   len(prompts),
+		Resources: len(resources),
+	})
+}
+
+// Restart closes the connection to the given MCP server, if any, and
+// connects to it again using the current configuration.
+func Restart(ctx context.Context, name string) error {
+	cfg := config.Get()
+	m, ok := cfg.MCP[name]
+	if !ok {
+		return fmt.Errorf("mcp '%s' not configured", name)
+	}
+	closeClient(name)
+	if m.Disabled {
+		updateState(name, StateDisabled, nil, nil, Counts{})
+		return nil
+	}
+	updateState(name, StateStarting, nil, nil, Counts{})
+	initClient(ctx, name, m, cfg.Resolver())
+	if state, ok := states.Get(name); ok && state.State == StateError {
+		return state.Error
+	}
+	return nil
+}
+
+// Disable closes the connection to the given MCP server and removes its
+// tools, prompts, and resources.
+func Disable(name string) {
+	closeClient(name)
+	updateState(name, StateDisabled, nil, nil, Counts{})
+}
+
+func closeClient(name string) {
+	if session, ok := sessions.Take(name); ok {
+		if err := session.Close(); err != nil {
+			slog.Debug("error closing mcp client", "error", err, "name", name)
+		}
+	}
+	updateTools(name, nil)
+	updatePrompts(name, nil)
+	updateResources(name, nil)
+	updateResourceTemplates(name, nil)
 }
 
 func getOrRenewClient(ctx context.Context, name string) (*mcp.ClientSession, error) {
@@ -247,6 +310,12 @@ func updateState(name string, state State, err error, client *mcp.ClientSession,
 	}
 	states.Set(name, info)
 
+	if err != nil {
+		appendLog(name, "error", "%s: %v", state, err)
+	} else {
+		appendLog(name, "info", "%s", state)
+	}
+
 	// Publish state change event
 	broker.Publish(pubsub.UpdatedEvent, Event{
 		Type:   EventStateChanged,
@@ -262,7 +331,7 @@ func createSession(ctx context.Context, name string, m config.MCPConfig, resolve
 	mcpCtx, cancel := context.WithCancel(ctx)
 	cancelTimer := time.AfterFunc(timeout, cancel)
 
-	transport, err := createTransport(mcpCtx, m, resolver)
+	transport, err := createTransport(mcpCtx, name, m, resolver)
 	if err != nil {
 		updateState(name, StateError, err, nil, Counts{})
 		slog.Error("error creating mcp client", "error", err, "name", name)
@@ -292,6 +361,7 @@ func createSession(ctx context.Context, name string, m config.MCPConfig, resolve
 			},
 			LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
 				slog.Info("MCP log", "name", name, "data", req.Params.Data)
+				appendLog(name, string(req.Params.Level), "%v", req.Params.Data)
 			},
 		},
 	)
@@ -339,7 +409,7 @@ func maybeTimeoutErr(err error, timeout time.Duration) error {
 	return err
 }
 
-func createTransport(ctx context.Context, m config.MCPConfig, resolver config.VariableResolver) (mcp.Transport, error) {
+func createTransport(ctx context.Context, name string, m config.MCPConfig, resolver config.VariableResolver) (mcp.Transport, error) {
 	switch m.Type {
 	case config.MCPStdio:
 		command, err := resolver.ResolveValue(m.Command)
@@ -358,11 +428,7 @@ func createTransport(ctx context.Context, m config.MCPConfig, resolver config.Va
 		if strings.TrimSpace(m.URL) == "" {
 			return nil, fmt.Errorf("mcp http config requires a non-empty 'url' field")
 		}
-		client := &http.Client{
-			Transport: &headerRoundTripper{
-				headers: m.ResolvedHeaders(),
-			},
-		}
+		client := &http.Client{Transport: httpTransport(name, m)}
 		return &mcp.StreamableClientTransport{
 			Endpoint:   m.URL,
 			HTTPClient: client,
@@ -371,11 +437,7 @@ func createTransport(ctx context.Context, m config.MCPConfig, resolver config.Va
 		if strings.TrimSpace(m.URL) == "" {
 			return nil, fmt.Errorf("mcp sse config requires a non-empty 'url' field")
 		}
-		client := &http.Client{
-			Transport: &headerRoundTripper{
-				headers: m.ResolvedHeaders(),
-			},
-		}
+		client := &http.Client{Transport: httpTransport(name, m)}
 		return &mcp.SSEClientTransport{
 			Endpoint:   m.URL,
 			HTTPClient: client,
@@ -385,6 +447,19 @@ func createTransport(ctx context.Context, m config.MCPConfig, resolver config.Va
 	}
 }
 
+// httpTransport returns the round tripper for HTTP and SSE servers. Stored
+// OAuth credentials are only used when no Authorization header is configured.
+func httpTransport(name string, m config.MCPConfig) http.RoundTripper {
+	headers := m.ResolvedHeaders()
+	var rt http.RoundTripper = &headerRoundTripper{headers: headers}
+	for k := range headers {
+		if strings.EqualFold(k, "Authorization") {
+			return rt
+		}
+	}
+	return authRoundTripper{name: name, next: rt}
+}
+
 type headerRoundTripper struct {
 	headers map[string]string
 }
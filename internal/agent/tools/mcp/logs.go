@@ -0,0 +1,20 @@
+package mcp
+
+import "github.com/charmbracelet/crush/internal/logring"
+
+// maxLogEntries is the number of log entries kept for each MCP server.
+const maxLogEntries = 200
+
+// LogEntry is a line in the connection log of an MCP server.
+type LogEntry = logring.Entry
+
+var logs = logring.New(maxLogEntries)
+
+// Logs returns the connection log of the given MCP server, oldest first.
+func Logs(name string) []LogEntry {
+	return logs.Get(name)
+}
+
+func appendLog(name, level, format string, args ...any) {
+	logs.Append(name, level, format, args...)
+}
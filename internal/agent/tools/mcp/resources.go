@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"iter"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type (
+	Resource         = mcp.Resource
+	ResourceTemplate = mcp.ResourceTemplate
+	ResourceContents = mcp.ResourceContents
+)
+
+var (
+	allResources         = csync.NewMap[string, []*Resource]()
+	allResourceTemplates = csync.NewMap[string, []*ResourceTemplate]()
+)
+
+// Resources returns all available MCP resources.
+func Resources() iter.Seq2[string, []*Resource] {
+	return allResources.Seq2()
+}
+
+// ResourceTemplates returns all available MCP resource templates.
+func ResourceTemplates() iter.Seq2[string, []*ResourceTemplate] {
+	return allResourceTemplates.Seq2()
+}
+
+// ReadResource retrieves the contents of the resource with the given URI.
+func ReadResource(ctx context.Context, clientName, uri string) ([]*ResourceContents, error) {
+	c, err := getOrRenewClient(ctx, clientName)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+func getResources(ctx context.Context, c *mcp.ClientSession) ([]*Resource, error) {
+	if c.InitializeResult().Capabilities.Resources == nil {
+		return nil, nil
+	}
+	result, err := c.ListResources(ctx, &mcp.ListResourcesParams{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Resources, nil
+}
+
+func getResourceTemplates(ctx context.Context, c *mcp.ClientSession) ([]*ResourceTemplate, error) {
+	if c.InitializeResult().Capabilities.Resources == nil {
+		return nil, nil
+	}
+	result, err := c.ListResourceTemplates(ctx, &mcp.ListResourceTemplatesParams{})
+	if err != nil {
+		return nil, err
+	}
+	return result.ResourceTemplates, nil
+}
+
+func updateResources(name string, resources []*Resource) {
+	if len(resources) == 0 {
+		allResources.Del(name)
+		return
+	}
+	allResources.Set(name, resources)
+}
+
+func updateResourceTemplates(name string, templates []*ResourceTemplate) {
+	if len(templates) == 0 {
+		allResourceTemplates.Del(name)
+		return
+	}
+	allResourceTemplates.Set(name, templates)
+}
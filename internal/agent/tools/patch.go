@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/patch"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type PatchParams struct {
+	FilePath      string  `json:"file_path" description:"The absolute path to the file to patch"`
+	Patch         string  `json:"patch" description:"The hunks to apply, in unified diff format: context lines start with a space, removed lines with -, added lines with +, and each hunk with an @@ line"`
+	MinSimilarity float64 `json:"min_similarity,omitempty" description:"How similar, from 0 to 1, the context and removed lines must be to the file when they don't match exactly (default 0.8)"`
+	DryRun        bool    `json:"dry_run,omitempty" description:"Show the resulting diff without writing the file (default false)"`
+}
+
+type PatchPermissionsParams struct {
+	FilePath   string `json:"file_path"`
+	OldContent string `json:"old_content,omitempty"`
+	NewContent string `json:"new_content,omitempty"`
+}
+
+type PatchResponseMetadata struct {
+	Additions  int           `json:"additions"`
+	Removals   int           `json:"removals"`
+	OldContent string        `json:"old_content,omitempty"`
+	NewContent string        `json:"new_content,omitempty"`
+	Hunks      []patch.Match `json:"hunks,omitempty"`
+	DryRun     bool          `json:"dry_run,omitempty"`
+}
+
+const PatchToolName = "patch"
+
+//go:embed patch.md
+var patchDescription []byte
+
+func NewPatchTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		PatchToolName,
+		string(patchDescription),
+		func(ctx context.Context, params PatchParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			if params.MinSimilarity < 0 || params.MinSimilarity > 1 {
+				return fantasy.NewTextErrorResponse("min_similarity must be between 0 and 1"), nil
+			}
+			minSimilarity := params.MinSimilarity
+			if minSimilarity == 0 {
+				minSimilarity = patch.DefaultMinSimilarity
+			}
+			hunks, err := patch.Parse(params.Patch)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("invalid patch: %s", err)), nil
+			}
+
+			filePath := filepathext.SmartJoin(workingDir, params.FilePath)
+
+			if resp, ok := crushIgnored(workingDir, filePath); ok {
+				return resp, nil
+			}
+			fileInfo, err := os.Stat(filePath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("file not found: %s", filePath)), nil
+				}
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to access file: %w", err)
+			}
+			if fileInfo.IsDir() {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
+			}
+			if !params.DryRun {
+				lastRead := getLastReadTime(filePath)
+				if lastRead.IsZero() {
+					return fantasy.NewTextErrorResponse("you must read the file before editing it. Use the View tool first"), nil
+				}
+				if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+					return fantasy.NewTextErrorResponse(
+						fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+							filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
+						)), nil
+				}
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+			}
+			oldContent, isCrlf := fsext.ToUnixLineEndings(string(content))
+			newContent, matches, err := patch.Apply(oldContent, hunks, minSimilarity)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			if oldContent == newContent {
+				return fantasy.NewTextErrorResponse("the patch makes no changes"), nil
+			}
+
+			unified, additions, removals := diff.GenerateDiff(
+				oldContent,
+				newContent,
+				strings.TrimPrefix(filePath, workingDir),
+			)
+			metadata := PatchResponseMetadata{
+				Additions:  additions,
+				Removals:   removals,
+				OldContent: oldContent,
+				NewContent: newContent,
+				Hunks:      matches,
+				DryRun:     params.DryRun,
+			}
+			if params.DryRun {
+				return fantasy.WithResponseMetadata(
+					fantasy.NewTextResponse(fmt.Sprintf("%s\n<diff>\n%s</diff>\n", formatPatchMatches(matches), unified)),
+					metadata,
+				), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for patching a file")
+			}
+			p := permissions.Request(
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        fsext.PathOrPrefix(filePath, workingDir),
+					ToolCallID:  call.ID,
+					ToolName:    PatchToolName,
+					Action:      "write",
+					Description: fmt.Sprintf("Apply %d hunk(s) to file %s", len(hunks), filePath),
+					Params: PatchPermissionsParams{
+						FilePath:   filePath,
+						OldContent: oldContent,
+						NewContent: newContent,
+					},
+				},
+			)
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			written := newContent
+			if isCrlf {
+				written, _ = fsext.ToWindowsLineEndings(written)
+			}
+			if err := os.WriteFile(filePath, []byte(written), 0o644); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
+			}
+			recordEditHistory(ctx, files, sessionID, filePath, oldContent, newContent)
+			recordFileWrite(filePath)
+			recordFileRead(filePath)
+			notifyLSPs(ctx, lspClients, filePath)
+
+			text := fmt.Sprintf("<result>\n%s\n</result>\n", formatPatchMatches(matches))
+			text += getDiagnostics(filePath, lspClients)
+			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(text), metadata), nil
+		})
+}
+
+// formatPatchMatches tells where the hunks applied, and how well the ones
+// not matching exactly did, for the agent to check them.
+func formatPatchMatches(matches []patch.Match) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Applied %d hunk(s).", len(matches))
+	for i, m := range matches {
+		if !m.Exact() {
+			fmt.Fprintf(&sb, "\nHunk %d matched line %d with %.0f%% similarity, check the result.", i+1, m.Line, m.Similarity*100)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/plan"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+//go:embed plan.md
+var planDescription []byte
+
+const PlanToolName = "plan"
+
+type PlanParams struct {
+	Steps []string `json:"steps" description:"The ordered list of steps to carry out"`
+}
+
+type PlanResponseMetadata struct {
+	Approved bool     `json:"approved"`
+	Steps    []string `json:"steps"`
+}
+
+func NewPlanTool(plans plan.Service, sessions session.Service) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		PlanToolName,
+		string(planDescription),
+		func(ctx context.Context, params PlanParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if len(params.Steps) == 0 {
+				return fantasy.NewTextErrorResponse("at least one step is required"), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for proposing a plan")
+			}
+
+			steps, err := plans.Propose(ctx, sessionID, call.ID, params.Steps)
+			if errors.Is(err, plan.ErrPlanRejected) {
+				return fantasy.WithResponseMetadata(
+					fantasy.NewTextResponse("The user rejected the plan. Do not carry out any of the steps; ask the user how they would like to proceed."),
+					PlanResponseMetadata{Steps: params.Steps},
+				), nil
+			}
+			if err != nil {
+				return fantasy.ToolResponse{}, err
+			}
+
+			currentSession, err := sessions.Get(ctx, sessionID)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to get session: %w", err)
+			}
+			todos := make([]session.Todo, len(steps))
+			for i, step := range steps {
+				todos[i] = session.Todo{
+					Content: step,
+					Status:  session.TodoStatusPending,
+				}
+			}
+			currentSession.Todos = todos
+			if _, err := sessions.Save(ctx, currentSession); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to save plan: %w", err)
+			}
+
+			var sb strings.Builder
+			sb.WriteString("The user approved the following plan:\n\n")
+			for i, step := range steps {
+				fmt.Fprintf(&sb, "%d. %s\n", i+1, step)
+			}
+			sb.WriteString("\nThe steps have been added to the todo list. Carry them out in order and keep the todo list up to date as you progress.")
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(sb.String()),
+				PlanResponseMetadata{Approved: true, Steps: steps},
+			), nil
+		})
+}
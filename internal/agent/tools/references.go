@@ -47,41 +47,18 @@ func NewReferencesTool(lspClients *csync.Map[string, *lsp.Client]) fantasy.Agent
 				return fantasy.NewTextErrorResponse("no LSP clients available"), nil
 			}
 
-			workingDir := cmp.Or(params.Path, ".")
-
-			matches, _, err := searchFiles(ctx, regexp.QuoteMeta(params.Symbol), workingDir, "", 100)
-			if err != nil {
-				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to search for symbol: %s", err)), nil
-			}
-
-			if len(matches) == 0 {
+			allLocations, err := FindSymbol(ctx, lspClients, params.Symbol, cmp.Or(params.Path, "."), false)
+			if errors.Is(err, ErrSymbolNotFound) {
 				return fantasy.NewTextResponse(fmt.Sprintf("Symbol '%s' not found", params.Symbol)), nil
 			}
 
-			var allLocations []protocol.Location
-			var allErrs error
-			for _, match := range matches {
-				locations, err := find(ctx, lspClients, params.Symbol, match)
-				if err != nil {
-					if strings.Contains(err.Error(), "no identifier found") {
-						// grep probably matched a comment, string value, or something else that's irrelevant
-						continue
-					}
-					slog.Error("Failed to find references", "error", err, "symbol", params.Symbol, "path", match.path, "line", match.lineNum, "char", match.charNum)
-					allErrs = errors.Join(allErrs, err)
-					continue
-				}
-				allLocations = append(allLocations, locations...)
-				// XXX: should we break here or look for all results?
-			}
-
 			if len(allLocations) > 0 {
-				output := formatReferences(cleanupLocations(allLocations))
+				output := formatReferences(allLocations)
 				return fantasy.NewTextResponse(output), nil
 			}
 
-			if allErrs != nil {
-				return fantasy.NewTextErrorResponse(allErrs.Error()), nil
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
 			}
 			return fantasy.NewTextResponse(fmt.Sprintf("No references found for symbol '%s'", params.Symbol)), nil
 		})
@@ -91,7 +68,45 @@ func (r *referencesTool) Name() string {
 	return ReferencesToolName
 }
 
-func find(ctx context.Context, lspClients *csync.Map[string, *lsp.Client], symbol string, match grepMatch) ([]protocol.Location, error) {
+// ErrSymbolNotFound is returned by [FindSymbol] when the symbol doesn't
+// appear in any file.
+var ErrSymbolNotFound = errors.New("symbol not found")
+
+// FindSymbol searches path for the symbol and asks the LSP servers for its
+// references, or for its definitions when definitions is set. The returned
+// locations are sorted and deduplicated.
+func FindSymbol(ctx context.Context, lspClients *csync.Map[string, *lsp.Client], symbol, path string, definitions bool) ([]protocol.Location, error) {
+	matches, _, err := searchFiles(ctx, regexp.QuoteMeta(symbol), path, "", 100)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for symbol: %s", err)
+	}
+	if len(matches) == 0 {
+		return nil, ErrSymbolNotFound
+	}
+
+	var allLocations []protocol.Location
+	var allErrs error
+	for _, match := range matches {
+		locations, err := find(ctx, lspClients, symbol, match, definitions)
+		if err != nil {
+			if strings.Contains(err.Error(), "no identifier found") {
+				// grep probably matched a comment, string value, or something else that's irrelevant
+				continue
+			}
+			slog.Error("Failed to find references", "error", err, "symbol", symbol, "path", match.path, "line", match.lineNum, "char", match.charNum)
+			allErrs = errors.Join(allErrs, err)
+			continue
+		}
+		allLocations = append(allLocations, locations...)
+		// XXX: should we break here or look for all results?
+	}
+	if len(allLocations) > 0 {
+		return cleanupLocations(allLocations), nil
+	}
+	return nil, allErrs
+}
+
+func find(ctx context.Context, lspClients *csync.Map[string, *lsp.Client], symbol string, match grepMatch, definitions bool) ([]protocol.Location, error) {
 	absPath, err := filepath.Abs(match.path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absolute path: %s", err)
@@ -110,6 +125,9 @@ func find(ctx context.Context, lspClients *csync.Map[string, *lsp.Client], symbo
 		return nil, nil
 	}
 
+	if definitions {
+		return client.FindDefinitions(ctx, absPath, match.lineNum, match.charNum+getSymbolOffset(symbol))
+	}
 	return client.FindReferences(
 		ctx,
 		absPath,
@@ -0,0 +1,488 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/remote"
+)
+
+// remoteSearchLimit bounds the matches of the remote grep and glob tools.
+const remoteSearchLimit = 100
+
+// remoteFiles is what the remote tools share: the host, and when the files
+// were modified on the host as of their last read, to tell when they
+// changed since. The clocks of the host and of Crush may differ, so they
+// can't be compared.
+type remoteFiles struct {
+	host  *remote.Host
+	reads *csync.Map[string, time.Time]
+}
+
+// NewRemoteTools returns the tools working on the files of the host of a
+// remote workspace, in place of the local ones of the same names.
+func NewRemoteTools(host *remote.Host, permissions permission.Service, files history.Service) []fantasy.AgentTool {
+	r := &remoteFiles{host: host, reads: csync.NewMap[string, time.Time]()}
+	return []fantasy.AgentTool{
+		r.bashTool(permissions),
+		r.editTool(permissions, files),
+		r.globTool(),
+		r.grepTool(),
+		r.lsTool(),
+		r.viewTool(),
+		r.writeTool(permissions, files),
+	}
+}
+
+// recordRead remembers the modification time of the file at p on the host.
+func (r *remoteFiles) recordRead(ctx context.Context, p string) {
+	if info, err := r.host.Stat(ctx, p); err == nil {
+		r.reads.Set(p, info.ModTime)
+	}
+}
+
+// checkRead returns why the file at p can't be edited: it wasn't read, or
+// changed since.
+func (r *remoteFiles) checkRead(info remote.FileInfo, p string) string {
+	modTime, ok := r.reads.Get(p)
+	switch {
+	case !ok:
+		return "you must read the file before editing it. Use the View tool first"
+	case info.ModTime.After(modTime):
+		return fmt.Sprintf("file %s has been modified since it was last read", p)
+	}
+	return ""
+}
+
+// location returns p on the host, for permission requests.
+func (r *remoteFiles) location(p string) string {
+	return r.host.Target + ":" + p
+}
+
+func (r *remoteFiles) bashTool(permissions permission.Service) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		BashToolName,
+		fmt.Sprintf("Runs a shell command on %s, the host of the workspace, over SSH, in the directory of the workspace or working_dir. Each command runs in a new shell: chain commands with && rather than relying on cd or exported variables. Commands can't run in the background, and commands taking long are stopped when the request is canceled.", r.host.Target),
+		func(ctx context.Context, params BashParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.Command == "" {
+				return fantasy.NewTextErrorResponse("missing command"), nil
+			}
+			if params.RunInBackground {
+				return fantasy.NewTextErrorResponse("commands can't run in the background on a remote host"), nil
+			}
+			dir := r.host.Abs(params.WorkingDir)
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for executing shell command")
+			}
+			if !IsSafeReadOnly(params.Command) {
+				p := permissions.Request(permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        r.location(dir),
+					ToolCallID:  call.ID,
+					ToolName:    BashToolName,
+					Action:      "execute",
+					Description: fmt.Sprintf("Execute command on %s: %s", r.host.Target, params.Command),
+					Params:      BashPermissionsParams(params),
+				})
+				if !p {
+					return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+				}
+			}
+
+			startTime := time.Now()
+			stdout, stderr, err := r.host.Run(ctx, dir, params.Command, nil)
+			if err != nil && remote.ExitCode(err) < 0 {
+				if ctx.Err() != nil {
+					return fantasy.ToolResponse{}, ctx.Err()
+				}
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			output := formatRemoteOutput(stdout, stderr, remote.ExitCode(err))
+			metadata := BashResponseMetadata{
+				StartTime:        startTime.UnixMilli(),
+				EndTime:          time.Now().UnixMilli(),
+				Output:           output,
+				Description:      params.Description,
+				WorkingDirectory: r.location(dir),
+			}
+			if output == "" {
+				return fantasy.WithResponseMetadata(fantasy.NewTextResponse(BashNoOutput), metadata), nil
+			}
+			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(output), metadata), nil
+		})
+}
+
+// formatRemoteOutput formats the output of a command run on a host.
+func formatRemoteOutput(stdout, stderr string, exitCode int) string {
+	output := stdout
+	if stderr != "" {
+		if output != "" {
+			output += "\n"
+		}
+		output += stderr
+	}
+	if exitCode != 0 {
+		output = strings.TrimRight(output, "\n") + fmt.Sprintf("\nExit code %d", exitCode)
+	}
+	return strings.TrimRight(output, "\n")
+}
+
+func (r *remoteFiles) viewTool() fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		ViewToolName,
+		string(viewDescription),
+		func(ctx context.Context, params ViewParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			filePath := r.host.Abs(params.FilePath)
+			info, err := r.host.Stat(ctx, filePath)
+			if errors.Is(err, fs.ErrNotExist) {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("File not found: %s", filePath)), nil
+			} else if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error accessing file: %v", err)), nil
+			}
+			if info.IsDir {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Path is a directory, not a file: %s", filePath)), nil
+			}
+			if info.Size > MaxReadSize {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes",
+					info.Size, MaxReadSize)), nil
+			}
+
+			data, err := r.host.ReadFile(ctx, filePath)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error reading file: %v", err)), nil
+			}
+			if !utf8.Valid(data) {
+				return fantasy.NewTextErrorResponse("File content is not valid UTF-8"), nil
+			}
+			content, _ := fsext.ToUnixLineEndings(string(data))
+			lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+			limit := cmp.Or(max(params.Limit, 0), DefaultReadLimit)
+			start := min(max(params.Offset, 0), len(lines))
+			end := min(start+limit, len(lines))
+			shown := lines[start:end]
+			for i, line := range shown {
+				if len(line) > MaxLineLength {
+					shown[i] = line[:MaxLineLength] + "..."
+				}
+			}
+			content = strings.Join(shown, "\n")
+
+			output := "<file>\n" + addLineNumbers(content, start+1)
+			if end < len(lines) {
+				output += fmt.Sprintf("\n\n(File has more lines. Use 'offset' parameter to read beyond line %d)", end)
+			}
+			output += "\n</file>\n"
+			r.reads.Set(filePath, info.ModTime)
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(output),
+				ViewResponseMetadata{
+					FilePath: filePath,
+					Content:  content,
+				},
+			), nil
+		})
+}
+
+func (r *remoteFiles) writeTool(permissions permission.Service, files history.Service) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		WriteToolName,
+		string(writeDescription),
+		func(ctx context.Context, params WriteParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			filePath := r.host.Abs(params.FilePath)
+			var oldContent string
+			info, err := r.host.Stat(ctx, filePath)
+			switch {
+			case err == nil && info.IsDir:
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Path is a directory, not a file: %s", filePath)), nil
+			case err == nil:
+				if reason := r.checkRead(info, filePath); reason != "" {
+					return fantasy.NewTextErrorResponse(reason), nil
+				}
+				data, err := r.host.ReadFile(ctx, filePath)
+				if err != nil {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("error reading file: %v", err)), nil
+				}
+				oldContent, _ = fsext.ToUnixLineEndings(string(data))
+				if oldContent == params.Content {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("File %s already contains the exact content. No changes made.", filePath)), nil
+				}
+			case !errors.Is(err, fs.ErrNotExist):
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error accessing file: %v", err)), nil
+			}
+			return r.write(ctx, permissions, files, call, WriteToolName, filePath, oldContent, params.Content,
+				fmt.Sprintf("Create file %s", filePath))
+		})
+}
+
+func (r *remoteFiles) editTool(permissions permission.Service, files history.Service) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		EditToolName,
+		string(editDescription),
+		func(ctx context.Context, params EditParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			filePath := r.host.Abs(params.FilePath)
+			info, err := r.host.Stat(ctx, filePath)
+			if params.OldString == "" {
+				if err == nil {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("file already exists: %s", filePath)), nil
+				}
+				return r.write(ctx, permissions, files, call, EditToolName, filePath, "", params.NewString,
+					fmt.Sprintf("Create file %s", filePath))
+			}
+			if errors.Is(err, fs.ErrNotExist) {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("file not found: %s", filePath)), nil
+			} else if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error accessing file: %v", err)), nil
+			}
+			if info.IsDir {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
+			}
+			if reason := r.checkRead(info, filePath); reason != "" {
+				return fantasy.NewTextErrorResponse(reason), nil
+			}
+			data, err := r.host.ReadFile(ctx, filePath)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error reading file: %v", err)), nil
+			}
+			oldContent, _ := fsext.ToUnixLineEndings(string(data))
+			switch count := strings.Count(oldContent, params.OldString); {
+			case count == 0:
+				return fantasy.NewTextErrorResponse("old_string not found in file. Make sure it matches exactly, including whitespace and line breaks"), nil
+			case count > 1 && !params.ReplaceAll:
+				return fantasy.NewTextErrorResponse("old_string appears multiple times in the file. Please provide more context to ensure a unique match, or set replace_all to true"), nil
+			}
+			newContent := strings.Replace(oldContent, params.OldString, params.NewString, 1)
+			if params.ReplaceAll {
+				newContent = strings.ReplaceAll(oldContent, params.OldString, params.NewString)
+			}
+			if newContent == oldContent {
+				return fantasy.NewTextErrorResponse("new content is the same as old content. No changes made."), nil
+			}
+			return r.write(ctx, permissions, files, call, EditToolName, filePath, oldContent, newContent,
+				fmt.Sprintf("Replace content in file %s", filePath))
+		})
+}
+
+// write asks to change the file at filePath from oldContent to newContent,
+// writes it and records the change in its history, for the write and edit
+// tools.
+func (r *remoteFiles) write(ctx context.Context, permissions permission.Service, files history.Service, call fantasy.ToolCall, toolName, filePath, oldContent, newContent, description string) (fantasy.ToolResponse, error) {
+	sessionID := GetSessionFromContext(ctx)
+	if sessionID == "" {
+		return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for writing a file")
+	}
+	unified, additions, removals := diff.GenerateDiff(oldContent, newContent, strings.TrimPrefix(filePath, r.host.Dir))
+	p := permissions.Request(permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        r.location(path.Dir(filePath)),
+		ToolCallID:  call.ID,
+		ToolName:    toolName,
+		Action:      "write",
+		Description: description,
+		Params: EditPermissionsParams{
+			FilePath:   filePath,
+			OldContent: oldContent,
+			NewContent: newContent,
+		},
+	})
+	if !p {
+		return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+	}
+	if err := r.host.WriteFile(ctx, filePath, []byte(newContent)); err != nil {
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("error writing file: %v", err)), nil
+	}
+	recordEditHistory(ctx, files, sessionID, filePath, oldContent, newContent)
+	r.recordRead(ctx, filePath)
+
+	var metadata any = EditResponseMetadata{Additions: additions, Removals: removals, OldContent: oldContent, NewContent: newContent}
+	if toolName == WriteToolName {
+		metadata = WriteResponseMetadata{Diff: unified, Additions: additions, Removals: removals}
+	}
+	return fantasy.WithResponseMetadata(
+		fantasy.NewTextResponse(fmt.Sprintf("<result>\nFile written on %s: %s\n</result>", r.host.Target, filePath)),
+		metadata,
+	), nil
+}
+
+// listFiles lists the files under dir on the host, the ones git ignores
+// left out when ripgrep is there, matching the glob pattern when given.
+func (r *remoteFiles) listFiles(ctx context.Context, dir, pattern string, depth, limit int) ([]string, bool, error) {
+	rg := "rg --files --color=never"
+	find := "find . -type f -not -path '*/.*'"
+	if depth > 0 {
+		rg += fmt.Sprintf(" --max-depth %d", depth)
+		find = fmt.Sprintf("find . -maxdepth %d -type f -not -path '*/.*'", depth)
+	}
+	if pattern != "" {
+		rg += " --glob " + remote.Quote(pattern)
+		find += " -name " + remote.Quote(path.Base(pattern))
+	}
+	command := fmt.Sprintf("if command -v rg >/dev/null 2>&1; then %s; else %s; fi | head -n %d", rg, find, limit+1)
+	out, stderr, err := r.host.Run(ctx, dir, command, nil)
+	if err != nil {
+		return nil, false, cmp.Or(stderrError(stderr), err)
+	}
+	var files []string
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		if line != "" {
+			files = append(files, path.Join(dir, line))
+		}
+	}
+	truncated := len(files) > limit
+	return files[:min(len(files), limit)], truncated, nil
+}
+
+// stderrError returns what a command wrote on stderr as an error, or nil
+// when it wrote nothing.
+func stderrError(stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (r *remoteFiles) globTool() fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		GlobToolName,
+		string(globDescription),
+		func(ctx context.Context, params GlobParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.Pattern == "" {
+				return fantasy.NewTextErrorResponse("pattern is required"), nil
+			}
+			files, truncated, err := r.listFiles(ctx, r.host.Abs(params.Path), params.Pattern, 0, remoteSearchLimit)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error finding files: %v", err)), nil
+			}
+			output := "No files found"
+			if len(files) > 0 {
+				output = strings.Join(files, "\n")
+				if truncated {
+					output += "\n\n(Results are truncated. Consider using a more specific path or pattern.)"
+				}
+			}
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(output),
+				GlobResponseMetadata{NumberOfFiles: len(files), Truncated: truncated},
+			), nil
+		})
+}
+
+func (r *remoteFiles) lsTool() fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		LSToolName,
+		string(lsDescription),
+		func(ctx context.Context, params LSParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			dir := r.host.Abs(params.Path)
+			files, truncated, err := r.listFiles(ctx, dir, "", params.Depth, maxLSFiles)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error listing directory: %v", err)), nil
+			}
+			files = slices.DeleteFunc(files, func(file string) bool {
+				for _, pattern := range params.Ignore {
+					if ok, _ := path.Match(pattern, path.Base(file)); ok {
+						return true
+					}
+				}
+				return false
+			})
+			slices.Sort(files)
+			var output string
+			if truncated {
+				output = fmt.Sprintf("There are more than %d files in the directory. Use a more specific path or use the Glob tool to find specific files. The first %[1]d files are included below.\n", maxLSFiles)
+			}
+			output += "\n" + printTree(createFileTree(files, dir), dir)
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(output),
+				LSResponseMetadata{NumberOfFiles: len(files), Truncated: truncated},
+			), nil
+		})
+}
+
+func (r *remoteFiles) grepTool() fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		GrepToolName,
+		string(grepDescription),
+		func(ctx context.Context, params GrepParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.Pattern == "" {
+				return fantasy.NewTextErrorResponse("pattern is required"), nil
+			}
+			dir := r.host.Abs(params.Path)
+			rg := "rg -n --no-heading --color=never"
+			grep := "grep -rnE --exclude-dir='.[!.]*'"
+			if params.LiteralText {
+				rg += " -F"
+				grep = "grep -rnF --exclude-dir='.[!.]*'"
+			}
+			if params.Include != "" {
+				rg += " --glob " + remote.Quote(params.Include)
+				grep += " --include=" + remote.Quote(params.Include)
+			}
+			pattern := remote.Quote(params.Pattern)
+			command := fmt.Sprintf("if command -v rg >/dev/null 2>&1; then %s -- %s .; else %s -- %s .; fi | head -n %d",
+				rg, pattern, grep, pattern, remoteSearchLimit+1)
+			out, stderr, err := r.host.Run(ctx, dir, command, nil)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error searching files: %v", cmp.Or(stderrError(stderr), err))), nil
+			}
+
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if lines[0] == "" {
+				lines = nil
+			}
+			truncated := len(lines) > remoteSearchLimit
+			lines = lines[:min(len(lines), remoteSearchLimit)]
+			var output strings.Builder
+			if len(lines) == 0 {
+				output.WriteString("No files found")
+			} else {
+				fmt.Fprintf(&output, "Found %d matches\n", len(lines))
+				currentFile := ""
+				for _, line := range lines {
+					file, rest, _ := strings.Cut(line, ":")
+					lineNum, text, _ := strings.Cut(rest, ":")
+					file = path.Join(dir, file)
+					if file != currentFile {
+						if currentFile != "" {
+							output.WriteString("\n")
+						}
+						currentFile = file
+						fmt.Fprintf(&output, "%s:\n", file)
+					}
+					if len(text) > maxGrepContentWidth {
+						text = text[:maxGrepContentWidth] + "..."
+					}
+					fmt.Fprintf(&output, "  Line %s: %s\n", lineNum, text)
+				}
+				if truncated {
+					output.WriteString("\n(Results are truncated. Consider using a more specific path or pattern.)")
+				}
+			}
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(output.String()),
+				GrepResponseMetadata{NumberOfMatches: len(lines), Truncated: truncated},
+			), nil
+		})
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/remote"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteTools(t *testing.T) {
+	// An ssh running the commands locally.
+	bin := t.TempDir()
+	script := "#!/bin/sh\nfor last; do :; done\nexec sh -c \"$last\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	agentTools := NewRemoteTools(
+		&remote.Host{Target: "devbox", Dir: dir},
+		&mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()},
+		&mockHistoryService{Broker: pubsub.NewBroker[history.File]()},
+	)
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "session")
+	run := func(name string, params any) fantasy.ToolResponse {
+		t.Helper()
+		input, err := json.Marshal(params)
+		require.NoError(t, err)
+		for _, tool := range agentTools {
+			if tool.Info().Name == name {
+				resp, err := tool.Run(ctx, fantasy.ToolCall{ID: "call", Name: name, Input: string(input)})
+				require.NoError(t, err)
+				return resp
+			}
+		}
+		t.Fatalf("no remote %s tool", name)
+		return fantasy.ToolResponse{}
+	}
+
+	resp := run(EditToolName, EditParams{FilePath: "main.go", OldString: "func main() {}", NewString: "func main() { run() }"})
+	require.True(t, resp.IsError, "files are read before they're edited")
+
+	resp = run(ViewToolName, ViewParams{FilePath: "main.go"})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "     3|func main() {}")
+
+	resp = run(EditToolName, EditParams{FilePath: "main.go", OldString: "func main() {}", NewString: "func main() { run() }"})
+	require.False(t, resp.IsError, resp.Content)
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	require.Equal(t, "package main\n\nfunc main() { run() }\n", string(data))
+
+	resp = run(WriteToolName, WriteParams{FilePath: "cmd/run.go", Content: "package main\n\nfunc run() {}\n"})
+	require.False(t, resp.IsError, resp.Content)
+
+	resp = run(GrepToolName, GrepParams{Pattern: "func run"})
+	require.Contains(t, resp.Content, "Found 1 matches")
+	require.Contains(t, resp.Content, filepath.Join(dir, "cmd", "run.go")+":\n  Line 3: func run() {}")
+
+	resp = run(GlobToolName, GlobParams{Pattern: "*.go"})
+	require.Contains(t, resp.Content, filepath.Join(dir, "main.go"))
+
+	resp = run(LSToolName, LSParams{})
+	require.Contains(t, resp.Content, "  - cmd/\n    - run.go\n")
+
+	resp = run(BashToolName, BashParams{Command: "cat main.go | wc -l; exit 2"})
+	require.Equal(t, "3\nExit code 2", resp.Content)
+}
@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/replace"
+)
+
+type ReplaceParams struct {
+	Pattern     string   `json:"pattern" description:"The text to find, or a regular expression when regex is true"`
+	Replacement string   `json:"replacement" description:"The text to replace every match with. With regex, $1 or ${name} expand to submatches."`
+	Regex       bool     `json:"regex,omitempty" description:"Treat pattern as a Go regular expression (default false)"`
+	IgnoreCase  bool     `json:"ignore_case,omitempty" description:"Match case-insensitively (default false)"`
+	Include     []string `json:"include,omitempty" description:"Glob patterns of the files to change (e.g. \"*.go\", \"internal/**/*.ts\")"`
+	Exclude     []string `json:"exclude,omitempty" description:"Glob patterns of the files to leave alone"`
+	Path        string   `json:"path,omitempty" description:"The directory to search in. Defaults to the current working directory."`
+	DryRun      bool     `json:"dry_run,omitempty" description:"List the changes without applying them (default false)"`
+}
+
+type ReplacePermissionsParams struct {
+	Pattern     string              `json:"pattern"`
+	Replacement string              `json:"replacement"`
+	Files       []ReplaceFileChange `json:"files"`
+}
+
+type ReplaceFileChange struct {
+	FilePath   string `json:"file_path"`
+	OldContent string `json:"old_content,omitempty"`
+	NewContent string `json:"new_content,omitempty"`
+}
+
+type ReplaceResponseMetadata struct {
+	Files        int  `json:"files"`
+	Replacements int  `json:"replacements"`
+	DryRun       bool `json:"dry_run,omitempty"`
+	// Paths are the files changed.
+	Paths []string `json:"paths,omitempty"`
+}
+
+const ReplaceToolName = "replace"
+
+// maxReplacePreviewLines is the number of changed lines listed per file.
+const maxReplacePreviewLines = 10
+
+//go:embed replace.md
+var replaceDescription []byte
+
+func NewReplaceTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		ReplaceToolName,
+		string(replaceDescription),
+		func(ctx context.Context, params ReplaceParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.Pattern == "" {
+				return fantasy.NewTextErrorResponse("pattern is required"), nil
+			}
+
+			searchPath := filepathext.SmartJoin(workingDir, cmp.Or(params.Path, "."))
+			changes, err := replace.Find(ctx, searchPath, replace.Options{
+				Pattern:     params.Pattern,
+				Replacement: params.Replacement,
+				Regex:       params.Regex,
+				IgnoreCase:  params.IgnoreCase,
+				Include:     params.Include,
+				Exclude:     params.Exclude,
+			})
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return fantasy.ToolResponse{}, err
+				}
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			if len(changes) == 0 {
+				return fantasy.NewTextResponse("No matches found"), nil
+			}
+
+			count := 0
+			for _, c := range changes {
+				count += c.Count
+			}
+			metadata := ReplaceResponseMetadata{Files: len(changes), Replacements: count, DryRun: params.DryRun}
+			if params.DryRun {
+				return fantasy.WithResponseMetadata(
+					fantasy.NewTextResponse(formatReplacePreview(changes, workingDir)),
+					metadata,
+				), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for replacing text")
+			}
+
+			permissionFiles := make([]ReplaceFileChange, len(changes))
+			for i, c := range changes {
+				permissionFiles[i] = ReplaceFileChange{FilePath: c.Path, OldContent: c.OldContent, NewContent: c.NewContent}
+			}
+			p := permissions.Request(permission.CreatePermissionRequest{
+				SessionID:   sessionID,
+				Path:        fsext.PathOrPrefix(searchPath, workingDir),
+				ToolCallID:  call.ID,
+				ToolName:    ReplaceToolName,
+				Action:      "write",
+				Description: fmt.Sprintf("Replace %d occurrence(s) of %q in %d file(s)", count, params.Pattern, len(changes)),
+				Params: ReplacePermissionsParams{
+					Pattern:     params.Pattern,
+					Replacement: params.Replacement,
+					Files:       permissionFiles,
+				},
+			})
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			written, applyErr := replace.Apply(changes)
+			for _, c := range changes {
+				if !slices.Contains(written, c.Path) {
+					continue
+				}
+				recordEditHistory(ctx, files, sessionID, c.Path, c.OldContent, c.NewContent)
+				recordFileWrite(c.Path)
+				recordFileRead(c.Path)
+				notifyLSPs(ctx, lspClients, c.Path)
+			}
+
+			var output strings.Builder
+			fmt.Fprintf(&output, "<result>\n%s", formatReplacePreview(changes, workingDir))
+			if applyErr != nil {
+				fmt.Fprintf(&output, "\nSome files were not changed:\n%s\n", applyErr)
+			}
+			output.WriteString("</result>\n")
+			for _, path := range written {
+				output.WriteString(getDiagnostics(path, lspClients))
+			}
+			metadata.Files = len(written)
+			metadata.Paths = written
+			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(output.String()), metadata), nil
+		})
+}
+
+func formatReplacePreview(changes []replace.FileChange, workingDir string) string {
+	count := 0
+	for _, c := range changes {
+		count += c.Count
+	}
+	var output strings.Builder
+	fmt.Fprintf(&output, "%d replacement(s) in %d file(s):\n", count, len(changes))
+	for _, c := range changes {
+		path := c.Path
+		if rel, err := filepath.Rel(workingDir, c.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = filepath.ToSlash(rel)
+		}
+		fmt.Fprintf(&output, "\n%s (%d):\n", path, c.Count)
+		for i, m := range c.Matches {
+			if i == maxReplacePreviewLines {
+				fmt.Fprintf(&output, "  ... %d more\n", len(c.Matches)-i)
+				break
+			}
+			fmt.Fprintf(&output, "  %d: - %s\n", m.Line, strings.ReplaceAll(m.Before, "\n", "\\n"))
+			fmt.Fprintf(&output, "  %d: + %s\n", m.Line, strings.ReplaceAll(m.After, "\n", "\\n"))
+		}
+	}
+	return output.String()
+}
+
+// recordEditHistory stores the file versions before and after an edit, so
+// it can be reviewed and reverted like any other.
+func recordEditHistory(ctx context.Context, files history.Service, sessionID, path, oldContent, newContent string) {
+	file, err := files.GetByPathAndSession(ctx, path, sessionID)
+	if err != nil {
+		if _, err := files.Create(ctx, sessionID, path, oldContent); err != nil {
+			slog.Error("Error creating file history", "error", err)
+			return
+		}
+	} else if file.Content != oldContent {
+		// User manually changed the content, store an intermediate version
+		if _, err := files.CreateVersion(ctx, sessionID, path, oldContent); err != nil {
+			slog.Error("Error creating file history version", "error", err)
+		}
+	}
+	if _, err := files.CreateVersion(ctx, sessionID, path, newContent); err != nil {
+		slog.Error("Error creating file history version", "error", err)
+	}
+}
@@ -12,12 +12,12 @@ import (
 	"golang.org/x/net/html"
 )
 
-// SearchResult represents a single search result from DuckDuckGo.
+// SearchResult represents a single web search result.
 type SearchResult struct {
-	Title    string
-	Link     string
-	Snippet  string
-	Position int
+	Title    string `json:"title"`
+	Link     string `json:"link"`
+	Snippet  string `json:"snippet"`
+	Position int    `json:"position"`
 }
 
 // searchDuckDuckGo performs a web search using DuckDuckGo's HTML endpoint.
@@ -173,7 +173,7 @@ func cleanDuckDuckGoURL(rawURL string) string {
 // formatSearchResults formats search results for LLM consumption.
 func formatSearchResults(results []SearchResult) string {
 	if len(results) == 0 {
-		return "No results were found for your search query. This could be due to DuckDuckGo's bot detection or the query returned no matches. Please try rephrasing your search or try again in a few minutes."
+		return "No results were found for your search query. This could be due to the search engine's bot detection or the query returned no matches. Please try rephrasing your search or try again in a few minutes."
 	}
 
 	var sb strings.Builder
@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+// SearchProvider is a web search engine.
+type SearchProvider interface {
+	// Name is the name of the engine, as configured.
+	Name() string
+	// Endpoint is the URL searches are sent to.
+	Endpoint() string
+	// Search returns at most maxResults results for query.
+	Search(ctx context.Context, client *http.Client, query string, maxResults int) ([]SearchResult, error)
+}
+
+// NewSearchProvider returns the search engine cfg configures, using the
+// already resolved apiKey.
+func NewSearchProvider(cfg config.ToolWebSearch, apiKey string) (SearchProvider, error) {
+	switch cfg.Provider {
+	case "", config.SearchProviderDuckDuckGo:
+		return duckDuckGo{}, nil
+	case config.SearchProviderBrave:
+		if apiKey == "" {
+			return nil, errors.New("brave search needs an API key")
+		}
+		return brave{baseURL: cfg.BaseURL, apiKey: apiKey}, nil
+	case config.SearchProviderBing:
+		if apiKey == "" {
+			return nil, errors.New("bing search needs an API key")
+		}
+		return bing{baseURL: cfg.BaseURL, apiKey: apiKey}, nil
+	case config.SearchProviderSearXNG:
+		if cfg.BaseURL == "" {
+			return nil, errors.New("searxng search needs a base URL")
+		}
+		return searXNG{baseURL: cfg.BaseURL}, nil
+	default:
+		return nil, fmt.Errorf("unknown search provider %q", cfg.Provider)
+	}
+}
+
+type duckDuckGo struct{}
+
+func (duckDuckGo) Name() string     { return config.SearchProviderDuckDuckGo }
+func (duckDuckGo) Endpoint() string { return "https://html.duckduckgo.com/html" }
+
+func (duckDuckGo) Search(ctx context.Context, client *http.Client, query string, maxResults int) ([]SearchResult, error) {
+	return searchDuckDuckGo(ctx, client, query, maxResults)
+}
+
+type brave struct {
+	baseURL string
+	apiKey  string
+}
+
+func (brave) Name() string { return config.SearchProviderBrave }
+
+func (b brave) Endpoint() string {
+	return strings.TrimSuffix(cmp.Or(b.baseURL, "https://api.search.brave.com"), "/") + "/res/v1/web/search"
+}
+
+func (b brave) Search(ctx context.Context, client *http.Client, query string, maxResults int) ([]SearchResult, error) {
+	q := url.Values{"q": {query}, "count": {strconv.Itoa(maxResults)}}
+	var resp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := getJSON(ctx, client, b.Endpoint()+"?"+q.Encode(), map[string]string{"X-Subscription-Token": b.apiKey}, &resp); err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	for _, r := range resp.Web.Results {
+		results = append(results, SearchResult{Title: r.Title, Link: r.URL, Snippet: stripTags(r.Description)})
+	}
+	return numbered(results, maxResults), nil
+}
+
+type bing struct {
+	baseURL string
+	apiKey  string
+}
+
+func (bing) Name() string { return config.SearchProviderBing }
+
+func (b bing) Endpoint() string {
+	return strings.TrimSuffix(cmp.Or(b.baseURL, "https://api.bing.microsoft.com"), "/") + "/v7.0/search"
+}
+
+func (b bing) Search(ctx context.Context, client *http.Client, query string, maxResults int) ([]SearchResult, error) {
+	q := url.Values{"q": {query}, "count": {strconv.Itoa(maxResults)}, "responseFilter": {"Webpages"}}
+	var resp struct {
+		WebPages struct {
+			Value []struct {
+				Name    string `json:"name"`
+				URL     string `json:"url"`
+				Snippet string `json:"snippet"`
+			} `json:"value"`
+		} `json:"webPages"`
+	}
+	if err := getJSON(ctx, client, b.Endpoint()+"?"+q.Encode(), map[string]string{"Ocp-Apim-Subscription-Key": b.apiKey}, &resp); err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	for _, r := range resp.WebPages.Value {
+		results = append(results, SearchResult{Title: r.Name, Link: r.URL, Snippet: r.Snippet})
+	}
+	return numbered(results, maxResults), nil
+}
+
+type searXNG struct {
+	baseURL string
+}
+
+func (searXNG) Name() string { return config.SearchProviderSearXNG }
+
+func (s searXNG) Endpoint() string {
+	return strings.TrimSuffix(s.baseURL, "/") + "/search"
+}
+
+func (s searXNG) Search(ctx context.Context, client *http.Client, query string, maxResults int) ([]SearchResult, error) {
+	q := url.Values{"q": {query}, "format": {"json"}}
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := getJSON(ctx, client, s.Endpoint()+"?"+q.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	for _, r := range resp.Results {
+		results = append(results, SearchResult{Title: r.Title, Link: r.URL, Snippet: r.Content})
+	}
+	return numbered(results, maxResults), nil
+}
+
+// getJSON decodes the JSON response to a GET of rawURL with headers into v.
+func getJSON(ctx context.Context, client *http.Client, rawURL string, headers map[string]string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "crush/1.0")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("search failed with status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// numbered returns the first maxResults results, with their position set.
+func numbered(results []SearchResult, maxResults int) []SearchResult {
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	for i := range results {
+		results[i].Position = i + 1
+	}
+	return results
+}
+
+// stripTags removes the emphasis tags some engines put in snippets.
+func stripTags(s string) string {
+	for _, tag := range []string{"<strong>", "</strong>", "<b>", "</b>"} {
+		s = strings.ReplaceAll(s, tag, "")
+	}
+	return s
+}
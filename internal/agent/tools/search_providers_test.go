@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchProviders(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/res/v1/web/search":
+			require.Equal(t, "key", r.Header.Get("X-Subscription-Token"))
+			_, _ = w.Write([]byte(`{"web":{"results":[{"title":"Go","url":"https://go.dev","description":"The <strong>Go</strong> language"},{"title":"Pkg","url":"https://pkg.go.dev","description":"Packages"}]}}`))
+		case "/v7.0/search":
+			require.Equal(t, "key", r.Header.Get("Ocp-Apim-Subscription-Key"))
+			_, _ = w.Write([]byte(`{"webPages":{"value":[{"name":"Go","url":"https://go.dev","snippet":"The Go language"}]}}`))
+		case "/search":
+			require.Equal(t, "json", r.URL.Query().Get("format"))
+			_, _ = w.Write([]byte(`{"results":[{"title":"Go","url":"https://go.dev","content":"The Go language"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	for _, name := range []string{config.SearchProviderBrave, config.SearchProviderBing, config.SearchProviderSearXNG} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			provider, err := NewSearchProvider(config.ToolWebSearch{Provider: name, BaseURL: srv.URL}, "key")
+			require.NoError(t, err)
+			require.Equal(t, name, provider.Name())
+
+			results, err := provider.Search(t.Context(), srv.Client(), "golang", 1)
+			require.NoError(t, err)
+			require.Equal(t, []SearchResult{{
+				Title:    "Go",
+				Link:     "https://go.dev",
+				Snippet:  "The Go language",
+				Position: 1,
+			}}, results)
+		})
+	}
+}
+
+func TestNewSearchProvider(t *testing.T) {
+	t.Parallel()
+
+	provider, err := NewSearchProvider(config.ToolWebSearch{}, "")
+	require.NoError(t, err)
+	require.Equal(t, config.SearchProviderDuckDuckGo, provider.Name())
+
+	_, err = NewSearchProvider(config.ToolWebSearch{Provider: config.SearchProviderBrave}, "")
+	require.Error(t, err)
+	_, err = NewSearchProvider(config.ToolWebSearch{Provider: config.SearchProviderSearXNG}, "")
+	require.Error(t, err)
+	_, err = NewSearchProvider(config.ToolWebSearch{Provider: "altavista"}, "")
+	require.Error(t, err)
+}
+
+func TestFilterSearchResults(t *testing.T) {
+	t.Parallel()
+
+	results := []SearchResult{
+		{Link: "https://go.dev/doc"},
+		{Link: "https://www.pinterest.com/pin"},
+		{Link: "https://github.com/golang/go"},
+		{Link: "not a url"},
+	}
+	links := func(results []SearchResult) []string {
+		var links []string
+		for _, r := range results {
+			links = append(links, r.Link)
+		}
+		return links
+	}
+
+	require.Equal(t,
+		[]string{"https://go.dev/doc", "https://github.com/golang/go"},
+		links(filterSearchResults(results, nil, []string{"pinterest.com"})))
+	require.Equal(t,
+		[]string{"https://go.dev/doc"},
+		links(filterSearchResults(results, []string{"go.dev"}, nil)))
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/semantic"
+)
+
+type SemanticSearchParams struct {
+	Query string `json:"query" description:"A natural language description of the code to find (e.g., where do we throttle API requests)"`
+	Limit int    `json:"limit,omitempty" description:"The number of results to return (default 10, max 30)"`
+}
+
+type SemanticSearchResponseMetadata struct {
+	NumberOfResults int `json:"number_of_results"`
+}
+
+const SemanticSearchToolName = "semantic_search"
+
+const (
+	defaultSemanticResults = 10
+	maxSemanticResults     = 30
+)
+
+//go:embed semantic_search.md
+var semanticSearchDescription []byte
+
+func NewSemanticSearchTool(index *semantic.Index, workingDir string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		SemanticSearchToolName,
+		string(semanticSearchDescription),
+		func(ctx context.Context, params SemanticSearchParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if strings.TrimSpace(params.Query) == "" {
+				return fantasy.NewTextErrorResponse("query is required"), nil
+			}
+			limit := params.Limit
+			if limit <= 0 {
+				limit = defaultSemanticResults
+			}
+			limit = min(limit, maxSemanticResults)
+
+			results, err := index.Search(ctx, params.Query, limit)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error searching: %v", err)), nil
+			}
+			if len(results) == 0 {
+				return fantasy.NewTextResponse("The semantic index is empty"), nil
+			}
+
+			var output strings.Builder
+			fmt.Fprintf(&output, "Found %d relevant chunk(s), most relevant first:\n", len(results))
+			for _, r := range results {
+				path := r.Path
+				if rel, err := filepath.Rel(workingDir, r.Path); err == nil && !strings.HasPrefix(rel, "..") {
+					path = rel
+				}
+				fmt.Fprintf(&output, "\n%s:%d-%d (score %.2f)\n", filepath.ToSlash(path), r.StartLine, r.EndLine, r.Score)
+				output.WriteString(addLineNumbers(r.Snippet, r.StartLine))
+				output.WriteString("\n")
+			}
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(output.String()),
+				SemanticSearchResponseMetadata{NumberOfResults: len(results)},
+			), nil
+		})
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/symbols"
+)
+
+type SymbolsParams struct {
+	Query string `json:"query" description:"The symbol name to search for, optionally qualified (e.g., UserService or config.Load)"`
+	Kind  string `json:"kind,omitempty" description:"Only return declarations of this kind (e.g., func, type, class, method)"`
+	Path  string `json:"path,omitempty" description:"The directory to search in. Defaults to the current working directory."`
+}
+
+type SymbolsResponseMetadata struct {
+	NumberOfSymbols int `json:"number_of_symbols"`
+}
+
+const SymbolsToolName = "symbols"
+
+// maxSymbolResults is the number of symbols returned at most.
+const maxSymbolResults = 50
+
+//go:embed symbols.md
+var symbolsDescription []byte
+
+func NewSymbolsTool(workingDir string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		SymbolsToolName,
+		string(symbolsDescription),
+		func(ctx context.Context, params SymbolsParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.Query == "" {
+				return fantasy.NewTextErrorResponse("query is required"), nil
+			}
+
+			searchPath := cmp.Or(params.Path, workingDir)
+			index, err := symbols.Index(ctx, searchPath)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("error indexing symbols: %w", err)
+			}
+			if params.Kind != "" {
+				var filtered []symbols.Symbol
+				for _, s := range index {
+					if strings.EqualFold(s.Kind, params.Kind) {
+						filtered = append(filtered, s)
+					}
+				}
+				index = filtered
+			}
+
+			matches := symbols.Filter(index, params.Query, maxSymbolResults)
+			if len(matches) == 0 {
+				return fantasy.NewTextResponse(fmt.Sprintf("No symbols found matching '%s'", params.Query)), nil
+			}
+
+			var output strings.Builder
+			fmt.Fprintf(&output, "Found %d symbol(s):\n\n", len(matches))
+			for _, s := range matches {
+				path := s.Path
+				if rel, err := filepath.Rel(workingDir, s.Path); err == nil && !strings.HasPrefix(rel, "..") {
+					path = rel
+				}
+				fmt.Fprintf(&output, "%s %s", s.Kind, s.Name)
+				if s.Scope != "" {
+					fmt.Fprintf(&output, " (in %s)", s.Scope)
+				}
+				fmt.Fprintf(&output, " - %s:%d\n", filepath.ToSlash(path), s.Line)
+			}
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(output.String()),
+				SymbolsResponseMetadata{NumberOfSymbols: len(matches)},
+			), nil
+		})
+}
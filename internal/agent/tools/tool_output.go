@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+)
+
+const (
+	ToolOutputToolName = "tool_output"
+
+	// ToolResultsTTL is how long the full results of truncated tool calls
+	// are kept.
+	ToolResultsTTL = 30 * 24 * time.Hour
+
+	// defaultToolOutputLimit is how many lines tool_output reads by default.
+	defaultToolOutputLimit = 500
+)
+
+//go:embed tool_output.md
+var toolOutputDescription []byte
+
+type ToolOutputParams struct {
+	ToolCallID string `json:"tool_call_id" description:"The ID of the tool call whose output was truncated"`
+	Offset     int    `json:"offset,omitempty" description:"The line number to start reading from (0-based)"`
+	Limit      int    `json:"limit,omitempty" description:"The number of lines to read (defaults to 500)"`
+}
+
+type ToolOutputResponseMetadata struct {
+	ToolCallID string `json:"tool_call_id"`
+	Offset     int    `json:"offset"`
+	Lines      int    `json:"lines"`
+	TotalLines int    `json:"total_lines"`
+}
+
+// ToolResults keeps the full results of the tool calls that were truncated,
+// for tool_output to read and the user to inspect. A nil store keeps
+// nothing.
+type ToolResults struct {
+	dir string
+}
+
+// NewToolResults returns the store keeping results in the tool_results
+// folder of dataDir.
+func NewToolResults(dataDir string) *ToolResults {
+	return &ToolResults{dir: filepath.Join(dataDir, "tool_results")}
+}
+
+func (r *ToolResults) path(toolCallID string) string {
+	sum := sha256.Sum256([]byte(toolCallID))
+	return filepath.Join(r.dir, hex.EncodeToString(sum[:]))
+}
+
+// Save keeps the full result of a tool call.
+func (r *ToolResults) Save(toolCallID, content string) error {
+	if r == nil {
+		return nil
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path(toolCallID), []byte(content), 0o644)
+}
+
+// Load returns the full result of a tool call, and false when it wasn't
+// truncated or was pruned since.
+func (r *ToolResults) Load(toolCallID string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	content, err := os.ReadFile(r.path(toolCallID))
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// Prune removes the results kept for longer than ttl.
+func (r *ToolResults) Prune(ttl time.Duration) error {
+	if r == nil {
+		return nil
+	}
+	entries, err := os.ReadDir(r.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < ttl {
+			continue
+		}
+		if err := os.Remove(filepath.Join(r.dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TruncateResult cuts the middle of a result longer than MaxOutputLength,
+// telling the agent how to read it with tool_output.
+func TruncateResult(toolCallID, content string) string {
+	if len(content) <= MaxOutputLength {
+		return content
+	}
+	half := MaxOutputLength / 2
+	start := content[:half]
+	end := content[len(content)-half:]
+	offset := strings.Count(start, "\n")
+	truncated := countLines(content[half : len(content)-half])
+	return fmt.Sprintf(
+		"%s\n\n... [%d lines truncated: call %s with tool_call_id %q and offset %d to read them] ...\n\n%s",
+		start, truncated, ToolOutputToolName, toolCallID, offset, end,
+	)
+}
+
+func NewToolOutputTool(results *ToolResults) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		ToolOutputToolName,
+		string(toolOutputDescription),
+		func(ctx context.Context, params ToolOutputParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.ToolCallID == "" {
+				return fantasy.NewTextErrorResponse("missing tool_call_id"), nil
+			}
+			if params.Offset < 0 || params.Limit < 0 {
+				return fantasy.NewTextErrorResponse("offset and limit must not be negative"), nil
+			}
+			content, ok := results.Load(params.ToolCallID)
+			if !ok {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("no truncated output for tool call %s", params.ToolCallID)), nil
+			}
+			limit := params.Limit
+			if limit == 0 {
+				limit = defaultToolOutputLimit
+			}
+
+			lines := strings.Split(content, "\n")
+			if params.Offset >= len(lines) {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("offset %d is past the end of the output, which has %d lines", params.Offset, len(lines))), nil
+			}
+			end := min(len(lines), params.Offset+limit)
+			var output strings.Builder
+			for i := params.Offset; i < end; i++ {
+				if output.Len()+len(lines[i]) > MaxOutputLength {
+					if i == params.Offset {
+						// Cut a line too long to read whole.
+						output.WriteString(lines[i][:MaxOutputLength])
+						output.WriteByte('\n')
+						i++
+					}
+					end = i
+					break
+				}
+				output.WriteString(lines[i])
+				output.WriteByte('\n')
+			}
+			fmt.Fprintf(&output, "\n(Lines %d to %d of %d.", params.Offset, end-1, len(lines))
+			if end < len(lines) {
+				fmt.Fprintf(&output, " Use offset %d to read on.", end)
+			}
+			output.WriteString(")")
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(output.String()),
+				ToolOutputResponseMetadata{
+					ToolCallID: params.ToolCallID,
+					Offset:     params.Offset,
+					Lines:      end - params.Offset,
+					TotalLines: len(lines),
+				},
+			), nil
+		})
+}
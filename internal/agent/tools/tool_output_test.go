@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateResult(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "short", TruncateResult("call_1", "short"))
+
+	var lines []string
+	for i := range 10000 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	content := strings.Join(lines, "\n")
+	truncated := TruncateResult("call_1", content)
+	require.Less(t, len(truncated), len(content))
+	require.True(t, strings.HasPrefix(truncated, "line 0\n"))
+	require.True(t, strings.HasSuffix(truncated, "line 9999"))
+	require.Contains(t, truncated, `call tool_output with tool_call_id "call_1"`)
+}
+
+func TestToolOutputTool(t *testing.T) {
+	t.Parallel()
+
+	results := NewToolResults(t.TempDir())
+	_, ok := results.Load("call_1")
+	require.False(t, ok)
+	require.NoError(t, results.Save("call_1", "a\nb\nc\nd"))
+
+	tool := NewToolOutputTool(results)
+	run := func(params ToolOutputParams) fantasy.ToolResponse {
+		input, err := json.Marshal(params)
+		require.NoError(t, err)
+		resp, err := tool.Run(t.Context(), fantasy.ToolCall{ID: "call_2", Name: ToolOutputToolName, Input: string(input)})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := run(ToolOutputParams{ToolCallID: "call_1", Offset: 1, Limit: 2})
+	require.False(t, resp.IsError)
+	require.Equal(t, "b\nc\n\n(Lines 1 to 2 of 4. Use offset 3 to read on.)", resp.Content)
+
+	resp = run(ToolOutputParams{ToolCallID: "call_1", Offset: 3})
+	require.Equal(t, "d\n\n(Lines 3 to 3 of 4.)", resp.Content)
+
+	require.True(t, run(ToolOutputParams{ToolCallID: "call_1", Offset: 4}).IsError)
+	require.True(t, run(ToolOutputParams{ToolCallID: "unknown"}).IsError)
+}
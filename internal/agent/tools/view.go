@@ -64,6 +64,10 @@ func NewViewTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 			// Handle relative paths
 			filePath := filepathext.SmartJoin(workingDir, params.FilePath)
 
+			if resp, ok := crushIgnored(workingDir, filePath); ok {
+				return resp, nil
+			}
+
 			// Check if file is outside working directory and request permission if needed
 			absWorkingDir, err := filepath.Abs(workingDir)
 			if err != nil {
@@ -186,6 +190,7 @@ func NewViewTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 			}
 			output += "\n</file>\n"
 			output += getDiagnostics(filePath, lspClients)
+			output += nestedInstructions(ctx, workingDir, filePath)
 			recordFileRead(filePath)
 			return fantasy.WithResponseMetadata(
 				fantasy.NewTextResponse(output),
@@ -3,17 +3,36 @@ package tools
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/permission"
 )
 
 //go:embed web_search.md
 var webSearchToolDescription []byte
 
-// NewWebSearchTool creates a web search tool for sub-agents (no permissions needed).
-func NewWebSearchTool(client *http.Client) fantasy.AgentTool {
+// WebSearchOptions configures the web_search tool.
+type WebSearchOptions struct {
+	// Provider is the search engine; DuckDuckGo when nil.
+	Provider SearchProvider
+	// Permissions is asked before searching; sub-agents don't ask when nil.
+	Permissions permission.Service
+	WorkingDir  string
+	// PermittedDomains are search engines searched without asking for
+	// permission.
+	PermittedDomains []string
+	// AllowedDomains, when set, are the only domains results come from.
+	AllowedDomains []string
+	// BlockedDomains are domains results never come from.
+	BlockedDomains []string
+}
+
+// NewWebSearchTool creates a web search tool.
+func NewWebSearchTool(opts WebSearchOptions, client *http.Client) fantasy.AgentTool {
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
@@ -24,6 +43,10 @@ func NewWebSearchTool(client *http.Client) fantasy.AgentTool {
 			},
 		}
 	}
+	provider := opts.Provider
+	if provider == nil {
+		provider = duckDuckGo{}
+	}
 
 	return fantasy.NewParallelAgentTool(
 		WebSearchToolName,
@@ -41,11 +64,77 @@ func NewWebSearchTool(client *http.Client) fantasy.AgentTool {
 				maxResults = 20
 			}
 
-			results, err := searchDuckDuckGo(ctx, client, params.Query, maxResults)
+			if opts.Permissions != nil && !searchPermitted(provider, opts.PermittedDomains) {
+				sessionID := GetSessionFromContext(ctx)
+				if sessionID == "" {
+					return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for searching the web")
+				}
+				p := opts.Permissions.Request(
+					permission.CreatePermissionRequest{
+						SessionID:   sessionID,
+						Path:        opts.WorkingDir,
+						ToolCallID:  call.ID,
+						ToolName:    WebSearchToolName,
+						Action:      "search",
+						Description: fmt.Sprintf("Search the web with %s: %s", provider.Name(), params.Query),
+						Params: WebSearchPermissionsParams{
+							Query:    params.Query,
+							Provider: provider.Name(),
+						},
+					},
+				)
+				if !p {
+					return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+				}
+			}
+
+			// Ask for more when some results may be filtered out.
+			limit := maxResults
+			if len(opts.AllowedDomains) > 0 || len(opts.BlockedDomains) > 0 {
+				limit = 20
+			}
+			results, err := provider.Search(ctx, client, params.Query, limit)
 			if err != nil {
 				return fantasy.NewTextErrorResponse("Failed to search: " + err.Error()), nil
 			}
+			filtered := filterSearchResults(results, opts.AllowedDomains, opts.BlockedDomains)
+			kept := numbered(filtered, maxResults)
 
-			return fantasy.NewTextResponse(formatSearchResults(results)), nil
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(formatSearchResults(kept)),
+				WebSearchResponseMetadata{
+					Provider: provider.Name(),
+					Query:    params.Query,
+					Results:  kept,
+					Filtered: len(results) - len(filtered),
+				},
+			), nil
 		})
 }
+
+// searchPermitted reports whether provider may be searched without asking.
+func searchPermitted(provider SearchProvider, domains []string) bool {
+	u, err := url.Parse(provider.Endpoint())
+	return err == nil && DomainAllowed(u.Hostname(), domains)
+}
+
+// filterSearchResults returns the results from the allowed domains, or any
+// domain when none is, that aren't from a blocked domain.
+func filterSearchResults(results []SearchResult, allowed, blocked []string) []SearchResult {
+	var kept []SearchResult
+	for _, result := range results {
+		u, err := url.Parse(result.Link)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := u.Hostname()
+		if len(allowed) > 0 && !DomainAllowed(host, allowed) {
+			continue
+		}
+		if DomainAllowed(host, blocked) {
+			continue
+		}
+		kept = append(kept, result)
+	}
+	return kept
+}
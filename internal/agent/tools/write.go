@@ -65,6 +65,10 @@ func NewWriteTool(lspClients *csync.Map[string, *lsp.Client], permissions permis
 
 			filePath := filepathext.SmartJoin(workingDir, params.FilePath)
 
+			if resp, ok := crushIgnored(workingDir, filePath); ok {
+				return resp, nil
+			}
+
 			fileInfo, err := os.Stat(filePath)
 			if err == nil {
 				if fileInfo.IsDir() {
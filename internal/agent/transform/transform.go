@@ -0,0 +1,222 @@
+// Package transform rewrites the JSON bodies exchanged with a provider, so
+// proxies and self-hosted backends that differ a bit from the API they mimic
+// can be used without code changes.
+package transform
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+// Apply returns v, a decoded JSON value, rewritten by t.
+func Apply(v any, t config.ProviderTransform) any {
+	for path, name := range t.Rename {
+		rename(v, strings.Split(path, "."), name)
+	}
+	for _, path := range t.Remove {
+		remove(v, strings.Split(path, "."))
+	}
+	for path, value := range t.Set {
+		v = set(v, strings.Split(path, "."), value)
+	}
+	return v
+}
+
+// ApplyJSON returns data rewritten by t, or data as is when it isn't JSON.
+func ApplyJSON(data []byte, t config.ProviderTransform) []byte {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	out, err := json.Marshal(Apply(v, t))
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// each calls fn with every object path leads to, and the last key of path.
+func each(v any, path []string, fn func(obj map[string]any, key string)) {
+	if len(path) == 0 {
+		return
+	}
+	if path[0] == "*" {
+		if arr, ok := v.([]any); ok {
+			for _, elem := range arr {
+				each(elem, path[1:], fn)
+			}
+		}
+		return
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		fn(obj, path[0])
+		return
+	}
+	each(obj[path[0]], path[1:], fn)
+}
+
+func rename(v any, path []string, name string) {
+	each(v, path, func(obj map[string]any, key string) {
+		if value, ok := obj[key]; ok {
+			delete(obj, key)
+			obj[name] = value
+		}
+	})
+}
+
+func remove(v any, path []string) {
+	each(v, path, func(obj map[string]any, key string) {
+		delete(obj, key)
+	})
+}
+
+// set returns v with the field at path set to value, creating the objects
+// leading to it.
+func set(v any, path []string, value any) any {
+	if len(path) == 0 {
+		return value
+	}
+	if path[0] == "*" {
+		if arr, ok := v.([]any); ok {
+			for i, elem := range arr {
+				arr[i] = set(elem, path[1:], value)
+			}
+		}
+		return v
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		obj = map[string]any{}
+	}
+	obj[path[0]] = set(obj[path[0]], path[1:], value)
+	return obj
+}
+
+// NewClient returns a copy of client, or of the default client when nil,
+// rewriting requests and responses. Either transform may be nil.
+func NewClient(client *http.Client, request, response *config.ProviderTransform) *http.Client {
+	var c http.Client
+	if client != nil {
+		c = *client
+	}
+	c.Transport = &Transport{
+		Transport: c.Transport,
+		Request:   request,
+		Response:  response,
+	}
+	return &c
+}
+
+// Transport is an http.RoundTripper rewriting the JSON bodies of requests
+// and responses, including each event of streamed responses.
+type Transport struct {
+	// Transport sends the requests; http.DefaultTransport when nil.
+	Transport http.RoundTripper
+	Request   *config.ProviderTransform
+	Response  *config.ProviderTransform
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Request != nil && req.Body != nil && isJSON(req.Header.Get("Content-Type")) {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = ApplyJSON(body, *t.Request)
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.ContentLength = int64(len(body))
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || t.Response == nil {
+		return resp, err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "text/event-stream"):
+		resp.Body = &eventStream{
+			src:       bufio.NewReader(resp.Body),
+			closer:    resp.Body,
+			transform: *t.Response,
+		}
+	case isJSON(contentType):
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = ApplyJSON(body, *t.Response)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Del("Content-Length")
+	}
+	return resp, nil
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(contentType, "application/json")
+}
+
+// eventStream rewrites the data of each server-sent event read from src.
+type eventStream struct {
+	src       *bufio.Reader
+	closer    io.Closer
+	transform config.ProviderTransform
+	buf       []byte
+	err       error
+}
+
+func (s *eventStream) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		var line []byte
+		line, s.err = s.src.ReadBytes('\n')
+		s.buf = s.rewrite(line)
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// rewrite returns line rewritten when it is the JSON data of an event.
+func (s *eventStream) rewrite(line []byte) []byte {
+	data, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return line
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return line
+	}
+	out := append([]byte("data: "), ApplyJSON(trimmed, s.transform)...)
+	if bytes.HasSuffix(line, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	return out
+}
+
+func (s *eventStream) Close() error {
+	return s.closer.Close()
+}
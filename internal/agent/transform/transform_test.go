@@ -0,0 +1,48 @@
+package transform
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyJSON(t *testing.T) {
+	t.Parallel()
+
+	got := ApplyJSON([]byte(`{"model":"m","stream_options":{"include_usage":true},"choices":[{"delta":{"reasoning":"a"}},{"delta":{"content":"b"}}]}`), config.ProviderTransform{
+		Rename: map[string]string{"choices.*.delta.reasoning": "reasoning_content"},
+		Remove: []string{"stream_options"},
+		Set:    map[string]any{"options.num_ctx": 8192.0},
+	})
+	require.JSONEq(t, `{"model":"m","options":{"num_ctx":8192},"choices":[{"delta":{"reasoning_content":"a"}},{"delta":{"content":"b"}}]}`, string(got))
+
+	require.Equal(t, "[DONE]", string(ApplyJSON([]byte("[DONE]"), config.ProviderTransform{Remove: []string{"x"}})))
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.JSONEq(t, `{"model":"m","keep_alive":"5m"}`, string(body))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"reasoning\":\"think\"}\n\ndata: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewClient(srv.Client(),
+		&config.ProviderTransform{Set: map[string]any{"keep_alive": "5m"}},
+		&config.ProviderTransform{Rename: map[string]string{"reasoning": "reasoning_content"}},
+	)
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"model":"m"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "data: {\"reasoning_content\":\"think\"}\n\ndata: [DONE]\n\n", string(body))
+}
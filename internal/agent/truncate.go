@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+)
+
+// truncatedTool truncates the results of the tool too long for the
+// context, keeping them whole for tool_output to read.
+type truncatedTool struct {
+	fantasy.AgentTool
+	results *tools.ToolResults
+}
+
+// withTruncation wraps the tools to truncate their long results.
+func withTruncation(results *tools.ToolResults, agentTools []fantasy.AgentTool) []fantasy.AgentTool {
+	wrapped := make([]fantasy.AgentTool, len(agentTools))
+	for i, tool := range agentTools {
+		if tool.Info().Name == tools.ToolOutputToolName {
+			wrapped[i] = tool
+			continue
+		}
+		wrapped[i] = &truncatedTool{AgentTool: tool, results: results}
+	}
+	return wrapped
+}
+
+func (t *truncatedTool) Run(ctx context.Context, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	resp, err := t.AgentTool.Run(ctx, call)
+	if err != nil || resp.Type != "text" || len(resp.Content) <= tools.MaxOutputLength {
+		return resp, err
+	}
+	if err := t.results.Save(call.ID, resp.Content); err != nil {
+		slog.Warn("Failed to keep the full result of a tool call", "tool", call.Name, "error", err)
+	}
+	resp.Content = tools.TruncateResult(call.ID, resp.Content)
+	return resp, nil
+}
@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+)
+
+// webSearchOptions returns the options of the web_search tool, with the
+// configured search engine.
+func (c *coordinator) webSearchOptions() (tools.WebSearchOptions, error) {
+	cfg := c.cfg.Tools.WebSearch
+	apiKey := cfg.APIKey
+	if apiKey != "" {
+		resolved, err := c.cfg.Resolver().ResolveValue(apiKey)
+		if err != nil {
+			return tools.WebSearchOptions{}, fmt.Errorf("failed to resolve web search API key: %w", err)
+		}
+		apiKey = resolved
+	}
+	provider, err := tools.NewSearchProvider(cfg, apiKey)
+	if err != nil {
+		return tools.WebSearchOptions{}, err
+	}
+	return tools.WebSearchOptions{
+		Provider:         provider,
+		Permissions:      c.permissions,
+		WorkingDir:       c.cfg.WorkingDir(),
+		PermittedDomains: c.cfg.AllowedDomains(),
+		AllowedDomains:   cfg.AllowedDomains,
+		BlockedDomains:   cfg.BlockedDomains,
+	}, nil
+}
@@ -0,0 +1,380 @@
+// Package apiserver exposes sessions, messages, tool events and permission
+// requests over a local HTTP API with server-sent events, so editors and
+// other frontends can drive the same agent the TUI uses.
+package apiserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// pingInterval is how often idle event streams get a keep-alive comment.
+const pingInterval = 30 * time.Second
+
+// Server serves the API. Every request must carry the token, either as a
+// bearer token or, for EventSource clients that can't set headers, as the
+// token query parameter. An empty token turns the check off, for listeners
+// only the user can reach, such as unix sockets.
+type Server struct {
+	sessions    session.Service
+	messages    message.Service
+	permissions permission.Service
+	coordinator agent.Coordinator
+	token       string
+
+	// pending holds the permission requests waiting for an answer.
+	pending *csync.Map[string, permission.PermissionRequest]
+	events  *pubsub.Broker[Event]
+	mux     *http.ServeMux
+}
+
+// New creates a server. The coordinator may be nil when no provider is
+// configured, in which case prompts are rejected.
+func New(
+	sessions session.Service,
+	messages message.Service,
+	permissions permission.Service,
+	coordinator agent.Coordinator,
+	token string,
+) *Server {
+	s := &Server{
+		sessions:    sessions,
+		messages:    messages,
+		permissions: permissions,
+		coordinator: coordinator,
+		token:       token,
+		pending:     csync.NewMap[string, permission.PermissionRequest](),
+		events:      pubsub.NewBroker[Event](),
+		mux:         http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /v1/sessions", s.listSessions)
+	s.mux.HandleFunc("POST /v1/sessions", s.createSession)
+	s.mux.HandleFunc("GET /v1/sessions/{id}", s.getSession)
+	s.mux.HandleFunc("DELETE /v1/sessions/{id}", s.deleteSession)
+	s.mux.HandleFunc("GET /v1/sessions/{id}/messages", s.listMessages)
+	s.mux.HandleFunc("POST /v1/sessions/{id}/messages", s.sendMessage)
+	s.mux.HandleFunc("POST /v1/sessions/{id}/cancel", s.cancel)
+	s.mux.HandleFunc("GET /v1/permissions", s.listPermissions)
+	s.mux.HandleFunc("POST /v1/permissions/{id}", s.answerPermission)
+	s.mux.HandleFunc("GET /v1/events", s.streamEvents)
+	return s
+}
+
+// Start forwards the events of the services to the event stream until ctx
+// is done.
+func (s *Server) Start(ctx context.Context) {
+	forward(ctx, s.sessions.Subscribe, func(e pubsub.Event[session.Session]) {
+		s.publish(e.Type, EventSession, FromSession(e.Payload))
+	})
+	forward(ctx, s.messages.Subscribe, func(e pubsub.Event[message.Message]) {
+		s.publish(e.Type, EventMessage, FromMessage(e.Payload))
+	})
+	forward(ctx, s.permissions.Subscribe, func(e pubsub.Event[permission.PermissionRequest]) {
+		s.pending.Set(e.Payload.ID, e.Payload)
+		s.publish(e.Type, EventPermission, e.Payload)
+	})
+	forward(ctx, s.permissions.SubscribeNotifications, func(e pubsub.Event[permission.PermissionNotification]) {
+		if e.Payload.Granted || e.Payload.Denied {
+			for id, req := range s.pending.Seq2() {
+				if req.ToolCallID == e.Payload.ToolCallID {
+					s.pending.Del(id)
+				}
+			}
+		}
+		s.publish(e.Type, EventPermissionNotification, e.Payload)
+	})
+	go func() {
+		<-ctx.Done()
+		s.events.Shutdown()
+	}()
+}
+
+func forward[T any](ctx context.Context, subscribe func(context.Context) <-chan pubsub.Event[T], handle func(pubsub.Event[T])) {
+	events := subscribe(ctx)
+	go func() {
+		for e := range events {
+			handle(e)
+		}
+	}()
+}
+
+func (s *Server) publish(action pubsub.EventType, kind string, data any) {
+	s.events.Publish(action, Event{Type: kind, Action: string(action), Data: data})
+}
+
+// ServeHTTP checks the token and serves the API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && !validToken(r, s.token) {
+		writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// RequireToken serves next to the requests carrying token, as a bearer
+// token or the token query parameter, like the API does.
+func RequireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validToken(r, token) {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validToken(r *http.Request, want string) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	return want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
+
+// ReadOnly serves the API to spectators, who can watch the sessions and
+// their events but neither prompt the agent nor answer permissions.
+func (s *Server) ReadOnly() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, http.StatusForbidden, errors.New("the API is read-only"))
+			return
+		}
+		s.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	list, err := s.sessions.List(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	sessions := make([]Session, 0, len(list))
+	for _, sess := range list {
+		sessions = append(sessions, FromSession(sess))
+	}
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Title string `json:"title"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Title == "" {
+		body.Title = "New Session"
+	}
+	sess, err := s.sessions.Create(r.Context(), body.Title)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, FromSession(sess))
+}
+
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, FromSession(sess))
+}
+
+func (s *Server) deleteSession(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	if s.coordinator != nil && s.coordinator.IsSessionBusy(sess.ID) {
+		writeError(w, http.StatusConflict, errors.New("session is busy"))
+		return
+	}
+	if err := s.sessions.Delete(r.Context(), sess.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listMessages(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	list, err := s.messages.List(r.Context(), sess.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	messages := make([]Message, 0, len(list))
+	for _, msg := range list {
+		messages = append(messages, FromMessage(msg))
+	}
+	writeJSON(w, http.StatusOK, messages)
+}
+
+// sendMessage runs the agent on the prompt in the background; the progress
+// is reported through the event stream.
+func (s *Server) sendMessage(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	if s.coordinator == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("no provider configured"))
+		return
+	}
+	var body struct {
+		Content string `json:"content"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if strings.TrimSpace(body.Content) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("content is required"))
+		return
+	}
+	go func() {
+		_, err := s.coordinator.Run(context.Background(), sess.ID, body.Content)
+		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, permission.ErrorPermissionDenied) {
+			slog.Error("API prompt failed", "session_id", sess.ID, "error", err)
+			s.publish(pubsub.CreatedEvent, EventError, Error{SessionID: sess.ID, Error: err.Error()})
+		}
+	}()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) cancel(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	if s.coordinator != nil {
+		s.coordinator.Cancel(sess.ID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listPermissions(w http.ResponseWriter, _ *http.Request) {
+	requests := slices.Collect(s.pending.Seq())
+	if requests == nil {
+		requests = []permission.PermissionRequest{}
+	}
+	writeJSON(w, http.StatusOK, requests)
+}
+
+func (s *Server) answerPermission(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Action string `json:"action"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if !slices.Contains([]string{"allow", "allow_session", "deny"}, body.Action) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid action %q, use allow, allow_session or deny", body.Action))
+		return
+	}
+	// Taking the request makes sure it is only answered once.
+	req, ok := s.pending.Take(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("permission request not found"))
+		return
+	}
+	switch body.Action {
+	case "allow":
+		s.permissions.Grant(req)
+	case "allow_session":
+		s.permissions.GrantPersistent(req)
+	case "deny":
+		s.permissions.Deny(req)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// streamEvents streams the events as server-sent events until the client
+// goes away.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+	events := s.events.Subscribe(r.Context())
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Comments keep idle connections from being closed by proxies.
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e.Payload)
+			if err != nil {
+				slog.Error("Failed to encode event", "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Payload.Type, data); err != nil {
+				return
+			}
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func (s *Server) session(w http.ResponseWriter, r *http.Request) (session.Session, bool) {
+	sess, err := s.sessions.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, errors.New("session not found"))
+		return session.Session{}, false
+	}
+	return sess, true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	// An empty body is fine for requests without required fields.
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, Error{Error: err.Error()})
+}
@@ -0,0 +1,138 @@
+package apiserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+
+	permissions := permission.NewPermissionService(t.TempDir(), false, nil)
+	srv := New(session.NewService(q), message.NewService(q), permissions, nil, "secret")
+	srv.Start(t.Context())
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	do := func(t *testing.T, method, path, body string, out any) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), method, ts.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if out != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+		return resp.StatusCode
+	}
+
+	t.Run("rejects requests without the token", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/v1/sessions")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("creates and lists sessions", func(t *testing.T) {
+		var created Session
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/v1/sessions", `{"title":"From the editor"}`, &created))
+		require.Equal(t, "From the editor", created.Title)
+
+		var sessions []Session
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/v1/sessions", "", &sessions))
+		require.Contains(t, sessions, created)
+
+		var messages []Message
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/v1/sessions/"+created.ID+"/messages", "", &messages))
+		require.Empty(t, messages)
+
+		require.Equal(t, http.StatusServiceUnavailable, do(t, http.MethodPost, "/v1/sessions/"+created.ID+"/messages", `{"content":"hi"}`, nil))
+		require.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/v1/sessions/missing", "", nil))
+	})
+
+	t.Run("streams events", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, ts.URL+"/v1/events?token=secret", nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/v1/sessions", `{"title":"Streamed"}`, nil))
+
+		scanner := bufio.NewScanner(resp.Body)
+		require.True(t, scanner.Scan())
+		require.Equal(t, "event: session", scanner.Text())
+		require.True(t, scanner.Scan())
+		var event struct {
+			Type   string  `json:"type"`
+			Action string  `json:"action"`
+			Data   Session `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(scanner.Text(), "data: ")), &event))
+		require.Equal(t, "created", event.Action)
+		require.Equal(t, "Streamed", event.Data.Title)
+	})
+
+	t.Run("answers permission requests", func(t *testing.T) {
+		granted := make(chan bool, 1)
+		go func() {
+			granted <- permissions.Request(permission.CreatePermissionRequest{
+				SessionID:   "s",
+				ToolCallID:  "call",
+				ToolName:    "bash",
+				Action:      "execute",
+				Description: "Execute command: ls",
+				Path:        ".",
+			})
+		}()
+
+		var pending []permission.PermissionRequest
+		require.Eventually(t, func() bool {
+			do(t, http.MethodGet, "/v1/permissions", "", &pending)
+			return len(pending) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/v1/permissions/"+pending[0].ID, `{"action":"maybe"}`, nil))
+		require.Equal(t, http.StatusNoContent, do(t, http.MethodPost, "/v1/permissions/"+pending[0].ID, `{"action":"allow"}`, nil))
+		require.True(t, <-granted)
+		require.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/v1/permissions/"+pending[0].ID, `{"action":"deny"}`, nil))
+	})
+}
+
+func TestReadOnly(t *testing.T) {
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+
+	srv := New(session.NewService(q), message.NewService(q), permission.NewPermissionService(t.TempDir(), false, nil), nil, "")
+	ts := httptest.NewServer(srv.ReadOnly())
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/v1/sessions")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "no token is needed without one")
+
+	resp, err = http.Post(ts.URL+"/v1/sessions", "application/json", strings.NewReader(`{"title":"Spectated"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,102 @@
+package apiserver
+
+import (
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// Event types of the event stream.
+const (
+	EventSession                = "session"
+	EventMessage                = "message"
+	EventPermission             = "permission"
+	EventPermissionNotification = "permission_notification"
+	EventError                  = "error"
+)
+
+// Event is a server-sent event. Action is created, updated or deleted.
+type Event struct {
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	Data   any    `json:"data"`
+}
+
+// Error is the body of failed requests and of error events.
+type Error struct {
+	SessionID string `json:"session_id,omitempty"`
+	Error     string `json:"error"`
+}
+
+// Session is a session as exposed by the API.
+type Session struct {
+	ID               string   `json:"id"`
+	ParentSessionID  string   `json:"parent_session_id,omitempty"`
+	Title            string   `json:"title"`
+	MessageCount     int64    `json:"message_count"`
+	PromptTokens     int64    `json:"prompt_tokens"`
+	CompletionTokens int64    `json:"completion_tokens"`
+	Cost             float64  `json:"cost"`
+	Tags             []string `json:"tags,omitempty"`
+	CreatedAt        int64    `json:"created_at"`
+	UpdatedAt        int64    `json:"updated_at"`
+}
+
+// FromSession converts a session to its API representation.
+func FromSession(s session.Session) Session {
+	return Session{
+		ID:               s.ID,
+		ParentSessionID:  s.ParentSessionID,
+		Title:            s.Title,
+		MessageCount:     s.MessageCount,
+		PromptTokens:     s.PromptTokens,
+		CompletionTokens: s.CompletionTokens,
+		Cost:             s.Cost,
+		Tags:             s.Tags,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
+	}
+}
+
+// Message is a message as exposed by the API, with its parts flattened.
+type Message struct {
+	ID          string               `json:"id"`
+	SessionID   string               `json:"session_id"`
+	Role        message.MessageRole  `json:"role"`
+	Text        string               `json:"text,omitempty"`
+	Reasoning   string               `json:"reasoning,omitempty"`
+	ToolCalls   []message.ToolCall   `json:"tool_calls,omitempty"`
+	ToolResults []message.ToolResult `json:"tool_results,omitempty"`
+	Finish      *message.Finish      `json:"finish,omitempty"`
+	Model       string               `json:"model,omitempty"`
+	Provider    string               `json:"provider,omitempty"`
+	// ParentMessageID is the assistant message tool results answer.
+	ParentMessageID  string `json:"parent_message_id,omitempty"`
+	PromptTokens     int64  `json:"prompt_tokens,omitempty"`
+	CompletionTokens int64  `json:"completion_tokens,omitempty"`
+	LatencyMs        int64  `json:"latency_ms,omitempty"`
+	CreatedAt        int64  `json:"created_at"`
+	UpdatedAt        int64  `json:"updated_at"`
+}
+
+// FromMessage converts a message to its API representation.
+func FromMessage(m message.Message) Message {
+	return Message{
+		ID:          m.ID,
+		SessionID:   m.SessionID,
+		Role:        m.Role,
+		Text:        m.Content().Text,
+		Reasoning:   m.ReasoningContent().Thinking,
+		ToolCalls:   m.ToolCalls(),
+		ToolResults: m.ToolResults(),
+		Finish:      m.FinishPart(),
+		Model:       m.Model,
+		Provider:    m.Provider,
+
+		ParentMessageID:  m.ParentMessageID,
+		PromptTokens:     m.PromptTokens,
+		CompletionTokens: m.CompletionTokens,
+		LatencyMs:        m.Latency.Milliseconds(),
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+}
@@ -18,19 +18,29 @@ import (
 	"charm.land/fantasy"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/agent/tools"
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/ci"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/filewatch"
 	"github.com/charmbracelet/crush/internal/format"
 	"github.com/charmbracelet/crush/internal/history"
 	"github.com/charmbracelet/crush/internal/log"
 	"github.com/charmbracelet/crush/internal/lsp"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/plan"
 	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/ratelimit"
+	"github.com/charmbracelet/crush/internal/redact"
+	"github.com/charmbracelet/crush/internal/remote"
+	"github.com/charmbracelet/crush/internal/semantic"
 	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/charmbracelet/crush/internal/stats"
+	"github.com/charmbracelet/crush/internal/task"
 	"github.com/charmbracelet/crush/internal/tui/components/anim"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/update"
@@ -45,13 +55,26 @@ type App struct {
 	Messages    message.Service
 	History     history.Service
 	Permissions permission.Service
+	Plans       plan.Service
+	Tasks       task.Service
+	FileWatch   filewatch.Service
+	CI          ci.Service
+	Stats       stats.Service
 
 	AgentCoordinator agent.Coordinator
 
 	LSPClients *csync.Map[string, *lsp.Client]
 
+	// SemanticIndex is the embedding index of the workspace, nil when
+	// semantic search is not configured.
+	SemanticIndex *semantic.Index
+
 	config *config.Config
 
+	// interrupted are the sessions Crush was responding in when it last
+	// exited without finishing.
+	interrupted []session.Session
+
 	serviceEventsWG *sync.WaitGroup
 	eventsCtx       context.Context
 	events          chan tea.Msg
@@ -74,11 +97,21 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 		allowedTools = cfg.Permissions.AllowedTools
 	}
 
+	var ciProvider ci.Provider
+	if !cfg.Options.TUI.DisableCIStatus {
+		ciProvider = ci.Detect(ctx, cfg.WorkingDir())
+	}
+
 	app := &App{
 		Sessions:    sessions,
 		Messages:    messages,
 		History:     files,
 		Permissions: permission.NewPermissionService(cfg.WorkingDir(), skipPermissionsRequests, allowedTools),
+		Plans:       plan.NewService(),
+		Tasks:       task.NewService(q, conn),
+		FileWatch:   filewatch.NewService(tools.ReadFiles),
+		CI:          ci.NewService(cfg.WorkingDir(), ciProvider),
+		Stats:       stats.NewService(q),
 		LSPClients:  csync.NewMap[string, *lsp.Client](),
 
 		globalCtx: ctx,
@@ -90,13 +123,42 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 		tuiWG:           &sync.WaitGroup{},
 	}
 
+	// Close what Crush was doing when it last exited without finishing,
+	// before saving what this one does.
+	app.interrupted = app.recoverInterrupted(ctx)
+	go app.trackInFlight(ctx)
+	go app.extractTasks(ctx)
+	go app.invalidateSymbols(ctx)
+	go app.followActiveRoot(ctx)
+
 	app.setupEvents()
 
-	// Initialize LSP clients in the background.
-	app.initLSPClients(ctx)
+	// The LSPs and the semantic index work on local files, while the files
+	// of a remote workspace are on its host.
+	if remote.New(cfg) == nil {
+		// Initialize LSP clients in the background.
+		app.initLSPClients(ctx)
+
+		// Update the semantic index in the background.
+		app.initSemanticIndex(ctx)
+	}
+
+	// Notice files changed outside Crush in the background.
+	if !cfg.Options.TUI.DisableFileWatch {
+		go app.FileWatch.Start(ctx, filewatch.DefaultInterval)
+	}
+
+	// Apply the config files when they change.
+	go cfg.Watch(ctx, config.DefaultReloadInterval)
+	go app.applyConfigReloads(ctx)
+
+	// Follow the CI status of the branch in the background.
+	go app.CI.Start(ctx, ci.DefaultInterval)
 
 	// Check for updates in the background.
-	go app.checkForUpdates(ctx)
+	if !cfg.Options.DisableUpdateCheck {
+		go app.checkForUpdates(ctx)
+	}
 
 	go func() {
 		slog.Info("Initializing MCP clients")
@@ -104,7 +166,7 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 	}()
 
 	// cleanup database upon app shutdown
-	app.cleanupFuncs = append(app.cleanupFuncs, conn.Close, mcp.Close)
+	app.cleanupFuncs = append(app.cleanupFuncs, conn.Close, mcp.Close, app.removeInFlight)
 
 	// TODO: remove the concept of agent config, most likely.
 	if !cfg.IsConfigured() {
@@ -117,14 +179,23 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 	return app, nil
 }
 
+// InterruptedSessions returns the sessions Crush was responding in when it
+// last exited without finishing, most recent first. What was streamed and
+// the tool calls made are kept, closed so that they can be continued.
+func (app *App) InterruptedSessions() []session.Session {
+	return app.interrupted
+}
+
 // Config returns the application configuration.
 func (app *App) Config() *config.Config {
 	return app.config
 }
 
 // RunNonInteractive runs the application in non-interactive mode with the
-// given prompt, printing to stdout.
-func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt string, quiet bool) error {
+// given prompt, printing the progress to output as plain text or JSON lines.
+// It returns permission.ErrorPermissionDenied when the run stopped on a
+// denied request and context.Canceled when it was cancelled.
+func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt string, opts RunOptions) error {
 	slog.Info("Running in non-interactive mode")
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -143,6 +214,7 @@ func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt
 	stderrTTY = term.IsTerminal(os.Stderr.Fd())
 	stdinTTY = term.IsTerminal(os.Stdin.Fd())
 
+	quiet := opts.Quiet || opts.JSON
 	if !quiet && stderrTTY {
 		t := styles.CurrentTheme()
 
@@ -192,9 +264,31 @@ func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt
 	}
 	slog.Info("Created session for non-interactive run", "session_id", sess.ID)
 
-	// Automatically approve all permission requests for this non-interactive
-	// session.
-	app.Permissions.AutoApproveSession(sess.ID)
+	reporter := &runReporter{output: output, json: opts.JSON}
+	reporter.event(RunEvent{Type: RunEventSession, SessionID: sess.ID})
+
+	if opts.Approve == "" || opts.Approve == ApproveAll {
+		// Automatically approve all permission requests for this
+		// non-interactive session.
+		app.Permissions.AutoApproveSession(sess.ID)
+	} else {
+		// Nobody is around to answer, so the policy does. Requests of
+		// sub-agents come from their own sessions and are answered too.
+		permissionEvents := app.Permissions.Subscribe(ctx)
+		go func() {
+			for event := range permissionEvents {
+				req := event.Payload
+				granted := opts.Approve.Allows(req.ToolName)
+				slog.Info("Non-interactive: answered permission request", "tool", req.ToolName, "granted", granted)
+				reporter.permission(req.ToolName, req.Description, granted, os.Stderr)
+				if granted {
+					app.Permissions.Grant(req)
+				} else {
+					app.Permissions.Deny(req)
+				}
+			}
+		}()
+	}
 
 	type response struct {
 		result *fantasy.AgentResult
@@ -203,19 +297,16 @@ func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt
 	done := make(chan response, 1)
 
 	go func(ctx context.Context, sessionID, prompt string) {
-		result, err := app.AgentCoordinator.Run(ctx, sess.ID, prompt)
-		if err != nil {
-			done <- response{
-				err: fmt.Errorf("failed to start agent processing stream: %w", err),
-			}
-		}
+		result, err := app.AgentCoordinator.Run(ctx, sessionID, prompt, opts.Attachments...)
 		done <- response{
 			result: result,
+			err:    err,
 		}
 	}(ctx, sess.ID, prompt)
 
 	messageEvents := app.Messages.Subscribe(ctx)
 	messageReadBytes := make(map[string]int)
+	reportedTools := make(map[string]bool)
 
 	defer func() {
 		if stderrTTY {
@@ -224,7 +315,9 @@ func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt
 
 		// Always print a newline at the end. If output is a TTY this will
 		// prevent the prompt from overwriting the last line of output.
-		_, _ = fmt.Fprintln(output)
+		if !opts.JSON {
+			_, _ = fmt.Fprintln(output)
+		}
 	}()
 
 	for {
@@ -237,40 +330,72 @@ func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt
 		select {
 		case result := <-done:
 			stopSpinner()
-			if result.err != nil {
-				if errors.Is(result.err, context.Canceled) || errors.Is(result.err, agent.ErrRequestCancelled) {
-					slog.Info("Non-interactive: agent processing cancelled", "session_id", sess.ID)
-					return nil
-				}
-				return fmt.Errorf("agent processing failed: %w", result.err)
+			switch err := result.err; {
+			case err == nil:
+				reporter.event(RunEvent{Type: RunEventResult, SessionID: sess.ID, Status: RunStatusSuccess})
+				return nil
+			case errors.Is(err, context.Canceled) || errors.Is(err, agent.ErrRequestCancelled):
+				slog.Info("Non-interactive: agent processing cancelled", "session_id", sess.ID)
+				reporter.event(RunEvent{Type: RunEventResult, SessionID: sess.ID, Status: RunStatusCancelled})
+				return fmt.Errorf("agent processing cancelled: %w", context.Canceled)
+			case errors.Is(err, permission.ErrorPermissionDenied):
+				reporter.event(RunEvent{Type: RunEventResult, SessionID: sess.ID, Status: RunStatusDenied, Error: err.Error()})
+				return fmt.Errorf("agent processing stopped: %w", err)
+			default:
+				reporter.event(RunEvent{Type: RunEventResult, SessionID: sess.ID, Status: RunStatusError, Error: err.Error()})
+				return fmt.Errorf("agent processing failed: %w", err)
 			}
-			return nil
 
 		case event := <-messageEvents:
 			msg := event.Payload
-			if msg.SessionID == sess.ID && msg.Role == message.Assistant && len(msg.Parts) > 0 {
-				stopSpinner()
+			if msg.SessionID != sess.ID {
+				continue
+			}
+			if msg.Role == message.Tool {
+				for _, tr := range msg.ToolResults() {
+					if reportedTools["result:"+tr.ToolCallID] {
+						continue
+					}
+					reportedTools["result:"+tr.ToolCallID] = true
+					reporter.event(RunEvent{Type: RunEventToolResult, ToolCallID: tr.ToolCallID, Name: tr.Name, Content: tr.Content, IsError: tr.IsError})
+				}
+				continue
+			}
+			if msg.Role != message.Assistant || len(msg.Parts) == 0 {
+				continue
+			}
+			stopSpinner()
 
-				content := msg.Content().String()
-				readBytes := messageReadBytes[msg.ID]
+			content := msg.Content().String()
+			readBytes := messageReadBytes[msg.ID]
 
-				if len(content) < readBytes {
-					slog.Error("Non-interactive: message content is shorter than read bytes", "message_length", len(content), "read_bytes", readBytes)
-					return fmt.Errorf("message content is shorter than read bytes: %d < %d", len(content), readBytes)
-				}
+			if len(content) < readBytes {
+				slog.Error("Non-interactive: message content is shorter than read bytes", "message_length", len(content), "read_bytes", readBytes)
+				return fmt.Errorf("message content is shorter than read bytes: %d < %d", len(content), readBytes)
+			}
 
-				part := content[readBytes:]
-				// Trim leading whitespace. Sometimes the LLM includes leading
-				// formatting and intentation, which we don't want here.
-				if readBytes == 0 {
-					part = strings.TrimLeft(part, " \t")
+			part := content[readBytes:]
+			// Trim leading whitespace. Sometimes the LLM includes leading
+			// formatting and intentation, which we don't want here.
+			if readBytes == 0 {
+				part = strings.TrimLeft(part, " \t")
+			}
+			if part != "" {
+				reporter.text(part)
+			}
+			messageReadBytes[msg.ID] = len(content)
+
+			for _, tc := range msg.ToolCalls() {
+				if !tc.Finished || reportedTools["call:"+tc.ID] {
+					continue
 				}
-				fmt.Fprint(output, part)
-				messageReadBytes[msg.ID] = len(content)
+				reportedTools["call:"+tc.ID] = true
+				reporter.event(RunEvent{Type: RunEventToolCall, ToolCallID: tc.ID, Name: tc.Name, Input: tc.Input})
 			}
 
 		case <-ctx.Done():
 			stopSpinner()
+			reporter.event(RunEvent{Type: RunEventResult, SessionID: sess.ID, Status: RunStatusCancelled})
 			return ctx.Err()
 		}
 	}
@@ -290,9 +415,19 @@ func (app *App) setupEvents() {
 	setupSubscriber(ctx, app.serviceEventsWG, "messages", app.Messages.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "permissions", app.Permissions.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "permissions-notifications", app.Permissions.SubscribeNotifications, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "plans", app.Plans.Subscribe, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "tasks", app.Tasks.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "history", app.History.Subscribe, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "filewatch", app.FileWatch.Subscribe, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "ci", app.CI.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "mcp", mcp.SubscribeEvents, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "lsp", SubscribeLSPEvents, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "background-shells", shell.SubscribeBackgroundExits, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "background-runaways", shell.SubscribeBackgroundRunaways, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "failover", agent.SubscribeFailoverEvents, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "ratelimit", ratelimit.Subscribe, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "redact", redact.Subscribe, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "config", config.SubscribeReloads, app.events)
 	cleanupFunc := func() error {
 		cancel()
 		app.serviceEventsWG.Wait()
@@ -347,7 +482,9 @@ func (app *App) InitCoderAgent(ctx context.Context) error {
 		app.Messages,
 		app.Permissions,
 		app.History,
+		app.Plans,
 		app.LSPClients,
+		app.SemanticIndex,
 	)
 	if err != nil {
 		slog.Error("Failed to create coder agent", "err", err)
@@ -373,6 +510,10 @@ func (app *App) Subscribe(program *tea.Program) {
 	})
 	defer app.tuiWG.Done()
 
+	// Plans wait for the user to review them while the TUI is running.
+	app.Plans.SetInteractive(true)
+	defer app.Plans.SetInteractive(false)
+
 	for {
 		select {
 		case <-tuiCtx.Done():
@@ -397,6 +538,8 @@ func (app *App) Shutdown() {
 		wg.Go(func() {
 			app.AgentCoordinator.CancelAll()
 		})
+		// Before the cleanups close the database the sessions are read from.
+		app.AgentCoordinator.EndSessions(context.WithoutCancel(app.globalCtx))
 	}
 
 	// Kill all background shells.
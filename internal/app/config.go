@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/lsp"
+)
+
+// applyConfigReloads brings the models and the MCP and LSP servers in line
+// with the config files each time they're reloaded. Everything else reads
+// the configuration when it needs it.
+func (app *App) applyConfigReloads(ctx context.Context) {
+	for event := range config.SubscribeReloads(ctx) {
+		reload := event.Payload
+		if len(reload.Errors) > 0 {
+			continue
+		}
+		slog.Info("Config reloaded", "paths", reload.Paths)
+
+		if app.AgentCoordinator != nil {
+			if err := app.AgentCoordinator.UpdateModels(ctx); err != nil {
+				slog.Error("Failed to update models after config reload", "error", err)
+			}
+		}
+
+		for _, name := range reload.MCP {
+			if server, ok := app.config.MCP[name]; !ok || server.Disabled {
+				mcp.Disable(name)
+				continue
+			}
+			go func() {
+				if err := mcp.Restart(ctx, name); err != nil {
+					slog.Warn("Failed to restart MCP server after config reload", "name", name, "error", err)
+				}
+			}()
+		}
+
+		for _, name := range reload.LSP {
+			if server, ok := app.config.LSP[name]; !ok || server.Disabled {
+				app.stopLSPClient(ctx, name)
+				updateLSPState(name, lsp.StateDisabled, nil, nil, 0)
+				continue
+			}
+			if err := app.RestartLSPClient(ctx, name); err != nil {
+				slog.Warn("Failed to restart LSP server after config reload", "name", name, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/remote"
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+// StartJob starts the job profile of the config with the name as a
+// background job, taking input unless it's read-only.
+func (app *App) StartJob(name string) (*shell.BackgroundShell, error) {
+	profile, ok := app.config.Jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("no job named %q in the config", name)
+	}
+	command := profile.Command
+	if len(profile.Args) > 0 {
+		args := make([]string, len(profile.Args))
+		for i, arg := range profile.Args {
+			args[i] = remote.Quote(arg)
+		}
+		command += " " + strings.Join(args, " ")
+	}
+	dir := app.config.WorkingDir()
+	if profile.Cwd != "" {
+		dir = profile.Cwd
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(app.config.WorkingDir(), dir)
+		}
+	}
+	var env []string
+	if extra := profile.Environ(); len(extra) > 0 {
+		env = append(os.Environ(), extra...)
+	}
+
+	manager := shell.GetBackgroundShellManager()
+	manager.Cleanup()
+	bgShell, err := manager.StartEnv(context.Background(), dir, env, command, cmp.Or(profile.Title, name), !profile.ReadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start job %s: %w", name, err)
+	}
+	bgShell.NotifyExit()
+	return bgShell, nil
+}
+
+// StartAutostartJobs starts the job profiles to start when Crush opens,
+// returning the names of those started and why the others weren't.
+func (app *App) StartAutostartJobs() ([]string, error) {
+	var started []string
+	var errs []error
+	for _, name := range app.config.JobNames() {
+		if !app.config.Jobs[name].Autostart {
+			continue
+		}
+		if _, err := app.StartJob(name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		started = append(started, name)
+	}
+	return started, errors.Join(errs...)
+}
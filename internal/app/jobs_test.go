@@ -0,0 +1,67 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartJob(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	app := &App{
+		config: &config.Config{
+			Jobs: map[string]config.JobProfile{
+				"greet": {
+					Command:  `printf '%s, %s\n' "$GREETING"`,
+					Args:     []string{"it's me"},
+					Env:      map[string]string{"GREETING": "hello"},
+					Cwd:      dir,
+					ReadOnly: true,
+				},
+			},
+		},
+	}
+
+	_, err := app.StartJob("missing")
+	require.Error(t, err)
+
+	job, err := app.StartJob("greet")
+	require.NoError(t, err)
+	t.Cleanup(func() { shell.GetBackgroundShellManager().Remove(job.ID) })
+	job.Wait()
+
+	stdout, _, _, err := job.GetOutput()
+	require.NoError(t, err)
+	require.Equal(t, "hello, it's me\n", stdout)
+	require.Equal(t, dir, job.WorkingDir)
+	require.Equal(t, "greet", job.Description, "the name is the title by default")
+	require.False(t, job.Interactive(), "read-only jobs take no input")
+}
+
+func TestStartAutostartJobs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	app := &App{
+		config: &config.Config{
+			Jobs: map[string]config.JobProfile{
+				"logs":   {Command: "echo tail", Cwd: dir, Autostart: true},
+				"dev":    {Command: "echo dev", Cwd: dir, Autostart: true},
+				"deploy": {Command: "echo deploy", Cwd: dir},
+			},
+		},
+	}
+
+	started, err := app.StartAutostartJobs()
+	require.NoError(t, err)
+	require.Equal(t, []string{"dev", "logs"}, started, "only the jobs to autostart are started")
+	for _, job := range shell.GetBackgroundShellManager().Jobs() {
+		if job.WorkingDir == dir {
+			t.Cleanup(func() { shell.GetBackgroundShellManager().Remove(job.ID) })
+		}
+	}
+}
@@ -2,7 +2,10 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/config"
@@ -19,14 +22,67 @@ func (app *App) initLSPClients(ctx context.Context) {
 		go app.createAndStartLSPClient(ctx, name, clientConfig)
 	}
 	slog.Info("LSP clients initialization started in background")
+	go app.monitorLSPClients(ctx)
+}
+
+// RestartLSPClient shuts down the named LSP client, if it's running, and
+// starts it again in the background.
+func (app *App) RestartLSPClient(ctx context.Context, name string) error {
+	clientConfig, ok := app.config.LSP[name]
+	if !ok {
+		return fmt.Errorf("LSP %s not configured", name)
+	}
+	app.stopLSPClient(ctx, name)
+	lsp.AppendLog(name, "info", "Restarting")
+	go app.createAndStartLSPClient(app.globalCtx, name, clientConfig)
+	return nil
+}
+
+// stopLSPClient shuts down the named LSP client, if it's running.
+func (app *App) stopLSPClient(ctx context.Context, name string) {
+	client, ok := app.LSPClients.Take(name)
+	if !ok {
+		return
+	}
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := client.Close(shutdownCtx); err != nil {
+		slog.Warn("Failed to shutdown LSP client", "name", name, "error", err)
+	}
+}
+
+// monitorLSPClients marks the LSP clients whose server exited as crashed, so
+// the failure shows up in the UI.
+func (app *App) monitorLSPClients(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for name, client := range app.LSPClients.Seq2() {
+				if client.GetServerState() != lsp.StateReady || client.IsRunning() {
+					continue
+				}
+				slog.Error("LSP server exited", "name", name)
+				lsp.AppendLog(name, "error", "Server exited unexpectedly")
+				client.SetServerState(lsp.StateCrashed)
+				info, _ := GetLSPState(name)
+				updateLSPState(name, lsp.StateCrashed, errors.New("server exited unexpectedly"), client, info.DiagnosticCount)
+			}
+		}
+	}
 }
 
 // createAndStartLSPClient creates a new LSP client, initializes it, and starts its workspace watcher
 func (app *App) createAndStartLSPClient(ctx context.Context, name string, config config.LSPConfig) {
 	slog.Debug("Creating LSP client", "name", name, "command", config.Command, "fileTypes", config.FileTypes, "args", config.Args)
 
-	// Check if any root markers exist in the working directory (config now has defaults)
-	if !lsp.HasRootMarkers(app.config.WorkingDir(), config.RootMarkers) {
+	// Check if any root markers exist in the roots of the workspace (config now has defaults)
+	if !slices.ContainsFunc(app.config.Roots(), func(root string) bool {
+		return lsp.HasRootMarkers(root, config.RootMarkers)
+	}) {
 		slog.Debug("Skipping LSP client: no root markers found", "name", name, "rootMarkers", config.RootMarkers)
 		updateLSPState(name, lsp.StateDisabled, nil, nil, 0)
 		return
@@ -34,12 +90,14 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 
 	// Update state to starting
 	updateLSPState(name, lsp.StateStarting, nil, nil, 0)
+	lsp.AppendLog(name, "info", "Starting %s", config.Command)
 
 	// Create LSP client.
-	lspClient, err := lsp.New(ctx, name, config, app.config.Resolver())
+	lspClient, err := lsp.New(ctx, name, config, app.config.Resolver(), app.config.Roots())
 	if err != nil {
 		slog.Error("Failed to create LSP client for", name, err)
 		updateLSPState(name, lsp.StateError, err, nil, 0)
+		lsp.AppendLog(name, "error", "Failed to start: %v", err)
 		return
 	}
 
@@ -55,6 +113,7 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 	if err != nil {
 		slog.Error("LSP client initialization failed", "name", name, "error", err)
 		updateLSPState(name, lsp.StateError, err, lspClient, 0)
+		lsp.AppendLog(name, "error", "Failed to initialize: %v", err)
 		lspClient.Close(ctx)
 		return
 	}
@@ -66,11 +125,13 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 		// some functionality might still work.
 		lspClient.SetServerState(lsp.StateError)
 		updateLSPState(name, lsp.StateError, err, lspClient, 0)
+		lsp.AppendLog(name, "error", "Not ready: %v", err)
 	} else {
 		// Server reached a ready state scuccessfully.
 		slog.Debug("LSP server is ready", "name", name)
 		lspClient.SetServerState(lsp.StateReady)
 		updateLSPState(name, lsp.StateReady, nil, lspClient, 0)
+		lsp.AppendLog(name, "info", "Ready")
 	}
 
 	slog.Info("LSP client initialized", "name", name)
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+const (
+	// inFlightInterval is how often the sessions in flight are saved again
+	// while there are some.
+	inFlightInterval = 5 * time.Second
+	// inFlightStale is how old the file of a Crush has to be for it to have
+	// exited without finishing its sessions, rather than still running.
+	inFlightStale = 3 * inFlightInterval
+)
+
+// inFlight is what a running Crush saves about the sessions it's responding
+// in. The messages themselves are saved as they stream in, this tells which
+// of them were cut short when Crush didn't get to exit.
+type inFlight struct {
+	PID        int       `json:"pid"`
+	SessionIDs []string  `json:"session_ids"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// inFlightDir returns the directory holding a file per running Crush.
+func inFlightDir(dataDir string) string {
+	return filepath.Join(dataDir, "in-flight")
+}
+
+// inFlightPath returns the file of this Crush.
+func inFlightPath(dataDir string) string {
+	return filepath.Join(inFlightDir(dataDir), strconv.Itoa(os.Getpid())+".json")
+}
+
+// removeInFlight removes the file of this Crush, as it exits cleanly.
+func (app *App) removeInFlight() error {
+	if err := os.Remove(inFlightPath(app.config.Options.DataDirectory)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// trackInFlight saves the sessions with a response in progress until the
+// context is done, removing the file when there are none left.
+func (app *App) trackInFlight(ctx context.Context) {
+	path := inFlightPath(app.config.Options.DataDirectory)
+	sessions := make(map[string]bool)
+	save := func() {
+		if len(sessions) == 0 {
+			if err := app.removeInFlight(); err != nil {
+				slog.Warn("Failed to remove the sessions in flight", "error", err)
+			}
+			return
+		}
+		data, err := json.Marshal(inFlight{
+			PID:        os.Getpid(),
+			SessionIDs: slices.Sorted(maps.Keys(sessions)),
+			UpdatedAt:  time.Now(),
+		})
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(path), 0o755)
+		}
+		if err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+		if err != nil {
+			slog.Warn("Failed to save the sessions in flight", "error", err)
+		}
+	}
+
+	messages := app.Messages.Subscribe(ctx)
+	ticker := time.NewTicker(inFlightInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if len(sessions) > 0 {
+				save()
+			}
+		case event, ok := <-messages:
+			if !ok {
+				return
+			}
+			msg := event.Payload
+			if msg.Role != message.Assistant || event.Type == pubsub.DeletedEvent {
+				continue
+			}
+			busy := !msg.IsFinished()
+			if busy != sessions[msg.SessionID] {
+				if busy {
+					sessions[msg.SessionID] = true
+				} else {
+					delete(sessions, msg.SessionID)
+				}
+				save()
+			}
+		}
+	}
+}
+
+// recoverInterrupted finds the sessions that were responding when a Crush
+// exited without finishing them, and closes what was left open in them so
+// that they can be continued. They're returned, most recent first.
+func (app *App) recoverInterrupted(ctx context.Context) []session.Session {
+	dir := inFlightDir(app.config.Options.DataDirectory)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var interrupted []session.Session
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var state inFlight
+		if err := json.Unmarshal(data, &state); err != nil || time.Since(state.UpdatedAt) < inFlightStale {
+			continue
+		}
+		for _, id := range state.SessionIDs {
+			sess, err := app.Sessions.Get(ctx, id)
+			if err != nil {
+				continue
+			}
+			if err := closeInterrupted(ctx, app.Messages, id); err != nil {
+				slog.Error("Failed to recover an interrupted session", "session", id, "error", err)
+				continue
+			}
+			interrupted = append(interrupted, sess)
+		}
+		if err := os.Remove(path); err != nil {
+			slog.Warn("Failed to remove the sessions in flight", "error", err)
+		}
+	}
+	slices.SortFunc(interrupted, func(a, b session.Session) int {
+		return cmp.Compare(b.UpdatedAt, a.UpdatedAt)
+	})
+	return interrupted
+}
+
+// closeInterrupted keeps what the assistant said before it was interrupted,
+// finishing its messages and answering its tool calls that have no result,
+// as providers reject them otherwise.
+func closeInterrupted(ctx context.Context, messages message.Service, sessionID string) error {
+	msgs, err := messages.List(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	answered := make(map[string]bool)
+	for _, msg := range msgs {
+		for _, tr := range msg.ToolResults() {
+			answered[tr.ToolCallID] = true
+		}
+	}
+	for _, msg := range msgs {
+		if msg.Role != message.Assistant {
+			continue
+		}
+		if !msg.IsFinished() {
+			msg.FinishThinking()
+			for _, tc := range msg.ToolCalls() {
+				if !tc.Finished {
+					tc.Finished = true
+					tc.Input = "{}"
+					msg.AddToolCall(tc)
+				}
+			}
+			msg.AddFinish(message.FinishReasonError, "Interrupted", "Crush exited before the response was complete.")
+			if err := messages.Update(ctx, msg); err != nil {
+				return err
+			}
+		}
+		for _, tc := range msg.ToolCalls() {
+			if answered[tc.ID] {
+				continue
+			}
+			_, err := messages.Create(ctx, sessionID, message.CreateMessageParams{
+				Role: message.Tool,
+				Parts: []message.ContentPart{message.ToolResult{
+					ToolCallID: tc.ID,
+					Name:       tc.Name,
+					Content:    "Crush exited before the tool finished",
+					IsError:    true,
+				}},
+				ParentMessageID: msg.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to answer tool call %s: %w", tc.ID, err)
+			}
+		}
+	}
+	return nil
+}
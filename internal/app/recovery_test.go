@@ -0,0 +1,78 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverInterrupted(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	conn, err := db.Connect(t.Context(), dataDir)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+	app := &App{
+		Sessions: session.NewService(q),
+		Messages: message.NewService(q),
+		config:   &config.Config{Options: &config.Options{DataDirectory: dataDir}},
+	}
+
+	sess, err := app.Sessions.Create(t.Context(), "Interrupted")
+	require.NoError(t, err)
+	running, err := app.Sessions.Create(t.Context(), "Still running")
+	require.NoError(t, err)
+	assistant, err := app.Messages.Create(t.Context(), sess.ID, message.CreateMessageParams{
+		Role: message.Assistant,
+		Parts: []message.ContentPart{
+			message.TextContent{Text: "Let me look"},
+			message.ToolCall{ID: "call-1", Name: "view", Input: `{"file_path":"main.go"}`, Finished: true},
+			message.ToolCall{ID: "call-2", Name: "bash", Input: `{"comm`},
+		},
+	})
+	require.NoError(t, err)
+
+	write := func(name string, state inFlight) {
+		data, err := json.Marshal(state)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(inFlightDir(dataDir), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(inFlightDir(dataDir), name), data, 0o644))
+	}
+	write("1.json", inFlight{PID: 1, SessionIDs: []string{sess.ID}, UpdatedAt: time.Now().Add(-time.Minute)})
+	write("2.json", inFlight{PID: 2, SessionIDs: []string{running.ID}, UpdatedAt: time.Now()})
+
+	interrupted := app.recoverInterrupted(t.Context())
+	require.Len(t, interrupted, 1)
+	require.Equal(t, sess.ID, interrupted[0].ID)
+	require.NoFileExists(t, filepath.Join(inFlightDir(dataDir), "1.json"))
+	require.FileExists(t, filepath.Join(inFlightDir(dataDir), "2.json"))
+
+	msgs, err := app.Messages.List(t.Context(), sess.ID)
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+	require.Equal(t, assistant.ID, msgs[0].ID)
+	require.Equal(t, "Let me look", msgs[0].Content().Text)
+	require.Equal(t, message.FinishReasonError, msgs[0].FinishReason())
+	for _, tc := range msgs[0].ToolCalls() {
+		require.True(t, tc.Finished)
+	}
+	var answered []string
+	for _, msg := range msgs[1:] {
+		require.Equal(t, message.Tool, msg.Role)
+		for _, tr := range msg.ToolResults() {
+			require.True(t, tr.IsError)
+			answered = append(answered, tr.ToolCallID)
+		}
+	}
+	require.ElementsMatch(t, []string{"call-1", "call-2"}, answered)
+}
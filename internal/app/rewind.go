@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// RewindSession removes the prompt that led to the message with messageID,
+// which is the message itself when it's a prompt, along with everything
+// after it, so that the prompt can be edited and sent again. The removed
+// prompt is returned.
+func (app *App) RewindSession(ctx context.Context, sessionID, messageID string) (message.Message, error) {
+	msgs, err := app.Messages.List(ctx, sessionID)
+	if err != nil {
+		return message.Message{}, err
+	}
+	start := -1
+	for i, msg := range msgs {
+		if msg.Role == message.User {
+			start = i
+		}
+		if msg.ID == messageID {
+			break
+		}
+		if i == len(msgs)-1 {
+			return message.Message{}, fmt.Errorf("message %s is not in the session", messageID)
+		}
+	}
+	if start < 0 {
+		return message.Message{}, errors.New("no prompt led to the message")
+	}
+
+	sess, err := app.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return message.Message{}, err
+	}
+	// Delete the last messages first, so that the transcript never shows
+	// responses without their prompt.
+	for i := len(msgs) - 1; i >= start; i-- {
+		if err := app.Messages.Delete(ctx, msgs[i].ID); err != nil {
+			return message.Message{}, fmt.Errorf("failed to delete message %s: %w", msgs[i].ID, err)
+		}
+		if msgs[i].ID == sess.SummaryMessageID {
+			sess.SummaryMessageID = ""
+			if _, err := app.Sessions.Save(ctx, sess); err != nil {
+				return message.Message{}, err
+			}
+		}
+	}
+	return msgs[start], nil
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewindSession(t *testing.T) {
+	t.Parallel()
+
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+	app := &App{
+		Sessions: session.NewService(q),
+		Messages: message.NewService(q),
+	}
+
+	sess, err := app.Sessions.Create(t.Context(), "Rewind")
+	require.NoError(t, err)
+	create := func(role message.MessageRole, text string) message.Message {
+		msg, err := app.Messages.Create(t.Context(), sess.ID, message.CreateMessageParams{
+			Role:  role,
+			Parts: []message.ContentPart{message.TextContent{Text: text}},
+		})
+		require.NoError(t, err)
+		return msg
+	}
+	first := create(message.User, "first")
+	create(message.Assistant, "first answer")
+	second := create(message.User, "second")
+	answer := create(message.Assistant, "second answer")
+
+	_, err = app.RewindSession(t.Context(), sess.ID, "missing")
+	require.Error(t, err)
+
+	prompt, err := app.RewindSession(t.Context(), sess.ID, answer.ID)
+	require.NoError(t, err)
+	require.Equal(t, second.ID, prompt.ID)
+	msgs, err := app.Messages.List(t.Context(), sess.ID)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	require.Equal(t, first.ID, msgs[0].ID)
+
+	prompt, err = app.RewindSession(t.Context(), sess.ID, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, "first", prompt.Content().Text)
+	msgs, err = app.Messages.List(t.Context(), sess.ID)
+	require.NoError(t, err)
+	require.Empty(t, msgs)
+}
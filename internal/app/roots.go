@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+// followActiveRoot makes the root of the files the agent changes the active
+// root of the workspace, until the context is done.
+func (app *App) followActiveRoot(ctx context.Context) {
+	files := app.History.Subscribe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-files:
+			if !ok {
+				return
+			}
+			if event.Type != pubsub.CreatedEvent || !filepath.IsAbs(event.Payload.Path) {
+				continue
+			}
+			app.config.SetActiveRoot(event.Payload.Path)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// ApprovalPolicy decides which permission requests of a non-interactive run
+// are granted. Tools allowed in the configuration are always granted.
+type ApprovalPolicy string
+
+const (
+	// ApproveAll grants every request.
+	ApproveAll ApprovalPolicy = "all"
+	// ApproveEdits grants file reads and edits, denying commands, network
+	// access and MCP tools.
+	ApproveEdits ApprovalPolicy = "edits"
+	// ApproveNone denies every request.
+	ApproveNone ApprovalPolicy = "none"
+)
+
+// ApprovalPolicies lists the valid approval policies.
+var ApprovalPolicies = []ApprovalPolicy{ApproveAll, ApproveEdits, ApproveNone}
+
+var editTools = []string{
+	tools.ViewToolName,
+	tools.LSToolName,
+	tools.EditToolName,
+	tools.MultiEditToolName,
+	tools.PatchToolName,
+	tools.WriteToolName,
+	tools.ReplaceToolName,
+}
+
+// Allows reports whether the policy grants a request of the given tool.
+func (p ApprovalPolicy) Allows(toolName string) bool {
+	switch p {
+	case ApproveAll:
+		return true
+	case ApproveEdits:
+		return slices.Contains(editTools, toolName)
+	default:
+		return false
+	}
+}
+
+// RunOptions configures a non-interactive run.
+type RunOptions struct {
+	// Quiet hides the spinner.
+	Quiet bool
+	// JSON writes the progress as JSON lines instead of plain text.
+	JSON bool
+	// Approve is the policy applied to permission requests.
+	Approve ApprovalPolicy
+	// Attachments are sent along with the prompt.
+	Attachments []message.Attachment
+}
+
+// RunEvent is a line of the JSON output of a non-interactive run.
+type RunEvent struct {
+	Type       string `json:"type"`
+	SessionID  string `json:"session_id,omitempty"`
+	Text       string `json:"text,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Input      string `json:"input,omitempty"`
+	Content    string `json:"content,omitempty"`
+	IsError    bool   `json:"is_error,omitempty"`
+	Granted    *bool  `json:"granted,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Event types of the JSON output.
+const (
+	RunEventSession    = "session"
+	RunEventText       = "text"
+	RunEventToolCall   = "tool_call"
+	RunEventToolResult = "tool_result"
+	RunEventPermission = "permission"
+	RunEventResult     = "result"
+)
+
+// Statuses of the final result event.
+const (
+	RunStatusSuccess   = "success"
+	RunStatusError     = "error"
+	RunStatusDenied    = "denied"
+	RunStatusCancelled = "cancelled"
+)
+
+// runReporter writes the progress of a run. Permission decisions are
+// reported from another goroutine, hence the lock.
+type runReporter struct {
+	mu     sync.Mutex
+	output io.Writer
+	json   bool
+}
+
+func (r *runReporter) event(e RunEvent) {
+	if !r.json {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = json.NewEncoder(r.output).Encode(e)
+}
+
+func (r *runReporter) text(s string) {
+	if r.json {
+		r.event(RunEvent{Type: RunEventText, Text: s})
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fmt.Fprint(r.output, s)
+}
+
+func (r *runReporter) permission(toolName, description string, granted bool, stderr io.Writer) {
+	if r.json {
+		r.event(RunEvent{Type: RunEventPermission, Name: toolName, Content: description, Granted: &granted})
+		return
+	}
+	if !granted {
+		fmt.Fprintf(stderr, "Denied %s: %s\n", toolName, description)
+	}
+}
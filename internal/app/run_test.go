@@ -0,0 +1,41 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalPolicyAllows(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, ApproveAll.Allows(tools.BashToolName))
+	require.True(t, ApproveEdits.Allows(tools.EditToolName))
+	require.False(t, ApproveEdits.Allows(tools.BashToolName))
+	require.False(t, ApproveEdits.Allows("mcp_github_create_issue"))
+	require.False(t, ApproveNone.Allows(tools.ViewToolName))
+}
+
+func TestRunReporter(t *testing.T) {
+	t.Parallel()
+
+	var plain, stderr bytes.Buffer
+	r := &runReporter{output: &plain}
+	r.event(RunEvent{Type: RunEventSession, SessionID: "s"})
+	r.text("Hello")
+	r.permission(tools.BashToolName, "Execute command: rm -rf /", false, &stderr)
+	require.Equal(t, "Hello", plain.String())
+	require.Equal(t, "Denied bash: Execute command: rm -rf /\n", stderr.String())
+
+	var lines bytes.Buffer
+	r = &runReporter{output: &lines, json: true}
+	r.text("Hello")
+	r.permission(tools.EditToolName, "Edit main.go", true, &stderr)
+	r.event(RunEvent{Type: RunEventResult, Status: RunStatusSuccess})
+	require.Equal(t, `{"type":"text","text":"Hello"}
+{"type":"permission","name":"edit","content":"Edit main.go","granted":true}
+{"type":"result","status":"success"}
+`, lines.String())
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/schedule"
+)
+
+// RunScheduled runs the prompt of a schedule without anyone around,
+// answering permission requests with policy, and records the run in the
+// history of the schedules.
+func (app *App) RunScheduled(ctx context.Context, s schedule.Schedule, policy ApprovalPolicy) (schedule.Run, error) {
+	dataDir := app.config.Options.DataDirectory
+	run := schedule.Run{
+		ScheduleID: s.ID,
+		Prompt:     s.Prompt,
+		StartedAt:  time.Now(),
+	}
+	if err := schedule.Start(dataDir, s.ID, run.StartedAt); err != nil {
+		return run, err
+	}
+
+	var output bytes.Buffer
+	err := app.RunNonInteractive(ctx, &output, s.Prompt, RunOptions{JSON: true, Approve: policy})
+	run.FinishedAt = time.Now()
+	run.Status = RunStatusError
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	// The events of the JSON output tell the session and the response.
+	var response strings.Builder
+	dec := json.NewDecoder(&output)
+	for {
+		var event RunEvent
+		if dec.Decode(&event) != nil {
+			break
+		}
+		switch event.Type {
+		case RunEventSession:
+			run.SessionID = event.SessionID
+		case RunEventText:
+			response.WriteString(event.Text)
+		case RunEventResult:
+			run.Status = event.Status
+		}
+	}
+	run.Response = strings.TrimSpace(response.String())
+	return run, schedule.Record(dataDir, run)
+}
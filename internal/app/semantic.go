@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/semantic"
+)
+
+// initSemanticIndex opens the embedding index of the workspace when semantic
+// search is configured and brings it up to date in the background, so the
+// first search doesn't have to embed the whole repository.
+func (app *App) initSemanticIndex(ctx context.Context) {
+	if !app.config.Tools.SemanticSearch.Enabled() {
+		return
+	}
+	embedder, err := semantic.NewEmbedder(app.config)
+	if err != nil {
+		slog.Error("Failed to create embedder for semantic search", "error", err)
+		return
+	}
+	app.SemanticIndex = semantic.Open(app.config.WorkingDir(), app.config.Options.DataDirectory, embedder)
+
+	go func() {
+		start := time.Now()
+		if err := app.SemanticIndex.Update(ctx); err != nil {
+			slog.Error("Failed to update semantic index", "error", err)
+			return
+		}
+		slog.Info("Semantic index updated", "took", time.Since(start))
+	}()
+}
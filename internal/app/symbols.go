@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+
+	"github.com/charmbracelet/crush/internal/symbols"
+)
+
+// invalidateSymbols drops the symbol indexes containing the files the agent
+// writes and the ones changed outside Crush, until the context is done, so
+// the next lookup sees their symbols as they are.
+func (app *App) invalidateSymbols(ctx context.Context) {
+	written := app.History.Subscribe(ctx)
+	changed := app.FileWatch.Subscribe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-written:
+			if !ok {
+				return
+			}
+			symbols.Invalidate(event.Payload.Path)
+		case event, ok := <-changed:
+			if !ok {
+				return
+			}
+			for _, path := range event.Payload.Paths {
+				symbols.Invalidate(path)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/task"
+)
+
+// extractTasks makes the plans the agent writes in its responses the task
+// lists of their sessions, until the context is done. Responses without one
+// leave the list as it is.
+func (app *App) extractTasks(ctx context.Context) {
+	messages := app.Messages.Subscribe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-messages:
+			if !ok {
+				return
+			}
+			msg := event.Payload
+			if event.Type != pubsub.UpdatedEvent || msg.Role != message.Assistant || !msg.IsFinished() {
+				continue
+			}
+			items := task.Parse(msg.Content().Text)
+			if len(items) == 0 {
+				continue
+			}
+			if _, err := app.Tasks.Replace(ctx, msg.SessionID, items); err != nil {
+				slog.Error("Failed to save the tasks of a response", "session", msg.SessionID, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package ci follows the CI status of the current branch on GitHub, through
+// the gh CLI, or GitLab, through the glab CLI.
+package ci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/gh"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/remote"
+)
+
+// DefaultInterval is how often the CI status is checked.
+const DefaultInterval = 30 * time.Second
+
+const (
+	// maxLogLines is the number of lines kept from the end of a job log.
+	maxLogLines = 200
+	// maxLogSize is the size of the largest log excerpt.
+	maxLogSize = 32 * 1024
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusPass    Status = "pass"
+	StatusFail    Status = "fail"
+	StatusSkipped Status = "skipped"
+)
+
+// Job is a CI job, or a whole workflow run when the provider only reports
+// those.
+type Job struct {
+	// ID identifies the job for the provider.
+	ID       string
+	Name     string
+	Workflow string
+	Status   Status
+	URL      string
+}
+
+// Summary is the CI status of the current branch.
+type Summary struct {
+	Provider string
+	// Status is empty when the branch has no CI jobs.
+	Status Status
+	Jobs   []Job
+	// Error is set when the status couldn't be checked.
+	Error string
+}
+
+// Provider talks to a CI service.
+type Provider interface {
+	Name() string
+	// Jobs returns the CI jobs of the commit checked out in dir.
+	Jobs(ctx context.Context, dir string) ([]Job, error)
+	// Log returns the log of a job.
+	Log(ctx context.Context, dir string, job Job) (string, error)
+}
+
+// Detect returns the provider for the repository in dir, or nil when its
+// origin isn't hosted on a supported service or the matching CLI is
+// missing.
+func Detect(ctx context.Context, dir string) Provider {
+	out, err := run(ctx, dir, "git", "remote", "get-url", "origin")
+	if err != nil {
+		return nil
+	}
+	remote := strings.TrimSpace(out)
+	if strings.Contains(remote, "gitlab") {
+		if installed("glab") {
+			return gitlab{}
+		}
+		return nil
+	}
+	if installed("gh") {
+		return github{}
+	}
+	return nil
+}
+
+// Overall sums the job statuses up: failing when any job failed, pending
+// when any job is still running, passing otherwise.
+func Overall(jobs []Job) Status {
+	if len(jobs) == 0 {
+		return ""
+	}
+	status := StatusSkipped
+	for _, job := range jobs {
+		switch job.Status {
+		case StatusFail:
+			return StatusFail
+		case StatusPending:
+			status = StatusPending
+		case StatusPass:
+			if status == StatusSkipped {
+				status = StatusPass
+			}
+		}
+	}
+	return status
+}
+
+// TailLog keeps the end of a job log, where the failure usually is.
+func TailLog(log string) string {
+	lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+	if len(lines) > maxLogLines {
+		lines = lines[len(lines)-maxLogLines:]
+	}
+	log = strings.Join(lines, "\n")
+	if len(log) > maxLogSize {
+		log = log[len(log)-maxLogSize:]
+	}
+	return log
+}
+
+type Service interface {
+	pubsub.Subscriber[Summary]
+	// Available reports whether the repository has a supported CI provider.
+	Available() bool
+	// Current returns the last known status.
+	Current() Summary
+	// Refresh checks the status now, publishing it when it changed.
+	Refresh(ctx context.Context) Summary
+	// Start checks the status every interval until the context is done.
+	Start(ctx context.Context, interval time.Duration)
+	// Log returns the end of the log of a job.
+	Log(ctx context.Context, job Job) (string, error)
+}
+
+type service struct {
+	*pubsub.Broker[Summary]
+
+	dir      string
+	provider Provider
+
+	mu      sync.Mutex
+	current Summary
+}
+
+// NewService creates the CI watcher of the repository in dir. The provider
+// can be nil, in which case nothing is watched.
+func NewService(dir string, provider Provider) Service {
+	s := &service{
+		Broker:   pubsub.NewBroker[Summary](),
+		dir:      dir,
+		provider: provider,
+	}
+	if provider != nil {
+		s.current.Provider = provider.Name()
+	}
+	return s
+}
+
+func (s *service) Available() bool {
+	return s.provider != nil
+}
+
+func (s *service) Current() Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+func (s *service) Refresh(ctx context.Context) Summary {
+	if s.provider == nil {
+		return Summary{}
+	}
+	summary := Summary{Provider: s.provider.Name()}
+	jobs, err := s.provider.Jobs(ctx, s.dir)
+	if err != nil {
+		summary.Error = err.Error()
+	} else {
+		summary.Jobs = jobs
+		summary.Status = Overall(jobs)
+	}
+
+	s.mu.Lock()
+	changed := summary.Status != s.current.Status ||
+		summary.Error != s.current.Error ||
+		!slices.Equal(summary.Jobs, s.current.Jobs)
+	s.current = summary
+	s.mu.Unlock()
+
+	if changed {
+		s.Publish(pubsub.UpdatedEvent, summary)
+	}
+	return summary
+}
+
+func (s *service) Start(ctx context.Context, interval time.Duration) {
+	if s.provider == nil {
+		return
+	}
+	s.Refresh(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Refresh(ctx)
+		}
+	}
+}
+
+func (s *service) Log(ctx context.Context, job Job) (string, error) {
+	if s.provider == nil {
+		return "", fmt.Errorf("no CI provider")
+	}
+	log, err := s.provider.Log(ctx, s.dir, job)
+	if err != nil {
+		return "", err
+	}
+	return TailLog(log), nil
+}
+
+func installed(name string) bool {
+	return remote.Installed(name)
+}
+
+// run runs a command and returns its output. The output is returned even
+// when the command fails, as some report a failing status with their exit
+// code.
+func run(ctx context.Context, dir, name string, args ...string) (string, error) {
+	cmd := remote.Command(ctx, dir, name, args...)
+	if name == "gh" {
+		// Work with the GitHub host and account of the config.
+		var err error
+		if cmd, err = gh.Command(ctx, dir, args...); err != nil {
+			return "", err
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("%s %s: %s", name, args[0], msg)
+		}
+		return stdout.String(), fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return stdout.String(), nil
+}
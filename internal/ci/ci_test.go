@@ -0,0 +1,66 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverall(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, Status(""), Overall(nil))
+	require.Equal(t, StatusPass, Overall([]Job{{Status: StatusPass}, {Status: StatusSkipped}}))
+	require.Equal(t, StatusPending, Overall([]Job{{Status: StatusPass}, {Status: StatusPending}}))
+	require.Equal(t, StatusFail, Overall([]Job{{Status: StatusPending}, {Status: StatusFail}}))
+	require.Equal(t, StatusSkipped, Overall([]Job{{Status: StatusSkipped}}))
+}
+
+func TestTailLog(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+	for i := range 300 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	got := strings.Split(TailLog(strings.Join(lines, "\n")+"\n"), "\n")
+	require.Len(t, got, maxLogLines)
+	require.Equal(t, "line 100", got[0])
+	require.Equal(t, "line 299", got[len(got)-1])
+}
+
+type fakeProvider struct {
+	jobs []Job
+}
+
+func (fakeProvider) Name() string { return "Fake" }
+
+func (f *fakeProvider) Jobs(context.Context, string) ([]Job, error) { return f.jobs, nil }
+
+func (fakeProvider) Log(context.Context, string, Job) (string, error) { return "ok\n", nil }
+
+func TestServiceRefresh(t *testing.T) {
+	t.Parallel()
+
+	provider := &fakeProvider{jobs: []Job{{ID: "1", Name: "test", Status: StatusPending}}}
+	s := NewService(t.TempDir(), provider)
+	require.True(t, s.Available())
+
+	events := s.Subscribe(t.Context())
+	summary := s.Refresh(t.Context())
+	require.Equal(t, StatusPending, summary.Status)
+	require.Equal(t, summary, (<-events).Payload)
+
+	// Nothing changed, nothing is published.
+	s.Refresh(t.Context())
+	provider.jobs = []Job{{ID: "1", Name: "test", Status: StatusFail}}
+	summary = s.Refresh(t.Context())
+	require.Equal(t, StatusFail, summary.Status)
+	require.Equal(t, summary, (<-events).Payload)
+	require.Equal(t, summary, s.Current())
+
+	require.False(t, NewService(t.TempDir(), nil).Available())
+}
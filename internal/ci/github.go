@@ -0,0 +1,123 @@
+package ci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// actionsJobURL matches the URL of a GitHub Actions job.
+var actionsJobURL = regexp.MustCompile(`/actions/runs/\d+/job/(\d+)`)
+
+type github struct{}
+
+func (github) Name() string { return "GitHub" }
+
+// Jobs returns the checks of the branch's pull request, or the workflow
+// runs of the commit when there is no pull request.
+func (g github) Jobs(ctx context.Context, dir string) ([]Job, error) {
+	out, err := run(ctx, dir, "gh", "pr", "checks", "--json", "name,bucket,link,workflow")
+	if err == nil || strings.HasPrefix(strings.TrimSpace(out), "[") {
+		// gh exits with an error when checks are failing or pending.
+		return parseGitHubChecks([]byte(out))
+	}
+	if !strings.Contains(err.Error(), "no pull requests found") {
+		return nil, err
+	}
+
+	sha, err := run(ctx, dir, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	out, err = run(ctx, dir, "gh", "run", "list", "--commit", strings.TrimSpace(sha), "--json", "databaseId,workflowName,status,conclusion,url")
+	if err != nil {
+		return nil, err
+	}
+	return parseGitHubRuns([]byte(out))
+}
+
+func (github) Log(ctx context.Context, dir string, job Job) (string, error) {
+	kind, id, _ := strings.Cut(job.ID, "/")
+	var args []string
+	switch kind {
+	case "job":
+		args = []string{"run", "view", "--job", id, "--log"}
+	case "run":
+		args = []string{"run", "view", id, "--log-failed"}
+	default:
+		return "", fmt.Errorf("the log of %s isn't available from gh, see %s", job.Name, job.URL)
+	}
+	out, err := run(ctx, dir, "gh", args...)
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func parseGitHubChecks(data []byte) ([]Job, error) {
+	var checks []struct {
+		Name     string `json:"name"`
+		Bucket   string `json:"bucket"`
+		Link     string `json:"link"`
+		Workflow string `json:"workflow"`
+	}
+	if err := json.Unmarshal(data, &checks); err != nil {
+		return nil, err
+	}
+	jobs := make([]Job, 0, len(checks))
+	for _, c := range checks {
+		job := Job{Name: c.Name, Workflow: c.Workflow, URL: c.Link}
+		if m := actionsJobURL.FindStringSubmatch(c.Link); m != nil {
+			job.ID = "job/" + m[1]
+		}
+		switch c.Bucket {
+		case "pass":
+			job.Status = StatusPass
+		case "fail":
+			job.Status = StatusFail
+		case "pending":
+			job.Status = StatusPending
+		default: // skipping, cancel
+			job.Status = StatusSkipped
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func parseGitHubRuns(data []byte) ([]Job, error) {
+	var runs []struct {
+		DatabaseID   int64  `json:"databaseId"`
+		WorkflowName string `json:"workflowName"`
+		Status       string `json:"status"`
+		Conclusion   string `json:"conclusion"`
+		URL          string `json:"url"`
+	}
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, err
+	}
+	jobs := make([]Job, 0, len(runs))
+	for _, r := range runs {
+		job := Job{
+			ID:       "run/" + strconv.FormatInt(r.DatabaseID, 10),
+			Name:     r.WorkflowName,
+			Workflow: r.WorkflowName,
+			URL:      r.URL,
+		}
+		switch {
+		case r.Status != "completed":
+			job.Status = StatusPending
+		case r.Conclusion == "success":
+			job.Status = StatusPass
+		case r.Conclusion == "failure", r.Conclusion == "timed_out", r.Conclusion == "startup_failure":
+			job.Status = StatusFail
+		default: // cancelled, skipped, neutral
+			job.Status = StatusSkipped
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
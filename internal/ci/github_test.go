@@ -0,0 +1,37 @@
+package ci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitHubChecks(t *testing.T) {
+	t.Parallel()
+
+	jobs, err := parseGitHubChecks([]byte(`[
+		{"name":"lint","bucket":"pass","link":"https://github.com/o/r/actions/runs/1/job/11","workflow":"build"},
+		{"name":"test","bucket":"fail","link":"https://github.com/o/r/actions/runs/1/job/12","workflow":"build"},
+		{"name":"deploy","bucket":"skipping","link":"https://ci.example.com/5","workflow":""}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, []Job{
+		{ID: "job/11", Name: "lint", Workflow: "build", Status: StatusPass, URL: "https://github.com/o/r/actions/runs/1/job/11"},
+		{ID: "job/12", Name: "test", Workflow: "build", Status: StatusFail, URL: "https://github.com/o/r/actions/runs/1/job/12"},
+		{Name: "deploy", Status: StatusSkipped, URL: "https://ci.example.com/5"},
+	}, jobs)
+}
+
+func TestParseGitHubRuns(t *testing.T) {
+	t.Parallel()
+
+	jobs, err := parseGitHubRuns([]byte(`[
+		{"databaseId":7,"workflowName":"build","status":"in_progress","conclusion":"","url":"u7"},
+		{"databaseId":8,"workflowName":"release","status":"completed","conclusion":"timed_out","url":"u8"}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, []Job{
+		{ID: "run/7", Name: "build", Workflow: "build", Status: StatusPending, URL: "u7"},
+		{ID: "run/8", Name: "release", Workflow: "release", Status: StatusFail, URL: "u8"},
+	}, jobs)
+}
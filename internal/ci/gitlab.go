@@ -0,0 +1,77 @@
+package ci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type gitlab struct{}
+
+func (gitlab) Name() string { return "GitLab" }
+
+// Jobs returns the jobs of the latest pipeline of the branch. glab fills
+// the :fullpath and :branch placeholders from the working directory.
+func (gitlab) Jobs(ctx context.Context, dir string) ([]Job, error) {
+	out, err := run(ctx, dir, "glab", "api", "projects/:fullpath/pipelines?ref=:branch&per_page=1")
+	if err != nil {
+		return nil, err
+	}
+	var pipelines []struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(out), &pipelines); err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+	out, err = run(ctx, dir, "glab", "api", fmt.Sprintf("projects/:fullpath/pipelines/%d/jobs?per_page=100", pipelines[0].ID))
+	if err != nil {
+		return nil, err
+	}
+	return parseGitLabJobs([]byte(out))
+}
+
+func (gitlab) Log(ctx context.Context, dir string, job Job) (string, error) {
+	return run(ctx, dir, "glab", "api", "projects/:fullpath/jobs/"+job.ID+"/trace")
+}
+
+func parseGitLabJobs(data []byte) ([]Job, error) {
+	var glJobs []struct {
+		ID           int64  `json:"id"`
+		Name         string `json:"name"`
+		Stage        string `json:"stage"`
+		Status       string `json:"status"`
+		AllowFailure bool   `json:"allow_failure"`
+		WebURL       string `json:"web_url"`
+	}
+	if err := json.Unmarshal(data, &glJobs); err != nil {
+		return nil, err
+	}
+	jobs := make([]Job, 0, len(glJobs))
+	for _, j := range glJobs {
+		job := Job{
+			ID:       strconv.FormatInt(j.ID, 10),
+			Name:     j.Name,
+			Workflow: j.Stage,
+			URL:      j.WebURL,
+		}
+		switch j.Status {
+		case "success":
+			job.Status = StatusPass
+		case "failed":
+			job.Status = StatusFail
+			if j.AllowFailure {
+				job.Status = StatusSkipped
+			}
+		case "canceled", "skipped", "manual":
+			job.Status = StatusSkipped
+		default: // created, pending, running, preparing, scheduled...
+			job.Status = StatusPending
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,25 @@
+package ci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitLabJobs(t *testing.T) {
+	t.Parallel()
+
+	jobs, err := parseGitLabJobs([]byte(`[
+		{"id":1,"name":"lint","stage":"test","status":"success","web_url":"u1"},
+		{"id":2,"name":"unit","stage":"test","status":"failed","web_url":"u2"},
+		{"id":3,"name":"flaky","stage":"test","status":"failed","allow_failure":true,"web_url":"u3"},
+		{"id":4,"name":"deploy","stage":"deploy","status":"running","web_url":"u4"}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, []Job{
+		{ID: "1", Name: "lint", Workflow: "test", Status: StatusPass, URL: "u1"},
+		{ID: "2", Name: "unit", Workflow: "test", Status: StatusFail, URL: "u2"},
+		{ID: "3", Name: "flaky", Workflow: "test", Status: StatusSkipped, URL: "u3"},
+		{ID: "4", Name: "deploy", Workflow: "deploy", Status: StatusPending, URL: "u4"},
+	}, jobs)
+}
@@ -0,0 +1,110 @@
+// Package clipboard writes to the system clipboard, trying the native API
+// first, then the usual clipboard commands, and finally falling back to
+// OSC 52, which the terminal handles and which also works over SSH.
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// Method is a way of writing to the clipboard.
+type Method string
+
+const (
+	// Auto tries the methods available on the system in order.
+	Auto    Method = "auto"
+	Native  Method = "native"
+	WlCopy  Method = "wl-copy"
+	Xclip   Method = "xclip"
+	Xsel    Method = "xsel"
+	Pbcopy  Method = "pbcopy"
+	ClipExe Method = "clip.exe"
+	// OSC52 can't be written from here, the terminal does it: callers
+	// send the escape sequence when Write returns it.
+	OSC52 Method = "osc52"
+)
+
+// Methods lists the valid methods.
+var Methods = []Method{Auto, Native, WlCopy, Xclip, Xsel, Pbcopy, ClipExe, OSC52}
+
+// commands are the arguments copying stdin to the clipboard.
+var commands = map[Method][]string{
+	WlCopy:  {"wl-copy"},
+	Xclip:   {"xclip", "-selection", "clipboard"},
+	Xsel:    {"xsel", "--clipboard", "--input"},
+	Pbcopy:  {"pbcopy"},
+	ClipExe: {"clip.exe"},
+}
+
+// Write copies text with the given method, returning the one used. When it
+// is OSC52, nothing was written yet and the caller must send the sequence
+// to the terminal.
+func Write(method Method, text string) (Method, error) {
+	switch method {
+	case "", Auto:
+		for _, m := range candidates() {
+			if err := write(m, text); err == nil {
+				return m, nil
+			}
+		}
+		return OSC52, nil
+	case OSC52:
+		return OSC52, nil
+	default:
+		if err := write(method, text); err != nil {
+			return method, err
+		}
+		return method, nil
+	}
+}
+
+// candidates returns the methods worth trying on this system, in order.
+func candidates() []Method {
+	var methods []Method
+	switch runtime.GOOS {
+	case "darwin":
+		methods = append(methods, Native, Pbcopy)
+	case "windows":
+		methods = append(methods, Native)
+	default:
+		// Over SSH the tools would write to the clipboard of the remote
+		// machine, OSC 52 reaches the one in front of the user.
+		if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+			return nil
+		}
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			methods = append(methods, WlCopy)
+		}
+		if os.Getenv("DISPLAY") != "" {
+			methods = append(methods, Xclip, Xsel)
+		}
+		if os.Getenv("WSL_DISTRO_NAME") != "" {
+			methods = append(methods, ClipExe)
+		}
+	}
+	return methods
+}
+
+func write(method Method, text string) error {
+	if method == Native {
+		return clipboard.WriteAll(text)
+	}
+	args, ok := commands[method]
+	if !ok {
+		return fmt.Errorf("unknown clipboard method %q", method)
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return err
+	}
+	// The output isn't captured: xclip and wl-copy stay around to own the
+	// selection, and would keep the pipes open.
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
@@ -0,0 +1,33 @@
+package clipboard
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOSC52(t *testing.T) {
+	t.Parallel()
+
+	method, err := Write(OSC52, "hello")
+	require.NoError(t, err)
+	require.Equal(t, OSC52, method)
+}
+
+func TestWriteUnknownMethod(t *testing.T) {
+	t.Parallel()
+
+	_, err := Write("carrier-pigeon", "hello")
+	require.EqualError(t, err, `unknown clipboard method "carrier-pigeon"`)
+}
+
+func TestCandidatesOverSSH(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("macOS and Windows use their native clipboard")
+	}
+	t.Setenv("SSH_TTY", "/dev/pts/1")
+	t.Setenv("DISPLAY", ":0")
+
+	require.Empty(t, candidates())
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/tidwall/gjson"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change the configuration",
+	Long: `Read the configuration in effect for the current project, merged from all
+config files, and change settings in the same file the TUI saves them to.
+Keys are dotted paths, like options.tui.compact_mode.`,
+	Example: `
+# Print the whole configuration
+crush config get
+
+# Print a single setting
+crush config get options.tui.compact_mode
+
+# Change a setting; values are parsed as JSON when possible
+crush config set options.tui.compact_mode true
+crush config set models.large.model gpt-4o
+
+# Show which config files set the model settings
+crush config sources models
+
+# List the profiles
+crush config profiles
+  `,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get [key]",
+	Short:             "Print the configuration or one of its settings",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := configJSON(cmd)
+		if err != nil {
+			return err
+		}
+		value := gjson.ParseBytes(data)
+		if len(args) == 1 {
+			value = value.Get(args[0])
+			if !value.Exists() {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+		}
+		if value.Type == gjson.String {
+			fmt.Fprintln(cmd.OutOrStdout(), value.String())
+			return nil
+		}
+		var pretty any
+		if err := json.Unmarshal([]byte(value.Raw), &pretty); err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(pretty, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <key> <value>",
+	Short:             "Change a setting",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		return cfg.SetConfigField(args[0], configValue(args[1]))
+	},
+}
+
+var configSourcesCmd = &cobra.Command{
+	Use:               "sources [key]",
+	Short:             "Show the config files merged and where each setting comes from",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		origins, err := cfg.Origins()
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		if len(args) == 0 {
+			fmt.Fprintln(out, "Config files, from the least to the most important:")
+			for _, layer := range cfg.Layers() {
+				missing := ""
+				if !layer.Exists() {
+					missing = " (missing)"
+				}
+				fmt.Fprintf(out, "  %-16s %s%s\n", layer.Name, layer.Path, missing)
+			}
+			fmt.Fprintln(out)
+		}
+		for _, origin := range origins {
+			if len(args) == 1 && origin.Key != args[0] && !strings.HasPrefix(origin.Key, args[0]+".") {
+				continue
+			}
+			fmt.Fprintf(out, "%s = %s (%s)\n", origin.Key, origin.Value, strings.Join(origin.Layers, " < "))
+		}
+		return nil
+	},
+}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the config profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		profiles := config.Profiles()
+		if len(profiles) == 0 {
+			fmt.Fprintf(out, "No profiles in %s\n", config.ProfilesDir())
+			return nil
+		}
+		for _, profile := range profiles {
+			marker := " "
+			if profile == config.ActiveProfile() {
+				marker = "*"
+			}
+			fmt.Fprintf(out, "%s %s\n", marker, profile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd, configSetCmd, configSourcesCmd, configProfilesCmd)
+}
+
+// configValue parses values like true, 42 or ["a"] as JSON, leaving anything
+// else as a string.
+func configValue(s string) any {
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	return s
+}
+
+func configJSON(cmd *cobra.Command) ([]byte, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return config.MaskSecrets(data)
+}
+
+// completeConfigKeys completes the dotted path being typed one level at a
+// time.
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	data, err := configJSON(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	parent, prefix := "", ""
+	value := gjson.ParseBytes(data)
+	if i := strings.LastIndex(toComplete, "."); i >= 0 {
+		parent = toComplete[:i]
+		prefix = parent + "."
+		value = value.Get(parent)
+	}
+	if !value.IsObject() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []cobra.Completion
+	value.ForEach(func(key, v gjson.Result) bool {
+		path := prefix + key.String()
+		if v.IsObject() {
+			path += "."
+		}
+		if strings.HasPrefix(path, toComplete) {
+			completions = append(completions, path)
+		}
+		return true
+	})
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/keyring"
+	"github.com/spf13/cobra"
+)
+
+var credentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Show where the API keys of providers are kept",
+	Long:  "Show whether the API key of each provider is kept in the keychain, in plaintext configuration, or comes from the environment",
+	Example: `
+# List where the API keys are kept
+crush credentials
+
+# Move the plaintext API keys Crush saved into the keychain
+crush credentials migrate
+  `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Secrets are kept in the %s.\n", keyring.Backend())
+		for _, c := range cfg.Credentials() {
+			source := string(c.Source)
+			if c.Migratable {
+				source += " (run 'crush credentials migrate' to move it to the keychain)"
+			}
+			fmt.Fprintf(out, "%s\t%s\n", c.Provider, source)
+		}
+		return nil
+	},
+}
+
+var credentialsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move plaintext API keys into the keychain",
+	Long:  "Move the API keys Crush saved in plaintext in its configuration into the keychain, leaving references to them",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		migrated, err := cfg.MigrateCredentials()
+		if len(migrated) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved the API keys of %s to the %s.\n", strings.Join(migrated, ", "), keyring.Backend())
+		} else if err == nil {
+			fmt.Fprintln(cmd.OutOrStdout(), "No plaintext API keys to move.")
+		}
+		return err
+	},
+}
+
+func init() {
+	credentialsCmd.AddCommand(credentialsMigrateCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Maintain the database of the project",
+	Long:  "Maintain the database holding the sessions of the project. Migrations are applied automatically when Crush opens it",
+	Example: `
+# Reclaim the space of deleted sessions
+crush db vacuum
+  `,
+}
+
+var dbVacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Compact the database",
+	Long:  "Compact the search index and rebuild the database to reclaim the space of deleted sessions. Quit Crush first, it waits for the database otherwise",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		conn, err := db.Connect(cmd.Context(), cfg.Options.DataDirectory)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		before, after, err := db.Vacuum(cmd.Context(), conn, cfg.Options.DataDirectory)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Compacted the database from %s to %s.\n", formatSize(before), formatSize(after))
+		return nil
+	},
+}
+
+// formatSize formats byte count as human-readable size.
+func formatSize(bytes int64) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	if bytes < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+}
+
+func init() {
+	dbCmd.AddCommand(dbVacuumCmd)
+}
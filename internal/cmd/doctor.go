@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/crush/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that Crush has what it needs",
+	Long:  "Check the connection to the providers, the MCP and LSP servers, the programs Crush runs, the terminal and the database, suggesting how to fix what's wrong",
+	Example: `
+# Run the checks
+crush doctor
+
+# Run the checks and print them as JSON
+crush doctor --json
+  `,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		checks := doctor.Run(cmd.Context(), cfg, doctor.Options{})
+
+		if jsonOutput {
+			if err := printJSON(cmd, checks); err != nil {
+				return err
+			}
+		} else {
+			doctor.Print(cmd.OutOrStdout(), checks)
+		}
+		if doctor.Failed(checks) {
+			return errors.New("some checks failed")
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().Bool("json", false, "Output the checks as JSON")
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate listens on a unix socket created with mode 0600, rather
+// than made so after, when others could connect to it already.
+func listenPrivate(path string) (net.Listener, error) {
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
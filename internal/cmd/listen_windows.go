@@ -0,0 +1,11 @@
+//go:build windows
+
+package cmd
+
+import "net"
+
+// listenPrivate listens on a unix socket, which the ACL of its directory
+// protects on Windows.
+func listenPrivate(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List the available models",
+	Long:  "List the models of the configured providers, marking the ones in use",
+	Example: `
+# List the models in a table
+crush models
+
+# Only the models of a provider, as JSON
+crush models --provider anthropic --json
+  `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		provider, _ := cmd.Flags().GetString("provider")
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		models := listModels(cfg, provider)
+
+		if jsonOutput {
+			return printJSON(cmd, models)
+		}
+
+		if len(models) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No models available. Run 'crush' to set up a provider.")
+			return nil
+		}
+
+		if term.IsTerminal(os.Stdout.Fd()) {
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return lipgloss.NewStyle().Padding(0, 2)
+				}).
+				Headers("Provider", "Model", "Name", "Context", "In Use")
+			for _, m := range models {
+				t.Row(m.Provider, m.ID, m.Name, strconv.FormatInt(m.ContextWindow, 10), m.InUse)
+			}
+			lipgloss.Println(t)
+			return nil
+		}
+
+		for _, m := range models {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\n", m.Provider, m.ID, m.Name, m.InUse)
+		}
+		return nil
+	},
+}
+
+func init() {
+	modelsCmd.Flags().Bool("json", false, "Output as JSON")
+	modelsCmd.Flags().StringP("provider", "p", "", "Only list the models of this provider")
+	_ = modelsCmd.RegisterFlagCompletionFunc("provider", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var ids []cobra.Completion
+		for _, p := range cfg.EnabledProviders() {
+			ids = append(ids, cobra.CompletionWithDesc(p.ID, p.Name))
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+type listedModel struct {
+	Provider      string  `json:"provider"`
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	ContextWindow int64   `json:"context_window"`
+	CostPer1MIn   float64 `json:"cost_per_1m_in"`
+	CostPer1MOut  float64 `json:"cost_per_1m_out"`
+	InUse         string  `json:"in_use,omitempty"`
+}
+
+// listModels returns the models of the enabled providers, or only of
+// provider when set. InUse names the model types the model is selected for.
+func listModels(cfg *config.Config, provider string) []listedModel {
+	models := []listedModel{}
+	for _, p := range cfg.EnabledProviders() {
+		if provider != "" && p.ID != provider {
+			continue
+		}
+		for _, m := range p.Models {
+			listed := listedModel{
+				Provider:      p.ID,
+				ID:            m.ID,
+				Name:          m.Name,
+				ContextWindow: m.ContextWindow,
+				CostPer1MIn:   m.CostPer1MIn,
+				CostPer1MOut:  m.CostPer1MOut,
+			}
+			for _, typ := range []config.SelectedModelType{config.SelectedModelTypeLarge, config.SelectedModelTypeSmall} {
+				if selected, ok := cfg.Models[typ]; ok && selected.Provider == p.ID && selected.Model == m.ID {
+					if listed.InUse != "" {
+						listed.InUse += ", "
+					}
+					listed.InUse += string(typ)
+				}
+			}
+			models = append(models, listed)
+		}
+	}
+	slices.SortStableFunc(models, func(a, b listedModel) int {
+		return cmp.Compare(a.Provider, b.Provider)
+	})
+	return models
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -19,9 +18,12 @@ import (
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/db"
 	"github.com/charmbracelet/crush/internal/event"
+	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/projects"
+	"github.com/charmbracelet/crush/internal/remote"
 	"github.com/charmbracelet/crush/internal/stringext"
 	"github.com/charmbracelet/crush/internal/tui"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
 	"github.com/charmbracelet/crush/internal/version"
 	"github.com/charmbracelet/fang"
 	uv "github.com/charmbracelet/ultraviolet"
@@ -35,8 +37,12 @@ func init() {
 	rootCmd.PersistentFlags().StringP("cwd", "c", "", "Current working directory")
 	rootCmd.PersistentFlags().StringP("data-dir", "D", "", "Custom crush data directory")
 	rootCmd.PersistentFlags().BoolP("debug", "d", false, "Debug")
+	rootCmd.PersistentFlags().StringSlice("root", nil, "Other root directory of the workspace, such as another repository of the project; repeatable")
+	rootCmd.PersistentFlags().String("remote", "", "Work on a workspace living on another host over SSH, given as host:dir")
+	rootCmd.PersistentFlags().String("profile", "", "Config profile to load, from the profiles directory next to the global config")
 	rootCmd.Flags().BoolP("help", "h", false, "Help")
 	rootCmd.Flags().BoolP("yolo", "y", false, "Automatically accept all permissions (dangerous mode)")
+	rootCmd.Flags().Bool("spectators", false, "Let crush spectate watch the sessions live from another terminal")
 
 	rootCmd.AddCommand(
 		runCmd,
@@ -46,6 +52,16 @@ func init() {
 		logsCmd,
 		schemaCmd,
 		loginCmd,
+		serveCmd,
+		sessionsCmd,
+		configCmd,
+		modelsCmd,
+		credentialsCmd,
+		doctorCmd,
+		dbCmd,
+		scheduleCmd,
+		spectateCmd,
+		updateCmd,
 	)
 }
 
@@ -65,25 +81,58 @@ crush -d
 # Run with debug logging in a specific directory
 crush -d -c /path/to/project
 
+# Work on the backend repository alongside the frontend one
+crush -c ~/src/frontend --root ~/src/backend
+
+# Work on a project living on a dev server
+crush --remote devbox:~/src/app
+
 # Run with custom data directory
 crush -D /path/to/custom/.crush
 
+# Run with the settings of a profile, such as ~/.config/crush/profiles/work.json
+crush --profile work
+
 # Print version
 crush -v
 
 # Run a single non-interactive prompt
 crush run "Explain the use of context in Go"
 
+# Start with the output of a command attached
+git diff | crush
+
 # Run in dangerous mode (auto-accept all permissions)
 crush -y
+
+# Let a teammate watch from another terminal with crush spectate
+crush --spectators
   `,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// config.Load reads the profile from the environment.
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			return os.Setenv(config.ProfileEnv, profile)
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		stdin, err := readStdin()
+		if err != nil {
+			return err
+		}
+
 		app, err := setupAppWithProgressBar(cmd)
 		if err != nil {
 			return err
 		}
 		defer app.Shutdown()
 
+		if spectators, _ := cmd.Flags().GetBool("spectators"); spectators {
+			if err := serveSpectators(cmd.Context(), app); err != nil {
+				return err
+			}
+		}
+
 		event.AppInitialized()
 
 		// Set up the TUI.
@@ -91,12 +140,41 @@ crush -y
 		ui := tui.New(app)
 		ui.QueryVersion = shouldQueryTerminalVersion(env)
 
-		program := tea.NewProgram(
-			ui,
+		opts := []tea.ProgramOption{
 			tea.WithEnvironment(env),
 			tea.WithContext(cmd.Context()),
-			tea.WithFilter(tui.MouseEventFilter)) // Filter mouse events based on focus state
+		}
+		if tuiOpts := app.Config().Options.TUI; tuiOpts.ReducedMotion || tuiOpts.Accessibility.Enabled {
+			opts = append(opts,
+				tea.WithFilter(tui.ReducedMotionFilter),
+				tea.WithFPS(tui.ReducedMotionFPS),
+			)
+		} else {
+			opts = append(opts, tea.WithFilter(tui.MouseEventFilter)) // Filter mouse events based on focus state
+		}
+		// Whatever was piped in is pre-attached to the first prompt, and the
+		// keyboard is read from the terminal instead.
+		var piped *message.Attachment
+		if len(bytes.TrimSpace(stdin)) > 0 {
+			attachment, err := stdinAttachment(stdin)
+			if err != nil {
+				return err
+			}
+			in, out, err := tea.OpenTTY()
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+			defer out.Close()
+			opts = append(opts, tea.WithInput(in))
+			piped = &attachment
+		}
+
+		program := tea.NewProgram(ui, opts...)
 		go app.Subscribe(program)
+		if piped != nil {
+			go program.Send(filepicker.FilePickedMsg{Attachment: *piped})
+		}
 
 		if _, err := program.Run(); err != nil {
 			event.Error(err)
@@ -149,7 +227,7 @@ func Execute() {
 		fang.WithVersion(version.Version),
 		fang.WithNotifySignal(os.Interrupt),
 	); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -192,6 +270,18 @@ func setupApp(cmd *cobra.Command) (*app.App, error) {
 		return nil, err
 	}
 
+	roots, _ := cmd.Flags().GetStringSlice("root")
+	if err := cfg.AddRoots(roots...); err != nil {
+		return nil, err
+	}
+	if spec, _ := cmd.Flags().GetString("remote"); spec != "" {
+		r, err := remote.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SetRemote(r)
+	}
+
 	if cfg.Permissions == nil {
 		cfg.Permissions = &config.Permissions{}
 	}
@@ -239,25 +329,6 @@ func shouldEnableMetrics() bool {
 	return true
 }
 
-func MaybePrependStdin(prompt string) (string, error) {
-	if term.IsTerminal(os.Stdin.Fd()) {
-		return prompt, nil
-	}
-	fi, err := os.Stdin.Stat()
-	if err != nil {
-		return prompt, err
-	}
-	// Check if stdin is a named pipe ( | ) or regular file ( < ).
-	if fi.Mode()&os.ModeNamedPipe == 0 && !fi.Mode().IsRegular() {
-		return prompt, nil
-	}
-	bts, err := io.ReadAll(os.Stdin)
-	if err != nil {
-		return prompt, err
-	}
-	return string(bts) + "\n\n" + prompt, nil
-}
-
 func ResolveCwd(cmd *cobra.Command) (string, error) {
 	cwd, _ := cmd.Flags().GetString("cwd")
 	if cwd != "" {
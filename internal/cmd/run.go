@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/event"
+	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/spf13/cobra"
 )
 
@@ -16,22 +22,50 @@ var runCmd = &cobra.Command{
 	Use:   "run [prompt...]",
 	Short: "Run a single non-interactive prompt",
 	Long: `Run a single prompt in non-interactive mode and exit.
-The prompt can be provided as arguments or piped from stdin.`,
+The prompt can be provided as arguments or piped from stdin. When both are
+given, stdin is attached to the prompt as context.
+
+Permission requests are answered by the --approve policy: "all" grants
+everything, "edits" grants file reads and edits but denies commands, network
+access and MCP tools, and "none" denies everything. Tools allowed in the
+configuration are always granted.
+
+The exit status is 0 on success, 1 when the agent failed, 2 on usage errors,
+3 when the run stopped on a denied permission and 130 when it was cancelled.`,
 	Example: `
 # Run a simple prompt
 crush run Explain the use of context in Go
 
-# Pipe input from stdin
+# Pipe input from stdin, attached as context with its syntax detected
 curl https://charm.land | crush run "Summarize this website"
+git diff | crush run "Review this"
 
 # Read from a file
 crush run "What is this code doing?" <<< prrr.go
 
 # Run in quiet mode (hide the spinner)
 crush run --quiet "Generate a README for this project"
+
+# Let the agent edit files but not run commands, streaming JSON lines
+crush run --approve edits --output json "Fix the failing test" | jq .
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		quiet, _ := cmd.Flags().GetBool("quiet")
+		approve, _ := cmd.Flags().GetString("approve")
+		output, _ := cmd.Flags().GetString("output")
+
+		policy := app.ApprovalPolicy(approve)
+		if !slices.Contains(app.ApprovalPolicies, policy) {
+			return &exitError{code: exitUsage, err: fmt.Errorf("invalid approval policy %q: must be one of all, edits or none", approve)}
+		}
+		if output != "plain" && output != "json" {
+			return &exitError{code: exitUsage, err: fmt.Errorf("invalid output format %q: must be plain or json", output)}
+		}
+		opts := app.RunOptions{
+			Quiet:   quiet,
+			JSON:    output == "json",
+			Approve: policy,
+		}
 
 		// Cancel on SIGINT or SIGTERM.
 		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
@@ -44,25 +78,44 @@ crush run --quiet "Generate a README for this project"
 		defer app.Shutdown()
 
 		if !app.Config().IsConfigured() {
-			return fmt.Errorf("no providers configured - please run 'crush' to set up a provider interactively")
+			return &exitError{code: exitUsage, err: fmt.Errorf("no providers configured - please run 'crush' to set up a provider interactively")}
 		}
 
 		prompt := strings.Join(args, " ")
 
-		prompt, err = MaybePrependStdin(prompt)
+		stdin, err := readStdin()
 		if err != nil {
 			slog.Error("Failed to read from stdin", "error", err)
 			return err
 		}
-
-		if prompt == "" {
-			return fmt.Errorf("no prompt provided")
+		switch {
+		case prompt == "" && len(bytes.TrimSpace(stdin)) == 0:
+			return &exitError{code: exitUsage, err: fmt.Errorf("no prompt provided")}
+		case prompt == "":
+			// Without arguments, what was piped is the prompt.
+			prompt = string(stdin)
+		case len(bytes.TrimSpace(stdin)) > 0:
+			attachment, err := stdinAttachment(stdin)
+			if err != nil {
+				return &exitError{code: exitUsage, err: err}
+			}
+			opts.Attachments = append(opts.Attachments, attachment)
 		}
 
 		event.SetInteractive(true)
 		event.AppInitialized()
 
-		return app.RunNonInteractive(ctx, os.Stdout, prompt, quiet)
+		err = app.RunNonInteractive(ctx, os.Stdout, prompt, opts)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, context.Canceled) || errors.Is(err, agent.ErrRequestCancelled):
+			return &exitError{code: exitCancelled, err: err}
+		case errors.Is(err, permission.ErrorPermissionDenied):
+			return &exitError{code: exitDenied, err: err}
+		default:
+			return err
+		}
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 		event.AppExited()
@@ -71,4 +124,36 @@ crush run --quiet "Generate a README for this project"
 
 func init() {
 	runCmd.Flags().BoolP("quiet", "q", false, "Hide spinner")
+	runCmd.Flags().String("approve", string(app.ApproveAll), "Permission policy: all, edits or none")
+	runCmd.Flags().StringP("output", "o", "plain", "Output format: plain or json (JSON lines)")
+	runCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &exitError{code: exitUsage, err: err}
+	})
+}
+
+// Exit statuses of crush run, so scripts can tell failures apart.
+const (
+	exitFailure   = 1
+	exitUsage     = 2
+	exitDenied    = 3
+	exitCancelled = 130
+)
+
+// exitError is an error ending the process with a specific status.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// exitCode returns the status the process should exit with for err.
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
 }
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	require.Equal(t, exitFailure, exitCode(errors.New("boom")))
+	require.Equal(t, exitUsage, exitCode(&exitError{code: exitUsage, err: errors.New("no prompt provided")}))
+	wrapped := fmt.Errorf("run: %w", &exitError{code: exitDenied, err: errors.New("denied")})
+	require.Equal(t, exitDenied, exitCode(wrapped))
+}
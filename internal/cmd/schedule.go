@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/event"
+	"github.com/charmbracelet/crush/internal/notify"
+	"github.com/charmbracelet/crush/internal/schedule"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// scheduleCheckInterval is how often crush schedule run looks for the
+// schedules that are due.
+const scheduleCheckInterval = time.Minute
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Run prompts at set times",
+	Long: `Have the agent run prompts on its own at set times, as with crush run.
+A schedule is a time and a prompt separated by a colon. The time is hourly,
+daily, weekly, "every <interval>" or "daily at <hh:mm>".
+
+Schedules run while crush schedule run is running, which sends a
+notification with the outcome of each run. Runs missed while it wasn't run
+once when it starts. Past runs are listed by "Show Scheduled Runs" in the
+commands of the TUI, their sessions being those of the project.`,
+	Example: `
+# Update the dependencies every day
+crush schedule add "daily: update deps and run tests"
+
+# Triage the issues every morning
+crush schedule add "daily at 09:00: triage the new issues"
+
+# Run the schedules that are due, and keep running them
+crush schedule run
+
+# Run the schedules that are due and exit, as from cron
+crush schedule run --once
+  `,
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add <schedule>",
+	Short: "Add a schedule",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := schedule.Add(cfg.Options.DataDirectory, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		next, _ := s.Next()
+		fmt.Fprintf(cmd.OutOrStdout(), "Added schedule %s, running %s next.\n", s.ID, next.Local().Format("2006-01-02 15:04"))
+		return nil
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the schedules",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		list, err := schedule.Load(cfg.Options.DataDirectory)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(cmd, list)
+		}
+		if len(list.Schedules) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No schedules yet.")
+			return nil
+		}
+		for _, s := range list.Schedules {
+			next := "never"
+			if t, err := s.Next(); err == nil {
+				next = t.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\tnext %s\t%s\n", s.ID, s.When, next, s.Prompt)
+		}
+		return nil
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:     "remove <id>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a schedule",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := schedule.Remove(cfg.Options.DataDirectory, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed schedule %s: %s\n", s.ID, s.Prompt)
+		return nil
+	},
+}
+
+var scheduleRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the schedules that are due, and keep running them",
+	Long: `Run the schedules that are due, one at a time, then keep running them as
+they get due until interrupted. Permission requests are answered by the
+--approve policy, as with crush run.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		once, _ := cmd.Flags().GetBool("once")
+		approve, _ := cmd.Flags().GetString("approve")
+		policy := app.ApprovalPolicy(approve)
+		if !slices.Contains(app.ApprovalPolicies, policy) {
+			return fmt.Errorf("invalid approval policy %q: must be one of all, edits or none", approve)
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+		defer cancel()
+
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+		if !app.Config().IsConfigured() {
+			return fmt.Errorf("no providers configured - please run 'crush' to set up a provider interactively")
+		}
+		event.AppInitialized()
+
+		dataDir := app.Config().Options.DataDirectory
+		ticker := time.NewTicker(scheduleCheckInterval)
+		defer ticker.Stop()
+		for {
+			list, err := schedule.Load(dataDir)
+			if err != nil {
+				return err
+			}
+			for _, s := range list.Due(time.Now()) {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s running %s: %s\n", time.Now().Format("15:04"), s.ID, s.Prompt)
+				run, err := app.RunScheduled(ctx, s, policy)
+				if err != nil {
+					slog.Error("Failed to record a scheduled run", "schedule", s.ID, "error", err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s %s, session %s\n", time.Now().Format("15:04"), s.ID, run.Status, run.SessionID)
+				notifyRun(app.Config(), run)
+			}
+			if once {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		event.AppExited()
+	},
+}
+
+// notifyRun sends the desktop notification of a finished run when the
+// configuration wants it.
+func notifyRun(cfg *config.Config, run schedule.Run) {
+	notifications := cfg.Options.TUI.Notifications
+	if !notifications.Wants(notify.EventScheduledRun) {
+		return
+	}
+	title := "Crush: scheduled run " + run.Status
+	body := run.Prompt
+	if run.Error != "" {
+		body += ": " + run.Error
+	}
+	method := notify.Resolve(notify.Method(notifications.Method))
+	if c := notify.Command(method, title, body); c != nil {
+		if err := c.Run(); err != nil {
+			slog.Warn("Failed to send notification", "method", method, "error", err)
+		}
+		return
+	}
+	if term.IsTerminal(os.Stderr.Fd()) {
+		fmt.Fprint(os.Stderr, notify.Sequence(title, body))
+	}
+}
+
+func init() {
+	scheduleListCmd.Flags().Bool("json", false, "Print the schedules and their runs as JSON")
+	scheduleRunCmd.Flags().Bool("once", false, "Run the schedules that are due and exit")
+	scheduleRunCmd.Flags().String("approve", string(app.ApproveAll), "Permission policy: all, edits or none")
+	scheduleCmd.AddCommand(scheduleAddCmd, scheduleListCmd, scheduleRemoveCmd, scheduleRunCmd)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/apiserver"
+	"github.com/charmbracelet/crush/internal/mcpserver"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve Crush to other programs",
+	Long: `With --mcp, serve Crush's built-in file, search, and shell tools over the
+Model Context Protocol, so other agents and editors can use them.
+
+Tool calls go through the same permissions as in the TUI: tools listed in
+permissions.allowed_tools run right away. Over stdio, anything else is sent
+to the client as a confirmation prompt (or denied if the client can't show
+one). Over SSE, clients must carry the token as a bearer token, read from
+$CRUSH_API_TOKEN or generated and printed on startup, and anything else is
+denied, since the client would be approving its own calls. Use --yolo to
+skip all permission prompts.
+
+With --api, serve sessions, messages, tool events and permission requests over
+a local HTTP API with server-sent events, so editors and other frontends can
+drive the agent. Requests must carry the token as a bearer token; it is read
+from $CRUSH_API_TOKEN, or generated and printed on startup. With --read-only,
+the API only lets clients watch, as crush spectate does. With --socket, it's
+served on a unix socket created only for the user, where no token is needed
+when its directory is closed to others too, like one made with mkdir -m 700.`,
+	Example: `
+# Serve over stdio, e.g. from another agent's MCP configuration
+crush serve --mcp
+
+# Serve over SSE on a local port
+crush serve --mcp --transport sse --addr 127.0.0.1:8787
+
+# Serve the HTTP API for editor plugins
+crush serve --api --addr 127.0.0.1:8787
+
+# Let others watch the sessions, but not drive the agent
+crush serve --api --read-only
+  `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serveMCP, _ := cmd.Flags().GetBool("mcp")
+		serveAPI, _ := cmd.Flags().GetBool("api")
+		transport, _ := cmd.Flags().GetString("transport")
+		addr, _ := cmd.Flags().GetString("addr")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+		socket, _ := cmd.Flags().GetString("socket")
+
+		switch {
+		case serveMCP && serveAPI:
+			return errors.New("pass either --mcp or --api, not both")
+		case !serveMCP && !serveAPI:
+			return errors.New("nothing to serve, pass --mcp to serve tools over MCP or --api to serve the HTTP API")
+		case serveMCP && (readOnly || socket != ""):
+			return errors.New("--read-only and --socket only apply to --api")
+		}
+
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, os.Kill)
+		defer cancel()
+
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		if serveAPI {
+			token := os.Getenv("CRUSH_API_TOKEN")
+			if token == "" && (socket == "" || !privateDir(filepath.Dir(socket))) {
+				token = rand.Text()
+				fmt.Fprintf(os.Stderr, "API token: %s\n", token)
+			}
+			srv := apiserver.New(app.Sessions, app.Messages, app.Permissions, app.AgentCoordinator, token)
+			srv.Start(ctx)
+			var handler http.Handler = srv
+			if readOnly {
+				handler = srv.ReadOnly()
+			}
+			if socket != "" {
+				listener, err := listenUnix(socket)
+				if err != nil {
+					return err
+				}
+				return serve(ctx, handler, listener, "the API")
+			}
+			return listenAndServe(ctx, handler, addr, "the API")
+		}
+
+		srv, err := mcpserver.New(ctx, app.Config(), app.Sessions, app.Permissions, app.History, app.LSPClients)
+		if err != nil {
+			return err
+		}
+
+		switch transport {
+		case "stdio":
+			slog.Info("Serving MCP over stdio")
+			return srv.Run(ctx, &mcp.StdioTransport{})
+		case "sse":
+			token := os.Getenv("CRUSH_API_TOKEN")
+			if token == "" {
+				token = rand.Text()
+				fmt.Fprintf(os.Stderr, "MCP token: %s\n", token)
+			}
+			return listenAndServe(ctx, srv.SSEHandler(token), addr, "MCP over SSE")
+		default:
+			return fmt.Errorf("unsupported transport %q, use stdio or sse", transport)
+		}
+	},
+}
+
+func listenAndServe(ctx context.Context, handler http.Handler, addr, what string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, handler, listener, what)
+}
+
+func serve(ctx context.Context, handler http.Handler, listener net.Listener, what string) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	if listener.Addr().Network() == "unix" {
+		fmt.Fprintf(os.Stderr, "Serving %s on %s\n", what, listener.Addr())
+	} else {
+		fmt.Fprintf(os.Stderr, "Serving %s on http://%s\n", what, listener.Addr())
+	}
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func init() {
+	serveCmd.Flags().Bool("mcp", false, "Serve tools over the Model Context Protocol")
+	serveCmd.Flags().Bool("api", false, "Serve sessions and events over a local HTTP API")
+	serveCmd.Flags().String("transport", "stdio", "MCP transport to use: stdio or sse")
+	serveCmd.Flags().String("addr", "127.0.0.1:8787", "Address to listen on for the API or the sse transport")
+	serveCmd.Flags().Bool("read-only", false, "Only let API clients watch, not prompt the agent or answer permissions")
+	serveCmd.Flags().String("socket", "", "Unix socket to serve the API on instead of the address, without a token in a private directory")
+}
@@ -0,0 +1,491 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/crush/internal/apiserver"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/redact"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/sessionshare"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:     "sessions",
+	Aliases: []string{"session"},
+	Short:   "Inspect the sessions of the project",
+	Long:    "List, show, export and share the sessions of the current project without opening the TUI",
+	Example: `
+# List the sessions
+crush sessions list
+
+# Show a session
+crush sessions show 4f0c9a2e
+
+# Export a session as Markdown
+crush sessions export 4f0c9a2e > session.md
+
+# Find the sessions talking about a subject
+crush sessions search rate limit
+
+# Share a session with the team, in .crush/sessions
+crush sessions share 4f0c9a2e
+
+# Read the sessions shared by the team
+crush sessions shared
+  `,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+
+		sessions, _, closeDB, err := openSessions(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		list, err := sessions.List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		list = sessionsTagged(list, session.NormalizeTags(tags))
+
+		if jsonOutput {
+			out := make([]apiserver.Session, 0, len(list))
+			for _, s := range list {
+				out = append(out, apiserver.FromSession(s))
+			}
+			return printJSON(cmd, out)
+		}
+
+		if len(list) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No sessions yet.")
+			return nil
+		}
+
+		if term.IsTerminal(os.Stdout.Fd()) {
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return lipgloss.NewStyle().Padding(0, 2)
+				}).
+				Headers("ID", "Title", "Tags", "Messages", "Tokens", "Cost", "Updated")
+			for _, s := range list {
+				t.Row(
+					s.ID,
+					s.Title,
+					strings.Join(s.Tags, ", "),
+					strconv.FormatInt(s.MessageCount, 10),
+					strconv.FormatInt(s.PromptTokens+s.CompletionTokens, 10),
+					fmt.Sprintf("$%.2f", s.Cost),
+					time.Unix(s.UpdatedAt, 0).Local().Format("2006-01-02 15:04"),
+				)
+			}
+			lipgloss.Println(t)
+			return nil
+		}
+
+		for _, s := range list {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%d\t%s\n", s.ID, s.Title, s.MessageCount, time.Unix(s.UpdatedAt, 0).Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+var sessionsShowCmd = &cobra.Command{
+	Use:               "show <id>",
+	Short:             "Show a session and its conversation",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		sess, msgs, err := loadSession(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(cmd, sessionExport(sess, msgs))
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n", sess.Title)
+		fmt.Fprintf(cmd.OutOrStdout(), "ID:       %s\n", sess.ID)
+		fmt.Fprintf(cmd.OutOrStdout(), "Updated:  %s\n", time.Unix(sess.UpdatedAt, 0).Local().Format("2006-01-02 15:04"))
+		fmt.Fprintf(cmd.OutOrStdout(), "Messages: %d\n", sess.MessageCount)
+		fmt.Fprintf(cmd.OutOrStdout(), "Tokens:   %d in, %d out\n", sess.PromptTokens, sess.CompletionTokens)
+		fmt.Fprintf(cmd.OutOrStdout(), "Cost:     $%.2f\n", sess.Cost)
+		for _, msg := range msgs {
+			switch msg.Role {
+			case message.User:
+				fmt.Fprintf(cmd.OutOrStdout(), "\n> %s\n", strings.ReplaceAll(strings.TrimSpace(msg.Content().Text), "\n", "\n> "))
+			case message.Assistant:
+				if text := strings.TrimSpace(msg.Content().Text); text != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n", text)
+				}
+				for _, tc := range msg.ToolCalls() {
+					fmt.Fprintf(cmd.OutOrStdout(), "  → %s\n", tc.Name)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:               "export <id>",
+	Short:             "Export a session as Markdown or JSON",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		noRedact, _ := cmd.Flags().GetBool("no-redact")
+		if format != "markdown" && format != "json" {
+			return fmt.Errorf("invalid format %q: must be markdown or json", format)
+		}
+
+		sess, msgs, err := loadSession(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		var redactor *redact.Redactor
+		if !noRedact {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if redactor, err = redact.New(cfg); err != nil {
+				return err
+			}
+		}
+
+		var w io.Writer = cmd.OutOrStdout()
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		var exported string
+		if format == "json" {
+			data, err := json.MarshalIndent(sessionExport(sess, msgs), "", "  ")
+			if err != nil {
+				return err
+			}
+			exported = string(data) + "\n"
+		} else {
+			exported = sessionMarkdown(sess, msgs)
+		}
+		// Masks stay valid JSON, as they hold neither quotes nor backslashes.
+		_, err = io.WriteString(w, redactor.String(exported))
+		return err
+	},
+}
+
+var sessionsSearchCmd = &cobra.Command{
+	Use:   "search <words>...",
+	Short: "Search the prompts and responses of the sessions",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		sessions, messages, closeDB, err := openSessions(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		results, err := messages.Search(cmd.Context(), strings.Join(args, " "), limit)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(cmd, results)
+		}
+		if len(results) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No matches.")
+			return nil
+		}
+
+		titles := map[string]string{}
+		for _, r := range results {
+			if _, ok := titles[r.SessionID]; ok {
+				continue
+			}
+			if sess, err := sessions.Get(cmd.Context(), r.SessionID); err == nil {
+				titles[r.SessionID] = sess.Title
+			}
+		}
+		for _, r := range results {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\n", r.SessionID, titles[r.SessionID], r.Role, strings.Join(strings.Fields(r.Snippet), " "))
+		}
+		return nil
+	},
+}
+
+var sessionsShareCmd = &cobra.Command{
+	Use:               "share <id>",
+	Short:             "Share a session with the team in .crush/sessions",
+	Long:              "Write a session, its secrets masked, to the .crush/sessions directory of the project, where it can be committed for teammates to read",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sess, msgs, err := loadSession(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		redactor, err := redact.New(cfg)
+		if err != nil {
+			return err
+		}
+		path, err := sessionshare.Write(cfg.WorkingDir(), sessionshare.New(cmd.Context(), cfg.WorkingDir(), sess, msgs), redactor)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), path)
+		return nil
+	},
+}
+
+var sessionsSharedCmd = &cobra.Command{
+	Use:   "shared [file]",
+	Short: "List the sessions shared in .crush/sessions, or show one",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			shared, err := sessionshare.Read(args[0])
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(cmd.OutOrStdout(), sessionshare.Markdown(shared))
+			return err
+		}
+
+		cwd, err := ResolveCwd(cmd)
+		if err != nil {
+			return err
+		}
+		list, err := sessionshare.List(cwd)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			rel, err := filepath.Rel(cwd, s.Path)
+			if err != nil {
+				rel = s.Path
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\n", rel, s.Session.Title, s.SharedBy, time.Unix(s.SharedAt, 0).Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+func init() {
+	sessionsListCmd.Flags().Bool("json", false, "Output as JSON")
+	sessionsListCmd.Flags().StringSlice("tag", nil, "Only list the sessions with these tags")
+	sessionsShowCmd.Flags().Bool("json", false, "Output as JSON")
+	sessionsExportCmd.Flags().StringP("format", "f", "markdown", "Export format: markdown or json")
+	sessionsExportCmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+	sessionsExportCmd.Flags().Bool("no-redact", false, "Export secrets as they are instead of masking them")
+	_ = sessionsExportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"markdown", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	sessionsSearchCmd.Flags().Bool("json", false, "Output as JSON")
+	sessionsSearchCmd.Flags().IntP("limit", "n", 20, "Maximum number of matches")
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsShowCmd, sessionsExportCmd, sessionsSearchCmd, sessionsShareCmd, sessionsSharedCmd)
+}
+
+// openSessions connects to the database of the project without starting
+// the rest of the app.
+// sessionsTagged keeps the sessions having all the tags.
+func sessionsTagged(sessions []session.Session, tags []string) []session.Session {
+	return slices.DeleteFunc(sessions, func(s session.Session) bool {
+		for _, tag := range tags {
+			if !slices.Contains(s.Tags, tag) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func openSessions(cmd *cobra.Command) (session.Service, message.Service, func(), error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	conn, err := db.Connect(cmd.Context(), cfg.Options.DataDirectory)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	q := db.New(conn)
+	return session.NewService(q), message.NewService(q), func() { conn.Close() }, nil
+}
+
+// loadConfig loads the configuration of the project the command runs in.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, err
+	}
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	cfg, err := config.Load(cwd, dataDir, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadSession returns the session with the given ID, or the only one
+// starting with it, and its messages.
+func loadSession(cmd *cobra.Command, id string) (session.Session, []message.Message, error) {
+	sessions, messages, closeDB, err := openSessions(cmd)
+	if err != nil {
+		return session.Session{}, nil, err
+	}
+	defer closeDB()
+
+	sess, err := findSession(cmd.Context(), sessions, id)
+	if err != nil {
+		return session.Session{}, nil, err
+	}
+	msgs, err := messages.List(cmd.Context(), sess.ID)
+	if err != nil {
+		return session.Session{}, nil, err
+	}
+	return sess, msgs, nil
+}
+
+func findSession(ctx context.Context, sessions session.Service, id string) (session.Session, error) {
+	sess, err := sessions.Get(ctx, id)
+	if err == nil {
+		return sess, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return session.Session{}, err
+	}
+	list, err := sessions.List(ctx)
+	if err != nil {
+		return session.Session{}, err
+	}
+	var matches []session.Session
+	for _, s := range list {
+		if strings.HasPrefix(s.ID, id) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return session.Session{}, fmt.Errorf("session %q not found", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return session.Session{}, fmt.Errorf("session %q is ambiguous, it matches %d sessions", id, len(matches))
+	}
+}
+
+func completeSessionIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sessions, _, closeDB, err := openSessions(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	defer closeDB()
+	list, err := sessions.List(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []cobra.Completion
+	for _, s := range list {
+		if strings.HasPrefix(s.ID, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(s.ID, s.Title))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+type exportedSession struct {
+	Session  apiserver.Session   `json:"session"`
+	Messages []apiserver.Message `json:"messages"`
+}
+
+func sessionExport(sess session.Session, msgs []message.Message) exportedSession {
+	out := exportedSession{Session: apiserver.FromSession(sess), Messages: make([]apiserver.Message, 0, len(msgs))}
+	for _, msg := range msgs {
+		out.Messages = append(out.Messages, apiserver.FromMessage(msg))
+	}
+	return out
+}
+
+// sessionMarkdown renders the conversation of a session, tool calls and
+// results included.
+func sessionMarkdown(sess session.Session, msgs []message.Message) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", sess.Title)
+	for _, msg := range msgs {
+		switch msg.Role {
+		case message.User:
+			sb.WriteString("\n## User\n\n")
+			sb.WriteString(strings.TrimSpace(msg.Content().Text) + "\n")
+			for _, bc := range msg.BinaryContent() {
+				fmt.Fprintf(&sb, "\nAttached: `%s`\n", bc.Path)
+			}
+		case message.Assistant:
+			sb.WriteString("\n## Assistant\n")
+			if text := strings.TrimSpace(msg.Content().Text); text != "" {
+				sb.WriteString("\n" + text + "\n")
+			}
+			for _, tc := range msg.ToolCalls() {
+				fmt.Fprintf(&sb, "\n**Tool call** `%s`\n\n```json\n%s\n```\n", tc.Name, tc.Input)
+			}
+		case message.Tool:
+			for _, tr := range msg.ToolResults() {
+				label := "Tool result"
+				if tr.IsError {
+					label = "Tool error"
+				}
+				fmt.Fprintf(&sb, "\n**%s** `%s`\n\n```\n%s\n```\n", label, tr.Name, strings.TrimSpace(tr.Content))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func printJSON(cmd *cobra.Command, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionMarkdown(t *testing.T) {
+	t.Parallel()
+
+	msgs := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "List the files"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.TextContent{Text: "Sure."},
+			message.ToolCall{ID: "1", Name: "ls", Input: `{"path":"."}`},
+		}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "1", Name: "ls", Content: "main.go\n"}}},
+	}
+	require.Equal(t, "# Files\n"+
+		"\n## User\n\nList the files\n"+
+		"\n## Assistant\n\nSure.\n"+
+		"\n**Tool call** `ls`\n\n```json\n{\"path\":\".\"}\n```\n"+
+		"\n**Tool result** `ls`\n\n```\nmain.go\n```\n",
+		sessionMarkdown(session.Session{Title: "Files"}, msgs))
+}
+
+func TestConfigValue(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, json.RawMessage("true"), configValue("true"))
+	require.Equal(t, json.RawMessage(`["a"]`), configValue(`["a"]`))
+	require.Equal(t, "gpt-4o", configValue("gpt-4o"))
+}
+
+func TestSessionsTagged(t *testing.T) {
+	t.Parallel()
+
+	sessions := []session.Session{
+		{ID: "a", Tags: []string{"crush", "gpt-5"}},
+		{ID: "b", Tags: []string{"crush"}},
+		{ID: "c"},
+	}
+	require.Len(t, sessionsTagged(slices.Clone(sessions), nil), 3)
+	require.Equal(t, []session.Session{sessions[0], sessions[1]}, sessionsTagged(slices.Clone(sessions), []string{"crush"}))
+	require.Equal(t, []session.Session{sessions[0]}, sessionsTagged(slices.Clone(sessions), []string{"crush", "gpt-5"}))
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/apiserver"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/spectate"
+	"github.com/spf13/cobra"
+)
+
+var spectateCmd = &cobra.Command{
+	Use:   "spectate",
+	Short: "Watch a running Crush live, read-only",
+	Long: `Attach to a running Crush and print the conversation of the session it works
+on as it goes, without being able to prompt it or answer its permissions. Handy
+for pairing and demos.
+
+By default, it attaches to the Crush of the project started with --spectators,
+over a unix socket in the data directory. With --addr, it attaches to an API
+served by crush serve --api instead, with the token read from $CRUSH_API_TOKEN.`,
+	Example: `
+# In one terminal
+crush --spectators
+
+# In another one
+crush spectate
+
+# Watch an API served elsewhere
+CRUSH_API_TOKEN=... crush spectate --addr http://127.0.0.1:8787
+  `,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		socket, _ := cmd.Flags().GetString("socket")
+
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+
+		if addr != "" {
+			return spectate.NewClient(addr, os.Getenv("CRUSH_API_TOKEN")).Watch(ctx, cmd.OutOrStdout())
+		}
+		if socket == "" {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			socket = filepath.Join(cfg.Options.DataDirectory, spectate.SocketName)
+		}
+		if _, err := os.Stat(socket); err != nil {
+			return fmt.Errorf("no Crush to spectate at %s, start it with --spectators", socket)
+		}
+		return spectate.NewSocketClient(socket, os.Getenv("CRUSH_API_TOKEN")).Watch(ctx, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	spectateCmd.Flags().String("addr", "", "URL of an API served by crush serve --api to watch")
+	spectateCmd.Flags().String("socket", "", "Unix socket to watch, instead of the one of the project")
+}
+
+// serveSpectators serves the API read-only on the unix socket of the data
+// directory, for crush spectate, until ctx is done.
+func serveSpectators(ctx context.Context, app *app.App) error {
+	path := filepath.Join(app.Config().Options.DataDirectory, spectate.SocketName)
+	listener, err := listenUnix(path)
+	if err != nil {
+		return fmt.Errorf("failed to serve spectators: %w", err)
+	}
+	srv := apiserver.New(app.Sessions, app.Messages, app.Permissions, nil, "")
+	srv.Start(ctx)
+	server := &http.Server{
+		Handler:           srv.ReadOnly(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Spectator server failed", "error", err)
+		}
+	}()
+	return nil
+}
+
+// listenUnix listens on a unix socket only the user can reach, replacing
+// one left behind by a Crush that's gone, but not one still in use.
+func listenUnix(path string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another Crush is already spectated at %s", path)
+	}
+	_ = os.Remove(path)
+	return listenPrivate(path)
+}
+
+// privateDir reports whether only the user can reach into dir, so that a
+// socket in it needs no token: it's owned by the user and closed to others.
+func privateDir(dir string) bool {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() || info.Mode().Perm()&0o077 != 0 {
+		return false
+	}
+	uid, err := fsext.Owner(dir)
+	return err == nil && uid == os.Getuid()
+}
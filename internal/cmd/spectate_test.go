@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions")
+	}
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0o755))
+	require.False(t, privateDir(dir), "others can reach into it")
+
+	path := filepath.Join(dir, "crush.sock")
+	listener, err := listenUnix(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the socket is created for the user only")
+
+	_, err = listenUnix(path)
+	require.Error(t, err, "a socket in use isn't replaced")
+
+	private := filepath.Join(dir, "private")
+	require.NoError(t, os.Mkdir(private, 0o700))
+	require.True(t, privateDir(private))
+}
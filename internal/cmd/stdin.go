@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/x/term"
+)
+
+// maxStdinSize matches the limit of the editor attachments.
+const maxStdinSize = 5 * 1024 * 1024
+
+// readStdin returns what was piped or redirected to stdin, or nil when stdin
+// is a terminal.
+func readStdin() ([]byte, error) {
+	if term.IsTerminal(os.Stdin.Fd()) {
+		return nil, nil
+	}
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, err
+	}
+	// Check if stdin is a named pipe ( | ) or regular file ( < ).
+	if fi.Mode()&os.ModeNamedPipe == 0 && !fi.Mode().IsRegular() {
+		return nil, nil
+	}
+	bts, err := io.ReadAll(io.LimitReader(os.Stdin, maxStdinSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(bts) > maxStdinSize {
+		return nil, fmt.Errorf("stdin is larger than %d MB", maxStdinSize/1024/1024)
+	}
+	return bts, nil
+}
+
+// stdinAttachment turns what was piped to stdin into an attachment. Text is
+// wrapped in a code block of its detected syntax so the model reads it as
+// such.
+func stdinAttachment(content []byte) (message.Attachment, error) {
+	mimeType := http.DetectContentType(content[:min(512, len(content))])
+	if strings.HasPrefix(mimeType, "image/") {
+		return message.Attachment{
+			FileName: "stdin." + strings.TrimPrefix(mimeType, "image/"),
+			MimeType: mimeType,
+			Content:  content,
+		}, nil
+	}
+	if !utf8.Valid(content) {
+		return message.Attachment{}, errors.New("stdin is neither text nor an image")
+	}
+
+	syntax := detectSyntax(content)
+	text := strings.TrimRight(string(content), "\n")
+	return message.Attachment{
+		FileName: "stdin." + syntax,
+		MimeType: "text/plain",
+		Content:  []byte("```" + syntax + "\n" + text + "\n```"),
+	}, nil
+}
+
+// detectSyntax guesses the syntax of piped text, returning the extension
+// used for it.
+func detectSyntax(content []byte) string {
+	text := string(bytes.TrimSpace(content))
+	firstLine, _, _ := strings.Cut(text, "\n")
+	switch {
+	case strings.HasPrefix(text, "diff --git "),
+		strings.HasPrefix(text, "--- ") && strings.Contains(text, "\n+++ ") && strings.Contains(text, "\n@@ "):
+		return "diff"
+	case json.Valid(content):
+		return "json"
+	case strings.HasPrefix(firstLine, "#!"):
+		for _, shell := range []string{"bash", "zsh", "sh"} {
+			if strings.HasSuffix(firstLine, "/"+shell) || strings.HasSuffix(firstLine, " "+shell) {
+				return "sh"
+			}
+		}
+		if strings.Contains(firstLine, "python") {
+			return "py"
+		}
+		if strings.Contains(firstLine, "node") {
+			return "js"
+		}
+	case strings.HasPrefix(firstLine, "package ") && !strings.HasSuffix(firstLine, ";"):
+		return "go"
+	case strings.HasPrefix(strings.ToLower(text), "<!doctype html"), strings.HasPrefix(text, "<html"):
+		return "html"
+	case strings.HasPrefix(text, "<?xml"):
+		return "xml"
+	}
+	return "txt"
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSyntax(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]string{
+		"diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n": "diff",
+		"--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n":                                             "diff",
+		`{"name": "crush"}`:                              "json",
+		"#!/usr/bin/env bash\necho hi\n":                 "sh",
+		"#!/usr/bin/env python3\nprint('hi')\n":          "py",
+		"package main\n\nfunc main() {}\n":               "go",
+		"package com.example;\n\nclass Main {}\n":        "txt",
+		"<!DOCTYPE html>\n<html></html>\n":               "html",
+		"panic: runtime error: index out of range [3]\n": "txt",
+	} {
+		require.Equal(t, want, detectSyntax([]byte(input)), input)
+	}
+}
+
+func TestStdinAttachment(t *testing.T) {
+	t.Parallel()
+
+	attachment, err := stdinAttachment([]byte("diff --git a/x b/x\n+hi\n\n"))
+	require.NoError(t, err)
+	require.Equal(t, "stdin.diff", attachment.FileName)
+	require.True(t, attachment.IsText())
+	require.Equal(t, "```diff\ndiff --git a/x b/x\n+hi\n```", string(attachment.Content))
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	attachment, err = stdinAttachment(png)
+	require.NoError(t, err)
+	require.Equal(t, "stdin.png", attachment.FileName)
+	require.True(t, attachment.IsImage())
+
+	_, err = stdinAttachment([]byte{0xff, 0xfe, 0x00, 0x01})
+	require.Error(t, err)
+}
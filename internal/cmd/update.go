@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/crush/internal/update"
+	"github.com/charmbracelet/crush/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update Crush to the latest release",
+	Long: `Download the latest release of Crush from GitHub for this platform, check it
+against the checksums of the release and replace the running executable with
+it. Crush installed with a package manager is left to the package manager.`,
+	Example: `
+# See what's new without installing it
+crush update --check
+
+# Install the latest release
+crush update
+  `,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, _ := cmd.Flags().GetBool("check")
+		force, _ := cmd.Flags().GetBool("force")
+
+		info, err := update.Check(cmd.Context(), version.Version, update.Default)
+		if err != nil {
+			return err
+		}
+		if !info.Available() && !force {
+			fmt.Fprintf(cmd.OutOrStdout(), "Crush v%s is the latest release.\n", info.Current)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Crush v%s is available, you have v%s.\n", info.Latest, info.Current)
+		if check {
+			if info.Changelog != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n", info.Changelog)
+			}
+			return nil
+		}
+		if info.IsDevelopment() && !force {
+			return errors.New("this is a development build, use --force to replace it with the release")
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		if exe, err = filepath.EvalSymlinks(exe); err != nil {
+			return err
+		}
+		if manager := update.ManagedInstall(exe); manager != "" && !force {
+			return fmt.Errorf("crush was installed with %s, update it there", manager)
+		}
+		if err := update.Install(cmd.Context(), info, exe); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Updated %s to v%s.\n", exe, info.Latest)
+		return nil
+	},
+}
+
+func init() {
+	updateCmd.Flags().Bool("check", false, "Only tell whether there's a new release, and what's new in it")
+	updateCmd.Flags().Bool("force", false, "Install the release over development builds and package manager installs")
+}
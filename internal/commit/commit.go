@@ -0,0 +1,128 @@
+// Package commit drafts and creates git commits from the staged changes.
+package commit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/remote"
+)
+
+// maxDiffSize is the size of the largest diff sent to the model. Bigger
+// diffs are cut, keeping the list of changed files intact.
+const maxDiffSize = 64 * 1024
+
+// ErrNothingStaged is returned when there are no staged changes to commit.
+var ErrNothingStaged = errors.New("nothing staged to commit, stage your changes with git add first")
+
+// StagedDiff returns the staged changes of the repository containing dir,
+// prefixed with a summary of the changed files.
+func StagedDiff(ctx context.Context, dir string) (string, error) {
+	stat, err := git(ctx, dir, nil, "diff", "--cached", "--no-color", "--stat")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(stat) == "" {
+		return "", ErrNothingStaged
+	}
+	diff, err := git(ctx, dir, nil, "diff", "--cached", "--no-color")
+	if err != nil {
+		return "", err
+	}
+	if len(diff) > maxDiffSize {
+		diff = diff[:maxDiffSize] + "\n[diff truncated]\n"
+	}
+	return stat + "\n" + diff, nil
+}
+
+// Commit commits the staged changes with the message and returns the
+// summary git prints, e.g. "[main 1a2b3c4] Fix the thing".
+func Commit(ctx context.Context, dir, message string) (string, error) {
+	if strings.TrimSpace(message) == "" {
+		return "", errors.New("commit message is empty")
+	}
+	out, err := git(ctx, dir, strings.NewReader(message), "commit", "--file=-")
+	if err != nil {
+		return "", err
+	}
+	summary, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	return summary, nil
+}
+
+// Instructions describes the commit message style the options ask for, to
+// be handed to the model drafting the message.
+func Instructions(opts *config.CommitOptions) string {
+	if opts == nil {
+		opts = &config.CommitOptions{}
+	}
+	var sb strings.Builder
+	if opts.MaxSubjectLength > 0 {
+		fmt.Fprintf(&sb, "- Keep the subject line under %d characters.\n", opts.MaxSubjectLength)
+	}
+	if opts.Conventional {
+		sb.WriteString("- Follow the Conventional Commits format: type(scope): subject. The scope is optional.\n")
+		if len(opts.Types) > 0 {
+			fmt.Fprintf(&sb, "- The type must be one of: %s.\n", strings.Join(opts.Types, ", "))
+		}
+		if len(opts.Scopes) > 0 {
+			fmt.Fprintf(&sb, "- When there is a scope, it must be one of: %s.\n", strings.Join(opts.Scopes, ", "))
+		}
+	}
+	if opts.Body {
+		sb.WriteString("- After the subject, add a blank line and a short body explaining what changed and why, wrapped at 72 characters.\n")
+	} else {
+		sb.WriteString("- Write only the subject line, without a body.\n")
+	}
+	return sb.String()
+}
+
+// WithAttribution appends the attribution configured for commits made with
+// Crush to the message.
+func WithAttribution(message string, attribution *config.Attribution, modelName string) string {
+	message = strings.TrimSpace(message)
+	if attribution == nil {
+		return message + "\n"
+	}
+	var extra []string
+	if attribution.GeneratedWith {
+		extra = append(extra, "💘 Generated with Crush")
+	}
+	switch attribution.TrailerStyle {
+	case config.TrailerStyleAssistedBy:
+		if modelName == "" {
+			extra = append(extra, "Assisted-by: Crush <crush@charm.land>")
+		} else {
+			extra = append(extra, fmt.Sprintf("Assisted-by: %s via Crush <crush@charm.land>", modelName))
+		}
+	case config.TrailerStyleCoAuthoredBy:
+		extra = append(extra, "Co-Authored-By: Crush <crush@charm.land>")
+	}
+	if len(extra) == 0 {
+		return message + "\n"
+	}
+	return message + "\n\n" + strings.Join(extra, "\n\n") + "\n"
+}
+
+func git(ctx context.Context, dir string, stdin *strings.Reader, args ...string) (string, error) {
+	cmd := remote.Command(ctx, dir, "git", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		if msg := strings.TrimSpace(stdout.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,116 @@
+package commit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "Crush"},
+		{"config", "user.email", "crush@charm.land"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		_, err := git(t.Context(), dir, nil, args...)
+		require.NoError(t, err)
+	}
+	return dir
+}
+
+func TestStagedDiffAndCommit(t *testing.T) {
+	t.Parallel()
+
+	dir := initRepo(t)
+	_, err := StagedDiff(t.Context(), dir)
+	require.ErrorIs(t, err, ErrNothingStaged)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+	_, err = git(t.Context(), dir, nil, "add", "main.go")
+	require.NoError(t, err)
+
+	diff, err := StagedDiff(t.Context(), dir)
+	require.NoError(t, err)
+	require.Contains(t, diff, "main.go | 1 +")
+	require.Contains(t, diff, "+package main")
+
+	summary, err := Commit(t.Context(), dir, "Add the main package\n")
+	require.NoError(t, err)
+	require.Contains(t, summary, "Add the main package")
+
+	_, err = StagedDiff(t.Context(), dir)
+	require.ErrorIs(t, err, ErrNothingStaged)
+}
+
+func TestCommitEmptyMessage(t *testing.T) {
+	t.Parallel()
+
+	_, err := Commit(t.Context(), t.TempDir(), " \n")
+	require.EqualError(t, err, "commit message is empty")
+}
+
+func TestInstructions(t *testing.T) {
+	t.Parallel()
+
+	got := Instructions(&config.CommitOptions{
+		Conventional:     true,
+		Types:            []string{"feat", "fix"},
+		Scopes:           []string{"tui"},
+		MaxSubjectLength: 50,
+	})
+	require.Contains(t, got, "under 50 characters")
+	require.Contains(t, got, "Conventional Commits")
+	require.Contains(t, got, "one of: feat, fix.")
+	require.Contains(t, got, "one of: tui.")
+	require.Contains(t, got, "without a body")
+
+	got = Instructions(&config.CommitOptions{Body: true})
+	require.NotContains(t, got, "Conventional Commits")
+	require.Contains(t, got, "short body")
+}
+
+func TestWithAttribution(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		attribution *config.Attribution
+		want        string
+	}{
+		{
+			name: "none",
+			want: "feat: add x\n",
+		},
+		{
+			name:        "assisted by",
+			attribution: &config.Attribution{TrailerStyle: config.TrailerStyleAssistedBy},
+			want:        "feat: add x\n\nAssisted-by: Small Model via Crush <crush@charm.land>\n",
+		},
+		{
+			name:        "co-authored by and generated with",
+			attribution: &config.Attribution{TrailerStyle: config.TrailerStyleCoAuthoredBy, GeneratedWith: true},
+			want:        "feat: add x\n\n💘 Generated with Crush\n\nCo-Authored-By: Crush <crush@charm.land>\n",
+		},
+		{
+			name:        "trailer disabled",
+			attribution: &config.Attribution{TrailerStyle: config.TrailerStyleNone},
+			want:        "feat: add x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, WithAttribution("feat: add x\n", tt.attribution, "Small Model"))
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
@@ -28,11 +29,14 @@ import (
 )
 
 const (
-	appName              = "crush"
-	defaultDataDirectory = ".crush"
-	defaultInitializeAs  = "AGENTS.md"
+	appName                    = "crush"
+	defaultDataDirectory       = ".crush"
+	defaultInitializeAs        = "AGENTS.md"
+	defaultCommitSubjectLength = 72
 )
 
+var defaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
 var defaultContextPaths = []string{
 	".github/copilot-instructions.md",
 	".cursorrules",
@@ -116,6 +120,11 @@ type ProviderConfig struct {
 	// Extra body
 	ExtraBody map[string]any `json:"extra_body,omitempty" jsonschema:"description=Additional fields to include in request bodies, only works with openai-compatible providers"`
 
+	// Rewrites of the request and response bodies, for proxies and
+	// self-hosted backends that differ from the API they mimic.
+	RequestTransform  *ProviderTransform `json:"request_transform,omitempty" jsonschema:"description=Rewrites the JSON body of requests; only works with openai-compatible providers"`
+	ResponseTransform *ProviderTransform `json:"response_transform,omitempty" jsonschema:"description=Rewrites the JSON body of responses and of each streamed event; only works with openai-compatible providers"`
+
 	ProviderOptions map[string]any `json:"provider_options,omitempty" jsonschema:"description=Additional provider-specific options for this provider"`
 
 	// Used to pass extra parameters to the provider.
@@ -123,6 +132,18 @@ type ProviderConfig struct {
 
 	// The provider models
 	Models []catwalk.Model `json:"models,omitempty" jsonschema:"description=List of models available from this provider"`
+
+	// IDs of the models that can't call tools, found by asking Ollama.
+	ToolsUnsupported []string `json:"-"`
+}
+
+// ProviderTransform rewrites a JSON body. Fields are named by their dotted
+// path, where * stands for every element of an array, like
+// choices.*.delta.reasoning. Fields are renamed, then removed, then set.
+type ProviderTransform struct {
+	Rename map[string]string `json:"rename,omitempty" jsonschema:"description=New names of fields by their dotted path; * matches every array element"`
+	Remove []string          `json:"remove,omitempty" jsonschema:"description=Dotted paths of fields to remove,example=stream_options"`
+	Set    map[string]any    `json:"set,omitempty" jsonschema:"description=Values of fields to set by their dotted path"`
 }
 
 // ToProvider converts the [ProviderConfig] to a [catwalk.Provider].
@@ -194,6 +215,18 @@ type MCPConfig struct {
 
 	// TODO: maybe make it possible to get the value from the env
 	Headers map[string]string `json:"headers,omitempty" jsonschema:"description=HTTP headers for HTTP/SSE MCP servers"`
+
+	OAuth *MCPOAuthConfig `json:"oauth,omitempty" jsonschema:"description=OAuth configuration for HTTP/SSE MCP servers that require authorization"`
+}
+
+// MCPOAuthConfig configures the OAuth authorization code flow for an MCP
+// server. Endpoints are discovered from the server when left empty.
+type MCPOAuthConfig struct {
+	ClientID         string   `json:"client_id,omitempty" jsonschema:"description=OAuth client ID, registered dynamically when empty"`
+	ClientSecret     string   `json:"client_secret,omitempty" jsonschema:"description=OAuth client secret for confidential clients"`
+	AuthorizationURL string   `json:"authorization_url,omitempty" jsonschema:"description=Authorization endpoint, discovered from the server when empty,format=uri"`
+	TokenURL         string   `json:"token_url,omitempty" jsonschema:"description=Token endpoint, discovered from the server when empty,format=uri"`
+	Scopes           []string `json:"scopes,omitempty" jsonschema:"description=OAuth scopes to request,example=read"`
 }
 
 type LSPConfig struct {
@@ -210,10 +243,138 @@ type LSPConfig struct {
 type TUIOptions struct {
 	CompactMode bool   `json:"compact_mode,omitempty" jsonschema:"description=Enable compact mode for the TUI interface,default=false"`
 	DiffMode    string `json:"diff_mode,omitempty" jsonschema:"description=Diff mode for the TUI interface,enum=unified,enum=split"`
-	// Here we can add themes later or any TUI related options
-	//
 
-	Completions Completions `json:"completions,omitzero" jsonschema:"description=Completions UI options"`
+	DisableDiagnosticsFeedback bool `json:"disable_diagnostics_feedback,omitempty" jsonschema:"description=Disable offering to send new LSP errors back to the agent after it edits files,default=false"`
+	DisableCIStatus            bool `json:"disable_ci_status,omitempty" jsonschema:"description=Disable following the CI status of the current branch in the status bar,default=false"`
+	DisableFileWatch           bool `json:"disable_file_watch,omitempty" jsonschema:"description=Disable noticing when files the agent has read are changed outside Crush,default=false"`
+	CollapseToolCalls          bool `json:"collapse_tool_calls,omitempty" jsonschema:"description=Show only the header of tool calls until they are expanded,default=false"`
+	DisableTerminalTitle       bool `json:"disable_terminal_title,omitempty" jsonschema:"description=Disable setting the terminal title to the current session,default=false"`
+	VimMode                    bool `json:"vim_mode,omitempty" jsonschema:"description=Navigate the chat with vim-style keys; esc in the editor switches to the chat and i back,default=false"`
+	VimEditor                  bool `json:"vim_editor,omitempty" jsonschema:"description=Edit the prompt like vim: with normal; insert and visual modes; operators; text objects and registers,default=false"`
+	ReducedMotion              bool `json:"reduced_motion,omitempty" jsonschema:"description=Keep spinners and animations still and redraw less often; useful over slow SSH connections and for accessibility,default=false"`
+	DisableMouse               bool `json:"disable_mouse,omitempty" jsonschema:"description=Leave the mouse to the terminal; panes are focused with tab and shift+tab,default=false"`
+
+	Clipboard          string `json:"clipboard,omitempty" jsonschema:"description=How text is copied; auto tries the native clipboard and the clipboard commands before OSC 52,enum=auto,enum=native,enum=wl-copy,enum=xclip,enum=xsel,enum=pbcopy,enum=clip.exe,enum=osc52,default=auto"`
+	TerminalProgress   string `json:"terminal_progress,omitempty" jsonschema:"description=Report progress to the terminal tab while the agent works; auto does it in terminals known to support it,enum=auto,enum=always,enum=never,default=auto"`
+	SynchronizedOutput string `json:"synchronized_output,omitempty" jsonschema:"description=Draw frames at once with synchronized output (mode 2026) to avoid tearing; auto does it when the terminal says it supports it,enum=auto,enum=always,default=auto"`
+	Theme              string `json:"theme,omitempty" jsonschema:"description=Color theme of the interface,default=charmtone"`
+	Editor             string `json:"editor,omitempty" jsonschema:"description=Command editing prompts; commit messages and pull requests; defaults to $VISUAL then $EDITOR,example=nvim,example=code --wait"`
+
+	ToolEnv     map[string]map[string]string `json:"tool_env,omitempty" jsonschema:"description=Environment variables to set for the external tools Crush runs; by tool: editor; gh_dash or shell for the code blocks run"`
+	ToolRestart map[string]string            `json:"tool_restart,omitempty" jsonschema:"description=What to do when gh_dash or shell exits with an error: never (the default); ask to start it again; or always start it again after a growing delay"`
+
+	Completions   Completions   `json:"completions,omitzero" jsonschema:"description=Completions UI options"`
+	Notifications Notifications `json:"notifications,omitzero" jsonschema:"description=Desktop notifications sent while the terminal is not focused"`
+	ExternalPanes ExternalPanes `json:"external_panes,omitzero" jsonschema:"description=Run editors and other interactive tools in a tmux or zellij pane instead of inside Crush"`
+	Accessibility Accessibility `json:"accessibility,omitzero" jsonschema:"description=Output suited to terminal screen readers"`
+	StatusBar     StatusBar     `json:"status_bar,omitzero" jsonschema:"description=Segments shown in the status bar"`
+	SpellCheck    SpellCheck    `json:"spell_check,omitzero" jsonschema:"description=Spell checking of the prompt"`
+}
+
+// External tools Crush runs in the terminal, whose environment is set with
+// tool_env.
+const (
+	ExternalToolEditor = "editor"
+	ExternalToolGHDash = "gh_dash"
+	ExternalToolShell  = "shell"
+)
+
+// ToolEnvFor returns the environment configured for the external tool, with
+// its variables resolved.
+func (o *TUIOptions) ToolEnvFor(tool string) []string {
+	if len(o.ToolEnv[tool]) == 0 {
+		return nil
+	}
+	return resolveEnvs(maps.Clone(o.ToolEnv[tool]))
+}
+
+// Restart policies of external tools exiting with an error.
+const (
+	RestartNever  = "never"
+	RestartAsk    = "ask"
+	RestartAlways = "always"
+)
+
+// ToolRestartFor returns the restart policy of the external tool, never
+// unless configured otherwise.
+func (o *TUIOptions) ToolRestartFor(tool string) string {
+	switch policy := o.ToolRestart[tool]; policy {
+	case RestartAsk, RestartAlways:
+		return policy
+	}
+	return RestartNever
+}
+
+// SpellCheck configures checking the spelling of the prompt.
+type SpellCheck struct {
+	Enabled    bool     `json:"enabled,omitempty" jsonschema:"description=List the misspelled words of the prompt under it and suggest corrections with alt+s,default=false"`
+	Dictionary string   `json:"dictionary,omitempty" jsonschema:"description=Word list with one word per line,default=/usr/share/dict/words,example=/usr/share/dict/british-english"`
+	Words      []string `json:"words,omitempty" jsonschema:"description=Additional words spelled correctly,example=crush,example=kubectl"`
+}
+
+// Status bar segments built into Crush.
+const (
+	StatusSegmentModel  = "model"
+	StatusSegmentCost   = "cost"
+	StatusSegmentBranch = "branch"
+	StatusSegmentCI     = "ci"
+	StatusSegmentTasks  = "tasks"
+	StatusSegmentTime   = "time"
+	StatusSegmentRoot   = "root"
+)
+
+// StatusBar configures the segments shown at the right of the status bar.
+type StatusBar struct {
+	Segments []string                 `json:"segments,omitempty" jsonschema:"description=Segments shown in order; built-in segments are model, cost, branch, ci, tasks, time and root, and custom segments are referred to by name. Defaults to root and ci followed by the custom segments,example=model,example=cost,example=branch,example=ci,example=tasks,example=time,example=root"`
+	Custom   map[string]StatusSegment `json:"custom,omitempty" jsonschema:"description=Segments showing the output of a shell command, by name"`
+}
+
+// StatusSegment is a status bar segment showing the first line of the
+// output of a command.
+type StatusSegment struct {
+	Command  string `json:"command" jsonschema:"required,description=Shell command run in the active root of the workspace,example=kubectl config current-context"`
+	Interval int    `json:"interval,omitempty" jsonschema:"description=Seconds between runs of the command,default=30,example=60"`
+}
+
+// SetRemote makes the workspace the remote one given on the command line,
+// over the one of the config files.
+func (c *Config) SetRemote(r Remote) {
+	c.remoteFlag = &r
+	c.Options.Remote = &r
+}
+
+// Ordered returns the segments to show, in order.
+func (s StatusBar) Ordered() []string {
+	if len(s.Segments) > 0 {
+		return s.Segments
+	}
+	return append([]string{StatusSegmentRoot, StatusSegmentCI}, slices.Sorted(maps.Keys(s.Custom))...)
+}
+
+// Accessibility configures the screen reader mode.
+type Accessibility struct {
+	Enabled bool   `json:"enabled,omitempty" jsonschema:"description=Draw the UI without box drawing characters or animations and label messages by who sent them,default=false"`
+	Log     string `json:"log,omitempty" jsonschema:"description=File state changes are announced to as plain text lines; defaults to accessibility.log in the data directory,example=/tmp/crush-announcements.log"`
+}
+
+// ExternalPanes configures running interactive tools in a pane of the
+// terminal multiplexer Crush runs in.
+type ExternalPanes struct {
+	Multiplexer string `json:"multiplexer,omitempty" jsonschema:"description=Multiplexer to open panes in; auto picks the one Crush runs in,enum=auto,enum=tmux,enum=zellij"`
+	Window      bool   `json:"window,omitempty" jsonschema:"description=Open a new window instead of splitting the current one,default=false"`
+}
+
+// Notifications configures the desktop notifications.
+type Notifications struct {
+	Disabled bool     `json:"disabled,omitempty" jsonschema:"description=Disable desktop notifications,default=false"`
+	Method   string   `json:"method,omitempty" jsonschema:"description=How notifications are sent,enum=auto,enum=osc777,enum=terminal-notifier,enum=notify-send,default=auto"`
+	Events   []string `json:"events,omitempty" jsonschema:"description=Events to be notified of; all of them when empty,example=turn_finished,example=permission_requested,example=process_exited,example=scheduled_run"`
+	Always   bool     `json:"always,omitempty" jsonschema:"description=Notify even when the terminal is focused,default=false"`
+}
+
+// Wants reports whether the given event should be notified of.
+func (n Notifications) Wants(event string) bool {
+	return !n.Disabled && (len(n.Events) == 0 || slices.Contains(n.Events, event))
 }
 
 // Completions defines options for the completions UI.
@@ -226,9 +387,35 @@ func (c Completions) Limits() (depth, items int) {
 	return ptrValOr(c.MaxDepth, 0), ptrValOr(c.MaxItems, 0)
 }
 
+// Hooks are commands run on events of the agent, each receiving the event
+// as JSON on stdin.
+type Hooks struct {
+	PreToolCall  []Hook `json:"pre_tool_call,omitempty" jsonschema:"description=Run before a tool is called; a hook exiting with an error blocks the call, its output telling the agent why"`
+	PostEdit     []Hook `json:"post_edit,omitempty" jsonschema:"description=Run after the agent changes a file, once per file, as to format it"`
+	TurnComplete []Hook `json:"turn_complete,omitempty" jsonschema:"description=Run when the agent is done responding to a prompt"`
+	SessionEnd   []Hook `json:"session_end,omitempty" jsonschema:"description=Run when Crush exits, for each session prompted since it started"`
+}
+
+// Hook is a command run on an event of the agent.
+type Hook struct {
+	Command string   `json:"command" jsonschema:"required,description=Shell command to run; the event is on its stdin,example=jq -r .file_path | xargs gofmt -w"`
+	Tools   []string `json:"tools,omitempty" jsonschema:"description=Tools the hook runs for, on pre_tool_call and post_edit; all of them when empty,example=bash,example=edit"`
+	Timeout int      `json:"timeout,omitempty" jsonschema:"description=Seconds the command may run for,default=30"`
+}
+
 type Permissions struct {
-	AllowedTools []string `json:"allowed_tools,omitempty" jsonschema:"description=List of tools that don't require permission prompts,example=bash,example=view"` // Tools that don't require permission prompts
-	SkipRequests bool     `json:"-"`                                                                                                                              // Automatically accept all permissions (YOLO mode)
+	AllowedTools   []string `json:"allowed_tools,omitempty" jsonschema:"description=List of tools that don't require permission prompts,example=bash,example=view"`                                             // Tools that don't require permission prompts
+	AllowedDomains []string `json:"allowed_domains,omitempty" jsonschema:"description=Domains the fetch tool reads from without permission prompts; subdomains are included,example=go.dev,example=github.com"` // Domains fetched from without permission prompts
+	SkipRequests   bool     `json:"-"`                                                                                                                                                                          // Automatically accept all permissions (YOLO mode)
+}
+
+// AllowedDomains returns the domains fetched from without permission
+// prompts.
+func (c *Config) AllowedDomains() []string {
+	if c.Permissions == nil {
+		return nil
+	}
+	return c.Permissions.AllowedDomains
 }
 
 type TrailerStyle string
@@ -254,18 +441,63 @@ func (Attribution) JSONSchemaExtend(schema *jsonschema.Schema) {
 	}
 }
 
+type CommitOptions struct {
+	Conventional     bool     `json:"conventional,omitempty" jsonschema:"description=Draft commit messages following Conventional Commits,default=false"`
+	Types            []string `json:"types,omitempty" jsonschema:"description=Conventional commit types to choose from,example=feat,example=fix,example=docs"`
+	Scopes           []string `json:"scopes,omitempty" jsonschema:"description=Conventional commit scopes to choose from; any scope is allowed when empty,example=tui,example=lsp"`
+	Body             bool     `json:"body,omitempty" jsonschema:"description=Add a body explaining the change below the subject line,default=false"`
+	MaxSubjectLength int      `json:"max_subject_length,omitempty" jsonschema:"description=Maximum length of the subject line,default=72"`
+}
+
+// Failover configures retrying the requests of the large model and falling
+// back to other models when it is rate limited or failing.
+type Failover struct {
+	Models       []SelectedModel `json:"models,omitempty" jsonschema:"description=Models to fall back to; in order; when the large model keeps failing"`
+	MaxRetries   *int            `json:"max_retries,omitempty" jsonschema:"description=Times to retry a model before falling back to the next one,default=2,minimum=0"`
+	InitialDelay int             `json:"initial_delay,omitempty" jsonschema:"description=Seconds to wait before the first retry; doubled after each retry,default=2,minimum=0"`
+	MaxDelay     int             `json:"max_delay,omitempty" jsonschema:"description=Most seconds to wait between retries,default=30,minimum=0"`
+}
+
+// Redaction configures masking secrets before they are sent to providers or
+// exported.
+type Redaction struct {
+	Disabled bool     `json:"disabled,omitempty" jsonschema:"description=Send and export secrets as they are,default=false"`
+	Patterns []string `json:"patterns,omitempty" jsonschema:"description=Regular expressions matching more secrets; masking their first group when they have one,example=internal-[a-z0-9]{32}"`
+	EnvFiles []string `json:"env_files,omitempty" jsonschema:"description=Files whose values are secrets; relative to the working directory; .env and .env.local by default,example=.env.production"`
+}
+
+// Remote is a workspace living on another host, reached over SSH.
+type Remote struct {
+	Host string `json:"host" jsonschema:"required,description=SSH destination such as user@host or a host of ~/.ssh/config,example=devbox"`
+	Dir  string `json:"dir,omitempty" jsonschema:"description=Directory of the workspace on the host; the home directory by default,example=~/src/app"`
+}
+
+// GitHub selects the GitHub host and account gh works with.
+type GitHub struct {
+	Host string `json:"host,omitempty" jsonschema:"description=GitHub Enterprise host gh works with; github.com by default,example=github.example.com"`
+	User string `json:"user,omitempty" jsonschema:"description=Account logged in with gh auth login to use rather than the active one,example=octocat-work"`
+}
+
 type Options struct {
-	ContextPaths              []string     `json:"context_paths,omitempty" jsonschema:"description=Paths to files containing context information for the AI,example=.cursorrules,example=CRUSH.md"`
-	TUI                       *TUIOptions  `json:"tui,omitempty" jsonschema:"description=Terminal user interface options"`
-	Debug                     bool         `json:"debug,omitempty" jsonschema:"description=Enable debug logging,default=false"`
-	DebugLSP                  bool         `json:"debug_lsp,omitempty" jsonschema:"description=Enable debug logging for LSP servers,default=false"`
-	DisableAutoSummarize      bool         `json:"disable_auto_summarize,omitempty" jsonschema:"description=Disable automatic conversation summarization,default=false"`
-	DataDirectory             string       `json:"data_directory,omitempty" jsonschema:"description=Directory for storing application data (relative to working directory),default=.crush,example=.crush"` // Relative to the cwd
-	DisabledTools             []string     `json:"disabled_tools,omitempty" jsonschema:"description=List of built-in tools to disable and hide from the agent,example=bash,example=sourcegraph"`
-	DisableProviderAutoUpdate bool         `json:"disable_provider_auto_update,omitempty" jsonschema:"description=Disable providers auto-update,default=false"`
-	Attribution               *Attribution `json:"attribution,omitempty" jsonschema:"description=Attribution settings for generated content"`
-	DisableMetrics            bool         `json:"disable_metrics,omitempty" jsonschema:"description=Disable sending metrics,default=false"`
-	InitializeAs              string       `json:"initialize_as,omitempty" jsonschema:"description=Name of the context file to create/update during project initialization,default=AGENTS.md,example=AGENTS.md,example=CRUSH.md,example=CLAUDE.md,example=docs/LLMs.md"`
+	ContextPaths              []string       `json:"context_paths,omitempty" jsonschema:"description=Paths to files containing context information for the AI,example=.cursorrules,example=CRUSH.md"`
+	TUI                       *TUIOptions    `json:"tui,omitempty" jsonschema:"description=Terminal user interface options"`
+	Debug                     bool           `json:"debug,omitempty" jsonschema:"description=Enable debug logging,default=false"`
+	DebugLSP                  bool           `json:"debug_lsp,omitempty" jsonschema:"description=Enable debug logging for LSP servers,default=false"`
+	DisableAutoSummarize      bool           `json:"disable_auto_summarize,omitempty" jsonschema:"description=Disable automatic conversation summarization,default=false"`
+	DataDirectory             string         `json:"data_directory,omitempty" jsonschema:"description=Directory for storing application data (relative to working directory),default=.crush,example=.crush"` // Relative to the cwd
+	DisabledTools             []string       `json:"disabled_tools,omitempty" jsonschema:"description=List of built-in tools to disable and hide from the agent,example=bash,example=sourcegraph"`
+	Roots                     []string       `json:"roots,omitempty" jsonschema:"description=Other root directories of the workspace such as the repositories of other parts of the project; relative to the working directory,example=../backend"`
+	Remote                    *Remote        `json:"remote,omitempty" jsonschema:"description=Remote host the workspace lives on; where the tools; git and commands run over SSH"`
+	GitHub                    *GitHub        `json:"github,omitempty" jsonschema:"description=GitHub host and account used for pull requests; reviews; issues; CI status and gh dash"`
+	DisableProviderAutoUpdate bool           `json:"disable_provider_auto_update,omitempty" jsonschema:"description=Disable providers auto-update,default=false"`
+	Attribution               *Attribution   `json:"attribution,omitempty" jsonschema:"description=Attribution settings for generated content"`
+	Commit                    *CommitOptions `json:"commit,omitempty" jsonschema:"description=Options for the commit messages drafted by Crush"`
+	Failover                  *Failover      `json:"failover,omitempty" jsonschema:"description=Retry and fallback models for rate limited or failing providers"`
+	Redaction                 *Redaction     `json:"redaction,omitempty" jsonschema:"description=Masking of API keys; tokens and .env values before they reach providers or exports"`
+	LogRequests               bool           `json:"log_requests,omitempty" jsonschema:"description=Record the requests sent to providers in each session without their keys; to browse them with Inspect Requests,default=false"`
+	DisableMetrics            bool           `json:"disable_metrics,omitempty" jsonschema:"description=Disable sending metrics,default=false"`
+	DisableUpdateCheck        bool           `json:"disable_update_check,omitempty" jsonschema:"description=Disable checking for new releases of Crush on startup,default=false"`
+	InitializeAs              string         `json:"initialize_as,omitempty" jsonschema:"description=Name of the context file to create/update during project initialization,default=AGENTS.md,example=AGENTS.md,example=CRUSH.md,example=CLAUDE.md,example=docs/LLMs.md"`
 }
 
 type MCPs map[string]MCPConfig
@@ -352,10 +584,77 @@ type Agent struct {
 
 	// Overrides the context paths for this agent
 	ContextPaths []string `json:"context_paths,omitempty"`
+
+	// Custom system prompt template, only set for user-defined sub-agents
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// SubAgent is a user-defined agent the coder can delegate tasks to through
+// the agent tool.
+type SubAgent struct {
+	Description  string              `json:"description" jsonschema:"required,description=What the agent is good at; shown to the main agent when deciding whether to delegate,example=Reviews changes for bugs and style issues"`
+	Prompt       string              `json:"prompt,omitempty" jsonschema:"description=System prompt for the agent; supports the same template variables as the built-in prompts and defaults to the task prompt"`
+	Model        SelectedModelType   `json:"model,omitempty" jsonschema:"description=The model type to use for this agent,enum=large,enum=small,default=large"`
+	AllowedTools []string            `json:"allowed_tools,omitempty" jsonschema:"description=Built-in tools this agent may use; defaults to read-only tools,example=glob,example=grep,example=view"`
+	AllowedMCP   map[string][]string `json:"allowed_mcp,omitempty" jsonschema:"description=MCP servers this agent may use mapped to their allowed tools; an empty list allows every tool of that server"`
+	Disabled     bool                `json:"disabled,omitempty" jsonschema:"description=Whether this agent is disabled,default=false"`
+}
+
+// JobProfile is a command started by name as a background job from the
+// commands, like a dev server or a database console.
+type JobProfile struct {
+	Command   string            `json:"command" jsonschema:"required,description=Shell command started as a background job,example=npm run dev"`
+	Args      []string          `json:"args,omitempty" jsonschema:"description=Arguments appended to the command; quoted for the shell,example=--port,example=3000"`
+	Env       map[string]string `json:"env,omitempty" jsonschema:"description=Environment variables of the job; values can refer to other variables like $HOME"`
+	Cwd       string            `json:"cwd,omitempty" jsonschema:"description=Directory the job runs in; relative to the working directory,example=web"`
+	Title     string            `json:"title,omitempty" jsonschema:"description=Title of the job in the commands and the jobs; defaults to its name,example=Dev server"`
+	ReadOnly  bool              `json:"readonly,omitempty" jsonschema:"description=Keep the job from taking input typed in the jobs; for jobs only watched,default=false"`
+	Autostart bool              `json:"autostart,omitempty" jsonschema:"description=Start the job in the background when Crush opens; best set in the config of the project,default=false"`
+}
+
+// Environ returns the environment variables of the job as KEY=value, their
+// values resolved.
+func (p JobProfile) Environ() []string {
+	if len(p.Env) == 0 {
+		return nil
+	}
+	return resolveEnvs(maps.Clone(p.Env))
 }
 
 type Tools struct {
-	Ls ToolLs `json:"ls,omitzero"`
+	Ls             ToolLs             `json:"ls,omitzero"`
+	SemanticSearch ToolSemanticSearch `json:"semantic_search,omitzero"`
+	WebSearch      ToolWebSearch      `json:"web_search,omitzero"`
+}
+
+// Web search engines the web_search tool can use.
+const (
+	SearchProviderDuckDuckGo = "duckduckgo"
+	SearchProviderBrave      = "brave"
+	SearchProviderSearXNG    = "searxng"
+	SearchProviderBing       = "bing"
+)
+
+// ToolWebSearch configures the web_search tool.
+type ToolWebSearch struct {
+	Provider       string   `json:"provider,omitempty" jsonschema:"description=Search engine to use; brave and bing need an API key and searxng a base URL,enum=duckduckgo,enum=brave,enum=searxng,enum=bing,default=duckduckgo"`
+	APIKey         string   `json:"api_key,omitempty" jsonschema:"description=API key of the search engine,example=$BRAVE_API_KEY"`
+	BaseURL        string   `json:"base_url,omitempty" jsonschema:"description=Base URL of the search API; required for searxng,format=uri,example=http://localhost:8888"`
+	AllowedDomains []string `json:"allowed_domains,omitempty" jsonschema:"description=Only return results from these domains and their subdomains,example=go.dev"`
+	BlockedDomains []string `json:"blocked_domains,omitempty" jsonschema:"description=Never return results from these domains and their subdomains,example=pinterest.com"`
+}
+
+// ToolSemanticSearch configures the embedding index behind the
+// semantic_search tool. The tool is only available when a model is set.
+type ToolSemanticSearch struct {
+	Model    string `json:"model,omitempty" jsonschema:"description=Embedding model used to index the repository; enables semantic search,example=text-embedding-3-small,example=nomic-embed-text"`
+	Provider string `json:"provider,omitempty" jsonschema:"description=ID of a configured provider whose base URL and API key are used for embeddings,example=openai"`
+	BaseURL  string `json:"base_url,omitempty" jsonschema:"description=Base URL of an OpenAI-compatible embeddings API,format=uri,example=http://localhost:11434/v1"`
+	APIKey   string `json:"api_key,omitempty" jsonschema:"description=API key for the embeddings API,example=$OPENAI_API_KEY"`
+}
+
+func (t ToolSemanticSearch) Enabled() bool {
+	return t.Model != ""
 }
 
 type ToolLs struct {
@@ -389,6 +688,12 @@ type Config struct {
 
 	Tools Tools `json:"tools,omitzero" jsonschema:"description=Tool configurations"`
 
+	Hooks Hooks `json:"hooks,omitzero" jsonschema:"description=Commands run on events of the agent"`
+
+	SubAgents map[string]SubAgent `json:"agents,omitempty" jsonschema:"description=Named sub-agents the main agent can delegate tasks to"`
+
+	Jobs map[string]JobProfile `json:"jobs,omitempty" jsonschema:"description=Named commands started as background jobs from the commands"`
+
 	Agents map[string]Agent `json:"-"`
 
 	// Internal
@@ -397,6 +702,14 @@ type Config struct {
 	resolver       VariableResolver
 	dataConfigDir  string             `json:"-"`
 	knownProviders []catwalk.Provider `json:"-"`
+	layers         []Layer            `json:"-"`
+	profile        string             `json:"-"`
+	// activeRoot is the root the agent worked in last, and extraRoots the
+	// roots added on the command line.
+	activeRoot *atomic.Pointer[string] `json:"-"`
+	extraRoots []string                `json:"-"`
+	// remoteFlag is the remote workspace given on the command line.
+	remoteFlag *Remote `json:"-"`
 }
 
 func (c *Config) WorkingDir() string {
@@ -472,6 +785,48 @@ func (c *Config) SetCompactMode(enabled bool) error {
 	return c.SetConfigField("options.tui.compact_mode", enabled)
 }
 
+// SetTheme switches the color theme and persists the change.
+func (c *Config) SetTheme(name string) error {
+	c.Options.TUI.Theme = name
+	return c.SetConfigField("options.tui.theme", name)
+}
+
+// SetEditor sets the command editing prompts and persists the change.
+func (c *Config) SetEditor(editor string) error {
+	c.Options.TUI.Editor = editor
+	return c.SetConfigField("options.tui.editor", editor)
+}
+
+// SetMCPDisabled enables or disables the given MCP server and persists the
+// change.
+func (c *Config) SetMCPDisabled(name string, disabled bool) error {
+	m, ok := c.MCP[name]
+	if !ok {
+		return fmt.Errorf("mcp %q not configured", name)
+	}
+	m.Disabled = disabled
+	c.MCP[name] = m
+	return c.SetConfigField(fmt.Sprintf("mcp.%s.disabled", escapeConfigKey(name)), disabled)
+}
+
+// AddMCP adds a new MCP server and persists it.
+func (c *Config) AddMCP(name string, m MCPConfig) error {
+	if _, ok := c.MCP[name]; ok {
+		return fmt.Errorf("mcp %q already configured", name)
+	}
+	if c.MCP == nil {
+		c.MCP = make(MCPs)
+	}
+	c.MCP[name] = m
+	return c.SetConfigField("mcp."+escapeConfigKey(name), m)
+}
+
+// escapeConfigKey escapes characters that have a special meaning in config
+// field paths.
+func escapeConfigKey(key string) string {
+	return strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`).Replace(key)
+}
+
 func (c *Config) Resolve(key string) (string, error) {
 	if c.resolver == nil {
 		return "", fmt.Errorf("no variable resolver configured")
@@ -521,6 +876,25 @@ func (c *Config) SetConfigField(key string, value any) error {
 	return nil
 }
 
+// RemoveConfigField removes key from the configuration Crush writes to.
+func (c *Config) RemoveConfigField(key string) error {
+	data, err := os.ReadFile(c.dataConfigDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	newValue, err := sjson.Delete(string(data), key)
+	if err != nil {
+		return fmt.Errorf("failed to remove config field %s: %w", key, err)
+	}
+	if err := os.WriteFile(c.dataConfigDir, []byte(newValue), 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
 // RefreshOAuthToken refreshes the OAuth token for the given provider.
 func (c *Config) RefreshOAuthToken(ctx context.Context, providerID string) error {
 	providerConfig, exists := c.Providers.Get(providerID)
@@ -578,10 +952,13 @@ func (c *Config) SetProviderAPIKey(providerID string, apiKey any) error {
 
 	switch v := apiKey.(type) {
 	case string:
-		if err := c.SetConfigField(fmt.Sprintf("providers.%s.api_key", providerID), v); err != nil {
-			return fmt.Errorf("failed to save api key to config file: %w", err)
+		if err := c.storeAPIKey(providerID, v); err != nil {
+			return err
+		}
+		setKeyOrToken = func() {
+			providerConfig.APIKey = v
+			providerConfig.APIKeyTemplate = CredentialPrefix + providerID
 		}
-		setKeyOrToken = func() { providerConfig.APIKey = v }
 	case *oauth.Token:
 		if err := cmp.Or(
 			c.SetConfigField(fmt.Sprintf("providers.%s.api_key", providerID), v.AccessToken),
@@ -686,9 +1063,12 @@ func allToolNames() []string {
 		"bash",
 		"job_output",
 		"job_kill",
+		"job_input",
 		"download",
 		"edit",
 		"multiedit",
+		"patch",
+		"replace",
 		"lsp_diagnostics",
 		"lsp_references",
 		"fetch",
@@ -696,9 +1076,14 @@ func allToolNames() []string {
 		"glob",
 		"grep",
 		"ls",
+		"plan",
+		"semantic_search",
 		"sourcegraph",
+		"symbols",
 		"todos",
+		"tool_output",
 		"view",
+		"web_search",
 		"write",
 	}
 }
@@ -712,7 +1097,7 @@ func resolveAllowedTools(allTools []string, disabledTools []string) []string {
 }
 
 func resolveReadOnlyTools(tools []string) []string {
-	readOnlyTools := []string{"glob", "grep", "ls", "sourcegraph", "view"}
+	readOnlyTools := []string{"glob", "grep", "ls", "semantic_search", "sourcegraph", "symbols", "tool_output", "view"}
 	// filter to only include tools that are in allowedtools (include mode)
 	return filterSlice(tools, readOnlyTools, true)
 }
@@ -753,9 +1138,66 @@ func (c *Config) SetupAgents() {
 			AllowedMCP: map[string][]string{},
 		},
 	}
+
+	for name, sub := range c.SubAgents {
+		if sub.Disabled {
+			continue
+		}
+		if _, ok := agents[name]; ok {
+			slog.Warn("Sub-agent name conflicts with a built-in agent, ignoring", "agent", name)
+			continue
+		}
+		allowedMCP := sub.AllowedMCP
+		if allowedMCP == nil {
+			allowedMCP = map[string][]string{}
+		}
+		agents[name] = Agent{
+			ID:           name,
+			Name:         name,
+			Description:  sub.Description,
+			Model:        cmp.Or(sub.Model, SelectedModelTypeLarge),
+			ContextPaths: c.Options.ContextPaths,
+			AllowedTools: resolveSubAgentTools(allowedTools, sub.AllowedTools),
+			AllowedMCP:   allowedMCP,
+			Prompt:       sub.Prompt,
+		}
+	}
 	c.Agents = agents
 }
 
+// JobNames returns the sorted names of the job profiles.
+func (c *Config) JobNames() []string {
+	return slices.Sorted(maps.Keys(c.Jobs))
+}
+
+// SubAgentNames returns the sorted names of the enabled user-defined
+// sub-agents.
+func (c *Config) SubAgentNames() []string {
+	var names []string
+	for name := range c.SubAgents {
+		if name == AgentCoder || name == AgentTask {
+			continue
+		}
+		if agent, ok := c.Agents[name]; ok && !agent.Disabled {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func resolveSubAgentTools(allowedTools []string, requested []string) []string {
+	if requested == nil {
+		return resolveReadOnlyTools(allowedTools)
+	}
+	// Sub-agents can't delegate any further, so the agent tool is never
+	// available to them.
+	requested = slices.DeleteFunc(slices.Clone(requested), func(name string) bool {
+		return name == "agent"
+	})
+	return filterSlice(allowedTools, requested, true)
+}
+
 func (c *Config) Resolver() VariableResolver {
 	return c.resolver
 }
@@ -812,6 +1254,7 @@ func (c *ProviderConfig) TestConnection(resolver VariableResolver) error {
 	if err != nil {
 		return fmt.Errorf("failed to create request for provider %s: %w", c.ID, err)
 	}
+	defer b.Body.Close()
 	if c.ID == string(catwalk.InferenceProviderZAI) {
 		if b.StatusCode == http.StatusUnauthorized {
 			// for z.ai just check if the http response is not 401
@@ -822,7 +1265,6 @@ func (c *ProviderConfig) TestConnection(resolver VariableResolver) error {
 			return fmt.Errorf("failed to connect to provider %s: %s", c.ID, b.Status)
 		}
 	}
-	_ = b.Body.Close()
 	return nil
 }
 
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/keyring"
+	"github.com/tidwall/gjson"
+)
+
+// CredentialPrefix marks an API key kept in the keychain, such as
+// "keyring:openai", followed by the account it's stored under.
+const CredentialPrefix = "keyring:"
+
+const credentialService = "crush"
+
+var credentialStore = sync.OnceValue(func() keyring.Keyring {
+	fallback := filepath.Join(filepath.Dir(GlobalConfigData()), "credentials.json")
+	return keyring.New(credentialService, fallback)
+})
+
+// CredentialStore returns the keychain holding the API keys of providers.
+func CredentialStore() keyring.Keyring {
+	return credentialStore()
+}
+
+// CredentialSource is where the API key of a provider comes from.
+type CredentialSource string
+
+const (
+	CredentialNone      CredentialSource = "none"
+	CredentialKeychain  CredentialSource = "keychain"
+	CredentialPlaintext CredentialSource = "plaintext"
+	CredentialVariable  CredentialSource = "variable"
+	CredentialOAuth     CredentialSource = "oauth"
+)
+
+// Credential tells where the API key of a provider comes from.
+type Credential struct {
+	Provider string
+	Name     string
+	Source   CredentialSource
+	// Migratable is whether the key is stored as is in the configuration
+	// Crush writes to, and can be moved to the keychain.
+	Migratable bool
+}
+
+// Credentials returns where the API keys of the configured providers come
+// from, by provider.
+func (c *Config) Credentials() []Credential {
+	stored := c.storedAPIKeys()
+	var credentials []Credential
+	for id, provider := range c.Providers.Seq2() {
+		template := cmp.Or(provider.APIKeyTemplate, provider.APIKey)
+		credential := Credential{
+			Provider: id,
+			Name:     cmp.Or(provider.Name, id),
+			Source:   credentialSource(template),
+		}
+		if provider.OAuthToken != nil {
+			credential.Source = CredentialOAuth
+		}
+		credential.Migratable = credential.Source == CredentialPlaintext && stored[id] != ""
+		credentials = append(credentials, credential)
+	}
+	slices.SortFunc(credentials, func(a, b Credential) int {
+		return cmp.Compare(a.Provider, b.Provider)
+	})
+	return credentials
+}
+
+func credentialSource(template string) CredentialSource {
+	switch {
+	case template == "":
+		return CredentialNone
+	case strings.HasPrefix(template, CredentialPrefix):
+		return CredentialKeychain
+	case strings.Contains(template, "$"):
+		return CredentialVariable
+	default:
+		return CredentialPlaintext
+	}
+}
+
+// storedAPIKeys returns the API keys of the configuration Crush writes to,
+// by provider, except those of OAuth providers, which are refreshed.
+func (c *Config) storedAPIKeys() map[string]string {
+	keys := make(map[string]string)
+	data, err := os.ReadFile(c.dataConfigDir)
+	if err != nil {
+		return keys
+	}
+	gjson.GetBytes(data, "providers").ForEach(func(id, provider gjson.Result) bool {
+		if apiKey := provider.Get("api_key"); apiKey.Type == gjson.String && !provider.Get("oauth").Exists() {
+			keys[id.String()] = apiKey.String()
+		}
+		return true
+	})
+	return keys
+}
+
+// storeAPIKey keeps the API key of the provider in the keychain, with a
+// reference to it in the configuration.
+func (c *Config) storeAPIKey(providerID, apiKey string) error {
+	if err := CredentialStore().Set(providerID, apiKey); err != nil {
+		return fmt.Errorf("failed to save api key to keychain: %w", err)
+	}
+	if err := c.SetConfigField(fmt.Sprintf("providers.%s.api_key", providerID), CredentialPrefix+providerID); err != nil {
+		return fmt.Errorf("failed to save api key to config file: %w", err)
+	}
+	return nil
+}
+
+// MigrateCredentials moves the API keys stored as is in the configuration
+// Crush writes to into the keychain, and returns the providers moved.
+func (c *Config) MigrateCredentials() ([]string, error) {
+	var migrated []string
+	for id, apiKey := range c.storedAPIKeys() {
+		if credentialSource(apiKey) != CredentialPlaintext {
+			continue
+		}
+		if err := c.storeAPIKey(id, apiKey); err != nil {
+			return migrated, err
+		}
+		if provider, ok := c.Providers.Get(id); ok {
+			provider.APIKeyTemplate = CredentialPrefix + id
+			c.Providers.Set(id, provider)
+		}
+		migrated = append(migrated, id)
+	}
+	slices.Sort(migrated)
+	return migrated, nil
+}
+
+// ForgetAPIKey removes the API key of the provider from the keychain and
+// from the configuration Crush writes to.
+func (c *Config) ForgetAPIKey(providerID string) error {
+	if err := CredentialStore().Delete(providerID); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	if err := c.RemoveConfigField(fmt.Sprintf("providers.%s.api_key", providerID)); err != nil {
+		return err
+	}
+	if provider, ok := c.Providers.Get(providerID); ok {
+		provider.APIKey = ""
+		provider.APIKeyTemplate = ""
+		c.Providers.Set(providerID, provider)
+	}
+	return nil
+}
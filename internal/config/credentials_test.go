@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/env"
+	"github.com/charmbracelet/crush/internal/keyring"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCredential(t *testing.T) {
+	t.Parallel()
+
+	store := keyring.NewFile(filepath.Join(t.TempDir(), "credentials.json"))
+	require.NoError(t, store.Set("openai", "sk-secret"))
+	r := &shellVariableResolver{
+		shell:       &mockShell{},
+		env:         env.NewFromMap(nil),
+		credentials: func() keyring.Keyring { return store },
+	}
+
+	value, err := r.ResolveValue(CredentialPrefix + "openai")
+	require.NoError(t, err)
+	require.Equal(t, "sk-secret", value)
+
+	_, err = r.ResolveValue(CredentialPrefix + "anthropic")
+	require.Error(t, err)
+}
+
+func TestCredentials(t *testing.T) {
+	t.Parallel()
+
+	dataConfig := filepath.Join(t.TempDir(), "crush.json")
+	require.NoError(t, os.WriteFile(dataConfig, []byte(`{"providers": {
+		"openai": {"api_key": "sk-plaintext"},
+		"copilot": {"api_key": "token", "oauth": {"access_token": "token"}}
+	}}`), 0o600))
+
+	c := &Config{dataConfigDir: dataConfig, Providers: csync.NewMap[string, ProviderConfig]()}
+	c.Providers.Set("openai", ProviderConfig{ID: "openai", Name: "OpenAI", APIKey: "sk-plaintext"})
+	c.Providers.Set("anthropic", ProviderConfig{ID: "anthropic", APIKey: "$ANTHROPIC_API_KEY"})
+	c.Providers.Set("groq", ProviderConfig{ID: "groq", APIKey: "gsk-secret", APIKeyTemplate: CredentialPrefix + "groq"})
+	c.Providers.Set("ollama", ProviderConfig{ID: "ollama"})
+
+	require.Equal(t, []Credential{
+		{Provider: "anthropic", Name: "anthropic", Source: CredentialVariable},
+		{Provider: "groq", Name: "groq", Source: CredentialKeychain},
+		{Provider: "ollama", Name: "ollama", Source: CredentialNone},
+		{Provider: "openai", Name: "OpenAI", Source: CredentialPlaintext, Migratable: true},
+	}, c.Credentials())
+	require.Equal(t, map[string]string{"openai": "sk-plaintext"}, c.storedAPIKeys())
+}
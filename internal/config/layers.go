@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ProfileEnv names the profile to load on top of the global configuration.
+const ProfileEnv = "CRUSH_PROFILE"
+
+// projectOverride is the file in the .crush directory of a project
+// overriding everything else.
+const projectOverride = "config.json"
+
+// Layer is a configuration file merged into the configuration. Layers are
+// merged in order, the later ones overriding the earlier ones.
+type Layer struct {
+	// Name tells what the file is for, such as "global" or "profile work".
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Exists reports whether the file of the layer is there.
+func (l Layer) Exists() bool {
+	_, err := os.Stat(l.Path)
+	return err == nil
+}
+
+// ActiveProfile returns the profile selected with CRUSH_PROFILE, if any.
+func ActiveProfile() string {
+	return os.Getenv(ProfileEnv)
+}
+
+// ProfilesDir returns the directory holding the profiles, next to the
+// global configuration.
+func ProfilesDir() string {
+	return filepath.Join(filepath.Dir(GlobalConfig()), "profiles")
+}
+
+// ProfilePath returns the file of the named profile.
+func ProfilePath(name string) string {
+	return filepath.Join(ProfilesDir(), name+".json")
+}
+
+// Profiles returns the names of the available profiles.
+func Profiles() []string {
+	entries, err := os.ReadDir(ProfilesDir())
+	if err != nil {
+		return nil
+	}
+	var profiles []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			profiles = append(profiles, name)
+		}
+	}
+	slices.Sort(profiles)
+	return profiles
+}
+
+// configLayers returns the files making up the configuration of cwd: the
+// global configuration, the one Crush writes to, the profile, the crush.json
+// files from the root of the filesystem down to cwd, and the config.json of
+// the closest .crush directory.
+func configLayers(cwd, profile string) []Layer {
+	layers := []Layer{
+		{Name: "global", Path: GlobalConfig()},
+		{Name: "global data", Path: GlobalConfigData()},
+	}
+	if profile != "" {
+		layers = append(layers, Layer{Name: "profile " + profile, Path: ProfilePath(profile)})
+	}
+
+	configNames := []string{appName + ".json", "." + appName + ".json"}
+	foundConfigs, err := fsext.Lookup(cwd, configNames...)
+	if err == nil {
+		// Reverse order so last config has more priority.
+		slices.Reverse(foundConfigs)
+		for _, path := range foundConfigs {
+			layers = append(layers, Layer{Name: "project", Path: path})
+		}
+	}
+
+	if dir, ok := fsext.LookupClosest(cwd, defaultDataDirectory); ok {
+		layers = append(layers, Layer{Name: "project override", Path: filepath.Join(dir, projectOverride)})
+	}
+	return layers
+}
+
+// Layers returns the files the configuration was merged from, whether they
+// exist or not.
+func (c *Config) Layers() []Layer {
+	return c.layers
+}
+
+// Profile returns the name of the profile loaded, if any.
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// Origin is a setting of the configuration files and the layers setting it.
+type Origin struct {
+	// Key is the dotted path of the setting.
+	Key string `json:"key"`
+	// Value is the merged value, as JSON. API keys and tokens are masked.
+	Value string `json:"value"`
+	// Layers are the names of the layers setting it, the last one winning.
+	// Lists are concatenated instead, so every layer contributes.
+	Layers []string `json:"layers"`
+}
+
+// Origins returns the settings of the configuration files, with the layers
+// they come from, sorted by key. Settings Crush fills in by itself aren't
+// listed.
+func (c *Config) Origins() ([]Origin, error) {
+	return layerOrigins(c.layers)
+}
+
+func layerOrigins(layers []Layer) ([]Origin, error) {
+	var (
+		readers []io.Reader
+		keys    []string
+		origins = make(map[string]*Origin)
+		paths   = make(map[string]string)
+	)
+	for _, layer := range layers {
+		data, err := os.ReadFile(layer.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", layer.Path, err)
+		}
+		if !gjson.ValidBytes(data) {
+			return nil, fmt.Errorf("invalid JSON in config file %s", layer.Path)
+		}
+		readers = append(readers, bytes.NewReader(data))
+		flatten(gjson.ParseBytes(data), "", "", func(key, path string) {
+			origin, ok := origins[key]
+			if !ok {
+				origin = &Origin{Key: key}
+				origins[key] = origin
+				paths[key] = path
+				keys = append(keys, key)
+			}
+			if !slices.Contains(origin.Layers, layer.Name) {
+				origin.Layers = append(origin.Layers, layer.Name)
+			}
+		})
+	}
+	if len(readers) == 0 {
+		return nil, nil
+	}
+
+	merged, err := Merge(readers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge configuration readers: %w", err)
+	}
+	data, err := io.ReadAll(merged)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(keys)
+	result := make([]Origin, 0, len(keys))
+	for _, key := range keys {
+		origin := origins[key]
+		origin.Value = maskValue(key, gjson.GetBytes(data, paths[key]))
+		result = append(result, *origin)
+	}
+	return result, nil
+}
+
+// maskValue returns v as JSON, hiding it when it's an API key or a token
+// written as is rather than a reference to one.
+func maskValue(key string, v gjson.Result) string {
+	if secretKey(key) && v.Type == gjson.String && credentialSource(v.String()) == CredentialPlaintext {
+		return `"********"`
+	}
+	return v.Raw
+}
+
+// MaskSecrets returns the JSON data with the values of its API keys, tokens
+// and secrets hidden, as they are once resolved, e.g. to print it.
+func MaskSecrets(data []byte) ([]byte, error) {
+	var err error
+	flatten(gjson.ParseBytes(data), "", "", func(key, path string) {
+		if err != nil || !secretKey(key) {
+			return
+		}
+		if v := gjson.GetBytes(data, path); v.Type == gjson.String && v.String() != "" {
+			data, err = sjson.SetBytes(data, path, "********")
+		}
+	})
+	return data, err
+}
+
+// secretKey reports whether the setting at the dotted key holds a secret,
+// such as an API key, a token or an authorization header.
+func secretKey(key string) bool {
+	name := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	return name == "api_key" || name == "authorization" || strings.HasSuffix(name, "token") || strings.Contains(name, "secret")
+}
+
+// flatten calls fn with the dotted key and the gjson path of every value of
+// v that isn't an object.
+func flatten(v gjson.Result, key, path string, fn func(key, path string)) {
+	if !v.IsObject() {
+		if key != "" {
+			fn(key, path)
+		}
+		return
+	}
+	v.ForEach(func(k, child gjson.Result) bool {
+		name := k.String()
+		flatten(child, joinKey(key, name), joinKey(path, escapePath(name)), fn)
+		return true
+	})
+}
+
+func joinKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// escapePath escapes the characters gjson gives a meaning to in a key.
+func escapePath(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`.*?|#@!\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/oauth"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestLayerOrigins(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) Layer {
+		path := filepath.Join(dir, name+".json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return Layer{Name: name, Path: path}
+	}
+	layers := []Layer{
+		write("global", `{"options": {"tui": {"compact_mode": true}}, "providers": {"openai": {"api_key": "sk-plaintext"}}}`),
+		{Name: "global data", Path: filepath.Join(dir, "missing.json")},
+		write("profile work", `{"options": {"tui": {"compact_mode": false}}, "providers": {"anthropic": {"api_key": "$ANTHROPIC_API_KEY"}}}`),
+		write("project", `{"options": {"context_paths": ["a.md"]}, "mcp": {"docs.internal": {"url": "https://docs"}}}`),
+		write("project override", `{"options": {"context_paths": ["b.md"]}}`),
+	}
+
+	origins, err := layerOrigins(layers)
+	require.NoError(t, err)
+	require.Equal(t, []Origin{
+		{Key: "mcp.docs.internal.url", Value: `"https://docs"`, Layers: []string{"project"}},
+		{Key: "options.context_paths", Value: `["a.md","b.md"]`, Layers: []string{"project", "project override"}},
+		{Key: "options.tui.compact_mode", Value: "false", Layers: []string{"global", "profile work"}},
+		{Key: "providers.anthropic.api_key", Value: `"$ANTHROPIC_API_KEY"`, Layers: []string{"profile work"}},
+		{Key: "providers.openai.api_key", Value: `"********"`, Layers: []string{"global"}},
+	}, origins)
+
+	_, err = layerOrigins([]Layer{write("broken", `{"options": `)})
+	require.Error(t, err)
+}
+
+func TestConfigLayers(t *testing.T) {
+	t.Setenv("CRUSH_GLOBAL_CONFIG", t.TempDir())
+	t.Setenv("CRUSH_GLOBAL_DATA", t.TempDir())
+
+	project := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(project, defaultDataDirectory), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "crush.json"), []byte(`{}`), 0o644))
+
+	var names []string
+	for _, layer := range configLayers(project, "work") {
+		names = append(names, layer.Name)
+	}
+	require.Equal(t, []string{"global", "global data", "profile work", "project", "project override"}, names)
+}
+
+func TestLoadProfile(t *testing.T) {
+	global := t.TempDir()
+	t.Setenv("CRUSH_GLOBAL_CONFIG", global)
+	t.Setenv("CRUSH_GLOBAL_DATA", t.TempDir())
+	require.NoError(t, os.Mkdir(filepath.Join(global, "profiles"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(global, "crush.json"), []byte(`{"options": {"tui": {"compact_mode": true}}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(global, "profiles", "work.json"), []byte(`{"options": {"tui": {"compact_mode": false}}}`), 0o644))
+	require.Equal(t, []string{"work"}, Profiles())
+
+	t.Setenv(ProfileEnv, "work")
+	cfg, err := Load(t.TempDir(), t.TempDir(), false)
+	require.NoError(t, err)
+	require.Equal(t, "work", cfg.Profile())
+	require.False(t, cfg.Options.TUI.CompactMode)
+
+	t.Setenv(ProfileEnv, "personal")
+	_, err = Load(t.TempDir(), t.TempDir(), false)
+	require.Error(t, err)
+}
+
+func TestMaskSecrets(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Providers: csync.NewMapFrom(map[string]ProviderConfig{
+			"anthropic": {
+				ID:         "anthropic",
+				APIKey:     "sk-ant-configured-key",
+				OAuthToken: &oauth.Token{AccessToken: "access-secret", RefreshToken: "refresh-secret"},
+			},
+		}),
+		MCP: MCPs{"docs": {URL: "https://docs", Headers: map[string]string{"Authorization": "Bearer header-secret"}}},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "sk-ant-configured-key")
+
+	masked, err := MaskSecrets(data)
+	require.NoError(t, err)
+	for _, secret := range []string{"sk-ant-configured-key", "access-secret", "refresh-secret", "header-secret"} {
+		require.NotContains(t, string(masked), secret)
+	}
+	require.Equal(t, "https://docs", gjson.GetBytes(masked, "mcp.docs.url").String(), "other settings are left alone")
+	require.Equal(t, "********", gjson.GetBytes(masked, "providers.anthropic.api_key").String())
+}
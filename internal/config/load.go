@@ -24,6 +24,7 @@ import (
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/home"
 	"github.com/charmbracelet/crush/internal/log"
+	"github.com/charmbracelet/crush/internal/ollama"
 	powernapConfig "github.com/charmbracelet/x/powernap/pkg/config"
 )
 
@@ -46,7 +47,17 @@ func LoadReader(fd io.Reader) (*Config, error) {
 
 // Load loads the configuration from the default paths.
 func Load(workingDir, dataDir string, debug bool) (*Config, error) {
-	configPaths := lookupConfigs(workingDir)
+	profile := ActiveProfile()
+	if profile != "" {
+		if _, err := os.Stat(ProfilePath(profile)); err != nil {
+			return nil, fmt.Errorf("profile %q not found in %s", profile, ProfilesDir())
+		}
+	}
+	layers := configLayers(workingDir, profile)
+	configPaths := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		configPaths = append(configPaths, layer.Path)
+	}
 
 	cfg, err := loadFromConfigPaths(configPaths)
 	if err != nil {
@@ -54,6 +65,8 @@ func Load(workingDir, dataDir string, debug bool) (*Config, error) {
 	}
 
 	cfg.dataConfigDir = GlobalConfigData()
+	cfg.layers = layers
+	cfg.profile = profile
 
 	cfg.setDefaults(workingDir, dataDir)
 
@@ -196,6 +209,8 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			SystemPromptPrefix: config.SystemPromptPrefix,
 			ExtraHeaders:       headers,
 			ExtraBody:          config.ExtraBody,
+			RequestTransform:   config.RequestTransform,
+			ResponseTransform:  config.ResponseTransform,
 			ExtraParams:        make(map[string]string),
 			Models:             p.Models,
 		}
@@ -292,11 +307,19 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 		if providerConfig.APIKey == "" {
 			slog.Warn("Provider is missing API key, this might be OK for local providers", "provider", id)
 		}
+		if providerConfig.BaseURL == "" && id == OllamaProviderID {
+			providerConfig.BaseURL = ollama.DefaultBaseURL + "/v1"
+		}
 		if providerConfig.BaseURL == "" {
 			slog.Warn("Skipping custom provider due to missing API endpoint", "provider", id)
 			c.Providers.Del(id)
 			continue
 		}
+		if providerConfig.IsOllama() {
+			if baseURL, err := resolver.ResolveValue(providerConfig.BaseURL); err == nil {
+				providerConfig.discoverOllamaModels(baseURL)
+			}
+		}
 		if len(providerConfig.Models) == 0 {
 			slog.Warn("Skipping custom provider because the provider has no models", "provider", id)
 			c.Providers.Del(id)
@@ -357,6 +380,8 @@ func (c *Config) setDefaults(workingDir, dataDir string) {
 	// Apply defaults to LSP configurations
 	c.applyLSPDefaults()
 
+	c.setupRoots()
+
 	// Add the default context paths if they are not already present
 	c.Options.ContextPaths = append(defaultContextPaths, c.Options.ContextPaths...)
 	slices.Sort(c.Options.ContextPaths)
@@ -366,6 +391,13 @@ func (c *Config) setDefaults(workingDir, dataDir string) {
 		c.Options.DisableProviderAutoUpdate, _ = strconv.ParseBool(str)
 	}
 
+	if str, ok := os.LookupEnv("CRUSH_ACCESSIBLE"); ok {
+		c.Options.TUI.Accessibility.Enabled, _ = strconv.ParseBool(str)
+	}
+	if c.Options.TUI.Accessibility.Enabled && c.Options.TUI.Accessibility.Log == "" {
+		c.Options.TUI.Accessibility.Log = filepath.Join(c.Options.DataDirectory, "accessibility.log")
+	}
+
 	if c.Options.Attribution == nil {
 		c.Options.Attribution = &Attribution{
 			TrailerStyle:  TrailerStyleAssistedBy,
@@ -383,6 +415,15 @@ func (c *Config) setDefaults(workingDir, dataDir string) {
 			c.Options.Attribution.TrailerStyle = TrailerStyleAssistedBy
 		}
 	}
+	if c.Options.Commit == nil {
+		c.Options.Commit = &CommitOptions{}
+	}
+	if c.Options.Commit.MaxSubjectLength == 0 {
+		c.Options.Commit.MaxSubjectLength = defaultCommitSubjectLength
+	}
+	if c.Options.Commit.Conventional && len(c.Options.Commit.Types) == 0 {
+		c.Options.Commit.Types = defaultCommitTypes
+	}
 	if c.Options.InitializeAs == "" {
 		c.Options.InitializeAs = defaultInitializeAs
 	}
@@ -598,28 +639,6 @@ func (c *Config) configureSelectedModels(knownProviders []catwalk.Provider) erro
 	return nil
 }
 
-// lookupConfigs searches config files recursively from CWD up to FS root
-func lookupConfigs(cwd string) []string {
-	// prepend default config paths
-	configPaths := []string{
-		GlobalConfig(),
-		GlobalConfigData(),
-	}
-
-	configNames := []string{appName + ".json", "." + appName + ".json"}
-
-	foundConfigs, err := fsext.Lookup(cwd, configNames...)
-	if err != nil {
-		// returns at least default configs
-		return configPaths
-	}
-
-	// reverse order so last config has more priority
-	slices.Reverse(foundConfigs)
-
-	return append(configPaths, foundConfigs...)
-}
-
 func loadFromConfigPaths(configPaths []string) (*Config, error) {
 	var configs []io.Reader
 
@@ -3,6 +3,8 @@ package config
 import (
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -469,7 +471,7 @@ func TestConfig_setupAgentsWithNoDisabledTools(t *testing.T) {
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
-	assert.Equal(t, []string{"glob", "grep", "ls", "sourcegraph", "view"}, taskAgent.AllowedTools)
+	assert.Equal(t, []string{"glob", "grep", "ls", "semantic_search", "sourcegraph", "symbols", "tool_output", "view"}, taskAgent.AllowedTools)
 }
 
 func TestConfig_setupAgentsWithDisabledTools(t *testing.T) {
@@ -487,11 +489,11 @@ func TestConfig_setupAgentsWithDisabledTools(t *testing.T) {
 	coderAgent, ok := cfg.Agents[AgentCoder]
 	require.True(t, ok)
 
-	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "multiedit", "lsp_diagnostics", "lsp_references", "fetch", "agentic_fetch", "glob", "ls", "sourcegraph", "todos", "view", "write"}, coderAgent.AllowedTools)
+	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "job_input", "multiedit", "patch", "replace", "lsp_diagnostics", "lsp_references", "fetch", "agentic_fetch", "glob", "ls", "plan", "semantic_search", "sourcegraph", "symbols", "todos", "tool_output", "view", "web_search", "write"}, coderAgent.AllowedTools)
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
-	assert.Equal(t, []string{"glob", "ls", "sourcegraph", "view"}, taskAgent.AllowedTools)
+	assert.Equal(t, []string{"glob", "ls", "semantic_search", "sourcegraph", "symbols", "tool_output", "view"}, taskAgent.AllowedTools)
 }
 
 func TestConfig_setupAgentsWithEveryReadOnlyToolDisabled(t *testing.T) {
@@ -501,7 +503,10 @@ func TestConfig_setupAgentsWithEveryReadOnlyToolDisabled(t *testing.T) {
 				"glob",
 				"grep",
 				"ls",
+				"semantic_search",
 				"sourcegraph",
+				"symbols",
+				"tool_output",
 				"view",
 			},
 		},
@@ -510,7 +515,7 @@ func TestConfig_setupAgentsWithEveryReadOnlyToolDisabled(t *testing.T) {
 	cfg.SetupAgents()
 	coderAgent, ok := cfg.Agents[AgentCoder]
 	require.True(t, ok)
-	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "download", "edit", "multiedit", "lsp_diagnostics", "lsp_references", "fetch", "agentic_fetch", "todos", "write"}, coderAgent.AllowedTools)
+	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "job_input", "download", "edit", "multiedit", "patch", "replace", "lsp_diagnostics", "lsp_references", "fetch", "agentic_fetch", "plan", "todos", "web_search", "write"}, coderAgent.AllowedTools)
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
@@ -1248,3 +1253,87 @@ func TestConfig_configureSelectedModels(t *testing.T) {
 		require.Equal(t, int64(100), large.MaxTokens)
 	})
 }
+
+func TestConfig_setupAgentsWithSubAgents(t *testing.T) {
+	cfg := &Config{
+		Options: &Options{
+			DisabledTools: []string{"bash"},
+		},
+		SubAgents: map[string]SubAgent{
+			"reviewer": {
+				Description:  "Reviews code",
+				Prompt:       "You review code.",
+				Model:        SelectedModelTypeSmall,
+				AllowedTools: []string{"agent", "bash", "grep", "view"},
+			},
+			"explorer": {
+				Description: "Explores the codebase",
+			},
+			"disabled": {
+				Description: "Never used",
+				Disabled:    true,
+			},
+			AgentCoder: {
+				Description: "Conflicts with a built-in agent",
+			},
+		},
+	}
+
+	cfg.SetupAgents()
+
+	reviewer, ok := cfg.Agents["reviewer"]
+	require.True(t, ok)
+	assert.Equal(t, SelectedModelTypeSmall, reviewer.Model)
+	assert.Equal(t, "You review code.", reviewer.Prompt)
+	assert.Equal(t, []string{"grep", "view"}, reviewer.AllowedTools)
+	assert.Equal(t, map[string][]string{}, reviewer.AllowedMCP)
+
+	explorer, ok := cfg.Agents["explorer"]
+	require.True(t, ok)
+	assert.Equal(t, SelectedModelTypeLarge, explorer.Model)
+	assert.Equal(t, []string{"glob", "grep", "ls", "semantic_search", "sourcegraph", "symbols", "tool_output", "view"}, explorer.AllowedTools)
+
+	_, ok = cfg.Agents["disabled"]
+	require.False(t, ok)
+	assert.Equal(t, "Coder", cfg.Agents[AgentCoder].Name)
+
+	assert.Equal(t, []string{"explorer", "reviewer"}, cfg.SubAgentNames())
+}
+
+func TestConfig_configureProvidersDiscoversOllamaModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"qwen3:8b"},{"name":"gemma:2b"}]}`))
+		case "/api/show":
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), "qwen3") {
+				_, _ = w.Write([]byte(`{"capabilities":["completion","tools"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"capabilities":["completion"]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &Config{
+		Providers: csync.NewMapFrom(map[string]ProviderConfig{
+			"ollama": {
+				BaseURL: srv.URL + "/v1",
+				Models:  []catwalk.Model{{ID: "qwen3:8b", Name: "Qwen 3"}},
+			},
+		}),
+	}
+	cfg.setDefaults("/tmp", "")
+	env := env.NewFromMap(map[string]string{})
+	err := cfg.configureProviders(env, NewEnvironmentVariableResolver(env), nil)
+	require.NoError(t, err)
+
+	pc, ok := cfg.Providers.Get("ollama")
+	require.True(t, ok)
+	require.Len(t, pc.Models, 2)
+	require.Equal(t, "Qwen 3", pc.Models[0].Name, "configured models are kept")
+	require.Equal(t, "gemma:2b", pc.Models[1].ID)
+	require.True(t, pc.SupportsTools("qwen3:8b"))
+	require.False(t, pc.SupportsTools("gemma:2b"))
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddMCP_Persists(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg := &Config{}
+	cfg.setDefaults(dir, "")
+	cfg.dataConfigDir = filepath.Join(dir, "config.json")
+
+	require.NoError(t, cfg.AddMCP("docs.server", MCPConfig{
+		Type:    MCPStdio,
+		Command: "docs-mcp",
+		Args:    []string{"--stdio"},
+	}))
+	require.Error(t, cfg.AddMCP("docs.server", MCPConfig{Type: MCPStdio}))
+
+	require.NoError(t, cfg.SetMCPDisabled("docs.server", true))
+	require.True(t, cfg.MCP["docs.server"].Disabled)
+
+	mcps, ok := readConfigJSON(t, cfg.dataConfigDir)["mcp"].(map[string]any)
+	require.True(t, ok)
+	server, ok := mcps["docs.server"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "docs-mcp", server["command"])
+	require.Equal(t, true, server["disabled"])
+
+	require.Error(t, cfg.SetMCPDisabled("missing", true))
+}
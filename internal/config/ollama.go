@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/ollama"
+)
+
+// OllamaProviderID is the ID of the provider of the local Ollama models.
+const OllamaProviderID = "ollama"
+
+// ollamaDiscoveryTimeout bounds asking Ollama for its models while loading
+// the configuration.
+const ollamaDiscoveryTimeout = 2 * time.Second
+
+// IsOllama reports whether the provider is an Ollama server.
+func (c *ProviderConfig) IsOllama() bool {
+	return c.ID == OllamaProviderID || strings.Contains(c.BaseURL, ":11434")
+}
+
+// SupportsTools reports whether model can call tools. Only local models are
+// known not to.
+func (c *ProviderConfig) SupportsTools(model string) bool {
+	return !slices.Contains(c.ToolsUnsupported, model)
+}
+
+// AddOllamaModel adds a model pulled on Ollama to the provider, unless it is
+// already there, and records whether it can call tools.
+func (c *ProviderConfig) AddOllamaModel(model ollama.Model) {
+	if !model.Supports(ollama.CapabilityTools) && !slices.Contains(c.ToolsUnsupported, model.Name) {
+		c.ToolsUnsupported = append(c.ToolsUnsupported, model.Name)
+	}
+	i := slices.IndexFunc(c.Models, func(m catwalk.Model) bool {
+		return m.ID == model.Name
+	})
+	if i < 0 {
+		c.Models = append(c.Models, model.Catwalk())
+		return
+	}
+	if c.Models[i].ContextWindow == 0 {
+		c.Models[i].ContextWindow = model.Catwalk().ContextWindow
+	}
+}
+
+// discoverOllamaModels adds the models pulled on the Ollama server at
+// baseURL to the provider. The configured models are kept as they are when
+// the server can't be reached.
+func (c *ProviderConfig) discoverOllamaModels(baseURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaDiscoveryTimeout)
+	defer cancel()
+	models, err := ollama.New(baseURL).List(ctx)
+	if err != nil {
+		slog.Warn("Couldn't list the local Ollama models", "provider", c.ID, "error", err)
+		return
+	}
+	for _, model := range models {
+		c.AddOllamaModel(model)
+	}
+}
+
+// OllamaProvider returns the provider of the Ollama models, when there is
+// one.
+func (c *Config) OllamaProvider() (ProviderConfig, bool) {
+	if p, ok := c.Providers.Get(OllamaProviderID); ok {
+		return p, true
+	}
+	for p := range c.Providers.Seq() {
+		if p.IsOllama() {
+			return p, true
+		}
+	}
+	return ProviderConfig{}, false
+}
+
+// OllamaBaseURL returns the URL of the Ollama server.
+func (c *Config) OllamaBaseURL() string {
+	p, ok := c.OllamaProvider()
+	if !ok {
+		return ollama.DefaultBaseURL
+	}
+	baseURL, err := c.Resolve(p.BaseURL)
+	if err != nil || baseURL == "" {
+		return ollama.DefaultBaseURL
+	}
+	return baseURL
+}
+
+// AddPulledOllamaModel adds a model that was just pulled to the Ollama
+// provider, so it can be selected. The provider is added, and saved, when
+// there isn't one yet.
+func (c *Config) AddPulledOllamaModel(model ollama.Model) error {
+	p, ok := c.OllamaProvider()
+	if !ok {
+		p = ProviderConfig{
+			ID:      OllamaProviderID,
+			Name:    "Ollama",
+			Type:    catwalk.TypeOpenAICompat,
+			BaseURL: ollama.DefaultBaseURL + "/v1",
+		}
+		if err := c.SetConfigField("providers.ollama.base_url", p.BaseURL); err != nil {
+			return err
+		}
+	}
+	p.AddOllamaModel(model)
+	c.Providers.Set(p.ID, p)
+	return nil
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultReloadInterval is how often the config files are checked for
+// changes.
+const DefaultReloadInterval = 2 * time.Second
+
+// ValidationError is a mistake in a config file, at the line and column it
+// was found. Line is 0 when the mistake is in the merged configuration
+// rather than in one of the files.
+type ValidationError struct {
+	Path    string `json:"path,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	switch {
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Message)
+	case e.Path != "":
+		return fmt.Sprintf("%s: %s", e.Path, e.Message)
+	default:
+		return e.Message
+	}
+}
+
+// ReloadEvent is published when the config files changed, once they were
+// applied or, when Errors isn't empty, left aside.
+type ReloadEvent struct {
+	// Paths are the files that changed.
+	Paths  []string
+	Errors []ValidationError
+	// MCP and LSP are the servers added, changed or removed.
+	MCP []string
+	LSP []string
+}
+
+var reloads = pubsub.NewBroker[ReloadEvent]()
+
+// SubscribeReloads returns a channel receiving when the config files were
+// reloaded.
+func SubscribeReloads(ctx context.Context) <-chan pubsub.Event[ReloadEvent] {
+	return reloads.Subscribe(ctx)
+}
+
+// Validate checks the config files that exist for syntax errors, values of
+// the wrong type and unknown MCP transports.
+func Validate(layers []Layer) []ValidationError {
+	var errs []ValidationError
+	for _, layer := range layers {
+		data, err := os.ReadFile(layer.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, ValidationError{Path: layer.Path, Message: err.Error()})
+			continue
+		}
+		errs = append(errs, validateFile(layer.Path, data)...)
+	}
+	return errs
+}
+
+func validateFile(path string, data []byte) []ValidationError {
+	at := func(offset int, message string) ValidationError {
+		line, column := position(data, offset)
+		return ValidationError{Path: path, Line: line, Column: column, Message: message}
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return []ValidationError{at(int(syntaxErr.Offset)-1, syntaxErr.Error())}
+		case errors.As(err, &typeErr):
+			// The offset is right after the value, point at its start when
+			// it can be found.
+			offset := int(typeErr.Offset) - 1
+			if value := gjson.GetBytes(data, typeErr.Field); value.Exists() {
+				offset = value.Index
+			}
+			return []ValidationError{at(offset, fmt.Sprintf("%s should be a %s, not a %s", typeErr.Field, typeErr.Type, typeErr.Value))}
+		default:
+			return []ValidationError{{Path: path, Message: err.Error()}}
+		}
+	}
+
+	var errs []ValidationError
+	gjson.GetBytes(data, "mcp").ForEach(func(name, server gjson.Result) bool {
+		transport := server.Get("type")
+		switch MCPType(transport.String()) {
+		case MCPStdio, MCPSSE, MCPHttp:
+		default:
+			if transport.Exists() {
+				errs = append(errs, at(transport.Index, fmt.Sprintf("mcp.%s.type should be stdio, sse or http, not %s", name, transport.Raw)))
+			}
+		}
+		return true
+	})
+	return errs
+}
+
+// position returns the line and the column of the byte at offset, both
+// starting at 1.
+func position(data []byte, offset int) (int, int) {
+	offset = max(0, min(offset, len(data)))
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, column
+}
+
+// Reload loads the config files again and applies them in place, so that
+// everything holding the configuration sees the change. When the files
+// have mistakes, they're returned and the configuration is left as is.
+func (c *Config) Reload() []ValidationError {
+	if errs := Validate(configLayers(c.workingDir, c.profile)); len(errs) > 0 {
+		return errs
+	}
+
+	fresh, err := Load(c.workingDir, c.Options.DataDirectory, c.Options.Debug)
+	if err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+	// Skipping permission requests is only ever asked on the command line.
+	if c.Permissions != nil && c.Permissions.SkipRequests {
+		if fresh.Permissions == nil {
+			fresh.Permissions = &Permissions{}
+		}
+		fresh.Permissions.SkipRequests = true
+	}
+	// Neither are the roots added on the command line, and the agent still
+	// works in the same root.
+	if err := fresh.AddRoots(c.extraRoots...); err != nil {
+		slog.Warn("Failed to add the roots of the command line again", "error", err)
+	}
+	fresh.activeRoot = c.activeRoot
+	if c.remoteFlag != nil {
+		fresh.SetRemote(*c.remoteFlag)
+	}
+	*c = *fresh
+	return nil
+}
+
+// changed returns the names of the entries added, changed or removed from
+// before to after.
+func changed[V any](before, after map[string]V) []string {
+	var names []string
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for name, v := range after {
+		if old, ok := before[name]; !ok || !reflect.DeepEqual(old, v) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Watch checks the config files every interval until the context is done,
+// reloading them when they change and publishing a ReloadEvent. The file
+// Crush writes to itself isn't watched, as what it writes is applied
+// already.
+func (c *Config) Watch(ctx context.Context, interval time.Duration) {
+	modTimes := func() map[string]time.Time {
+		times := make(map[string]time.Time)
+		for _, layer := range configLayers(c.workingDir, c.profile) {
+			if layer.Path == c.dataConfigDir {
+				continue
+			}
+			if info, err := os.Stat(layer.Path); err == nil {
+				times[layer.Path] = info.ModTime()
+			}
+		}
+		return times
+	}
+
+	last := modTimes()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := modTimes()
+			paths := changed(last, current)
+			if len(paths) == 0 {
+				continue
+			}
+			last = current
+
+			mcps, lsps := maps.Clone(c.MCP), maps.Clone(c.LSP)
+			errs := c.Reload()
+			event := ReloadEvent{Paths: paths, Errors: errs}
+			if len(errs) == 0 {
+				event.MCP = changed(mcps, c.MCP)
+				event.LSP = changed(lsps, c.LSP)
+			}
+			reloads.Publish(pubsub.UpdatedEvent, event)
+		}
+	}
+}
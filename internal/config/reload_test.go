@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFile(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		input string
+		want  []ValidationError
+	}{
+		"valid": {
+			input: `{"options": {"debug": true}}`,
+		},
+		"syntax": {
+			input: "{\n  \"options\": {\n    \"debug\": true,\n  }\n}",
+			want: []ValidationError{{
+				Path: "crush.json", Line: 4, Column: 3,
+				Message: "invalid character '}' looking for beginning of object key string",
+			}},
+		},
+		"type": {
+			input: "{\n  \"options\": {\"debug\": \"yes\"}\n}",
+			want: []ValidationError{{
+				Path: "crush.json", Line: 2, Column: 24,
+				Message: "options.debug should be a bool, not a string",
+			}},
+		},
+		"mcp type": {
+			input: "{\"mcp\": {\n  \"docs\": {\"type\": \"websocket\"}\n}}",
+			want: []ValidationError{{
+				Path: "crush.json", Line: 2, Column: 20,
+				Message: `mcp.docs.type should be stdio, sse or http, not "websocket"`,
+			}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.want, validateFile("crush.json", []byte(tc.input)))
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	t.Setenv("CRUSH_GLOBAL_CONFIG", t.TempDir())
+	t.Setenv("CRUSH_GLOBAL_DATA", t.TempDir())
+
+	project := t.TempDir()
+	path := filepath.Join(project, "crush.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"options": {"tui": {"compact_mode": true}}}`), 0o644))
+
+	cfg, err := Load(project, t.TempDir(), false)
+	require.NoError(t, err)
+	cfg.Permissions = &Permissions{SkipRequests: true}
+	options := cfg.Options
+	require.True(t, options.TUI.CompactMode)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"options": {"tui": {"compact_mode": "no"}}}`), 0o644))
+	errs := cfg.Reload()
+	require.Len(t, errs, 1)
+	require.Equal(t, path, errs[0].Path)
+	require.Same(t, options, cfg.Options)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"options": {"tui": {"compact_mode": false}}}`), 0o644))
+	require.Empty(t, cfg.Reload())
+	require.False(t, cfg.Options.TUI.CompactMode)
+	require.True(t, cfg.Permissions.SkipRequests)
+}
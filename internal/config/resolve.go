@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/crush/internal/env"
+	"github.com/charmbracelet/crush/internal/keyring"
 	"github.com/charmbracelet/crush/internal/shell"
 )
 
@@ -19,8 +20,9 @@ type Shell interface {
 }
 
 type shellVariableResolver struct {
-	shell Shell
-	env   env.Env
+	shell       Shell
+	env         env.Env
+	credentials func() keyring.Keyring
 }
 
 func NewShellVariableResolver(env env.Env) VariableResolver {
@@ -31,6 +33,7 @@ func NewShellVariableResolver(env env.Env) VariableResolver {
 				Env: env.Env(),
 			},
 		),
+		credentials: CredentialStore,
 	}
 }
 
@@ -38,7 +41,16 @@ func NewShellVariableResolver(env env.Env) VariableResolver {
 // it will resolve shell-like variable substitution anywhere in the string, including:
 // - $(command) for command substitution
 // - $VAR or ${VAR} for environment variables
+// A whole value of keyring:<account> is the secret kept in the keychain.
 func (r *shellVariableResolver) ResolveValue(value string) (string, error) {
+	if account, ok := strings.CutPrefix(value, CredentialPrefix); ok && r.credentials != nil {
+		secret, err := r.credentials().Get(account)
+		if err != nil {
+			return "", fmt.Errorf("credential %q not found in keychain: %w", account, err)
+		}
+		return secret, nil
+	}
+
 	// Special case: lone $ is an error (backward compatibility)
 	if value == "$" {
 		return "", fmt.Errorf("invalid value format: %s", value)
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/charmbracelet/crush/internal/home"
+)
+
+// Roots returns the root directories of the workspace: the working
+// directory, then the other roots configured.
+func (c *Config) Roots() []string {
+	roots := []string{c.workingDir}
+	if c.Options != nil {
+		roots = append(roots, c.Options.Roots...)
+	}
+	return roots
+}
+
+// RootOf returns the root containing path, the innermost one when roots
+// are nested, or the working directory when none does.
+func (c *Config) RootOf(path string) string {
+	best := ""
+	for _, root := range c.Roots() {
+		if within(root, path) && len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return c.workingDir
+	}
+	return best
+}
+
+// RelPath returns path relative to its root, prefixed with the name of the
+// root when it isn't the working directory.
+func (c *Config) RelPath(path string) string {
+	root := c.RootOf(path)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return path
+	}
+	if root == c.workingDir {
+		return rel
+	}
+	return filepath.Join(filepath.Base(root), rel)
+}
+
+// ActiveRoot returns the root the agent worked in last, where the git
+// integrations and the status bar commands run. It starts as the working
+// directory.
+func (c *Config) ActiveRoot() string {
+	if c.activeRoot != nil {
+		if root := c.activeRoot.Load(); root != nil {
+			return *root
+		}
+	}
+	return c.workingDir
+}
+
+// SetActiveRoot makes the root containing path the active one.
+func (c *Config) SetActiveRoot(path string) {
+	if c.activeRoot == nil {
+		return
+	}
+	root := c.RootOf(path)
+	c.activeRoot.Store(&root)
+}
+
+// AddRoots adds directories to the roots of the workspace, failing when
+// one of them isn't a directory.
+func (c *Config) AddRoots(dirs ...string) error {
+	for _, dir := range dirs {
+		root := resolveRoot(c.workingDir, dir)
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return fmt.Errorf("root %s is not a directory", dir)
+		}
+		if !slices.Contains(c.Roots(), root) {
+			c.Options.Roots = append(c.Options.Roots, root)
+		}
+		c.extraRoots = append(c.extraRoots, root)
+	}
+	return nil
+}
+
+// setupRoots resolves the roots configured, leaving out the ones that
+// don't exist.
+func (c *Config) setupRoots() {
+	c.activeRoot = &atomic.Pointer[string]{}
+	configured := c.Options.Roots
+	c.Options.Roots = nil
+	for _, dir := range configured {
+		root := resolveRoot(c.workingDir, dir)
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			slog.Warn("Skipping a root of the workspace that isn't a directory", "root", dir)
+			continue
+		}
+		if !slices.Contains(c.Roots(), root) {
+			c.Options.Roots = append(c.Options.Roots, root)
+		}
+	}
+}
+
+func resolveRoot(workingDir, dir string) string {
+	dir = home.Long(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(workingDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// within reports whether path is root or inside it.
+func within(root, path string) bool {
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoots(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	wd := filepath.Join(parent, "frontend")
+	backend := filepath.Join(parent, "backend")
+	require.NoError(t, os.Mkdir(wd, 0o755))
+	require.NoError(t, os.Mkdir(backend, 0o755))
+
+	cfg := &Config{Options: &Options{Roots: []string{"../backend", "../missing", wd}}}
+	cfg.setDefaults(wd, "")
+	require.Equal(t, []string{wd, backend}, cfg.Roots(), "missing roots and the working directory are left out")
+
+	require.Equal(t, backend, cfg.RootOf(filepath.Join(backend, "main.go")))
+	require.Equal(t, wd, cfg.RootOf(filepath.Join(wd, "src", "app.ts")))
+	require.Equal(t, wd, cfg.RootOf("/elsewhere/file"))
+	require.Equal(t, wd, cfg.RootOf(backend+"-old"))
+
+	require.Equal(t, filepath.Join("src", "app.ts"), cfg.RelPath(filepath.Join(wd, "src", "app.ts")))
+	require.Equal(t, filepath.Join("backend", "main.go"), cfg.RelPath(filepath.Join(backend, "main.go")))
+	require.Equal(t, "/elsewhere/file", cfg.RelPath("/elsewhere/file"))
+}
+
+func TestActiveRoot(t *testing.T) {
+	t.Parallel()
+
+	wd, other := t.TempDir(), t.TempDir()
+	cfg := &Config{Options: &Options{}}
+	cfg.setDefaults(wd, "")
+	require.Equal(t, wd, cfg.ActiveRoot())
+
+	require.Error(t, cfg.AddRoots(filepath.Join(other, "missing")))
+	require.NoError(t, cfg.AddRoots(other, other))
+	require.Equal(t, []string{wd, other}, cfg.Roots())
+
+	cfg.SetActiveRoot(filepath.Join(other, "main.go"))
+	require.Equal(t, other, cfg.ActiveRoot())
+	cfg.SetActiveRoot("/elsewhere/file")
+	require.Equal(t, wd, cfg.ActiveRoot())
+}
@@ -33,6 +33,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
 	}
+	if q.createTaskStmt, err = db.PrepareContext(ctx, createTask); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTask: %w", err)
+	}
 	if q.deleteFileStmt, err = db.PrepareContext(ctx, deleteFile); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteFile: %w", err)
 	}
@@ -48,6 +51,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteSessionMessagesStmt, err = db.PrepareContext(ctx, deleteSessionMessages); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSessionMessages: %w", err)
 	}
+	if q.deleteSessionTasksStmt, err = db.PrepareContext(ctx, deleteSessionTasks); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSessionTasks: %w", err)
+	}
 	if q.getFileStmt, err = db.PrepareContext(ctx, getFile); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFile: %w", err)
 	}
@@ -78,15 +84,45 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listSessionsStmt, err = db.PrepareContext(ctx, listSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListSessions: %w", err)
 	}
+	if q.listTasksBySessionStmt, err = db.PrepareContext(ctx, listTasksBySession); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTasksBySession: %w", err)
+	}
+	if q.searchMessagesStmt, err = db.PrepareContext(ctx, searchMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchMessages: %w", err)
+	}
+	if q.setMessagePinnedStmt, err = db.PrepareContext(ctx, setMessagePinned); err != nil {
+		return nil, fmt.Errorf("error preparing query SetMessagePinned: %w", err)
+	}
+	if q.setTaskDoneStmt, err = db.PrepareContext(ctx, setTaskDone); err != nil {
+		return nil, fmt.Errorf("error preparing query SetTaskDone: %w", err)
+	}
 	if q.updateMessageStmt, err = db.PrepareContext(ctx, updateMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateMessage: %w", err)
 	}
 	if q.updateSessionStmt, err = db.PrepareContext(ctx, updateSession); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateSession: %w", err)
 	}
+	if q.updateSessionTagsStmt, err = db.PrepareContext(ctx, updateSessionTags); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSessionTags: %w", err)
+	}
 	if q.updateSessionTitleAndUsageStmt, err = db.PrepareContext(ctx, updateSessionTitleAndUsage); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateSessionTitleAndUsage: %w", err)
 	}
+	if q.weeklyFileEditsStmt, err = db.PrepareContext(ctx, weeklyFileEdits); err != nil {
+		return nil, fmt.Errorf("error preparing query WeeklyFileEdits: %w", err)
+	}
+	if q.weeklyLatencyStmt, err = db.PrepareContext(ctx, weeklyLatency); err != nil {
+		return nil, fmt.Errorf("error preparing query WeeklyLatency: %w", err)
+	}
+	if q.weeklyModelTokensStmt, err = db.PrepareContext(ctx, weeklyModelTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query WeeklyModelTokens: %w", err)
+	}
+	if q.weeklySessionsStmt, err = db.PrepareContext(ctx, weeklySessions); err != nil {
+		return nil, fmt.Errorf("error preparing query WeeklySessions: %w", err)
+	}
+	if q.weeklyToolCallsStmt, err = db.PrepareContext(ctx, weeklyToolCalls); err != nil {
+		return nil, fmt.Errorf("error preparing query WeeklyToolCalls: %w", err)
+	}
 	return &q, nil
 }
 
@@ -107,6 +143,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
 		}
 	}
+	if q.createTaskStmt != nil {
+		if cerr := q.createTaskStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTaskStmt: %w", cerr)
+		}
+	}
 	if q.deleteFileStmt != nil {
 		if cerr := q.deleteFileStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteFileStmt: %w", cerr)
@@ -132,6 +173,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteSessionMessagesStmt: %w", cerr)
 		}
 	}
+	if q.deleteSessionTasksStmt != nil {
+		if cerr := q.deleteSessionTasksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSessionTasksStmt: %w", cerr)
+		}
+	}
 	if q.getFileStmt != nil {
 		if cerr := q.getFileStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getFileStmt: %w", cerr)
@@ -182,6 +228,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listSessionsStmt: %w", cerr)
 		}
 	}
+	if q.listTasksBySessionStmt != nil {
+		if cerr := q.listTasksBySessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTasksBySessionStmt: %w", cerr)
+		}
+	}
+	if q.searchMessagesStmt != nil {
+		if cerr := q.searchMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchMessagesStmt: %w", cerr)
+		}
+	}
+	if q.setMessagePinnedStmt != nil {
+		if cerr := q.setMessagePinnedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setMessagePinnedStmt: %w", cerr)
+		}
+	}
+	if q.setTaskDoneStmt != nil {
+		if cerr := q.setTaskDoneStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setTaskDoneStmt: %w", cerr)
+		}
+	}
 	if q.updateMessageStmt != nil {
 		if cerr := q.updateMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateMessageStmt: %w", cerr)
@@ -192,11 +258,41 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateSessionStmt: %w", cerr)
 		}
 	}
+	if q.updateSessionTagsStmt != nil {
+		if cerr := q.updateSessionTagsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSessionTagsStmt: %w", cerr)
+		}
+	}
 	if q.updateSessionTitleAndUsageStmt != nil {
 		if cerr := q.updateSessionTitleAndUsageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateSessionTitleAndUsageStmt: %w", cerr)
 		}
 	}
+	if q.weeklyFileEditsStmt != nil {
+		if cerr := q.weeklyFileEditsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing weeklyFileEditsStmt: %w", cerr)
+		}
+	}
+	if q.weeklyLatencyStmt != nil {
+		if cerr := q.weeklyLatencyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing weeklyLatencyStmt: %w", cerr)
+		}
+	}
+	if q.weeklyModelTokensStmt != nil {
+		if cerr := q.weeklyModelTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing weeklyModelTokensStmt: %w", cerr)
+		}
+	}
+	if q.weeklySessionsStmt != nil {
+		if cerr := q.weeklySessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing weeklySessionsStmt: %w", cerr)
+		}
+	}
+	if q.weeklyToolCallsStmt != nil {
+		if cerr := q.weeklyToolCallsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing weeklyToolCallsStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -239,11 +335,13 @@ type Queries struct {
 	createFileStmt                 *sql.Stmt
 	createMessageStmt              *sql.Stmt
 	createSessionStmt              *sql.Stmt
+	createTaskStmt                 *sql.Stmt
 	deleteFileStmt                 *sql.Stmt
 	deleteMessageStmt              *sql.Stmt
 	deleteSessionStmt              *sql.Stmt
 	deleteSessionFilesStmt         *sql.Stmt
 	deleteSessionMessagesStmt      *sql.Stmt
+	deleteSessionTasksStmt         *sql.Stmt
 	getFileStmt                    *sql.Stmt
 	getFileByPathAndSessionStmt    *sql.Stmt
 	getMessageStmt                 *sql.Stmt
@@ -254,9 +352,19 @@ type Queries struct {
 	listMessagesBySessionStmt      *sql.Stmt
 	listNewFilesStmt               *sql.Stmt
 	listSessionsStmt               *sql.Stmt
+	listTasksBySessionStmt         *sql.Stmt
+	searchMessagesStmt             *sql.Stmt
+	setMessagePinnedStmt           *sql.Stmt
+	setTaskDoneStmt                *sql.Stmt
 	updateMessageStmt              *sql.Stmt
 	updateSessionStmt              *sql.Stmt
+	updateSessionTagsStmt          *sql.Stmt
 	updateSessionTitleAndUsageStmt *sql.Stmt
+	weeklyFileEditsStmt            *sql.Stmt
+	weeklyLatencyStmt              *sql.Stmt
+	weeklyModelTokensStmt          *sql.Stmt
+	weeklySessionsStmt             *sql.Stmt
+	weeklyToolCallsStmt            *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
@@ -266,11 +374,13 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		createFileStmt:                 q.createFileStmt,
 		createMessageStmt:              q.createMessageStmt,
 		createSessionStmt:              q.createSessionStmt,
+		createTaskStmt:                 q.createTaskStmt,
 		deleteFileStmt:                 q.deleteFileStmt,
 		deleteMessageStmt:              q.deleteMessageStmt,
 		deleteSessionStmt:              q.deleteSessionStmt,
 		deleteSessionFilesStmt:         q.deleteSessionFilesStmt,
 		deleteSessionMessagesStmt:      q.deleteSessionMessagesStmt,
+		deleteSessionTasksStmt:         q.deleteSessionTasksStmt,
 		getFileStmt:                    q.getFileStmt,
 		getFileByPathAndSessionStmt:    q.getFileByPathAndSessionStmt,
 		getMessageStmt:                 q.getMessageStmt,
@@ -281,8 +391,18 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		listMessagesBySessionStmt:      q.listMessagesBySessionStmt,
 		listNewFilesStmt:               q.listNewFilesStmt,
 		listSessionsStmt:               q.listSessionsStmt,
+		listTasksBySessionStmt:         q.listTasksBySessionStmt,
+		searchMessagesStmt:             q.searchMessagesStmt,
+		setMessagePinnedStmt:           q.setMessagePinnedStmt,
+		setTaskDoneStmt:                q.setTaskDoneStmt,
 		updateMessageStmt:              q.updateMessageStmt,
 		updateSessionStmt:              q.updateSessionStmt,
+		updateSessionTagsStmt:          q.updateSessionTagsStmt,
 		updateSessionTitleAndUsageStmt: q.updateSessionTitleAndUsageStmt,
+		weeklyFileEditsStmt:            q.weeklyFileEditsStmt,
+		weeklyLatencyStmt:              q.weeklyLatencyStmt,
+		weeklyModelTokensStmt:          q.weeklyModelTokensStmt,
+		weeklySessionsStmt:             q.weeklySessionsStmt,
+		weeklyToolCallsStmt:            q.weeklyToolCallsStmt,
 	}
 }
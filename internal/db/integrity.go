@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ncruces/go-sqlite3/driver"
+)
+
+// CheckIntegrity runs SQLite's integrity check on the database of dataDir,
+// returning the problems it found. The database is opened read only and no
+// migrations are applied.
+func CheckIntegrity(ctx context.Context, dataDir string) ([]string, error) {
+	dsn := "file:" + filepath.ToSlash(filepath.Join(dataDir, "crush.db")) + "?mode=ro"
+	db, err := driver.Open(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check;")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check database: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return nil, err
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	return problems, rows.Err()
+}
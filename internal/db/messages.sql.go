@@ -19,12 +19,13 @@ INSERT INTO messages (
     model,
     provider,
     is_summary_message,
+    parent_message_id,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+    ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
 )
-RETURNING id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, is_summary_message
+RETURNING id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, is_summary_message, prompt_tokens, completion_tokens, latency_ms, parent_message_id, pinned
 `
 
 type CreateMessageParams struct {
@@ -35,6 +36,7 @@ type CreateMessageParams struct {
 	Model            sql.NullString `json:"model"`
 	Provider         sql.NullString `json:"provider"`
 	IsSummaryMessage int64          `json:"is_summary_message"`
+	ParentMessageID  sql.NullString `json:"parent_message_id"`
 }
 
 func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error) {
@@ -46,6 +48,7 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		arg.Model,
 		arg.Provider,
 		arg.IsSummaryMessage,
+		arg.ParentMessageID,
 	)
 	var i Message
 	err := row.Scan(
@@ -59,6 +62,11 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		&i.FinishedAt,
 		&i.Provider,
 		&i.IsSummaryMessage,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.LatencyMs,
+		&i.ParentMessageID,
+		&i.Pinned,
 	)
 	return i, err
 }
@@ -84,7 +92,7 @@ func (q *Queries) DeleteSessionMessages(ctx context.Context, sessionID string) e
 }
 
 const getMessage = `-- name: GetMessage :one
-SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, is_summary_message
+SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, is_summary_message, prompt_tokens, completion_tokens, latency_ms, parent_message_id, pinned
 FROM messages
 WHERE id = ? LIMIT 1
 `
@@ -103,12 +111,17 @@ func (q *Queries) GetMessage(ctx context.Context, id string) (Message, error) {
 		&i.FinishedAt,
 		&i.Provider,
 		&i.IsSummaryMessage,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.LatencyMs,
+		&i.ParentMessageID,
+		&i.Pinned,
 	)
 	return i, err
 }
 
 const listMessagesBySession = `-- name: ListMessagesBySession :many
-SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, is_summary_message
+SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, is_summary_message, prompt_tokens, completion_tokens, latency_ms, parent_message_id, pinned
 FROM messages
 WHERE session_id = ?
 ORDER BY created_at ASC
@@ -134,6 +147,11 @@ func (q *Queries) ListMessagesBySession(ctx context.Context, sessionID string) (
 			&i.FinishedAt,
 			&i.Provider,
 			&i.IsSummaryMessage,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.LatencyMs,
+			&i.ParentMessageID,
+			&i.Pinned,
 		); err != nil {
 			return nil, err
 		}
@@ -148,22 +166,107 @@ func (q *Queries) ListMessagesBySession(ctx context.Context, sessionID string) (
 	return items, nil
 }
 
+const searchMessages = `-- name: SearchMessages :many
+SELECT
+    m.id,
+    m.session_id,
+    m.role,
+    m.created_at,
+    CAST(snippet(messages_fts, 2, '', '', '…', 16) AS TEXT) AS snippet
+FROM messages_fts
+JOIN messages m ON m.id = messages_fts.message_id
+WHERE messages_fts MATCH ?1
+ORDER BY rank
+LIMIT ?2
+`
+
+type SearchMessagesParams struct {
+	Query      string `json:"query"`
+	MaxResults int64  `json:"max_results"`
+}
+
+type SearchMessagesRow struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Role      string `json:"role"`
+	CreatedAt int64  `json:"created_at"`
+	Snippet   string `json:"snippet"`
+}
+
+func (q *Queries) SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error) {
+	rows, err := q.query(ctx, q.searchMessagesStmt, searchMessages, arg.Query, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchMessagesRow{}
+	for rows.Next() {
+		var i SearchMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Role,
+			&i.CreatedAt,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setMessagePinned = `-- name: SetMessagePinned :exec
+UPDATE messages
+SET pinned = ?
+WHERE id = ?
+`
+
+type SetMessagePinnedParams struct {
+	Pinned int64  `json:"pinned"`
+	ID     string `json:"id"`
+}
+
+func (q *Queries) SetMessagePinned(ctx context.Context, arg SetMessagePinnedParams) error {
+	_, err := q.exec(ctx, q.setMessagePinnedStmt, setMessagePinned, arg.Pinned, arg.ID)
+	return err
+}
+
 const updateMessage = `-- name: UpdateMessage :exec
 UPDATE messages
 SET
     parts = ?,
     finished_at = ?,
+    prompt_tokens = ?,
+    completion_tokens = ?,
+    latency_ms = ?,
     updated_at = strftime('%s', 'now')
 WHERE id = ?
 `
 
 type UpdateMessageParams struct {
-	Parts      string        `json:"parts"`
-	FinishedAt sql.NullInt64 `json:"finished_at"`
-	ID         string        `json:"id"`
+	Parts            string        `json:"parts"`
+	FinishedAt       sql.NullInt64 `json:"finished_at"`
+	PromptTokens     int64         `json:"prompt_tokens"`
+	CompletionTokens int64         `json:"completion_tokens"`
+	LatencyMs        int64         `json:"latency_ms"`
+	ID               string        `json:"id"`
 }
 
 func (q *Queries) UpdateMessage(ctx context.Context, arg UpdateMessageParams) error {
-	_, err := q.exec(ctx, q.updateMessageStmt, updateMessage, arg.Parts, arg.FinishedAt, arg.ID)
+	_, err := q.exec(ctx, q.updateMessageStmt, updateMessage,
+		arg.Parts,
+		arg.FinishedAt,
+		arg.PromptTokens,
+		arg.CompletionTokens,
+		arg.LatencyMs,
+		arg.ID,
+	)
 	return err
 }
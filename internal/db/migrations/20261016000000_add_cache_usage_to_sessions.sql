@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN cache_read_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sessions ADD COLUMN cache_write_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sessions ADD COLUMN cache_savings REAL NOT NULL DEFAULT 0.0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN cache_savings;
+ALTER TABLE sessions DROP COLUMN cache_write_tokens;
+ALTER TABLE sessions DROP COLUMN cache_read_tokens;
+-- +goose StatementEnd
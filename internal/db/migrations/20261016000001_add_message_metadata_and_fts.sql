@@ -0,0 +1,85 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Usage and latency of each response, and the assistant message tool
+-- results answer
+ALTER TABLE messages ADD COLUMN prompt_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN completion_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN latency_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN parent_message_id TEXT;
+
+-- Tool results answer the assistant message created right before them
+UPDATE messages
+SET parent_message_id = (
+    SELECT a.id
+    FROM messages a
+    WHERE a.session_id = messages.session_id
+      AND a.role = 'assistant'
+      AND a.rowid < messages.rowid
+    ORDER BY a.rowid DESC
+    LIMIT 1
+)
+WHERE role = 'tool';
+
+CREATE INDEX IF NOT EXISTS idx_messages_parent_message_id ON messages (parent_message_id);
+
+-- Full-text index of the prompts and of the responses once they finish
+CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5 (
+    message_id UNINDEXED,
+    session_id UNINDEXED,
+    text,
+    tokenize = 'porter unicode61'
+);
+
+INSERT INTO messages_fts (message_id, session_id, text)
+SELECT id, session_id, (
+    SELECT group_concat(json_extract(p.value, '$.data.text'), ' ')
+    FROM json_each(messages.parts) p
+    WHERE json_extract(p.value, '$.type') = 'text'
+)
+FROM messages
+WHERE role = 'user' OR (role = 'assistant' AND finished_at IS NOT NULL);
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_insert
+AFTER INSERT ON messages
+WHEN new.role = 'user'
+BEGIN
+INSERT INTO messages_fts (message_id, session_id, text)
+VALUES (new.id, new.session_id, (
+    SELECT group_concat(json_extract(p.value, '$.data.text'), ' ')
+    FROM json_each(new.parts) p
+    WHERE json_extract(p.value, '$.type') = 'text'
+));
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_update
+AFTER UPDATE OF parts ON messages
+WHEN new.role = 'user' OR (new.role = 'assistant' AND new.finished_at IS NOT NULL)
+BEGIN
+DELETE FROM messages_fts WHERE message_id = old.id;
+INSERT INTO messages_fts (message_id, session_id, text)
+VALUES (new.id, new.session_id, (
+    SELECT group_concat(json_extract(p.value, '$.data.text'), ' ')
+    FROM json_each(new.parts) p
+    WHERE json_extract(p.value, '$.type') = 'text'
+));
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_delete
+AFTER DELETE ON messages
+BEGIN
+DELETE FROM messages_fts WHERE message_id = old.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS messages_fts_delete;
+DROP TRIGGER IF EXISTS messages_fts_update;
+DROP TRIGGER IF EXISTS messages_fts_insert;
+DROP TABLE IF EXISTS messages_fts;
+DROP INDEX IF EXISTS idx_messages_parent_message_id;
+ALTER TABLE messages DROP COLUMN parent_message_id;
+ALTER TABLE messages DROP COLUMN latency_ms;
+ALTER TABLE messages DROP COLUMN completion_tokens;
+ALTER TABLE messages DROP COLUMN prompt_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tasks (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    done INTEGER DEFAULT 0 NOT NULL,
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_session_id ON tasks (session_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tasks_session_id;
+DROP TABLE IF EXISTS tasks;
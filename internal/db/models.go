@@ -29,6 +29,11 @@ type Message struct {
 	FinishedAt       sql.NullInt64  `json:"finished_at"`
 	Provider         sql.NullString `json:"provider"`
 	IsSummaryMessage int64          `json:"is_summary_message"`
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	LatencyMs        int64          `json:"latency_ms"`
+	ParentMessageID  sql.NullString `json:"parent_message_id"`
+	Pinned           int64          `json:"pinned"`
 }
 
 type Session struct {
@@ -43,4 +48,18 @@ type Session struct {
 	CreatedAt        int64          `json:"created_at"`
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
 	Todos            sql.NullString `json:"todos"`
+	CacheReadTokens  int64          `json:"cache_read_tokens"`
+	CacheWriteTokens int64          `json:"cache_write_tokens"`
+	CacheSavings     float64        `json:"cache_savings"`
+	Tags             sql.NullString `json:"tags"`
+}
+
+type Task struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Position  int64  `json:"position"`
+	Content   string `json:"content"`
+	Done      int64  `json:"done"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
 }
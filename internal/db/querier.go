@@ -12,11 +12,13 @@ type Querier interface {
 	CreateFile(ctx context.Context, arg CreateFileParams) (File, error)
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
 	DeleteFile(ctx context.Context, id string) error
 	DeleteMessage(ctx context.Context, id string) error
 	DeleteSession(ctx context.Context, id string) error
 	DeleteSessionFiles(ctx context.Context, sessionID string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
+	DeleteSessionTasks(ctx context.Context, sessionID string) error
 	GetFile(ctx context.Context, id string) (File, error)
 	GetFileByPathAndSession(ctx context.Context, arg GetFileByPathAndSessionParams) (File, error)
 	GetMessage(ctx context.Context, id string) (Message, error)
@@ -27,9 +29,19 @@ type Querier interface {
 	ListMessagesBySession(ctx context.Context, sessionID string) ([]Message, error)
 	ListNewFiles(ctx context.Context) ([]File, error)
 	ListSessions(ctx context.Context) ([]Session, error)
+	ListTasksBySession(ctx context.Context, sessionID string) ([]Task, error)
+	SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error)
+	SetMessagePinned(ctx context.Context, arg SetMessagePinnedParams) error
+	SetTaskDone(ctx context.Context, arg SetTaskDoneParams) (Task, error)
 	UpdateMessage(ctx context.Context, arg UpdateMessageParams) error
 	UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error)
+	UpdateSessionTags(ctx context.Context, arg UpdateSessionTagsParams) error
 	UpdateSessionTitleAndUsage(ctx context.Context, arg UpdateSessionTitleAndUsageParams) error
+	WeeklyFileEdits(ctx context.Context, createdAt int64) ([]WeeklyFileEditsRow, error)
+	WeeklyLatency(ctx context.Context, createdAt int64) ([]WeeklyLatencyRow, error)
+	WeeklyModelTokens(ctx context.Context, createdAt int64) ([]WeeklyModelTokensRow, error)
+	WeeklySessions(ctx context.Context, createdAt int64) ([]WeeklySessionsRow, error)
+	WeeklyToolCalls(ctx context.Context, createdAt int64) ([]WeeklyToolCallsRow, error)
 }
 
 var _ Querier = (*Queries)(nil)
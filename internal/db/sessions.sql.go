@@ -33,7 +33,7 @@ INSERT INTO sessions (
     null,
     strftime('%s', 'now'),
     strftime('%s', 'now')
-) RETURNING id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, todos
+) RETURNING id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, todos, cache_read_tokens, cache_write_tokens, cache_savings, tags
 `
 
 type CreateSessionParams struct {
@@ -69,6 +69,10 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.CreatedAt,
 		&i.SummaryMessageID,
 		&i.Todos,
+		&i.CacheReadTokens,
+		&i.CacheWriteTokens,
+		&i.CacheSavings,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -84,7 +88,7 @@ func (q *Queries) DeleteSession(ctx context.Context, id string) error {
 }
 
 const getSessionByID = `-- name: GetSessionByID :one
-SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, todos
+SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, todos, cache_read_tokens, cache_write_tokens, cache_savings, tags
 FROM sessions
 WHERE id = ? LIMIT 1
 `
@@ -104,12 +108,16 @@ func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error
 		&i.CreatedAt,
 		&i.SummaryMessageID,
 		&i.Todos,
+		&i.CacheReadTokens,
+		&i.CacheWriteTokens,
+		&i.CacheSavings,
+		&i.Tags,
 	)
 	return i, err
 }
 
 const listSessions = `-- name: ListSessions :many
-SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, todos
+SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, todos, cache_read_tokens, cache_write_tokens, cache_savings, tags
 FROM sessions
 WHERE parent_session_id is NULL
 ORDER BY updated_at DESC
@@ -136,6 +144,10 @@ func (q *Queries) ListSessions(ctx context.Context) ([]Session, error) {
 			&i.CreatedAt,
 			&i.SummaryMessageID,
 			&i.Todos,
+			&i.CacheReadTokens,
+			&i.CacheWriteTokens,
+			&i.CacheSavings,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -158,9 +170,12 @@ SET
     completion_tokens = ?,
     summary_message_id = ?,
     cost = ?,
-    todos = ?
+    todos = ?,
+    cache_read_tokens = ?,
+    cache_write_tokens = ?,
+    cache_savings = ?
 WHERE id = ?
-RETURNING id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, todos
+RETURNING id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, todos, cache_read_tokens, cache_write_tokens, cache_savings, tags
 `
 
 type UpdateSessionParams struct {
@@ -170,6 +185,9 @@ type UpdateSessionParams struct {
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
 	Cost             float64        `json:"cost"`
 	Todos            sql.NullString `json:"todos"`
+	CacheReadTokens  int64          `json:"cache_read_tokens"`
+	CacheWriteTokens int64          `json:"cache_write_tokens"`
+	CacheSavings     float64        `json:"cache_savings"`
 	ID               string         `json:"id"`
 }
 
@@ -181,6 +199,9 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		arg.SummaryMessageID,
 		arg.Cost,
 		arg.Todos,
+		arg.CacheReadTokens,
+		arg.CacheWriteTokens,
+		arg.CacheSavings,
 		arg.ID,
 	)
 	var i Session
@@ -196,10 +217,30 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		&i.CreatedAt,
 		&i.SummaryMessageID,
 		&i.Todos,
+		&i.CacheReadTokens,
+		&i.CacheWriteTokens,
+		&i.CacheSavings,
+		&i.Tags,
 	)
 	return i, err
 }
 
+const updateSessionTags = `-- name: UpdateSessionTags :exec
+UPDATE sessions
+SET tags = ?
+WHERE id = ?
+`
+
+type UpdateSessionTagsParams struct {
+	Tags sql.NullString `json:"tags"`
+	ID   string         `json:"id"`
+}
+
+func (q *Queries) UpdateSessionTags(ctx context.Context, arg UpdateSessionTagsParams) error {
+	_, err := q.exec(ctx, q.updateSessionTagsStmt, updateSessionTags, arg.Tags, arg.ID)
+	return err
+}
+
 const updateSessionTitleAndUsage = `-- name: UpdateSessionTitleAndUsage :exec
 UPDATE sessions
 SET
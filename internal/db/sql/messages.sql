@@ -18,10 +18,11 @@ INSERT INTO messages (
     model,
     provider,
     is_summary_message,
+    parent_message_id,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+    ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
 )
 RETURNING *;
 
@@ -30,9 +31,16 @@ UPDATE messages
 SET
     parts = ?,
     finished_at = ?,
+    prompt_tokens = ?,
+    completion_tokens = ?,
+    latency_ms = ?,
     updated_at = strftime('%s', 'now')
 WHERE id = ?;
 
+-- name: SetMessagePinned :exec
+UPDATE messages
+SET pinned = ?
+WHERE id = ?;
 
 -- name: DeleteMessage :exec
 DELETE FROM messages
@@ -41,3 +49,16 @@ WHERE id = ?;
 -- name: DeleteSessionMessages :exec
 DELETE FROM messages
 WHERE session_id = ?;
+
+-- name: SearchMessages :many
+SELECT
+    m.id,
+    m.session_id,
+    m.role,
+    m.created_at,
+    CAST(snippet(messages_fts, 2, '', '', '…', 16) AS TEXT) AS snippet
+FROM messages_fts
+JOIN messages m ON m.id = messages_fts.message_id
+WHERE messages_fts MATCH sqlc.arg(query)
+ORDER BY rank
+LIMIT sqlc.arg(max_results);
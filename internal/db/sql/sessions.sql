@@ -42,10 +42,18 @@ SET
     completion_tokens = ?,
     summary_message_id = ?,
     cost = ?,
-    todos = ?
+    todos = ?,
+    cache_read_tokens = ?,
+    cache_write_tokens = ?,
+    cache_savings = ?
 WHERE id = ?
 RETURNING *;
 
+-- name: UpdateSessionTags :exec
+UPDATE sessions
+SET tags = ?
+WHERE id = ?;
+
 -- name: UpdateSessionTitleAndUsage :exec
 UPDATE sessions
 SET
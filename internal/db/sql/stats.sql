@@ -0,0 +1,50 @@
+-- name: WeeklySessions :many
+SELECT
+    CAST(created_at / 604800 AS INTEGER) AS week,
+    COUNT(*) AS count
+FROM sessions
+WHERE parent_session_id IS NULL AND created_at >= ?
+GROUP BY week
+ORDER BY week;
+
+-- name: WeeklyModelTokens :many
+SELECT
+    CAST(COALESCE(model, '') AS TEXT) AS model,
+    CAST(created_at / 604800 AS INTEGER) AS week,
+    CAST(SUM(prompt_tokens + completion_tokens) AS INTEGER) AS tokens
+FROM messages
+WHERE role = 'assistant' AND created_at >= ?
+GROUP BY model, week
+ORDER BY model, week;
+
+-- name: WeeklyToolCalls :many
+SELECT
+    CAST(json_extract(part.value, '$.data.name') AS TEXT) AS name,
+    CAST(m.created_at / 604800 AS INTEGER) AS week,
+    COUNT(*) AS count
+FROM messages m, json_each(m.parts) AS part
+WHERE m.role = 'assistant'
+    AND m.created_at >= ?
+    AND json_extract(part.value, '$.type') = 'tool_call'
+GROUP BY name, week
+ORDER BY name, week;
+
+-- name: WeeklyFileEdits :many
+SELECT
+    path,
+    CAST(created_at / 604800 AS INTEGER) AS week,
+    COUNT(*) AS count
+FROM files
+WHERE version > 0 AND created_at >= ?
+GROUP BY path, week
+ORDER BY path, week;
+
+-- name: WeeklyLatency :many
+SELECT
+    CAST(created_at / 604800 AS INTEGER) AS week,
+    CAST(AVG(latency_ms) AS INTEGER) AS latency_ms,
+    COUNT(*) AS count
+FROM messages
+WHERE role = 'assistant' AND latency_ms > 0 AND created_at >= ?
+GROUP BY week
+ORDER BY week;
@@ -0,0 +1,31 @@
+-- name: ListTasksBySession :many
+SELECT *
+FROM tasks
+WHERE session_id = ?
+ORDER BY position ASC;
+
+-- name: CreateTask :one
+INSERT INTO tasks (
+    id,
+    session_id,
+    position,
+    content,
+    done,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+RETURNING *;
+
+-- name: SetTaskDone :one
+UPDATE tasks
+SET
+    done = ?,
+    updated_at = strftime('%s', 'now')
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteSessionTasks :exec
+DELETE FROM tasks
+WHERE session_id = ?;
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Vacuum compacts the full-text index and rebuilds the database of dataDir
+// to reclaim the space of deleted sessions, returning its size in bytes
+// before and after.
+func Vacuum(ctx context.Context, conn *sql.DB, dataDir string) (int64, int64, error) {
+	path := filepath.Join(dataDir, "crush.db")
+	size := func() int64 {
+		var total int64
+		for _, p := range []string{path, path + "-wal"} {
+			if info, err := os.Stat(p); err == nil {
+				total += info.Size()
+			}
+		}
+		return total
+	}
+
+	before := size()
+	for _, stmt := range []string{
+		"INSERT INTO messages_fts (messages_fts) VALUES ('optimize');",
+		"VACUUM;",
+		"PRAGMA wal_checkpoint(TRUNCATE);",
+		"PRAGMA optimize;",
+	} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return before, size(), fmt.Errorf("failed to run `%s`: %w", stmt, err)
+		}
+	}
+	return before, size(), nil
+}
@@ -0,0 +1,471 @@
+// Package doctor checks what Crush depends on: the connection to the
+// providers, the MCP and LSP servers, the programs it runs, the terminal and
+// the database, suggesting how to fix what's wrong.
+package doctor
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/termcap"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn"
+	Fail Status = "fail"
+	// Skip is for what couldn't be checked, or isn't used.
+	Skip Status = "skip"
+)
+
+// Category groups the checks.
+type Category string
+
+const (
+	Providers Category = "Providers"
+	MCP       Category = "MCP"
+	LSP       Category = "LSP"
+	Programs  Category = "Programs"
+	Terminal  Category = "Terminal"
+	Database  Category = "Database"
+)
+
+// Categories lists the categories in the order the checks are run.
+var Categories = []Category{Providers, MCP, LSP, Programs, Terminal, Database}
+
+// Check is the outcome of checking one thing.
+type Check struct {
+	Category Category `json:"category"`
+	Name     string   `json:"name"`
+	Status   Status   `json:"status"`
+	Detail   string   `json:"detail,omitempty"`
+	// Fix suggests what to do when the status isn't OK.
+	Fix string `json:"fix,omitempty"`
+}
+
+// Options are what the checks look at besides the configuration.
+type Options struct {
+	// LookPath finds programs, exec.LookPath when nil.
+	LookPath func(string) (string, error)
+	// Environ is the environment, os.Environ() when nil.
+	Environ []string
+	// MCP and LSP are the servers running in this Crush, with the error
+	// they failed with. The others are checked from their configuration.
+	MCP map[string]error
+	LSP map[string]error
+	// Terminal is what the terminal answered to the TUI, guessed from
+	// Environ when nil.
+	Terminal *termcap.Caps
+}
+
+// timeout bounds the network checks.
+const timeout = 10 * time.Second
+
+// Run runs all the checks.
+func Run(ctx context.Context, cfg *config.Config, opts Options) []Check {
+	if opts.LookPath == nil {
+		opts.LookPath = exec.LookPath
+	}
+	if opts.Environ == nil {
+		opts.Environ = os.Environ()
+	}
+
+	var checks []Check
+	checks = append(checks, checkProviders(cfg)...)
+	checks = append(checks, checkMCP(ctx, cfg, opts)...)
+	checks = append(checks, checkLSP(cfg, opts)...)
+	checks = append(checks, checkPrograms(opts.LookPath)...)
+	caps := termcap.Detect(opts.Environ)
+	if opts.Terminal != nil {
+		caps = *opts.Terminal
+	}
+	checks = append(checks, checkTerminal(opts.Environ, caps)...)
+	checks = append(checks, checkDatabase(ctx, cfg.Options.DataDirectory))
+	return checks
+}
+
+// Failed reports whether any check failed.
+func Failed(checks []Check) bool {
+	return slices.ContainsFunc(checks, func(c Check) bool {
+		return c.Status == Fail
+	})
+}
+
+// Icons mark the status of the checks.
+var Icons = map[Status]string{
+	OK:   "✓",
+	Warn: "!",
+	Fail: "✗",
+	Skip: "-",
+}
+
+// Print writes the checks by category, with the fixes below them.
+func Print(w io.Writer, checks []Check) {
+	for _, category := range Categories {
+		printed := false
+		for _, check := range checks {
+			if check.Category != category {
+				continue
+			}
+			if !printed {
+				fmt.Fprintf(w, "%s\n", category)
+				printed = true
+			}
+			fmt.Fprintf(w, "  %s %s: %s\n", Icons[check.Status], check.Name, strings.ReplaceAll(check.Detail, "\n", "\n      "))
+			if check.Fix != "" {
+				fmt.Fprintf(w, "      %s\n", check.Fix)
+			}
+		}
+	}
+}
+
+// checkable reports whether the connection to provider can be tested.
+func checkable(provider config.ProviderConfig) bool {
+	switch provider.Type {
+	case catwalk.TypeOpenAI, catwalk.TypeOpenAICompat, catwalk.TypeOpenRouter, catwalk.TypeAnthropic, catwalk.TypeGoogle:
+		return true
+	default:
+		return false
+	}
+}
+
+func checkProviders(cfg *config.Config) []Check {
+	var providers []config.ProviderConfig
+	if cfg.Providers != nil {
+		for provider := range cfg.Providers.Seq() {
+			if !provider.Disable {
+				providers = append(providers, provider)
+			}
+		}
+	}
+	if len(providers) == 0 {
+		return []Check{{
+			Category: Providers,
+			Name:     "providers",
+			Status:   Fail,
+			Detail:   "No provider is configured.",
+			Fix:      "Run crush to go through the setup wizard, or set the API key of a provider in the environment, such as ANTHROPIC_API_KEY.",
+		}}
+	}
+	slices.SortFunc(providers, func(a, b config.ProviderConfig) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	resolver := cfg.Resolver()
+	checks := make([]Check, len(providers))
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		check := Check{Category: Providers, Name: cmp.Or(provider.Name, provider.ID)}
+		if !checkable(provider) {
+			check.Status = Skip
+			check.Detail = fmt.Sprintf("The connection to %s providers can't be tested.", provider.Type)
+			checks[i] = check
+			continue
+		}
+		wg.Go(func() {
+			start := time.Now()
+			err := provider.TestConnection(resolver)
+			if err != nil {
+				check.Status = Fail
+				check.Detail = err.Error()
+				check.Fix = fmt.Sprintf("Check the API key with 'crush credentials' and the base URL of providers.%s in the configuration.", provider.ID)
+			} else {
+				check.Status = OK
+				check.Detail = fmt.Sprintf("Connected in %s.", time.Since(start).Round(time.Millisecond))
+			}
+			checks[i] = check
+		})
+	}
+	wg.Wait()
+	return checks
+}
+
+func checkMCP(ctx context.Context, cfg *config.Config, opts Options) []Check {
+	var checks []Check
+	for _, name := range slices.Sorted(maps.Keys(cfg.MCP)) {
+		server := cfg.MCP[name]
+		check := Check{Category: MCP, Name: name}
+		err, running := opts.MCP[name]
+		switch {
+		case server.Disabled:
+			check.Status = Skip
+			check.Detail = "Disabled."
+		case running:
+			check = serverCheck(check, err, fmt.Sprintf("Check the logs with 'crush logs' and the settings of mcp.%s.", name))
+		case server.Type == config.MCPStdio || server.Type == "":
+			check = commandCheck(check, opts.LookPath, server.Command, fmt.Sprintf("mcp.%s.command", name))
+		default:
+			check = urlCheck(ctx, check, server.URL, fmt.Sprintf("mcp.%s.url", name))
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func checkLSP(cfg *config.Config, opts Options) []Check {
+	var checks []Check
+	for _, name := range slices.Sorted(maps.Keys(cfg.LSP)) {
+		server := cfg.LSP[name]
+		check := Check{Category: LSP, Name: name}
+		err, running := opts.LSP[name]
+		switch {
+		case server.Disabled:
+			check.Status = Skip
+			check.Detail = "Disabled."
+		case running:
+			check = serverCheck(check, err, fmt.Sprintf("Check the logs with 'crush logs' and the settings of lsp.%s.", name))
+		default:
+			check = commandCheck(check, opts.LookPath, server.Command, fmt.Sprintf("lsp.%s.command", name))
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// serverCheck is the check of a server running in this Crush.
+func serverCheck(check Check, err error, fix string) Check {
+	if err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = fix
+		return check
+	}
+	check.Status = OK
+	check.Detail = "Running."
+	return check
+}
+
+// commandCheck checks that the command of a server is installed.
+func commandCheck(check Check, lookPath func(string) (string, error), command, setting string) Check {
+	if command == "" {
+		check.Status = Fail
+		check.Detail = "No command is set."
+		check.Fix = fmt.Sprintf("Set %s.", setting)
+		return check
+	}
+	path, err := lookPath(command)
+	if err != nil {
+		check.Status = Fail
+		check.Detail = fmt.Sprintf("%s isn't on the PATH.", command)
+		check.Fix = fmt.Sprintf("Install %s, or set %s to where it is.", filepath.Base(command), setting)
+		return check
+	}
+	check.Status = OK
+	check.Detail = path
+	return check
+}
+
+// urlCheck checks that a server answers at url. Any response will do, as
+// servers may not take a bare GET.
+func urlCheck(ctx context.Context, check Check, url, setting string) Check {
+	if url == "" {
+		check.Status = Fail
+		check.Detail = "No URL is set."
+		check.Fix = fmt.Sprintf("Set %s.", setting)
+		return check
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = fmt.Sprintf("Fix %s.", setting)
+		return check
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = fmt.Sprintf("Check that the server is up and that %s is right.", setting)
+		return check
+	}
+	resp.Body.Close()
+	check.Status = OK
+	check.Detail = fmt.Sprintf("%s answered.", url)
+	return check
+}
+
+// programs are what Crush runs, and what goes missing without them.
+var programs = []struct {
+	name     string
+	required bool
+	without  string
+}{
+	{name: "git", required: true, without: "Changes, commits and the project history aren't available."},
+	{name: "rg", without: "Searching files falls back to a slower built-in search."},
+	{name: "gh", without: "Pull requests, reviews, issues and CI status aren't available."},
+	{name: "ctags", without: "Symbols are found with patterns, which miss some of them."},
+}
+
+func checkPrograms(lookPath func(string) (string, error)) []Check {
+	checks := make([]Check, 0, len(programs))
+	for _, program := range programs {
+		check := Check{Category: Programs, Name: program.name}
+		path, err := lookPath(program.name)
+		switch {
+		case err == nil:
+			check.Status = OK
+			check.Detail = path
+		case program.required:
+			check.Status = Fail
+			check.Detail = program.without
+			check.Fix = fmt.Sprintf("Install %s and make sure it's on the PATH.", program.name)
+		default:
+			check.Status = Warn
+			check.Detail = program.without
+			check.Fix = fmt.Sprintf("Install %s and make sure it's on the PATH.", program.name)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkTerminal reports the capabilities of the terminal, with hints for
+// tmux, which sits between Crush and the terminal.
+func checkTerminal(environ []string, caps termcap.Caps) []Check {
+	getenv := func(key string) string {
+		for _, kv := range environ {
+			if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+				return v
+			}
+		}
+		return ""
+	}
+	term := getenv("TERM")
+	tmux := getenv("TMUX") != ""
+
+	color := Check{Category: Terminal, Name: "truecolor"}
+	switch {
+	case caps.TrueColor():
+		color.Status = OK
+		color.Detail = "24-bit colors are supported."
+	default:
+		color.Status = Warn
+		color.Detail = fmt.Sprintf("Only %s colors were detected, the theme is approximated.", caps.Color)
+		color.Fix = "Set COLORTERM=truecolor if the terminal supports 24-bit colors."
+		if tmux {
+			color.Fix = "Add 'set -ag terminal-overrides \",*:RGB\"' to tmux.conf, and set COLORTERM=truecolor."
+		}
+	}
+
+	color256 := Check{Category: Terminal, Name: "256-color"}
+	switch {
+	case caps.Colors256():
+		color256.Status = OK
+		color256.Detail = "256 colors are supported."
+	default:
+		color256.Status = Warn
+		color256.Detail = "Fewer than 256 colors, gradients are drawn flat."
+		color256.Fix = "Set TERM to what the terminal supports, like xterm-256color."
+	}
+
+	mouse := Check{Category: Terminal, Name: "mouse"}
+	switch {
+	case term == "dumb" || term == "":
+		mouse.Status = Fail
+		mouse.Detail = fmt.Sprintf("TERM is %q, the terminal can't report the mouse.", term)
+		mouse.Fix = "Run Crush in a terminal emulator, with TERM set to what it supports, like xterm-256color."
+	case !caps.Mouse:
+		mouse.Status = Warn
+		mouse.Detail = "The terminal doesn't report the mouse, panes are focused with tab."
+		mouse.Fix = "Run Crush in a terminal emulator to scroll and select with the mouse."
+	default:
+		mouse.Status = OK
+		mouse.Detail = "Clicks and scrolling are reported."
+		if tmux {
+			mouse.Detail = "Clicks and scrolling are reported when tmux has 'set -g mouse on'."
+		}
+	}
+
+	keyboard := Check{Category: Terminal, Name: "kitty keyboard"}
+	switch {
+	case !caps.Probed.KittyKeyboard:
+		keyboard.Status = Skip
+		keyboard.Detail = "Only known once Crush asks the terminal, see the doctor dialog."
+	case caps.KittyKeyboard:
+		keyboard.Status = OK
+		keyboard.Detail = "Keys are disambiguated, ctrl+m is told from enter."
+	default:
+		keyboard.Status = Warn
+		keyboard.Detail = "The terminal doesn't speak the kitty keyboard protocol, some shortcuts fall back to others."
+		keyboard.Fix = "Use a terminal with the kitty keyboard protocol, like Ghostty, kitty, WezTerm or foot."
+		if tmux {
+			keyboard.Detail = "tmux doesn't pass on enhanced keys, like shift+enter, to Crush and the editors it opens."
+			keyboard.Fix = "Add 'set -s extended-keys on' and 'set -as terminal-features \",*:extkeys\"' to tmux.conf."
+		}
+	}
+
+	osc52 := Check{Category: Terminal, Name: "OSC 52"}
+	switch {
+	case tmux:
+		osc52.Status = Warn
+		osc52.Detail = "tmux only passes OSC 52 on to the terminal when set-clipboard is on."
+		osc52.Fix = "Add 'set -g set-clipboard on' to tmux.conf."
+	case caps.OSC52:
+		osc52.Status = OK
+		osc52.Detail = "The terminal copies to the clipboard, even over SSH."
+	default:
+		osc52.Status = Skip
+		osc52.Detail = "The terminal isn't known to copy to the clipboard with OSC 52."
+		osc52.Fix = "If copying doesn't work, set options.tui.clipboard to a command available here, like xclip or wl-copy."
+	}
+
+	synced := Check{Category: Terminal, Name: "synchronized output"}
+	switch {
+	case !caps.Probed.SyncOutput:
+		synced.Status = Skip
+		synced.Detail = "Only known once Crush asks the terminal, see the doctor dialog."
+	case caps.SyncOutput:
+		synced.Status = OK
+		synced.Detail = "Frames are drawn at once, without tearing."
+	default:
+		synced.Status = Skip
+		synced.Detail = "The terminal draws frames as they come, fast output may flicker."
+	}
+
+	return []Check{color, color256, mouse, keyboard, osc52, synced}
+}
+
+func checkDatabase(ctx context.Context, dataDir string) Check {
+	check := Check{Category: Database, Name: "integrity"}
+	path := filepath.Join(dataDir, "crush.db")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		check.Status = Skip
+		check.Detail = "No database yet, it's created when Crush first runs here."
+		return check
+	}
+	problems, err := db.CheckIntegrity(ctx, dataDir)
+	switch {
+	case err != nil:
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = fmt.Sprintf("Check that %s is readable.", path)
+	case len(problems) > 0:
+		check.Status = Fail
+		check.Detail = strings.Join(problems, "\n")
+		check.Fix = fmt.Sprintf("Quit Crush, then recover what can be with 'sqlite3 %s .recover', or move the file aside to start over without the sessions.", path)
+	default:
+		check.Status = OK
+		check.Detail = path
+	}
+	return check
+}
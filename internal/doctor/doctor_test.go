@@ -0,0 +1,137 @@
+package doctor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/termcap"
+	"github.com/stretchr/testify/require"
+)
+
+func lookPathOf(installed ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, i := range installed {
+			if i == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestCheckPrograms(t *testing.T) {
+	t.Parallel()
+
+	statuses := map[string]Status{}
+	for _, check := range checkPrograms(lookPathOf("rg")) {
+		statuses[check.Name] = check.Status
+		if check.Status != OK {
+			require.NotEmpty(t, check.Fix)
+		}
+	}
+	require.Equal(t, map[string]Status{"git": Fail, "rg": OK, "gh": Warn, "ctags": Warn}, statuses)
+}
+
+func TestCheckTerminal(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		environ []string
+		want    []Status
+	}{
+		"ghostty": {
+			environ: []string{"TERM=xterm-ghostty", "COLORTERM=truecolor", "TERM_PROGRAM=ghostty"},
+			want:    []Status{OK, OK, OK, Skip, OK, Skip},
+		},
+		"tmux": {
+			environ: []string{"TERM=tmux-256color", "TMUX=/tmp/tmux-1000/default,1,0"},
+			want:    []Status{Warn, OK, OK, Skip, Warn, Skip},
+		},
+		"dumb": {
+			environ: []string{"TERM=dumb"},
+			want:    []Status{Warn, Warn, Fail, Skip, Skip, Skip},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var got []Status
+			for _, check := range checkTerminal(tc.environ, termcap.Detect(tc.environ)) {
+				got = append(got, check.Status)
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("probed", func(t *testing.T) {
+		t.Parallel()
+		environ := []string{"TERM=xterm-256color"}
+		caps := termcap.Detect(environ)
+		caps.KittyKeyboard, caps.Probed.KittyKeyboard = true, true
+		caps.Probed.SyncOutput = true
+		var got []Status
+		for _, check := range checkTerminal(environ, caps) {
+			got = append(got, check.Status)
+		}
+		require.Equal(t, []Status{Warn, OK, OK, OK, Skip, Skip}, got)
+	})
+
+	t.Run("tmux without extended keys", func(t *testing.T) {
+		t.Parallel()
+		environ := []string{"TERM=tmux-256color", "TMUX=/tmp/tmux-1000/default,1,0"}
+		caps := termcap.Detect(environ)
+		caps.Probed.KittyKeyboard = true
+		keyboard := checkTerminal(environ, caps)[3]
+		require.Equal(t, Warn, keyboard.Status)
+		require.Contains(t, keyboard.Fix, "extended-keys")
+	})
+}
+
+func TestCheckServers(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		MCP: config.MCPs{
+			"docs":    {Type: config.MCPStdio, Command: "docs-mcp"},
+			"search":  {Type: config.MCPStdio, Command: "search-mcp"},
+			"running": {Type: config.MCPHttp, URL: "http://localhost:1/mcp"},
+			"off":     {Type: config.MCPStdio, Disabled: true},
+		},
+		LSP: config.LSPs{
+			"gopls": {Command: "gopls"},
+		},
+	}
+	opts := Options{
+		LookPath: lookPathOf("docs-mcp"),
+		MCP:      map[string]error{"running": errors.New("connection refused")},
+		LSP:      map[string]error{"gopls": nil},
+	}
+
+	statuses := map[string]Status{}
+	for _, check := range checkMCP(t.Context(), cfg, opts) {
+		statuses[check.Name] = check.Status
+	}
+	for _, check := range checkLSP(cfg, opts) {
+		statuses[check.Name] = check.Status
+	}
+	require.Equal(t, map[string]Status{
+		"docs":    OK,
+		"search":  Fail,
+		"running": Fail,
+		"off":     Skip,
+		"gopls":   OK,
+	}, statuses)
+}
+
+func TestCheckDatabase(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	require.Equal(t, Skip, checkDatabase(t.Context(), dataDir).Status)
+
+	conn, err := db.Connect(t.Context(), dataDir)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.Equal(t, OK, checkDatabase(t.Context(), dataDir).Status)
+}
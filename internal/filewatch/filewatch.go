@@ -0,0 +1,119 @@
+// Package filewatch notices when files the agent has in its context are
+// changed outside Crush, e.g. saved from an editor, so the context can be
+// refreshed before the agent works with an outdated copy.
+//
+// Only the files the agent has read and the ones explicitly watched are
+// checked, by polling their modification time. That's a handful of stat
+// calls per interval, and it works the same on every platform and
+// filesystem.
+package filewatch
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+// DefaultInterval is how often the files are checked.
+const DefaultInterval = 2 * time.Second
+
+// Event reports files changed outside Crush.
+type Event struct {
+	Paths []string
+}
+
+type Service interface {
+	pubsub.Subscriber[Event]
+	// Watch starts tracking a file from its current state, e.g. when it is
+	// attached to a message.
+	Watch(path string)
+	// Check returns the files changed since they were last seen and not
+	// reported before, publishing an event when there are any.
+	Check() []string
+	// Start checks the files every interval until the context is done.
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type service struct {
+	*pubsub.Broker[Event]
+
+	// seen returns the files the agent has read with the time they were
+	// last read.
+	seen func() map[string]time.Time
+
+	mu       sync.Mutex
+	watched  map[string]time.Time
+	reported map[string]time.Time
+}
+
+// NewService creates a watcher for the files returned by seen, which maps
+// paths to the time their content was last taken into the context.
+func NewService(seen func() map[string]time.Time) Service {
+	return &service{
+		Broker:   pubsub.NewBroker[Event](),
+		seen:     seen,
+		watched:  map[string]time.Time{},
+		reported: map[string]time.Time{},
+	}
+}
+
+func (s *service) Watch(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watched[path] = info.ModTime()
+}
+
+func (s *service) Check() []string {
+	files := s.seen()
+
+	s.mu.Lock()
+	for path, t := range s.watched {
+		if seen, ok := files[path]; !ok || t.After(seen) {
+			files[path] = t
+		}
+	}
+	var changed []string
+	for path, seen := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		modTime := info.ModTime()
+		if !modTime.After(seen) {
+			continue
+		}
+		if reported, ok := s.reported[path]; ok && !modTime.After(reported) {
+			continue
+		}
+		s.reported[path] = modTime
+		changed = append(changed, path)
+	}
+	s.mu.Unlock()
+
+	if len(changed) > 0 {
+		slices.Sort(changed)
+		s.Publish(pubsub.UpdatedEvent, Event{Paths: changed})
+	}
+	return changed
+}
+
+func (s *service) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Check()
+		}
+	}
+}
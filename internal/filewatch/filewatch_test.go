@@ -0,0 +1,45 @@
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	read := filepath.Join(dir, "read.go")
+	attached := filepath.Join(dir, "attached.go")
+	untracked := filepath.Join(dir, "untracked.go")
+	for _, path := range []string{read, attached, untracked} {
+		require.NoError(t, os.WriteFile(path, []byte("package a\n"), 0o644))
+	}
+
+	readAt := time.Now()
+	s := NewService(func() map[string]time.Time {
+		return map[string]time.Time{read: readAt}
+	})
+	s.Watch(attached)
+	require.Empty(t, s.Check())
+
+	events := s.Subscribe(t.Context())
+	later := time.Now().Add(time.Minute)
+	for _, path := range []string{read, attached, untracked} {
+		require.NoError(t, os.Chtimes(path, later, later))
+	}
+	require.Equal(t, []string{attached, read}, s.Check())
+	event := <-events
+	require.Equal(t, []string{attached, read}, event.Payload.Paths)
+
+	// The same change is only reported once.
+	require.Empty(t, s.Check())
+
+	evenLater := later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(read, evenLater, evenLater))
+	require.Equal(t, []string{read}, s.Check())
+}
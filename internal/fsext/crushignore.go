@@ -0,0 +1,130 @@
+package fsext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/home"
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// CrushIgnoreFile is the name of the files listing, in gitignore syntax, the
+// paths kept away from the agent.
+const CrushIgnoreFile = ".crushignore"
+
+// userCrushIgnore is the file listing the paths kept away from the agent in
+// every workspace.
+func userCrushIgnore() string {
+	return filepath.Join(home.Dir(), ".config", "crush", "ignore")
+}
+
+// CrushIgnored reports whether path is excluded by the .crushignore files
+// between root and it, or by ~/.config/crush/ignore. Unlike .gitignore, which
+// only leaves files out of listings and searches, these rules keep the agent
+// from reading and writing the files even when it asks for them by name.
+func CrushIgnored(root, path string) bool {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return false
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return matches(readIgnoreFile(userCrushIgnore()), filepath.Base(path))
+	}
+	if rel == "." {
+		return false
+	}
+	if matches(readIgnoreFile(userCrushIgnore()), rel) {
+		return true
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i := range parts {
+		dir := filepath.Join(append([]string{root}, parts[:i]...)...)
+		if matches(readIgnoreFile(filepath.Join(dir, CrushIgnoreFile)), filepath.Join(parts[i:]...)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the rules match rel, or one of the directories it
+// is in.
+func matches(rules ignore.IgnoreParser, rel string) bool {
+	if rules == nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for dir := rel; dir != "." && dir != "/"; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if rules.MatchesPath(dir) || rules.MatchesPath(dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// readIgnoreFile compiles the rules of the ignore file at name, nil when
+// there's none.
+func readIgnoreFile(name string) ignore.IgnoreParser {
+	rules, err := ignore.CompileIgnoreFile(name)
+	if err != nil {
+		return nil
+	}
+	return rules
+}
+
+// IgnoreRule is a pattern of a .crushignore file.
+type IgnoreRule struct {
+	// File is the file the rule is in.
+	File string
+	// Line is the line of the rule in the file, from 1.
+	Line    int
+	Pattern string
+}
+
+// CrushIgnoreRules returns the rules of ~/.config/crush/ignore, then of the
+// .crushignore files under each of roots, skipping the directories ignored.
+func CrushIgnoreRules(roots ...string) []IgnoreRule {
+	rules := readRules(userCrushIgnore())
+	for _, root := range roots {
+		walker := NewFastGlobWalker(root)
+		_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && (walker.ShouldSkip(path) || CrushIgnored(root, path)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Name() == CrushIgnoreFile {
+				rules = append(rules, readRules(path)...)
+			}
+			return nil
+		})
+	}
+	return rules
+}
+
+// readRules returns the patterns of the ignore file at name, without the
+// blank lines and the comments.
+func readRules(name string) []IgnoreRule {
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	var rules []IgnoreRule
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rules = append(rules, IgnoreRule{File: name, Line: i + 1, Pattern: line})
+	}
+	return rules
+}
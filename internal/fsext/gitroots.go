@@ -0,0 +1,44 @@
+package fsext
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// GitRoots returns the top-level directories of the git repositories of
+// dir: the one containing it, then the ones beneath it, at most depth
+// levels down and leaving out the directories SkipHidden does.
+func GitRoots(dir string, depth int) []string {
+	dir = filepath.Clean(dir)
+	var roots []string
+	if git, ok := LookupClosest(dir, ".git"); ok {
+		roots = append(roots, filepath.Dir(git))
+	}
+
+	var walk func(path string, level int)
+	walk = func(path string, level int) {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			sub := filepath.Join(path, entry.Name())
+			if SkipHidden(entry.Name()) {
+				continue
+			}
+			// .git is a file in worktrees and submodules.
+			if _, err := os.Stat(filepath.Join(sub, ".git")); err == nil && !slices.Contains(roots, sub) {
+				roots = append(roots, sub)
+			}
+			if level < depth {
+				walk(sub, level+1)
+			}
+		}
+	}
+	walk(dir, 1)
+	return roots
+}
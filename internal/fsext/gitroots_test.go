@@ -0,0 +1,40 @@
+package fsext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitRoots(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	mkdir := func(parts ...string) string {
+		t.Helper()
+		dir := filepath.Join(append([]string{root}, parts...)...)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		return dir
+	}
+	mkdir(".git")
+	mkdir("services", "api", ".git")
+	mkdir("services", "web", ".git")
+	mkdir("node_modules", "dep", ".git")
+	mkdir("a", "b", "c", "d", ".git")
+	require.NoError(t, os.WriteFile(filepath.Join(mkdir("libs", "shared"), ".git"), []byte("gitdir: ../../.git/modules/shared\n"), 0o644))
+
+	require.Equal(t, []string{
+		root,
+		filepath.Join(root, "libs", "shared"),
+		filepath.Join(root, "services", "api"),
+		filepath.Join(root, "services", "web"),
+	}, GitRoots(root, 3), "the repositories too deep or in ignored directories are left out")
+
+	require.Equal(t, []string{
+		root,
+		filepath.Join(root, "services", "api"),
+		filepath.Join(root, "services", "web"),
+	}, GitRoots(filepath.Join(root, "services"), 3), "the repository containing the directory comes first")
+}
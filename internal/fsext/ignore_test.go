@@ -120,3 +120,35 @@ func TestShouldExcludeFileCommonPatterns(t *testing.T) {
 		require.True(t, ShouldExcludeFile(tempDir, dir), "Expected %s to be ignored by common patterns", filepath.Base(dir))
 	}
 }
+
+func TestCrushIgnored(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	for _, name := range []string{"secrets/key.pem", "src/main.go", "src/gen/out.go", "src/cert.pem"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(tempDir, filepath.Dir(name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, name), []byte("test"), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".crushignore"), []byte("# Keys.\nsecrets/\n*.pem\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "src", ".crushignore"), []byte("gen\n"), 0o644))
+	// A .gitignore leaves files out of listings, but not out of reach.
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".gitignore"), []byte("main.go\n"), 0o644))
+
+	require.True(t, CrushIgnored(tempDir, filepath.Join(tempDir, "secrets")))
+	require.True(t, CrushIgnored(tempDir, filepath.Join(tempDir, "secrets", "key.pem")))
+	require.True(t, CrushIgnored(tempDir, filepath.Join(tempDir, "src", "cert.pem")))
+	require.True(t, CrushIgnored(tempDir, filepath.Join(tempDir, "src", "gen", "out.go")))
+	require.True(t, CrushIgnored(tempDir, filepath.Join(tempDir, "src", "gen", "new.go")), "Files yet to be written are excluded too")
+	require.False(t, CrushIgnored(tempDir, filepath.Join(tempDir, "src", "main.go")))
+	require.False(t, CrushIgnored(tempDir, tempDir))
+
+	rules := CrushIgnoreRules(tempDir)
+	var patterns []string
+	for _, rule := range rules {
+		if filepath.Dir(rule.File) != filepath.Dir(userCrushIgnore()) {
+			patterns = append(patterns, rule.Pattern)
+		}
+	}
+	require.ElementsMatch(t, []string{"secrets/", "*.pem", "gen"}, patterns)
+	require.Contains(t, rules, IgnoreRule{File: filepath.Join(tempDir, ".crushignore"), Line: 3, Pattern: "*.pem"})
+}
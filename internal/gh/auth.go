@@ -0,0 +1,149 @@
+package gh
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/remote"
+)
+
+// defaultHost is the host gh works with when none is configured.
+const defaultHost = "github.com"
+
+// ErrNotLoggedIn is returned when gh isn't logged in to the host, or not
+// with the configured account.
+var ErrNotLoggedIn = errors.New("gh isn't logged in")
+
+// Account is the GitHub host and account gh works with.
+type Account struct {
+	// Host is empty for the host gh picks, github.com or $GH_HOST.
+	Host string
+	// User is empty for the active account of the host.
+	User string
+}
+
+// String returns the account as user@host, or only the host.
+func (a Account) String() string {
+	host := cmp.Or(a.Host, defaultHost)
+	if a.User == "" {
+		return host
+	}
+	return a.User + "@" + host
+}
+
+// CurrentAccount returns the account configured in the options, the zero
+// Account when gh is left to pick.
+func CurrentAccount() Account {
+	cfg := config.Get()
+	if cfg == nil || cfg.Options == nil || cfg.Options.GitHub == nil {
+		return Account{}
+	}
+	return Account{Host: cfg.Options.GitHub.Host, User: cfg.Options.GitHub.User}
+}
+
+// tokenVar is the variable gh reads the token of the host from: GH_TOKEN for
+// github.com and GH_ENTERPRISE_TOKEN for GitHub Enterprise Server.
+func (a Account) tokenVar() string {
+	host := cmp.Or(a.Host, defaultHost)
+	if host == defaultHost || strings.HasSuffix(host, ".ghe.com") {
+		return "GH_TOKEN"
+	}
+	return "GH_ENTERPRISE_TOKEN"
+}
+
+// Command returns the gh command with the arguments, set up to work with
+// the configured account.
+func Command(ctx context.Context, dir string, args ...string) (*exec.Cmd, error) {
+	a := CurrentAccount()
+	if a == (Account{}) {
+		return remote.Command(ctx, dir, "gh", args...), nil
+	}
+	if remote.Current() != nil {
+		// The token lives on the host, it's read there.
+		script := a.script() + `gh "$@"`
+		return remote.Command(ctx, dir, "sh", append([]string{"-c", script, "gh"}, args...)...), nil
+	}
+	env, err := a.env(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cmd := remote.Command(ctx, dir, "gh", args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd, nil
+}
+
+// ShellCommand prefixes the shell command running gh, like "gh dash", to
+// work with the configured account, for the terminal or a pane.
+func ShellCommand(command string) string {
+	a := CurrentAccount()
+	if a == (Account{}) {
+		return command
+	}
+	return "sh -c " + remote.Quote(a.script()+command)
+}
+
+// env returns the environment selecting the account, reading its token
+// with gh auth token.
+func (a Account) env(ctx context.Context) ([]string, error) {
+	var env []string
+	if a.Host != "" {
+		env = append(env, "GH_HOST="+a.Host)
+	}
+	if a.User == "" {
+		return env, nil
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "gh", "auth", "token", "--hostname", cmp.Or(a.Host, defaultHost), "--user", a.User)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w as %s: %s", ErrNotLoggedIn, a, cmp.Or(strings.TrimSpace(stderr.String()), err.Error()))
+	}
+	return append(env, a.tokenVar()+"="+strings.TrimSpace(stdout.String())), nil
+}
+
+// script is the shell equivalent of env, to prefix a command with. It exits
+// when the token can't be read, rather than going on with another account.
+func (a Account) script() string {
+	var script, vars string
+	if a.Host != "" {
+		vars = "GH_HOST=" + remote.Quote(a.Host) + " "
+	}
+	if a.User != "" {
+		script = "token=$(gh auth token --hostname " + remote.Quote(cmp.Or(a.Host, defaultHost)) + " --user " + remote.Quote(a.User) + ") || exit 1; "
+		vars += a.tokenVar() + `="$token" `
+	}
+	return script + vars
+}
+
+// AuthStatus checks gh is logged in to the configured host, with the
+// configured account, returning ErrNotLoggedIn with the reason otherwise.
+func AuthStatus(ctx context.Context, dir string) error {
+	if !Installed() {
+		return ErrNotInstalled
+	}
+	a := CurrentAccount()
+	cmd, err := Command(ctx, dir, "auth", "status", "--hostname", cmp.Or(a.Host, defaultHost))
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		reason := cmp.Or(strings.TrimSpace(stderr.String()), err.Error())
+		return fmt.Errorf("%w to %s: %s", ErrNotLoggedIn, a, reason)
+	}
+	return nil
+}
+
+// LoginCommand returns the command logging gh in to the configured host.
+func LoginCommand() string {
+	return "gh auth login --hostname " + remote.Quote(cmp.Or(CurrentAccount().Host, defaultHost))
+}
@@ -0,0 +1,58 @@
+package gh
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccount(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "github.com", Account{}.String())
+	require.Equal(t, "octocat@github.com", Account{User: "octocat"}.String())
+	require.Equal(t, "octocat@github.example.com", Account{Host: "github.example.com", User: "octocat"}.String())
+
+	require.Equal(t, "GH_TOKEN", Account{}.tokenVar())
+	require.Equal(t, "GH_TOKEN", Account{Host: "acme.ghe.com"}.tokenVar())
+	require.Equal(t, "GH_ENTERPRISE_TOKEN", Account{Host: "github.example.com"}.tokenVar())
+}
+
+func TestAccountScript(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	// A gh knowing the token of one user, and printing what it's run with.
+	bin := t.TempDir()
+	fake := `#!/bin/sh
+if [ "$1 $2" = "auth token" ]; then
+	[ "$6" = "octocat" ] || { echo "no oauth token found for $6" >&2; exit 1; }
+	echo "secret-$4"
+	exit 0
+fi
+echo "$GH_HOST $GH_TOKEN$GH_ENTERPRISE_TOKEN $*"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "gh"), []byte(fake), 0o755))
+	run := func(a Account) (string, error) {
+		cmd := exec.Command("sh", "-c", a.script()+`gh "$@"`, "gh", "pr", "view")
+		cmd.Env = []string{"PATH=" + bin + string(os.PathListSeparator) + os.Getenv("PATH")}
+		out, err := cmd.Output()
+		return string(out), err
+	}
+
+	out, err := run(Account{Host: "github.example.com", User: "octocat"})
+	require.NoError(t, err)
+	require.Equal(t, "github.example.com secret-github.example.com pr view\n", out)
+
+	out, err = run(Account{User: "octocat"})
+	require.NoError(t, err)
+	require.Equal(t, " secret-github.com pr view\n", out)
+
+	_, err = run(Account{User: "someone"})
+	require.Error(t, err, "gh isn't run with another account when the token can't be read")
+}
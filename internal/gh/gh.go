@@ -0,0 +1,133 @@
+// Package gh works with the GitHub pull requests of the current branch
+// through the gh CLI.
+package gh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/remote"
+)
+
+// maxChangesSize is the size of the largest diff handed to the model.
+const maxChangesSize = 64 * 1024
+
+var (
+	// ErrNotInstalled is returned when the gh CLI can't be found.
+	ErrNotInstalled = errors.New("gh is not installed, get it from https://cli.github.com")
+	// ErrNoCommits is returned when the branch has no commits of its own.
+	ErrNoCommits = errors.New("the current branch has no commits that aren't on the base branch")
+)
+
+// PullRequest is a pull request to create.
+type PullRequest struct {
+	Title string
+	Body  string
+	Draft bool
+}
+
+// Installed reports whether the gh CLI is available.
+func Installed() bool {
+	return remote.Installed("gh")
+}
+
+// BaseBranch returns the branch pull requests are opened against, as a
+// remote ref such as "origin/main".
+func BaseBranch(ctx context.Context, dir string) (string, error) {
+	if ref, err := run(ctx, dir, nil, "git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimSpace(ref), nil
+	}
+	if !Installed() {
+		return "", errors.New("can't tell the base branch, run git remote set-head origin --auto")
+	}
+	name, err := run(ctx, dir, nil, "gh", "repo", "view", "--json", "defaultBranchRef", "--jq", ".defaultBranchRef.name")
+	if err != nil {
+		return "", err
+	}
+	return "origin/" + strings.TrimSpace(name), nil
+}
+
+// BranchChanges describes the commits of the current branch that aren't on
+// the base branch: their messages, the changed files and the diff.
+func BranchChanges(ctx context.Context, dir string) (string, error) {
+	base, err := BaseBranch(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	log, err := run(ctx, dir, nil, "git", "log", "--no-merges", "--format=- %s%n%w(0,2,2)%b", base+"..HEAD")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(log) == "" {
+		return "", ErrNoCommits
+	}
+	stat, err := run(ctx, dir, nil, "git", "diff", "--no-color", "--stat", base+"...HEAD")
+	if err != nil {
+		return "", err
+	}
+	diff, err := run(ctx, dir, nil, "git", "diff", "--no-color", base+"...HEAD")
+	if err != nil {
+		return "", err
+	}
+	if len(diff) > maxChangesSize {
+		diff = diff[:maxChangesSize] + "\n[diff truncated]\n"
+	}
+	return fmt.Sprintf("Commits:\n%s\nChanged files:\n%s\n%s", log, stat, diff), nil
+}
+
+// SplitPullRequest splits a drafted pull request into its title, the first
+// line, and its body, the rest.
+func SplitPullRequest(text string) (string, string) {
+	title, body, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+	return title, strings.TrimSpace(body)
+}
+
+// CreatePullRequest pushes the current branch and opens the pull request,
+// returning its URL.
+func CreatePullRequest(ctx context.Context, dir string, pr PullRequest) (string, error) {
+	if !Installed() {
+		return "", ErrNotInstalled
+	}
+	if strings.TrimSpace(pr.Title) == "" {
+		return "", errors.New("pull request title is empty")
+	}
+	if _, err := run(ctx, dir, nil, "git", "push", "--set-upstream", "origin", "HEAD"); err != nil {
+		return "", err
+	}
+	args := []string{"pr", "create", "--title", pr.Title, "--body-file", "-"}
+	if pr.Draft {
+		args = append(args, "--draft")
+	}
+	out, err := run(ctx, dir, strings.NewReader(pr.Body), "gh", args...)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
+func run(ctx context.Context, dir string, stdin io.Reader, name string, args ...string) (string, error) {
+	cmd := remote.Command(ctx, dir, name, args...)
+	if name == "gh" {
+		var err error
+		if cmd, err = Command(ctx, dir, args...); err != nil {
+			return "", err
+		}
+	}
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s %s: %s", name, args[0], msg)
+		}
+		return "", fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return stdout.String(), nil
+}
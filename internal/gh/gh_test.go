@@ -0,0 +1,66 @@
+package gh
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=Crush", "-c", "user.email=crush@charm.land", "-c", "commit.gpgsign=false"}, args...)
+	_, err := run(t.Context(), dir, nil, "git", args...)
+	require.NoError(t, err)
+}
+
+func TestBranchChanges(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream")
+	require.NoError(t, os.Mkdir(upstream, 0o755))
+	git(t, upstream, "init", "--quiet", "--initial-branch=main")
+	require.NoError(t, os.WriteFile(filepath.Join(upstream, "README.md"), []byte("# Project\n"), 0o644))
+	git(t, upstream, "add", ".")
+	git(t, upstream, "commit", "--quiet", "-m", "Initial commit")
+	git(t, root, "clone", "--quiet", "--bare", "upstream", "origin.git")
+	git(t, root, "clone", "--quiet", "origin.git", "work")
+
+	work := filepath.Join(root, "work")
+	base, err := BaseBranch(t.Context(), work)
+	require.NoError(t, err)
+	require.Equal(t, "origin/main", base)
+
+	git(t, work, "checkout", "--quiet", "-b", "feature")
+	_, err = BranchChanges(t.Context(), work)
+	require.ErrorIs(t, err, ErrNoCommits)
+
+	require.NoError(t, os.WriteFile(filepath.Join(work, "main.go"), []byte("package main\n"), 0o644))
+	git(t, work, "add", ".")
+	git(t, work, "commit", "--quiet", "-m", "Add the main package", "-m", "It does nothing yet.")
+
+	changes, err := BranchChanges(t.Context(), work)
+	require.NoError(t, err)
+	require.Contains(t, changes, "- Add the main package\n  It does nothing yet.")
+	require.Contains(t, changes, "main.go | 1 +")
+	require.Contains(t, changes, "+package main")
+	require.NotContains(t, changes, "README.md")
+}
+
+func TestSplitPullRequest(t *testing.T) {
+	t.Parallel()
+
+	title, body := SplitPullRequest("# Add the thing\n\nIt adds the thing.\n\n- one\n- two\n")
+	require.Equal(t, "Add the thing", title)
+	require.Equal(t, "It adds the thing.\n\n- one\n- two", body)
+
+	title, body = SplitPullRequest("  Only a title  ")
+	require.Equal(t, "Only a title", title)
+	require.Empty(t, body)
+}
@@ -0,0 +1,151 @@
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrNoPullRequest is returned when the current branch has no pull request.
+var ErrNoPullRequest = errors.New("the current branch has no pull request")
+
+// ReviewThread is a conversation started by a review comment.
+type ReviewThread struct {
+	Path string
+	// Line is 1-based, zero when the thread is about the whole file.
+	Line     int
+	Outdated bool
+	Comments []ReviewComment
+}
+
+// ReviewComment is a comment of a review thread.
+type ReviewComment struct {
+	Author string
+	Body   string
+	URL    string
+}
+
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100) {
+        nodes {
+          isResolved
+          isOutdated
+          path
+          line
+          originalLine
+          comments(first: 50) {
+            nodes {
+              author { login }
+              body
+              url
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// UnresolvedReviewThreads returns the review threads of the current
+// branch's pull request that aren't resolved yet, with the pull request URL.
+func UnresolvedReviewThreads(ctx context.Context, dir string) (string, []ReviewThread, error) {
+	if !Installed() {
+		return "", nil, ErrNotInstalled
+	}
+	out, err := run(ctx, dir, nil, "gh", "pr", "view", "--json", "number,url")
+	if err != nil {
+		if strings.Contains(err.Error(), "no pull requests found") {
+			return "", nil, ErrNoPullRequest
+		}
+		return "", nil, err
+	}
+	var pr struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(out), &pr); err != nil {
+		return "", nil, err
+	}
+
+	// The threads are queried on the base repository, the one gh resolves
+	// for the working directory.
+	repo, err := run(ctx, dir, nil, "gh", "repo", "view", "--json", "owner,name", "--jq", `.owner.login + "/" + .name`)
+	if err != nil {
+		return "", nil, err
+	}
+	owner, name, _ := strings.Cut(strings.TrimSpace(repo), "/")
+
+	out, err = run(ctx, dir, nil, "gh", "api", "graphql",
+		"-f", "query="+reviewThreadsQuery,
+		"-f", "owner="+owner,
+		"-f", "repo="+name,
+		"-F", "number="+strconv.Itoa(pr.Number),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	threads, err := parseReviewThreads([]byte(out))
+	if err != nil {
+		return "", nil, err
+	}
+	return pr.URL, threads, nil
+}
+
+func parseReviewThreads(data []byte) ([]ReviewThread, error) {
+	var resp struct {
+		Data struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						Nodes []struct {
+							IsResolved   bool   `json:"isResolved"`
+							IsOutdated   bool   `json:"isOutdated"`
+							Path         string `json:"path"`
+							Line         *int   `json:"line"`
+							OriginalLine *int   `json:"originalLine"`
+							Comments     struct {
+								Nodes []struct {
+									Author *struct {
+										Login string `json:"login"`
+									} `json:"author"`
+									Body string `json:"body"`
+									URL  string `json:"url"`
+								} `json:"nodes"`
+							} `json:"comments"`
+						} `json:"nodes"`
+					} `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	var threads []ReviewThread
+	for _, node := range resp.Data.Repository.PullRequest.ReviewThreads.Nodes {
+		if node.IsResolved || len(node.Comments.Nodes) == 0 {
+			continue
+		}
+		thread := ReviewThread{Path: node.Path, Outdated: node.IsOutdated}
+		switch {
+		case node.Line != nil:
+			thread.Line = *node.Line
+		case node.OriginalLine != nil:
+			thread.Line = *node.OriginalLine
+		}
+		for _, c := range node.Comments.Nodes {
+			author := "ghost" // GitHub's name for deleted accounts.
+			if c.Author != nil {
+				author = c.Author.Login
+			}
+			thread.Comments = append(thread.Comments, ReviewComment{Author: author, Body: strings.TrimSpace(c.Body), URL: c.URL})
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
@@ -0,0 +1,39 @@
+package gh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReviewThreads(t *testing.T) {
+	t.Parallel()
+
+	data := `{"data":{"repository":{"pullRequest":{"reviewThreads":{"nodes":[
+		{"isResolved":true,"isOutdated":false,"path":"a.go","line":3,"comments":{"nodes":[{"author":{"login":"meowgorithm"},"body":"done","url":"u1"}]}},
+		{"isResolved":false,"isOutdated":false,"path":"b.go","line":10,"comments":{"nodes":[
+			{"author":{"login":"meowgorithm"},"body":" Handle the error here. ","url":"u2"},
+			{"author":null,"body":"Agreed.","url":"u3"}
+		]}},
+		{"isResolved":false,"isOutdated":true,"path":"c.go","line":null,"originalLine":7,"comments":{"nodes":[{"author":{"login":"andreynering"},"body":"Rename this.","url":"u4"}]}}
+	]}}}}}`
+
+	threads, err := parseReviewThreads([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, []ReviewThread{
+		{
+			Path: "b.go",
+			Line: 10,
+			Comments: []ReviewComment{
+				{Author: "meowgorithm", Body: "Handle the error here.", URL: "u2"},
+				{Author: "ghost", Body: "Agreed.", URL: "u3"},
+			},
+		},
+		{
+			Path:     "c.go",
+			Line:     7,
+			Outdated: true,
+			Comments: []ReviewComment{{Author: "andreynering", Body: "Rename this.", URL: "u4"}},
+		},
+	}, threads)
+}
@@ -0,0 +1,132 @@
+// Package hooks runs the commands configured to run on events of the agent,
+// passing them the event as JSON on stdin.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+// Events hooks run on.
+const (
+	PreToolCall  = "pre_tool_call"
+	PostEdit     = "post_edit"
+	TurnComplete = "turn_complete"
+	SessionEnd   = "session_end"
+)
+
+// defaultTimeout is how long a hook runs for at most, unless configured
+// otherwise.
+const defaultTimeout = 30 * time.Second
+
+// Event is what hooks receive on stdin.
+type Event struct {
+	Event      string `json:"event"`
+	SessionID  string `json:"session_id,omitempty"`
+	WorkingDir string `json:"working_dir"`
+	// ToolName, ToolCallID and ToolInput are set on pre_tool_call and
+	// post_edit.
+	ToolName   string          `json:"tool_name,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	ToolInput  json.RawMessage `json:"tool_input,omitempty"`
+	// FilePath is the file changed, on post_edit.
+	FilePath string `json:"file_path,omitempty"`
+	// Response is what the agent answered, and Error why it failed, on
+	// turn_complete.
+	Response string `json:"response,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BlockedError is returned when a pre_tool_call hook fails, blocking the
+// tool call.
+type BlockedError struct {
+	Command string
+	// Reason is what the hook wrote.
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("blocked by hook %q", e.Command)
+	}
+	return fmt.Sprintf("blocked by hook %q: %s", e.Command, e.Reason)
+}
+
+// For returns the hooks of cfg that run on the event, for the given tool on
+// tool events.
+func For(cfg config.Hooks, event, toolName string) []config.Hook {
+	var hooks []config.Hook
+	switch event {
+	case PreToolCall:
+		hooks = cfg.PreToolCall
+	case PostEdit:
+		hooks = cfg.PostEdit
+	case TurnComplete:
+		hooks = cfg.TurnComplete
+	case SessionEnd:
+		hooks = cfg.SessionEnd
+	}
+	return slices.DeleteFunc(slices.Clone(hooks), func(h config.Hook) bool {
+		return toolName != "" && len(h.Tools) > 0 && !slices.Contains(h.Tools, toolName)
+	})
+}
+
+// Run runs the hooks of cfg matching the event one after the other. On
+// pre_tool_call, the first one failing stops the others and its failure is
+// returned as a *BlockedError. Failures of the other events are logged.
+func Run(ctx context.Context, cfg config.Hooks, event Event) error {
+	input, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, hook := range For(cfg, event.Event, event.ToolName) {
+		output, err := run(ctx, hook, event, input)
+		if err == nil {
+			continue
+		}
+		slog.Warn("Hook failed", "event", event.Event, "command", hook.Command, "error", err, "output", output)
+		if event.Event == PreToolCall {
+			return &BlockedError{Command: hook.Command, Reason: output}
+		}
+	}
+	return nil
+}
+
+// run runs a hook, returning what it wrote.
+func run(ctx context.Context, hook config.Hook, event Event, input []byte) (string, error) {
+	timeout := defaultTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	env := append(os.Environ(),
+		"CRUSH_HOOK_EVENT="+event.Event,
+		"CRUSH_SESSION_ID="+event.SessionID,
+	)
+	if event.ToolName != "" {
+		env = append(env, "CRUSH_TOOL_NAME="+event.ToolName)
+	}
+	if event.FilePath != "" {
+		env = append(env, "CRUSH_FILE_PATH="+event.FilePath)
+	}
+	sh := shell.NewShell(&shell.Options{WorkingDir: event.WorkingDir, Env: env})
+
+	var output bytes.Buffer
+	err := sh.ExecInput(ctx, hook.Command, bytes.NewReader(input), &output, &output)
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	return strings.TrimSpace(output.String()), err
+}
@@ -0,0 +1,89 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFor(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Hooks{
+		PreToolCall: []config.Hook{
+			{Command: "all"},
+			{Command: "bash only", Tools: []string{"bash"}},
+		},
+	}
+	require.Len(t, For(cfg, PreToolCall, "bash"), 2)
+	require.Equal(t, []config.Hook{{Command: "all"}}, For(cfg, PreToolCall, "edit"))
+	require.Empty(t, For(cfg, PostEdit, "bash"))
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passes the event on stdin", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		cfg := config.Hooks{
+			TurnComplete: []config.Hook{{Command: "cat > event.json"}},
+		}
+		err := Run(t.Context(), cfg, Event{Event: TurnComplete, SessionID: "s1", WorkingDir: dir, Response: "Done."})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "event.json"))
+		require.NoError(t, err)
+		var event Event
+		require.NoError(t, json.Unmarshal(data, &event))
+		require.Equal(t, "s1", event.SessionID)
+		require.Equal(t, "Done.", event.Response)
+	})
+
+	t.Run("sets the environment", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		cfg := config.Hooks{
+			PostEdit: []config.Hook{{Command: `echo "$CRUSH_HOOK_EVENT $CRUSH_FILE_PATH" > env.txt`}},
+		}
+		err := Run(t.Context(), cfg, Event{Event: PostEdit, WorkingDir: dir, ToolName: "edit", FilePath: "main.go"})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "env.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "post_edit main.go\n", string(data))
+	})
+
+	t.Run("blocks tool calls on failure", func(t *testing.T) {
+		t.Parallel()
+		cfg := config.Hooks{
+			PreToolCall: []config.Hook{{Command: "echo no force pushes; exit 1"}},
+		}
+		err := Run(t.Context(), cfg, Event{Event: PreToolCall, WorkingDir: t.TempDir(), ToolName: "bash"})
+		var blocked *BlockedError
+		require.True(t, errors.As(err, &blocked))
+		require.Equal(t, "no force pushes", blocked.Reason)
+	})
+
+	t.Run("ignores failures of other events", func(t *testing.T) {
+		t.Parallel()
+		cfg := config.Hooks{
+			TurnComplete: []config.Hook{{Command: "exit 1"}},
+		}
+		require.NoError(t, Run(t.Context(), cfg, Event{Event: TurnComplete, WorkingDir: t.TempDir()}))
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		t.Parallel()
+		cfg := config.Hooks{
+			PreToolCall: []config.Hook{{Command: "sleep 5", Timeout: 1}},
+		}
+		err := Run(t.Context(), cfg, Event{Event: PreToolCall, WorkingDir: t.TempDir(), ToolName: "bash"})
+		require.Error(t, err)
+	})
+}
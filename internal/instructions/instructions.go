@@ -0,0 +1,298 @@
+// Package instructions finds the instruction files, like CRUSH.md and
+// AGENTS.md, telling the agent about a project, and expands the @include
+// directives in them.
+package instructions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/home"
+)
+
+// maxIncludeDepth is how deep includes can nest.
+const maxIncludeDepth = 5
+
+// includeDirective starts the lines including another file.
+const includeDirective = "@include "
+
+// File is an instruction file.
+type File struct {
+	// Path is the path of the file.
+	Path string
+	// Dir is the directory the instructions apply to.
+	Dir string
+	// Content is the content of the file, with the includes expanded.
+	Content string
+	// Includes are the files included, directly or not.
+	Includes []string
+	// Errors tell the includes that failed.
+	Errors []string
+}
+
+// Read reads the instruction file at path, applying to dir.
+func Read(path, dir string) (File, error) {
+	f := File{Path: path, Dir: dir}
+	content, err := f.expand(path, nil)
+	if err != nil {
+		return File{}, err
+	}
+	f.Content = content
+	return f, nil
+}
+
+// expand returns the content of the file at path, replacing the lines of
+// @include directives with the content of the files they name, relative to
+// path. Directives in code blocks are left alone. The including files lead
+// to path.
+func (f *File) expand(path string, including []string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(string(content), "\n")
+	inCode := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+		}
+		name, ok := strings.CutPrefix(trimmed, includeDirective)
+		if inCode || !ok {
+			continue
+		}
+		name = home.Long(strings.TrimSpace(name))
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(path), name)
+		}
+		chain := append(slices.Clone(including), path)
+		if slices.Contains(chain, name) {
+			f.Errors = append(f.Errors, fmt.Sprintf("%s: includes itself", name))
+			lines[i] = ""
+			continue
+		}
+		if len(chain) >= maxIncludeDepth {
+			f.Errors = append(f.Errors, fmt.Sprintf("%s: includes nest deeper than %d files", name, maxIncludeDepth))
+			lines[i] = ""
+			continue
+		}
+		// The file comes before the ones it includes.
+		at := len(f.Includes)
+		included, err := f.expand(name, chain)
+		if err != nil {
+			f.Errors = append(f.Errors, fmt.Sprintf("%s: %v", name, unwrapPathError(err)))
+			lines[i] = ""
+			continue
+		}
+		if !slices.Contains(f.Includes, name) {
+			f.Includes = slices.Insert(f.Includes, at, name)
+		}
+		lines[i] = strings.TrimSuffix(included, "\n")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// unwrapPathError drops the operation and path from err, which are already
+// told.
+func unwrapPathError(err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		return pathErr.Err
+	}
+	return err
+}
+
+// Names returns the names of the instruction files among the context paths:
+// the relative paths of files, like CRUSH.md, which are looked for in the
+// parents and subdirectories of the working directory too.
+func Names(contextPaths []string) []string {
+	var names []string
+	for _, p := range contextPaths {
+		if p == "" || filepath.IsAbs(p) || strings.HasPrefix(p, "~") || strings.HasPrefix(p, "$") ||
+			strings.HasSuffix(p, "/") || strings.ContainsRune(p, '/') {
+			continue
+		}
+		names = append(names, p)
+	}
+	return names
+}
+
+// Parents returns the instruction files of the directories above dir, up to
+// the root of its repository or the home directory, the outermost first.
+// There are none when dir is in neither.
+func Parents(dir string, names []string) []File {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for current := dir; !isRepoRoot(current); {
+		parent := filepath.Dir(current)
+		if parent == home.Dir() {
+			break
+		}
+		if parent == current {
+			// Neither in a repository nor in the home directory.
+			return nil
+		}
+		dirs = append(dirs, parent)
+		current = parent
+	}
+	var files []File
+	for _, d := range slices.Backward(dirs) {
+		files = append(files, inDir(d, names)...)
+	}
+	return files
+}
+
+// Nested returns the instruction files of the directories between root and
+// the directory of path, the outermost first, those of root excluded. They
+// apply to the files of their directory, over the ones of root.
+func Nested(root, path string, names []string) []File {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return nil
+	}
+	var files []File
+	dir := root
+	for part := range strings.SplitSeq(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, part)
+		if fsext.CrushIgnored(root, dir) {
+			break
+		}
+		files = append(files, inDir(dir, names)...)
+	}
+	return files
+}
+
+// inDir returns the instruction files named names in dir.
+func inDir(dir string, names []string) []File {
+	var files []File
+	seen := map[string]bool{}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || seen[strings.ToLower(path)] {
+			continue
+		}
+		seen[strings.ToLower(path)] = true
+		if f, err := Read(path, dir); err == nil {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func isRepoRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// Load returns the instruction files of the system prompt: those of the
+// parents of the working directory, then of the context paths, so the ones
+// closer to the working directory come later and take precedence.
+func Load(cfg *config.Config) []File {
+	var files []File
+	seen := map[string]bool{}
+	add := func(f File) {
+		key := strings.ToLower(f.Path)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		files = append(files, f)
+	}
+	for _, f := range Parents(cfg.WorkingDir(), Names(cfg.Options.ContextPaths)) {
+		add(f)
+	}
+	for _, p := range cfg.Options.ContextPaths {
+		for _, f := range contextPath(expandPath(p, cfg), cfg) {
+			add(f)
+		}
+	}
+	return files
+}
+
+// contextPath returns the instruction files at p: the file, or the files
+// under the directory, skipping those excluded by .crushignore.
+func contextPath(p string, cfg *config.Config) []File {
+	fullPath := p
+	if !filepath.IsAbs(p) {
+		fullPath = filepath.Join(cfg.WorkingDir(), p)
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil || fsext.CrushIgnored(cfg.RootOf(fullPath), fullPath) {
+		return nil
+	}
+	if !info.IsDir() {
+		if f, err := Read(fullPath, cfg.WorkingDir()); err == nil {
+			return []File{f}
+		}
+		return nil
+	}
+	var files []File
+	_ = filepath.WalkDir(fullPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if fsext.CrushIgnored(cfg.RootOf(path), path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			if f, err := Read(path, cfg.WorkingDir()); err == nil {
+				files = append(files, f)
+			}
+		}
+		return nil
+	})
+	return files
+}
+
+// expandPath expands ~ and environment variables in file paths
+func expandPath(path string, cfg *config.Config) string {
+	path = home.Long(path)
+	// Handle environment variable expansion using the same pattern as config
+	if strings.HasPrefix(path, "$") {
+		if expanded, err := cfg.Resolver().ResolveValue(path); err == nil {
+			path = expanded
+		}
+	}
+	return path
+}
+
+// delivered are the nested instruction files given to the agent, by session.
+var (
+	delivered   = map[string][]File{}
+	deliveredMu sync.Mutex
+)
+
+// Deliver returns the nested instruction files applying to path that the
+// session wasn't given yet, and records them as given.
+func Deliver(sessionID, root, path string, names []string) []File {
+	nested := Nested(root, path, names)
+	deliveredMu.Lock()
+	defer deliveredMu.Unlock()
+	var fresh []File
+	for _, f := range nested {
+		if !slices.ContainsFunc(delivered[sessionID], func(g File) bool { return g.Path == f.Path }) {
+			fresh = append(fresh, f)
+			delivered[sessionID] = append(delivered[sessionID], f)
+		}
+	}
+	return fresh
+}
+
+// Delivered returns the nested instruction files given to the session.
+func Delivered(sessionID string) []File {
+	deliveredMu.Lock()
+	defer deliveredMu.Unlock()
+	return slices.Clone(delivered[sessionID])
+}
@@ -0,0 +1,99 @@
+package instructions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"CRUSH.md":       "# Project\n@include docs/style.md\n```\n@include not/this.md\n```\n@include missing.md\nEnd.\n",
+		"docs/style.md":  "Use tabs.\n@include ../docs/tests.md\n",
+		"docs/tests.md":  "Run go test.\n",
+		"loop/CRUSH.md":  "@include CRUSH.md\n",
+		"other/CRUSH.md": "Other.\n",
+	})
+
+	f, err := Read(filepath.Join(dir, "CRUSH.md"), dir)
+	require.NoError(t, err)
+	require.Equal(t, "# Project\nUse tabs.\nRun go test.\n```\n@include not/this.md\n```\n\nEnd.\n", f.Content)
+	require.Equal(t, []string{filepath.Join(dir, "docs", "style.md"), filepath.Join(dir, "docs", "tests.md")}, f.Includes)
+	require.Len(t, f.Errors, 1)
+	require.Contains(t, f.Errors[0], "missing.md")
+
+	f, err = Read(filepath.Join(dir, "loop", "CRUSH.md"), dir)
+	require.NoError(t, err)
+	require.Len(t, f.Errors, 1)
+	require.Contains(t, f.Errors[0], "includes itself")
+
+	_, err = Read(filepath.Join(dir, "none.md"), dir)
+	require.Error(t, err)
+}
+
+func TestNames(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"CRUSH.md", "AGENTS.md"}, Names([]string{
+		".github/copilot-instructions.md",
+		"CRUSH.md",
+		".cursor/rules/",
+		"~/notes.md",
+		"/etc/rules.md",
+		"AGENTS.md",
+	}))
+}
+
+func TestParentsAndNested(t *testing.T) {
+	t.Parallel()
+
+	repo := t.TempDir()
+	writeFiles(t, repo, map[string]string{
+		".git/HEAD":                     "ref: refs/heads/main\n",
+		"AGENTS.md":                     "Repository.\n",
+		"services/CRUSH.md":             "Services.\n",
+		"services/api/CRUSH.md":         "API.\n",
+		"services/api/internal/db.go":   "package internal\n",
+		"services/api/.crushignore":     "private/\n",
+		"services/api/private/CRUSH.md": "Private.\n",
+		"services/api/private/x.go":     "package private\n",
+	})
+	names := []string{"CRUSH.md", "AGENTS.md"}
+
+	var paths []string
+	for _, f := range Parents(filepath.Join(repo, "services", "api"), names) {
+		paths = append(paths, f.Path)
+	}
+	require.Equal(t, []string{filepath.Join(repo, "AGENTS.md"), filepath.Join(repo, "services", "CRUSH.md")}, paths)
+	require.Empty(t, Parents(repo, names), "The repository root has no parents to look into")
+
+	paths = nil
+	for _, f := range Nested(repo, filepath.Join(repo, "services", "api", "internal", "db.go"), names) {
+		paths = append(paths, f.Path)
+	}
+	require.Equal(t, []string{filepath.Join(repo, "services", "CRUSH.md"), filepath.Join(repo, "services", "api", "CRUSH.md")}, paths)
+	require.Empty(t, Nested(repo, filepath.Join(repo, "main.go"), names))
+
+	for _, f := range Nested(repo, filepath.Join(repo, "services", "api", "private", "x.go"), names) {
+		require.NotContains(t, f.Path, "private", "Directories excluded by .crushignore give no instructions")
+	}
+
+	api := filepath.Join(repo, "services", "api", "internal", "db.go")
+	require.Len(t, Deliver(t.Name(), repo, api, names), 2)
+	require.Empty(t, Deliver(t.Name(), repo, api, names), "Files are given once a session")
+	require.Len(t, Delivered(t.Name()), 2)
+}
@@ -0,0 +1,182 @@
+// Package issues lists the open issues of the repository on GitHub, through
+// the gh CLI, or GitLab, through the glab CLI.
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/gh"
+	"github.com/charmbracelet/crush/internal/remote"
+)
+
+// maxLinkedFiles is the number of files linked from an issue at most.
+const maxLinkedFiles = 5
+
+// ErrNoCLI is returned when the CLI of the repository's host is missing.
+var ErrNoCLI = errors.New("listing issues needs gh for GitHub or glab for GitLab")
+
+// Issue is an open issue.
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+	URL    string
+	Author string
+	Labels []string
+}
+
+// List returns the open issues of the repository in dir, most recent first.
+func List(ctx context.Context, dir string) ([]Issue, error) {
+	remote, err := run(ctx, dir, "git", "remote", "get-url", "origin")
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(remote, "gitlab") {
+		if !installed("glab") {
+			return nil, ErrNoCLI
+		}
+		out, err := run(ctx, dir, "glab", "api", "projects/:fullpath/issues?state=opened&per_page=100")
+		if err != nil {
+			return nil, err
+		}
+		return parseGitLabIssues([]byte(out))
+	}
+	if !installed("gh") {
+		return nil, ErrNoCLI
+	}
+	out, err := run(ctx, dir, "gh", "issue", "list", "--state", "open", "--limit", "100", "--json", "number,title,body,url,author,labels")
+	if err != nil {
+		return nil, err
+	}
+	return parseGitHubIssues([]byte(out))
+}
+
+// filePattern matches things that look like file paths, including the ones
+// in GitHub and GitLab blob URLs.
+var filePattern = regexp.MustCompile(`[\w.-]+(?:/[\w.-]+)*\.\w+`)
+
+// LinkedFiles returns the files of the repository in dir mentioned by the
+// issue, in order of appearance.
+func LinkedFiles(dir string, issue Issue) []string {
+	var files []string
+	for _, match := range filePattern.FindAllString(issue.Title+"\n"+issue.Body, -1) {
+		// Blob URLs look like host/owner/repo/blob/ref/path.
+		if _, blob, ok := strings.Cut(match, "/blob/"); ok {
+			_, match, _ = strings.Cut(blob, "/")
+		}
+		path := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(match, "./")))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) || slices.Contains(files, path) {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, path)
+		if len(files) == maxLinkedFiles {
+			break
+		}
+	}
+	return files
+}
+
+// Prompt builds the first message of a session working on the issue.
+func Prompt(issue Issue) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Work on this issue: %s (#%d)\n%s\n", issue.Title, issue.Number, issue.URL)
+	if len(issue.Labels) > 0 {
+		fmt.Fprintf(&sb, "Labels: %s\n", strings.Join(issue.Labels, ", "))
+	}
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		fmt.Fprintf(&sb, "\n%s\n", body)
+	}
+	sb.WriteString("\nStart by finding the code involved and explaining your plan, then make the change.")
+	return sb.String()
+}
+
+func parseGitHubIssues(data []byte) ([]Issue, error) {
+	var ghIssues []struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+		URL    string `json:"url"`
+		Author struct {
+			Login string `json:"login"`
+		} `json:"author"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(data, &ghIssues); err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(ghIssues))
+	for _, i := range ghIssues {
+		issue := Issue{Number: i.Number, Title: i.Title, Body: i.Body, URL: i.URL, Author: i.Author.Login}
+		for _, l := range i.Labels {
+			issue.Labels = append(issue.Labels, l.Name)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func parseGitLabIssues(data []byte) ([]Issue, error) {
+	var glIssues []struct {
+		IID         int      `json:"iid"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		WebURL      string   `json:"web_url"`
+		Labels      []string `json:"labels"`
+		Author      struct {
+			Username string `json:"username"`
+		} `json:"author"`
+	}
+	if err := json.Unmarshal(data, &glIssues); err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(glIssues))
+	for _, i := range glIssues {
+		issues = append(issues, Issue{
+			Number: i.IID,
+			Title:  i.Title,
+			Body:   i.Description,
+			URL:    i.WebURL,
+			Author: i.Author.Username,
+			Labels: i.Labels,
+		})
+	}
+	return issues, nil
+}
+
+func installed(name string) bool {
+	return remote.Installed(name)
+}
+
+func run(ctx context.Context, dir, name string, args ...string) (string, error) {
+	cmd := remote.Command(ctx, dir, name, args...)
+	if name == "gh" {
+		var err error
+		if cmd, err = gh.Command(ctx, dir, args...); err != nil {
+			return "", err
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s %s: %s", name, args[0], msg)
+		}
+		return "", fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,49 @@
+package issues
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "internal", "tui"), 0o755))
+	for _, name := range []string{"main.go", "internal/tui/tui.go", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), nil, 0o644))
+	}
+
+	issue := Issue{
+		Title: "Crash in internal/tui/tui.go",
+		Body: "It panics at internal/tui/tui.go:120, see " +
+			"https://github.com/charmbracelet/crush/blob/main/main.go#L10.\n" +
+			"Unrelated: missing.go, v1.2, example.com.",
+	}
+	require.Equal(t, []string{
+		filepath.Join(dir, "internal", "tui", "tui.go"),
+		filepath.Join(dir, "main.go"),
+	}, LinkedFiles(dir, issue))
+}
+
+func TestParseIssues(t *testing.T) {
+	t.Parallel()
+
+	gh, err := parseGitHubIssues([]byte(`[{"number":7,"title":"Bug","body":"b","url":"u","author":{"login":"kujtimiihoxha"},"labels":[{"name":"bug"}]}]`))
+	require.NoError(t, err)
+	require.Equal(t, []Issue{{Number: 7, Title: "Bug", Body: "b", URL: "u", Author: "kujtimiihoxha", Labels: []string{"bug"}}}, gh)
+
+	gl, err := parseGitLabIssues([]byte(`[{"iid":3,"title":"Idea","description":"d","web_url":"u","labels":["feature"],"author":{"username":"raphamorim"}}]`))
+	require.NoError(t, err)
+	require.Equal(t, []Issue{{Number: 3, Title: "Idea", Body: "d", URL: "u", Author: "raphamorim", Labels: []string{"feature"}}}, gl)
+}
+
+func TestPrompt(t *testing.T) {
+	t.Parallel()
+
+	got := Prompt(Issue{Number: 7, Title: "Bug", Body: "It breaks.\n", URL: "https://example.com/7", Labels: []string{"bug"}})
+	require.Equal(t, "Work on this issue: Bug (#7)\nhttps://example.com/7\nLabels: bug\n\nIt breaks.\n\nStart by finding the code involved and explaining your plan, then make the change.", got)
+}
@@ -0,0 +1,259 @@
+// Package keyring stores secrets in the operating system keychain, falling
+// back to a private file when no keychain is available.
+package keyring
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when no secret is stored for an account.
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// Keyring stores secrets for a single service, keyed by account.
+type Keyring interface {
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// New returns a keyring for the given service. The OS keychain is used when
+// available (the security tool on macOS, secret-tool on Linux, the
+// Credential Manager on Windows); otherwise secrets are kept in a JSON file
+// at fallbackPath readable only by the user.
+func New(service, fallbackPath string) Keyring {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return macKeyring{service: service}
+		}
+	case "linux", "freebsd", "openbsd":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return &fallbackKeyring{
+				primary:  secretToolKeyring{service: service},
+				fallback: NewFile(fallbackPath),
+			}
+		}
+	case "windows":
+		if k, ok := credentialManager(service); ok {
+			return &fallbackKeyring{
+				primary:  k,
+				fallback: NewFile(fallbackPath),
+			}
+		}
+	}
+	return NewFile(fallbackPath)
+}
+
+// Backend names where New keeps secrets on this machine.
+func Backend() string {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return "macOS Keychain"
+		}
+	case "linux", "freebsd", "openbsd":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return "Secret Service"
+		}
+	case "windows":
+		if _, ok := credentialManager(""); ok {
+			return "Windows Credential Manager"
+		}
+	}
+	return "private file"
+}
+
+type macKeyring struct {
+	service string
+}
+
+func (k macKeyring) Get(account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", k.service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", ErrNotFound
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (k macKeyring) Set(account, secret string) error {
+	if strings.ContainsAny(secret, "\r\n") {
+		return errors.New("keyring: secret spans several lines")
+	}
+	// security -i reads the command from stdin, which keeps the secret out
+	// of argv, where any local user could read it, and away from the -w
+	// prompt, which reads the terminal and stops at 128 characters. It
+	// reports failed commands on stderr only.
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		securityQuote(k.service), securityQuote(account), securityQuote(secret)))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("keyring: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if stderr.Len() > 0 {
+		return fmt.Errorf("keyring: %s", bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
+
+// securityQuote quotes s as an argument of a security -i command.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (k macKeyring) Delete(account string) error {
+	if err := exec.Command("security", "delete-generic-password", "-s", k.service, "-a", account).Run(); err != nil {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type secretToolKeyring struct {
+	service string
+}
+
+func (k secretToolKeyring) Get(account string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", k.service, "account", account).Output()
+	if err != nil || len(out) == 0 {
+		return "", ErrNotFound
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (k secretToolKeyring) Set(account, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label="+k.service+": "+account, "service", k.service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("keyring: %w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func (k secretToolKeyring) Delete(account string) error {
+	if err := exec.Command("secret-tool", "clear", "service", k.service, "account", account).Run(); err != nil {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// fallbackKeyring uses the primary keyring and falls back to the secondary
+// one when the primary is unusable, e.g. secret-tool without a running
+// secret service on a headless machine.
+type fallbackKeyring struct {
+	primary  Keyring
+	fallback Keyring
+}
+
+func (k *fallbackKeyring) Get(account string) (string, error) {
+	if secret, err := k.primary.Get(account); err == nil {
+		return secret, nil
+	}
+	return k.fallback.Get(account)
+}
+
+func (k *fallbackKeyring) Set(account, secret string) error {
+	if err := k.primary.Set(account, secret); err == nil {
+		return nil
+	}
+	return k.fallback.Set(account, secret)
+}
+
+func (k *fallbackKeyring) Delete(account string) error {
+	primaryErr := k.primary.Delete(account)
+	fallbackErr := k.fallback.Delete(account)
+	if primaryErr != nil && fallbackErr != nil {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// NewFile returns a keyring backed by a JSON file with 0o600 permissions.
+func NewFile(path string) Keyring {
+	return &fileKeyring{path: path}
+}
+
+type fileKeyring struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (k *fileKeyring) Get(account string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	secrets, err := k.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k *fileKeyring) Set(account, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	secrets, err := k.load()
+	if err != nil {
+		return err
+	}
+	secrets[account] = secret
+	return k.save(secrets)
+}
+
+func (k *fileKeyring) Delete(account string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	secrets, err := k.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[account]; !ok {
+		return ErrNotFound
+	}
+	delete(secrets, account)
+	return k.save(secrets)
+}
+
+func (k *fileKeyring) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := os.ReadFile(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+	return secrets, nil
+}
+
+func (k *fileKeyring) save(secrets map[string]string) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0o700); err != nil {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	if err := os.WriteFile(k.path, data, 0o600); err != nil {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build darwin
+
+package keyring
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacKeyring(t *testing.T) {
+	k := macKeyring{service: fmt.Sprintf("crush-test-%d", time.Now().UnixNano())}
+	t.Cleanup(func() { _ = k.Delete("account") })
+
+	// OAuth credentials are stored as JSON, longer than what the -w prompt
+	// reads and full of quotes.
+	secret := `{"access_token":"` + strings.Repeat("a", 200) + `","refresh_token":"r\\t"}`
+	require.NoError(t, k.Set("account", secret))
+	got, err := k.Get("account")
+	require.NoError(t, err)
+	require.Equal(t, secret, got)
+
+	require.NoError(t, k.Set("account", "updated"))
+	got, err = k.Get("account")
+	require.NoError(t, err)
+	require.Equal(t, "updated", got)
+
+	require.NoError(t, k.Delete("account"))
+	_, err = k.Get("account")
+	require.ErrorIs(t, err, ErrNotFound)
+}
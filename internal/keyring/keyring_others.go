@@ -0,0 +1,9 @@
+//go:build !windows
+
+package keyring
+
+// credentialManager returns the keyring of the Windows Credential Manager,
+// only available on Windows.
+func credentialManager(string) (Keyring, bool) {
+	return nil, false
+}
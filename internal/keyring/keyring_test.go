@@ -0,0 +1,33 @@
+package keyring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileKeyring(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "secrets.json")
+	k := NewFile(path)
+
+	_, err := k.Get("server")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, k.Set("server", "s3cr3t"))
+	secret, err := k.Get("server")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", secret)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(t, k.Delete("server"))
+	_, err = k.Get("server")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, k.Delete("server"), ErrNotFound)
+}
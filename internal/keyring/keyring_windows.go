@@ -0,0 +1,101 @@
+//go:build windows
+
+package keyring
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32        = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+// credential mirrors the CREDENTIALW structure of the Windows API.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialManager returns the keyring of the Windows Credential Manager.
+func credentialManager(service string) (Keyring, bool) {
+	return winKeyring{service: service}, procCredReadW.Find() == nil
+}
+
+type winKeyring struct {
+	service string
+}
+
+func (k winKeyring) target(account string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(k.service + ":" + account)
+}
+
+func (k winKeyring) Get(account string) (string, error) {
+	target, err := k.target(account)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	if r, _, _ := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred))); r == 0 {
+		return "", ErrNotFound
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (k winKeyring) Set(account, secret string) error {
+	target, err := k.target(account)
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	return nil
+}
+
+func (k winKeyring) Delete(account string) error {
+	target, err := k.target(account)
+	if err != nil {
+		return err
+	}
+	if r, _, _ := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// tailBytes is how much of the end of the log file is read at first.
+const tailBytes = 1 << 20
+
+// Entry is a record of the log file.
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Source is the file and line the record was logged from.
+	Source string
+	// Attrs are the other fields, by key.
+	Attrs []Attr
+}
+
+// Attr is a field of an Entry.
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// ParseEntry parses a line of the log file, reporting whether it was a
+// record.
+func ParseEntry(line []byte) (Entry, bool) {
+	var data map[string]any
+	if err := json.Unmarshal(line, &data); err != nil {
+		return Entry{}, false
+	}
+	var entry Entry
+	for k, v := range data {
+		switch k {
+		case "time":
+			s, _ := v.(string)
+			entry.Time, _ = time.Parse(time.RFC3339Nano, s)
+		case "level":
+			s, _ := v.(string)
+			_ = entry.Level.UnmarshalText([]byte(s))
+		case "msg":
+			entry.Message = fmt.Sprint(v)
+		case "source":
+			if source, ok := v.(map[string]any); ok {
+				entry.Source = fmt.Sprintf("%v:%v", source["file"], source["line"])
+			}
+		default:
+			value, ok := v.(string)
+			if !ok {
+				b, _ := json.Marshal(v)
+				value = string(b)
+			}
+			entry.Attrs = append(entry.Attrs, Attr{Key: k, Value: value})
+		}
+	}
+	slices.SortFunc(entry.Attrs, func(a, b Attr) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return entry, true
+}
+
+// Read reads the records of the log file at path from offset, returning them
+// with the offset to read the next ones from. A negative offset reads the
+// end of the file. When the file is shorter than offset, as when it was
+// rotated, it's read from the start. Incomplete lines are left for the next
+// read.
+func Read(path string, offset int64) ([]Entry, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	tail := offset < 0
+	switch {
+	case tail:
+		offset = max(0, info.Size()-tailBytes)
+	case info.Size() < offset:
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, offset, err
+	}
+
+	// The first line may be cut when reading the end of the file.
+	if tail && offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+			offset += int64(i + 1)
+		}
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, offset, nil
+	}
+
+	var entries []Entry
+	for line := range bytes.Lines(data[:end+1]) {
+		if entry, ok := ParseEntry(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, offset + int64(end+1), nil
+}
@@ -0,0 +1,58 @@
+package log
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntry(t *testing.T) {
+	t.Parallel()
+
+	entry, ok := ParseEntry([]byte(`{"time":"2025-06-01T10:00:00.5Z","level":"WARN","source":{"function":"f","file":"/src/app.go","line":12},"msg":"Slow response","provider":"anthropic","elapsed":2.5}`))
+	require.True(t, ok)
+	require.Equal(t, slog.LevelWarn, entry.Level)
+	require.Equal(t, "Slow response", entry.Message)
+	require.Equal(t, "/src/app.go:12", entry.Source)
+	require.Equal(t, []Attr{{Key: "elapsed", Value: "2.5"}, {Key: "provider", Value: "anthropic"}}, entry.Attrs)
+	require.Equal(t, 500_000_000, entry.Time.Nanosecond())
+
+	_, ok = ParseEntry([]byte("panic: oops"))
+	require.False(t, ok)
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "crush.log")
+	first := `{"level":"INFO","msg":"one"}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(first+`{"level":"DEBUG","msg":"tw`), 0o644))
+
+	entries, offset, err := Read(path, -1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "one", entries[0].Message)
+	require.Equal(t, int64(len(first)), offset)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("o\"}\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, offset, err = Read(path, offset)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "two", entries[0].Message)
+	require.Equal(t, slog.LevelDebug, entries[0].Level)
+
+	// Rotated: the file is shorter than what was read.
+	require.NoError(t, os.WriteFile(path, []byte(`{"level":"ERROR","msg":"three"}`+"\n"), 0o644))
+	entries, _, err = Read(path, offset)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "three", entries[0].Message)
+}
@@ -16,6 +16,10 @@ import (
 var (
 	initOnce    sync.Once
 	initialized atomic.Bool
+
+	// level can be changed while running, see SetLevel.
+	level   = new(slog.LevelVar)
+	logPath atomic.Value
 )
 
 func Setup(logFile string, debug bool) {
@@ -28,9 +32,9 @@ func Setup(logFile string, debug bool) {
 			Compress:   false, // Enable compression
 		}
 
-		level := slog.LevelInfo
+		level.Set(slog.LevelInfo)
 		if debug {
-			level = slog.LevelDebug
+			level.Set(slog.LevelDebug)
 		}
 
 		logger := slog.NewJSONHandler(logRotator, &slog.HandlerOptions{
@@ -39,6 +43,7 @@ func Setup(logFile string, debug bool) {
 		})
 
 		slog.SetDefault(slog.New(logger))
+		logPath.Store(logFile)
 		initialized.Store(true)
 	})
 }
@@ -47,6 +52,23 @@ func Initialized() bool {
 	return initialized.Load()
 }
 
+// File returns the path of the log file, empty until Setup is called.
+func File() string {
+	path, _ := logPath.Load().(string)
+	return path
+}
+
+// Level returns the level below which records are dropped.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the level below which records are dropped, to get debug
+// logs without restarting.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
 func RecoverPanic(name string, cleanup func()) {
 	if r := recover(); r != nil {
 		event.Error(r, "panic", true, "name", name)
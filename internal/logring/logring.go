@@ -0,0 +1,52 @@
+// Package logring keeps the latest log entries of named servers, such as
+// the MCP and LSP ones, for the TUI to show.
+package logring
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Entry is a line in the log of a server.
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Message string
+}
+
+// Logs keeps the last entries of the log of each server.
+type Logs struct {
+	mu      sync.RWMutex
+	size    int
+	entries map[string][]Entry
+}
+
+// New returns logs keeping up to size entries for each server.
+func New(size int) *Logs {
+	return &Logs{size: size, entries: map[string][]Entry{}}
+}
+
+// Get returns the log of the given server, oldest first.
+func (l *Logs) Get(name string) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return slices.Clone(l.entries[name])
+}
+
+// Append adds an entry to the log of the given server, dropping the oldest
+// one when it's full.
+func (l *Logs) Append(name, level, format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := append(l.entries[name], Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+	})
+	if len(entries) > l.size {
+		entries = entries[len(entries)-l.size:]
+	}
+	l.entries[name] = entries
+}
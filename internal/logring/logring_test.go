@@ -0,0 +1,28 @@
+package logring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	logs := New(2)
+	require.Empty(t, logs.Get("server"))
+
+	for i := range 3 {
+		logs.Append("server", "info", "line %d", i)
+	}
+	logs.Append("other", "error", "failed")
+
+	entries := logs.Get("server")
+	require.Len(t, entries, 2, "the oldest entry is dropped")
+	require.Equal(t, "line 1", entries[0].Message)
+	require.Equal(t, "line 2", entries[1].Message)
+	require.Equal(t, "info", entries[1].Level)
+
+	entries[0].Message = "changed"
+	require.Equal(t, "line 1", logs.Get("server")[0].Message, "callers get a copy")
+}
@@ -8,6 +8,7 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -25,12 +26,18 @@ type Client struct {
 	client *powernap.Client
 	name   string
 
+	// Command running the language server
+	command string
+
 	// File types this LSP server handles (e.g., .go, .rs, .py)
 	fileTypes []string
 
 	// Configuration for this LSP client
 	config config.LSPConfig
 
+	// Root directories of the workspace, the first being the working directory
+	roots []string
+
 	// Diagnostic change callback
 	onDiagnosticsChanged func(name string, count int)
 
@@ -44,21 +51,28 @@ type Client struct {
 	serverState atomic.Value
 }
 
-// New creates a new LSP client using the powernap implementation.
-func New(ctx context.Context, name string, config config.LSPConfig, resolver config.VariableResolver) (*Client, error) {
-	// Convert working directory to file URI
-	workDir, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get working directory: %w", err)
+// New creates a new LSP client using the powernap implementation. The
+// first of roots, the root directories of the workspace, is the root of the
+// server; all of them are workspace folders.
+func New(ctx context.Context, name string, config config.LSPConfig, resolver config.VariableResolver, roots []string) (*Client, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no workspace root for lsp %s", name)
 	}
-
-	rootURI := string(protocol.URIFromPath(workDir))
+	rootURI := string(protocol.URIFromPath(roots[0]))
 
 	command, err := resolver.ResolveValue(config.Command)
 	if err != nil {
 		return nil, fmt.Errorf("invalid lsp command: %w", err)
 	}
 
+	folders := make([]protocol.WorkspaceFolder, 0, len(roots))
+	for _, root := range roots {
+		folders = append(folders, protocol.WorkspaceFolder{
+			URI:  string(protocol.URIFromPath(root)),
+			Name: filepath.Base(root),
+		})
+	}
+
 	// Create powernap client config
 	clientConfig := powernap.ClientConfig{
 		Command: home.Long(command),
@@ -69,14 +83,9 @@ func New(ctx context.Context, name string, config config.LSPConfig, resolver con
 			maps.Copy(env, config.Env)
 			return env
 		}(),
-		Settings:    config.Options,
-		InitOptions: config.InitOptions,
-		WorkspaceFolders: []protocol.WorkspaceFolder{
-			{
-				URI:  rootURI,
-				Name: filepath.Base(workDir),
-			},
-		},
+		Settings:         config.Options,
+		InitOptions:      config.InitOptions,
+		WorkspaceFolders: folders,
 	}
 
 	// Create the powernap client
@@ -88,10 +97,12 @@ func New(ctx context.Context, name string, config config.LSPConfig, resolver con
 	client := &Client{
 		client:      powernapClient,
 		name:        name,
+		command:     home.Long(command),
 		fileTypes:   config.FileTypes,
 		diagnostics: csync.NewVersionedMap[protocol.DocumentURI, []protocol.Diagnostic](),
 		openFiles:   csync.NewMap[string, *OpenFileInfo](),
 		config:      config,
+		roots:       roots,
 	}
 
 	// Initialize server state
@@ -129,7 +140,8 @@ func (c *Client) Initialize(ctx context.Context, workspaceDir string) (*protocol
 	c.RegisterServerRequestHandler("workspace/applyEdit", HandleApplyEdit)
 	c.RegisterServerRequestHandler("workspace/configuration", HandleWorkspaceConfiguration)
 	c.RegisterServerRequestHandler("client/registerCapability", HandleRegisterCapability)
-	c.RegisterNotificationHandler("window/showMessage", HandleServerMessage)
+	c.RegisterNotificationHandler("window/showMessage", c.handleLogMessage)
+	c.RegisterNotificationHandler("window/logMessage", c.handleLogMessage)
 	c.RegisterNotificationHandler("textDocument/publishDiagnostics", func(_ context.Context, _ string, params json.RawMessage) {
 		HandleDiagnostics(c, params)
 	})
@@ -161,6 +173,7 @@ const (
 	StateReady
 	StateError
 	StateDisabled
+	StateCrashed
 )
 
 // GetServerState returns the current state of the LSP server
@@ -176,6 +189,11 @@ func (c *Client) SetServerState(state ServerState) {
 	c.serverState.Store(state)
 }
 
+// IsRunning reports whether the language server is still connected.
+func (c *Client) IsRunning() bool {
+	return c.client.IsRunning()
+}
+
 // GetName returns the name of the LSP client
 func (c *Client) GetName() string {
 	return c.name
@@ -406,20 +424,17 @@ func (c *Client) DidChangeWatchedFiles(ctx context.Context, params protocol.DidC
 
 // openKeyConfigFiles opens important configuration files that help initialize the server.
 func (c *Client) openKeyConfigFiles(ctx context.Context) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return
-	}
-
 	// Try to open each file, ignoring errors if they don't exist
-	for _, file := range c.config.RootMarkers {
-		file = filepath.Join(wd, file)
-		if _, err := os.Stat(file); err == nil {
-			// File exists, try to open it
-			if err := c.OpenFile(ctx, file); err != nil {
-				slog.Error("Failed to open key config file", "file", file, "error", err)
-			} else {
-				slog.Debug("Opened key config file for initialization", "file", file)
+	for _, root := range c.roots {
+		for _, file := range c.config.RootMarkers {
+			file = filepath.Join(root, file)
+			if _, err := os.Stat(file); err == nil {
+				// File exists, try to open it
+				if err := c.OpenFile(ctx, file); err != nil {
+					slog.Error("Failed to open key config file", "file", file, "error", err)
+				} else {
+					slog.Debug("Opened key config file for initialization", "file", file)
+				}
 			}
 		}
 	}
@@ -455,6 +470,23 @@ func (c *Client) FindReferences(ctx context.Context, filepath string, line, char
 	return c.client.FindReferences(ctx, filepath, line-1, character-1, includeDeclaration)
 }
 
+// FindDefinitions finds the declarations of the symbol at the given position.
+// powernap doesn't expose textDocument/definition, so the declarations are
+// the locations only reported when references include the declaration.
+func (c *Client) FindDefinitions(ctx context.Context, filepath string, line, character int) ([]protocol.Location, error) {
+	withDecl, err := c.FindReferences(ctx, filepath, line, character, true)
+	if err != nil {
+		return nil, err
+	}
+	withoutDecl, err := c.FindReferences(ctx, filepath, line, character, false)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(withDecl, func(loc protocol.Location) bool {
+		return slices.Contains(withoutDecl, loc)
+	}), nil
+}
+
 // HasRootMarkers checks if any of the specified root marker patterns exist in the given directory.
 // Uses glob patterns to match files, allowing for more flexible matching.
 func HasRootMarkers(dir string, rootMarkers []string) bool {
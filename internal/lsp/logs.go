@@ -0,0 +1,51 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/charmbracelet/crush/internal/logring"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+// maxLogEntries is the number of log entries kept for each LSP server.
+const maxLogEntries = 200
+
+// LogEntry is a line in the log of an LSP server.
+type LogEntry = logring.Entry
+
+var logs = logring.New(maxLogEntries)
+
+// Logs returns the log of the given LSP server, oldest first. It holds the
+// messages sent by the server along with its lifecycle events, and is kept
+// across restarts.
+func Logs(name string) []LogEntry {
+	return logs.Get(name)
+}
+
+// AppendLog adds an entry to the log of the given LSP server.
+func AppendLog(name, level, format string, args ...any) {
+	logs.Append(name, level, format, args...)
+}
+
+// handleLogMessage records window/logMessage and window/showMessage
+// notifications in the server's log.
+func (c *Client) handleLogMessage(ctx context.Context, method string, params json.RawMessage) {
+	if method == "window/showMessage" {
+		HandleServerMessage(ctx, method, params)
+	}
+	var msg protocol.LogMessageParams
+	if err := json.Unmarshal(params, &msg); err != nil {
+		return
+	}
+	level := "log"
+	switch msg.Type {
+	case protocol.Error:
+		level = "error"
+	case protocol.Warning:
+		level = "warn"
+	case protocol.Info:
+		level = "info"
+	}
+	AppendLog(c.name, level, "%s", msg.Message)
+}
@@ -0,0 +1,68 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMemoryUnavailable is returned by [Client.MemoryUsage] when the memory
+// use of the server can't be determined.
+var ErrMemoryUnavailable = errors.New("memory use unavailable")
+
+// MemoryUsage returns the resident memory of the language server process,
+// in bytes. powernap doesn't expose the process, so it's looked up among the
+// children of Crush by command name.
+func (c *Client) MemoryUsage(ctx context.Context) (uint64, error) {
+	if runtime.GOOS == "windows" {
+		return 0, ErrMemoryUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ps", "-A", "-o", "ppid=,rss=,comm=").Output()
+	if err != nil {
+		return 0, ErrMemoryUnavailable
+	}
+	rss, ok := childRSS(out, os.Getpid(), c.command)
+	if !ok {
+		return 0, ErrMemoryUnavailable
+	}
+	return rss * 1024, nil
+}
+
+// childRSS finds the RSS, in kilobytes, of the child of ppid running command
+// in the output of ps -o ppid=,rss=,comm=.
+func childRSS(psOutput []byte, ppid int, command string) (uint64, bool) {
+	name := filepath.Base(command)
+	scanner := bufio.NewScanner(bytes.NewReader(psOutput))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		parent, err := strconv.Atoi(fields[0])
+		if err != nil || parent != ppid {
+			continue
+		}
+		// comm is truncated to 15 characters on Linux and may be a full
+		// path on macOS.
+		comm := filepath.Base(strings.Join(fields[2:], " "))
+		if comm != name && !(len(comm) >= 15 && strings.HasPrefix(name, comm)) {
+			continue
+		}
+		rss, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return rss, true
+	}
+	return 0, false
+}
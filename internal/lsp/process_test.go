@@ -0,0 +1,39 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildRSS(t *testing.T) {
+	t.Parallel()
+
+	ps := []byte(`    1  1024 init
+  100  2048 gopls
+  200  4096 gopls
+  100 81920 typescript-lang
+  100   512 /usr/local/bin/nil
+`)
+
+	tests := []struct {
+		name    string
+		command string
+		want    uint64
+		ok      bool
+	}{
+		{"exact name", "gopls", 2048, true},
+		{"full path", "/home/me/go/bin/gopls", 2048, true},
+		{"truncated comm", "typescript-language-server", 81920, true},
+		{"macos path comm", "nil", 512, true},
+		{"not a child", "init", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rss, ok := childRSS(ps, 100, tt.command)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, rss)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Package mcpserver exposes Crush's built-in file, search, and shell tools
+// over the Model Context Protocol so other agents and editors can use them.
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync/atomic"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/apiserver"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/version"
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Server serves the built-in tools over MCP. Every tool call goes through
+// the regular permission service. Over stdio, prompts are forwarded to the
+// client that started Crush as elicitation requests, and denied when it
+// can't show them. Over SSE, where any local process holding the token may
+// be the client, they're denied: the caller can't approve its own calls.
+type Server struct {
+	server      *mcp.Server
+	sessionID   string
+	permissions permission.Service
+
+	// elicit is whether permission prompts are sent to the client.
+	elicit atomic.Bool
+
+	// callers maps tool call IDs to the client that made the call, so
+	// permission prompts can be sent back to it.
+	callers *csync.Map[string, *mcp.ServerSession]
+}
+
+// New creates a server exposing the built-in tools. A Crush session is
+// created to hold the file history of the changes made through it.
+func New(
+	ctx context.Context,
+	cfg *config.Config,
+	sessions session.Service,
+	permissions permission.Service,
+	files history.Service,
+	lspClients *csync.Map[string, *lsp.Client],
+) (*Server, error) {
+	sess, err := sessions.Create(ctx, "MCP Server")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	s := &Server{
+		server: mcp.NewServer(&mcp.Implementation{
+			Name:    "crush",
+			Version: version.Version,
+			Title:   "Crush",
+		}, nil),
+		sessionID:   sess.ID,
+		permissions: permissions,
+		callers:     csync.NewMap[string, *mcp.ServerSession](),
+	}
+
+	workingDir := cfg.WorkingDir()
+	all := []fantasy.AgentTool{
+		tools.NewBashTool(permissions, workingDir, cfg.Options.Attribution, ""),
+		tools.NewJobOutputTool(),
+		tools.NewJobKillTool(),
+		tools.NewJobInputTool(permissions, workingDir),
+		tools.NewEditTool(lspClients, permissions, files, workingDir),
+		tools.NewMultiEditTool(lspClients, permissions, files, workingDir),
+		tools.NewGlobTool(workingDir),
+		tools.NewGrepTool(workingDir),
+		tools.NewLsTool(permissions, workingDir, cfg.Tools.Ls),
+		tools.NewViewTool(lspClients, permissions, workingDir),
+		tools.NewWriteTool(lspClients, permissions, files, workingDir),
+	}
+	for _, tool := range all {
+		if slices.Contains(cfg.Options.DisabledTools, tool.Info().Name) {
+			continue
+		}
+		s.addTool(tool)
+	}
+
+	go s.handlePermissions(ctx)
+	return s, nil
+}
+
+// Run serves a single client over the given transport, e.g.
+// [mcp.StdioTransport], until the client disconnects or ctx is cancelled.
+// The client is the one that started Crush, so it's asked for permissions.
+func (s *Server) Run(ctx context.Context, transport mcp.Transport) error {
+	s.elicit.Store(true)
+	return s.server.Run(ctx, transport)
+}
+
+// SSEHandler returns an HTTP handler serving any number of clients over SSE,
+// to those carrying the token only. Tools not allowed in the permissions
+// are denied, as the clients would approve their own calls.
+func (s *Server) SSEHandler(token string) http.Handler {
+	return apiserver.RequireToken(token, mcp.NewSSEHandler(func(*http.Request) *mcp.Server {
+		return s.server
+	}, nil))
+}
+
+func (s *Server) addTool(tool fantasy.AgentTool) {
+	info := tool.Info()
+	required := info.Required
+	if required == nil {
+		required = []string{}
+	}
+	s.server.AddTool(&mcp.Tool{
+		Name:        info.Name,
+		Description: info.Description,
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": info.Parameters,
+			"required":   required,
+		},
+	}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		callID := uuid.New().String()
+		s.callers.Set(callID, req.Session)
+		defer s.callers.Del(callID)
+
+		input := string(req.Params.Arguments)
+		if input == "" {
+			input = "{}"
+		}
+		ctx = context.WithValue(ctx, tools.SessionIDContextKey, s.sessionID)
+		ctx = context.WithValue(ctx, tools.MessageIDContextKey, callID)
+		resp, err := tool.Run(ctx, fantasy.ToolCall{
+			ID:    callID,
+			Name:  info.Name,
+			Input: input,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return toolResult(resp), nil
+	})
+}
+
+func toolResult(resp fantasy.ToolResponse) *mcp.CallToolResult {
+	result := &mcp.CallToolResult{IsError: resp.IsError}
+	if resp.Type == "image" && len(resp.Data) > 0 {
+		result.Content = append(result.Content, &mcp.ImageContent{
+			Data:     resp.Data,
+			MIMEType: resp.MediaType,
+		})
+	}
+	if resp.Content != "" || len(result.Content) == 0 {
+		result.Content = append(result.Content, &mcp.TextContent{Text: resp.Content})
+	}
+	return result
+}
+
+// handlePermissions answers the permission requests made by tool calls
+// coming from MCP clients.
+func (s *Server) handlePermissions(ctx context.Context) {
+	for event := range s.permissions.Subscribe(ctx) {
+		req := event.Payload
+		if req.SessionID != s.sessionID {
+			continue
+		}
+		caller, ok := s.callers.Get(req.ToolCallID)
+		if !ok || !s.elicit.Load() {
+			slog.Warn("Denying a tool not allowed in the permissions to an MCP client", "tool", req.ToolName, "action", req.Action)
+			s.permissions.Deny(req)
+			continue
+		}
+		go s.askPermission(ctx, caller, req)
+	}
+}
+
+func (s *Server) askPermission(ctx context.Context, caller *mcp.ServerSession, req permission.PermissionRequest) {
+	params := caller.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		slog.Warn("MCP client can't prompt for permissions, denying", "tool", req.ToolName, "action", req.Action)
+		s.permissions.Deny(req)
+		return
+	}
+
+	details, _ := json.MarshalIndent(req.Params, "", "  ")
+	result, err := caller.Elicit(ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("Allow %s to %s in %s?\n\n%s\n\n%s", req.ToolName, req.Action, req.Path, req.Description, details),
+		RequestedSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	})
+	if err != nil || result.Action != "accept" {
+		s.permissions.Deny(req)
+		return
+	}
+	s.permissions.Grant(req)
+}
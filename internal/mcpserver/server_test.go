@@ -0,0 +1,105 @@
+package mcpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	t.Setenv("CRUSH_DISABLE_PROVIDER_AUTO_UPDATE", "1")
+	t.Setenv("CRUSH_GLOBAL_CONFIG", t.TempDir())
+	t.Setenv("CRUSH_GLOBAL_DATA", t.TempDir())
+
+	workingDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, "hello.txt"), []byte("hello from crush\n"), 0o644))
+
+	cfg, err := config.Init(workingDir, "", false)
+	require.NoError(t, err)
+	cfg.Options.DisabledTools = []string{"bash"}
+
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+
+	newServer := func(t *testing.T, allowedTools []string) *mcp.ClientSession {
+		permissions := permission.NewPermissionService(workingDir, false, allowedTools)
+		srv, err := New(t.Context(), cfg, session.NewService(q), permissions, history.NewService(q, conn), csync.NewMap[string, *lsp.Client]())
+		require.NoError(t, err)
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		go func() { _ = srv.Run(t.Context(), serverTransport) }()
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+		cs, err := client.Connect(t.Context(), clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { cs.Close() })
+		return cs
+	}
+
+	t.Run("lists the built-in tools", func(t *testing.T) {
+		cs := newServer(t, nil)
+		result, err := cs.ListTools(t.Context(), nil)
+		require.NoError(t, err)
+
+		var names []string
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		require.Contains(t, names, "view")
+		require.Contains(t, names, "grep")
+		require.NotContains(t, names, "bash")
+	})
+
+	t.Run("runs allowed tools", func(t *testing.T) {
+		cs := newServer(t, []string{"view"})
+		result, err := cs.CallTool(t.Context(), &mcp.CallToolParams{
+			Name:      "view",
+			Arguments: map[string]any{"file_path": filepath.Join(workingDir, "hello.txt")},
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		require.Contains(t, result.Content[0].(*mcp.TextContent).Text, "hello from crush")
+	})
+
+	t.Run("denies when the client can't prompt", func(t *testing.T) {
+		cs := newServer(t, nil)
+		result, err := cs.CallTool(t.Context(), &mcp.CallToolParams{
+			Name: "write",
+			Arguments: map[string]any{
+				"file_path": filepath.Join(workingDir, "new.txt"),
+				"content":   "nope",
+			},
+		})
+		if err == nil {
+			require.True(t, result.IsError)
+		}
+		require.NoFileExists(t, filepath.Join(workingDir, "new.txt"))
+	})
+
+	t.Run("requires the token over SSE", func(t *testing.T) {
+		permissions := permission.NewPermissionService(workingDir, false, nil)
+		srv, err := New(t.Context(), cfg, session.NewService(q), permissions, history.NewService(q, conn), csync.NewMap[string, *lsp.Client]())
+		require.NoError(t, err)
+		ts := httptest.NewServer(srv.SSEHandler("secret"))
+		t.Cleanup(ts.Close)
+
+		resp, err := http.Get(ts.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
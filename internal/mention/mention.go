@@ -0,0 +1,225 @@
+// Package mention finds what a prompt mentions with @, files, symbols and
+// URLs, and turns it into attachments sent with the prompt.
+package mention
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/symbols"
+)
+
+// MaxSize is the size of the largest attachment a mention resolves to.
+const MaxSize = 5 * 1024 * 1024
+
+// snippetLines is the number of lines attached from the declaration of a
+// mentioned symbol.
+const snippetLines = 40
+
+// Mention is an @-mention in a text, at the byte offsets Start and End,
+// including the @.
+type Mention struct {
+	// Value is what is mentioned, without the @.
+	Value      string
+	Start, End int
+}
+
+// IsURL reports whether the mention is a web page.
+func (m Mention) IsURL() bool {
+	return strings.HasPrefix(m.Value, "https://") || strings.HasPrefix(m.Value, "http://")
+}
+
+// Parse returns the mentions of text, in order. Mentions start with an @
+// at the start of a word, so e-mail addresses aren't mentions, and never
+// are in code spans and blocks. Punctuation ending a sentence isn't part of
+// them.
+func Parse(text string) []Mention {
+	var mentions []Mention
+	inFence := false
+	offset := 0
+	for line := range strings.Lines(text) {
+		start := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		inCode := false
+		for i := 0; i < len(line); i++ {
+			switch {
+			case line[i] == '`':
+				inCode = !inCode
+				continue
+			case inCode || line[i] != '@':
+				continue
+			}
+			if i > 0 {
+				prev, _ := utf8.DecodeLastRuneInString(line[:i])
+				if !unicode.IsSpace(prev) && !strings.ContainsRune("([{\"'", prev) {
+					continue
+				}
+			}
+			end := i + 1
+			for end < len(line) {
+				r, size := utf8.DecodeRuneInString(line[end:])
+				if unicode.IsSpace(r) || r == '`' {
+					break
+				}
+				end += size
+			}
+			value := strings.TrimRight(line[i+1:end], ".,;:!?)]}\"'")
+			if value != "" {
+				mentions = append(mentions, Mention{
+					Value: value,
+					Start: start + i,
+					End:   start + i + 1 + len(value),
+				})
+			}
+			i = end - 1
+		}
+	}
+	return mentions
+}
+
+// Resolver turns mentions into attachments.
+type Resolver struct {
+	// Dir is the directory mentioned files are relative to, and whose
+	// symbols are mentioned.
+	Dir    string
+	Client *http.Client
+	// Cache keeps fetched pages; nothing is cached when nil.
+	Cache *tools.FetchCache
+	// Symbols is the index of Dir, built when it is first needed when nil.
+	Symbols []symbols.Symbol
+}
+
+// Resolve returns the attachment of what m mentions, and false when it
+// mentions nothing Crush knows about, like a person.
+func (r *Resolver) Resolve(ctx context.Context, m Mention) (message.Attachment, bool, error) {
+	if m.IsURL() {
+		attachment, err := r.fetch(ctx, m.Value)
+		return attachment, err == nil, err
+	}
+	path := m.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.Dir, path)
+	}
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		attachment, err := File(m.Value, path)
+		return attachment, err == nil, err
+	}
+	if r.Symbols == nil {
+		index, err := symbols.Index(ctx, r.Dir)
+		if err != nil {
+			return message.Attachment{}, false, err
+		}
+		r.Symbols = index
+	}
+	for _, symbol := range symbols.Filter(r.Symbols, m.Value, 1) {
+		if symbol.Name == m.Value || symbol.Scope+"."+symbol.Name == m.Value {
+			attachment, err := Symbol(r.Dir, symbol)
+			return attachment, err == nil, err
+		}
+	}
+	return message.Attachment{}, false, nil
+}
+
+// File returns the attachment of the file at path, mentioned as name.
+func File(name, path string) (message.Attachment, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return message.Attachment{}, err
+	}
+	if len(content) > MaxSize {
+		return message.Attachment{}, fmt.Errorf("%s is too big (>5mb)", name)
+	}
+	return message.Attachment{
+		FilePath: name,
+		FileName: filepath.Base(name),
+		MimeType: http.DetectContentType(content[:min(512, len(content))]),
+		Content:  content,
+	}, nil
+}
+
+// Symbol returns the attachment of the declaration of symbol, the lines
+// starting at it.
+func Symbol(dir string, symbol symbols.Symbol) (message.Attachment, error) {
+	path := symbol.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return message.Attachment{}, err
+	}
+	defer f.Close()
+
+	var snippet strings.Builder
+	last := symbol.Line
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan() && n < symbol.Line+snippetLines; n++ {
+		if n >= symbol.Line {
+			snippet.WriteString(scanner.Text())
+			snippet.WriteByte('\n')
+			last = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return message.Attachment{}, err
+	}
+	if snippet.Len() == 0 {
+		return message.Attachment{}, fmt.Errorf("%s has no line %d", symbol.Path, symbol.Line)
+	}
+	name := symbol.Path
+	if rel, err := filepath.Rel(dir, path); err == nil && filepath.IsLocal(rel) {
+		name = rel
+	}
+	return message.Attachment{
+		FilePath: fmt.Sprintf("%s:%d-%d", name, symbol.Line, last),
+		FileName: symbol.Name,
+		MimeType: "text/plain",
+		Content:  []byte(snippet.String()),
+	}, nil
+}
+
+// fetch returns the attachment of the web page at rawURL, converted to
+// Markdown when it is HTML.
+func (r *Resolver) fetch(ctx context.Context, rawURL string) (message.Attachment, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return message.Attachment{}, fmt.Errorf("invalid URL %q", rawURL)
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	content, err := r.Cache.Fetch(ctx, client, rawURL)
+	if err != nil {
+		return message.Attachment{}, fmt.Errorf("%s: %w", rawURL, err)
+	}
+	if content == "" {
+		return message.Attachment{}, errors.New(rawURL + " is empty")
+	}
+	name := u.Host + strings.TrimSuffix(u.Path, "/")
+	return message.Attachment{
+		FilePath: rawURL,
+		FileName: name,
+		MimeType: "text/plain",
+		Content:  []byte(content),
+	}, nil
+}
@@ -0,0 +1,71 @@
+package mention
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	text := "Look at @main.go and (@config.Load), mail me@example.com.\n" +
+		"Read @https://example.com/docs. Not `@code` or\n```\n@block\n```\n@last"
+	var got []string
+	for _, m := range Parse(text) {
+		got = append(got, m.Value)
+		require.Equal(t, "@"+m.Value, text[m.Start:m.End])
+	}
+	require.Equal(t, []string{"main.go", "config.Load", "https://example.com/docs", "last"}, got)
+	require.True(t, Parse("@http://localhost:8080/x")[0].IsURL())
+	require.False(t, Parse("@main.go")[0].IsURL())
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc Serve() {\n\treturn\n}\n"), 0o644))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body><h1>Docs</h1><p>Hello.</p></body></html>"))
+	}))
+	t.Cleanup(srv.Close)
+
+	r := &Resolver{Dir: dir, Client: srv.Client()}
+	resolve := func(value string) (string, string, bool) {
+		attachment, ok, err := r.Resolve(t.Context(), Mention{Value: value})
+		require.NoError(t, err)
+		return attachment.FilePath, string(attachment.Content), ok
+	}
+
+	path, content, ok := resolve("main.go")
+	require.True(t, ok)
+	require.Equal(t, "main.go", path)
+	require.Contains(t, content, "func Serve()")
+
+	path, content, ok = resolve("Serve")
+	require.True(t, ok)
+	require.Equal(t, "main.go:3-5", path)
+	require.Equal(t, "func Serve() {\n\treturn\n}\n", content)
+
+	path, content, ok = resolve(srv.URL + "/docs")
+	require.True(t, ok)
+	require.Equal(t, srv.URL+"/docs", path)
+	require.Contains(t, content, "# Docs")
+
+	_, _, ok = resolve("someone")
+	require.False(t, ok)
+
+	_, ok, err := r.Resolve(t.Context(), Mention{Value: srv.URL + "/missing"})
+	require.False(t, ok)
+	require.Error(t, err)
+}
@@ -135,6 +135,16 @@ type Message struct {
 	CreatedAt        int64
 	UpdatedAt        int64
 	IsSummaryMessage bool
+	// ParentMessageID is, for tool results, the assistant message whose
+	// tool calls they answer.
+	ParentMessageID string
+	// PromptTokens and CompletionTokens are what an assistant message used,
+	// and Latency how long it took to respond.
+	PromptTokens     int64
+	CompletionTokens int64
+	Latency          time.Duration
+	// Pinned messages are kept verbatim when the session is summarized.
+	Pinned bool
 }
 
 func (m *Message) Content() TextContent {
@@ -436,6 +446,9 @@ func (m *Message) AddBinary(mimeType string, data []byte) {
 	m.Parts = append(m.Parts, BinaryContent{MIMEType: mimeType, Data: data})
 }
 
+// AttachmentsNote introduces the text files attached to a prompt.
+const AttachmentsNote = "<system_info>The files below have been attached by the user, consider them in your response</system_info>"
+
 func PromptWithTextAttachments(prompt string, attachments []Attachment) string {
 	addedAttachments := false
 	for _, content := range attachments {
@@ -443,7 +456,7 @@ func PromptWithTextAttachments(prompt string, attachments []Attachment) string {
 			continue
 		}
 		if !addedAttachments {
-			prompt += "\n<system_info>The files below have been attached by the user, consider them in your response</system_info>\n"
+			prompt += "\n" + AttachmentsNote + "\n"
 			addedAttachments = true
 		}
 		tag := `<file>\n`
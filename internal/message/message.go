@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/db"
@@ -18,6 +19,17 @@ type CreateMessageParams struct {
 	Model            string
 	Provider         string
 	IsSummaryMessage bool
+	// ParentMessageID is the assistant message tool results answer.
+	ParentMessageID string
+}
+
+// SearchResult is a message matching a search, with the matching text.
+type SearchResult struct {
+	MessageID string
+	SessionID string
+	Role      MessageRole
+	CreatedAt int64
+	Snippet   string
 }
 
 type Service interface {
@@ -28,6 +40,12 @@ type Service interface {
 	List(ctx context.Context, sessionID string) ([]Message, error)
 	Delete(ctx context.Context, id string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
+	// Search returns the prompts and responses containing all the words of
+	// query, best matches first.
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// SetPinned pins or unpins the message with id, returning it. It isn't
+	// published as an update, as what the message says is unchanged.
+	SetPinned(ctx context.Context, id string, pinned bool) (Message, error)
 }
 
 type service struct {
@@ -79,6 +97,7 @@ func (s *service) Create(ctx context.Context, sessionID string, params CreateMes
 		Model:            sql.NullString{String: string(params.Model), Valid: true},
 		Provider:         sql.NullString{String: params.Provider, Valid: params.Provider != ""},
 		IsSummaryMessage: isSummary,
+		ParentMessageID:  sql.NullString{String: params.ParentMessageID, Valid: params.ParentMessageID != ""},
 	})
 	if err != nil {
 		return Message{}, err
@@ -120,9 +139,12 @@ func (s *service) Update(ctx context.Context, message Message) error {
 		finishedAt.Valid = true
 	}
 	err = s.q.UpdateMessage(ctx, db.UpdateMessageParams{
-		ID:         message.ID,
-		Parts:      string(parts),
-		FinishedAt: finishedAt,
+		ID:               message.ID,
+		Parts:            string(parts),
+		FinishedAt:       finishedAt,
+		PromptTokens:     message.PromptTokens,
+		CompletionTokens: message.CompletionTokens,
+		LatencyMs:        message.Latency.Milliseconds(),
 	})
 	if err != nil {
 		return err
@@ -134,6 +156,22 @@ func (s *service) Update(ctx context.Context, message Message) error {
 	return nil
 }
 
+func (s *service) SetPinned(ctx context.Context, id string, pinned bool) (Message, error) {
+	var value int64
+	if pinned {
+		value = 1
+	}
+	if err := s.q.SetMessagePinned(ctx, db.SetMessagePinnedParams{ID: id, Pinned: value}); err != nil {
+		return Message{}, err
+	}
+	message, err := s.Get(ctx, id)
+	if err != nil {
+		return Message{}, err
+	}
+	s.Publish(pubsub.UpdatedEvent, message.Clone())
+	return message, nil
+}
+
 func (s *service) Get(ctx context.Context, id string) (Message, error) {
 	dbMessage, err := s.q.GetMessage(ctx, id)
 	if err != nil {
@@ -157,6 +195,41 @@ func (s *service) List(ctx context.Context, sessionID string) ([]Message, error)
 	return messages, nil
 }
 
+func (s *service) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+	rows, err := s.q.SearchMessages(ctx, db.SearchMessagesParams{
+		Query:      match,
+		MaxResults: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = SearchResult{
+			MessageID: row.ID,
+			SessionID: row.SessionID,
+			Role:      MessageRole(row.Role),
+			CreatedAt: row.CreatedAt,
+			Snippet:   row.Snippet,
+		}
+	}
+	return results, nil
+}
+
+// ftsQuery turns the words of query into a full-text query matching all of
+// them, quoted so that operators and punctuation are searched as text.
+func ftsQuery(query string) string {
+	words := strings.Fields(query)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
+
 func (s *service) fromDBItem(item db.Message) (Message, error) {
 	parts, err := unmarshallParts([]byte(item.Parts))
 	if err != nil {
@@ -172,6 +245,11 @@ func (s *service) fromDBItem(item db.Message) (Message, error) {
 		CreatedAt:        item.CreatedAt,
 		UpdatedAt:        item.UpdatedAt,
 		IsSummaryMessage: item.IsSummaryMessage != 0,
+		ParentMessageID:  item.ParentMessageID.String,
+		PromptTokens:     item.PromptTokens,
+		CompletionTokens: item.CompletionTokens,
+		Latency:          time.Duration(item.LatencyMs) * time.Millisecond,
+		Pinned:           item.Pinned != 0,
 	}, nil
 }
 
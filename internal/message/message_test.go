@@ -0,0 +1,100 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFTSQuery(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `"rate" "limit"`, ftsQuery("rate  limit"))
+	require.Equal(t, `"say" """hi""" "OR"`, ftsQuery(`say "hi" OR`))
+	require.Empty(t, ftsQuery("  "))
+}
+
+func TestServiceMetadataAndSearch(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	conn, err := db.Connect(t.Context(), dataDir)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	_, err = q.CreateSession(t.Context(), db.CreateSessionParams{ID: "session", Title: "Rate limits"})
+	require.NoError(t, err)
+	svc := NewService(q)
+
+	_, err = svc.Create(t.Context(), "session", CreateMessageParams{
+		Role:  User,
+		Parts: []ContentPart{TextContent{Text: "Why do we hit the rate limit?"}},
+	})
+	require.NoError(t, err)
+
+	assistant, err := svc.Create(t.Context(), "session", CreateMessageParams{Role: Assistant})
+	require.NoError(t, err)
+	assistant.AppendContent("The throttling happens upstream.")
+	assistant.AddToolCall(ToolCall{ID: "call", Name: "view", Finished: true})
+	require.NoError(t, svc.Update(t.Context(), assistant))
+
+	// Responses are indexed once they're finished.
+	results, err := svc.Search(t.Context(), "throttling", 10)
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	assistant.AddFinish(FinishReasonToolUse, "", "")
+	assistant.PromptTokens = 1200
+	assistant.CompletionTokens = 80
+	assistant.Latency = 1500 * time.Millisecond
+	require.NoError(t, svc.Update(t.Context(), assistant))
+
+	result, err := svc.Create(t.Context(), "session", CreateMessageParams{
+		Role:            Tool,
+		Parts:           []ContentPart{ToolResult{ToolCallID: "call", Name: "view", Content: "ok"}},
+		ParentMessageID: assistant.ID,
+	})
+	require.NoError(t, err)
+
+	got, err := svc.Get(t.Context(), assistant.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1200), got.PromptTokens)
+	require.Equal(t, int64(80), got.CompletionTokens)
+	require.Equal(t, 1500*time.Millisecond, got.Latency)
+
+	got, err = svc.Get(t.Context(), result.ID)
+	require.NoError(t, err)
+	require.Equal(t, assistant.ID, got.ParentMessageID)
+
+	pinned, err := svc.SetPinned(t.Context(), assistant.ID, true)
+	require.NoError(t, err)
+	require.True(t, pinned.Pinned)
+	// Updates from the agent leave the pin alone.
+	require.NoError(t, svc.Update(t.Context(), assistant))
+	got, err = svc.Get(t.Context(), assistant.ID)
+	require.NoError(t, err)
+	require.True(t, got.Pinned)
+
+	results, err = svc.Search(t.Context(), "throttling", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, assistant.ID, results[0].MessageID)
+	require.Contains(t, results[0].Snippet, "throttling")
+
+	// The index is stemmed.
+	results, err = svc.Search(t.Context(), "limits", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, User, results[0].Role)
+
+	require.NoError(t, svc.DeleteSessionMessages(t.Context(), "session"))
+	results, err = svc.Search(t.Context(), "throttling", 10)
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	_, _, err = db.Vacuum(t.Context(), conn, dataDir)
+	require.NoError(t, err)
+}
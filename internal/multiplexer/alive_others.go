@@ -0,0 +1,14 @@
+//go:build !windows
+
+package multiplexer
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether the process is still running.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,212 @@
+// Package multiplexer runs commands in a new pane or window of tmux or
+// zellij, waiting for them to exit, so interactive programs like editors
+// don't have to take over the terminal Crush runs in.
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is a terminal multiplexer.
+type Kind string
+
+const (
+	// None runs commands in the terminal Crush runs in.
+	None   Kind = ""
+	Tmux   Kind = "tmux"
+	Zellij Kind = "zellij"
+)
+
+// Auto detects the multiplexer Crush runs in.
+const Auto = "auto"
+
+const (
+	// pollInterval is how often the exit status of the command is checked.
+	pollInterval = 200 * time.Millisecond
+	// startTimeout is how long the shell of the pane has to start.
+	startTimeout = 10 * time.Second
+)
+
+// Detect returns the multiplexer commands should run in given the
+// configured preference. Auto picks the one Crush runs in, going by $TMUX
+// and $ZELLIJ, and a multiplexer Crush doesn't run in is never used.
+func Detect(preference string) Kind {
+	inTmux := os.Getenv("TMUX") != ""
+	inZellij := os.Getenv("ZELLIJ") != ""
+	switch preference {
+	case Auto:
+		switch {
+		case inTmux:
+			return Tmux
+		case inZellij:
+			return Zellij
+		}
+	case string(Tmux):
+		if inTmux {
+			return Tmux
+		}
+	case string(Zellij):
+		if inZellij {
+			return Zellij
+		}
+	}
+	return None
+}
+
+// Run runs the shell command in a new pane, or a new window when window is
+// set, with env added to its environment, and waits for it to exit. A
+// non-zero exit status is returned as an error, like exec.Cmd.Run does.
+func Run(ctx context.Context, kind Kind, window bool, command, dir string, env []string) error {
+	tmp, err := os.MkdirTemp("", "crush-pane-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	status := filepath.Join(tmp, "status")
+
+	var cmd *exec.Cmd
+	switch kind {
+	case Tmux:
+		args := []string{"split-window", "-v"}
+		if window {
+			args = []string{"new-window"}
+		}
+		args = append(args, "-P", "-F", "#{pane_id}", "-c", dir, "sh", "-c", script(command, status, env))
+		cmd = exec.CommandContext(ctx, "tmux", args...)
+	case Zellij:
+		if window {
+			// Zellij tabs can't run a command directly, a floating pane
+			// is the closest thing.
+			cmd = exec.CommandContext(ctx, "zellij", "run", "--floating", "--close-on-exit", "--cwd", dir, "--", "sh", "-c", script(command, status, env))
+		} else {
+			cmd = exec.CommandContext(ctx, "zellij", "run", "--close-on-exit", "--cwd", dir, "--", "sh", "-c", script(command, status, env))
+		}
+	default:
+		return fmt.Errorf("unsupported multiplexer %q", kind)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to open a %s pane: %w", kind, err)
+	}
+	paneID := strings.TrimSpace(string(out))
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	started := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if done, err := readStatus(status); done {
+			return err
+		}
+		closed := false
+		switch kind {
+		case Tmux:
+			closed = !tmuxPaneExists(ctx, paneID)
+		case Zellij:
+			// Zellij doesn't tell which pane it opened, so the shell of
+			// the pane is watched instead.
+			pid, ok := readPID(status + ".pid")
+			if !ok {
+				if time.Since(started) > startTimeout {
+					return errors.New("the pane didn't start")
+				}
+				continue
+			}
+			closed = !processAlive(pid)
+		}
+		if closed {
+			// The command may have finished right before the pane closed.
+			if done, err := readStatus(status); done {
+				return err
+			}
+			return errors.New("the pane was closed before the command finished")
+		}
+	}
+}
+
+// readStatus reads the exit status the script wrote, reporting whether it
+// did.
+func readStatus(status string) (bool, error) {
+	data, err := os.ReadFile(status)
+	if err == nil {
+		return true, exitError(strings.TrimSpace(string(data)))
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return true, err
+	}
+	return false, nil
+}
+
+// readPID reads the process ID the script wrote, once it started.
+func readPID(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, err == nil
+}
+
+// script runs the command in its own shell, so an exit in it doesn't skip
+// the rest, and writes its exit status to the status file, renaming it into
+// place so it is never read half written. The process ID of the script is
+// written next to it first, for Run to notice the pane closing. The pane
+// gets the environment of the multiplexer rather than the one of Crush, so
+// env is set by env(1).
+func script(command, status string, env []string) string {
+	quoted := quote(status)
+	pid := quote(status + ".pid")
+	run := "sh -c " + quote(command)
+	if len(env) > 0 {
+		vars := make([]string, len(env))
+		for i, v := range env {
+			vars[i] = quote(v)
+		}
+		run = "env " + strings.Join(vars, " ") + " " + run
+	}
+	return "echo $$ > " + pid + ".tmp && mv " + pid + ".tmp " + pid + "; " +
+		run + "; echo $? > " + quoted + ".tmp && mv " + quoted + ".tmp " + quoted
+}
+
+func exitError(status string) error {
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return fmt.Errorf("invalid exit status %q", status)
+	}
+	if code != 0 {
+		return fmt.Errorf("exit status %d", code)
+	}
+	return nil
+}
+
+func tmuxPaneExists(ctx context.Context, paneID string) bool {
+	if paneID == "" {
+		return true
+	}
+	out, err := exec.CommandContext(ctx, "tmux", "list-panes", "-a", "-F", "#{pane_id}").Output()
+	if err != nil {
+		return true
+	}
+	for line := range strings.Lines(string(out)) {
+		if strings.TrimSpace(line) == paneID {
+			return true
+		}
+	}
+	return false
+}
+
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
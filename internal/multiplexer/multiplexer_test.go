@@ -0,0 +1,82 @@
+package multiplexer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		tmux       string
+		zellij     string
+		preference string
+		want       Kind
+	}{
+		{"disabled", "/tmp/tmux-1000/default,1,0", "", "", None},
+		{"auto in tmux", "/tmp/tmux-1000/default,1,0", "", Auto, Tmux},
+		{"auto in zellij", "", "0", Auto, Zellij},
+		{"auto outside", "", "", Auto, None},
+		{"tmux outside tmux", "", "0", "tmux", None},
+		{"zellij in zellij", "", "0", "zellij", Zellij},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TMUX", tt.tmux)
+			t.Setenv("ZELLIJ", tt.zellij)
+			require.Equal(t, tt.want, Detect(tt.preference))
+		})
+	}
+}
+
+func TestScript(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	status := filepath.Join(t.TempDir(), "it's status")
+	err := exec.Command("sh", "-c", script("echo hi >/dev/null; exit 3", status, nil)).Run()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(status)
+	require.NoError(t, err)
+	require.EqualError(t, exitError(string(data[:len(data)-1])), "exit status 3")
+	require.NoError(t, exitError("0"))
+
+	err = exec.Command("sh", "-c", script(`test "$GH_HOST" = "it's.example.com"`, status, []string{"GH_HOST=it's.example.com"})).Run()
+	require.NoError(t, err)
+	data, err = os.ReadFile(status)
+	require.NoError(t, err)
+	require.Equal(t, "0\n", string(data), "the environment is set for the command")
+
+	_, ok := readPID(status + ".pid")
+	require.True(t, ok, "the script tells its process ID")
+}
+
+func TestRunZellijPaneClosed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("zellij doesn't run on windows")
+	}
+
+	// The fake zellij opens the "pane" in the background and closes it
+	// while the command is still running.
+	bin := t.TempDir()
+	fake := `#!/bin/sh
+while [ "$1" != "--" ]; do shift; done
+shift
+"$@" >/dev/null 2>&1 &
+sleep 0.5
+kill -9 $!
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "zellij"), []byte(fake), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	err := Run(t.Context(), Zellij, false, "sleep 5", t.TempDir(), nil)
+	require.EqualError(t, err, "the pane was closed before the command finished")
+}
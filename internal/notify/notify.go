@@ -0,0 +1,83 @@
+// Package notify sends desktop notifications, either through the terminal
+// with OSC 777 or with terminal-notifier or notify-send.
+package notify
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Method is a way of sending notifications.
+type Method string
+
+const (
+	// MethodAuto picks terminal-notifier on macOS and notify-send on Linux
+	// when installed, falling back to OSC 777.
+	MethodAuto             Method = "auto"
+	MethodOSC777           Method = "osc777"
+	MethodTerminalNotifier Method = "terminal-notifier"
+	MethodNotifySend       Method = "notify-send"
+)
+
+// Events that can be notified of.
+const (
+	EventTurnFinished        = "turn_finished"
+	EventPermissionRequested = "permission_requested"
+	EventProcessExited       = "process_exited"
+	EventScheduledRun        = "scheduled_run"
+)
+
+// Resolve returns the method used when m is auto or empty.
+func Resolve(m Method) Method {
+	if m != MethodAuto && m != "" {
+		return m
+	}
+	switch {
+	case runtime.GOOS == "darwin" && installed("terminal-notifier"):
+		return MethodTerminalNotifier
+	case runtime.GOOS == "linux" && installed("notify-send") && (os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""):
+		return MethodNotifySend
+	default:
+		return MethodOSC777
+	}
+}
+
+// Sequence returns the OSC 777 sequence showing the notification. Inside
+// tmux it is wrapped to be passed through to the outer terminal.
+func Sequence(title, body string) string {
+	seq := "\x1b]777;notify;" + sanitize(title) + ";" + sanitize(body) + "\x07"
+	if os.Getenv("TMUX") != "" {
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// Command returns the command showing the notification with an external
+// program, or nil for OSC 777.
+func Command(m Method, title, body string) *exec.Cmd {
+	switch m {
+	case MethodTerminalNotifier:
+		return exec.Command("terminal-notifier", "-title", title, "-message", body, "-group", "crush")
+	case MethodNotifySend:
+		return exec.Command("notify-send", "--app-name=Crush", title, body)
+	default:
+		return nil
+	}
+}
+
+// sanitize drops the characters that would end the sequence early.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ';' || r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+func installed(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
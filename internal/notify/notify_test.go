@@ -0,0 +1,23 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequence(t *testing.T) {
+	t.Setenv("TMUX", "")
+	require.Equal(t, "\x1b]777;notify;Crush;Done  next\x07", Sequence("Crush", "Done; next"))
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	require.Equal(t, "\x1bPtmux;\x1b\x1b]777;notify;Crush;Done\x07\x1b\\", Sequence("Crush", "Done"))
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, Command(MethodOSC777, "Crush", "Done"))
+	require.Equal(t, []string{"notify-send", "--app-name=Crush", "Crush", "Done"}, Command(MethodNotifySend, "Crush", "Done").Args)
+	require.Equal(t, MethodNotifySend, Resolve(MethodNotifySend))
+}
@@ -0,0 +1,325 @@
+// Package mcpauth implements the OAuth authorization code flow with PKCE used
+// by MCP servers that require authorization.
+package mcpauth
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/oauth"
+)
+
+// Metadata is the subset of the OAuth authorization server metadata (RFC
+// 8414) needed to authorize against an MCP server.
+type Metadata struct {
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	RegistrationEndpoint  string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported       []string `json:"scopes_supported,omitempty"`
+}
+
+// Client describes an OAuth client registered with an MCP server.
+type Client struct {
+	ClientID         string   `json:"client_id"`
+	ClientSecret     string   `json:"client_secret,omitempty"`
+	AuthorizationURL string   `json:"authorization_url"`
+	TokenURL         string   `json:"token_url"`
+	Scopes           []string `json:"scopes,omitempty"`
+}
+
+// Credentials are persisted after a successful authorization so the token
+// can be refreshed without user interaction.
+type Credentials struct {
+	Client Client       `json:"client"`
+	Token  *oauth.Token `json:"token"`
+}
+
+// NeedsRefresh reports whether the access token is about to expire. Tokens
+// without an expiry never need refreshing.
+func (c Credentials) NeedsRefresh() bool {
+	return c.Token != nil && c.Token.ExpiresIn > 0 && c.Token.IsExpired()
+}
+
+// Discover fetches the authorization server metadata for the MCP server at
+// serverURL.
+func Discover(ctx context.Context, serverURL string) (*Metadata, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	wellKnown := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/.well-known/oauth-authorization-server"}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("mcp oauth: failed to discover metadata: status %d", resp.StatusCode)
+	}
+
+	var meta Metadata
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, err
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" {
+		return nil, errors.New("mcp oauth: metadata is missing authorization or token endpoint")
+	}
+	return &meta, nil
+}
+
+// Register performs dynamic client registration (RFC 7591) and returns the
+// issued client ID and secret.
+func Register(ctx context.Context, registrationURL, redirectURI string) (clientID, clientSecret string, err error) {
+	body, err := json.Marshal(map[string]any{
+		"client_name":                "Crush",
+		"redirect_uris":              []string{redirectURI},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, registrationURL, strings.NewReader(string(body)))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", "", fmt.Errorf("mcp oauth: failed to register client: status %d body %q", resp.StatusCode, string(data))
+	}
+
+	var result struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", "", err
+	}
+	return result.ClientID, result.ClientSecret, nil
+}
+
+// AuthorizeURL returns the URL the user must visit to grant access.
+func (c Client) AuthorizeURL(redirectURI, state, challenge string) (string, error) {
+	u, err := url.Parse(c.AuthorizationURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.ClientID)
+	q.Set("redirect_uri", redirectURI)
+	if len(c.Scopes) > 0 {
+		q.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+	q.Set("state", state)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// ExchangeToken exchanges the authorization code for an OAuth2 token.
+func (c Client) ExchangeToken(ctx context.Context, code, verifier, redirectURI string) (*oauth.Token, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	})
+}
+
+// RefreshToken refreshes the OAuth2 token using the provided refresh token.
+func (c Client) RefreshToken(ctx context.Context, refreshToken string) (*oauth.Token, error) {
+	token, err := c.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Servers may omit the refresh token when it is not rotated.
+	token.RefreshToken = cmp.Or(token.RefreshToken, refreshToken)
+	return token, nil
+}
+
+func (c Client) token(ctx context.Context, form url.Values) (*oauth.Token, error) {
+	form.Set("client_id", c.ClientID)
+	if c.ClientSecret != "" {
+		form.Set("client_secret", c.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("mcp oauth: failed to get token: status %d body %q", resp.StatusCode, string(body))
+	}
+
+	var token oauth.Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("mcp oauth: token response has no access token")
+	}
+	token.SetExpiresAt()
+	return &token, nil
+}
+
+// Authorize runs the full authorization code flow: it starts a callback
+// server on the loopback interface, registers a client if needed, calls
+// open with the authorization URL and waits for the redirect.
+func Authorize(ctx context.Context, serverURL string, client Client, open func(string) error) (*Credentials, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	if client.AuthorizationURL == "" || client.TokenURL == "" || client.ClientID == "" {
+		meta, err := Discover(ctx, serverURL)
+		if err != nil {
+			return nil, err
+		}
+		client.AuthorizationURL = cmp.Or(client.AuthorizationURL, meta.AuthorizationEndpoint)
+		client.TokenURL = cmp.Or(client.TokenURL, meta.TokenEndpoint)
+		if client.ClientID == "" {
+			if meta.RegistrationEndpoint == "" {
+				return nil, errors.New("mcp oauth: server does not support dynamic registration, set oauth.client_id")
+			}
+			client.ClientID, client.ClientSecret, err = Register(ctx, meta.RegistrationEndpoint, redirectURI)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	verifier, challenge, err := pkce()
+	if err != nil {
+		return nil, err
+	}
+	state, _, err := pkce()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := client.AuthorizeURL(redirectURI, state, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+			q := r.URL.Query()
+			var res result
+			switch {
+			case q.Get("error") != "":
+				res.err = fmt.Errorf("mcp oauth: authorization failed: %s", cmp.Or(q.Get("error_description"), q.Get("error")))
+			case q.Get("state") != state:
+				res.err = errors.New("mcp oauth: state mismatch")
+			default:
+				res.code = q.Get("code")
+			}
+			if res.err != nil {
+				http.Error(w, res.err.Error(), http.StatusBadRequest)
+			} else {
+				fmt.Fprintln(w, "Authorization complete. You can close this window and return to Crush.")
+			}
+			select {
+			case results <- res:
+			default:
+			}
+		}),
+	}
+	go server.Serve(listener) //nolint:errcheck
+	defer server.Close()
+
+	if err := open(authURL); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-results:
+		if res.err != nil {
+			return nil, res.err
+		}
+		token, err := client.ExchangeToken(ctx, res.code, verifier, redirectURI)
+		if err != nil {
+			return nil, err
+		}
+		return &Credentials{Client: client, Token: token}, nil
+	}
+}
+
+// pkce generates a PKCE verifier and its corresponding challenge.
+func pkce() (verifier string, challenge string, err error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(bytes)
+	hash := sha256.Sum256([]byte(verifier))
+	challenge = base64.RawURLEncoding.EncodeToString(hash[:])
+	return verifier, challenge, nil
+}
@@ -0,0 +1,70 @@
+package mcpauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			_ = json.NewEncoder(w).Encode(Metadata{
+				AuthorizationEndpoint: srv.URL + "/authorize",
+				TokenEndpoint:         srv.URL + "/token",
+				RegistrationEndpoint:  srv.URL + "/register",
+			})
+		case "/register":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"client_id":"dynamic"}`))
+		case "/token":
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "dynamic", r.PostForm.Get("client_id"))
+			switch r.PostForm.Get("grant_type") {
+			case "authorization_code":
+				require.Equal(t, "the-code", r.PostForm.Get("code"))
+				require.NotEmpty(t, r.PostForm.Get("code_verifier"))
+				_, _ = w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","expires_in":3600}`))
+			case "refresh_token":
+				require.Equal(t, "refresh", r.PostForm.Get("refresh_token"))
+				_, _ = w.Write([]byte(`{"access_token":"access2","expires_in":3600}`))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	open := func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		q := u.Query()
+		require.Equal(t, "S256", q.Get("code_challenge_method"))
+		go func() {
+			resp, err := http.Get(q.Get("redirect_uri") + "?code=the-code&state=" + url.QueryEscape(q.Get("state")))
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+
+	creds, err := Authorize(t.Context(), srv.URL+"/mcp", Client{}, open)
+	require.NoError(t, err)
+	require.Equal(t, "dynamic", creds.Client.ClientID)
+	require.Equal(t, "access", creds.Token.AccessToken)
+	require.False(t, creds.NeedsRefresh())
+
+	token, err := creds.Client.RefreshToken(t.Context(), creds.Token.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, "access2", token.AccessToken)
+	require.Equal(t, "refresh", token.RefreshToken)
+}
@@ -0,0 +1,207 @@
+// Package ollama talks to a local Ollama server to list the models it has
+// and pull new ones.
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// DefaultBaseURL is where Ollama listens by default.
+const DefaultBaseURL = "http://localhost:11434"
+
+// Capabilities of models, as Ollama reports them.
+const (
+	CapabilityTools    = "tools"
+	CapabilityThinking = "thinking"
+	CapabilityVision   = "vision"
+)
+
+// Client talks to an Ollama server.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// New returns a client of the server at baseURL, which may be the URL of its
+// OpenAI-compatible API, ending with /v1.
+func New(baseURL string) *Client {
+	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	baseURL = strings.TrimSuffix(baseURL, "/v1")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{baseURL: baseURL, http: http.DefaultClient}
+}
+
+// Model is a model pulled on the server.
+type Model struct {
+	Name          string
+	Size          int64
+	Capabilities  []string
+	ContextLength int64
+}
+
+// Supports reports whether the model has capability.
+func (m Model) Supports(capability string) bool {
+	return slices.Contains(m.Capabilities, capability)
+}
+
+// Catwalk returns the model as Crush describes models.
+func (m Model) Catwalk() catwalk.Model {
+	contextWindow := cmp.Or(m.ContextLength, 8192)
+	return catwalk.Model{
+		ID:               m.Name,
+		Name:             m.Name,
+		ContextWindow:    contextWindow,
+		DefaultMaxTokens: min(contextWindow/4, 16384),
+		CanReason:        m.Supports(CapabilityThinking),
+		SupportsImages:   m.Supports(CapabilityVision),
+	}
+}
+
+// List returns the models pulled on the server, with their capabilities.
+func (c *Client) List(ctx context.Context) ([]Model, error) {
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+			Size int64  `json:"size"`
+		} `json:"models"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/tags", nil, &tags); err != nil {
+		return nil, err
+	}
+	models := make([]Model, 0, len(tags.Models))
+	for _, tag := range tags.Models {
+		model, err := c.Show(ctx, tag.Name)
+		if err != nil {
+			return nil, err
+		}
+		model.Size = tag.Size
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+// Show returns the model called name, without its size.
+func (c *Client) Show(ctx context.Context, name string) (Model, error) {
+	var show struct {
+		Capabilities []string       `json:"capabilities"`
+		ModelInfo    map[string]any `json:"model_info"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/show", map[string]string{"model": name}, &show); err != nil {
+		return Model{}, err
+	}
+	model := Model{Name: name, Capabilities: show.Capabilities}
+	// The context length is keyed by architecture, like llama.context_length.
+	for key, value := range show.ModelInfo {
+		if n, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			model.ContextLength = int64(n)
+		}
+	}
+	return model, nil
+}
+
+// Progress is the progress of a pull.
+type Progress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Fraction returns how much of the current layer was downloaded, from 0 to
+// 1, or 0 when it isn't known.
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(1, float64(p.Completed)/float64(p.Total))
+}
+
+// Pull downloads the model called name, calling progress as it goes.
+func (c *Client) Pull(ctx context.Context, name string, progress func(Progress)) error {
+	body, err := json.Marshal(map[string]any{"model": name, "stream": true})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var p Progress
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			continue
+		}
+		if p.Error != "" {
+			return errors.New(p.Error)
+		}
+		if progress != nil {
+			progress(p)
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// responseError returns the error Ollama answered with.
+func responseError(resp *http.Response) error {
+	var e struct {
+		Error string `json:"error"`
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(b, &e) == nil && e.Error != "" {
+		return fmt.Errorf("ollama: %s", e.Error)
+	}
+	return fmt.Errorf("ollama: %s", resp.Status)
+}
@@ -0,0 +1,81 @@
+package ollama
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = io.WriteString(w, `{"models":[{"name":"qwen3:8b","size":5200000000},{"name":"gemma:2b","size":1700000000}]}`)
+		case "/api/show":
+			var req struct {
+				Model string `json:"model"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req.Model == "qwen3:8b" {
+				_, _ = io.WriteString(w, `{"capabilities":["completion","tools","thinking"],"model_info":{"qwen3.context_length":40960}}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"capabilities":["completion"],"model_info":{"gemma.context_length":8192}}`)
+		case "/api/pull":
+			var req struct {
+				Model string `json:"model"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req.Model == "missing" {
+				_, _ = io.WriteString(w, `{"status":"pulling manifest"}`+"\n"+`{"error":"pull model manifest: file does not exist"}`+"\n")
+				return
+			}
+			_, _ = io.WriteString(w, `{"status":"pulling manifest"}`+"\n"+
+				`{"status":"pulling 1a2b","total":100,"completed":50}`+"\n"+
+				`{"status":"success"}`+"\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	models, err := New(newServer(t).URL + "/v1/").List(t.Context())
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+
+	require.Equal(t, "qwen3:8b", models[0].Name)
+	require.True(t, models[0].Supports(CapabilityTools))
+	require.Equal(t, int64(40960), models[0].ContextLength)
+	m := models[0].Catwalk()
+	require.Equal(t, "qwen3:8b", m.ID)
+	require.Equal(t, int64(40960), m.ContextWindow)
+	require.True(t, m.CanReason)
+
+	require.False(t, models[1].Supports(CapabilityTools))
+}
+
+func TestPull(t *testing.T) {
+	t.Parallel()
+
+	c := New(newServer(t).URL)
+	var statuses []string
+	var fraction float64
+	require.NoError(t, c.Pull(t.Context(), "qwen3:8b", func(p Progress) {
+		statuses = append(statuses, p.Status)
+		fraction = max(fraction, p.Fraction())
+	}))
+	require.Equal(t, []string{"pulling manifest", "pulling 1a2b", "success"}, statuses)
+	require.Equal(t, 0.5, fraction)
+
+	err := c.Pull(t.Context(), "missing", nil)
+	require.EqualError(t, err, "pull model manifest: file does not exist")
+}
@@ -0,0 +1,263 @@
+// Package patch applies hunks of changes to text, anchoring them on their
+// context lines and tolerating small differences from the text.
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultMinSimilarity is how similar the lines of a hunk must be to the
+// text they replace, by default.
+const DefaultMinSimilarity = 0.8
+
+// Kinds of lines of a hunk.
+const (
+	Context = ' '
+	Removed = '-'
+	Added   = '+'
+)
+
+// Line is a line of a hunk.
+type Line struct {
+	Kind byte
+	Text string
+}
+
+// Hunk is a change anchored on the lines around it.
+type Hunk struct {
+	// Start is the 1-based line the hunk starts at in the text, as given by
+	// its header. It is only a hint, breaking ties between the places the
+	// hunk matches; 0 when unknown.
+	Start int
+	Lines []Line
+}
+
+// old returns the context and removed lines, that the hunk matches.
+func (h Hunk) old() []string {
+	var lines []string
+	for _, l := range h.Lines {
+		if l.Kind != Added {
+			lines = append(lines, l.Text)
+		}
+	}
+	return lines
+}
+
+// Match is where a hunk was applied.
+type Match struct {
+	// Line is the 1-based line of the text the hunk matched.
+	Line int
+	// Similarity is how similar the lines were, from 0 to 1.
+	Similarity float64
+}
+
+// Exact reports whether the hunk matched the text exactly.
+func (m Match) Exact() bool {
+	return m.Similarity == 1
+}
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// Parse reads hunks in the format of unified diffs: lines starting with a
+// space are context, with "-" removed and with "+" added. "@@" lines start
+// new hunks, giving where they start; their line numbers are optional, as
+// in "@@ @@". A hunk only adding lines adds them at its start line. File
+// headers are skipped, and empty lines are taken as empty context lines.
+func Parse(patch string) ([]Hunk, error) {
+	var hunks []Hunk
+	var current *Hunk
+	lines := strings.Split(strings.TrimRight(patch, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			hunks = append(hunks, Hunk{})
+			current = &hunks[len(hunks)-1]
+			if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+				current.Start, _ = strconv.Atoi(m[1])
+			}
+			continue
+		case current == nil && (strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ")):
+			continue
+		case line == `\ No newline at end of file`:
+			continue
+		}
+		if current == nil {
+			hunks = append(hunks, Hunk{})
+			current = &hunks[len(hunks)-1]
+		}
+		if line == "" {
+			current.Lines = append(current.Lines, Line{Kind: Context})
+			continue
+		}
+		switch line[0] {
+		case Context, Removed, Added:
+			current.Lines = append(current.Lines, Line{Kind: line[0], Text: line[1:]})
+		default:
+			return nil, fmt.Errorf("line %d: %q doesn't start with a space, - or +", i+1, line)
+		}
+	}
+	hunks = trimHunks(hunks)
+	if len(hunks) == 0 {
+		return nil, errors.New("the patch has no changes")
+	}
+	return hunks, nil
+}
+
+// trimHunks drops the empty context lines ending hunks, left by blank lines
+// between them, and the hunks without changes.
+func trimHunks(hunks []Hunk) []Hunk {
+	var trimmed []Hunk
+	for _, h := range hunks {
+		for len(h.Lines) > 0 && h.Lines[len(h.Lines)-1] == (Line{Kind: Context}) {
+			h.Lines = h.Lines[:len(h.Lines)-1]
+		}
+		changes := false
+		for _, l := range h.Lines {
+			changes = changes || l.Kind != Context
+		}
+		if changes {
+			trimmed = append(trimmed, h)
+		}
+	}
+	return trimmed
+}
+
+// Apply applies the hunks to content one after the other. A hunk applies
+// where its context and removed lines match the text, exactly or else with
+// a similarity of at least minSimilarity; the context lines of the text are
+// kept as they are. It fails when a hunk matches nowhere, or several places
+// equally well without a start line to tell them apart.
+func Apply(content string, hunks []Hunk, minSimilarity float64) (string, []Match, error) {
+	lines := strings.Split(content, "\n")
+	matches := make([]Match, 0, len(hunks))
+	// offset is how many lines the hunks applied so far added.
+	offset := 0
+	for i, h := range hunks {
+		hint := 0
+		if h.Start > 0 {
+			hint = h.Start - 1 + offset
+		}
+		old := h.old()
+		var m Match
+		var at int
+		if len(old) == 0 {
+			if h.Start == 0 {
+				return "", nil, fmt.Errorf("hunk %d only adds lines: give it context lines, or a start line in its @@ header", i+1)
+			}
+			at, m = min(hint, len(lines)), Match{Similarity: 1}
+		} else {
+			var err error
+			at, m, err = find(lines, old, hint, minSimilarity)
+			if err != nil {
+				return "", nil, fmt.Errorf("hunk %d: %w", i+1, err)
+			}
+		}
+		m.Line = at + 1
+		matches = append(matches, m)
+
+		var replacement []string
+		pos := at
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case Context:
+				replacement = append(replacement, lines[pos])
+				pos++
+			case Removed:
+				pos++
+			case Added:
+				replacement = append(replacement, l.Text)
+			}
+		}
+		lines = append(lines[:at], append(replacement, lines[pos:]...)...)
+		offset += len(replacement) - (pos - at)
+	}
+	return strings.Join(lines, "\n"), matches, nil
+}
+
+// find returns where old matches lines best.
+func find(lines, old []string, hint int, minSimilarity float64) (int, Match, error) {
+	best, bestScore := -1, 0.0
+	ambiguous := false
+	for at := 0; at+len(old) <= len(lines); at++ {
+		score := windowSimilarity(lines[at:at+len(old)], old, max(bestScore, minSimilarity))
+		switch {
+		case score < minSimilarity || score < bestScore:
+			continue
+		case score == bestScore:
+			ambiguous = true
+			if abs(at-hint) < abs(best-hint) {
+				best = at
+			}
+		default:
+			best, bestScore, ambiguous = at, score, false
+		}
+	}
+	switch {
+	case best < 0:
+		return 0, Match{}, fmt.Errorf("no lines match with a similarity of at least %.0f%%: view the file again and copy its lines exactly", minSimilarity*100)
+	case ambiguous && hint == 0:
+		return 0, Match{}, errors.New("the lines match several places: add context lines, or a start line in the @@ header")
+	}
+	return best, Match{Similarity: bestScore}, nil
+}
+
+// windowSimilarity returns the average similarity of the lines, giving up
+// with 0 as soon as it can't reach floor.
+func windowSimilarity(lines, old []string, floor float64) float64 {
+	total := 0.0
+	for i := range old {
+		total += lineSimilarity(lines[i], old[i])
+		// Even if the remaining lines were identical.
+		if (total+float64(len(old)-i-1))/float64(len(old)) < floor {
+			return 0
+		}
+	}
+	return total / float64(len(old))
+}
+
+// lineSimilarity returns how similar two lines are, from 0 to 1. Lines only
+// differing by their indentation or trailing spaces are almost identical.
+func lineSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == b {
+		return 0.99
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
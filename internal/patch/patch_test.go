@@ -0,0 +1,131 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const source = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hello")
+}
+
+func other() {
+	fmt.Println("hello")
+}
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	hunks, err := Parse(`--- a/main.go
++++ b/main.go
+@@ -5,3 +5,3 @@
+ func main() {
+-	fmt.Println("hello")
++	fmt.Println("bye")
+ }
+
+@@ @@
+ import "fmt"
++import "os"
+`)
+	require.NoError(t, err)
+	require.Len(t, hunks, 2)
+	require.Equal(t, 5, hunks[0].Start)
+	require.Equal(t, []Line{
+		{Kind: Context, Text: "func main() {"},
+		{Kind: Removed, Text: "\tfmt.Println(\"hello\")"},
+		{Kind: Added, Text: "\tfmt.Println(\"bye\")"},
+		{Kind: Context, Text: "}"},
+	}, hunks[0].Lines)
+	require.Zero(t, hunks[1].Start)
+
+	_, err = Parse(" context only\n")
+	require.Error(t, err)
+	_, err = Parse("no prefix\n")
+	require.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	t.Run("exact", func(t *testing.T) {
+		t.Parallel()
+		hunks, err := Parse(" func main() {\n-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n")
+		require.NoError(t, err)
+		got, matches, err := Apply(source, hunks, DefaultMinSimilarity)
+		require.NoError(t, err)
+		require.Contains(t, got, "func main() {\n\tfmt.Println(\"bye\")\n}")
+		require.Contains(t, got, "func other() {\n\tfmt.Println(\"hello\")\n}")
+		require.Equal(t, []Match{{Line: 5, Similarity: 1}}, matches)
+	})
+
+	t.Run("fuzzy keeps the context of the text", func(t *testing.T) {
+		t.Parallel()
+		hunks, err := Parse(" func other () {\n-    fmt.Println(\"hello\")\n+\tfmt.Println(\"other\")\n")
+		require.NoError(t, err)
+		got, matches, err := Apply(source, hunks, DefaultMinSimilarity)
+		require.NoError(t, err)
+		require.Contains(t, got, "func other() {\n\tfmt.Println(\"other\")\n}")
+		require.Equal(t, 9, matches[0].Line)
+		require.False(t, matches[0].Exact())
+	})
+
+	t.Run("below the threshold", func(t *testing.T) {
+		t.Parallel()
+		hunks, err := Parse(" func somethingElse(a, b int) {\n-\treturn a + b\n")
+		require.NoError(t, err)
+		_, _, err = Apply(source, hunks, DefaultMinSimilarity)
+		require.ErrorContains(t, err, "no lines match")
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		t.Parallel()
+		hunks, err := Parse("-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n")
+		require.NoError(t, err)
+		_, _, err = Apply(source, hunks, DefaultMinSimilarity)
+		require.ErrorContains(t, err, "several places")
+
+		// The start line breaks the tie.
+		hunks[0].Start = 9
+		got, _, err := Apply(source, hunks, DefaultMinSimilarity)
+		require.NoError(t, err)
+		require.Contains(t, got, "func other() {\n\tfmt.Println(\"bye\")\n}")
+	})
+
+	t.Run("several hunks", func(t *testing.T) {
+		t.Parallel()
+		hunks, err := Parse(`@@ -3,1 +3,2 @@
+ import "fmt"
++import "os"
+@@ -9,2 +10,2 @@
+ func other() {
+-	fmt.Println("hello")
++	os.Exit(1)
+`)
+		require.NoError(t, err)
+		got, matches, err := Apply(source, hunks, DefaultMinSimilarity)
+		require.NoError(t, err)
+		require.Contains(t, got, "import \"fmt\"\nimport \"os\"\n")
+		require.Contains(t, got, "func other() {\n\tos.Exit(1)\n}")
+		require.Equal(t, 10, matches[1].Line)
+	})
+
+	t.Run("additions need a start line", func(t *testing.T) {
+		t.Parallel()
+		hunks, err := Parse("+// Package main says hello.\n")
+		require.NoError(t, err)
+		_, _, err = Apply(source, hunks, DefaultMinSimilarity)
+		require.Error(t, err)
+
+		hunks[0].Start = 1
+		got, _, err := Apply(source, hunks, DefaultMinSimilarity)
+		require.NoError(t, err)
+		require.Equal(t, "// Package main says hello.\n"+source, got)
+	})
+}
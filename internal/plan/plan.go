@@ -0,0 +1,126 @@
+// Package plan handles plan proposals made by the agent and their review
+// by the user before any work is carried out.
+package plan
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/google/uuid"
+)
+
+// ErrPlanRejected is returned when the user rejects a proposed plan.
+var ErrPlanRejected = errors.New("user rejected the plan")
+
+// Request is a plan proposed by the agent that is waiting for review.
+type Request struct {
+	ID         string   `json:"id"`
+	SessionID  string   `json:"session_id"`
+	ToolCallID string   `json:"tool_call_id"`
+	Steps      []string `json:"steps"`
+}
+
+type response struct {
+	steps    []string
+	approved bool
+}
+
+type Service interface {
+	pubsub.Subscriber[Request]
+	// Propose blocks until the user approves or rejects the plan, returning
+	// the steps as edited by the user. Without a user to review it, the
+	// plan is approved as proposed.
+	Propose(ctx context.Context, sessionID, toolCallID string, steps []string) ([]string, error)
+	Approve(request Request, steps []string)
+	Reject(request Request)
+	// SetInteractive tells whether a user is around to review the plans,
+	// i.e. the TUI is running.
+	SetInteractive(interactive bool)
+	// Approved reports whether the last plan of the session was approved,
+	// since plan mode was last toggled.
+	Approved(sessionID string) bool
+	SetEnabled(enabled bool)
+	Enabled() bool
+}
+
+type service struct {
+	*pubsub.Broker[Request]
+
+	pending     *csync.Map[string, chan response]
+	approved    *csync.Map[string, bool]
+	interactive atomic.Bool
+	enabled     atomic.Bool
+}
+
+func NewService() Service {
+	return &service{
+		Broker:   pubsub.NewBroker[Request](),
+		pending:  csync.NewMap[string, chan response](),
+		approved: csync.NewMap[string, bool](),
+	}
+}
+
+func (s *service) Propose(ctx context.Context, sessionID, toolCallID string, steps []string) ([]string, error) {
+	s.approved.Del(sessionID)
+	if !s.interactive.Load() {
+		s.approved.Set(sessionID, true)
+		return steps, nil
+	}
+
+	request := Request{
+		ID:         uuid.New().String(),
+		SessionID:  sessionID,
+		ToolCallID: toolCallID,
+		Steps:      steps,
+	}
+
+	respCh := make(chan response, 1)
+	s.pending.Set(request.ID, respCh)
+	defer s.pending.Del(request.ID)
+
+	s.Publish(pubsub.CreatedEvent, request)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp := <-respCh:
+		if !resp.approved {
+			return nil, ErrPlanRejected
+		}
+		s.approved.Set(sessionID, true)
+		return resp.steps, nil
+	}
+}
+
+func (s *service) Approve(request Request, steps []string) {
+	if respCh, ok := s.pending.Get(request.ID); ok {
+		respCh <- response{steps: steps, approved: true}
+	}
+}
+
+func (s *service) Reject(request Request) {
+	if respCh, ok := s.pending.Get(request.ID); ok {
+		respCh <- response{}
+	}
+}
+
+func (s *service) SetInteractive(interactive bool) {
+	s.interactive.Store(interactive)
+}
+
+func (s *service) Approved(sessionID string) bool {
+	approved, _ := s.approved.Get(sessionID)
+	return approved
+}
+
+func (s *service) SetEnabled(enabled bool) {
+	s.enabled.Store(enabled)
+	s.approved.Reset(map[string]bool{})
+}
+
+func (s *service) Enabled() bool {
+	return s.enabled.Load()
+}
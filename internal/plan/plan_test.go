@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Propose(t *testing.T) {
+	t.Parallel()
+
+	t.Run("approved with edits", func(t *testing.T) {
+		t.Parallel()
+
+		svc := NewService()
+		svc.SetInteractive(true)
+		events := svc.Subscribe(t.Context())
+
+		go func() {
+			event := <-events
+			require.Equal(t, []string{"one", "two"}, event.Payload.Steps)
+			svc.Approve(event.Payload, []string{"two", "three"})
+		}()
+
+		steps, err := svc.Propose(t.Context(), "session", "call", []string{"one", "two"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"two", "three"}, steps)
+		require.True(t, svc.Approved("session"))
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		t.Parallel()
+
+		svc := NewService()
+		svc.SetInteractive(true)
+		events := svc.Subscribe(t.Context())
+
+		go func() {
+			event := <-events
+			svc.Reject(event.Payload)
+		}()
+
+		_, err := svc.Propose(t.Context(), "session", "call", []string{"one"})
+		require.ErrorIs(t, err, ErrPlanRejected)
+		require.False(t, svc.Approved("session"))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		svc := NewService()
+		svc.SetInteractive(true)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err := svc.Propose(ctx, "session", "call", []string{"one"})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestService_ProposeWithoutUser(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService()
+	steps, err := svc.Propose(t.Context(), "session", "call", []string{"one"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"one"}, steps)
+	require.True(t, svc.Approved("session"))
+
+	svc.SetEnabled(true)
+	require.False(t, svc.Approved("session"), "toggling plan mode asks for a new plan")
+}
+
+func TestService_Enabled(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService()
+	require.False(t, svc.Enabled())
+	svc.SetEnabled(true)
+	require.True(t, svc.Enabled())
+}
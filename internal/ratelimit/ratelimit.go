@@ -0,0 +1,298 @@
+// Package ratelimit keeps track of the rate limits providers report, holding
+// back requests that would exceed them until they reset, and optionally
+// retrying the ones rate limited anyway.
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+const (
+	// maxWait is the longest a request is held back for; past that, the
+	// rate limited response is returned as is.
+	maxWait = 2 * time.Minute
+	// maxRetries is how many times a rate limited request is sent again.
+	maxRetries = 3
+	// retryDelay is how long to wait for a rate limited request to be sent
+	// again when the provider didn't say.
+	retryDelay = 5 * time.Second
+)
+
+var broker = pubsub.NewBroker[Event]()
+
+// Event is published when requests to a provider are held back until Until.
+type Event struct {
+	Host  string
+	Until time.Time
+	// Reason is what ran out: requests or tokens.
+	Reason string
+}
+
+// Subscribe returns a channel receiving when requests are held back.
+func Subscribe(ctx context.Context) <-chan pubsub.Event[Event] {
+	return broker.Subscribe(ctx)
+}
+
+// limit is what is left of a rate limit, until it resets.
+type limit struct {
+	remaining int64
+	reset     time.Time
+}
+
+// known reports whether the limit was reported and hasn't reset yet.
+func (l limit) known(now time.Time) bool {
+	return !l.reset.IsZero() && now.Before(l.reset)
+}
+
+// state is what a provider reported about its limits.
+type state struct {
+	requests limit
+	tokens   limit
+	// blocked is until when the provider refuses requests, after a rate
+	// limited one.
+	blocked time.Time
+}
+
+// Limiter tracks the rate limits of each provider, by host.
+type Limiter struct {
+	mu     sync.Mutex
+	hosts  map[string]*state
+	now    func() time.Time
+	sleep  func(context.Context, time.Duration) error
+	notify func(Event)
+}
+
+// New returns a limiter knowing no limits yet.
+func New() *Limiter {
+	return &Limiter{
+		hosts: make(map[string]*state),
+		now:   time.Now,
+		sleep: sleep,
+		notify: func(e Event) {
+			broker.Publish(pubsub.UpdatedEvent, e)
+		},
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wait returns how long a request of about tokens tokens to host has to
+// wait for the limits to reset, and why.
+func (l *Limiter) wait(host string, tokens int64) (time.Duration, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.hosts[host]
+	if !ok {
+		return 0, ""
+	}
+	now := l.now()
+	switch {
+	case now.Before(s.blocked):
+		return s.blocked.Sub(now), "rate limited"
+	case s.requests.known(now) && s.requests.remaining <= 0:
+		return s.requests.reset.Sub(now), "requests"
+	case s.tokens.known(now) && s.tokens.remaining < tokens:
+		return s.tokens.reset.Sub(now), "tokens"
+	}
+	return 0, ""
+}
+
+// reserve counts a request of about tokens tokens against the limits of
+// host, until the provider reports them again.
+func (l *Limiter) reserve(host string, tokens int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if s, ok := l.hosts[host]; ok {
+		s.requests.remaining--
+		s.tokens.remaining -= tokens
+	}
+}
+
+// update records the limits reported in the response of host.
+func (l *Limiter) update(host string, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.hosts[host]
+	if !ok {
+		s = &state{}
+		l.hosts[host] = s
+	}
+	now := l.now()
+	if requests, ok := parseLimit(resp.Header, now, "requests"); ok {
+		s.requests = requests
+	}
+	if tokens, ok := parseLimit(resp.Header, now, "input-tokens", "tokens"); ok {
+		s.tokens = tokens
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		s.blocked = now.Add(retryAfter(resp.Header, now, s))
+	}
+}
+
+// Wait blocks until a request of about tokens tokens to host fits in its
+// limits, or ctx is done.
+func (l *Limiter) Wait(ctx context.Context, host string, tokens int64) error {
+	for {
+		d, reason := l.wait(host, tokens)
+		if d <= 0 {
+			l.reserve(host, tokens)
+			return nil
+		}
+		if d > maxWait {
+			// Better to let the provider answer than to hang for that long.
+			return nil
+		}
+		l.notify(Event{Host: host, Until: l.now().Add(d), Reason: reason})
+		if err := l.sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// parseLimit reads the remaining amount of the first of kinds the headers
+// report, and when it resets. Both Anthropic's and OpenAI's headers are
+// understood.
+func parseLimit(h http.Header, now time.Time, kinds ...string) (limit, bool) {
+	for _, kind := range kinds {
+		for _, names := range [][2]string{
+			{"anthropic-ratelimit-" + kind + "-remaining", "anthropic-ratelimit-" + kind + "-reset"},
+			{"x-ratelimit-remaining-" + kind, "x-ratelimit-reset-" + kind},
+		} {
+			remaining, err := strconv.ParseInt(h.Get(names[0]), 10, 64)
+			if err != nil {
+				continue
+			}
+			reset, ok := parseReset(h.Get(names[1]), now)
+			if !ok {
+				continue
+			}
+			return limit{remaining: remaining, reset: reset}, true
+		}
+	}
+	return limit{}, false
+}
+
+// parseReset reads when a limit resets: a time, a duration such as "6m0s",
+// or a number of seconds.
+func parseReset(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d), true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return now.Add(time.Duration(seconds * float64(time.Second))), true
+	}
+	return time.Time{}, false
+}
+
+// retryAfter returns how long to wait after a rate limited response: what
+// the provider said, or else until the exhausted limit resets.
+func retryAfter(h http.Header, now time.Time, s *state) time.Duration {
+	if ms, err := strconv.ParseFloat(h.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	if value := h.Get("retry-after"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+			return time.Duration(seconds * float64(time.Second))
+		}
+		if t, err := http.ParseTime(value); err == nil && t.After(now) {
+			return t.Sub(now)
+		}
+	}
+	for _, l := range []limit{s.requests, s.tokens} {
+		if l.known(now) && l.remaining <= 0 {
+			return l.reset.Sub(now)
+		}
+	}
+	return retryDelay
+}
+
+// NewClient returns a copy of client, or of the default client when nil,
+// holding back its requests according to the limits l knows of, and
+// retrying rate limited ones when retry is set.
+func NewClient(client *http.Client, l *Limiter, retry bool) *http.Client {
+	var c http.Client
+	if client != nil {
+		c = *client
+	}
+	c.Transport = &Transport{Transport: c.Transport, Limiter: l, Retry: retry}
+	return &c
+}
+
+// Transport is an http.RoundTripper holding back requests that would exceed
+// the rate limits of their provider, and optionally retrying rate limited
+// ones once the provider allows it.
+type Transport struct {
+	// Transport sends the requests; http.DefaultTransport when nil.
+	Transport http.RoundTripper
+	Limiter   *Limiter
+	// Retry is whether rate limited requests are sent again. Leave it off
+	// when the caller retries them, or falls back to another provider,
+	// itself.
+	Retry bool
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	host := req.URL.Host
+	// About four bytes make a token.
+	tokens := int64(len(body) / 4)
+
+	for retry := 0; ; retry++ {
+		if err := t.Limiter.Wait(req.Context(), host, tokens); err != nil {
+			return nil, err
+		}
+		attempt := req.Clone(req.Context())
+		if body != nil {
+			attempt.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		resp, err := base.RoundTrip(attempt)
+		if err != nil {
+			return resp, err
+		}
+		t.Limiter.update(host, resp)
+		if resp.StatusCode != http.StatusTooManyRequests || !t.Retry || retry == maxRetries {
+			return resp, nil
+		}
+		if d, _ := t.Limiter.wait(host, 0); d > maxWait {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestLimiter returns a limiter whose clock only moves when it sleeps,
+// and the events it published.
+func newTestLimiter() (*Limiter, *[]Event) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []Event
+	l := New()
+	l.now = func() time.Time { return now }
+	l.sleep = func(_ context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return nil
+	}
+	l.notify = func(e Event) { events = append(events, e) }
+	return l, &events
+}
+
+func response(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("{}")),
+	}
+	for name, value := range headers {
+		resp.Header.Set(name, value)
+	}
+	return resp
+}
+
+func TestParseLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	anthropic := response(http.StatusOK, map[string]string{
+		"anthropic-ratelimit-requests-remaining": "3",
+		"anthropic-ratelimit-requests-reset":     "2026-01-01T00:00:30Z",
+	}).Header
+	l, ok := parseLimit(anthropic, now, "requests")
+	require.True(t, ok)
+	require.Equal(t, limit{remaining: 3, reset: now.Add(30 * time.Second)}, l)
+
+	openai := response(http.StatusOK, map[string]string{
+		"x-ratelimit-remaining-tokens": "1000",
+		"x-ratelimit-reset-tokens":     "6m0s",
+	}).Header
+	l, ok = parseLimit(openai, now, "input-tokens", "tokens")
+	require.True(t, ok)
+	require.Equal(t, limit{remaining: 1000, reset: now.Add(6 * time.Minute)}, l)
+
+	_, ok = parseLimit(http.Header{}, now, "requests")
+	require.False(t, ok)
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("holds back requests once none are left", func(t *testing.T) {
+		t.Parallel()
+		l, events := newTestLimiter()
+		remaining := 2
+		client := NewClient(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			remaining--
+			return response(http.StatusOK, map[string]string{
+				"x-ratelimit-remaining-requests": strconv.Itoa(remaining),
+				"x-ratelimit-reset-requests":     "10s",
+			}), nil
+		})}, l, true)
+
+		for range 2 {
+			resp, err := client.Post("https://api.example.com/v1", "application/json", strings.NewReader("{}"))
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+		}
+		require.Empty(t, *events)
+
+		// The second request took the last one left, until they reset.
+		resp, err := client.Post("https://api.example.com/v1", "application/json", strings.NewReader("{}"))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Len(t, *events, 1)
+		require.Equal(t, "api.example.com", (*events)[0].Host)
+		require.Equal(t, "requests", (*events)[0].Reason)
+	})
+
+	t.Run("retries rate limited requests", func(t *testing.T) {
+		t.Parallel()
+		l, events := newTestLimiter()
+		var bodies []string
+		client := NewClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				return response(http.StatusTooManyRequests, map[string]string{"retry-after": "3"}), nil
+			}
+			return response(http.StatusOK, nil), nil
+		})}, l, true)
+
+		resp, err := client.Post("https://api.example.com/v1", "application/json", strings.NewReader(`{"a":1}`))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, []string{`{"a":1}`, `{"a":1}`}, bodies)
+		require.Len(t, *events, 1)
+		require.Equal(t, "rate limited", (*events)[0].Reason)
+	})
+
+	t.Run("leaves rate limited requests to the caller without retry", func(t *testing.T) {
+		t.Parallel()
+		l, _ := newTestLimiter()
+		calls := 0
+		client := NewClient(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			calls++
+			return response(http.StatusTooManyRequests, map[string]string{"retry-after": "3"}), nil
+		})}, l, false)
+
+		resp, err := client.Get("https://api.example.com/v1")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("returns rate limited responses it would wait too long for", func(t *testing.T) {
+		t.Parallel()
+		l, events := newTestLimiter()
+		calls := 0
+		client := NewClient(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			calls++
+			return response(http.StatusTooManyRequests, map[string]string{"retry-after": "3600"}), nil
+		})}, l, true)
+
+		resp, err := client.Get("https://api.example.com/v1")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, 1, calls)
+		require.Empty(t, *events)
+	})
+}
@@ -0,0 +1,197 @@
+// Package redact masks secrets, such as API keys, tokens and the values of
+// .env files, in text leaving the machine.
+package redact
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/env"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/joho/godotenv"
+)
+
+// minLength is how long a value has to be to be taken for a secret.
+const minLength = 8
+
+var broker = pubsub.NewBroker[Event]()
+
+// Event is published when secrets were masked in what was sent for a
+// session.
+type Event struct {
+	SessionID string
+	// Kinds are the kinds of the secrets masked, such as "github-token".
+	Kinds []string
+	Count int
+}
+
+// Subscribe returns a channel receiving when secrets were masked.
+func Subscribe(ctx context.Context) <-chan pubsub.Event[Event] {
+	return broker.Subscribe(ctx)
+}
+
+// Publish tells the secrets masked for the session.
+func Publish(e Event) {
+	broker.Publish(pubsub.CreatedEvent, e)
+}
+
+type pattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// builtin are the secrets recognized by their shape. When a pattern has a
+// group, only the group is masked.
+var builtin = []pattern{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"anthropic-key", regexp.MustCompile(`sk-ant-[A-Za-z0-9_\-]{20,}`)},
+	{"openai-key", regexp.MustCompile(`sk-(?:proj-|svcacct-)?[A-Za-z0-9_\-]{20,}`)},
+	{"github-token", regexp.MustCompile(`(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}`)},
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`)},
+	{"google-api-key", regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`)},
+	{"slack-token", regexp.MustCompile(`xox[abposr]-[A-Za-z0-9\-]{10,}`)},
+	{"stripe-key", regexp.MustCompile(`(?:sk|rk)_live_[A-Za-z0-9]{20,}`)},
+	{"jwt", regexp.MustCompile(`eyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`)},
+	{"secret", regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|password|passwd)["']?\s*[:=]\s*["']?([A-Za-z0-9_\-./+=]{12,})`)},
+}
+
+// Finding is a secret that was masked.
+type Finding struct {
+	Kind   string
+	Secret string
+}
+
+// Redactor masks secrets. A nil Redactor masks nothing.
+type Redactor struct {
+	patterns []pattern
+	// values are secrets known by their value, longest first so that a
+	// value containing another is masked whole.
+	values []Finding
+}
+
+// New returns the redactor cfg configures, or nil when redaction is
+// disabled. It masks the built-in and configured patterns, the values of the
+// .env files of the working directory, and those of the environment
+// variables named like secrets.
+func New(cfg *config.Config) (*Redactor, error) {
+	opts := cfg.Options.Redaction
+	if opts == nil {
+		opts = &config.Redaction{}
+	}
+	if opts.Disabled {
+		return nil, nil
+	}
+
+	var patterns []pattern
+	for _, expr := range opts.Patterns {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", expr, err)
+		}
+		patterns = append(patterns, pattern{kind: "custom", re: re})
+	}
+
+	values := make(map[string]string)
+	envFiles := opts.EnvFiles
+	if len(envFiles) == 0 {
+		envFiles = []string{".env", ".env.local"}
+	}
+	for _, name := range envFiles {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(cfg.WorkingDir(), name)
+		}
+		vars, err := godotenv.Read(name)
+		if err != nil {
+			// Most projects don't have all of them.
+			continue
+		}
+		for key, value := range vars {
+			if secretValue(value) {
+				values[value] = "env:" + key
+			}
+		}
+	}
+	for _, kv := range env.New().Env() {
+		key, value, _ := strings.Cut(kv, "=")
+		if secretName(key) && secretValue(value) {
+			values[value] = "env:" + key
+		}
+	}
+	return newRedactor(patterns, values), nil
+}
+
+func newRedactor(patterns []pattern, values map[string]string) *Redactor {
+	r := &Redactor{patterns: append(slices.Clone(builtin), patterns...)}
+	for value, kind := range values {
+		r.values = append(r.values, Finding{Kind: kind, Secret: value})
+	}
+	slices.SortFunc(r.values, func(a, b Finding) int {
+		return cmp.Or(cmp.Compare(len(b.Secret), len(a.Secret)), strings.Compare(a.Secret, b.Secret))
+	})
+	return r
+}
+
+// secretName reports whether an environment variable is named like one
+// holding a secret.
+func secretName(name string) bool {
+	name = strings.ToUpper(name)
+	for _, s := range []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "CREDENTIAL"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretValue reports whether a value could be a secret: long enough and
+// neither a plain word nor a number, which would be masked everywhere.
+func secretValue(value string) bool {
+	if len(value) < minLength {
+		return false
+	}
+	letters := strings.IndexFunc(value, func(r rune) bool { return !unicode.IsLetter(r) }) < 0
+	digits := strings.IndexFunc(value, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+	return !letters && !digits
+}
+
+func mask(kind string) string {
+	return "[REDACTED:" + kind + "]"
+}
+
+// Redact returns text with its secrets masked, and the secrets found.
+func (r *Redactor) Redact(text string) (string, []Finding) {
+	if r == nil || text == "" {
+		return text, nil
+	}
+	var findings []Finding
+	for _, v := range r.values {
+		if strings.Contains(text, v.Secret) {
+			text = strings.ReplaceAll(text, v.Secret, mask(v.Kind))
+			findings = append(findings, v)
+		}
+	}
+	for _, p := range r.patterns {
+		text = p.re.ReplaceAllStringFunc(text, func(match string) string {
+			start, end := 0, len(match)
+			if loc := p.re.FindStringSubmatchIndex(match); len(loc) > 3 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			findings = append(findings, Finding{Kind: p.kind, Secret: match[start:end]})
+			return match[:start] + mask(p.kind) + match[end:]
+		})
+	}
+	return text, findings
+}
+
+// String returns text with its secrets masked.
+func (r *Redactor) String(text string) string {
+	text, _ = r.Redact(text)
+	return text
+}
@@ -0,0 +1,253 @@
+// Package remote runs commands and reads and writes files on the host a
+// remote workspace lives on, over SSH. It uses the ssh command, so the
+// hosts, keys and agents of the SSH configuration apply, and shares one
+// connection between the commands.
+package remote
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+// controlPersist is how long the shared connection stays open once the
+// last command exited.
+const controlPersist = "10m"
+
+// Host is the host of a remote workspace.
+type Host struct {
+	// Target is the SSH destination, such as user@host.
+	Target string
+	// Dir is the directory of the workspace on the host.
+	Dir string
+}
+
+// New returns the host of the remote workspace, or nil when the workspace
+// is local.
+func New(cfg *config.Config) *Host {
+	if cfg == nil || cfg.Options == nil || cfg.Options.Remote == nil || cfg.Options.Remote.Host == "" {
+		return nil
+	}
+	return &Host{
+		Target: cfg.Options.Remote.Host,
+		Dir:    cmp.Or(cfg.Options.Remote.Dir, "~"),
+	}
+}
+
+// Current returns the host of the remote workspace of the configuration
+// loaded, or nil when it's local.
+func Current() *Host {
+	return New(config.Get())
+}
+
+// Parse reads a remote workspace given as host:dir, the directory being
+// optional.
+func Parse(spec string) (config.Remote, error) {
+	host, dir, _ := strings.Cut(spec, ":")
+	if host == "" {
+		return config.Remote{}, fmt.Errorf("remote %q has no host, it should look like host:dir", spec)
+	}
+	return config.Remote{Host: host, Dir: dir}, nil
+}
+
+// String returns the host and directory, as in host:dir.
+func (h *Host) String() string {
+	return h.Target + ":" + h.Dir
+}
+
+// Abs returns p as an absolute path on the host, relative paths being
+// relative to the directory of the workspace.
+func (h *Host) Abs(p string) string {
+	if p == "" {
+		return h.Dir
+	}
+	if path.IsAbs(p) || p == "~" || strings.HasPrefix(p, "~/") {
+		return path.Clean(p)
+	}
+	return path.Join(h.Dir, p)
+}
+
+// Args returns the arguments of ssh running command in dir on the host,
+// with a terminal when tty is set.
+func (h *Host) Args(dir, command string, tty bool) []string {
+	args := []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(os.TempDir(), "crush-ssh-%C"),
+		"-o", "ControlPersist=" + controlPersist,
+	}
+	if tty {
+		args = append(args, "-t")
+	} else {
+		// Without a terminal, ssh couldn't ask for passwords anyway.
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	return append(args, h.Target, "cd "+QuotePath(h.Abs(dir))+" && {\n"+command+"\n}")
+}
+
+// Command returns the command running command in dir on the host.
+func (h *Host) Command(ctx context.Context, dir, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "ssh", h.Args(dir, command, false)...)
+}
+
+// ShellCommand returns a shell command line running command in the
+// directory of the workspace on the host, in a terminal, for interactive
+// programs.
+func (h *Host) ShellCommand(command string) string {
+	args := h.Args(h.Dir, command, true)
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+	return "ssh " + strings.Join(quoted, " ")
+}
+
+// Run runs command in dir on the host, returning its output. The error of
+// a command exiting with a non-zero code is an *exec.ExitError.
+func (h *Host) Run(ctx context.Context, dir, command string, stdin io.Reader) (string, string, error) {
+	cmd := h.Command(ctx, dir, command)
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ExitCode(err) == 255 {
+		// ssh exits with 255 when it fails to connect.
+		err = fmt.Errorf("failed to connect to %s: %s", h.Target, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), stderr.String(), err
+}
+
+// FileInfo describes a file on the host.
+type FileInfo struct {
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
+}
+
+// Stat describes the file at p, failing with fs.ErrNotExist when there's
+// none. The host needs the stat of GNU coreutils, as Linux hosts have.
+func (h *Host) Stat(ctx context.Context, p string) (FileInfo, error) {
+	out, stderr, err := h.Run(ctx, h.Dir, "stat -L -c '%s %Y %F' -- "+QuotePath(h.Abs(p)), nil)
+	if err != nil {
+		return FileInfo{}, fileError(p, stderr, err)
+	}
+	fields := strings.SplitN(strings.TrimSpace(out), " ", 3)
+	if len(fields) != 3 {
+		return FileInfo{}, fmt.Errorf("unexpected output of stat: %q", out)
+	}
+	size, _ := strconv.ParseInt(fields[0], 10, 64)
+	modTime, _ := strconv.ParseInt(fields[1], 10, 64)
+	return FileInfo{
+		Size:    size,
+		ModTime: time.Unix(modTime, 0),
+		IsDir:   fields[2] == "directory",
+	}, nil
+}
+
+// ReadFile returns the content of the file at p.
+func (h *Host) ReadFile(ctx context.Context, p string) ([]byte, error) {
+	out, stderr, err := h.Run(ctx, h.Dir, "cat -- "+QuotePath(h.Abs(p)), nil)
+	if err != nil {
+		return nil, fileError(p, stderr, err)
+	}
+	return []byte(out), nil
+}
+
+// WriteFile writes data to the file at p, creating its directory when
+// needed.
+func (h *Host) WriteFile(ctx context.Context, p string, data []byte) error {
+	abs := QuotePath(h.Abs(p))
+	dir := QuotePath(path.Dir(h.Abs(p)))
+	_, stderr, err := h.Run(ctx, h.Dir, "mkdir -p -- "+dir+" && cat > "+abs, bytes.NewReader(data))
+	if err != nil {
+		return fileError(p, stderr, err)
+	}
+	return nil
+}
+
+// fileError returns the error of a command on the file at p, telling
+// missing files apart.
+func fileError(p, stderr string, err error) error {
+	if strings.Contains(stderr, "No such file or directory") {
+		return &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%s: %s", p, msg)
+	}
+	return err
+}
+
+// ExitCode returns the exit code of the command that failed with err, 0
+// when it didn't and -1 when it didn't run.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// Quote quotes s for the shell of the host.
+func Quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@%+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// QuotePath quotes a path for the shell of the host, leaving a leading ~
+// for the shell to expand to the home directory.
+func QuotePath(p string) string {
+	if p == "~" {
+		return p
+	}
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return "~/" + Quote(rest)
+	}
+	return Quote(p)
+}
+
+// Command returns the command running name with args in dir, on the host
+// of the remote workspace when there's one, in the directory of the
+// workspace then.
+func Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd {
+	h := Current()
+	if h == nil {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Dir = dir
+		return cmd
+	}
+	quoted := []string{Quote(name)}
+	for _, arg := range args {
+		quoted = append(quoted, Quote(arg))
+	}
+	return h.Command(ctx, h.Dir, strings.Join(quoted, " "))
+}
+
+// Installed reports whether the program name is installed, on the host of
+// the remote workspace when there's one.
+func Installed(name string) bool {
+	h := Current()
+	if h == nil {
+		_, err := exec.LookPath(name)
+		return err == nil
+	}
+	_, _, err := h.Run(context.Background(), h.Dir, "command -v "+Quote(name), nil)
+	return err == nil
+}
@@ -0,0 +1,80 @@
+package remote
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSH puts an ssh on the PATH running the commands locally.
+func fakeSSH(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\nfor last; do :; done\nexec sh -c \"$last\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	r, err := Parse("devbox:~/src/app")
+	require.NoError(t, err)
+	require.Equal(t, config.Remote{Host: "devbox", Dir: "~/src/app"}, r)
+
+	r, err = Parse("me@devbox")
+	require.NoError(t, err)
+	require.Equal(t, config.Remote{Host: "me@devbox"}, r)
+
+	_, err = Parse(":/src")
+	require.Error(t, err)
+}
+
+func TestQuote(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "main.go", Quote("main.go"))
+	require.Equal(t, "''", Quote(""))
+	require.Equal(t, `'it'\''s here'`, Quote("it's here"))
+	require.Equal(t, "~/'my app'", QuotePath("~/my app"))
+	require.Equal(t, "~", QuotePath("~"))
+}
+
+func TestAbs(t *testing.T) {
+	t.Parallel()
+
+	h := &Host{Target: "devbox", Dir: "~/src/app"}
+	require.Equal(t, "~/src/app", h.Abs(""))
+	require.Equal(t, "~/src/app/cmd/main.go", h.Abs("cmd/main.go"))
+	require.Equal(t, "/etc/hosts", h.Abs("/etc/hosts"))
+	require.Equal(t, "~/.bashrc", h.Abs("~/.bashrc"))
+}
+
+func TestHost(t *testing.T) {
+	fakeSSH(t)
+	h := &Host{Target: "devbox", Dir: t.TempDir()}
+	ctx := t.Context()
+
+	require.NoError(t, h.WriteFile(ctx, "src/main.go", []byte("package main\n")))
+	data, err := h.ReadFile(ctx, "src/main.go")
+	require.NoError(t, err)
+	require.Equal(t, "package main\n", string(data))
+
+	info, err := h.Stat(ctx, "src/main.go")
+	require.NoError(t, err)
+	require.EqualValues(t, 13, info.Size)
+	require.False(t, info.IsDir)
+	info, err = h.Stat(ctx, "src")
+	require.NoError(t, err)
+	require.True(t, info.IsDir)
+
+	_, err = h.ReadFile(ctx, "missing.go")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	stdout, _, err := h.Run(ctx, "src", "ls; exit 3", nil)
+	require.Equal(t, "main.go\n", stdout)
+	require.Equal(t, 3, ExitCode(err))
+}
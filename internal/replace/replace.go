@@ -0,0 +1,259 @@
+// Package replace finds and replaces text across the files of a workspace.
+package replace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/charmbracelet/crush/internal/fsext"
+)
+
+const (
+	// maxFileSize is the size of the largest file searched.
+	maxFileSize = 1024 * 1024
+	// maxFiles is the number of files changed at most in one replacement.
+	maxFiles = 500
+)
+
+// ErrTooManyFiles is returned when a replacement would change more than
+// maxFiles files.
+var ErrTooManyFiles = fmt.Errorf("the replacement would change more than %d files, narrow it down with path filters", maxFiles)
+
+// Options describes a replacement.
+type Options struct {
+	// Pattern is the text to find, or a regular expression when Regex is set.
+	Pattern string
+	// Replacement replaces every match. With Regex, $1 or ${name} expand to
+	// the submatches.
+	Replacement string
+	Regex       bool
+	IgnoreCase  bool
+	// Include and Exclude are glob patterns filtering the files, e.g. "*.go"
+	// or "internal/**/*.ts". Patterns without a slash match the file name.
+	Include []string
+	Exclude []string
+}
+
+// Match is a changed line.
+type Match struct {
+	// Line is 1-based.
+	Line   int
+	Before string
+	After  string
+}
+
+// FileChange is the result of the replacement in a file.
+type FileChange struct {
+	Path       string
+	OldContent string
+	NewContent string
+	// Count is the number of matches in the file.
+	Count   int
+	Matches []Match
+}
+
+// Find computes the changes the replacement makes to the files under dir,
+// honoring .gitignore and .crushignore. Nothing is written.
+func Find(ctx context.Context, dir string, opts Options) ([]FileChange, error) {
+	re, err := compile(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range slices.Concat(opts.Include, opts.Exclude) {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid path filter: %s", pattern)
+		}
+	}
+
+	walker := fsext.NewFastGlobWalker(dir)
+	var changes []FileChange
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walker.ShouldSkip(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || !matchesFilters(filepath.ToSlash(rel), opts) {
+			return nil
+		}
+		change, ok := replaceFile(path, re, opts)
+		if !ok {
+			return nil
+		}
+		if len(changes) == maxFiles {
+			return ErrTooManyFiles
+		}
+		changes = append(changes, change)
+		return nil
+	})
+	return changes, err
+}
+
+// Apply writes the changes, skipping the files modified since they were
+// searched. It returns the paths of the files written.
+func Apply(changes []FileChange) ([]string, error) {
+	var written []string
+	var errs []error
+	for _, change := range changes {
+		content, err := os.ReadFile(change.Path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if string(content) != change.OldContent {
+			errs = append(errs, fmt.Errorf("%s was modified since it was searched", change.Path))
+			continue
+		}
+		info, err := os.Stat(change.Path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.WriteFile(change.Path, []byte(change.NewContent), info.Mode().Perm()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		written = append(written, change.Path)
+	}
+	return written, errors.Join(errs...)
+}
+
+func compile(opts Options) (*regexp.Regexp, error) {
+	if opts.Pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+	pattern := opts.Pattern
+	if !opts.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	return re, nil
+}
+
+func matchesFilters(rel string, opts Options) bool {
+	matches := func(pattern string) bool {
+		target := rel
+		if !strings.Contains(pattern, "/") {
+			target = filepath.Base(rel)
+		}
+		ok, _ := doublestar.Match(pattern, target)
+		return ok
+	}
+	for _, pattern := range opts.Exclude {
+		if matches(pattern) {
+			return false
+		}
+	}
+	if len(opts.Include) == 0 {
+		return true
+	}
+	for _, pattern := range opts.Include {
+		if matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func replaceFile(path string, re *regexp.Regexp, opts Options) (FileChange, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxFileSize {
+		return FileChange{}, false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0 {
+		return FileChange{}, false
+	}
+
+	oldContent := string(content)
+	locs := re.FindAllStringSubmatchIndex(oldContent, -1)
+	if len(locs) == 0 {
+		return FileChange{}, false
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range locs {
+		b.WriteString(oldContent[last:loc[0]])
+		if opts.Regex {
+			b.Write(re.ExpandString(nil, opts.Replacement, oldContent, loc))
+		} else {
+			b.WriteString(opts.Replacement)
+		}
+		last = loc[1]
+	}
+	b.WriteString(oldContent[last:])
+	newContent := b.String()
+	if newContent == oldContent {
+		return FileChange{}, false
+	}
+
+	return FileChange{
+		Path:       path,
+		OldContent: oldContent,
+		NewContent: newContent,
+		Count:      len(locs),
+		Matches:    changedLines(oldContent, newContent),
+	}, true
+}
+
+// changedLines lists the lines that differ. When the replacement adds or
+// removes lines, they can't be paired up, and the region between the common
+// prefix and suffix is reported as a single match instead.
+func changedLines(oldContent, newContent string) []Match {
+	oldLines := strings.Split(oldContent, "\n")
+	newLines := strings.Split(newContent, "\n")
+	if len(oldLines) != len(newLines) {
+		return changedLinesByPrefix(oldLines, newLines)
+	}
+	var matches []Match
+	for i := range oldLines {
+		if oldLines[i] != newLines[i] {
+			matches = append(matches, Match{Line: i + 1, Before: oldLines[i], After: newLines[i]})
+		}
+	}
+	return matches
+}
+
+func changedLinesByPrefix(oldLines, newLines []string) []Match {
+	start := 0
+	for start < len(oldLines) && start < len(newLines) && oldLines[start] == newLines[start] {
+		start++
+	}
+	oldEnd, newEnd := len(oldLines), len(newLines)
+	for oldEnd > start && newEnd > start && oldLines[oldEnd-1] == newLines[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+	return []Match{{
+		Line:   start + 1,
+		Before: strings.Join(oldLines[start:oldEnd], "\n"),
+		After:  strings.Join(newLines[start:newEnd], "\n"),
+	}}
+}
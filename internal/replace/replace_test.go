@@ -0,0 +1,132 @@
+package replace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":           "package a\n\nfunc oldName() {}\n\nvar _ = oldName\n",
+		"sub/b.go":       "package b\n\n// oldName does nothing.\n",
+		"sub/c.txt":      "oldName\n",
+		"gen/d.go":       "oldName\n",
+		"untouched.go":   "package untouched\n",
+		"binary.go":      "oldName\x00",
+		".gitignore":     "ignored.go\n",
+		"ignored.go":     "oldName\n",
+		"sub/OLDNAME.go": "OLDNAME\n",
+	})
+
+	tests := []struct {
+		name  string
+		opts  Options
+		files map[string]int
+	}{
+		{
+			name:  "literal",
+			opts:  Options{Pattern: "oldName", Replacement: "newName"},
+			files: map[string]int{"a.go": 2, "sub/b.go": 1, "sub/c.txt": 1, "gen/d.go": 1},
+		},
+		{
+			name:  "include",
+			opts:  Options{Pattern: "oldName", Replacement: "newName", Include: []string{"*.go"}},
+			files: map[string]int{"a.go": 2, "sub/b.go": 1, "gen/d.go": 1},
+		},
+		{
+			name:  "include and exclude paths",
+			opts:  Options{Pattern: "oldName", Replacement: "newName", Include: []string{"**/*.go"}, Exclude: []string{"gen/**"}},
+			files: map[string]int{"a.go": 2, "sub/b.go": 1},
+		},
+		{
+			name:  "ignore case",
+			opts:  Options{Pattern: "oldname", Replacement: "newName", IgnoreCase: true, Include: []string{"sub/*"}},
+			files: map[string]int{"sub/b.go": 1, "sub/c.txt": 1, "sub/OLDNAME.go": 1},
+		},
+		{
+			name:  "regex",
+			opts:  Options{Pattern: `func (\w+)\(\)`, Replacement: "func ${1}V2()", Regex: true},
+			files: map[string]int{"a.go": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			changes, err := Find(t.Context(), dir, tt.opts)
+			require.NoError(t, err)
+			got := map[string]int{}
+			for _, c := range changes {
+				rel, err := filepath.Rel(dir, c.Path)
+				require.NoError(t, err)
+				got[filepath.ToSlash(rel)] = c.Count
+			}
+			require.Equal(t, tt.files, got)
+		})
+	}
+
+	t.Run("matches", func(t *testing.T) {
+		t.Parallel()
+		changes, err := Find(t.Context(), dir, Options{Pattern: `func (\w+)\(\)`, Replacement: "func ${1}V2()", Regex: true})
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, []Match{{Line: 3, Before: "func oldName() {}", After: "func oldNameV2() {}"}}, changes[0].Matches)
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		t.Parallel()
+		_, err := Find(t.Context(), dir, Options{Pattern: "(", Regex: true})
+		require.Error(t, err)
+	})
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.txt": "hello world\n",
+		"b.txt": "hello there\n",
+	})
+	changes, err := Find(t.Context(), dir, Options{Pattern: "hello", Replacement: "bye"})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	// A file changed after the search is left alone.
+	modified := filepath.Join(dir, "b.txt")
+	require.NoError(t, os.WriteFile(modified, []byte("hello again\n"), 0o644))
+
+	written, err := Apply(changes)
+	require.Error(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "a.txt")}, written)
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "bye world\n", string(content))
+	content, err = os.ReadFile(modified)
+	require.NoError(t, err)
+	require.Equal(t, "hello again\n", string(content))
+}
+
+func TestChangedLines(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t,
+		[]Match{{Line: 2, Before: "b\nc", After: "x"}},
+		changedLines("a\nb\nc\nd\n", "a\nx\nd\n"),
+	)
+}
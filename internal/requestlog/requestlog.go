@@ -0,0 +1,321 @@
+// Package requestlog records the requests sent to providers and their
+// responses, per session, to debug prompts.
+package requestlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponse is how much of a response is kept.
+const maxResponse = 256 * 1024
+
+const redacted = "[REDACTED]"
+
+// Entry is a request and its response.
+type Entry struct {
+	Time    time.Time         `json:"time"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Model   string            `json:"model,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Request string            `json:"request,omitempty"`
+	Status  int               `json:"status,omitempty"`
+	// Response is the start of the response body, cut when Truncated.
+	Response  string `json:"response,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	// FirstByte is how long the response took to start, and Duration how
+	// long it took to finish.
+	FirstByte    time.Duration `json:"first_byte"`
+	Duration     time.Duration `json:"duration"`
+	InputTokens  int64         `json:"input_tokens,omitempty"`
+	OutputTokens int64         `json:"output_tokens,omitempty"`
+	StopReason   string        `json:"stop_reason,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Log stores the entries of each session in a JSON lines file.
+type Log struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// New returns the log kept in the data directory.
+func New(dataDir string) *Log {
+	return &Log{dir: filepath.Join(dataDir, "requests")}
+}
+
+func (l *Log) path(sessionID string) string {
+	return filepath.Join(l.dir, filepath.Base(sessionID)+".jsonl")
+}
+
+// Append adds e to the entries of the session.
+func (l *Log) Append(sessionID string, e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(l.dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path(sessionID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Entries returns the entries of the session, oldest first.
+func (l *Log) Entries(sessionID string) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.Open(l.path(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// NewClient returns a copy of client, or of the default client when nil,
+// recording the requests of the session sessionID returns for their
+// context. Requests outside of sessions aren't recorded.
+func NewClient(client *http.Client, log *Log, sessionID func(context.Context) string) *http.Client {
+	var c http.Client
+	if client != nil {
+		c = *client
+	}
+	c.Transport = &Transport{
+		Transport: c.Transport,
+		Log:       log,
+		SessionID: sessionID,
+	}
+	return &c
+}
+
+// Transport is an http.RoundTripper recording requests and responses,
+// without their secrets.
+type Transport struct {
+	// Transport sends the requests; http.DefaultTransport when nil.
+	Transport http.RoundTripper
+	Log       *Log
+	SessionID func(context.Context) string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	sessionID := t.SessionID(req.Context())
+	if sessionID == "" {
+		return base.RoundTrip(req)
+	}
+
+	entry := Entry{
+		Time:    time.Now(),
+		Method:  req.Method,
+		URL:     redactURL(req.URL),
+		Headers: redactHeaders(req.Header),
+	}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		entry.Request = string(body)
+		var payload struct {
+			Model string `json:"model"`
+		}
+		if json.Unmarshal(body, &payload) == nil {
+			entry.Model = payload.Model
+		}
+	}
+
+	resp, err := base.RoundTrip(req)
+	entry.FirstByte = time.Since(entry.Time)
+	if err != nil {
+		entry.Duration = entry.FirstByte
+		entry.Error = err.Error()
+		_ = t.Log.Append(sessionID, entry)
+		return resp, err
+	}
+	entry.Status = resp.StatusCode
+	resp.Body = &recorder{
+		ReadCloser: resp.Body,
+		entry:      entry,
+		done: func(e Entry) {
+			_ = t.Log.Append(sessionID, e)
+		},
+	}
+	return resp, nil
+}
+
+// recorder keeps the start of a response body as it's read, and the usage
+// it reports, recording the entry once the body is read or closed.
+type recorder struct {
+	io.ReadCloser
+	entry Entry
+	body  bytes.Buffer
+	line  []byte
+	once  sync.Once
+	done  func(Entry)
+}
+
+func (r *recorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.record(p[:n])
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			r.entry.Error = err.Error()
+		}
+		r.finish()
+	}
+	return n, err
+}
+
+func (r *recorder) Close() error {
+	r.finish()
+	return r.ReadCloser.Close()
+}
+
+func (r *recorder) record(b []byte) {
+	if room := maxResponse - r.body.Len(); room < len(b) {
+		r.body.Write(b[:max(0, room)])
+		r.entry.Truncated = true
+	} else {
+		r.body.Write(b)
+	}
+	// Streamed responses report usage in their last events, so every line
+	// is looked at even when the body is too long to be kept.
+	r.line = append(r.line, b...)
+	for {
+		i := bytes.IndexByte(r.line, '\n')
+		if i < 0 {
+			break
+		}
+		r.scan(r.line[:i])
+		r.line = r.line[i+1:]
+	}
+}
+
+func (r *recorder) finish() {
+	r.once.Do(func() {
+		r.scan(r.line)
+		r.entry.Response = r.body.String()
+		r.entry.Duration = time.Since(r.entry.Time)
+		r.done(r.entry)
+	})
+}
+
+// scan picks the usage and stop reason from a line of the response, either
+// a whole JSON body or the data of a server-sent event.
+func (r *recorder) scan(line []byte) {
+	line = bytes.TrimSpace(line)
+	line = bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
+	if len(line) == 0 || line[0] != '{' {
+		return
+	}
+	var v any
+	if json.Unmarshal(line, &v) == nil {
+		usage(v, &r.entry)
+	}
+}
+
+// usage sets the token counts and the stop reason of e from v, whichever
+// names the provider gives them.
+func usage(v any, e *Entry) {
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			usage(elem, e)
+		}
+	case map[string]any:
+		for key, value := range v {
+			switch value := value.(type) {
+			case float64:
+				switch key {
+				case "prompt_tokens", "input_tokens", "promptTokenCount":
+					e.InputTokens = int64(value)
+				case "completion_tokens", "output_tokens", "candidatesTokenCount":
+					e.OutputTokens = int64(value)
+				}
+			case string:
+				switch key {
+				case "finish_reason", "stop_reason", "finishReason":
+					e.StopReason = value
+				}
+			default:
+				usage(value, e)
+			}
+		}
+	}
+}
+
+// secret reports whether a header or query parameter holds a secret.
+func secret(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"auth", "key", "token", "secret", "cookie", "signature"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		if secret(name) {
+			headers[name] = redacted
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	for name := range query {
+		if secret(name) {
+			query.Set(name, redacted)
+		}
+	}
+	redactedURL := *u
+	redactedURL.User = nil
+	redactedURL.RawQuery = strings.ReplaceAll(query.Encode(), url.QueryEscape(redacted), redacted)
+	return redactedURL.String()
+}
@@ -0,0 +1,79 @@
+package requestlog
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sessionKey struct{}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"},\"finish_reason\":null}]}\n\n")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"finish_reason\":\"stop\"}],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3}}\n\n")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := filepath.Join(t.TempDir(), "requests")
+	log := New(dir)
+	client := NewClient(srv.Client(), log, func(ctx context.Context) string {
+		id, _ := ctx.Value(sessionKey{}).(string)
+		return id
+	})
+
+	send := func(ctx context.Context) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/chat?key=secret&alt=sse", strings.NewReader(`{"model":"m","messages":[]}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_, err = io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	send(t.Context())
+	send(context.WithValue(t.Context(), sessionKey{}, "session"))
+
+	entries, err := log.Entries("session")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	e := entries[0]
+	require.Equal(t, "m", e.Model)
+	require.Equal(t, http.StatusOK, e.Status)
+	require.Equal(t, `{"model":"m","messages":[]}`, e.Request)
+	require.Equal(t, redacted, e.Headers["Authorization"])
+	require.NotContains(t, e.URL, "secret")
+	require.Contains(t, e.URL, "alt=sse")
+	require.Equal(t, int64(12), e.InputTokens)
+	require.Equal(t, int64(3), e.OutputTokens)
+	require.Equal(t, "stop", e.StopReason)
+	require.Contains(t, e.Response, "[DONE]")
+
+	if runtime.GOOS != "windows" {
+		// The entries hold whole prompts and responses.
+		info, err := os.Stat(dir)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+		info, err = os.Stat(log.path("session"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	entries, err = log.Entries("other")
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
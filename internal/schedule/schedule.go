@@ -0,0 +1,277 @@
+// Package schedule keeps the prompts the agent runs on its own at set
+// times, and the history of their runs, in the data directory of the
+// project.
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	fileName = "schedules.json"
+	// MaxRuns is how many runs are kept in the history, the oldest are
+	// dropped.
+	MaxRuns = 100
+	// minInterval is the shortest time allowed between runs.
+	minInterval = time.Minute
+	// responseLimit is how much of the response of a run is kept.
+	responseLimit = 2000
+)
+
+// Schedule is a prompt run at set times.
+type Schedule struct {
+	ID string `json:"id"`
+	// When tells when the prompt runs, as in "daily", "every 2h" or
+	// "daily at 09:00".
+	When      string    `json:"when"`
+	Prompt    string    `json:"prompt"`
+	CreatedAt time.Time `json:"created_at"`
+	LastRunAt time.Time `json:"last_run_at,omitzero"`
+}
+
+// Run is a run of a schedule.
+type Run struct {
+	ScheduleID string    `json:"schedule_id"`
+	Prompt     string    `json:"prompt"`
+	SessionID  string    `json:"session_id,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Status is success, error, denied or cancelled.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Response is the start of what the agent answered.
+	Response string `json:"response,omitempty"`
+}
+
+// Succeeded reports whether the run finished without an error.
+func (r Run) Succeeded() bool {
+	return r.Status == "success"
+}
+
+// List holds the schedules and their runs, most recent last.
+type List struct {
+	Schedules []Schedule `json:"schedules"`
+	Runs      []Run      `json:"runs"`
+}
+
+var mu sync.Mutex
+
+func filePath(dataDir string) string {
+	return filepath.Join(dataDir, fileName)
+}
+
+// Load reads the schedules of the project with the given data directory.
+func Load(dataDir string) (*List, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return load(dataDir)
+}
+
+func load(dataDir string) (*List, error) {
+	data, err := os.ReadFile(filePath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return &List{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list List
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+	return &list, nil
+}
+
+func save(dataDir string, list *List) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath(dataDir), data, 0o600)
+}
+
+// update loads the schedules, applies fn and saves them.
+func update(dataDir string, fn func(list *List) error) error {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := load(dataDir)
+	if err != nil {
+		return err
+	}
+	if err := fn(list); err != nil {
+		return err
+	}
+	return save(dataDir, list)
+}
+
+// Add adds the schedule described by spec, a time and a prompt separated by
+// a colon, as in "daily: update the dependencies and run the tests".
+func Add(dataDir, spec string) (Schedule, error) {
+	m := specRe.FindStringSubmatch(spec)
+	if m == nil || strings.TrimSpace(m[2]) == "" {
+		return Schedule{}, fmt.Errorf("%q is missing the prompt: write it as \"daily: <prompt>\"", spec)
+	}
+	when := strings.ToLower(strings.Join(strings.Fields(m[1]), " "))
+	prompt := strings.TrimSpace(m[2])
+	if _, err := parseWhen(when); err != nil {
+		return Schedule{}, err
+	}
+	s := Schedule{
+		ID:        uuid.New().String()[:8],
+		When:      when,
+		Prompt:    prompt,
+		CreatedAt: time.Now(),
+	}
+	return s, update(dataDir, func(list *List) error {
+		list.Schedules = append(list.Schedules, s)
+		return nil
+	})
+}
+
+// Remove removes the schedule with the given ID, or ID prefix.
+func Remove(dataDir, id string) (Schedule, error) {
+	var removed Schedule
+	return removed, update(dataDir, func(list *List) error {
+		i, err := find(list.Schedules, id)
+		if err != nil {
+			return err
+		}
+		removed = list.Schedules[i]
+		list.Schedules = slices.Delete(list.Schedules, i, i+1)
+		return nil
+	})
+}
+
+// Start marks the schedule as run at the given time, before it runs, so
+// that it isn't run again if Crush exits during the run.
+func Start(dataDir, id string, at time.Time) error {
+	return update(dataDir, func(list *List) error {
+		i, err := find(list.Schedules, id)
+		if err != nil {
+			return err
+		}
+		list.Schedules[i].LastRunAt = at
+		return nil
+	})
+}
+
+// Record adds a finished run to the history.
+func Record(dataDir string, run Run) error {
+	if len(run.Response) > responseLimit {
+		run.Response = run.Response[:responseLimit] + "…"
+	}
+	return update(dataDir, func(list *List) error {
+		list.Runs = append(list.Runs, run)
+		list.Runs = list.Runs[max(0, len(list.Runs)-MaxRuns):]
+		return nil
+	})
+}
+
+func find(schedules []Schedule, id string) (int, error) {
+	found := -1
+	for i, s := range schedules {
+		if !strings.HasPrefix(s.ID, id) || id == "" {
+			continue
+		}
+		if found >= 0 {
+			return -1, fmt.Errorf("schedule ID %q is ambiguous", id)
+		}
+		found = i
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("no schedule with ID %q", id)
+	}
+	return found, nil
+}
+
+// Due returns the schedules due to run at now.
+func (l *List) Due(now time.Time) []Schedule {
+	var due []Schedule
+	for _, s := range l.Schedules {
+		if next, err := s.Next(); err == nil && !next.After(now) {
+			due = append(due, s)
+		}
+	}
+	return due
+}
+
+// Next returns when the schedule runs next.
+func (s Schedule) Next() (time.Time, error) {
+	w, err := parseWhen(s.When)
+	if err != nil {
+		return time.Time{}, err
+	}
+	last := s.LastRunAt
+	if last.IsZero() {
+		last = s.CreatedAt
+	}
+	if !w.clock {
+		return last.Add(w.every), nil
+	}
+	last = last.Local()
+	next := time.Date(last.Year(), last.Month(), last.Day(), w.hour, w.minute, 0, 0, time.Local)
+	for !next.After(last) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+var (
+	// specRe splits a spec into its time and its prompt, the colon of a
+	// time of day not being the separator.
+	specRe  = regexp.MustCompile(`(?is)^\s*((?:daily\s+)?at\s+\d{1,2}:\d{2}|[^:]*)\s*:(.*)$`)
+	timeRe  = regexp.MustCompile(`^(?:daily )?at (\d{1,2}):(\d{2})$`)
+	everyRe = regexp.MustCompile(`^every (\S+)$`)
+)
+
+type when struct {
+	every time.Duration
+	// clock is whether it runs every day at hour:minute instead.
+	clock        bool
+	hour, minute int
+}
+
+func parseWhen(s string) (when, error) {
+	switch s {
+	case "hourly":
+		return when{every: time.Hour}, nil
+	case "daily":
+		return when{every: 24 * time.Hour}, nil
+	case "weekly":
+		return when{every: 7 * 24 * time.Hour}, nil
+	}
+	if m := timeRe.FindStringSubmatch(s); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return when{}, fmt.Errorf("invalid time in %q", s)
+		}
+		return when{clock: true, hour: hour, minute: minute}, nil
+	}
+	if m := everyRe.FindStringSubmatch(s); m != nil {
+		every, err := time.ParseDuration(m[1])
+		if err != nil {
+			return when{}, fmt.Errorf("invalid interval in %q: %w", s, err)
+		}
+		if every < minInterval {
+			return when{}, fmt.Errorf("%q is too often: runs need to be at least %s apart", s, minInterval)
+		}
+		return when{every: every}, nil
+	}
+	return when{}, fmt.Errorf("unknown time %q: use hourly, daily, weekly, \"every 2h\" or \"daily at 09:00\"", s)
+}
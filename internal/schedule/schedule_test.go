@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	tests := []struct {
+		spec   string
+		when   string
+		prompt string
+	}{
+		{"daily: update deps and run tests", "daily", "update deps and run tests"},
+		{"Every 2h : check the build: then report", "every 2h", "check the build: then report"},
+		{"daily at 9:30: triage the issues", "daily at 9:30", "triage the issues"},
+		{"at 18:00:summarize the day", "at 18:00", "summarize the day"},
+	}
+	for _, tt := range tests {
+		s, err := Add(dataDir, tt.spec)
+		require.NoError(t, err, tt.spec)
+		require.Equal(t, tt.when, s.When)
+		require.Equal(t, tt.prompt, s.Prompt)
+	}
+
+	for _, spec := range []string{"daily", "daily:  ", "monthly: x", "every 10s: x", "at 25:00: x"} {
+		_, err := Add(dataDir, spec)
+		require.Error(t, err, spec)
+	}
+
+	list, err := Load(dataDir)
+	require.NoError(t, err)
+	require.Len(t, list.Schedules, len(tests))
+
+	removed, err := Remove(dataDir, list.Schedules[0].ID[:6])
+	require.NoError(t, err)
+	require.Equal(t, "daily", removed.When)
+	_, err = Remove(dataDir, removed.ID)
+	require.Error(t, err)
+}
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+	s := Schedule{When: "every 90m", CreatedAt: created}
+	next, err := s.Next()
+	require.NoError(t, err)
+	require.Equal(t, created.Add(90*time.Minute), next)
+
+	s = Schedule{When: "daily at 09:00", CreatedAt: created}
+	next, err = s.Next()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local), next)
+
+	s.LastRunAt = time.Date(2026, 10, 17, 9, 0, 5, 0, time.Local)
+	next, err = s.Next()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local), next)
+
+	list := List{Schedules: []Schedule{s, {When: "hourly", CreatedAt: created}}}
+	require.Len(t, list.Due(created.Add(time.Hour)), 1)
+	require.Len(t, list.Due(created.Add(48*time.Hour)), 2)
+}
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	s, err := Add(dataDir, "hourly: check")
+	require.NoError(t, err)
+
+	at := time.Now()
+	require.NoError(t, Start(dataDir, s.ID, at))
+	for range MaxRuns + 1 {
+		require.NoError(t, Record(dataDir, Run{ScheduleID: s.ID, Status: "success"}))
+	}
+
+	list, err := Load(dataDir)
+	require.NoError(t, err)
+	require.True(t, list.Schedules[0].LastRunAt.Equal(at))
+	require.Len(t, list.Runs, MaxRuns)
+	require.True(t, list.Runs[0].Succeeded())
+}
@@ -0,0 +1,132 @@
+package semantic
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+const defaultEmbeddingsURL = "https://api.openai.com/v1"
+
+// Embedder turns texts into embedding vectors.
+type Embedder interface {
+	// Model identifies the embedding model, so an index built with another
+	// model can be discarded.
+	Model() string
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+}
+
+// NewEmbedder creates an embedder for the OpenAI-compatible embeddings API
+// configured in tools.semantic_search. The base URL and API key fall back to
+// those of the configured provider.
+func NewEmbedder(cfg *config.Config) (Embedder, error) {
+	opts := cfg.Tools.SemanticSearch
+	if !opts.Enabled() {
+		return nil, errors.New("semantic search is not configured")
+	}
+
+	e := &openAIEmbedder{
+		model:   opts.Model,
+		baseURL: opts.BaseURL,
+		client:  &http.Client{Timeout: 2 * time.Minute},
+	}
+	apiKey := opts.APIKey
+	if opts.Provider != "" {
+		provider, ok := cfg.Providers.Get(opts.Provider)
+		if !ok {
+			return nil, fmt.Errorf("embeddings provider %s not configured", opts.Provider)
+		}
+		e.baseURL = cmp.Or(e.baseURL, provider.BaseURL)
+		apiKey = cmp.Or(apiKey, provider.APIKey)
+		e.headers = provider.ExtraHeaders
+	}
+	e.baseURL = strings.TrimSuffix(cmp.Or(e.baseURL, defaultEmbeddingsURL), "/")
+
+	if apiKey != "" {
+		resolved, err := cfg.Resolver().ResolveValue(apiKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve embeddings API key: %w", err)
+		}
+		e.apiKey = resolved
+	}
+	return e, nil
+}
+
+type openAIEmbedder struct {
+	model   string
+	baseURL string
+	apiKey  string
+	headers map[string]string
+	client  *http.Client
+}
+
+type embeddingsRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *openAIEmbedder) Model() string {
+	return e.model
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	body, err := json.Marshal(embeddingsRequest{Model: e.model, Input: inputs})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("embeddings request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result embeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings: %w", err)
+	}
+	vectors := make([][]float32, len(inputs))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embeddings response has an unexpected index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("embeddings response is missing input %d", i)
+		}
+	}
+	return vectors, nil
+}
@@ -0,0 +1,398 @@
+// Package semantic keeps an embedding index of the files in a workspace so
+// code can be found by meaning rather than by name, e.g. "where do we
+// throttle API requests".
+//
+// Files are split into overlapping line windows and embedded through an
+// OpenAI-compatible embeddings API. The index lives in the data directory and
+// is updated incrementally: only files whose size or modification time
+// changed since the last update are embedded again.
+package semantic
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/crush/internal/fsext"
+)
+
+const (
+	// chunkLines is the number of lines embedded together.
+	chunkLines = 50
+	// chunkOverlap is the number of lines shared by consecutive chunks, so a
+	// function split across a boundary is still found.
+	chunkOverlap = 10
+	// maxFileSize is the size of the largest file indexed. Larger files are
+	// usually generated or data.
+	maxFileSize = 256 * 1024
+	// maxFiles is the number of files indexed at most.
+	maxFiles = 20000
+	// batchSize is the number of chunks sent in one embeddings request.
+	batchSize = 64
+)
+
+// Result is a chunk of a file matching a query.
+type Result struct {
+	Path string
+	// StartLine and EndLine are 1-based and inclusive.
+	StartLine int
+	EndLine   int
+	// Score is the cosine similarity between the query and the chunk.
+	Score   float32
+	Snippet string
+}
+
+// Index is the embedding index of a workspace. It's safe for concurrent use.
+type Index struct {
+	dir      string
+	path     string
+	embedder Embedder
+
+	// updating is held for the whole of an update, so only one embeds at a
+	// time, while mu only guards files and is never held while embedding.
+	updating sync.Mutex
+	mu       sync.Mutex
+	files    map[string]fileEntry
+}
+
+type indexFile struct {
+	Model string               `json:"model"`
+	Files map[string]fileEntry `json:"files"`
+}
+
+type fileEntry struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Chunks  []chunk   `json:"chunks"`
+}
+
+type chunk struct {
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Vector    vector `json:"vector"`
+}
+
+// vector is stored as base64 encoded little-endian float32s, which is a
+// fraction of the size of a JSON array of numbers.
+type vector []float32
+
+func (v vector) MarshalJSON() ([]byte, error) {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(buf))
+}
+
+func (v *vector) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(buf)%4 != 0 {
+		return errors.New("invalid vector length")
+	}
+	*v = make(vector, len(buf)/4)
+	for i := range *v {
+		(*v)[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return nil
+}
+
+// Open loads the index of dir stored in dataDir, if any. An index built with
+// a different embedding model is discarded.
+func Open(dir, dataDir string, embedder Embedder) *Index {
+	idx := &Index{
+		dir:      dir,
+		path:     filepath.Join(dataDir, "semantic", "index.json"),
+		embedder: embedder,
+		files:    map[string]fileEntry{},
+	}
+	data, err := os.ReadFile(idx.path)
+	if err != nil {
+		return idx
+	}
+	var stored indexFile
+	if err := json.Unmarshal(data, &stored); err == nil && stored.Model == embedder.Model() && stored.Files != nil {
+		idx.files = stored.Files
+	}
+	return idx
+}
+
+// Update embeds the files that were added or changed since the last update
+// and drops the ones that were removed.
+func (idx *Index) Update(ctx context.Context) error {
+	idx.updating.Lock()
+	defer idx.updating.Unlock()
+	return idx.update(ctx)
+}
+
+func (idx *Index) update(ctx context.Context) error {
+	files, err := idx.listFiles()
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	current := maps.Clone(idx.files)
+	idx.mu.Unlock()
+
+	var removed []string
+	for path := range current {
+		if _, ok := files[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+
+	updated := map[string]fileEntry{}
+	var pending []pendingChunk
+	for path, info := range files {
+		if entry, ok := current[path]; ok && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || !isText(content) {
+			continue
+		}
+		updated[path] = fileEntry{ModTime: info.ModTime(), Size: info.Size()}
+		pending = append(pending, split(path, idx.relative(path), string(content))...)
+	}
+	if len(removed) == 0 && len(updated) == 0 {
+		return nil
+	}
+
+	var embedErr error
+	for batch := range slices.Chunk(pending, batchSize) {
+		inputs := make([]string, len(batch))
+		for i, p := range batch {
+			inputs[i] = p.text
+		}
+		vectors, err := idx.embedder.Embed(ctx, inputs)
+		if err != nil {
+			// Keep what was embedded so far; the files with missing chunks
+			// are embedded again on the next update.
+			forgetIncomplete(updated, pending)
+			embedErr = err
+			break
+		}
+		for i, p := range batch {
+			entry := updated[p.path]
+			entry.Chunks = append(entry.Chunks, chunk{
+				StartLine: p.startLine,
+				EndLine:   p.endLine,
+				Vector:    normalize(vectors[i]),
+			})
+			updated[p.path] = entry
+		}
+	}
+
+	idx.mu.Lock()
+	for _, path := range removed {
+		delete(idx.files, path)
+	}
+	maps.Copy(idx.files, updated)
+	data, err := json.Marshal(indexFile{Model: idx.embedder.Model(), Files: idx.files})
+	idx.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := idx.save(data); err != nil && embedErr == nil {
+		return err
+	}
+	return embedErr
+}
+
+// forgetIncomplete removes the updated files whose chunks were not all
+// embedded, leaving what the index had for them.
+func forgetIncomplete(updated map[string]fileEntry, pending []pendingChunk) {
+	expected := map[string]int{}
+	for _, p := range pending {
+		expected[p.path]++
+	}
+	for path, n := range expected {
+		if len(updated[path].Chunks) != n {
+			delete(updated, path)
+		}
+	}
+}
+
+// Search updates the index and returns up to limit chunks closest to the
+// query, best matches first.
+func (idx *Index) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+	// An update already running isn't waited for: the index as it is
+	// answers meanwhile.
+	if idx.updating.TryLock() {
+		err := idx.update(ctx)
+		idx.updating.Unlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to update the semantic index: %w", err)
+		}
+	}
+	vectors, err := idx.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	q := normalize(vectors[0])
+
+	idx.mu.Lock()
+	var results []Result
+	for path, entry := range idx.files {
+		for _, c := range entry.Chunks {
+			results = append(results, Result{
+				Path:      path,
+				StartLine: c.StartLine,
+				EndLine:   c.EndLine,
+				Score:     dot(q, c.Vector),
+			})
+		}
+	}
+	idx.mu.Unlock()
+
+	slices.SortFunc(results, func(a, b Result) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.StartLine, b.StartLine),
+		)
+	})
+	results = results[:min(limit, len(results))]
+	for i := range results {
+		results[i].Snippet = readLines(results[i].Path, results[i].StartLine, results[i].EndLine)
+	}
+	return results, nil
+}
+
+func (idx *Index) listFiles() (map[string]os.FileInfo, error) {
+	walker := fsext.NewFastGlobWalker(idx.dir)
+	files := map[string]os.FileInfo{}
+	err := filepath.WalkDir(idx.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors
+		}
+		if walker.ShouldSkip(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Size() == 0 || info.Size() > maxFileSize {
+			return nil
+		}
+		files[path] = info
+		if len(files) >= maxFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return files, err
+}
+
+func (idx *Index) relative(path string) string {
+	if rel, err := filepath.Rel(idx.dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// save writes the index, private as it holds a digest of the code.
+func (idx *Index) save(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(idx.path, data, 0o600)
+}
+
+type pendingChunk struct {
+	path      string
+	startLine int
+	endLine   int
+	text      string
+}
+
+// split cuts content into overlapping windows of lines. Each window is
+// prefixed with the file name, which carries a lot of meaning on its own.
+func split(path, name, content string) []pendingChunk {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	var chunks []pendingChunk
+	for start := 0; start < len(lines); start += chunkLines - chunkOverlap {
+		end := min(start+chunkLines, len(lines))
+		text := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(text) != "" {
+			chunks = append(chunks, pendingChunk{
+				path:      path,
+				startLine: start + 1,
+				endLine:   end,
+				text:      name + "\n" + text,
+			})
+		}
+		if end == len(lines) {
+			break
+		}
+	}
+	return chunks
+}
+
+func isText(content []byte) bool {
+	return utf8.Valid(content) && !bytes.ContainsRune(content, 0)
+}
+
+func readLines(path string, start, end int) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(content), "\n")
+	start = min(max(start-1, 0), len(lines))
+	end = min(end, len(lines))
+	return strings.Join(lines[start:end], "\n")
+}
+
+func normalize(v []float32) vector {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	out := make(vector, len(v))
+	for i, f := range v {
+		out[i] = f / norm
+	}
+	return out
+}
+
+func dot(a, b vector) float32 {
+	var sum float32
+	for i := range min(len(a), len(b)) {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,182 @@
+package semantic
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// wordEmbedder embeds texts as bags of words, so texts sharing words are
+// close to each other.
+type wordEmbedder struct {
+	model string
+	calls int
+	texts int
+}
+
+func (e *wordEmbedder) Model() string {
+	return e.model
+}
+
+func (e *wordEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	e.calls++
+	e.texts += len(inputs)
+	vectors := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		v := make([]float32, 64)
+		for _, word := range strings.Fields(strings.ToLower(input)) {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			v[h.Sum32()%64]++
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestIndexSearch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dataDir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "limiter.go"), "package api\n\n// throttle requests to the api\nfunc throttle() {}\n")
+	writeFile(t, filepath.Join(dir, "render.go"), "package ui\n\n// draw the screen\nfunc draw() {}\n")
+	writeFile(t, filepath.Join(dir, "blob.bin"), "\x00\x01\x02")
+
+	embedder := &wordEmbedder{model: "words"}
+	idx := Open(dir, dataDir, embedder)
+	results, err := idx.Search(t.Context(), "throttle api requests", 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, filepath.Join(dir, "limiter.go"), results[0].Path)
+	require.Equal(t, 1, results[0].StartLine)
+	require.Equal(t, 4, results[0].EndLine)
+	require.Contains(t, results[0].Snippet, "func throttle()")
+	require.Greater(t, results[0].Score, results[1].Score)
+
+	t.Run("reuses the stored index", func(t *testing.T) {
+		embedder := &wordEmbedder{model: "words"}
+		require.NoError(t, Open(dir, dataDir, embedder).Update(t.Context()))
+		require.Zero(t, embedder.calls)
+	})
+
+	t.Run("discards an index from another model", func(t *testing.T) {
+		embedder := &wordEmbedder{model: "other"}
+		require.NoError(t, Open(dir, dataDir, embedder).Update(t.Context()))
+		require.Equal(t, 2, embedder.texts)
+	})
+}
+
+func TestIndexUpdate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	writeFile(t, path, "package a\n")
+	writeFile(t, filepath.Join(dir, "b.go"), "package b\n")
+
+	embedder := &wordEmbedder{model: "words"}
+	idx := Open(dir, t.TempDir(), embedder)
+	require.NoError(t, idx.Update(t.Context()))
+	require.Equal(t, 2, embedder.texts)
+
+	// Only the changed file is embedded again.
+	writeFile(t, path, "package a\n\nfunc A() {}\n")
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(t, idx.Update(t.Context()))
+	require.Equal(t, 3, embedder.texts)
+
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, idx.Update(t.Context()))
+	require.Len(t, idx.files, 1)
+}
+
+// blockingEmbedder holds back the embedding of chunks, which carry their
+// file name on the first line, until release is closed.
+type blockingEmbedder struct {
+	wordEmbedder
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *blockingEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	if strings.Contains(inputs[0], "\n") {
+		close(e.started)
+		<-e.release
+	}
+	return (&wordEmbedder{}).Embed(ctx, inputs)
+}
+
+func TestIndexSearchDuringUpdate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dataDir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.go"), "package a\n")
+	embedder := &blockingEmbedder{
+		wordEmbedder: wordEmbedder{model: "words"},
+		started:      make(chan struct{}),
+		release:      make(chan struct{}),
+	}
+	idx := Open(dir, dataDir, embedder)
+
+	done := make(chan error, 1)
+	go func() { done <- idx.Update(t.Context()) }()
+	<-embedder.started
+
+	// The running update doesn't hold the index while embedding.
+	results, err := idx.Search(t.Context(), "package", 5)
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	close(embedder.release)
+	require.NoError(t, <-done)
+	results, err = idx.Search(t.Context(), "package", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dataDir, "semantic", "index.json"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	lines := make([]string, 120)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	chunks := split("/x/a.go", "a.go", strings.Join(lines, "\n")+"\n")
+	require.Len(t, chunks, 3)
+	require.Equal(t, [2]int{1, 50}, [2]int{chunks[0].startLine, chunks[0].endLine})
+	require.Equal(t, [2]int{41, 90}, [2]int{chunks[1].startLine, chunks[1].endLine})
+	require.Equal(t, [2]int{81, 120}, [2]int{chunks[2].startLine, chunks[2].endLine})
+	require.True(t, strings.HasPrefix(chunks[0].text, "a.go\n"))
+}
+
+func TestVectorJSON(t *testing.T) {
+	t.Parallel()
+
+	v := vector{0.5, -1, 3.25}
+	data, err := v.MarshalJSON()
+	require.NoError(t, err)
+	var got vector
+	require.NoError(t, got.UnmarshalJSON(data))
+	require.Equal(t, v, got)
+}
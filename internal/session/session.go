@@ -37,9 +37,18 @@ type Session struct {
 	CompletionTokens int64
 	SummaryMessageID string
 	Cost             float64
+	// CacheReadTokens and CacheWriteTokens add up the prompt tokens read
+	// from and written to the provider's cache, and CacheSavings what
+	// caching saved, net of the cost of the writes.
+	CacheReadTokens  int64
+	CacheWriteTokens int64
+	CacheSavings     float64
 	Todos            []Todo
 	CreatedAt        int64
 	UpdatedAt        int64
+	// Tags group the session with others, set by hand or after the
+	// repository and the models it was run in.
+	Tags []string
 }
 
 type Service interface {
@@ -51,6 +60,8 @@ type Service interface {
 	List(ctx context.Context) ([]Session, error)
 	Save(ctx context.Context, session Session) (Session, error)
 	UpdateTitleAndUsage(ctx context.Context, sessionID, title string, promptTokens, completionTokens int64, cost float64) error
+	// SetTags replaces the tags of a session, which Save leaves alone.
+	SetTags(ctx context.Context, sessionID string, tags []string) (Session, error)
 	Delete(ctx context.Context, id string) error
 
 	// Agent tool session management
@@ -148,6 +159,9 @@ func (s *service) Save(ctx context.Context, session Session) (Session, error) {
 			String: todosJSON,
 			Valid:  todosJSON != "",
 		},
+		CacheReadTokens:  session.CacheReadTokens,
+		CacheWriteTokens: session.CacheWriteTokens,
+		CacheSavings:     session.CacheSavings,
 	})
 	if err != nil {
 		return Session{}, err
@@ -159,14 +173,22 @@ func (s *service) Save(ctx context.Context, session Session) (Session, error) {
 
 // UpdateTitleAndUsage updates only the title and usage fields atomically.
 // This is safer than fetching, modifying, and saving the entire session.
+// The session is published afterwards, for the new title to be shown.
 func (s *service) UpdateTitleAndUsage(ctx context.Context, sessionID, title string, promptTokens, completionTokens int64, cost float64) error {
-	return s.q.UpdateSessionTitleAndUsage(ctx, db.UpdateSessionTitleAndUsageParams{
+	err := s.q.UpdateSessionTitleAndUsage(ctx, db.UpdateSessionTitleAndUsageParams{
 		ID:               sessionID,
 		Title:            title,
 		PromptTokens:     promptTokens,
 		CompletionTokens: completionTokens,
 		Cost:             cost,
 	})
+	if err != nil {
+		return err
+	}
+	if session, err := s.Get(ctx, sessionID); err == nil {
+		s.Publish(pubsub.UpdatedEvent, session)
+	}
+	return nil
 }
 
 func (s *service) List(ctx context.Context) ([]Session, error) {
@@ -186,6 +208,10 @@ func (s service) fromDBItem(item db.Session) Session {
 	if err != nil {
 		slog.Error("failed to unmarshal todos", "session_id", item.ID, "error", err)
 	}
+	tags, err := unmarshalTags(item.Tags.String)
+	if err != nil {
+		slog.Error("failed to unmarshal tags", "session_id", item.ID, "error", err)
+	}
 	return Session{
 		ID:               item.ID,
 		ParentSessionID:  item.ParentSessionID.String,
@@ -195,7 +221,11 @@ func (s service) fromDBItem(item db.Session) Session {
 		CompletionTokens: item.CompletionTokens,
 		SummaryMessageID: item.SummaryMessageID.String,
 		Cost:             item.Cost,
+		CacheReadTokens:  item.CacheReadTokens,
+		CacheWriteTokens: item.CacheWriteTokens,
+		CacheSavings:     item.CacheSavings,
 		Todos:            todos,
+		Tags:             tags,
 		CreatedAt:        item.CreatedAt,
 		UpdatedAt:        item.UpdatedAt,
 	}
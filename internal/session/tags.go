@@ -0,0 +1,83 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+// NormalizeTags trims and lowercases tags, dropping the empty ones and the
+// duplicates, and sorts them.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+		if tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// EditTags applies edits to tags: each edit adds a tag, or removes it when
+// it starts with a -.
+func EditTags(tags []string, edits []string) []string {
+	tags = slices.Clone(tags)
+	for _, edit := range edits {
+		if removed, ok := strings.CutPrefix(edit, "-"); ok {
+			removed = strings.ToLower(strings.TrimPrefix(removed, "#"))
+			tags = slices.DeleteFunc(tags, func(tag string) bool { return tag == removed })
+			continue
+		}
+		tags = append(tags, edit)
+	}
+	return NormalizeTags(tags)
+}
+
+func (s *service) SetTags(ctx context.Context, sessionID string, tags []string) (Session, error) {
+	tagsJSON, err := marshalTags(NormalizeTags(tags))
+	if err != nil {
+		return Session{}, err
+	}
+	err = s.q.UpdateSessionTags(ctx, db.UpdateSessionTagsParams{
+		ID:   sessionID,
+		Tags: sql.NullString{String: tagsJSON, Valid: tagsJSON != ""},
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	session, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	s.Publish(pubsub.UpdatedEvent, session)
+	return session, nil
+}
+
+func marshalTags(tags []string) (string, error) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func unmarshalTags(data string) ([]string, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var tags []string
+	if err := json.Unmarshal([]byte(data), &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,15 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditTags(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"api", "crush"}, NormalizeTags([]string{" Crush", "#api", "", "crush"}))
+	require.Equal(t, []string{"crush", "ui"}, EditTags([]string{"api", "crush"}, []string{"-#api", "UI"}))
+	require.Empty(t, EditTags(nil, []string{"-api"}))
+}
@@ -0,0 +1,169 @@
+// Package sessionshare keeps exports of sessions in the repository, under
+// .crush/sessions, for teammates to read past agent runs.
+package sessionshare
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/apiserver"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/redact"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// Dir is where the sessions are shared, relative to the working directory.
+const Dir = ".crush/sessions"
+
+// Shared is a session shared in the repository, in the format of
+// crush sessions export --format json.
+type Shared struct {
+	Session  apiserver.Session   `json:"session"`
+	Messages []apiserver.Message `json:"messages"`
+	SharedBy string              `json:"shared_by,omitempty"`
+	SharedAt int64               `json:"shared_at,omitempty"`
+
+	// Path is the file the session was read from.
+	Path string `json:"-"`
+}
+
+// New prepares a session to be shared, by the git user of workingDir.
+func New(ctx context.Context, workingDir string, sess session.Session, msgs []message.Message) Shared {
+	shared := Shared{
+		Session:  apiserver.FromSession(sess),
+		Messages: make([]apiserver.Message, 0, len(msgs)),
+		SharedBy: gitUser(ctx, workingDir),
+		SharedAt: time.Now().Unix(),
+	}
+	for _, msg := range msgs {
+		shared.Messages = append(shared.Messages, apiserver.FromMessage(msg))
+	}
+	return shared
+}
+
+// Write writes a shared session to the directory of workingDir, masking
+// its secrets with redactor, and returns the path of the file. Sharing a
+// session again replaces the file.
+func Write(workingDir string, shared Shared, redactor *redact.Redactor) (string, error) {
+	data, err := json.MarshalIndent(shared, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(workingDir, Dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fileName(shared.Session))
+	// Masks stay valid JSON, as they hold neither quotes nor backslashes.
+	if err := os.WriteFile(path, []byte(redactor.String(string(data))+"\n"), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// List reads the sessions shared in workingDir, the last shared first.
+// Files that can't be read are skipped.
+func List(workingDir string) ([]Shared, error) {
+	paths, err := filepath.Glob(filepath.Join(workingDir, Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	shared := make([]Shared, 0, len(paths))
+	for _, path := range paths {
+		s, err := Read(path)
+		if err != nil {
+			slog.Warn("Skipping shared session", "path", path, "error", err)
+			continue
+		}
+		shared = append(shared, s)
+	}
+	slices.SortStableFunc(shared, func(a, b Shared) int {
+		return cmp.Compare(b.SharedAt, a.SharedAt)
+	})
+	return shared, nil
+}
+
+// Read reads the shared session at path.
+func Read(path string) (Shared, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Shared{}, err
+	}
+	var shared Shared
+	if err := json.Unmarshal(data, &shared); err != nil {
+		return Shared{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	shared.Path = path
+	return shared, nil
+}
+
+// Markdown renders the conversation of a shared session, tool calls and
+// results included.
+func Markdown(shared Shared) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", shared.Session.Title)
+	if shared.SharedBy != "" {
+		fmt.Fprintf(&sb, "\nShared by %s on %s\n", shared.SharedBy, time.Unix(shared.SharedAt, 0).Local().Format(time.DateOnly))
+	}
+	for _, msg := range shared.Messages {
+		switch msg.Role {
+		case message.User:
+			sb.WriteString("\n## User\n\n")
+			sb.WriteString(strings.TrimSpace(msg.Text) + "\n")
+		case message.Assistant:
+			sb.WriteString("\n## Assistant\n")
+			if text := strings.TrimSpace(msg.Text); text != "" {
+				sb.WriteString("\n" + text + "\n")
+			}
+			for _, tc := range msg.ToolCalls {
+				fmt.Fprintf(&sb, "\n**Tool call** `%s`\n\n```json\n%s\n```\n", tc.Name, tc.Input)
+			}
+		case message.Tool:
+			for _, tr := range msg.ToolResults {
+				label := "Tool result"
+				if tr.IsError {
+					label = "Tool error"
+				}
+				fmt.Fprintf(&sb, "\n**%s** `%s`\n\n```\n%s\n```\n", label, tr.Name, strings.TrimSpace(tr.Content))
+			}
+		}
+	}
+	return sb.String()
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fileName names the file of a session after its title and ID, which
+// keeps it the same when the session is shared again.
+func fileName(sess apiserver.Session) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(sess.Title), "-"), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	id, _, _ := strings.Cut(sess.ID, "-")
+	if slug == "" {
+		return id + ".json"
+	}
+	return slug + "-" + id + ".json"
+}
+
+// gitUser returns the name of the git user of dir, empty if there's none.
+func gitUser(ctx context.Context, dir string) string {
+	cmd := exec.CommandContext(ctx, "git", "config", "user.name")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,41 @@
+package sessionshare
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/apiserver"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "fix-the-login-form-4f0c9a2e.json", fileName(apiserver.Session{ID: "4f0c9a2e-1b2c-4d5e", Title: "Fix the login form!"}))
+	require.Equal(t, "4f0c9a2e.json", fileName(apiserver.Session{ID: "4f0c9a2e-1b2c-4d5e", Title: "…"}))
+}
+
+func TestWriteAndList(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	older := Shared{Session: apiserver.Session{ID: "a", Title: "Older"}, SharedAt: 1}
+	newer := Shared{
+		Session:  apiserver.Session{ID: "b", Title: "Newer"},
+		Messages: []apiserver.Message{{Role: message.User, Text: "List the files"}},
+		SharedBy: "Carlos",
+		SharedAt: 2,
+	}
+	for _, s := range []Shared{older, newer} {
+		_, err := Write(dir, s, nil)
+		require.NoError(t, err)
+	}
+
+	shared, err := List(dir)
+	require.NoError(t, err)
+	require.Len(t, shared, 2)
+	require.Equal(t, "Newer", shared[0].Session.Title)
+	require.Equal(t, filepath.Join(dir, Dir, "newer-b.json"), shared[0].Path)
+	require.Contains(t, Markdown(shared[0]), "## User\n\nList the files\n")
+}
@@ -2,13 +2,19 @@ package shell
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/pubsub"
 )
 
 const (
@@ -16,6 +22,14 @@ const (
 	MaxBackgroundJobs = 50
 	// CompletedJobRetentionMinutes is how long to keep completed jobs before auto-cleanup (8 hours)
 	CompletedJobRetentionMinutes = 8 * 60
+
+	// RunawayRate is the output rate, in bytes per second, past which a job
+	// writing for runawaySeconds in a row is considered a runaway.
+	RunawayRate    = 1024 * 1024
+	runawaySeconds = 10
+	// ThrottledRate is the output rate runaways are slowed down to, until
+	// they're kept or killed.
+	ThrottledRate = 64 * 1024
 )
 
 // BackgroundShell represents a shell running in the background.
@@ -27,11 +41,105 @@ type BackgroundShell struct {
 	WorkingDir  string
 	ctx         context.Context
 	cancel      context.CancelFunc
-	stdout      *bytes.Buffer
-	stderr      *bytes.Buffer
+	stdout      *output
+	stderr      *output
+	// stdin is the standard input of interactive shells, nil otherwise.
+	stdin       *os.File
 	done        chan struct{}
 	exitErr     error
 	completedAt int64 // Unix timestamp when job completed (0 if still running)
+
+	notifyExit  atomic.Bool
+	publishOnce sync.Once
+
+	watchdog *watchdog
+
+	// seen is how much of the output was looked at, and seenDone whether
+	// the exit was.
+	seen     atomic.Int64
+	seenDone atomic.Bool
+}
+
+// output is the output of a background shell, read while it's written.
+type output struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	watchdog *watchdog
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	n, err := o.buf.Write(p)
+	o.mu.Unlock()
+	if o.watchdog != nil {
+		// Outside of the lock, the output stays readable while it waits.
+		o.watchdog.wrote(n)
+	}
+	return n, err
+}
+
+// watchdog watches how fast a background shell writes, both outputs
+// together, to catch the ones stuck writing in a loop and slow them down
+// until the user keeps or kills them.
+type watchdog struct {
+	mu      sync.Mutex
+	second  time.Time
+	written int
+	// hot is the number of seconds in a row written past RunawayRate.
+	hot     int
+	runaway bool
+	kept    bool
+
+	done      <-chan struct{}
+	onRunaway func()
+}
+
+func (w *watchdog) wrote(n int) {
+	w.mu.Lock()
+	now := time.Now()
+	switch elapsed := now.Sub(w.second); {
+	case elapsed >= 2*time.Second:
+		// A second without output went by.
+		w.hot = 0
+		fallthrough
+	case elapsed >= time.Second:
+		if w.written >= RunawayRate {
+			w.hot++
+		} else {
+			w.hot = 0
+		}
+		w.second, w.written = now, 0
+	}
+	w.written += n
+	found := !w.runaway && !w.kept && w.hot >= runawaySeconds
+	if found {
+		w.runaway = true
+	}
+	throttled := w.runaway && !w.kept
+	w.mu.Unlock()
+
+	if found && w.onRunaway != nil {
+		w.onRunaway()
+	}
+	if throttled {
+		// Blocking the writes slows the program down as its pipe fills.
+		select {
+		case <-time.After(time.Duration(n) * time.Second / ThrottledRate):
+		case <-w.done:
+		}
+	}
+}
+
+func (o *output) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.String()
+}
+
+func (o *output) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Len()
 }
 
 // BackgroundShellManager manages background shell instances.
@@ -43,8 +151,40 @@ var (
 	backgroundManager     *BackgroundShellManager
 	backgroundManagerOnce sync.Once
 	idCounter             atomic.Uint64
+	exitBroker            = pubsub.NewBroker[BackgroundShellExit]()
+	runawayBroker         = pubsub.NewBroker[BackgroundShellRunaway]()
 )
 
+// BackgroundShellRunaway is published when a background job has written
+// faster than RunawayRate for a while and is slowed down to ThrottledRate,
+// until it's kept with Keep or killed.
+type BackgroundShellRunaway struct {
+	ID          string
+	Command     string
+	Description string
+}
+
+// SubscribeBackgroundRunaways returns a channel receiving the background
+// shells found writing in a loop.
+func SubscribeBackgroundRunaways(ctx context.Context) <-chan pubsub.Event[BackgroundShellRunaway] {
+	return runawayBroker.Subscribe(ctx)
+}
+
+// BackgroundShellExit is published when a background job exits on its own,
+// as opposed to being killed.
+type BackgroundShellExit struct {
+	ID          string
+	Command     string
+	Description string
+	ExitCode    int
+}
+
+// SubscribeBackgroundExits returns a channel receiving the exits of
+// background shells.
+func SubscribeBackgroundExits(ctx context.Context) <-chan pubsub.Event[BackgroundShellExit] {
+	return exitBroker.Subscribe(ctx)
+}
+
 // GetBackgroundShellManager returns the singleton background shell manager.
 func GetBackgroundShellManager() *BackgroundShellManager {
 	backgroundManagerOnce.Do(func() {
@@ -57,6 +197,23 @@ func GetBackgroundShellManager() *BackgroundShellManager {
 
 // Start creates and starts a new background shell with the given command.
 func (m *BackgroundShellManager) Start(ctx context.Context, workingDir string, blockFuncs []BlockFunc, command string, description string) (*BackgroundShell, error) {
+	return m.start(ctx, workingDir, nil, blockFuncs, command, description, false)
+}
+
+// StartInteractive is Start keeping the standard input of the command open,
+// to send it input with Send, for REPLs, debuggers and the like.
+func (m *BackgroundShellManager) StartInteractive(ctx context.Context, workingDir string, blockFuncs []BlockFunc, command string, description string) (*BackgroundShell, error) {
+	return m.start(ctx, workingDir, nil, blockFuncs, command, description, true)
+}
+
+// StartEnv is Start, or StartInteractive when interactive, running the
+// command with env as its environment rather than the one of Crush, for the
+// jobs the user starts.
+func (m *BackgroundShellManager) StartEnv(ctx context.Context, workingDir string, env []string, command string, description string, interactive bool) (*BackgroundShell, error) {
+	return m.start(ctx, workingDir, env, nil, command, description, interactive)
+}
+
+func (m *BackgroundShellManager) start(ctx context.Context, workingDir string, env []string, blockFuncs []BlockFunc, command string, description string, interactive bool) (*BackgroundShell, error) {
 	// Check job limit
 	if m.shells.Len() >= MaxBackgroundJobs {
 		return nil, fmt.Errorf("maximum number of background jobs (%d) reached. Please terminate or wait for some jobs to complete", MaxBackgroundJobs)
@@ -66,6 +223,7 @@ func (m *BackgroundShellManager) Start(ctx context.Context, workingDir string, b
 
 	shell := NewShell(&Options{
 		WorkingDir: workingDir,
+		Env:        env,
 		BlockFuncs: blockFuncs,
 	})
 
@@ -79,25 +237,87 @@ func (m *BackgroundShellManager) Start(ctx context.Context, workingDir string, b
 		Shell:       shell,
 		ctx:         shellCtx,
 		cancel:      cancel,
-		stdout:      &bytes.Buffer{},
-		stderr:      &bytes.Buffer{},
 		done:        make(chan struct{}),
 	}
+	bgShell.watchdog = &watchdog{
+		second: time.Now(),
+		done:   shellCtx.Done(),
+		onRunaway: func() {
+			runawayBroker.Publish(pubsub.CreatedEvent, BackgroundShellRunaway{
+				ID:          id,
+				Command:     command,
+				Description: description,
+			})
+		},
+	}
+	bgShell.stdout = &output{watchdog: bgShell.watchdog}
+	bgShell.stderr = &output{watchdog: bgShell.watchdog}
+
+	// A pipe rather than an io.Reader, so that programs read it themselves
+	// and input sent to a program that doesn't read doesn't block for long.
+	var stdin *os.File
+	if interactive {
+		r, w, err := os.Pipe()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		stdin, bgShell.stdin = r, w
+		// Builtins reading their input aren't interrupted by the context.
+		context.AfterFunc(shellCtx, func() { r.Close() })
+	}
 
 	m.shells.Set(id, bgShell)
 
 	go func() {
-		defer close(bgShell.done)
-
-		err := shell.ExecStream(shellCtx, command, bgShell.stdout, bgShell.stderr)
+		var err error
+		if stdin != nil {
+			err = shell.ExecInput(shellCtx, command, stdin, bgShell.stdout, bgShell.stderr)
+			stdin.Close()
+			bgShell.stdin.Close()
+		} else {
+			err = shell.ExecStream(shellCtx, command, bgShell.stdout, bgShell.stderr)
+		}
 
 		bgShell.exitErr = err
 		atomic.StoreInt64(&bgShell.completedAt, time.Now().Unix())
+		close(bgShell.done)
+
+		if bgShell.notifyExit.Load() {
+			bgShell.publishExit()
+		}
 	}()
 
 	return bgShell, nil
 }
 
+// NotifyExit makes the shell publish a BackgroundShellExit when it exits, or
+// right away if it already has. Shells are only worth notifying about once
+// they're handed over as background jobs.
+func (bs *BackgroundShell) NotifyExit() {
+	bs.notifyExit.Store(true)
+	select {
+	case <-bs.done:
+		bs.publishExit()
+	default:
+	}
+}
+
+func (bs *BackgroundShell) publishExit() {
+	bs.publishOnce.Do(func() {
+		// Killed shells exit because they were asked to.
+		if bs.ctx.Err() != nil {
+			return
+		}
+		exitBroker.Publish(pubsub.CreatedEvent, BackgroundShellExit{
+			ID:          bs.ID,
+			Command:     bs.Command,
+			Description: bs.Description,
+			ExitCode:    ExitCode(bs.exitErr),
+		})
+	})
+}
+
 // Get retrieves a background shell by ID.
 func (m *BackgroundShellManager) Get(id string) (*BackgroundShell, bool) {
 	return m.shells.Get(id)
@@ -141,6 +361,15 @@ func (m *BackgroundShellManager) List() []string {
 	return ids
 }
 
+// Jobs returns the background shells, the oldest first.
+func (m *BackgroundShellManager) Jobs() []*BackgroundShell {
+	jobs := slices.Collect(m.shells.Seq())
+	slices.SortFunc(jobs, func(a, b *BackgroundShell) int {
+		return cmp.Or(cmp.Compare(len(a.ID), len(b.ID)), cmp.Compare(a.ID, b.ID))
+	})
+	return jobs
+}
+
 // Cleanup removes completed jobs that have been finished for more than the retention period
 func (m *BackgroundShellManager) Cleanup() int {
 	now := time.Now().Unix()
@@ -195,6 +424,109 @@ func (bs *BackgroundShell) IsDone() bool {
 	}
 }
 
+// MarkSeen marks the output of the shell written so far, and its exit if it
+// exited, as looked at.
+func (bs *BackgroundShell) MarkSeen() {
+	// Read whether it's done first, not to miss the last output.
+	done := bs.IsDone()
+	bs.seen.Store(int64(bs.stdout.Len() + bs.stderr.Len()))
+	bs.seenDone.Store(done)
+}
+
+// Unread reports whether the shell wrote or exited since MarkSeen was last
+// called. Commands the agent is still waiting for aren't jobs yet, their
+// output is read by the agent.
+func (bs *BackgroundShell) Unread() bool {
+	if !bs.notifyExit.Load() && !bs.Interactive() {
+		return false
+	}
+	if bs.IsDone() && !bs.seenDone.Load() {
+		return true
+	}
+	return int64(bs.stdout.Len()+bs.stderr.Len()) > bs.seen.Load()
+}
+
+// Runaway reports whether the shell was found writing in a loop and is
+// slowed down, not kept yet.
+func (bs *BackgroundShell) Runaway() bool {
+	bs.watchdog.mu.Lock()
+	defer bs.watchdog.mu.Unlock()
+	return bs.watchdog.runaway && !bs.watchdog.kept
+}
+
+// Keep lets the shell write as fast as it wants again, for good.
+func (bs *BackgroundShell) Keep() {
+	bs.watchdog.mu.Lock()
+	defer bs.watchdog.mu.Unlock()
+	bs.watchdog.kept = true
+}
+
+// Interactive reports whether the shell takes input.
+func (bs *BackgroundShell) Interactive() bool {
+	return bs.stdin != nil
+}
+
+// sendTimeout bounds how long Send waits for the program to read its input.
+const sendTimeout = 5 * time.Second
+
+// Send writes input to the standard input of an interactive shell.
+func (bs *BackgroundShell) Send(input string) error {
+	if bs.stdin == nil {
+		return errors.New("the job doesn't take input, it wasn't started as interactive")
+	}
+	if bs.IsDone() {
+		return errors.New("the job has exited")
+	}
+	_ = bs.stdin.SetWriteDeadline(time.Now().Add(sendTimeout))
+	if _, err := bs.stdin.WriteString(input); err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return errors.New("the job isn't reading its input")
+		}
+		return err
+	}
+	return nil
+}
+
+// CloseInput closes the standard input of an interactive shell, like ctrl+d
+// in a terminal.
+func (bs *BackgroundShell) CloseInput() error {
+	if bs.stdin == nil {
+		return errors.New("the job doesn't take input, it wasn't started as interactive")
+	}
+	return bs.stdin.Close()
+}
+
+// WaitFor waits until pattern matches the output written to stdout or
+// stderr past the given offsets and returns the match, failing when the
+// shell exits without it or ctx is done first.
+func (bs *BackgroundShell) WaitFor(ctx context.Context, pattern *regexp.Regexp, stdoutOffset, stderrOffset int) (string, error) {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		// Read whether it's done first, not to miss the last output.
+		done := bs.IsDone()
+		for _, out := range []struct {
+			text   string
+			offset int
+		}{
+			{bs.stdout.String(), stdoutOffset},
+			{bs.stderr.String(), stderrOffset},
+		} {
+			if match := pattern.FindString(out.text[min(out.offset, len(out.text)):]); match != "" {
+				return match, nil
+			}
+		}
+		if done {
+			return "", fmt.Errorf("the job exited before writing %q", pattern)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 // Wait blocks until the background shell completes.
 func (bs *BackgroundShell) Wait() {
 	<-bs.done
@@ -2,6 +2,7 @@ package shell
 
 import (
 	"context"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -219,6 +220,138 @@ func TestBackgroundShellManager_List(t *testing.T) {
 	manager.Kill(bgShell2.ID)
 }
 
+func TestBackgroundShellManager_Jobs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping flacky test on windows")
+	}
+
+	t.Parallel()
+
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	manager := GetBackgroundShellManager()
+
+	first, err := manager.Start(ctx, workingDir, nil, "sleep 1", "")
+	if err != nil {
+		t.Fatalf("failed to start first background shell: %v", err)
+	}
+	second, err := manager.Start(ctx, workingDir, nil, "sleep 1", "")
+	if err != nil {
+		t.Fatalf("failed to start second background shell: %v", err)
+	}
+
+	// Other tests start jobs too, only the order of these two matters.
+	var order []string
+	for _, job := range manager.Jobs() {
+		if job == first || job == second {
+			order = append(order, job.ID)
+		}
+	}
+	if len(order) != 2 || order[0] != first.ID || order[1] != second.ID {
+		t.Errorf("expected jobs %s then %s, got %v", first.ID, second.ID, order)
+	}
+
+	manager.Kill(first.ID)
+	manager.Kill(second.ID)
+}
+
+func TestBackgroundShell_Send(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping flacky test on windows")
+	}
+
+	t.Parallel()
+
+	ctx := context.Background()
+	manager := GetBackgroundShellManager()
+
+	bgShell, err := manager.StartInteractive(ctx, t.TempDir(), nil, "while read line; do echo \"got $line\"; done", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.Kill(bgShell.ID)
+
+	if err := bgShell.Send("hello\n"); err != nil {
+		t.Fatalf("failed to send input: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stdout, _, _, _ := bgShell.GetOutput()
+		if stdout == "got hello\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the input echoed, got %q", stdout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := bgShell.CloseInput(); err != nil {
+		t.Fatalf("failed to close input: %v", err)
+	}
+	bgShell.Wait()
+	if err := bgShell.Send("again\n"); err == nil {
+		t.Error("expected an error sending to an exited job")
+	}
+
+	plain, err := manager.Start(ctx, t.TempDir(), nil, "sleep 1", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.Kill(plain.ID)
+	if err := plain.Send("hello\n"); err == nil {
+		t.Error("expected an error sending to a job that isn't interactive")
+	}
+}
+
+func TestBackgroundShell_WaitFor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping flacky test on windows")
+	}
+
+	t.Parallel()
+
+	ctx := context.Background()
+	manager := GetBackgroundShellManager()
+
+	bgShell, err := manager.StartInteractive(ctx, t.TempDir(), nil, "echo '>>> '; while read line; do echo \"got $line\"; echo '>>> '; done", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.Kill(bgShell.ID)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := bgShell.WaitFor(waitCtx, regexp.MustCompile(`>>> `), 0, 0); err != nil {
+		t.Fatalf("expected the prompt: %v", err)
+	}
+
+	stdout, stderr, _, _ := bgShell.GetOutput()
+	if err := bgShell.Send("hello\n"); err != nil {
+		t.Fatalf("failed to send input: %v", err)
+	}
+	match, err := bgShell.WaitFor(waitCtx, regexp.MustCompile(`got \w+`), len(stdout), len(stderr))
+	if err != nil {
+		t.Fatalf("expected the input echoed: %v", err)
+	}
+	if match != "got hello" {
+		t.Errorf("expected the match, got %q", match)
+	}
+
+	shortCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := bgShell.WaitFor(shortCtx, regexp.MustCompile(`never`), 0, 0); err == nil {
+		t.Error("expected a timeout waiting for output that never comes")
+	}
+
+	if err := bgShell.CloseInput(); err != nil {
+		t.Fatalf("failed to close input: %v", err)
+	}
+	if _, err := bgShell.WaitFor(waitCtx, regexp.MustCompile(`never`), 0, 0); err == nil {
+		t.Error("expected an error once the job exits")
+	}
+}
+
 func TestBackgroundShellManager_KillAll(t *testing.T) {
 	t.Parallel()
 
@@ -280,3 +413,114 @@ func TestBackgroundShellManager_KillAll(t *testing.T) {
 		}
 	}
 }
+
+func TestBackgroundShell_NotifyExit(t *testing.T) {
+	t.Parallel()
+
+	exits := SubscribeBackgroundExits(t.Context())
+	manager := GetBackgroundShellManager()
+
+	quiet, err := manager.Start(context.Background(), t.TempDir(), nil, "exit 0", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	notified, err := manager.Start(context.Background(), t.TempDir(), nil, "exit 3", "failing job")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	// Asking after the exit publishes right away.
+	notified.Wait()
+	notified.NotifyExit()
+	quiet.Wait()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-exits:
+			if event.Payload.ID == quiet.ID {
+				t.Fatal("expected no exit event for a shell that wasn't handed over")
+			}
+			if event.Payload.ID != notified.ID {
+				continue
+			}
+			if event.Payload.ExitCode != 3 || event.Payload.Description != "failing job" {
+				t.Errorf("unexpected exit event: %+v", event.Payload)
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for the exit event")
+		}
+	}
+}
+
+func TestBackgroundShell_Unread(t *testing.T) {
+	t.Parallel()
+
+	manager := GetBackgroundShellManager()
+	bgShell, err := manager.Start(context.Background(), t.TempDir(), nil, "echo hello", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.Remove(bgShell.ID)
+	bgShell.Wait()
+	if bgShell.Unread() {
+		t.Error("expected the output of a shell the agent waits for not to be unread")
+	}
+
+	bgShell.NotifyExit()
+	if !bgShell.Unread() {
+		t.Error("expected the output and exit of a job to be unread")
+	}
+	bgShell.MarkSeen()
+	if bgShell.Unread() {
+		t.Error("expected nothing unread once seen")
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	t.Parallel()
+
+	var found int
+	w := &watchdog{
+		second:    time.Now().Add(-time.Second),
+		written:   RunawayRate,
+		hot:       runawaySeconds - 1,
+		done:      make(chan struct{}),
+		onRunaway: func() { found++ },
+	}
+	w.wrote(1)
+	if found != 1 || !w.runaway {
+		t.Fatalf("expected the shell to be found writing in a loop once, got %d", found)
+	}
+
+	start := time.Now()
+	w.wrote(ThrottledRate / 10)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected the writes of a runaway to be slowed down, took %s", elapsed)
+	}
+
+	w.kept = true
+	start = time.Now()
+	w.wrote(ThrottledRate)
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected the writes of a kept shell not to be slowed down, took %s", elapsed)
+	}
+	if found != 1 {
+		t.Errorf("expected the runaway to be reported once, got %d", found)
+	}
+}
+
+func TestWatchdog_Quiet(t *testing.T) {
+	t.Parallel()
+
+	w := &watchdog{
+		second:  time.Now().Add(-2 * time.Second),
+		written: RunawayRate,
+		hot:     runawaySeconds - 1,
+		done:    make(chan struct{}),
+	}
+	w.wrote(1)
+	if w.runaway || w.hot != 1 {
+		t.Errorf("expected a second without output to start counting again, got %d seconds", w.hot)
+	}
+}
@@ -0,0 +1,282 @@
+// Package spectate watches a running Crush instance over its API, printing
+// the conversation of the session being worked on as it goes.
+package spectate
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/apiserver"
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// SocketName is the name of the unix socket a Crush instance is spectated
+// over, in its data directory.
+const SocketName = "spectate.sock"
+
+// maxEvent bounds the size of a single event, tool results included.
+const maxEvent = 16 << 20
+
+// Client reaches the API of a Crush instance.
+type Client struct {
+	http    *http.Client
+	baseURL string
+	token   string
+}
+
+// NewClient creates a client for the API served at baseURL.
+func NewClient(baseURL, token string) *Client {
+	return &Client{http: &http.Client{}, baseURL: strings.TrimSuffix(baseURL, "/"), token: token}
+}
+
+// NewSocketClient creates a client for the API served on a unix socket,
+// sending the token when there's one, for sockets outside private
+// directories.
+func NewSocketClient(path, token string) *Client {
+	var dialer net.Dialer
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}
+	return &Client{http: &http.Client{Transport: transport}, baseURL: "http://crush", token: token}
+}
+
+// Watch prints the session last worked on, then follows the events of the
+// instance, switching to whichever session gets messages, until ctx is done
+// or the instance goes away.
+func (c *Client) Watch(ctx context.Context, out io.Writer) error {
+	w := newWatcher(out)
+
+	// Subscribe first so nothing happening while the history loads is lost.
+	events, err := c.get(ctx, "/v1/events")
+	if err != nil {
+		return err
+	}
+	defer events.Close()
+
+	var sessions []apiserver.Session
+	if err := c.getJSON(ctx, "/v1/sessions", &sessions); err != nil {
+		return err
+	}
+	sessions = slices.DeleteFunc(sessions, func(s apiserver.Session) bool {
+		return s.ParentSessionID != ""
+	})
+	if len(sessions) == 0 {
+		fmt.Fprintln(out, "Waiting for a session to start...")
+	} else {
+		latest := slices.MaxFunc(sessions, func(a, b apiserver.Session) int {
+			return cmp.Compare(a.UpdatedAt, b.UpdatedAt)
+		})
+		if err := c.follow(ctx, w, latest); err != nil {
+			return err
+		}
+	}
+
+	scanner := bufio.NewScanner(events)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEvent)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var e struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			continue
+		}
+		switch e.Type {
+		case apiserver.EventSession:
+			var s apiserver.Session
+			if err := json.Unmarshal(e.Data, &s); err == nil && s.ParentSessionID == "" {
+				w.sessions[s.ID] = s
+			}
+		case apiserver.EventMessage:
+			var m apiserver.Message
+			if err := json.Unmarshal(e.Data, &m); err != nil {
+				continue
+			}
+			if m.SessionID != w.session {
+				s, err := c.session(ctx, w, m.SessionID)
+				if err != nil || s.ParentSessionID != "" {
+					// Sub-agents show in the tool calls of their parent.
+					continue
+				}
+				w.switchTo(s)
+			}
+			w.message(m)
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	if ctx.Err() == nil {
+		fmt.Fprintln(out, "\nCrush went away.")
+	}
+	return nil
+}
+
+// follow switches to a session and prints its history.
+func (c *Client) follow(ctx context.Context, w *watcher, s apiserver.Session) error {
+	var msgs []apiserver.Message
+	if err := c.getJSON(ctx, "/v1/sessions/"+s.ID+"/messages", &msgs); err != nil {
+		return err
+	}
+	w.switchTo(s)
+	for _, m := range msgs {
+		w.message(m)
+	}
+	return nil
+}
+
+// session returns the session with the given ID, asking the instance for
+// those it didn't announce.
+func (c *Client) session(ctx context.Context, w *watcher, id string) (apiserver.Session, error) {
+	if s, ok := w.sessions[id]; ok {
+		return s, nil
+	}
+	var s apiserver.Session
+	if err := c.getJSON(ctx, "/v1/sessions/"+id, &s); err != nil {
+		return apiserver.Session{}, err
+	}
+	w.sessions[id] = s
+	return s, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	body, err := c.get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+func (c *Client) get(ctx context.Context, path string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr apiserver.Error
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return nil, errors.New(apiErr.Error)
+		}
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// watcher prints the messages of a session, only what's new in those
+// streamed as they update.
+type watcher struct {
+	out      io.Writer
+	session  string
+	sessions map[string]apiserver.Session
+
+	// printed is how much of the text of each message was printed.
+	printed map[string]int
+	// done holds the finished messages and the tool calls and results
+	// printed.
+	done map[string]bool
+	// midLine is whether the last text printed didn't end its line.
+	midLine bool
+}
+
+func newWatcher(out io.Writer) *watcher {
+	return &watcher{
+		out:      out,
+		sessions: map[string]apiserver.Session{},
+		printed:  map[string]int{},
+		done:     map[string]bool{},
+	}
+}
+
+func (w *watcher) switchTo(s apiserver.Session) {
+	w.session = s.ID
+	w.sessions[s.ID] = s
+	w.print("\n── " + cmp.Or(s.Title, "New Session") + " ──\n")
+}
+
+func (w *watcher) message(m apiserver.Message) {
+	switch m.Role {
+	case message.User:
+		if _, ok := w.printed[m.ID]; ok {
+			return
+		}
+		w.printed[m.ID] = len(m.Text)
+		w.print("\n> " + strings.ReplaceAll(strings.TrimSpace(m.Text), "\n", "\n> ") + "\n")
+	case message.Assistant:
+		printed, seen := w.printed[m.ID]
+		if !seen {
+			w.print("\n")
+			w.printed[m.ID] = 0
+		}
+		if len(m.Text) > printed {
+			w.print(m.Text[printed:])
+			w.printed[m.ID] = len(m.Text)
+		}
+		for _, tc := range m.ToolCalls {
+			if !w.done[tc.ID] {
+				w.done[tc.ID] = true
+				w.line("  → " + tc.Name)
+			}
+		}
+		if m.Finish != nil && !w.done[m.ID] {
+			w.done[m.ID] = true
+			w.endLine()
+		}
+	case message.Tool:
+		for _, tr := range m.ToolResults {
+			if w.done["result:"+tr.ToolCallID] {
+				continue
+			}
+			w.done["result:"+tr.ToolCallID] = true
+			mark := "✓"
+			if tr.IsError {
+				mark = "✗"
+			}
+			w.line("  " + mark + " " + tr.Name)
+		}
+	}
+}
+
+// line prints text on a line of its own.
+func (w *watcher) line(text string) {
+	w.endLine()
+	w.print(text + "\n")
+}
+
+// endLine ends the line streamed text left open.
+func (w *watcher) endLine() {
+	if w.midLine {
+		w.print("\n")
+	}
+}
+
+func (w *watcher) print(text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprint(w.out, text)
+	w.midLine = !strings.HasSuffix(text, "\n")
+}
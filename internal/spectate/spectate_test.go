@@ -0,0 +1,28 @@
+package spectate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/apiserver"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	w := newWatcher(&out)
+	w.switchTo(apiserver.Session{ID: "s", Title: "List the files"})
+
+	w.message(apiserver.Message{ID: "u", Role: message.User, Text: "List the files"})
+	w.message(apiserver.Message{ID: "a", Role: message.Assistant, Text: "Let me"})
+	w.message(apiserver.Message{ID: "a", Role: message.Assistant, Text: "Let me look."})
+	w.message(apiserver.Message{ID: "a", Role: message.Assistant, Text: "Let me look.", ToolCalls: []message.ToolCall{{ID: "c", Name: "ls"}}})
+	w.message(apiserver.Message{ID: "a", Role: message.Assistant, Text: "Let me look.", ToolCalls: []message.ToolCall{{ID: "c", Name: "ls"}}, Finish: &message.Finish{}})
+	w.message(apiserver.Message{ID: "t", Role: message.Tool, ToolResults: []message.ToolResult{{ToolCallID: "c", Name: "ls"}}})
+	w.message(apiserver.Message{ID: "t", Role: message.Tool, ToolResults: []message.ToolResult{{ToolCallID: "c", Name: "ls"}}})
+
+	require.Equal(t, "\n── List the files ──\n\n> List the files\n\nLet me look.\n  → ls\n  ✓ ls\n", out.String())
+}
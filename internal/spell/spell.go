@@ -0,0 +1,267 @@
+// Package spell checks the spelling of the prose in prompts against a word
+// list. Code, paths, URLs, mentions and identifiers aren't prose and are
+// never reported.
+package spell
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultDictionary is the word list most Unix systems ship.
+const DefaultDictionary = "/usr/share/dict/words"
+
+// maxDistance is the largest number of edits between a misspelled word and
+// its suggestions.
+const maxDistance = 2
+
+// Checker knows a set of correctly spelled words.
+type Checker struct {
+	words map[string]struct{}
+}
+
+// New returns a checker knowing the given words.
+func New(words ...string) *Checker {
+	c := &Checker{words: make(map[string]struct{}, len(words))}
+	c.Add(words...)
+	return c
+}
+
+// Load returns a checker knowing the words of the dictionary file, one per
+// line, and the extra words.
+func Load(path string, extra []string) (*Checker, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := New(extra...)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		c.Add(strings.TrimSpace(scanner.Text()))
+	}
+	return c, scanner.Err()
+}
+
+// Add teaches the checker more words.
+func (c *Checker) Add(words ...string) {
+	for _, word := range words {
+		if word != "" {
+			c.words[strings.ToLower(word)] = struct{}{}
+		}
+	}
+}
+
+// Correct reports whether word is spelled correctly. Possessives and
+// hyphenated words are checked by their parts.
+func (c *Checker) Correct(word string) bool {
+	lower := strings.ToLower(word)
+	if _, ok := c.words[lower]; ok {
+		return true
+	}
+	if base, ok := strings.CutSuffix(lower, "'s"); ok && base != "" {
+		return c.Correct(base)
+	}
+	if strings.Contains(lower, "-") {
+		for part := range strings.SplitSeq(lower, "-") {
+			if part != "" && !c.Correct(part) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Word is a word of a text, at the byte offsets Start and End.
+type Word struct {
+	Text       string
+	Start, End int
+}
+
+// Misspelled returns the misspelled words of text, in order.
+func (c *Checker) Misspelled(text string) []Word {
+	var misspelled []Word
+	for _, word := range Words(text) {
+		if !c.Correct(word.Text) {
+			misspelled = append(misspelled, word)
+		}
+	}
+	return misspelled
+}
+
+// Words returns the words of text that are prose. Code spans and blocks,
+// URLs, paths, @-mentions, #-references, identifiers, acronyms and words
+// with digits are skipped.
+func Words(text string) []Word {
+	var words []Word
+	inFence := false
+	offset := 0
+	for line := range strings.Lines(text) {
+		start := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		inCode := false
+		i := 0
+		for i < len(line) {
+			if line[i] == '`' {
+				inCode = !inCode
+				i++
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(line[i:])
+			if inCode || unicode.IsSpace(r) {
+				i += size
+				continue
+			}
+			end := i
+			for end < len(line) {
+				r, size := utf8.DecodeRuneInString(line[end:])
+				if unicode.IsSpace(r) || r == '`' {
+					break
+				}
+				end += size
+			}
+			if word, ok := prose(line[i:end]); ok {
+				wordStart := start + i + strings.Index(line[i:end], word)
+				words = append(words, Word{Text: word, Start: wordStart, End: wordStart + len(word)})
+			}
+			i = end
+		}
+	}
+	return words
+}
+
+// prose returns the word in field, a run of text between spaces, without
+// the punctuation around it, and whether it is prose at all.
+func prose(field string) (string, bool) {
+	if strings.HasPrefix(field, "@") || strings.HasPrefix(field, "#") ||
+		strings.Contains(field, "://") || strings.ContainsAny(field, "/\\_<>{}[]=~$%") {
+		return "", false
+	}
+	word := strings.TrimFunc(field, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if utf8.RuneCountInString(word) < 2 {
+		return "", false
+	}
+	for i, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			// Upper case letters after the first mark identifiers like
+			// camelCase and acronyms.
+			if i > 0 {
+				return "", false
+			}
+		case r == '\'' || r == '’' || r == '-' || unicode.IsLetter(r):
+		default:
+			// Dots, digits and the like mark file names, versions and
+			// code.
+			return "", false
+		}
+	}
+	return strings.ReplaceAll(word, "’", "'"), true
+}
+
+// Suggest returns up to limit correctly spelled words close to word,
+// closest first. They are capitalized like word.
+func (c *Checker) Suggest(word string, limit int) []string {
+	type candidate struct {
+		word     string
+		distance int
+		// anagram marks words with the same letters, which are most
+		// likely what was meant.
+		anagram bool
+	}
+	lower := strings.ToLower(word)
+	length := utf8.RuneCountInString(lower)
+	letters := sortedLetters(lower)
+	var candidates []candidate
+	for known := range c.words {
+		if abs(utf8.RuneCountInString(known)-length) > maxDistance {
+			continue
+		}
+		if d := distance(lower, known); d <= maxDistance && d > 0 {
+			candidates = append(candidates, candidate{known, d, sortedLetters(known) == letters})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		if a.anagram != b.anagram {
+			if a.anagram {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.word, b.word)
+	})
+	suggestions := make([]string, 0, min(limit, len(candidates)))
+	for _, candidate := range candidates[:min(limit, len(candidates))] {
+		suggestions = append(suggestions, capitalizeLike(candidate.word, word))
+	}
+	return suggestions
+}
+
+func sortedLetters(word string) string {
+	letters := []rune(word)
+	slices.Sort(letters)
+	return string(letters)
+}
+
+// capitalizeLike capitalizes word when like is.
+func capitalizeLike(word, like string) string {
+	first, _ := utf8.DecodeRuneInString(like)
+	if !unicode.IsUpper(first) {
+		return word
+	}
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// distance returns the Damerau-Levenshtein distance between a and b, where
+// swapping two letters is a single edit.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
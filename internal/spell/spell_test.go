@@ -0,0 +1,60 @@
+package spell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWords(t *testing.T) {
+	t.Parallel()
+
+	text := "Fix the `parseFlags` bug in @internal/app.go, see https://example.com and #42.\n" +
+		"```go\nfunc mispeled() {}\n```\n" +
+		"Don't touch snake_case, camelCase, HTTP or v1.2, thanks!"
+	var got []string
+	for _, word := range Words(text) {
+		got = append(got, word.Text)
+		require.Equal(t, word.Text, text[word.Start:word.End])
+	}
+	require.Equal(t, []string{"Fix", "the", "bug", "in", "see", "and", "Don't", "touch", "or", "thanks"}, got)
+}
+
+func TestMisspelled(t *testing.T) {
+	t.Parallel()
+
+	c := New("please", "fix", "the", "tests", "crush", "well-known")
+	misspelled := c.Misspelled("Plese fix teh tests, Crush's well-known.")
+	require.Equal(t, []Word{
+		{Text: "Plese", Start: 0, End: 5},
+		{Text: "teh", Start: 10, End: 13},
+	}, misspelled)
+}
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+
+	c := New("the", "then", "ten", "tea", "receive", "deceive")
+	require.Equal(t, []string{"the", "tea", "ten"}, c.Suggest("teh", 3))
+	require.Equal(t, []string{"Receive", "Deceive"}, c.Suggest("Recieve", 2))
+	require.Empty(t, c.Suggest("zzzzzz", 3))
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "words")
+	require.NoError(t, os.WriteFile(path, []byte("Hello\nworld\n"), 0o644))
+
+	c, err := Load(path, []string{"Crush"})
+	require.NoError(t, err)
+	require.True(t, c.Correct("hello"))
+	require.True(t, c.Correct("World"))
+	require.True(t, c.Correct("crush"))
+	require.False(t, c.Correct("helo"))
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing"), nil)
+	require.Error(t, err)
+}
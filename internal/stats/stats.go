@@ -0,0 +1,157 @@
+// Package stats computes how the project was worked on with Crush, from the
+// sessions in its database. Nothing leaves the machine.
+package stats
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+)
+
+// Weeks is how many weeks, up to the current one, the statistics cover.
+const Weeks = 12
+
+const week = 7 * 24 * time.Hour
+
+// Series counts something per week, from the oldest week to the current
+// one.
+type Series struct {
+	Name   string
+	Weekly []int64
+	Total  int64
+}
+
+// Stats are the statistics of the last weeks.
+type Stats struct {
+	// Since is when the first week covered starts.
+	Since time.Time
+	// Sessions are the sessions started, sub-agents aside.
+	Sessions Series
+	// Models are the tokens sent and received per model, the most used
+	// first.
+	Models []Series
+	// Tools are the calls of each tool, the most called first.
+	Tools []Series
+	// Files are the edits of each file, the most edited first.
+	Files []Series
+	// Latency is the average time to respond to a turn, per week, in
+	// milliseconds; 0 for weeks without responses.
+	Latency Series
+	// AvgLatency is the average time to respond over the weeks covered.
+	AvgLatency time.Duration
+}
+
+type Service interface {
+	// Load computes the statistics of the Weeks up to now.
+	Load(ctx context.Context, now time.Time) (Stats, error)
+}
+
+type service struct {
+	q db.Querier
+}
+
+func NewService(q db.Querier) Service {
+	return &service{q: q}
+}
+
+func (s *service) Load(ctx context.Context, now time.Time) (Stats, error) {
+	first := now.Unix()/int64(week.Seconds()) - (Weeks - 1)
+	since := first * int64(week.Seconds())
+	st := Stats{
+		Since:    time.Unix(since, 0),
+		Sessions: Series{Name: "Sessions", Weekly: make([]int64, Weeks)},
+		Latency:  Series{Name: "Latency", Weekly: make([]int64, Weeks)},
+	}
+
+	sessions, err := s.q.WeeklySessions(ctx, since)
+	if err != nil {
+		return Stats{}, err
+	}
+	for _, row := range sessions {
+		st.Sessions.add(row.Week-first, row.Count)
+	}
+
+	models, err := s.q.WeeklyModelTokens(ctx, since)
+	if err != nil {
+		return Stats{}, err
+	}
+	byModel := map[string]*Series{}
+	for _, row := range models {
+		if row.Model != "" {
+			series(byModel, row.Model).add(row.Week-first, row.Tokens)
+		}
+	}
+	st.Models = sorted(byModel)
+
+	tools, err := s.q.WeeklyToolCalls(ctx, since)
+	if err != nil {
+		return Stats{}, err
+	}
+	byTool := map[string]*Series{}
+	for _, row := range tools {
+		if row.Name != "" {
+			series(byTool, row.Name).add(row.Week-first, row.Count)
+		}
+	}
+	st.Tools = sorted(byTool)
+
+	files, err := s.q.WeeklyFileEdits(ctx, since)
+	if err != nil {
+		return Stats{}, err
+	}
+	byFile := map[string]*Series{}
+	for _, row := range files {
+		series(byFile, row.Path).add(row.Week-first, row.Count)
+	}
+	st.Files = sorted(byFile)
+
+	latency, err := s.q.WeeklyLatency(ctx, since)
+	if err != nil {
+		return Stats{}, err
+	}
+	var totalMs, responses int64
+	for _, row := range latency {
+		if i := row.Week - first; i >= 0 && i < Weeks {
+			st.Latency.Weekly[i] = row.LatencyMs
+			totalMs += row.LatencyMs * row.Count
+			responses += row.Count
+		}
+	}
+	if responses > 0 {
+		st.AvgLatency = time.Duration(totalMs/responses) * time.Millisecond
+	}
+	return st, nil
+}
+
+// add counts n in week i of the series, ignoring weeks out of range.
+func (s *Series) add(i, n int64) {
+	if i < 0 || i >= int64(len(s.Weekly)) {
+		return
+	}
+	s.Weekly[i] += n
+	s.Total += n
+}
+
+func series(m map[string]*Series, name string) *Series {
+	s, ok := m[name]
+	if !ok {
+		s = &Series{Name: name, Weekly: make([]int64, Weeks)}
+		m[name] = s
+	}
+	return s
+}
+
+// sorted returns the series the largest first, by name when equal.
+func sorted(m map[string]*Series) []Series {
+	list := make([]Series, 0, len(m))
+	for _, s := range m {
+		list = append(list, *s)
+	}
+	slices.SortFunc(list, func(a, b Series) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.Name, b.Name))
+	})
+	return list
+}
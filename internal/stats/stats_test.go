@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+
+	_, err = q.CreateSession(t.Context(), db.CreateSessionParams{ID: "session", Title: "Stats"})
+	require.NoError(t, err)
+	messages := message.NewService(q)
+	for _, latency := range []time.Duration{time.Second, 3 * time.Second} {
+		msg, err := messages.Create(t.Context(), "session", message.CreateMessageParams{
+			Role:  message.Assistant,
+			Model: "gpt-4o",
+			Parts: []message.ContentPart{
+				message.ToolCall{ID: "a", Name: "view"},
+				message.ToolCall{ID: "b", Name: "edit"},
+				message.ToolCall{ID: "c", Name: "edit"},
+			},
+		})
+		require.NoError(t, err)
+		msg.PromptTokens, msg.CompletionTokens, msg.Latency = 100, 20, latency
+		require.NoError(t, messages.Update(t.Context(), msg))
+	}
+	files := history.NewService(q, conn)
+	_, err = files.Create(t.Context(), "session", "/src/main.go", "package main")
+	require.NoError(t, err)
+	_, err = files.CreateVersion(t.Context(), "session", "/src/main.go", "package main\n")
+	require.NoError(t, err)
+
+	st, err := NewService(q).Load(t.Context(), time.Now())
+	require.NoError(t, err)
+
+	require.Len(t, st.Sessions.Weekly, Weeks)
+	require.Equal(t, int64(1), st.Sessions.Weekly[Weeks-1])
+	require.Equal(t, []Series{{Name: "gpt-4o", Weekly: st.Models[0].Weekly, Total: 240}}, st.Models)
+	require.Equal(t, "edit", st.Tools[0].Name)
+	require.Equal(t, int64(4), st.Tools[0].Total)
+	require.Equal(t, "view", st.Tools[1].Name)
+	require.Equal(t, []Series{{Name: "/src/main.go", Weekly: st.Files[0].Weekly, Total: 1}}, st.Files)
+	require.Equal(t, 2*time.Second, st.AvgLatency)
+	require.Equal(t, int64(2000), st.Latency.Weekly[Weeks-1])
+}
@@ -0,0 +1,71 @@
+package symbols
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// rule matches a declaration on a single line. The expression captures the
+// symbol in a "name" group, and the kind in a "kind" group when kind is
+// empty.
+type rule struct {
+	re   *regexp.Regexp
+	kind string
+}
+
+type language []rule
+
+var (
+	golang = language{
+		{regexp.MustCompile(`^func\s+\([^)]*\)\s*(?P<name>\w+)`), "method"},
+		{regexp.MustCompile(`^func\s+(?P<name>\w+)`), "func"},
+		{regexp.MustCompile(`^type\s+(?P<name>\w+)`), "type"},
+		{regexp.MustCompile(`^(?P<kind>var|const)\s+(?P<name>\w+)`), ""},
+	}
+	python = language{
+		{regexp.MustCompile(`^\s*(?:async\s+)?def\s+(?P<name>\w+)`), "func"},
+		{regexp.MustCompile(`^\s*class\s+(?P<name>\w+)`), "class"},
+	}
+	javascript = language{
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+(?P<name>[\w$]+)`), "func"},
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(?P<name>[\w$]+)`), "class"},
+		{regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?(?P<kind>interface|type|enum)\s+(?P<name>[\w$]+)`), ""},
+		{regexp.MustCompile(`^\s*export\s+(?:const|let|var)\s+(?P<name>[\w$]+)`), "var"},
+	}
+	rust = language{
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+(?P<name>\w+)`), "func"},
+		{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?P<kind>struct|enum|trait|type|mod|union)\s+(?P<name>\w+)`), ""},
+	}
+	ruby = language{
+		{regexp.MustCompile(`^\s*def\s+(?:self\.)?(?P<name>\w+[?!=]?)`), "method"},
+		{regexp.MustCompile(`^\s*(?P<kind>class|module)\s+(?P<name>[\w:]+)`), ""},
+	}
+	jvm = language{
+		{regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|abstract|sealed|data|open|partial)\s+)*(?P<kind>class|interface|enum|record|struct|object)\s+(?P<name>\w+)`), ""},
+		{regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|override|suspend|inline)\s+)*fun\s+(?:<[^>]*>\s*)?(?:\w+\.)?(?P<name>\w+)`), "func"},
+	}
+)
+
+var languages = map[string]language{
+	".go":    golang,
+	".py":    python,
+	".js":    javascript,
+	".jsx":   javascript,
+	".mjs":   javascript,
+	".cjs":   javascript,
+	".ts":    javascript,
+	".tsx":   javascript,
+	".mts":   javascript,
+	".rs":    rust,
+	".rb":    ruby,
+	".java":  jvm,
+	".kt":    jvm,
+	".scala": jvm,
+	".cs":    jvm,
+}
+
+func languageFor(path string) (language, bool) {
+	lang, ok := languages[strings.ToLower(filepath.Ext(path))]
+	return lang, ok
+}
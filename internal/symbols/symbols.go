@@ -0,0 +1,303 @@
+// Package symbols indexes the symbols declared in a workspace so they can be
+// found by name.
+//
+// The index is built with universal-ctags when it's installed, and from a
+// set of declaration patterns for common languages otherwise. The index is kept until a file
+// in it is written or changes.
+package symbols
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/fsext"
+)
+
+// maxFiles is the number of files indexed at most.
+const maxFiles = 20000
+
+// Symbol is a named declaration in the workspace.
+type Symbol struct {
+	Name string
+	// Kind is the kind of declaration, e.g. func, type, class.
+	Kind string
+	// Scope is the enclosing declaration, when known.
+	Scope string
+	Path  string
+	// Line is 1-based.
+	Line int
+}
+
+// cache holds the index of each directory until a file in it changes.
+var cache = struct {
+	sync.Mutex
+	indexes map[string][]Symbol
+	// generation counts the invalidations, so an index built while a file
+	// changed isn't kept.
+	generation uint64
+}{indexes: make(map[string][]Symbol)}
+
+// Index returns the symbols declared in the files under dir, honoring
+// .gitignore and .crushignore. The index is built once, until Invalidate
+// is called for a file under dir.
+func Index(ctx context.Context, dir string) ([]Symbol, error) {
+	dir = filepath.Clean(dir)
+	cache.Lock()
+	symbols, ok := cache.indexes[dir]
+	generation := cache.generation
+	cache.Unlock()
+	if ok {
+		return symbols, nil
+	}
+
+	symbols, err := index(ctx, dir)
+	if err != nil || ctx.Err() != nil {
+		// A canceled index may be missing files.
+		return symbols, err
+	}
+	cache.Lock()
+	if cache.generation == generation {
+		cache.indexes[dir] = symbols
+	}
+	cache.Unlock()
+	return symbols, nil
+}
+
+// Invalidate drops the indexes of the directories containing path, e.g.
+// when it was written, so they are built again.
+func Invalidate(path string) {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.generation++
+	for dir := range cache.indexes {
+		if !filepath.IsAbs(path) || contains(dir, path) {
+			delete(cache.indexes, dir)
+		}
+	}
+}
+
+// contains reports whether path is under dir.
+func contains(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func index(ctx context.Context, dir string) ([]Symbol, error) {
+	files, err := listFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if symbols, err := indexWithCtags(ctx, dir, files); err == nil {
+		return symbols, nil
+	}
+	return indexWithPatterns(ctx, files), nil
+}
+
+func listFiles(dir string) ([]string, error) {
+	walker := fsext.NewFastGlobWalker(dir)
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors
+		}
+		if walker.ShouldSkip(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		files = append(files, path)
+		if len(files) >= maxFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return files, err
+}
+
+type ctagsTag struct {
+	Type  string `json:"_type"`
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Line  int    `json:"line"`
+	Kind  string `json:"kind"`
+	Scope string `json:"scope"`
+}
+
+// indexWithCtags runs universal-ctags over the files. Exuberant ctags has no
+// JSON output and makes this fail, falling back to the patterns.
+func indexWithCtags(ctx context.Context, dir string, files []string) ([]Symbol, error) {
+	ctagsPath, err := exec.LookPath("ctags")
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, ctagsPath, "--output-format=json", "--fields=+nKZ", "-L", "-", "-f", "-")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\n"))
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []Symbol
+	for line := range bytes.SplitSeq(out, []byte{'\n'}) {
+		var tag ctagsTag
+		if err := json.Unmarshal(line, &tag); err != nil || tag.Type != "tag" {
+			continue
+		}
+		path := tag.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		symbols = append(symbols, Symbol{
+			Name:  tag.Name,
+			Kind:  tag.Kind,
+			Scope: tag.Scope,
+			Path:  path,
+			Line:  tag.Line,
+		})
+	}
+	return symbols, nil
+}
+
+func indexWithPatterns(ctx context.Context, files []string) []Symbol {
+	var symbols []Symbol
+	for _, path := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		if lang, ok := languageFor(path); ok {
+			symbols = append(symbols, scanFile(path, lang)...)
+		}
+	}
+	return symbols
+}
+
+func scanFile(path string, lang language) []Symbol {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var symbols []Symbol
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		for _, rule := range lang {
+			m := rule.re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			kind := rule.kind
+			if kind == "" {
+				kind = m[rule.re.SubexpIndex("kind")]
+			}
+			symbols = append(symbols, Symbol{
+				Name: m[rule.re.SubexpIndex("name")],
+				Kind: kind,
+				Path: path,
+				Line: lineNum,
+			})
+			break
+		}
+	}
+	return symbols
+}
+
+// Filter returns up to limit symbols matching the query, best matches first.
+// Exact names rank above prefixes, then substrings, then names containing
+// the query's letters in order. A query like "config.Load" also matches the
+// scope or file of the symbol.
+func Filter(symbols []Symbol, query string, limit int) []Symbol {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	scope := ""
+	if i := strings.LastIndexAny(query, ".:"); i > 0 && i < len(query)-1 {
+		scope, query = strings.Trim(query[:i], ".:"), query[i+1:]
+	}
+
+	type scored struct {
+		symbol Symbol
+		score  int
+	}
+	var matches []scored
+	for _, s := range symbols {
+		score, ok := matchScore(s.Name, query)
+		if !ok {
+			continue
+		}
+		if scope != "" && !matchesScope(s, scope) {
+			continue
+		}
+		matches = append(matches, scored{s, score})
+	}
+	slices.SortStableFunc(matches, func(a, b scored) int {
+		return cmp.Or(
+			cmp.Compare(a.score, b.score),
+			cmp.Compare(len(a.symbol.Name), len(b.symbol.Name)),
+			cmp.Compare(a.symbol.Path, b.symbol.Path),
+			cmp.Compare(a.symbol.Line, b.symbol.Line),
+		)
+	})
+
+	result := make([]Symbol, 0, min(limit, len(matches)))
+	for _, m := range matches {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, m.symbol)
+	}
+	return result
+}
+
+func matchScore(name, query string) (int, bool) {
+	lowerName, lowerQuery := strings.ToLower(name), strings.ToLower(query)
+	switch {
+	case name == query:
+		return 0, true
+	case lowerName == lowerQuery:
+		return 1, true
+	case strings.HasPrefix(lowerName, lowerQuery):
+		return 2, true
+	case strings.Contains(lowerName, lowerQuery):
+		return 3, true
+	case isSubsequence(lowerName, lowerQuery):
+		return 4, true
+	}
+	return 0, false
+}
+
+func isSubsequence(s, sub string) bool {
+	for _, r := range sub {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+1:]
+	}
+	return true
+}
+
+func matchesScope(s Symbol, scope string) bool {
+	scope = strings.ToLower(scope)
+	if strings.Contains(strings.ToLower(s.Scope), scope) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(filepath.ToSlash(s.Path)), scope)
+}
@@ -0,0 +1,108 @@
+package symbols
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexWithPatterns(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	goFile := writeFile("service.go", `package service
+
+type UserService struct{}
+
+func NewUserService() *UserService { return nil }
+
+func (s *UserService) Get(id string) {}
+`)
+	tsFile := writeFile("web/user.ts", `export interface User {}
+export async function loadUser() {}
+`)
+	writeFile("README.md", "type NotASymbol\n")
+
+	files, err := listFiles(dir)
+	require.NoError(t, err)
+	symbols := indexWithPatterns(t.Context(), files)
+
+	require.ElementsMatch(t, []Symbol{
+		{Name: "UserService", Kind: "type", Path: goFile, Line: 3},
+		{Name: "NewUserService", Kind: "func", Path: goFile, Line: 5},
+		{Name: "Get", Kind: "method", Path: goFile, Line: 7},
+		{Name: "User", Kind: "interface", Path: tsFile, Line: 1},
+		{Name: "loadUser", Kind: "func", Path: tsFile, Line: 2},
+	}, symbols)
+}
+
+func TestIndexCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "service.go")
+	require.NoError(t, os.WriteFile(path, []byte("package service\n\nfunc Old() {}\n"), 0o644))
+	names := func() []string {
+		symbols, err := Index(t.Context(), dir)
+		require.NoError(t, err)
+		var names []string
+		for _, s := range symbols {
+			names = append(names, s.Name)
+		}
+		return names
+	}
+	require.Contains(t, names(), "Old")
+
+	require.NoError(t, os.WriteFile(path, []byte("package service\n\nfunc New() {}\n"), 0o644))
+	require.Contains(t, names(), "Old", "the index is kept until invalidated")
+
+	Invalidate(filepath.Join(t.TempDir(), "other.go"))
+	require.Contains(t, names(), "Old", "files elsewhere leave it alone")
+
+	Invalidate(path)
+	require.Contains(t, names(), "New")
+	require.NotContains(t, names(), "Old")
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	symbols := []Symbol{
+		{Name: "UserServiceImpl", Path: "a.go"},
+		{Name: "userService", Path: "b.go"},
+		{Name: "UserService", Path: "c.go"},
+		{Name: "UnusedSymbol", Path: "d.go"},
+		{Name: "Load", Path: "internal/config/load.go"},
+		{Name: "Load", Path: "internal/theme/load.go"},
+	}
+
+	names := func(symbols []Symbol) []string {
+		var names []string
+		for _, s := range symbols {
+			names = append(names, s.Name+"@"+s.Path)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{
+		"UserService@c.go",
+		"userService@b.go",
+		"UserServiceImpl@a.go",
+	}, names(Filter(symbols, "UserService", 10)))
+	require.Equal(t, []string{"UserService@c.go"}, names(Filter(symbols, "UserService", 1)))
+	require.Equal(t, []string{
+		"userService@b.go",
+		"UserService@c.go",
+		"UserServiceImpl@a.go",
+	}, names(Filter(symbols, "usvc", 10)))
+	require.Equal(t, []string{"Load@internal/config/load.go"}, names(Filter(symbols, "config.Load", 10)))
+	require.Empty(t, Filter(symbols, "  ", 10))
+}
@@ -0,0 +1,89 @@
+package task
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Item is a task found in a response of the agent.
+type Item struct {
+	Content string
+	Done    bool
+}
+
+var (
+	// listItemRe matches the items of bulleted and numbered lists, with an
+	// optional checkbox.
+	listItemRe = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s+(?:\[([ xX])\]\s+)?(.+?)\s*$`)
+	// planRe matches the lines introducing a list that is a plan.
+	planRe = regexp.MustCompile(`(?i)\b(plan|steps|todos?|to-dos?|tasks)\b`)
+)
+
+// minPlanSteps is how many items a list needs to be a plan.
+const minPlanSteps = 2
+
+// Parse finds the task list in text, the markdown of a response. Checklists
+// are tasks wherever they are, otherwise the first list introduced by a line
+// mentioning a plan, steps or tasks is, without its nested items. Code blocks
+// are skipped.
+func Parse(text string) []Item {
+	var (
+		checklist []Item
+		plan      []Item
+		steps     []Item
+		// inPlan is whether the list being read is a plan, indented as
+		// much as its items.
+		inPlan bool
+		indent int
+		intro  string
+		fence  string
+	)
+	endList := func() {
+		if plan == nil && len(steps) >= minPlanSteps {
+			plan = steps
+		}
+		steps = nil
+	}
+	for line := range strings.Lines(text) {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if trimmed == "" {
+			continue
+		}
+
+		m := listItemRe.FindStringSubmatch(line)
+		if m == nil {
+			endList()
+			intro = trimmed
+			inPlan = false
+			continue
+		}
+		item := Item{Content: m[3], Done: strings.EqualFold(m[2], "x")}
+		if m[2] != "" {
+			checklist = append(checklist, item)
+			continue
+		}
+		if steps == nil {
+			inPlan = planRe.MatchString(intro)
+			indent = len(m[1])
+		}
+		if inPlan && len(m[1]) <= indent {
+			steps = append(steps, item)
+		}
+	}
+	endList()
+
+	if len(checklist) > 0 {
+		return checklist
+	}
+	return plan
+}
@@ -0,0 +1,153 @@
+// Package task keeps the task lists extracted from the plans the agent
+// writes in its responses, with what's done in them, per session.
+package task
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/google/uuid"
+)
+
+// Task is an item of the task list of a session.
+type Task struct {
+	ID        string
+	SessionID string
+	// Position is where the task is in the list, from 0.
+	Position  int64
+	Content   string
+	Done      bool
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type Service interface {
+	pubsub.Subscriber[Task]
+	List(ctx context.Context, sessionID string) ([]Task, error)
+	// Replace makes items the task list of the session, keeping the tasks
+	// already done done.
+	Replace(ctx context.Context, sessionID string, items []Item) ([]Task, error)
+	SetDone(ctx context.Context, id string, done bool) (Task, error)
+}
+
+type service struct {
+	*pubsub.Broker[Task]
+	db *sql.DB
+	q  *db.Queries
+}
+
+func NewService(q *db.Queries, db *sql.DB) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Task](),
+		q:      q,
+		db:     db,
+	}
+}
+
+func (s *service) List(ctx context.Context, sessionID string) ([]Task, error) {
+	dbTasks, err := s.q.ListTasksBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]Task, len(dbTasks))
+	for i, dbTask := range dbTasks {
+		tasks[i] = fromDBItem(dbTask)
+	}
+	return tasks, nil
+}
+
+func (s *service) Replace(ctx context.Context, sessionID string, items []Item) ([]Task, error) {
+	old, err := s.List(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(old))
+	for _, task := range old {
+		done[task.Content] = done[task.Content] || task.Done
+	}
+	items = slices.Clone(items)
+	for i := range items {
+		items[i].Done = items[i].Done || done[items[i].Content]
+	}
+	if unchanged(old, items) {
+		return old, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.q.WithTx(tx)
+	if err := qtx.DeleteSessionTasks(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	tasks := make([]Task, 0, len(items))
+	for i, item := range items {
+		dbTask, err := qtx.CreateTask(ctx, db.CreateTaskParams{
+			ID:        uuid.New().String(),
+			SessionID: sessionID,
+			Position:  int64(i),
+			Content:   item.Content,
+			Done:      boolToInt(item.Done),
+		})
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, fromDBItem(dbTask))
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, task := range old {
+		s.Publish(pubsub.DeletedEvent, task)
+	}
+	for _, task := range tasks {
+		s.Publish(pubsub.CreatedEvent, task)
+	}
+	return tasks, nil
+}
+
+func (s *service) SetDone(ctx context.Context, id string, done bool) (Task, error) {
+	dbTask, err := s.q.SetTaskDone(ctx, db.SetTaskDoneParams{
+		ID:   id,
+		Done: boolToInt(done),
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	task := fromDBItem(dbTask)
+	s.Publish(pubsub.UpdatedEvent, task)
+	return task, nil
+}
+
+// unchanged reports whether tasks are items already.
+func unchanged(tasks []Task, items []Item) bool {
+	return slices.EqualFunc(tasks, items, func(task Task, item Item) bool {
+		return task.Content == item.Content && task.Done == item.Done
+	})
+}
+
+func fromDBItem(item db.Task) Task {
+	return Task{
+		ID:        item.ID,
+		SessionID: item.SessionID,
+		Position:  item.Position,
+		Content:   item.Content,
+		Done:      item.Done != 0,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want []Item
+	}{
+		{
+			name: "plan",
+			text: "Here's the plan:\n\n1. Add the migration\n   - with an index\n2. **Write** the service\n3) Wire it up\n\nThen I'll test it.\n\nSteps:\n1. Ignored",
+			want: []Item{
+				{Content: "Add the migration"},
+				{Content: "**Write** the service"},
+				{Content: "Wire it up"},
+			},
+		},
+		{
+			name: "bullets",
+			text: "TODO\n- first\n- second",
+			want: []Item{{Content: "first"}, {Content: "second"}},
+		},
+		{
+			name: "checklist",
+			text: "Done so far:\n1. Read the code\n2. Ran the tests\n\n- [x] Fix the parser\n* [ ] Update the docs",
+			want: []Item{{Content: "Fix the parser", Done: true}, {Content: "Update the docs"}},
+		},
+		{
+			name: "not a plan",
+			text: "The files are:\n1. main.go\n2. go.mod",
+		},
+		{
+			name: "single step",
+			text: "Plan:\n1. Just this",
+		},
+		{
+			name: "code block",
+			text: "Steps:\n```\n1. not\n2. these\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, Parse(tt.text))
+		})
+	}
+}
+
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	_, err = q.CreateSession(t.Context(), db.CreateSessionParams{ID: "session", Title: "Tasks"})
+	require.NoError(t, err)
+	svc := NewService(q, conn)
+
+	tasks, err := svc.Replace(t.Context(), "session", []Item{{Content: "one"}, {Content: "two"}})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+
+	done, err := svc.SetDone(t.Context(), tasks[0].ID, true)
+	require.NoError(t, err)
+	require.True(t, done.Done)
+
+	// The same list again leaves it alone.
+	same, err := svc.Replace(t.Context(), "session", []Item{{Content: "one"}, {Content: "two"}})
+	require.NoError(t, err)
+	require.Equal(t, tasks[0].ID, same[0].ID)
+
+	// What's done stays done in a new list.
+	tasks, err = svc.Replace(t.Context(), "session", []Item{{Content: "zero"}, {Content: "one"}, {Content: "two", Done: true}})
+	require.NoError(t, err)
+	got, err := svc.List(t.Context(), "session")
+	require.NoError(t, err)
+	require.Equal(t, tasks, got)
+	require.Equal(t, []bool{false, true, true}, []bool{got[0].Done, got[1].Done, got[2].Done})
+	require.Equal(t, int64(2), got[2].Position)
+
+	require.NoError(t, q.DeleteSession(t.Context(), "session"))
+	got, err = svc.List(t.Context(), "session")
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
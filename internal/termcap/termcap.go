@@ -0,0 +1,118 @@
+// Package termcap tracks what the terminal Crush runs in can do. The
+// capabilities are guessed from the environment at startup, then confirmed
+// by what the terminal answers to the queries of the TUI, and the components
+// do without what's missing.
+package termcap
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/colorprofile"
+)
+
+// Caps are the capabilities of a terminal.
+type Caps struct {
+	// Color is the color profile. Themes are approximated below TrueColor
+	// and gradients are drawn flat below ANSI256.
+	Color colorprofile.Profile
+	// Mouse is whether clicks and scrolling are reported.
+	Mouse bool
+	// KittyKeyboard is whether keys are disambiguated with the kitty
+	// keyboard protocol, telling ctrl+m from enter.
+	KittyKeyboard bool
+	// OSC52 is whether the terminal copies to the clipboard with OSC 52.
+	OSC52 bool
+	// SyncOutput is whether the terminal draws frames at once with
+	// synchronized output (mode 2026).
+	SyncOutput bool
+
+	// Probed tells which capabilities the terminal confirmed; the others
+	// are guessed from the environment.
+	Probed Probed
+}
+
+// Probed tells which capabilities the terminal answered for.
+type Probed struct {
+	Color         bool
+	KittyKeyboard bool
+	SyncOutput    bool
+}
+
+// TrueColor reports whether 24-bit colors are supported.
+func (c Caps) TrueColor() bool {
+	return c.Color >= colorprofile.TrueColor
+}
+
+// Colors256 reports whether at least 256 colors are supported.
+func (c Caps) Colors256() bool {
+	return c.Color >= colorprofile.ANSI256
+}
+
+// osc52Terminals are the values of TERM_PROGRAM of the terminals known to
+// handle OSC 52.
+var osc52Terminals = []string{"iTerm.app", "WezTerm", "ghostty", "vscode", "Tabby", "rio", "WarpTerminal"}
+
+// Detect guesses the capabilities from the environment. The keyboard
+// protocol and synchronized output are only known once the terminal
+// answers.
+func Detect(environ []string) Caps {
+	getenv := func(key string) string {
+		for _, kv := range environ {
+			if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+				return v
+			}
+		}
+		return ""
+	}
+	term := getenv("TERM")
+
+	return Caps{
+		Color: colorprofile.Env(environ),
+		Mouse: term != "" && term != "dumb" && term != "linux",
+		OSC52: getenv("TMUX") != "" ||
+			slices.Contains(osc52Terminals, getenv("TERM_PROGRAM")) ||
+			getenv("KITTY_WINDOW_ID") != "" ||
+			getenv("WT_SESSION") != "" ||
+			strings.Contains(term, "kitty") ||
+			strings.Contains(term, "alacritty") ||
+			strings.Contains(term, "foot") ||
+			strings.Contains(term, "ghostty") ||
+			strings.Contains(term, "wezterm"),
+	}
+}
+
+var (
+	mu sync.RWMutex
+	// current assumes everything until the terminal is detected, so what
+	// runs without a terminal, like the tests, renders in full.
+	current = Caps{
+		Color:      colorprofile.TrueColor,
+		Mouse:      true,
+		OSC52:      true,
+		SyncOutput: true,
+	}
+)
+
+// Get returns the capabilities of the terminal of the TUI.
+func Get() Caps {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Set sets the capabilities of the terminal of the TUI.
+func Set(caps Caps) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = caps
+}
+
+// Update changes the capabilities of the terminal of the TUI with fn, as the
+// terminal answers.
+func Update(fn func(*Caps)) {
+	mu.Lock()
+	defer mu.Unlock()
+	fn(&current)
+}
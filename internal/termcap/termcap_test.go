@@ -0,0 +1,24 @@
+package termcap
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	caps := Detect([]string{"TERM=xterm-ghostty", "COLORTERM=truecolor"})
+	require.True(t, caps.TrueColor())
+	require.True(t, caps.Mouse)
+	require.True(t, caps.OSC52)
+	require.False(t, caps.KittyKeyboard, "only known once the terminal answers")
+
+	caps = Detect([]string{"TERM=linux"})
+	require.Equal(t, colorprofile.ANSI, caps.Color)
+	require.False(t, caps.Colors256())
+	require.False(t, caps.Mouse)
+	require.False(t, caps.OSC52)
+}
@@ -60,6 +60,15 @@ var (
 // are received only by spinner components that sent them.
 var lastID int64
 
+// reducedMotion keeps animations still.
+var reducedMotion atomic.Bool
+
+// SetReducedMotion stops animations from moving: they are drawn once, with
+// the characters in place and the full ellipsis, and never step.
+func SetReducedMotion(reduced bool) {
+	reducedMotion.Store(reduced)
+}
+
 func nextID() int {
 	return int(atomic.AddInt64(&lastID, 1))
 }
@@ -349,6 +358,9 @@ func (a *Anim) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 
 // View renders the current state of the animation.
 func (a *Anim) View() string {
+	if reducedMotion.Load() {
+		return a.stillView()
+	}
 	var b strings.Builder
 	step := int(a.step.Load())
 	for i := range a.width {
@@ -381,8 +393,30 @@ func (a *Anim) View() string {
 	return b.String()
 }
 
+// stillView renders the animation without motion: the initial characters
+// followed by the label and the full ellipsis.
+func (a *Anim) stillView() string {
+	var b strings.Builder
+	for i := range a.cyclingCharWidth {
+		b.WriteString(a.initialFrames[0][i])
+	}
+	if a.labelWidth > 0 {
+		b.WriteString(labelGap)
+		for c := range a.label.Seq() {
+			b.WriteString(c)
+		}
+		if ellipsis, ok := a.ellipsisFrames.Get(len(ellipsisFrames) - 2); ok {
+			b.WriteString(ellipsis)
+		}
+	}
+	return b.String()
+}
+
 // Step is a command that triggers the next step in the animation.
 func (a *Anim) Step() tea.Cmd {
+	if reducedMotion.Load() {
+		return nil
+	}
 	return tea.Tick(time.Second/time.Duration(fps), func(t time.Time) tea.Msg {
 		return StepMsg{id: a.id}
 	})
@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"context"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/chat/messages"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+// ActionsKey opens the actions on the message of the selected item.
+var ActionsKey = key.NewBinding(key.WithKeys("m", "M"), key.WithHelp("m", "message actions"))
+
+// QuoteKey quotes the selected text in the prompt.
+var QuoteKey = key.NewBinding(key.WithKeys("q", "Q"), key.WithHelp("q", "quote selection"))
+
+// QuoteMsg quotes text at the start of the prompt, to ask about it. Code,
+// in the language Lang, is quoted as a fenced block rather than a block
+// quote.
+type QuoteMsg struct {
+	Text string
+	Lang string
+	Code bool
+}
+
+// OpenMessageActionsMsg asks to open the actions on a message.
+type OpenMessageActionsMsg struct {
+	Message message.Message
+}
+
+// openActions opens the actions on the message of the selected item, the
+// message that called a tool for a tool call.
+func (m *messageListCmp) openActions() tea.Cmd {
+	item := m.listCmp.SelectedItem()
+	if item == nil {
+		return nil
+	}
+	switch item := (*item).(type) {
+	case messages.MessageCmp:
+		return util.CmdHandler(OpenMessageActionsMsg{Message: item.GetMessage()})
+	case messages.ToolCallCmp:
+		// The message isn't shown when it only calls tools.
+		msg, err := m.app.Messages.Get(context.Background(), item.ParentMessageID())
+		if err != nil {
+			return util.ReportError(err)
+		}
+		return util.CmdHandler(OpenMessageActionsMsg{Message: msg})
+	}
+	return util.ReportInfo("Select a message to act on")
+}
+
+// quoteSelection quotes the selected text, or the text selected last, as
+// selecting with the mouse copies it and ends the selection.
+func (m *messageListCmp) quoteSelection() tea.Cmd {
+	text := m.lastSelection
+	if m.listCmp.HasSelection() {
+		text = m.GetSelectedText()
+		m.SelectionClear()
+	}
+	m.lastSelection = ""
+	if strings.TrimSpace(text) == "" {
+		return util.ReportInfo("Select the text to quote first")
+	}
+	return util.CmdHandler(QuoteMsg{Text: text})
+}
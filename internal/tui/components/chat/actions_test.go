@@ -0,0 +1,20 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteSelection(t *testing.T) {
+	t.Parallel()
+
+	m := New(nil).(*messageListCmp)
+	_, ok := m.quoteSelection()().(util.InfoMsg)
+	require.True(t, ok, "nothing was selected")
+
+	m.lastSelection = "the parser skips comments"
+	require.Equal(t, QuoteMsg{Text: "the parser skips comments"}, m.quoteSelection()())
+	require.Empty(t, m.lastSelection, "the selection is quoted once")
+}
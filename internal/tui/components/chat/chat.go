@@ -5,8 +5,8 @@ import (
 	"time"
 
 	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/agent/tools"
 	"github.com/charmbracelet/crush/internal/app"
@@ -24,6 +24,9 @@ import (
 type SendMsg struct {
 	Text        string
 	Attachments []message.Attachment
+	// Dropped are the sections of the request left out, as
+	// agent.WithDroppedSections takes them.
+	Dropped []string
 }
 
 type SessionSelectedMsg = session.Session
@@ -52,6 +55,7 @@ type MessageListCmp interface {
 	GoToBottom() tea.Cmd
 	GetSelectedText() string
 	CopySelectedText(bool) tea.Cmd
+	Searching() bool
 }
 
 // messageListCmp implements MessageListCmp, providing a virtualized list
@@ -72,12 +76,24 @@ type messageListCmp struct {
 	lastClickX    int
 	lastClickY    int
 	clickCount    int
+
+	vim vimState
+
+	// lastSelection is the text selected last, which can still be quoted
+	// once the selection is over.
+	lastSelection string
 }
 
 // New creates a new message list component with custom keybindings
 // and reverse ordering (newest messages at bottom).
 func New(app *app.App) MessageListCmp {
 	defaultListKeyMap := list.DefaultKeyMap()
+	vimMode := app != nil && app.Config() != nil && app.Config().Options.TUI.VimMode
+	if vimMode {
+		defaultListKeyMap = vimKeyMap(defaultListKeyMap)
+	}
+	input := textinput.New()
+	input.SetStyles(styles.CurrentTheme().S().TextInput)
 	listCmp := list.New(
 		[]list.Item{},
 		list.WithGap(1),
@@ -91,6 +107,7 @@ func New(app *app.App) MessageListCmp {
 		listCmp:           listCmp,
 		previousSelected:  "",
 		defaultListKeyMap: defaultListKeyMap,
+		vim:               vimState{enabled: vimMode, input: input},
 	}
 }
 
@@ -104,6 +121,25 @@ func (m *messageListCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
+		if m.vim.enabled && m.listCmp.IsFocused() {
+			if cmd, ok := m.handleVimKey(msg); ok {
+				return m, cmd
+			}
+		}
+		if m.listCmp.IsFocused() {
+			switch {
+			case key.Matches(msg, PinKey):
+				return m, m.togglePin()
+			case key.Matches(msg, ActionsKey):
+				return m, m.openActions()
+			case key.Matches(msg, QuoteKey):
+				return m, m.quoteSelection()
+			case key.Matches(msg, NextPinKey):
+				return m, m.findPin(false)
+			case key.Matches(msg, PrevPinKey):
+				return m, m.findPin(true)
+			}
+		}
 		if m.listCmp.IsFocused() && m.listCmp.HasSelection() {
 			switch {
 			case key.Matches(msg, messages.CopyKey):
@@ -186,6 +222,7 @@ func (m *messageListCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	case SessionSelectedMsg:
 		if msg.ID != m.session.ID {
+			m.lastSelection = ""
 			cmds = append(cmds, m.SetSession(msg))
 		}
 		return m, tea.Batch(cmds...)
@@ -194,6 +231,13 @@ func (m *messageListCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		cmds = append(cmds, m.listCmp.SetItems([]list.Item{}))
 		return m, tea.Batch(cmds...)
 
+	case pinnedMsg:
+		m.setPinned(msg.message)
+		if msg.message.Pinned {
+			return m, util.ReportInfo("Message pinned")
+		}
+		return m, util.ReportInfo("Message unpinned")
+
 	case pubsub.Event[message.Message]:
 		cmds = append(cmds, m.handleMessageEvent(msg))
 		return m, tea.Batch(cmds...)
@@ -208,6 +252,9 @@ func (m *messageListCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	u, cmd := m.listCmp.Update(msg)
 	m.listCmp = u.(list.List[list.Item])
 	cmds = append(cmds, cmd)
+	if _, ok := msg.(tea.KeyPressMsg); ok {
+		m.updateVisual()
+	}
 	return m, tea.Batch(cmds...)
 }
 
@@ -218,7 +265,7 @@ func (m *messageListCmp) View() string {
 		Padding(1, 1, 0, 1).
 		Width(m.width).
 		Height(m.height).
-		Render(m.listCmp.View())
+		Render(m.searchView(m.listCmp.View()))
 }
 
 func (m *messageListCmp) handlePermissionRequest(permission permission.PermissionNotification) tea.Cmd {
@@ -344,15 +391,27 @@ func (m *messageListCmp) messageExists(messageID string) bool {
 	return false
 }
 
-// handleDeleteMessage removes a message from the list.
+// handleDeleteMessage removes a message from the list, with its tool calls
+// and the section closing it.
 func (m *messageListCmp) handleDeleteMessage(msg message.Message) tea.Cmd {
-	items := m.listCmp.Items()
-	for i := len(items) - 1; i >= 0; i-- {
-		if msgCmp, ok := items[i].(messages.MessageCmp); ok && msgCmp.GetMessage().ID == msg.ID {
-			m.listCmp.DeleteItem(items[i].ID())
-			return nil
+	var ids []string
+	for _, item := range m.listCmp.Items() {
+		var messageID string
+		switch item := item.(type) {
+		case messages.MessageCmp:
+			messageID = item.GetMessage().ID
+		case messages.ToolCallCmp:
+			messageID = item.ParentMessageID()
+		case messages.AssistantSection:
+			messageID = item.MessageID()
+		}
+		if messageID == msg.ID {
+			ids = append(ids, item.ID())
 		}
 	}
+	for _, id := range ids {
+		m.listCmp.DeleteItem(id)
+	}
 	return nil
 }
 
@@ -457,6 +516,9 @@ func (m *messageListCmp) updateAssistantMessageContent(msg message.Message, assi
 	if shouldShowMessage {
 		items := m.listCmp.Items()
 		uiMsg := items[assistantIndex].(messages.MessageCmp)
+		// Pins are changed by the chat, the copy of the agent doesn't know
+		// about them.
+		msg.Pinned = uiMsg.GetMessage().Pinned
 		uiMsg.SetMessage(msg)
 		m.listCmp.UpdateItem(
 			items[assistantIndex].ID(),
@@ -643,6 +705,10 @@ func (m *messageListCmp) buildToolCallOptions(tc message.ToolCall, msg message.M
 		options = append(options, messages.WithToolCallResult(tr))
 	}
 
+	if msg.Pinned {
+		options = append(options, messages.WithToolCallPinned())
+	}
+
 	// Add cancelled status if applicable
 	if msg.FinishPart() != nil && msg.FinishPart().Reason == message.FinishReasonCanceled {
 		options = append(options, messages.WithToolCallCancelled())
@@ -727,6 +793,7 @@ func (m *messageListCmp) handleMouseClick(x, y int) tea.Cmd {
 // SelectionClear clears the current selection in the list component.
 func (m *messageListCmp) SelectionClear() tea.Cmd {
 	m.listCmp.SelectionClear()
+	m.vim.anchor = ""
 	m.previousSelected = ""
 	m.lastClickX, m.lastClickY = 0, 0
 	m.lastClickTime = time.Time{}
@@ -756,15 +823,9 @@ func (m *messageListCmp) CopySelectedText(clear bool) tea.Cmd {
 		return util.ReportInfo("No text selected")
 	}
 
+	m.lastSelection = selectedText
 	cmds := []tea.Cmd{
-		// We use both OSC 52 and native clipboard for compatibility with different
-		// terminal emulators and environments.
-		tea.SetClipboard(selectedText),
-		func() tea.Msg {
-			_ = clipboard.WriteAll(selectedText)
-			return nil
-		},
-		util.ReportInfo("Selected text copied to clipboard"),
+		util.CopyToClipboard(selectedText, "Selected text"),
 	}
 	if clear {
 		cmds = append(cmds, m.SelectionClear())
@@ -0,0 +1,79 @@
+package editor
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// draftDelay is how long the prompt has to stay unchanged before the
+// draft is saved.
+const draftDelay = time.Second
+
+// draftSaveMsg saves the draft, unless the prompt changed since it was
+// scheduled.
+type draftSaveMsg struct {
+	generation int
+}
+
+func (draftSaveMsg) ownMsg() {}
+
+// draftPath returns where the draft of the prompt is saved, so it isn't
+// lost when Crush exits before it is sent.
+func draftPath(dataDir string) string {
+	return filepath.Join(dataDir, "draft.md")
+}
+
+// loadDraft returns the saved draft, if any.
+func loadDraft(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// saveDraft saves the draft, removing it when it is empty.
+func saveDraft(path, value string) error {
+	if value == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(value), 0o644)
+}
+
+// scheduleDraftSave saves the draft once the prompt stays unchanged for a
+// moment. An empty prompt is saved right away, so a sent prompt doesn't
+// come back.
+func (m *editorCmp) scheduleDraftSave() tea.Cmd {
+	if m.draftPath == "" {
+		return nil
+	}
+	m.draftGeneration++
+	if m.textarea.Value() == "" {
+		return m.saveDraft()
+	}
+	generation := m.draftGeneration
+	return tea.Tick(draftDelay, func(time.Time) tea.Msg {
+		return draftSaveMsg{generation: generation}
+	})
+}
+
+// saveDraft saves the current prompt as the draft.
+func (m *editorCmp) saveDraft() tea.Cmd {
+	path, value := m.draftPath, m.textarea.Value()
+	return func() tea.Msg {
+		if err := saveDraft(path, value); err != nil {
+			slog.Warn("Failed to save the draft", "path", path, "error", err)
+		}
+		return nil
+	}
+}
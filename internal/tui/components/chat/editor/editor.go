@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"runtime"
 	"slices"
 	"strings"
 	"unicode"
@@ -17,15 +16,21 @@ import (
 	"charm.land/bubbles/v2/textarea"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/mention"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/spell"
+	"github.com/charmbracelet/crush/internal/symbols"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/completions"
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/contextinspect"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/quit"
 	"github.com/charmbracelet/crush/internal/tui/styles"
@@ -45,30 +50,73 @@ type Editor interface {
 	HasAttachments() bool
 	IsEmpty() bool
 	Cursor() *tea.Cursor
+	// HandlesEscape reports whether esc is for the editor, leaving the
+	// insert or the visual mode of vim, or a command being typed.
+	HandlesEscape() bool
+}
+
+// OwnMsg is implemented by the messages the editor sends itself, like the
+// results of its background work, which have to be passed back to it.
+type OwnMsg interface {
+	ownMsg()
 }
 
 type FileCompletionItem struct {
 	Path string // The file path
 }
 
+// SymbolCompletionItem is a symbol declared in the workspace, completed
+// after #.
+type SymbolCompletionItem struct {
+	Symbol symbols.Symbol
+}
+
 type editorCmp struct {
-	width              int
-	height             int
-	x, y               int
-	app                *app.App
-	session            session.Session
-	textarea           textarea.Model
-	attachments        []message.Attachment
+	width       int
+	height      int
+	x, y        int
+	app         *app.App
+	session     session.Session
+	textarea    textarea.Model
+	attachments []message.Attachment
+	// dropped are the sections left out of the next request in the context
+	// inspector, attachments aside.
+	dropped            []string
 	deleteMode         bool
 	readyPlaceholder   string
 	workingPlaceholder string
 
 	keyMap EditorKeyMap
 
-	// File path completions
+	// File path and symbol completions
 	currentQuery          string
 	completionsStartIndex int
 	isCompletionsOpen     bool
+	// completionsTrigger is the character that opened the completions
+	// last, @ for files and # for symbols.
+	completionsTrigger string
+
+	// spell is nil when spell checking is disabled. correcting is the word
+	// the open completions correct.
+	spell      *spell.Checker
+	spellErr   error
+	correcting spell.Word
+
+	// symbolIndex is the last index of the workspace symbols, nil until
+	// the first is built.
+	symbolIndex []symbols.Symbol
+	indexing    bool
+
+	undo  undoStack
+	kills killRing
+	// vim is nil unless the prompt is edited like in vim.
+	vim *vim
+
+	// draftPath is where the prompt is saved while it is written, empty
+	// when it isn't.
+	draftPath       string
+	draftGeneration int
+	draftRestored   bool
 }
 
 var DeleteKeyMaps = DeleteAttachmentKeyMaps{
@@ -86,23 +134,36 @@ var DeleteKeyMaps = DeleteAttachmentKeyMaps{
 	),
 }
 
-const maxFileResults = 25
+const (
+	maxFileResults   = 25
+	maxSymbolResults = 25
+)
 
 type OpenEditorMsg struct {
 	Text string
 }
 
-func (m *editorCmp) openEditor(value string) tea.Cmd {
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		// Use platform-appropriate default editor
-		if runtime.GOOS == "windows" {
-			editor = "notepad"
-		} else {
-			editor = "nvim"
+// quote returns what msg quotes as Markdown, a fenced code block for code
+// and a block quote otherwise, followed by an empty line.
+func quote(msg chat.QuoteMsg) string {
+	text := strings.Trim(msg.Text, "\n")
+	if msg.Code {
+		fence := "```"
+		for strings.Contains(text, fence) {
+			fence += "`"
 		}
+		return fence + msg.Lang + "\n" + text + "\n" + fence + "\n\n"
+	}
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
 	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
 
+// openEditor lets the user write the prompt in $VISUAL or $EDITOR. The
+// saved text replaces the prompt; attachments are kept.
+func (m *editorCmp) openEditor(value string) tea.Cmd {
 	tmpfile, err := os.CreateTemp("", "msg_*.md")
 	if err != nil {
 		return util.ReportError(err)
@@ -111,8 +172,9 @@ func (m *editorCmp) openEditor(value string) tea.Cmd {
 	if _, err := tmpfile.WriteString(value); err != nil {
 		return util.ReportError(err)
 	}
-	cmdStr := editor + " " + tmpfile.Name()
-	return util.ExecShell(context.TODO(), cmdStr, func(err error) tea.Msg {
+	cmdStr := externalEditorCommand(util.Editor(), tmpfile.Name())
+	return util.ExecShell(context.TODO(), config.ExternalToolEditor, cmdStr, func(err error) tea.Msg {
+		defer os.Remove(tmpfile.Name())
 		if err != nil {
 			return util.ReportError(err)
 		}
@@ -123,15 +185,44 @@ func (m *editorCmp) openEditor(value string) tea.Cmd {
 		if len(content) == 0 {
 			return util.ReportWarn("Message is empty")
 		}
-		os.Remove(tmpfile.Name())
 		return OpenEditorMsg{
 			Text: strings.TrimSpace(string(content)),
 		}
 	})
 }
 
+// externalEditorCommand builds the command editing path. GUI editors are
+// told to wait until the file is closed, so the prompt is read once it is
+// written rather than right away.
+func externalEditorCommand(editor, path string) string {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return editor + " " + path
+	}
+	var wait string
+	switch strings.TrimSuffix(filepath.Base(fields[0]), ".exe") {
+	case "code", "code-insiders", "codium", "cursor", "windsurf", "zed", "subl", "atom":
+		wait = "--wait"
+	case "mate", "bbedit":
+		wait = "-w"
+	case "gvim", "mvim":
+		wait = "-f"
+	}
+	if wait == "" || slices.Contains(fields[1:], wait) {
+		return editor + " " + path
+	}
+	return editor + " " + wait + " " + path
+}
+
 func (m *editorCmp) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{m.indexSymbols()}
+	switch {
+	case m.spellErr != nil:
+		cmds = append(cmds, util.ReportWarn(m.spellErr.Error()))
+	case m.draftRestored:
+		cmds = append(cmds, util.ReportInfo("Restored the unsent prompt"))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *editorCmp) send() tea.Cmd {
@@ -150,17 +241,30 @@ func (m *editorCmp) send() tea.Cmd {
 		return nil
 	}
 
+	dropped := m.dropped
+
 	m.textarea.Reset()
 	m.attachments = nil
+	m.dropped = nil
+	if m.vim != nil {
+		m.vim.insert()
+		m.undo.group(false)
+	}
 	// Change the placeholder when sending a new message.
 	m.randomizePlaceholders()
 
-	return tea.Batch(
-		util.CmdHandler(chat.SendMsg{
-			Text:        value,
-			Attachments: attachments,
-		}),
-	)
+	send := chat.SendMsg{
+		Text:        value,
+		Attachments: attachments,
+		Dropped:     dropped,
+	}
+	if mentions := unattached(value, attachments); len(mentions) > 0 && m.app.Config() != nil {
+		return tea.Batch(
+			util.ReportInfo("Attaching what the prompt mentions..."),
+			m.sendWithMentions(send, mentions),
+		)
+	}
+	return util.CmdHandler(send)
 }
 
 func (m *editorCmp) repositionCompletions() tea.Msg {
@@ -169,30 +273,105 @@ func (m *editorCmp) repositionCompletions() tea.Msg {
 }
 
 func (m *editorCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case draftSaveMsg:
+		if msg.generation == m.draftGeneration {
+			return m, m.saveDraft()
+		}
+		return m, nil
+	case tea.KeyPressMsg:
+		if cmd, ok := m.handleVimKey(msg); ok {
+			return m, cmd
+		}
+		if cmd, ok := m.handleHistoryKey(msg); ok {
+			return m, cmd
+		}
+	case tea.PasteMsg, OpenEditorMsg, chat.QuoteMsg, completions.SelectCompletionMsg:
+	default:
+		return m.update(msg)
+	}
+	// These may change the prompt, which can then be undone.
+	before := m.editState()
+	model, cmd := m.update(msg)
+	return model, tea.Batch(cmd, m.edited(before, msg))
+}
+
+func (m *editorCmp) update(msg tea.Msg) (util.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		return m, m.repositionCompletions
 	case filepicker.FilePickedMsg:
-		m.attachments = append(m.attachments, msg.Attachment)
+		m.attach(msg.Attachment)
+		return m, nil
+	case symbolsIndexedMsg:
+		m.indexing = false
+		m.symbolIndex = msg.index
 		return m, nil
+	case mentionsResolvedMsg:
+		return m, mentionsResolved(msg)
+	case pasteEditedMsg:
+		m.pasteEdited(msg)
+		return m, nil
+	case commands.FetchURLMsg:
+		return m, m.fetchPage(msg.URL)
+	case pageFetchedMsg:
+		if msg.err != nil {
+			return m, util.ReportError(msg.err)
+		}
+		m.attach(msg.attachment)
+		return m, util.ReportInfo("Attached " + msg.attachment.FilePath)
 	case completions.CompletionsOpenedMsg:
 		m.isCompletionsOpen = true
 	case completions.CompletionsClosedMsg:
 		m.isCompletionsOpen = false
 		m.currentQuery = ""
 		m.completionsStartIndex = 0
+		m.correcting = spell.Word{}
 	case completions.SelectCompletionMsg:
 		if !m.isCompletionsOpen {
 			return m, nil
 		}
+		switch item := msg.Value.(type) {
+		case SpellingCompletionItem:
+			m.correct(item)
+			return m, nil
+		case SymbolCompletionItem:
+			// After #, the symbol is written with where it's declared; after
+			// @, it's mentioned and its declaration attached.
+			inserted := fmt.Sprintf("%s (%s:%d)", item.Symbol.Name, item.Symbol.Path, item.Symbol.Line)
+			if m.completionsTrigger == "@" {
+				inserted = "@" + item.Symbol.Name
+			}
+			word := m.textarea.Word()
+			value := m.textarea.Value()
+			value = value[:m.completionsStartIndex] +
+				inserted +
+				value[m.completionsStartIndex+len(word):]
+			m.textarea.SetValue(value)
+			m.textarea.MoveToEnd()
+			if !msg.Insert {
+				m.isCompletionsOpen = false
+				m.currentQuery = ""
+				m.completionsStartIndex = 0
+			}
+			if m.completionsTrigger != "@" || msg.Insert {
+				return m, nil
+			}
+			attachment, err := mention.Symbol(m.app.Config().WorkingDir(), item.Symbol)
+			if err != nil {
+				return m, util.ReportError(err)
+			}
+			m.attach(attachment)
+			return m, nil
+		}
 		if item, ok := msg.Value.(FileCompletionItem); ok {
 			word := m.textarea.Word()
 			// If the selected item is a file, insert its path into the textarea
 			value := m.textarea.Value()
 			value = value[:m.completionsStartIndex] + // Remove the current query
-				item.Path + // Insert the file path
+				"@" + item.Path + // Insert the file mention
 				value[m.completionsStartIndex+len(word):] // Append the rest of the value
 			// XXX: This will always move the cursor to the end of the textarea.
 			m.textarea.SetValue(value)
@@ -207,7 +386,7 @@ func (m *editorCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 				// if it fails, let the LLM handle it later.
 				return m, nil
 			}
-			m.attachments = append(m.attachments, message.Attachment{
+			m.attach(message.Attachment{
 				FilePath: item.Path,
 				FileName: filepath.Base(item.Path),
 				MimeType: mimeOf(content),
@@ -220,9 +399,29 @@ func (m *editorCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			return m, util.ReportWarn("Agent is working, please wait...")
 		}
 		return m, m.openEditor(m.textarea.Value())
+	case commands.OpenContextInspectMsg:
+		return m, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: contextinspect.NewContextInspectDialogCmp(m.app.AgentCoordinator, m.session.ID, m.textarea.Value(), m.attachments, m.dropped),
+		})
+	case contextinspect.DropSectionsMsg:
+		// Dropped attachments are removed right away, the rest is left out
+		// when sending.
+		m.dropped = nil
+		for _, id := range msg.IDs {
+			if path, ok := strings.CutPrefix(id, string(agent.SectionAttachment)+":"); ok {
+				m.attachments = slices.DeleteFunc(m.attachments, func(att message.Attachment) bool {
+					return att.FilePath == path
+				})
+				continue
+			}
+			m.dropped = append(m.dropped, id)
+		}
 	case OpenEditorMsg:
 		m.textarea.SetValue(msg.Text)
 		m.textarea.MoveToEnd()
+	case chat.QuoteMsg:
+		m.textarea.SetValue(quote(msg) + m.textarea.Value())
+		m.textarea.MoveToEnd()
 	case tea.PasteMsg:
 		content, path, err := pasteToFile(msg)
 		if errors.Is(err, errNotAFile) {
@@ -247,6 +446,10 @@ func (m *editorCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		if !attachment.IsText() && !attachment.IsImage() {
 			return m, util.ReportWarn("Invalid file content type: " + mimeType)
 		}
+		if isPaste(attachment) {
+			// Long pastes are shown as a chip instead of flooding the prompt.
+			attachment.FileName = pasteName(content)
+		}
 		return m, util.CmdHandler(filepicker.FilePickedMsg{
 			Attachment: attachment,
 		})
@@ -263,14 +466,27 @@ func (m *editorCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			return m, util.CmdHandler(dialogs.OpenDialogMsg{
 				Model: commands.NewCommandDialog(m.session.ID),
 			})
+		case key.Matches(msg, m.keyMap.Spelling):
+			return m, m.suggestSpelling()
+		case key.Matches(msg, m.keyMap.ExpandPaste):
+			return m, m.expandPaste()
+		case key.Matches(msg, m.keyMap.EditPaste):
+			return m, m.editPaste()
 		// Completions
-		case msg.String() == "@" && !m.isCompletionsOpen &&
+		case (msg.String() == "@" || msg.String() == "#") && !m.isCompletionsOpen &&
 			// only show if beginning of prompt, or if previous char is a space or newline:
 			(len(m.textarea.Value()) == 0 || unicode.IsSpace(rune(m.textarea.Value()[len(m.textarea.Value())-1]))):
 			m.isCompletionsOpen = true
 			m.currentQuery = ""
 			m.completionsStartIndex = curIdx
-			cmds = append(cmds, m.startCompletions)
+			m.completionsTrigger = msg.String()
+			if m.completionsTrigger == "#" {
+				cmds = append(cmds, m.startSymbolCompletions(m.symbolIndex))
+			} else {
+				cmds = append(cmds, m.startCompletions(m.symbolIndex))
+			}
+			// The index is refreshed for the next time.
+			cmds = append(cmds, m.indexSymbols())
 		case m.isCompletionsOpen && curIdx <= m.completionsStartIndex:
 			cmds = append(cmds, util.CmdHandler(completions.CloseCompletionsMsg{}))
 		}
@@ -336,7 +552,7 @@ func (m *editorCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 				cmds = append(cmds, util.CmdHandler(completions.CloseCompletionsMsg{}))
 			} else {
 				word := m.textarea.Word()
-				if strings.HasPrefix(word, "@") {
+				if m.completionsTrigger != "" && strings.HasPrefix(word, m.completionsTrigger) && m.correcting.Text == "" {
 					// XXX: wont' work if editing in the middle of the field.
 					m.completionsStartIndex = strings.LastIndex(m.textarea.Value(), word)
 					m.currentQuery = word[1:]
@@ -387,6 +603,9 @@ func (m *editorCmp) Cursor() *tea.Cursor {
 	if cursor != nil {
 		cursor.X = cursor.X + m.x + 1
 		cursor.Y = cursor.Y + m.y + 1 // adjust for padding
+		if m.vim != nil && m.vim.mode != vimInsert {
+			cursor.Shape = tea.CursorBlock
+		}
 	}
 	return cursor
 }
@@ -423,16 +642,41 @@ func (m *editorCmp) View() string {
 	if m.app.Permissions.SkipRequests() {
 		m.textarea.Placeholder = "Yolo mode!"
 	}
+	// Misspelled words are listed in the padding under the prompt.
+	spelling := m.spellingView(m.width - 2)
+	if mode := m.vimView(); mode != "" {
+		// The mode of vim goes before the misspelled words.
+		spelling = ansi.Truncate(strings.TrimSuffix(mode+"  "+spelling, "  "), m.width-2, "…")
+	}
 	if len(m.attachments) == 0 {
-		return t.S().Base.Padding(1).Render(
-			m.textarea.View(),
+		if spelling == "" {
+			return t.S().Base.Padding(1).Render(
+				m.textarea.View(),
+			)
+		}
+		return t.S().Base.Padding(1, 1, 0, 1).Render(
+			lipgloss.JoinVertical(
+				lipgloss.Top,
+				m.textarea.View(),
+				spelling,
+			),
 		)
 	}
-	return t.S().Base.Padding(0, 1, 1, 1).Render(
+	if spelling == "" {
+		return t.S().Base.Padding(0, 1, 1, 1).Render(
+			lipgloss.JoinVertical(
+				lipgloss.Top,
+				m.attachmentsContent(),
+				m.textarea.View(),
+			),
+		)
+	}
+	return t.S().Base.PaddingLeft(1).PaddingRight(1).Render(
 		lipgloss.JoinVertical(
 			lipgloss.Top,
 			m.attachmentsContent(),
 			m.textarea.View(),
+			spelling,
 		),
 	)
 }
@@ -472,6 +716,9 @@ func (m *editorCmp) attachmentsContent() string {
 		Render
 	for i, attachment := range m.attachments {
 		filename := ansi.Truncate(filepath.Base(attachment.FileName), 10, "...")
+		if isPaste(attachment) {
+			filename = attachment.FileName
+		}
 		icon := styles.ImageIcon
 		if attachment.IsText() {
 			icon = styles.TextIcon
@@ -499,28 +746,55 @@ func (m *editorCmp) SetPosition(x, y int) tea.Cmd {
 	return nil
 }
 
-func (m *editorCmp) startCompletions() tea.Msg {
-	ls := m.app.Config().Options.TUI.Completions
-	depth, limit := ls.Limits()
-	files, _, _ := fsext.ListDirectory(".", nil, depth, limit)
-	slices.Sort(files)
-	completionItems := make([]completions.Completion, 0, len(files))
-	for _, file := range files {
-		file = strings.TrimPrefix(file, "./")
-		completionItems = append(completionItems, completions.Completion{
-			Title: file,
-			Value: FileCompletionItem{
-				Path: file,
-			},
-		})
+// startCompletions completes the files of the workspace, followed by the
+// symbols of index.
+func (m *editorCmp) startCompletions(index []symbols.Symbol) tea.Cmd {
+	return func() tea.Msg {
+		ls := m.app.Config().Options.TUI.Completions
+		depth, limit := ls.Limits()
+		files, _, _ := fsext.ListDirectory(".", nil, depth, limit)
+		slices.Sort(files)
+		completionItems := make([]completions.Completion, 0, len(files)+len(index))
+		for _, file := range files {
+			file = strings.TrimPrefix(file, "./")
+			completionItems = append(completionItems, completions.Completion{
+				Title: file,
+				Value: FileCompletionItem{
+					Path: file,
+				},
+			})
+		}
+		completionItems = append(completionItems, m.symbolCompletions(index)...)
+
+		x, y := m.completionsPosition()
+		return completions.OpenCompletionsMsg{
+			Completions: completionItems,
+			X:           x,
+			Y:           y,
+			MaxResults:  maxFileResults,
+		}
 	}
+}
 
-	x, y := m.completionsPosition()
-	return completions.OpenCompletionsMsg{
-		Completions: completionItems,
-		X:           x,
-		Y:           y,
-		MaxResults:  maxFileResults,
+// startSymbolCompletions completes the symbols of index, indexing the
+// workspace first when it hasn't been yet.
+func (m *editorCmp) startSymbolCompletions(index []symbols.Symbol) tea.Cmd {
+	return func() tea.Msg {
+		if index == nil {
+			var err error
+			index, err = symbols.Index(context.Background(), m.app.Config().WorkingDir())
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+			}
+		}
+
+		x, y := m.completionsPosition()
+		return completions.OpenCompletionsMsg{
+			Completions: m.symbolCompletions(index),
+			X:           x,
+			Y:           y,
+			MaxResults:  maxSymbolResults,
+		}
 	}
 }
 
@@ -556,6 +830,22 @@ func (c *editorCmp) IsCompletionsOpen() bool {
 	return c.isCompletionsOpen
 }
 
+// attach adds an attachment, replacing an older one of the same file, and
+// watches the file for changes made outside Crush.
+func (m *editorCmp) attach(attachment message.Attachment) {
+	i := slices.IndexFunc(m.attachments, func(a message.Attachment) bool {
+		return a.FilePath != "" && a.FilePath == attachment.FilePath
+	})
+	if i >= 0 {
+		m.attachments[i] = attachment
+	} else {
+		m.attachments = append(m.attachments, attachment)
+	}
+	if attachment.FilePath != "" && m.app.FileWatch != nil {
+		m.app.FileWatch.Watch(attachment.FilePath)
+	}
+}
+
 func (c *editorCmp) HasAttachments() bool {
 	return len(c.attachments) > 0
 }
@@ -606,8 +896,22 @@ func New(app *app.App) Editor {
 		app:      app,
 		textarea: ta,
 		keyMap:   DefaultEditorKeyMap(),
+
+		completionsTrigger: "@",
 	}
 	e.setEditorPrompt()
+	if app != nil && app.Config() != nil {
+		e.spell, e.spellErr = loadSpellChecker(app.Config().Options.TUI.SpellCheck)
+		if app.Config().Options.TUI.VimEditor {
+			e.vim = newVim()
+		}
+		e.draftPath = draftPath(app.Config().Options.DataDirectory)
+		if draft := loadDraft(e.draftPath); draft != "" {
+			e.textarea.SetValue(draft)
+			e.textarea.MoveToEnd()
+			e.draftRestored = true
+		}
+	}
 
 	e.randomizePlaceholders()
 	e.textarea.Placeholder = e.readyPlaceholder
@@ -633,7 +937,7 @@ func pasteToFile(msg tea.PasteMsg) ([]byte, string, error) {
 }
 
 func contentToFile(content []byte) ([]byte, string, error) {
-	f, err := os.CreateTemp("", "paste_*.txt")
+	f, err := os.CreateTemp("", pastePrefix+"*.txt")
 	if err != nil {
 		return nil, "", err
 	}
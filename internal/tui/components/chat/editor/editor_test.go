@@ -0,0 +1,44 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/tui/components/chat"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalEditorCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		editor string
+		want   string
+	}{
+		{"nvim", "nvim /tmp/msg.md"},
+		{"code", "code --wait /tmp/msg.md"},
+		{"/usr/local/bin/subl -n", "/usr/local/bin/subl -n --wait /tmp/msg.md"},
+		{"code --wait", "code --wait /tmp/msg.md"},
+		{"mate", "mate -w /tmp/msg.md"},
+		{"gvim", "gvim -f /tmp/msg.md"},
+		{"", " /tmp/msg.md"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, externalEditorCommand(tt.editor, "/tmp/msg.md"), tt.editor)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "> Use Postgres.\n>\n> Not MySQL.\n\n", quote(chat.QuoteMsg{Text: "Use Postgres.\n\nNot MySQL.\n"}))
+	require.Equal(t, "```go\nfunc main() {}\n```\n\n", quote(chat.QuoteMsg{Text: "func main() {}\n", Lang: "go", Code: true}))
+	require.Equal(t, "````md\n```sh\nls\n```\n````\n\n", quote(chat.QuoteMsg{Text: "```sh\nls\n```", Lang: "md", Code: true}), "the fence is longer than the ones quoted")
+}
+
+func TestPasteName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "pasted 3 lines", pasteName([]byte("a\nb\nc\n")))
+	require.Equal(t, "pasted 3 lines", pasteName([]byte("a\nb\nc")))
+	require.Equal(t, "pasted 1 line", pasteName([]byte("a")))
+}
@@ -0,0 +1,219 @@
+package editor
+
+import (
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/tui/components/completions"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const (
+	// maxUndo bounds how many edits can be undone.
+	maxUndo = 200
+	// maxKills bounds how much deleted text is kept to be pasted again.
+	maxKills = 20
+	// typingDelay is how long a pause in typing may last for the typing
+	// to still be undone at once.
+	typingDelay = time.Second
+)
+
+// editState is the text of the editor and where its cursor is.
+type editState struct {
+	value    string
+	row, col int
+}
+
+// undoStack keeps the states before the edits of the prompt, to undo them,
+// and the ones undone, to redo them.
+type undoStack struct {
+	undo []editState
+	redo []editState
+
+	// typing is set when the last edit was typing, which the typing right
+	// after it is undone with.
+	typing   bool
+	lastEdit time.Time
+
+	// grouping is set while a change is made of several edits, like typing
+	// in the insert mode of vim, which are undone at once. grouped tells
+	// whether the change was recorded yet.
+	grouping, grouped bool
+}
+
+// record saves the state before an edit.
+func (s *undoStack) record(before editState, typing bool, now time.Time) {
+	s.redo = nil
+	joined := typing && s.typing && now.Sub(s.lastEdit) < typingDelay && len(s.undo) > 0
+	if s.grouping {
+		joined = s.grouped
+		s.grouped = true
+	}
+	s.typing = typing
+	s.lastEdit = now
+	if joined {
+		return
+	}
+	s.undo = append(s.undo, before)
+	if len(s.undo) > maxUndo {
+		s.undo = s.undo[1:]
+	}
+}
+
+// group starts or ends a change undone at once.
+func (s *undoStack) group(on bool) {
+	s.grouping = on
+	s.grouped = false
+}
+
+// undoFrom returns the state before the last edit, if any, and keeps the
+// current one to redo.
+func (s *undoStack) undoFrom(current editState) (editState, bool) {
+	if len(s.undo) == 0 {
+		return current, false
+	}
+	prev := s.undo[len(s.undo)-1]
+	s.undo = s.undo[:len(s.undo)-1]
+	s.redo = append(s.redo, current)
+	s.typing = false
+	return prev, true
+}
+
+// redoFrom returns the state the last undo went back from, if any.
+func (s *undoStack) redoFrom(current editState) (editState, bool) {
+	if len(s.redo) == 0 {
+		return current, false
+	}
+	next := s.redo[len(s.redo)-1]
+	s.redo = s.redo[:len(s.redo)-1]
+	s.undo = append(s.undo, current)
+	s.typing = false
+	return next, true
+}
+
+// killRing keeps the text deleted with the line and word deletion keys, to
+// paste it again like in Emacs.
+type killRing struct {
+	kills []string
+
+	// index is the kill pasted last, before is the state before it was
+	// pasted, and after the text after it, which tells whether the paste
+	// can still be replaced with an older kill.
+	index  int
+	before editState
+	after  string
+}
+
+func (r *killRing) kill(text string) {
+	if text == "" {
+		return
+	}
+	r.kills = append(r.kills, text)
+	if len(r.kills) > maxKills {
+		r.kills = r.kills[1:]
+	}
+}
+
+// removed returns the text removed from before to get after, when the edit
+// removed a single span of text.
+func removed(before, after string) string {
+	if len(after) >= len(before) {
+		return ""
+	}
+	prefix := 0
+	for prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	return before[prefix : len(before)-suffix]
+}
+
+// editState returns the current state of the editor.
+func (m *editorCmp) editState() editState {
+	info := m.textarea.LineInfo()
+	return editState{
+		value: m.textarea.Value(),
+		row:   m.textarea.Line(),
+		col:   info.StartColumn + info.ColumnOffset,
+	}
+}
+
+// restore puts the editor back in a previous state.
+func (m *editorCmp) restore(s editState) {
+	m.textarea.SetValue(s.value)
+	m.textarea.MoveToBegin()
+	for i := 0; m.textarea.Line() < s.row && i < m.textarea.Height()+len(s.value); i++ {
+		m.textarea.CursorDown()
+	}
+	m.textarea.SetCursorColumn(s.col)
+}
+
+// isKill reports whether msg deletes text that goes to the kill ring.
+func (m *editorCmp) isKill(msg tea.KeyPressMsg) bool {
+	km := m.textarea.KeyMap
+	return key.Matches(msg, km.DeleteAfterCursor, km.DeleteBeforeCursor, km.DeleteWordBackward, km.DeleteWordForward)
+}
+
+// handleHistoryKey handles undo, redo and pasting deleted text, and reports
+// whether msg was one of them.
+func (m *editorCmp) handleHistoryKey(msg tea.KeyPressMsg) (tea.Cmd, bool) {
+	current := m.editState()
+	switch {
+	case key.Matches(msg, m.keyMap.Undo):
+		prev, ok := m.undo.undoFrom(current)
+		if !ok {
+			return util.ReportInfo("Nothing to undo"), true
+		}
+		m.restore(prev)
+	case key.Matches(msg, m.keyMap.Redo):
+		next, ok := m.undo.redoFrom(current)
+		if !ok {
+			return util.ReportInfo("Nothing to redo"), true
+		}
+		m.restore(next)
+	case key.Matches(msg, m.keyMap.Yank):
+		if len(m.kills.kills) == 0 {
+			return nil, true
+		}
+		m.undo.record(current, false, time.Now())
+		m.kills.index = len(m.kills.kills) - 1
+		m.kills.before = current
+		m.textarea.InsertString(m.kills.kills[m.kills.index])
+		m.kills.after = m.textarea.Value()
+	case key.Matches(msg, m.keyMap.YankPop):
+		// Only the text pasted right before can be replaced.
+		if len(m.kills.kills) == 0 || m.kills.after == "" || m.kills.after != current.value {
+			return nil, true
+		}
+		m.restore(m.kills.before)
+		m.kills.index = (m.kills.index - 1 + len(m.kills.kills)) % len(m.kills.kills)
+		m.textarea.InsertString(m.kills.kills[m.kills.index])
+		m.kills.after = m.textarea.Value()
+	default:
+		return nil, false
+	}
+	return tea.Batch(m.scheduleDraftSave(), util.CmdHandler(completions.CloseCompletionsMsg{})), true
+}
+
+// edited records an edit that changed the text from before, to undo it,
+// and saves the draft.
+func (m *editorCmp) edited(before editState, msg tea.Msg) tea.Cmd {
+	after := m.textarea.Value()
+	if after == before.value {
+		return nil
+	}
+	typing := false
+	if kp, ok := msg.(tea.KeyPressMsg); ok {
+		typing = kp.Text != ""
+		if m.isKill(kp) {
+			m.kills.kill(removed(before.value, after))
+		}
+	}
+	m.undo.record(before, typing, time.Now())
+	m.kills.after = ""
+	return m.scheduleDraftSave()
+}
@@ -0,0 +1,114 @@
+package editor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"charm.land/bubbles/v2/textarea"
+	tea "charm.land/bubbletea/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndoStack(t *testing.T) {
+	t.Parallel()
+
+	var s undoStack
+	now := time.Now()
+	s.record(editState{value: ""}, true, now)
+	s.record(editState{value: "h"}, true, now.Add(100*time.Millisecond))
+	s.record(editState{value: "hi"}, true, now.Add(2*time.Second))
+	s.record(editState{value: "hi there"}, false, now.Add(2100*time.Millisecond))
+	require.Len(t, s.undo, 3, "typing without a pause is undone at once")
+
+	current := editState{value: ""}
+	state, ok := s.undoFrom(current)
+	require.True(t, ok)
+	require.Equal(t, "hi there", state.value)
+	state, ok = s.undoFrom(state)
+	require.True(t, ok)
+	require.Equal(t, "hi", state.value)
+
+	state, ok = s.redoFrom(state)
+	require.True(t, ok)
+	require.Equal(t, "hi there", state.value)
+
+	s.record(state, false, now.Add(3*time.Second))
+	_, ok = s.redoFrom(state)
+	require.False(t, ok, "a new edit drops what was undone")
+}
+
+func TestRemoved(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "world", removed("hello world", "hello "))
+	require.Equal(t, "hello ", removed("hello world", "world"))
+	require.Equal(t, "lo wo", removed("hello world", "helrld"))
+	require.Equal(t, "l", removed("hello", "helo"))
+	require.Equal(t, "", removed("hello", "hello!"))
+}
+
+func TestHistoryKeys(t *testing.T) {
+	t.Parallel()
+
+	ta := textarea.New()
+	ta.Focus()
+	m := &editorCmp{textarea: ta, keyMap: DefaultEditorKeyMap()}
+	typeText := func(s string) {
+		for _, r := range s {
+			before := m.editState()
+			m.textarea, _ = m.textarea.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+			m.edited(before, tea.KeyPressMsg{Code: r, Text: string(r)})
+		}
+	}
+	press := func(k tea.KeyPressMsg) {
+		if _, ok := m.handleHistoryKey(k); ok {
+			return
+		}
+		before := m.editState()
+		m.textarea, _ = m.textarea.Update(k)
+		m.edited(before, k)
+	}
+
+	typeText("first line")
+	press(tea.KeyPressMsg{Code: 'w', Mod: tea.ModCtrl})
+	require.Equal(t, "first ", m.textarea.Value())
+	press(tea.KeyPressMsg{Code: 'w', Mod: tea.ModCtrl})
+	require.Equal(t, "", m.textarea.Value())
+	require.Equal(t, []string{"line", "first "}, m.kills.kills)
+
+	press(tea.KeyPressMsg{Code: 'y', Mod: tea.ModCtrl})
+	require.Equal(t, "first ", m.textarea.Value())
+	press(tea.KeyPressMsg{Code: 'y', Mod: tea.ModAlt})
+	require.Equal(t, "line", m.textarea.Value(), "an older kill replaces the paste")
+
+	press(tea.KeyPressMsg{Code: '_', Mod: tea.ModCtrl})
+	require.Equal(t, "", m.textarea.Value())
+	press(tea.KeyPressMsg{Code: '_', Mod: tea.ModCtrl})
+	require.Equal(t, "first ", m.textarea.Value())
+	press(tea.KeyPressMsg{Code: '_', Mod: tea.ModAlt})
+	require.Equal(t, "", m.textarea.Value())
+}
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+
+	ta := textarea.New()
+	ta.SetWidth(40)
+	m := &editorCmp{textarea: ta}
+	state := editState{value: "one\ntwo\nthree", row: 1, col: 2}
+	m.restore(state)
+	require.Equal(t, state, m.editState())
+}
+
+func TestDraft(t *testing.T) {
+	t.Parallel()
+
+	path := draftPath(filepath.Join(t.TempDir(), "data"))
+	require.Equal(t, "", loadDraft(path))
+	require.NoError(t, saveDraft(path, "a long prompt"))
+	require.Equal(t, "a long prompt", loadDraft(path))
+	require.NoError(t, saveDraft(path, ""))
+	require.Equal(t, "", loadDraft(path))
+	require.NoError(t, saveDraft(path, ""), "removing a missing draft is fine")
+}
@@ -9,6 +9,13 @@ type EditorKeyMap struct {
 	SendMessage key.Binding
 	OpenEditor  key.Binding
 	Newline     key.Binding
+	Undo        key.Binding
+	Redo        key.Binding
+	Yank        key.Binding
+	YankPop     key.Binding
+	Spelling    key.Binding
+	ExpandPaste key.Binding
+	EditPaste   key.Binding
 }
 
 func DefaultEditorKeyMap() EditorKeyMap {
@@ -22,8 +29,8 @@ func DefaultEditorKeyMap() EditorKeyMap {
 			key.WithHelp("enter", "send"),
 		),
 		OpenEditor: key.NewBinding(
-			key.WithKeys("ctrl+o"),
-			key.WithHelp("ctrl+o", "open editor"),
+			key.WithKeys("ctrl+e", "ctrl+o"),
+			key.WithHelp("ctrl+e", "open editor"),
 		),
 		Newline: key.NewBinding(
 			key.WithKeys("shift+enter", "ctrl+j"),
@@ -32,6 +39,36 @@ func DefaultEditorKeyMap() EditorKeyMap {
 			// to reflect that.
 			key.WithHelp("ctrl+j", "newline"),
 		),
+		Undo: key.NewBinding(
+			// Terminals send ctrl+/ as ctrl+_ unless they report keys
+			// unambiguously.
+			key.WithKeys("ctrl+_", "ctrl+/"),
+			key.WithHelp("ctrl+/", "undo"),
+		),
+		Redo: key.NewBinding(
+			key.WithKeys("alt+_", "alt+/"),
+			key.WithHelp("alt+/", "redo"),
+		),
+		Yank: key.NewBinding(
+			key.WithKeys("ctrl+y"),
+			key.WithHelp("ctrl+y", "paste deleted text"),
+		),
+		YankPop: key.NewBinding(
+			key.WithKeys("alt+y"),
+			key.WithHelp("alt+y", "paste older deleted text"),
+		),
+		Spelling: key.NewBinding(
+			key.WithKeys("alt+s"),
+			key.WithHelp("alt+s", "correct spelling"),
+		),
+		ExpandPaste: key.NewBinding(
+			key.WithKeys("alt+p"),
+			key.WithHelp("alt+p", "expand paste"),
+		),
+		EditPaste: key.NewBinding(
+			key.WithKeys("alt+e"),
+			key.WithHelp("alt+e", "edit paste"),
+		),
 	}
 }
 
@@ -42,6 +79,13 @@ func (k EditorKeyMap) KeyBindings() []key.Binding {
 		k.SendMessage,
 		k.OpenEditor,
 		k.Newline,
+		k.Undo,
+		k.Redo,
+		k.Yank,
+		k.YankPop,
+		k.Spelling,
+		k.ExpandPaste,
+		k.EditPaste,
 		AttachmentsKeyMaps.AttachmentDeleteMode,
 		AttachmentsKeyMaps.DeleteAllAttachments,
 		AttachmentsKeyMaps.Escape,
@@ -0,0 +1,161 @@
+package editor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/mention"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/symbols"
+	"github.com/charmbracelet/crush/internal/tui/components/chat"
+	"github.com/charmbracelet/crush/internal/tui/components/completions"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+// mentionTimeout bounds the time spent attaching what a prompt mentions
+// before it is sent.
+const mentionTimeout = 30 * time.Second
+
+// symbolsIndexedMsg carries a fresh index of the symbols of the workspace.
+type symbolsIndexedMsg struct {
+	index []symbols.Symbol
+}
+
+func (symbolsIndexedMsg) ownMsg() {}
+
+// pageFetchedMsg carries a web page fetched to be attached.
+type pageFetchedMsg struct {
+	attachment message.Attachment
+	err        error
+}
+
+func (pageFetchedMsg) ownMsg() {}
+
+// mentionsResolvedMsg carries a prompt whose mentions were attached, and
+// the mentions that couldn't be.
+type mentionsResolvedMsg struct {
+	send chat.SendMsg
+	errs []string
+}
+
+func (mentionsResolvedMsg) ownMsg() {}
+
+// indexSymbols indexes the symbols of the workspace in the background,
+// unless it is already being done.
+func (m *editorCmp) indexSymbols() tea.Cmd {
+	if m.indexing || m.app == nil || m.app.Config() == nil {
+		return nil
+	}
+	m.indexing = true
+	dir := m.app.Config().WorkingDir()
+	return func() tea.Msg {
+		index, _ := symbols.Index(context.Background(), dir)
+		return symbolsIndexedMsg{index: index}
+	}
+}
+
+// symbolCompletions returns the completions of the symbols of index, with
+// their path relative to the workspace.
+func (m *editorCmp) symbolCompletions(index []symbols.Symbol) []completions.Completion {
+	items := make([]completions.Completion, 0, len(index))
+	for _, symbol := range index {
+		symbol.Path = m.relPath(symbol.Path)
+		items = append(items, completions.Completion{
+			Title: fmt.Sprintf("%s %s:%d", symbol.Name, symbol.Path, symbol.Line),
+			Value: SymbolCompletionItem{Symbol: symbol},
+		})
+	}
+	return items
+}
+
+// relPath returns path relative to the workspace, when it's in it.
+func (m *editorCmp) relPath(path string) string {
+	if m.app == nil || m.app.Config() == nil || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(m.app.Config().WorkingDir(), path); err == nil && filepath.IsLocal(rel) {
+		return rel
+	}
+	return path
+}
+
+// unattached returns the mentions of value that aren't attached yet.
+func unattached(value string, attachments []message.Attachment) []mention.Mention {
+	var mentions []mention.Mention
+	for _, m := range mention.Parse(value) {
+		attached := slices.ContainsFunc(attachments, func(a message.Attachment) bool {
+			return a.FilePath == m.Value || a.FileName == m.Value
+		})
+		duplicate := slices.ContainsFunc(mentions, func(other mention.Mention) bool {
+			return other.Value == m.Value
+		})
+		if !attached && !duplicate {
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions
+}
+
+// sendWithMentions attaches the files, symbols and pages the prompt
+// mentions before sending it.
+func (m *editorCmp) sendWithMentions(send chat.SendMsg, mentions []mention.Mention) tea.Cmd {
+	resolver := m.resolver()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), mentionTimeout)
+		defer cancel()
+		var errs []string
+		for _, mention := range mentions {
+			attachment, ok, err := resolver.Resolve(ctx, mention)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Sprintf("@%s: %v", mention.Value, err))
+			case ok:
+				send.Attachments = append(send.Attachments, attachment)
+			}
+		}
+		return mentionsResolvedMsg{send: send, errs: errs}
+	}
+}
+
+// resolver returns the resolver of the mentions of the prompt.
+func (m *editorCmp) resolver() *mention.Resolver {
+	cfg := m.app.Config()
+	return &mention.Resolver{
+		Dir:     cfg.WorkingDir(),
+		Cache:   tools.NewFetchCache(filepath.Join(cfg.Options.DataDirectory, "fetch"), tools.FetchCacheTTL),
+		Symbols: m.symbolIndex,
+	}
+}
+
+// fetchPage fetches the web page at rawURL to attach it.
+func (m *editorCmp) fetchPage(rawURL string) tea.Cmd {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	resolver := m.resolver()
+	return tea.Batch(
+		util.ReportInfo("Fetching "+rawURL+"..."),
+		func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), mentionTimeout)
+			defer cancel()
+			attachment, _, err := resolver.Resolve(ctx, mention.Mention{Value: rawURL})
+			return pageFetchedMsg{attachment: attachment, err: err}
+		},
+	)
+}
+
+// mentionsResolved sends the prompt whose mentions were attached.
+func mentionsResolved(msg mentionsResolvedMsg) tea.Cmd {
+	cmds := []tea.Cmd{util.CmdHandler(msg.send)}
+	if len(msg.errs) > 0 {
+		cmds = append(cmds, util.ReportWarn("Couldn't attach "+strings.Join(msg.errs, "; ")))
+	}
+	return tea.Batch(cmds...)
+}
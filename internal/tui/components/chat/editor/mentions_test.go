@@ -0,0 +1,22 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnattached(t *testing.T) {
+	t.Parallel()
+
+	attachments := []message.Attachment{
+		{FilePath: "main.go", FileName: "main.go"},
+		{FilePath: "app.go:10-49", FileName: "Run"},
+	}
+	var got []string
+	for _, m := range unattached("Compare @main.go, @Run and @README.md with @https://example.com and @README.md", attachments) {
+		got = append(got, m.Value)
+	}
+	require.Equal(t, []string{"README.md", "https://example.com"}, got)
+}
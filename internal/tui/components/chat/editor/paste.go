@@ -0,0 +1,104 @@
+package editor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+// pastePrefix starts the names of the files pastes are kept in until they
+// are sent.
+const pastePrefix = "paste_"
+
+// pasteEditedMsg is sent once a paste was edited in the external editor.
+type pasteEditedMsg struct {
+	path    string
+	content []byte
+}
+
+func (pasteEditedMsg) ownMsg() {}
+
+// isPaste reports whether attachment holds text pasted in the prompt rather
+// than a file.
+func isPaste(attachment message.Attachment) bool {
+	return filepath.Dir(attachment.FilePath) == filepath.Clean(os.TempDir()) &&
+		strings.HasPrefix(filepath.Base(attachment.FilePath), pastePrefix)
+}
+
+// pasteName names the chip of a paste after its lines.
+func pasteName(content []byte) string {
+	lines := strings.Count(strings.TrimRight(string(content), "\n"), "\n") + 1
+	if lines == 1 {
+		return "pasted 1 line"
+	}
+	return fmt.Sprintf("pasted %d lines", lines)
+}
+
+// lastPaste returns the index of the last paste attached, or -1.
+func (m *editorCmp) lastPaste() int {
+	for i, attachment := range slices.Backward(m.attachments) {
+		if isPaste(attachment) {
+			return i
+		}
+	}
+	return -1
+}
+
+// expandPaste writes the last paste in the prompt, where it can be edited,
+// instead of attaching it.
+func (m *editorCmp) expandPaste() tea.Cmd {
+	i := m.lastPaste()
+	if i < 0 {
+		return util.ReportInfo("Nothing pasted to expand")
+	}
+	paste := m.attachments[i]
+	m.attachments = slices.Delete(m.attachments, i, i+1)
+	_ = os.Remove(paste.FilePath)
+	m.textarea.InsertString(string(paste.Content))
+	return nil
+}
+
+// editPaste opens the last paste in the external editor, keeping it
+// attached.
+func (m *editorCmp) editPaste() tea.Cmd {
+	i := m.lastPaste()
+	if i < 0 {
+		return util.ReportInfo("Nothing pasted to edit")
+	}
+	path := m.attachments[i].FilePath
+	return util.ExecShell(context.TODO(), config.ExternalToolEditor, externalEditorCommand(util.Editor(), path), func(err error) tea.Msg {
+		if err != nil {
+			return util.ReportError(err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return util.ReportError(err)
+		}
+		return pasteEditedMsg{path: path, content: content}
+	})
+}
+
+// pasteEdited updates the paste edited, removing it when it was emptied.
+func (m *editorCmp) pasteEdited(msg pasteEditedMsg) {
+	i := slices.IndexFunc(m.attachments, func(a message.Attachment) bool {
+		return a.FilePath == msg.path
+	})
+	if i < 0 {
+		return
+	}
+	if strings.TrimSpace(string(msg.content)) == "" {
+		m.attachments = slices.Delete(m.attachments, i, i+1)
+		_ = os.Remove(msg.path)
+		return
+	}
+	m.attachments[i].Content = msg.content
+	m.attachments[i].FileName = pasteName(msg.content)
+}
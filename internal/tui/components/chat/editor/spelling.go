@@ -0,0 +1,138 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/spell"
+	"github.com/charmbracelet/crush/internal/tui/components/completions"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const maxSuggestions = 8
+
+// SpellingCompletionItem is a correction of a misspelled word of the
+// prompt.
+type SpellingCompletionItem struct {
+	Suggestion string
+}
+
+// loadSpellChecker returns the spell checker the options ask for, nil when
+// spell checking is disabled.
+func loadSpellChecker(opts config.SpellCheck) (*spell.Checker, error) {
+	if !opts.Enabled {
+		return nil, nil
+	}
+	dictionary := opts.Dictionary
+	if dictionary == "" {
+		dictionary = spell.DefaultDictionary
+	}
+	checker, err := spell.Load(dictionary, opts.Words)
+	if err != nil {
+		return nil, fmt.Errorf("spell checking is unavailable: %w", err)
+	}
+	return checker, nil
+}
+
+// cursorOffset returns the byte offset of the cursor in the prompt.
+func (m *editorCmp) cursorOffset() int {
+	s := m.editState()
+	offset := 0
+	for i, line := range strings.Split(s.value, "\n") {
+		if i == s.row {
+			runes := []rune(line)
+			return offset + len(string(runes[:min(s.col, len(runes))]))
+		}
+		offset += len(line) + 1
+	}
+	return len(s.value)
+}
+
+// moveToOffset moves the cursor to the byte offset in the prompt.
+func (m *editorCmp) moveToOffset(offset int) {
+	before := m.textarea.Value()[:offset]
+	row := strings.Count(before, "\n")
+	col := len([]rune(before[strings.LastIndex(before, "\n")+1:]))
+	m.restore(editState{value: m.textarea.Value(), row: row, col: col})
+}
+
+// suggestSpelling opens the corrections of the misspelled word at or
+// before the cursor.
+func (m *editorCmp) suggestSpelling() tea.Cmd {
+	if m.spell == nil {
+		return util.ReportInfo("Spell checking is disabled")
+	}
+	misspelled := m.spell.Misspelled(m.textarea.Value())
+	offset := m.cursorOffset()
+	var word *spell.Word
+	for i := range misspelled {
+		if misspelled[i].Start <= offset {
+			word = &misspelled[i]
+		}
+	}
+	if word == nil && len(misspelled) > 0 {
+		word = &misspelled[0]
+	}
+	if word == nil {
+		return util.ReportInfo("No misspelled words")
+	}
+	suggestions := m.spell.Suggest(word.Text, maxSuggestions)
+	if len(suggestions) == 0 {
+		return util.ReportInfo(fmt.Sprintf("No suggestions for %q", word.Text))
+	}
+
+	items := make([]completions.Completion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		items = append(items, completions.Completion{
+			Title: suggestion,
+			Value: SpellingCompletionItem{Suggestion: suggestion},
+		})
+	}
+	m.correcting = *word
+	m.isCompletionsOpen = true
+	m.currentQuery = ""
+	m.completionsStartIndex = word.Start
+	x, y := m.completionsPosition()
+	return util.CmdHandler(completions.OpenCompletionsMsg{
+		Completions: items,
+		X:           x,
+		Y:           y,
+	})
+}
+
+// correct replaces the word being corrected with the suggestion.
+func (m *editorCmp) correct(item SpellingCompletionItem) {
+	w := m.correcting
+	value := m.textarea.Value()
+	if w.Text == "" || w.End > len(value) || value[w.Start:w.End] != w.Text {
+		return
+	}
+	m.textarea.SetValue(value[:w.Start] + item.Suggestion + value[w.End:])
+	m.correcting = spell.Word{Text: item.Suggestion, Start: w.Start, End: w.Start + len(item.Suggestion)}
+	m.moveToOffset(m.correcting.End)
+}
+
+// spellingView lists the misspelled words of the prompt in a line, empty
+// when there are none.
+func (m *editorCmp) spellingView(width int) string {
+	if m.spell == nil {
+		return ""
+	}
+	misspelled := m.spell.Misspelled(m.textarea.Value())
+	if len(misspelled) == 0 {
+		return ""
+	}
+	t := styles.CurrentTheme()
+	words := make([]string, 0, len(misspelled))
+	for _, word := range misspelled {
+		words = append(words, t.S().Base.Foreground(t.Error).Underline(true).Render(word.Text))
+	}
+	line := t.S().Muted.Render("Spelling: ") +
+		strings.Join(words, t.S().Muted.Render(", ")) +
+		t.S().Subtle.Render(" · "+m.keyMap.Spelling.Help().Key+" to correct")
+	return ansi.Truncate(line, width, "…")
+}
@@ -0,0 +1,35 @@
+package editor
+
+import (
+	"testing"
+
+	"charm.land/bubbles/v2/textarea"
+	"github.com/charmbracelet/crush/internal/spell"
+	"github.com/charmbracelet/crush/internal/tui/components/completions"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrectSpelling(t *testing.T) {
+	t.Parallel()
+
+	ta := textarea.New()
+	ta.SetWidth(80)
+	ta.Focus()
+	m := &editorCmp{textarea: ta, spell: spell.New("please", "fix", "the", "tests", "then", "ten")}
+	m.textarea.SetValue("please fix teh\ntests")
+	m.moveToOffset(len("please fix te"))
+	require.Equal(t, len("please fix te"), m.cursorOffset())
+
+	msg := m.suggestSpelling()()
+	open, ok := msg.(completions.OpenCompletionsMsg)
+	require.True(t, ok)
+	require.Equal(t, "the", open.Completions[0].Title)
+	require.True(t, m.isCompletionsOpen)
+
+	// Moving through the suggestions replaces the word each time.
+	m.correct(SpellingCompletionItem{Suggestion: "ten"})
+	require.Equal(t, "please fix ten\ntests", m.textarea.Value())
+	m.correct(open.Completions[0].Value.(SpellingCompletionItem))
+	require.Equal(t, "please fix the\ntests", m.textarea.Value())
+	require.Equal(t, len("please fix the"), m.cursorOffset())
+}
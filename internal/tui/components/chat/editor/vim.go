@@ -0,0 +1,1111 @@
+package editor
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/tui/components/completions"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+// vimMode is a mode of editing the prompt like vim.
+type vimMode int
+
+const (
+	vimInsert vimMode = iota
+	vimNormal
+	vimVisual
+	vimVisualLine
+)
+
+func (m vimMode) String() string {
+	switch m {
+	case vimNormal:
+		return "NORMAL"
+	case vimVisual:
+		return "VISUAL"
+	case vimVisualLine:
+		return "VISUAL LINE"
+	}
+	return "INSERT"
+}
+
+// register holds yanked or deleted text, put as whole lines when linewise.
+type register struct {
+	text     string
+	linewise bool
+}
+
+// vimAction is what a key asks of the editor besides changing the prompt.
+type vimAction int
+
+const (
+	vimUndo vimAction = iota + 1
+	vimRedo
+	vimSend
+)
+
+// vimResult is the prompt and the cursor after a key, and what else the
+// key asks for.
+type vimResult struct {
+	text []rune
+	pos  int
+
+	action vimAction
+	// count is how many times to undo or redo.
+	count int
+	// copied is text yanked to the clipboard register.
+	copied string
+}
+
+// vimCommand is a command of the normal or the visual mode, like "a2dw.
+type vimCommand struct {
+	register rune
+	count    int
+	// operator is d, c or y when the command operates on a motion or a
+	// text object, and empty otherwise.
+	operator string
+	// name is the motion, the text object, like iw, or the command, like p.
+	// An operator repeated, like dd, operates on lines.
+	name string
+	// char is the character of f, F, t, T and r.
+	char rune
+}
+
+// vim edits the prompt with the modes, operators, motions, text objects and
+// registers of vim.
+type vim struct {
+	mode vimMode
+	// keys are the keys of a command still being typed, like 2d or "a.
+	keys []string
+	// anchor is where the visual selection started.
+	anchor    int
+	registers map[rune]register
+	// lastFind is the last f, F, t or T with its character, repeated with ;
+	// and ,.
+	lastFind vimCommand
+}
+
+func newVim() *vim {
+	return &vim{registers: map[rune]register{}}
+}
+
+// pending returns the keys of the command being typed.
+func (v *vim) pending() string {
+	return strings.Join(v.keys, "")
+}
+
+// selection returns the range of the visual selection, end excluded.
+func (v *vim) selection(text []rune, pos int) (start, end int) {
+	start, end = min(v.anchor, pos), max(v.anchor, pos)+1
+	if v.mode == vimVisualLine {
+		start, end = lineStart(text, start), lineEnd(text, end-1)
+	}
+	return start, min(end, len(text))
+}
+
+// insert switches to the insert mode.
+func (v *vim) insert() {
+	v.mode = vimInsert
+	v.keys = nil
+}
+
+// normal switches to the normal mode from the insert mode, where the cursor
+// goes back on the last character typed like in vim.
+func (v *vim) normal(text []rune, pos int) int {
+	v.mode = vimNormal
+	v.keys = nil
+	if pos > lineStart(text, pos) {
+		pos--
+	}
+	return pos
+}
+
+// key handles a key of the normal or the visual mode, key being the text
+// typed or the name of the key, like esc.
+func (v *vim) key(text []rune, pos int, key string) vimResult {
+	res := vimResult{text: text, pos: pos}
+	visual := v.mode == vimVisual || v.mode == vimVisualLine
+	if key == "esc" {
+		if len(v.keys) == 0 && visual {
+			v.mode = vimNormal
+		}
+		v.keys = nil
+		return res
+	}
+	v.keys = append(v.keys, key)
+	cmd, complete, valid := parseVim(v.keys, visual)
+	if !valid {
+		v.keys = nil
+		return res
+	}
+	if !complete {
+		return res
+	}
+	v.keys = nil
+	if visual {
+		res = v.visual(res, cmd)
+	} else {
+		res = v.run(res, cmd)
+	}
+	if v.mode == vimNormal {
+		res.pos = clampNormal(res.text, res.pos)
+	}
+	return res
+}
+
+// run runs a command of the normal mode.
+func (v *vim) run(res vimResult, cmd vimCommand) vimResult {
+	text, pos := res.text, res.pos
+	count := max(1, cmd.count)
+
+	if cmd.operator != "" {
+		start, end, linewise, ok := v.target(text, pos, cmd)
+		if !ok {
+			return res
+		}
+		return v.operate(res, cmd.operator, cmd.register, start, end, linewise)
+	}
+
+	switch cmd.name {
+	case "x":
+		return v.run(res, vimCommand{register: cmd.register, count: count, operator: "d", name: "l"})
+	case "X":
+		return v.run(res, vimCommand{register: cmd.register, count: count, operator: "d", name: "h"})
+	case "D":
+		return v.run(res, vimCommand{register: cmd.register, count: count, operator: "d", name: "$"})
+	case "C":
+		return v.run(res, vimCommand{register: cmd.register, count: count, operator: "c", name: "$"})
+	case "s":
+		return v.run(res, vimCommand{register: cmd.register, count: count, operator: "c", name: "l"})
+	case "S":
+		return v.run(res, vimCommand{register: cmd.register, count: count, operator: "c", name: "cc"})
+	case "Y":
+		return v.run(res, vimCommand{register: cmd.register, count: count, operator: "y", name: "yy"})
+	case "p", "P":
+		return v.put(res, cmd)
+	case "i":
+		v.insert()
+	case "a":
+		v.insert()
+		res.pos = min(pos+1, lineEnd(text, pos))
+	case "I":
+		v.insert()
+		res.pos = firstNonBlank(text, pos)
+	case "A":
+		v.insert()
+		res.pos = lineEnd(text, pos)
+	case "o":
+		v.insert()
+		end := lineEnd(text, pos)
+		res.text = splice(text, end, end, []rune("\n"))
+		res.pos = end + 1
+	case "O":
+		v.insert()
+		start := lineStart(text, pos)
+		res.text = splice(text, start, start, []rune("\n"))
+		res.pos = start
+	case "r":
+		end := pos + count
+		if end > lineEnd(text, pos) {
+			return res
+		}
+		res.text = splice(text, pos, end, []rune(strings.Repeat(string(cmd.char), count)))
+		res.pos = end - 1
+	case "~":
+		end := min(pos+count, lineEnd(text, pos))
+		res.text = splice(text, pos, end, toggleCase(text[pos:end]))
+		res.pos = end
+	case "J":
+		res.text, res.pos = join(text, pos, max(1, count-1))
+	case "u":
+		res.action, res.count = vimUndo, count
+	case "ctrl+r":
+		res.action, res.count = vimRedo, count
+	case "v":
+		v.mode, v.anchor = vimVisual, pos
+	case "V":
+		v.mode, v.anchor = vimVisualLine, pos
+	case "enter":
+		res.action = vimSend
+	default:
+		target, _, _, ok := v.motion(text, pos, cmd, count)
+		if ok {
+			res.pos = target
+		}
+	}
+	return res
+}
+
+// visual runs a command of the visual mode.
+func (v *vim) visual(res vimResult, cmd vimCommand) vimResult {
+	text, pos := res.text, res.pos
+	start, end := v.selection(text, pos)
+	linewise := v.mode == vimVisualLine
+	switch cmd.name {
+	case "d", "x":
+		v.mode = vimNormal
+		return v.operate(res, "d", cmd.register, start, end, linewise)
+	case "c", "s":
+		return v.operate(res, "c", cmd.register, start, end, linewise)
+	case "y":
+		v.mode = vimNormal
+		return v.operate(res, "y", cmd.register, start, end, linewise)
+	case "p", "P":
+		v.mode = vimNormal
+		// The selection is replaced with the register, which p then
+		// replaces with the selection like in vim.
+		put := v.get(cmd.register)
+		if cmd.name == "p" {
+			res = v.operate(res, "d", cmd.register, start, end, linewise)
+		} else {
+			if linewise {
+				start, end = lines(text, start, end)
+			}
+			res.text, res.pos = deleteRange(text, start, end, linewise)
+		}
+		inserted := put.text
+		switch {
+		case linewise && !put.linewise:
+			inserted += "\n"
+		case !linewise && put.linewise:
+			inserted = "\n" + inserted
+		}
+		at := min(start, len(res.text))
+		if linewise && at == len(res.text) && at > 0 {
+			// The last lines were replaced.
+			inserted = "\n" + strings.TrimSuffix(inserted, "\n")
+			at = len(res.text)
+		}
+		res.text = splice(res.text, at, at, []rune(inserted))
+		res.pos = at + max(0, len([]rune(inserted))-1)
+		if linewise || put.linewise {
+			first := at
+			if strings.HasPrefix(inserted, "\n") {
+				first++
+			}
+			res.pos = firstNonBlank(res.text, first)
+		}
+	case "~", "u", "U":
+		v.mode = vimNormal
+		changed := slices.Clone(text[start:end])
+		switch cmd.name {
+		case "~":
+			changed = toggleCase(changed)
+		case "u":
+			changed = []rune(strings.ToLower(string(changed)))
+		case "U":
+			changed = []rune(strings.ToUpper(string(changed)))
+		}
+		res.text = splice(text, start, end, changed)
+		res.pos = start
+	case "J":
+		v.mode = vimNormal
+		lines := strings.Count(string(text[start:max(start, end-1)]), "\n")
+		res.text, res.pos = join(text, start, max(1, lines))
+	case "o":
+		v.anchor, res.pos = pos, v.anchor
+	case "v", "V":
+		mode := vimVisual
+		if cmd.name == "V" {
+			mode = vimVisualLine
+		}
+		if v.mode == mode {
+			v.mode = vimNormal
+		} else {
+			v.mode = mode
+		}
+	default:
+		if strings.HasPrefix(cmd.name, "i") || strings.HasPrefix(cmd.name, "a") {
+			start, end, ok := textObject(text, pos, cmd.name)
+			if ok && end > start {
+				v.anchor, res.pos = start, end-1
+			}
+			return res
+		}
+		target, _, _, ok := v.motion(text, pos, cmd, max(1, cmd.count))
+		if ok {
+			res.pos = min(target, max(0, len(text)-1))
+		}
+	}
+	return res
+}
+
+// target returns the range an operator operates on, end excluded.
+func (v *vim) target(text []rune, pos int, cmd vimCommand) (start, end int, linewise, ok bool) {
+	count := max(1, cmd.count)
+	switch {
+	case cmd.name == cmd.operator+cmd.operator:
+		end = pos
+		for range count - 1 {
+			if next := lineEnd(text, end); next < len(text) {
+				end = next + 1
+			}
+		}
+		return pos, end, true, true
+	case strings.HasPrefix(cmd.name, "i") || strings.HasPrefix(cmd.name, "a"):
+		start, end, ok = textObject(text, pos, cmd.name)
+		return start, end, false, ok
+	}
+
+	// Like in vim, cw changes to the end of the word, and dw leaves the
+	// line break after the last word of a line.
+	name := cmd.name
+	if cmd.operator == "c" && pos < len(text) && !unicode.IsSpace(text[pos]) {
+		switch name {
+		case "w":
+			name = "e"
+		case "W":
+			name = "E"
+		}
+	}
+	target, linewise, inclusive, ok := v.motion(text, pos, vimCommand{name: name, count: cmd.count, char: cmd.char}, count)
+	if !ok {
+		return 0, 0, false, false
+	}
+	if (name == "w" || name == "W") && target > pos {
+		if i := slices.Index(text[pos:target], '\n'); i > 0 {
+			target = pos + i
+		}
+	}
+	start, end = min(pos, target), max(pos, target)
+	if inclusive {
+		end = min(end+1, len(text))
+	}
+	return start, end, linewise, true
+}
+
+// operate deletes, changes or yanks from start to end.
+func (v *vim) operate(res vimResult, operator string, reg rune, start, end int, linewise bool) vimResult {
+	text := res.text
+	if linewise {
+		start, end = lines(text, start, end)
+	}
+	yanked := string(text[start:end])
+	if linewise && !strings.HasSuffix(yanked, "\n") {
+		yanked += "\n"
+	}
+	res.copied = v.store(reg, register{text: yanked, linewise: linewise}, operator == "y")
+
+	switch operator {
+	case "y":
+		if !linewise || start < lineStart(text, res.pos) {
+			res.pos = start
+		}
+	case "d":
+		res.text, res.pos = deleteRange(text, start, end, linewise)
+	case "c":
+		v.insert()
+		if linewise {
+			// The lines are emptied rather than removed, to type new ones.
+			if end > start && text[end-1] == '\n' {
+				end--
+			}
+			start = firstNonBlank(text, start)
+		}
+		res.text = splice(text, start, end, nil)
+		res.pos = start
+	}
+	return res
+}
+
+// put puts the register of cmd after or, with P, before the cursor.
+func (v *vim) put(res vimResult, cmd vimCommand) vimResult {
+	text, pos := res.text, res.pos
+	reg := v.get(cmd.register)
+	if reg.text == "" {
+		return res
+	}
+	count := max(1, cmd.count)
+	if reg.linewise {
+		lines := strings.Repeat(reg.text, count)
+		if cmd.name == "P" {
+			at := lineStart(text, pos)
+			res.text = splice(text, at, at, []rune(lines))
+			res.pos = at
+			return res
+		}
+		at := lineEnd(text, pos)
+		res.text = splice(text, at, at, []rune("\n"+strings.TrimSuffix(lines, "\n")))
+		res.pos = at + 1
+		return res
+	}
+	inserted := []rune(strings.Repeat(reg.text, count))
+	at := pos
+	if cmd.name == "p" && pos < lineEnd(text, pos) {
+		at++
+	}
+	res.text = splice(text, at, at, inserted)
+	res.pos = at + len(inserted) - 1
+	return res
+}
+
+// store keeps text yanked or deleted in reg, and in the unnamed register,
+// yanks also going to 0 and deletions shifting 1 to 9. It returns the text
+// copied to the clipboard, for + and *.
+func (v *vim) store(reg rune, r register, yank bool) string {
+	switch {
+	case reg == '_':
+		return ""
+	case reg >= 'A' && reg <= 'Z':
+		lower := unicode.ToLower(reg)
+		prev := v.registers[lower]
+		r = register{text: prev.text + r.text, linewise: prev.linewise || r.linewise}
+		v.registers[lower] = r
+	case reg != 0:
+		v.registers[reg] = r
+	case yank:
+		v.registers['0'] = r
+	default:
+		for i := '9'; i > '1'; i-- {
+			if prev, ok := v.registers[i-1]; ok {
+				v.registers[i] = prev
+			}
+		}
+		v.registers['1'] = r
+	}
+	v.registers['"'] = r
+	if reg == '+' || reg == '*' {
+		return r.text
+	}
+	return ""
+}
+
+// get returns the register to put, the unnamed one by default.
+func (v *vim) get(reg rune) register {
+	if reg == 0 {
+		reg = '"'
+	}
+	return v.registers[unicode.ToLower(reg)]
+}
+
+// motion returns where a motion moves the cursor, whether it moves by lines,
+// and whether an operator includes the character it moves to.
+func (v *vim) motion(text []rune, pos int, cmd vimCommand, count int) (target int, linewise, inclusive, ok bool) {
+	start, end := lineStart(text, pos), lineEnd(text, pos)
+	switch cmd.name {
+	case "h", "left", "backspace":
+		return max(start, pos-count), false, false, pos > start
+	case "l", "right", " ":
+		return min(end, pos+count), false, false, pos < end
+	case "j", "down", "k", "up":
+		delta := count
+		if cmd.name == "k" || cmd.name == "up" {
+			delta = -count
+		}
+		row, col := lineOf(text, pos)
+		lines := strings.Count(string(text), "\n") + 1
+		to := max(0, min(lines-1, row+delta))
+		if to == row {
+			return pos, true, false, false
+		}
+		return lineOffset(text, to, col), true, false, true
+	case "w", "W":
+		for range count {
+			pos = nextWordStart(text, pos, cmd.name == "W")
+		}
+		return pos, false, false, true
+	case "b", "B":
+		for range count {
+			pos = prevWordStart(text, pos, cmd.name == "B")
+		}
+		return pos, false, false, true
+	case "e", "E":
+		for range count {
+			pos = wordEnd(text, pos, cmd.name == "E")
+		}
+		return pos, false, true, true
+	case "0":
+		return start, false, false, true
+	case "^":
+		return firstNonBlank(text, pos), false, false, true
+	case "$":
+		for range count - 1 {
+			if end < len(text) {
+				end = lineEnd(text, end+1)
+			}
+		}
+		return end, false, false, true
+	case "gg", "G":
+		row := strings.Count(string(text), "\n")
+		if cmd.name == "gg" {
+			row = 0
+		}
+		if cmd.count > 0 {
+			row = min(row, cmd.count-1)
+		}
+		return firstNonBlank(text, lineOffset(text, row, 0)), true, false, true
+	case "f", "F", "t", "T":
+		v.lastFind = vimCommand{name: cmd.name, char: cmd.char}
+		return find(text, pos, cmd.name, cmd.char, count)
+	case ";", ",":
+		if v.lastFind.name == "" {
+			return pos, false, false, false
+		}
+		name := v.lastFind.name
+		if cmd.name == "," {
+			name = map[string]string{"f": "F", "F": "f", "t": "T", "T": "t"}[name]
+		}
+		return find(text, pos, name, v.lastFind.char, count)
+	}
+	return pos, false, false, false
+}
+
+// parseVim parses the keys of a command. It reports whether the command is
+// complete, and whether it's valid so far.
+func parseVim(keys []string, visual bool) (cmd vimCommand, complete, valid bool) {
+	i := 0
+	next := func() (string, bool) {
+		if i >= len(keys) {
+			return "", false
+		}
+		i++
+		return keys[i-1], true
+	}
+	counted := func(k string, ok bool) (string, int, bool) {
+		n := 0
+		for ok && len(k) == 1 && k[0] >= '0' && k[0] <= '9' && (k != "0" || n > 0) {
+			n = n*10 + int(k[0]-'0')
+			k, ok = next()
+		}
+		return k, n, ok
+	}
+
+	k, ok := next()
+	if !ok {
+		return cmd, false, true
+	}
+	if k == `"` {
+		var r string
+		if r, ok = next(); !ok {
+			return cmd, false, true
+		}
+		if !isRegister(r) {
+			return cmd, false, false
+		}
+		cmd.register = []rune(r)[0]
+		k, ok = next()
+	}
+	k, cmd.count, ok = counted(k, ok)
+	if !ok {
+		return cmd, false, true
+	}
+
+	if !visual && (k == "d" || k == "c" || k == "y") {
+		cmd.operator = k
+		var n int
+		k, n, ok = counted(next())
+		if !ok {
+			return cmd, false, true
+		}
+		if n > 0 {
+			cmd.count = max(1, cmd.count) * n
+		}
+		if k == cmd.operator {
+			cmd.name = k + k
+			return cmd, true, true
+		}
+		if k == "i" || k == "a" {
+			obj, ok := next()
+			if !ok {
+				return cmd, false, true
+			}
+			cmd.name = k + obj
+			return cmd, true, isTextObject(cmd.name)
+		}
+	}
+
+	if visual && (k == "i" || k == "a") {
+		obj, ok := next()
+		if !ok {
+			return cmd, false, true
+		}
+		cmd.name = k + obj
+		return cmd, true, isTextObject(cmd.name)
+	}
+
+	switch k {
+	case "g":
+		g, ok := next()
+		if !ok {
+			return cmd, false, true
+		}
+		cmd.name = "g" + g
+		return cmd, true, cmd.name == "gg"
+	case "f", "F", "t", "T", "r":
+		c, ok := next()
+		if !ok {
+			return cmd, false, true
+		}
+		if len([]rune(c)) != 1 || (k == "r" && cmd.operator != "") {
+			return cmd, false, false
+		}
+		cmd.name, cmd.char = k, []rune(c)[0]
+		return cmd, true, !visual || k != "r"
+	}
+	cmd.name = k
+	switch {
+	case slices.Contains(vimMotions, k):
+		return cmd, true, true
+	case cmd.operator != "":
+		return cmd, false, false
+	case visual:
+		return cmd, true, slices.Contains(vimVisualCommands, k)
+	}
+	return cmd, true, slices.Contains(vimCommands, k)
+}
+
+var (
+	vimMotions        = []string{"h", "j", "k", "l", "left", "down", "up", "right", " ", "backspace", "w", "W", "b", "B", "e", "E", "0", "^", "$", "G", ";", ","}
+	vimCommands       = []string{"x", "X", "D", "C", "s", "S", "Y", "p", "P", "i", "a", "I", "A", "o", "O", "~", "J", "u", "ctrl+r", "v", "V", "enter"}
+	vimVisualCommands = []string{"d", "x", "c", "s", "y", "p", "P", "~", "u", "U", "J", "o", "v", "V"}
+)
+
+func isRegister(r string) bool {
+	if len([]rune(r)) != 1 {
+		return false
+	}
+	c := []rune(r)[0]
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.ContainsRune(`"_+*`, c)
+}
+
+func isTextObject(name string) bool {
+	return len(name) == 2 && strings.ContainsAny(name[1:], `wW"'`+"`"+`()b[]{}B<>`)
+}
+
+// textObject returns the range of a text object around pos, like iw or a(.
+func textObject(text []rune, pos int, name string) (start, end int, ok bool) {
+	if len(text) == 0 {
+		return 0, 0, false
+	}
+	pos = min(pos, len(text)-1)
+	around := name[0] == 'a'
+	switch obj := rune(name[1]); obj {
+	case 'w', 'W':
+		big := obj == 'W'
+		c := class(text[pos], big)
+		start, end = pos, pos+1
+		for start > 0 && text[start-1] != '\n' && class(text[start-1], big) == c {
+			start--
+		}
+		for end < len(text) && text[end] != '\n' && class(text[end], big) == c {
+			end++
+		}
+		if around && c != 0 {
+			// Like in vim, aw takes the spaces after the word, or before it
+			// when there are none after.
+			trailing := end
+			for trailing < len(text) && (text[trailing] == ' ' || text[trailing] == '\t') {
+				trailing++
+			}
+			if trailing > end {
+				return start, trailing, true
+			}
+			for start > 0 && (text[start-1] == ' ' || text[start-1] == '\t') {
+				start--
+			}
+		}
+		return start, end, true
+	case '"', '\'', '`':
+		lineStart, lineEnd := lineStart(text, pos), lineEnd(text, pos)
+		var quotes []int
+		for i := lineStart; i < lineEnd; i++ {
+			if text[i] == obj && (i == lineStart || text[i-1] != '\\') {
+				quotes = append(quotes, i)
+			}
+		}
+		for i := 0; i+1 < len(quotes); i += 2 {
+			open, closing := quotes[i], quotes[i+1]
+			if closing < pos {
+				continue
+			}
+			if around {
+				return open, closing + 1, true
+			}
+			return open + 1, closing, true
+		}
+		return 0, 0, false
+	default:
+		open, closing := brackets(obj)
+		start = -1
+		depth := 0
+		for i := pos; i >= 0; i-- {
+			switch {
+			case text[i] == closing && i != pos:
+				depth++
+			case text[i] == open && depth > 0:
+				depth--
+			case text[i] == open:
+				start = i
+			}
+			if start >= 0 {
+				break
+			}
+		}
+		if start < 0 {
+			return 0, 0, false
+		}
+		depth = 0
+		for i := start + 1; i < len(text); i++ {
+			switch {
+			case text[i] == open:
+				depth++
+			case text[i] == closing && depth > 0:
+				depth--
+			case text[i] == closing:
+				if around {
+					return start, i + 1, true
+				}
+				return start + 1, i, true
+			}
+		}
+		return 0, 0, false
+	}
+}
+
+// brackets returns the brackets of a text object, like ( and ) for b.
+func brackets(obj rune) (open, closing rune) {
+	switch obj {
+	case '[', ']':
+		return '[', ']'
+	case '{', '}', 'B':
+		return '{', '}'
+	case '<', '>':
+		return '<', '>'
+	}
+	return '(', ')'
+}
+
+// find returns where f, F, t or T moves to the count-th char in the line.
+func find(text []rune, pos int, name string, char rune, count int) (target int, linewise, inclusive, ok bool) {
+	start, end := lineStart(text, pos), lineEnd(text, pos)
+	forward := name == "f" || name == "t"
+	i := pos
+	if name == "t" {
+		i++
+	} else if name == "T" {
+		i--
+	}
+	for n := 0; n < count; n++ {
+		for {
+			if forward {
+				i++
+			} else {
+				i--
+			}
+			if i < start || i >= end {
+				return pos, false, false, false
+			}
+			if text[i] == char {
+				break
+			}
+		}
+	}
+	switch name {
+	case "t":
+		i--
+	case "T":
+		i++
+	}
+	return i, false, forward, true
+}
+
+// class is the class of a character for word motions: 0 for spaces, 1 for
+// letters, digits and underscores, 2 for the rest. WORDs are only made of
+// what isn't a space.
+func class(r rune, big bool) int {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case big || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	}
+	return 2
+}
+
+func nextWordStart(text []rune, pos int, big bool) int {
+	if pos >= len(text) {
+		return len(text)
+	}
+	if c := class(text[pos], big); c != 0 {
+		for pos < len(text) && class(text[pos], big) == c {
+			pos++
+		}
+	}
+	for pos < len(text) && unicode.IsSpace(text[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func prevWordStart(text []rune, pos int, big bool) int {
+	pos = min(pos, len(text))
+	for pos > 0 && unicode.IsSpace(text[pos-1]) {
+		pos--
+	}
+	if pos == 0 {
+		return 0
+	}
+	c := class(text[pos-1], big)
+	for pos > 0 && class(text[pos-1], big) == c {
+		pos--
+	}
+	return pos
+}
+
+func wordEnd(text []rune, pos int, big bool) int {
+	pos++
+	for pos < len(text) && unicode.IsSpace(text[pos]) {
+		pos++
+	}
+	if pos >= len(text) {
+		return max(0, len(text)-1)
+	}
+	c := class(text[pos], big)
+	for pos+1 < len(text) && class(text[pos+1], big) == c {
+		pos++
+	}
+	return pos
+}
+
+// join joins count lines after the one at pos to it, with a space between
+// them like J in vim.
+func join(text []rune, pos, count int) ([]rune, int) {
+	for range count {
+		end := lineEnd(text, pos)
+		if end >= len(text) {
+			break
+		}
+		next := end + 1
+		for next < len(text) && (text[next] == ' ' || text[next] == '\t') {
+			next++
+		}
+		sep := []rune(" ")
+		if next == len(text) || text[next] == '\n' || (end > 0 && text[end-1] == ' ') {
+			sep = nil
+		}
+		text = splice(text, end, next, sep)
+		pos = end
+	}
+	return text, pos
+}
+
+// lines returns the whole lines from the one of start to the one of end,
+// with the line break after them.
+func lines(text []rune, start, end int) (int, int) {
+	start, end = lineStart(text, start), lineEnd(text, max(start, end))
+	if end < len(text) {
+		end++
+	}
+	return start, end
+}
+
+// deleteRange deletes from start to end, and returns where the cursor goes.
+func deleteRange(text []rune, start, end int, linewise bool) ([]rune, int) {
+	if linewise && end == len(text) && start > 0 && (end == start || text[end-1] != '\n') {
+		// Deleting the last lines takes the line break before them.
+		start--
+	}
+	text = splice(text, start, end, nil)
+	if linewise {
+		return text, firstNonBlank(text, min(start, len(text)))
+	}
+	return text, start
+}
+
+func toggleCase(runes []rune) []rune {
+	toggled := make([]rune, len(runes))
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			toggled[i] = unicode.ToLower(r)
+		} else {
+			toggled[i] = unicode.ToUpper(r)
+		}
+	}
+	return toggled
+}
+
+// splice replaces text from start to end with inserted.
+func splice(text []rune, start, end int, inserted []rune) []rune {
+	result := make([]rune, 0, len(text)-(end-start)+len(inserted))
+	result = append(result, text[:start]...)
+	result = append(result, inserted...)
+	return append(result, text[end:]...)
+}
+
+// clampNormal keeps the cursor on a character in the normal mode, where it
+// can't be after the end of a line.
+func clampNormal(text []rune, pos int) int {
+	pos = max(0, min(pos, len(text)))
+	if pos > lineStart(text, pos) && pos == lineEnd(text, pos) {
+		pos--
+	}
+	return pos
+}
+
+func lineStart(text []rune, pos int) int {
+	for pos > 0 && text[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
+
+func lineEnd(text []rune, pos int) int {
+	for pos < len(text) && text[pos] != '\n' {
+		pos++
+	}
+	return pos
+}
+
+func firstNonBlank(text []rune, pos int) int {
+	pos = lineStart(text, pos)
+	for pos < len(text) && (text[pos] == ' ' || text[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+// lineOf returns the line and the column of pos.
+func lineOf(text []rune, pos int) (row, col int) {
+	start := lineStart(text, pos)
+	return strings.Count(string(text[:start]), "\n"), pos - start
+}
+
+// lineOffset returns the offset of col in row, or of the end of the row
+// when it's shorter.
+func lineOffset(text []rune, row, col int) int {
+	pos := 0
+	for ; row > 0 && pos < len(text); pos++ {
+		if text[pos] == '\n' {
+			row--
+		}
+	}
+	return min(pos+col, lineEnd(text, pos))
+}
+
+// vimKey returns the key vim handles for a key press: the text typed, or
+// the name of the key.
+func vimKey(text, name string) string {
+	if text != "" {
+		return text
+	}
+	if name == "space" {
+		return " "
+	}
+	return name
+}
+
+// describe returns the size of the visual selection, like vim shows it.
+func (v *vim) describe(text []rune, pos int) string {
+	start, end := v.selection(text, pos)
+	if lines := strings.Count(string(text[start:end]), "\n"); v.mode == vimVisualLine || lines > 0 {
+		return strconv.Itoa(lines+1) + " lines"
+	}
+	return strconv.Itoa(end-start) + " chars"
+}
+
+// offset returns the offset of the cursor in the runes of the prompt.
+func (s editState) offset() int {
+	offset := 0
+	for i, line := range strings.Split(s.value, "\n") {
+		n := len([]rune(line))
+		if i == s.row {
+			return offset + min(s.col, n)
+		}
+		offset += n + 1
+	}
+	return len([]rune(s.value))
+}
+
+// stateAt returns the state of the editor with text and the cursor at pos.
+func stateAt(text []rune, pos int) editState {
+	row, col := lineOf(text, pos)
+	return editState{value: string(text), row: row, col: col}
+}
+
+// handleVimKey handles the keys of the modes of vim, and reports whether msg
+// was one of them. In the insert mode, only esc is, the rest typing text as
+// usual.
+func (m *editorCmp) handleVimKey(msg tea.KeyPressMsg) (tea.Cmd, bool) {
+	if m.vim == nil {
+		return nil, false
+	}
+	before := m.editState()
+	text := []rune(before.value)
+	if m.vim.mode == vimInsert {
+		if msg.String() != "esc" {
+			return nil, false
+		}
+		m.restore(stateAt(text, m.vim.normal(text, before.offset())))
+		m.undo.group(false)
+		return util.CmdHandler(completions.CloseCompletionsMsg{}), true
+	}
+
+	// The keys of Crush, like ctrl+o for the external editor, still work.
+	name := vimKey(msg.Text, msg.String())
+	if msg.Mod&(tea.ModCtrl|tea.ModAlt) != 0 && name != "ctrl+r" && len(m.vim.keys) == 0 {
+		return nil, false
+	}
+	res := m.vim.key(text, before.offset(), name)
+	switch res.action {
+	case vimSend:
+		return m.send(), true
+	case vimUndo, vimRedo:
+		for i := range res.count {
+			step, what := m.undo.undoFrom, "undo"
+			if res.action == vimRedo {
+				step, what = m.undo.redoFrom, "redo"
+			}
+			state, ok := step(m.editState())
+			if !ok {
+				if i == 0 {
+					return util.ReportInfo("Nothing to " + what), true
+				}
+				break
+			}
+			m.restore(state)
+		}
+		state := m.editState()
+		m.restore(stateAt([]rune(state.value), clampNormal([]rune(state.value), state.offset())))
+		return m.scheduleDraftSave(), true
+	}
+
+	m.restore(stateAt(res.text, res.pos))
+	if m.vim.mode == vimInsert {
+		// The change and the text typed after it are undone at once, like
+		// in vim.
+		m.undo.group(true)
+	}
+	cmds := []tea.Cmd{m.edited(before, nil)}
+	if res.copied != "" {
+		cmds = append(cmds, util.CopyToClipboard(res.copied, "Text"))
+	}
+	return tea.Batch(cmds...), true
+}
+
+// HandlesEscape implements Editor.
+func (m *editorCmp) HandlesEscape() bool {
+	return m.vim != nil && (m.vim.mode != vimNormal || len(m.vim.keys) > 0)
+}
+
+// vimView shows the mode of vim, the size of the visual selection and the
+// keys of the command being typed, empty when vim editing is disabled.
+func (m *editorCmp) vimView() string {
+	if m.vim == nil {
+		return ""
+	}
+	t := styles.CurrentTheme()
+	line := t.S().Muted.Render("-- " + m.vim.mode.String() + " --")
+	if m.vim.mode == vimVisual || m.vim.mode == vimVisualLine {
+		s := m.editState()
+		line += t.S().Subtle.Render(" " + m.vim.describe([]rune(s.value), s.offset()))
+	}
+	if pending := m.vim.pending(); pending != "" {
+		line += t.S().Subtle.Render(" " + pending)
+	}
+	return line
+}
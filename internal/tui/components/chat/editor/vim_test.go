@@ -0,0 +1,202 @@
+package editor
+
+import (
+	"cmp"
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/textarea"
+	tea "charm.land/bubbletea/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// vimKeys splits keys into the keys of vim, with the named ones like <esc>
+// in angle brackets.
+func vimKeys(keys string) []string {
+	var result []string
+	for keys != "" {
+		if strings.HasPrefix(keys, "<") {
+			if end := strings.Index(keys, ">"); end > 1 {
+				result = append(result, keys[1:end])
+				keys = keys[end+1:]
+				continue
+			}
+		}
+		r := []rune(keys)[0]
+		result = append(result, string(r))
+		keys = keys[len(string(r)):]
+	}
+	return result
+}
+
+// runVim types keys on text, where | is the cursor, and returns the text
+// with the cursor after them. The keys of the insert mode are typed like
+// the textarea does.
+func runVim(v *vim, text, keys string) string {
+	pos := strings.Index(text, "|")
+	runes := []rune(strings.Replace(text, "|", "", 1))
+	pos = len([]rune(text[:pos]))
+	for _, k := range vimKeys(keys) {
+		switch {
+		case v.mode == vimInsert && k == "esc":
+			pos = v.normal(runes, pos)
+		case v.mode == vimInsert:
+			runes = splice(runes, pos, pos, []rune(k))
+			pos += len([]rune(k))
+		default:
+			res := v.key(runes, pos, k)
+			runes, pos = res.text, res.pos
+		}
+	}
+	return string(runes[:pos]) + "|" + string(runes[pos:])
+}
+
+func TestVim(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text, keys, want string
+		// mode is the mode after the keys, NORMAL when empty.
+		mode string
+	}{
+		{text: "|one two three", keys: "w", want: "one |two three"},
+		{text: "|one two three", keys: "2w", want: "one two |three"},
+		{text: "one two thre|e", keys: "b", want: "one two |three"},
+		{text: "|one two", keys: "e", want: "on|e two"},
+		{text: "|foo.bar baz", keys: "W", want: "foo.bar |baz"},
+		{text: "|one two", keys: "$", want: "one tw|o"},
+		{text: "  one tw|o", keys: "^", want: "  |one two"},
+		{text: "a|\nbc\nd", keys: "j", want: "a\nb|c\nd"},
+		{text: "a\nbc\n|d", keys: "gg", want: "|a\nbc\nd"},
+		{text: "|a\nbc\nd", keys: "G", want: "a\nbc\n|d"},
+		{text: "|a\nbc\nd", keys: "2G", want: "a\n|bc\nd"},
+		{text: "|a(b)c(d)", keys: "f(", want: "a|(b)c(d)"},
+		{text: "|a(b)c(d)", keys: "f(;", want: "a(b)c|(d)"},
+		{text: "|a(b)c(d)", keys: "t)", want: "a(|b)c(d)"},
+
+		{text: "|one two three", keys: "dw", want: "|two three"},
+		{text: "one |two\nthree", keys: "dw", want: "one| \nthree"},
+		{text: "|one two three", keys: "d2w", want: "|three"},
+		{text: "|one two three", keys: "cwnew<esc>", want: "ne|w two three"},
+		{text: "one |two three", keys: "D", want: "one| "},
+		{text: "|one", keys: "x", want: "|ne"},
+		{text: "on|e", keys: "x", want: "o|n"},
+		{text: "a\n|b\nc", keys: "dd", want: "a\n|c"},
+		{text: "a\nb\n|c", keys: "dd", want: "a\n|b"},
+		{text: "|a\nb\nc", keys: "2dd", want: "|c"},
+		{text: "|a\nb\nc", keys: "dj", want: "|c"},
+		{text: "a\n  |b\nc", keys: "ccx<esc>", want: "a\n  |x\nc"},
+		{text: "call(|one, two)", keys: "di(", want: "call(|)"},
+		{text: "call(one, |two)", keys: "da(", want: "cal|l"},
+		{text: "x = {a: [1, |2]}", keys: "diB", want: "x = {|}"},
+		{text: `say "hel|lo" now`, keys: `ci"bye<esc>`, want: `say "by|e" now`},
+		{text: "one t|wo three", keys: "daw", want: "one |three"},
+		{text: "one t|wo three", keys: "ciwsix<esc>", want: "one si|x three"},
+
+		{text: "|one two", keys: "yiwP", want: "on|eone two"},
+		{text: "|one two", keys: "yiw$p", want: "one twoon|e"},
+		{text: "|a\nb", keys: "yyp", want: "a\n|a\nb"},
+		{text: "a\n|b", keys: "yyP", want: "a\n|b\nb"},
+		{text: "|a\nb", keys: "ddp", want: "b\n|a"},
+		{text: "|abc", keys: "3rx", want: "xx|x"},
+		{text: "|abc", keys: "2~", want: "AB|c"},
+		{text: "|a\n  b", keys: "J", want: "a| b"},
+
+		{text: "|one", keys: "i", want: "|one", mode: "INSERT"},
+		{text: "|one", keys: "a", want: "o|ne", mode: "INSERT"},
+		{text: "o|ne", keys: "A", want: "one|", mode: "INSERT"},
+		{text: "  o|ne", keys: "I", want: "  |one", mode: "INSERT"},
+		{text: "o|ne\ntwo", keys: "o", want: "one\n|\ntwo", mode: "INSERT"},
+		{text: "one\nt|wo", keys: "O", want: "one\n|\ntwo", mode: "INSERT"},
+
+		{text: "|one two three", keys: "vwd", want: "|wo three"},
+		{text: "one t|wo three", keys: "viwy", want: "one |two three"},
+		{text: "one t|wo three", keys: "viwU", want: "one |TWO three"},
+		{text: "a\n|b\nc\nd", keys: "Vjd", want: "a\n|d"},
+		{text: "|one two", keys: "yiwwviwp", want: "one on|e"},
+		{text: "|one two", keys: "vl", want: "o|ne two", mode: "VISUAL"},
+		{text: "|one two", keys: "v<esc>", want: "|one two"},
+
+		{text: "|one", keys: "dz", want: "|one"},
+		{text: "|one", keys: "d<esc>x", want: "|ne"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			t.Parallel()
+
+			v := newVim()
+			v.mode = vimNormal
+			require.Equal(t, tt.want, runVim(v, tt.text, tt.keys))
+			require.Equal(t, cmp.Or(tt.mode, "NORMAL"), v.mode.String())
+		})
+	}
+}
+
+func TestVimRegisters(t *testing.T) {
+	t.Parallel()
+
+	v := newVim()
+	v.mode = vimNormal
+	require.Equal(t, "oneon|e ", runVim(v, "|one two", `"ayiwwdiw"aP`))
+	require.Equal(t, "one", v.registers['a'].text)
+	require.Equal(t, "two", v.registers['1'].text, "deletions go to 1")
+	runVim(v, "|one two", "yiw")
+	require.Equal(t, "one", v.registers['0'].text, "yanks go to 0")
+
+	runVim(v, "one |two", `"Ayiw`)
+	require.Equal(t, "onetwo", v.registers['a'].text, "an uppercase register appends")
+
+	require.Equal(t, "|two", runVim(v, "|one two", `"_dw`))
+	require.Equal(t, "onetwo", v.registers['"'].text, "the black hole register keeps nothing")
+
+	res := v.key([]rune("one"), 0, `"`)
+	res = v.key(res.text, res.pos, "+")
+	res = v.key(res.text, res.pos, "y")
+	res = v.key(res.text, res.pos, "y")
+	require.Equal(t, "one\n", res.copied)
+}
+
+// typeVim types s in the textarea like the editor does in the insert mode.
+func typeVim(m *editorCmp, s string) {
+	for _, r := range s {
+		msg := tea.KeyPressMsg{Code: r, Text: string(r)}
+		if _, ok := m.handleVimKey(msg); ok {
+			continue
+		}
+		before := m.editState()
+		m.textarea, _ = m.textarea.Update(msg)
+		m.edited(before, msg)
+	}
+}
+
+func TestHandleVimKey(t *testing.T) {
+	t.Parallel()
+
+	ta := textarea.New()
+	ta.Focus()
+	m := &editorCmp{textarea: ta, keyMap: DefaultEditorKeyMap(), vim: newVim()}
+	esc := tea.KeyPressMsg{Code: tea.KeyEscape}
+
+	typeVim(m, "hello world")
+	require.True(t, m.HandlesEscape(), "esc in the insert mode is for the editor")
+	_, ok := m.handleVimKey(esc)
+	require.True(t, ok)
+	require.Equal(t, vimNormal, m.vim.mode)
+	require.False(t, m.HandlesEscape(), "esc in the normal mode is left to the chat")
+	require.Equal(t, 10, m.editState().offset(), "the cursor goes back on the last character")
+
+	typeVim(m, "bcwthere")
+	require.Equal(t, vimInsert, m.vim.mode)
+	require.Equal(t, "hello there", m.textarea.Value())
+	m.handleVimKey(esc)
+
+	typeVim(m, "u")
+	require.Equal(t, "hello world", m.textarea.Value(), "u undoes the change with the text typed after it")
+	m.handleVimKey(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+	require.Equal(t, "hello there", m.textarea.Value(), "ctrl+r redoes it")
+
+	typeVim(m, "0dw")
+	require.Equal(t, "there", m.textarea.Value())
+	_, ok = m.handleVimKey(tea.KeyPressMsg{Code: 'o', Mod: tea.ModCtrl})
+	require.False(t, ok, "the keys of crush still work in the normal mode")
+}
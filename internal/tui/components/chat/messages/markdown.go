@@ -0,0 +1,136 @@
+package messages
+
+import (
+	"strings"
+
+	"charm.land/glamour/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/charmbracelet/crush/internal/tui/highlight/cache"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+)
+
+// markdownCache keeps the rendered markdown of a message, so the chat doesn't
+// render all of it again on every frame. Content is rendered block by block:
+// the complete blocks are rendered once, and only the last one, which may
+// still grow while the message streams, is rendered again when it changes.
+type markdownCache struct {
+	theme *styles.Theme
+	width int
+
+	// content and rendered are the last render.
+	content  string
+	rendered string
+
+	// stable and stableRendered are the complete blocks of the content.
+	stable         string
+	stableRendered string
+}
+
+// render returns content rendered as markdown.
+func (c *markdownCache) render(content string, width int) string {
+	t := styles.CurrentTheme()
+	if c.theme != t || c.width != width {
+		*c = markdownCache{theme: t, width: width}
+	}
+	if content == c.content && c.rendered != "" {
+		return c.rendered
+	}
+
+	r := styles.GetMarkdownRenderer(width)
+	if !strings.HasPrefix(content, c.stable) {
+		c.stable, c.stableRendered = "", ""
+	}
+	// Blocks are always rendered one by one, so the message looks the same
+	// whether it streamed in or was rendered at once.
+	for _, split := range blockSplits(content) {
+		if split <= len(c.stable) {
+			continue
+		}
+		c.stableRendered = joinBlocks(c.stableRendered, renderStableBlocks(r, content[len(c.stable):split], width))
+		c.stable = content[:split]
+	}
+
+	c.content = content
+	c.rendered = joinBlocks(c.stableRendered, renderBlocks(r, content[len(c.stable):]))
+	return c.rendered
+}
+
+// renderStableBlocks renders complete markdown blocks, through the shared
+// highlight cache when they hold code, which is what's slow to render.
+func renderStableBlocks(r *glamour.TermRenderer, content string, width int) string {
+	if !strings.Contains(content, "```") && !strings.Contains(content, "~~~") {
+		return renderBlocks(r, content)
+	}
+	key := cache.NewKey(content, "markdown", styles.CurrentTheme().Name, "glamour", nil).Width(width)
+	rendered, _ := cache.Highlight(key, func() (string, error) {
+		return renderBlocks(r, content), nil
+	})
+	return rendered
+}
+
+// renderBlocks renders markdown blocks without the blank lines glamour puts
+// around them, which depend on what comes before and after.
+func renderBlocks(r *glamour.TermRenderer, content string) string {
+	rendered, err := r.Render(content)
+	if err != nil {
+		return content
+	}
+	lines := strings.Split(rendered, "\n")
+	blank := func(line string) bool {
+		return strings.TrimSpace(ansi.Strip(line)) == ""
+	}
+	for len(lines) > 0 && blank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && blank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinBlocks joins rendered blocks with a blank line, as glamour separates
+// them.
+func joinBlocks(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "\n\n" + b
+	}
+}
+
+// blockSplits returns where the complete blocks of content end: right after
+// its blank lines outside code blocks that are followed by a new block. What
+// comes before them can't change as more content streams in. Blank lines
+// followed by indented lines or list items don't split, as they may belong
+// to the block before them.
+func blockSplits(content string) []int {
+	var splits []int
+	pending := -1
+	inFence := false
+	pos := 0
+	for line := range strings.Lines(content) {
+		trimmed := strings.TrimSpace(line)
+		if pending >= 0 && trimmed != "" {
+			if !strings.ContainsAny(line[:1], " \t-*+0123456789") {
+				splits = append(splits, pending)
+			}
+			pending = -1
+		}
+		pos += len(line)
+		if !strings.HasSuffix(line, "\n") {
+			// The last line may still be incomplete.
+			break
+		}
+		switch {
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			inFence = !inFence
+		case trimmed == "" && !inFence:
+			pending = pos
+		}
+	}
+	return splits
+}
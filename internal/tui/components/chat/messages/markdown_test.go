@@ -0,0 +1,79 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/tui/highlight/cache"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockSplits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    []int
+	}{
+		{"empty", "", nil},
+		{"single paragraph", "Hello there", nil},
+		{"complete paragraph", "First\n\nSecond", []int{len("First\n\n")}},
+		{"next block not started", "First\n\n", nil},
+		{"blank line in a code block", "Intro\n\n```go\nfoo()\n\nbar()\n", []int{len("Intro\n\n")}},
+		{"after a code block", "```\ncode\n```\n\nAfter", []int{len("```\ncode\n```\n\n")}},
+		{"list items", "- one\n\n- two", nil},
+		{"after a list", "# List\n\n1. one\n\n2. two\n\nDone", []int{len("# List\n\n1. one\n\n2. two\n\n")}},
+		{"indented continuation", "- one\n\n  more", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, blockSplits(tt.content))
+		})
+	}
+}
+
+func TestMarkdownCache(t *testing.T) {
+	t.Parallel()
+
+	content := "# Title\n\nFirst paragraph.\n\n- one\n- two\n\n```go\nx := 1\n\ny := 2\n```\n\nLast"
+
+	var streamed markdownCache
+	for i := range content {
+		streamed.render(content[:i+1], 40)
+	}
+	require.Equal(t, "# Title\n\nFirst paragraph.\n\n- one\n- two\n\n```go\nx := 1\n\ny := 2\n```\n\n", streamed.stable)
+
+	var once markdownCache
+	require.Equal(t, once.render(content, 40), streamed.render(content, 40), "streaming renders the same as rendering at once")
+
+	plain := ansi.Strip(once.rendered)
+	require.Contains(t, plain, "Title")
+	require.Contains(t, plain, "y := 2")
+	require.NotContains(t, plain, "```")
+
+	wide := once.rendered
+	require.NotEqual(t, wide, once.render(content, 20), "width changes render again")
+}
+
+func TestMentionChips(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "See `@main.go` and `@https://example.com`, not me@example.com or `@code`.",
+		mentionChips("See @main.go and @https://example.com, not me@example.com or `@code`."))
+}
+
+func TestMarkdownCodeBlocksCached(t *testing.T) {
+	t.Parallel()
+
+	var c markdownCache
+	c.render("Intro\n\n```go\nx := 1\n```\n\nLast", 40)
+
+	key := cache.NewKey("```go\nx := 1\n```\n\n", "markdown", styles.CurrentTheme().Name, "glamour", nil)
+	_, ok := cache.Get(key.Width(40))
+	require.True(t, ok, "complete code blocks are kept in the shared cache")
+	_, ok = cache.Get(key.Width(80))
+	require.False(t, ok)
+}
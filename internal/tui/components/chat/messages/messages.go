@@ -15,12 +15,15 @@ import (
 	"github.com/charmbracelet/x/exp/ordered"
 	"github.com/google/uuid"
 
-	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/crush/internal/a11y"
 	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/mention"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/tui/components/anim"
 	"github.com/charmbracelet/crush/internal/tui/components/core"
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/codeblocks"
 	"github.com/charmbracelet/crush/internal/tui/exp/list"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
@@ -29,6 +32,18 @@ import (
 // CopyKey is the key binding for copying message content to the clipboard.
 var CopyKey = key.NewBinding(key.WithKeys("c", "y", "C", "Y"), key.WithHelp("c/y", "copy"))
 
+// CodeBlocksKey is the key binding for copying, saving or running the code
+// blocks of a message.
+var CodeBlocksKey = key.NewBinding(key.WithKeys("x", "X"), key.WithHelp("x", "code blocks"))
+
+// ToggleToolKey is the key binding for collapsing and expanding a tool call.
+var ToggleToolKey = key.NewBinding(key.WithKeys("enter", "o"), key.WithHelp("enter", "collapse/expand"))
+
+// InspectToolKey is the key binding for inspecting the full arguments and
+// output of a tool call. I works in the vim mode too, where i goes to the
+// editor.
+var InspectToolKey = key.NewBinding(key.WithKeys("i", "I"), key.WithHelp("i", "inspect tool"))
+
 // ClearSelectionKey is the key binding for clearing the current selection in the chat interface.
 var ClearSelectionKey = key.NewBinding(key.WithKeys("esc", "alt+esc"), key.WithHelp("esc", "clear selection"))
 
@@ -58,6 +73,8 @@ type messageCmp struct {
 
 	// Thinking viewport for displaying reasoning content
 	thinkingViewport viewport.Model
+
+	markdown markdownCache // Rendered content, rendered again only when it changes
 }
 
 var focusedMessageBorder = lipgloss.Border{
@@ -105,14 +122,16 @@ func (m *messageCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		}
 	case tea.KeyPressMsg:
 		if key.Matches(msg, CopyKey) {
-			return m, tea.Sequence(
-				tea.SetClipboard(m.message.Content().Text),
-				func() tea.Msg {
-					_ = clipboard.WriteAll(m.message.Content().Text)
-					return nil
-				},
-				util.ReportInfo("Message copied to clipboard"),
-			)
+			return m, util.CopyToClipboard(m.message.Content().Text, "Message")
+		}
+		if key.Matches(msg, CodeBlocksKey) {
+			blocks := codeblocks.Parse(m.message.Content().Text)
+			if len(blocks) == 0 {
+				return m, util.ReportInfo("No code block in the message")
+			}
+			return m, util.CmdHandler(dialogs.OpenDialogMsg{
+				Model: codeblocks.NewCodeBlocksDialogCmp(config.Get().WorkingDir(), blocks),
+			})
 		}
 	}
 	return m, nil
@@ -212,20 +231,37 @@ func (m *messageCmp) renderAssistantMessage() string {
 		if thinkingContent != "" {
 			parts = append(parts, "")
 		}
-		parts = append(parts, m.toMarkdown(content))
+		parts = append(parts, m.markdown.render(content, m.textWidth()))
 	}
 
+	parts = append(append(speaker("Crush"), m.pin()...), parts...)
 	joined := lipgloss.JoinVertical(lipgloss.Left, parts...)
 	return m.style().Render(joined)
 }
 
+// speaker returns the label of who sent a message, which is only shown in
+// the screen reader mode, where the border colors don't tell them apart.
+func speaker(name string) []string {
+	if !a11y.Enabled() {
+		return nil
+	}
+	return []string{styles.CurrentTheme().S().Base.Bold(true).Render(name + ":")}
+}
+
+// pin returns the label of a pinned message.
+func (m *messageCmp) pin() []string {
+	if !m.message.Pinned {
+		return nil
+	}
+	t := styles.CurrentTheme()
+	return []string{t.S().Base.Foreground(t.Warning).Render(styles.PinIcon + " Pinned")}
+}
+
 // renderUserMessage renders user messages with file attachments. It displays
 // message content and any attached files with appropriate icons.
 func (m *messageCmp) renderUserMessage() string {
 	t := styles.CurrentTheme()
-	parts := []string{
-		m.toMarkdown(m.message.Content().String()),
-	}
+	parts := append(append(speaker("You"), m.pin()...), m.markdown.render(mentionChips(m.message.Content().String()), m.textWidth()))
 
 	attachmentStyle := t.S().Base.
 		Padding(0, 1).
@@ -263,6 +299,20 @@ func (m *messageCmp) renderUserMessage() string {
 	return m.style().Render(joined)
 }
 
+// mentionChips puts the @-mentions of content in code spans, so they stand
+// out from the text around them.
+func mentionChips(content string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range mention.Parse(content) {
+		b.WriteString(content[last:m.Start])
+		b.WriteString("`" + content[m.Start:m.End] + "`")
+		last = m.End
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
 // toMarkdown converts text content to rendered markdown using the configured renderer
 func (m *messageCmp) toMarkdown(content string) string {
 	r := styles.GetMarkdownRenderer(m.textWidth())
@@ -390,6 +440,7 @@ func (m *messageCmp) Spinning() bool {
 type AssistantSection interface {
 	list.Item
 	layout.Sizeable
+	MessageID() string // ID of the message the section closes
 }
 type assistantSectionModel struct {
 	width               int
@@ -403,6 +454,11 @@ func (m *assistantSectionModel) ID() string {
 	return m.id
 }
 
+// MessageID implements AssistantSection.
+func (m *assistantSectionModel) MessageID() string {
+	return m.message.ID
+}
+
 func NewAssistantSection(message message.Message, lastUserMessageTime time.Time) AssistantSection {
 	return &assistantSectionModel{
 		width:               0,
@@ -4,6 +4,8 @@ import (
 	"cmp"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/agent/tools"
 	"github.com/charmbracelet/crush/internal/ansiext"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/tui/components/chat/todos"
 	"github.com/charmbracelet/crush/internal/tui/components/core"
@@ -166,6 +169,9 @@ func (br baseRenderer) makeHeader(v *toolCallCmp, tool string, width int, params
 		icon = t.S().Muted.Render(styles.ToolPending)
 	}
 	tool = t.S().Base.Foreground(t.Blue).Render(tool)
+	if v.pinned {
+		tool = t.S().Base.Foreground(t.Warning).Render(styles.PinIcon) + " " + tool
+	}
 	prefix := fmt.Sprintf("%s %s ", icon, tool)
 	return prefix + renderParamList(false, width-lipgloss.Width(prefix), params...)
 }
@@ -184,10 +190,13 @@ func init() {
 	registry.register(tools.BashToolName, func() renderer { return bashRenderer{} })
 	registry.register(tools.JobOutputToolName, func() renderer { return bashOutputRenderer{} })
 	registry.register(tools.JobKillToolName, func() renderer { return bashKillRenderer{} })
+	registry.register(tools.JobInputToolName, func() renderer { return bashInputRenderer{} })
 	registry.register(tools.DownloadToolName, func() renderer { return downloadRenderer{} })
 	registry.register(tools.ViewToolName, func() renderer { return viewRenderer{} })
 	registry.register(tools.EditToolName, func() renderer { return editRenderer{} })
 	registry.register(tools.MultiEditToolName, func() renderer { return multiEditRenderer{} })
+	registry.register(tools.PatchToolName, func() renderer { return patchRenderer{} })
+	registry.register(tools.ReplaceToolName, func() renderer { return replaceRenderer{} })
 	registry.register(tools.WriteToolName, func() renderer { return writeRenderer{} })
 	registry.register(tools.FetchToolName, func() renderer { return simpleFetchRenderer{} })
 	registry.register(tools.AgenticFetchToolName, func() renderer { return agenticFetchRenderer{} })
@@ -197,8 +206,12 @@ func init() {
 	registry.register(tools.GrepToolName, func() renderer { return grepRenderer{} })
 	registry.register(tools.LSToolName, func() renderer { return lsRenderer{} })
 	registry.register(tools.SourcegraphToolName, func() renderer { return sourcegraphRenderer{} })
+	registry.register(tools.SymbolsToolName, func() renderer { return symbolsRenderer{} })
+	registry.register(tools.ToolOutputToolName, func() renderer { return toolOutputRenderer{} })
+	registry.register(tools.SemanticSearchToolName, func() renderer { return semanticSearchRenderer{} })
 	registry.register(tools.DiagnosticsToolName, func() renderer { return diagnosticsRenderer{} })
 	registry.register(tools.TodosToolName, func() renderer { return todosRenderer{} })
+	registry.register(tools.PlanToolName, func() renderer { return planRenderer{} })
 	registry.register(agent.AgentToolName, func() renderer { return agentRenderer{} })
 }
 
@@ -414,6 +427,57 @@ func (bkr bashKillRenderer) Render(v *toolCallCmp) string {
 	return joinHeaderBody(header, body)
 }
 
+// -----------------------------------------------------------------------------
+//  Bash Input renderer
+// -----------------------------------------------------------------------------
+
+// bashInputRenderer handles interactive background shells started and the
+// input sent to them
+type bashInputRenderer struct {
+	baseRenderer
+}
+
+// Render displays the input sent and the output it got
+func (bir bashInputRenderer) Render(v *toolCallCmp) string {
+	var params tools.JobInputParams
+	if err := bir.unmarshalParams(v.call.Input, &params); err != nil {
+		return bir.renderError(v, "Invalid job_input parameters")
+	}
+
+	var meta tools.JobInputResponseMetadata
+	var description string
+	if v.result.Metadata != "" {
+		if err := bir.unmarshalParams(v.result.Metadata, &meta); err == nil {
+			if meta.Description != "" {
+				description = meta.Description
+			} else {
+				description = meta.Command
+			}
+		}
+	}
+
+	width := v.textWidth()
+	if v.isNested {
+		width -= 4 // Adjust for nested tool call indentation
+	}
+	action, input := "Input", "> "+strings.TrimSuffix(params.Input, "\n")
+	if params.ShellID == "" {
+		action, input = "Start", "$ "+params.Command
+	}
+	header := makeJobHeader(v, action, fmt.Sprintf("PID %s", cmp.Or(params.ShellID, meta.ShellID)), description, width)
+	if v.isNested {
+		return v.style().Render(header)
+	}
+	if res, done := earlyState(header, v); done {
+		return res
+	}
+	if params.CloseInput {
+		input += "\n^D"
+	}
+	body := renderPlainContent(v, input+"\n\n"+v.result.Content)
+	return joinHeaderBody(header, body)
+}
+
 // -----------------------------------------------------------------------------
 //  View renderer
 // -----------------------------------------------------------------------------
@@ -567,6 +631,110 @@ func (mer multiEditRenderer) Render(v *toolCallCmp) string {
 	})
 }
 
+// -----------------------------------------------------------------------------
+//  Patch renderer
+// -----------------------------------------------------------------------------
+
+// patchRenderer handles patches with diff visualization
+type patchRenderer struct {
+	baseRenderer
+}
+
+// Render displays the patched file with a formatted diff of changes, noting
+// dry runs and the hunks that matched fuzzily
+func (pr patchRenderer) Render(v *toolCallCmp) string {
+	t := styles.CurrentTheme()
+	var params tools.PatchParams
+	var args []string
+	if err := pr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(fsext.PrettyPath(params.FilePath)).
+			addFlag("dry run", params.DryRun).
+			build()
+	}
+
+	return pr.renderWithParams(v, "Patch", args, func() string {
+		var meta tools.PatchResponseMetadata
+		if err := pr.unmarshalParams(v.result.Metadata, &meta); err != nil {
+			return renderPlainContent(v, v.result.Content)
+		}
+
+		formatter := core.DiffFormatter().
+			Before(fsext.PrettyPath(params.FilePath), meta.OldContent).
+			After(fsext.PrettyPath(params.FilePath), meta.NewContent).
+			Width(v.textWidth() - 2) // -2 for padding
+		if v.textWidth() > 120 {
+			formatter = formatter.Split()
+		}
+		// add a message to the bottom if the content was truncated
+		formatted := formatter.String()
+		if lipgloss.Height(formatted) > responseContextHeight {
+			contentLines := strings.Split(formatted, "\n")
+			truncateMessage := t.S().Muted.
+				Background(t.BgBaseLighter).
+				PaddingLeft(2).
+				Width(v.textWidth() - 2).
+				Render(fmt.Sprintf("… (%d lines)", len(contentLines)-responseContextHeight))
+			formatted = strings.Join(contentLines[:responseContextHeight], "\n") + "\n" + truncateMessage
+		}
+
+		fuzzy := 0
+		for _, m := range meta.Hunks {
+			if !m.Exact() {
+				fuzzy++
+			}
+		}
+		var notes []string
+		if meta.DryRun {
+			notes = append(notes, "Dry run, the file wasn't changed")
+		}
+		if fuzzy > 0 {
+			notes = append(notes, fmt.Sprintf("%d of %d hunks matched fuzzily", fuzzy, len(meta.Hunks)))
+		}
+		if len(notes) > 0 {
+			noteTag := t.S().Base.Padding(0, 2).Background(t.Info).Foreground(t.White).Render("Note")
+			note := t.S().Base.
+				Width(v.textWidth() - 2).
+				Render(fmt.Sprintf("%s %s", noteTag, t.S().Muted.Render(strings.Join(notes, ", "))))
+			formatted = lipgloss.JoinVertical(lipgloss.Left, formatted, "", note)
+		}
+		return formatted
+	})
+}
+
+// -----------------------------------------------------------------------------
+//  Replace renderer
+// -----------------------------------------------------------------------------
+
+// replaceRenderer handles project-wide replacements with the changed lines
+type replaceRenderer struct {
+	baseRenderer
+}
+
+// Render displays the pattern and replacement with the optional filters
+func (rr replaceRenderer) Render(v *toolCallCmp) string {
+	var params tools.ReplaceParams
+	var args []string
+	if err := rr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(fmt.Sprintf("%s → %s", params.Pattern, params.Replacement)).
+			addKeyValue("include", strings.Join(params.Include, ",")).
+			addKeyValue("exclude", strings.Join(params.Exclude, ",")).
+			addKeyValue("path", params.Path).
+			addFlag("regex", params.Regex).
+			addFlag("dry_run", params.DryRun).
+			build()
+	}
+
+	return rr.renderWithParams(v, "Replace", args, func() string {
+		content := strings.TrimPrefix(v.result.Content, "<result>\n")
+		if i := strings.Index(content, "</result>"); i >= 0 {
+			content = content[:i]
+		}
+		return renderPlainContent(v, content)
+	})
+}
+
 // -----------------------------------------------------------------------------
 //  Write renderer
 // -----------------------------------------------------------------------------
@@ -745,21 +913,79 @@ type webSearchRenderer struct {
 	baseRenderer
 }
 
-// Render displays a compact view of web_search with just the query
+// Render displays the query and the sources found, one per line
 func (wsr webSearchRenderer) Render(v *toolCallCmp) string {
 	var params tools.WebSearchParams
+	var meta tools.WebSearchResponseMetadata
+	if v.result.Metadata != "" {
+		_ = wsr.unmarshalParams(v.result.Metadata, &meta)
+	}
 	var args []string
 	if err := wsr.unmarshalParams(v.call.Input, &params); err == nil {
-		args = newParamBuilder().
-			addMain(params.Query).
-			build()
+		builder := newParamBuilder().addMain(params.Query)
+		if meta.Provider != "" {
+			builder = builder.
+				addKeyValue("engine", meta.Provider).
+				addKeyValue("sources", strconv.Itoa(len(meta.Results)))
+		}
+		args = builder.build()
 	}
 
 	return wsr.renderWithParams(v, "Search", args, func() string {
-		return renderMarkdownContent(v, v.result.Content)
+		if meta.Provider == "" {
+			return renderMarkdownContent(v, v.result.Content)
+		}
+		return renderSources(v, meta)
 	})
 }
 
+// renderSources renders the results of a web search as a list of sources,
+// their title and domain.
+func renderSources(v *toolCallCmp, meta tools.WebSearchResponseMetadata) string {
+	t := styles.CurrentTheme()
+	if len(meta.Results) == 0 {
+		return renderPlainContent(v, v.result.Content)
+	}
+
+	width := v.textWidth() - 2
+	var out []string
+	for i, result := range meta.Results {
+		if i >= responseContextHeight {
+			break
+		}
+		domain := result.Link
+		if u, err := url.Parse(result.Link); err == nil && u.Host != "" {
+			domain = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+		title := ansiext.Escape(cmp.Or(result.Title, result.Link))
+		ln := t.S().Text.Background(t.BgBaseLighter).Render(fmt.Sprintf(" %d. %s ", result.Position, title)) +
+			t.S().Subtle.Background(t.BgBaseLighter).Render(domain)
+		if lipgloss.Width(ln) > width {
+			ln = v.fit(ln, width)
+		}
+		out = append(out, t.S().Muted.
+			Width(width).
+			Background(t.BgBaseLighter).
+			Render(ln))
+	}
+
+	var notes []string
+	if len(meta.Results) > responseContextHeight {
+		notes = append(notes, fmt.Sprintf("… (%d more)", len(meta.Results)-responseContextHeight))
+	}
+	if meta.Filtered > 0 {
+		notes = append(notes, fmt.Sprintf("%d filtered by domain rules", meta.Filtered))
+	}
+	if len(notes) > 0 {
+		out = append(out, t.S().Muted.
+			Background(t.BgBaseLighter).
+			Width(width).
+			Render(strings.Join(notes, ", ")))
+	}
+
+	return strings.Join(out, "\n")
+}
+
 // -----------------------------------------------------------------------------
 //  Download renderer
 // -----------------------------------------------------------------------------
@@ -811,6 +1037,83 @@ func (gr globRenderer) Render(v *toolCallCmp) string {
 	})
 }
 
+// -----------------------------------------------------------------------------
+//  Symbols renderer
+// -----------------------------------------------------------------------------
+
+// symbolsRenderer handles symbol lookups
+type symbolsRenderer struct {
+	baseRenderer
+}
+
+// Render displays the symbol query with optional kind and path parameters
+func (sr symbolsRenderer) Render(v *toolCallCmp) string {
+	var params tools.SymbolsParams
+	var args []string
+	if err := sr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(params.Query).
+			addKeyValue("kind", params.Kind).
+			addKeyValue("path", params.Path).
+			build()
+	}
+
+	return sr.renderWithParams(v, "Symbols", args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
+// -----------------------------------------------------------------------------
+//  Tool output renderer
+// -----------------------------------------------------------------------------
+
+// toolOutputRenderer handles reads of truncated tool results
+type toolOutputRenderer struct {
+	baseRenderer
+}
+
+// Render displays the tool call read with the optional offset and limit
+func (tr toolOutputRenderer) Render(v *toolCallCmp) string {
+	var params tools.ToolOutputParams
+	var args []string
+	if err := tr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(params.ToolCallID).
+			addKeyValue("offset", formatNonZero(params.Offset)).
+			addKeyValue("limit", formatNonZero(params.Limit)).
+			build()
+	}
+
+	return tr.renderWithParams(v, prettifyToolName(tools.ToolOutputToolName), args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
+// -----------------------------------------------------------------------------
+//  Semantic search renderer
+// -----------------------------------------------------------------------------
+
+// semanticSearchRenderer handles searches of the embedding index
+type semanticSearchRenderer struct {
+	baseRenderer
+}
+
+// Render displays the query with the optional limit parameter
+func (sr semanticSearchRenderer) Render(v *toolCallCmp) string {
+	var params tools.SemanticSearchParams
+	var args []string
+	if err := sr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(params.Query).
+			addKeyValue("limit", formatNonZero(params.Limit)).
+			build()
+	}
+
+	return sr.renderWithParams(v, "Semantic Search", args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
 // -----------------------------------------------------------------------------
 //  Grep renderer
 // -----------------------------------------------------------------------------
@@ -945,11 +1248,18 @@ func (tr agentRenderer) Render(v *toolCallCmp) string {
 	prompt := params.Prompt
 	prompt = strings.ReplaceAll(prompt, "\n", " ")
 
-	header := tr.makeHeader(v, "Agent", v.textWidth())
+	// Show the delegation chain when a named sub-agent handles the task.
+	var chain []string
+	tag := "Task"
+	if params.Agent != "" {
+		chain = append(chain, config.AgentCoder+" → "+params.Agent)
+		tag = params.Agent
+	}
+	header := tr.makeHeader(v, "Agent", v.textWidth(), chain...)
 	if res, done := earlyState(header, v); v.cancelled && done {
 		return res
 	}
-	taskTag := t.S().Base.Bold(true).Padding(0, 1).MarginLeft(2).Background(t.BlueLight).Foreground(t.White).Render("Task")
+	taskTag := t.S().Base.Bold(true).Padding(0, 1).MarginLeft(2).Background(t.BlueLight).Foreground(t.White).Render(tag)
 	remainingWidth := v.textWidth() - lipgloss.Width(header) - lipgloss.Width(taskTag) - 2
 	remainingWidth = min(remainingWidth, 120-lipgloss.Width(taskTag)-2)
 	prompt = t.S().Muted.Width(remainingWidth).Render(prompt)
@@ -1278,12 +1588,18 @@ func prettifyToolName(name string) string {
 		return "Job: Output"
 	case tools.JobKillToolName:
 		return "Job: Kill"
+	case tools.JobInputToolName:
+		return "Job: Input"
 	case tools.DownloadToolName:
 		return "Download"
 	case tools.EditToolName:
 		return "Edit"
 	case tools.MultiEditToolName:
 		return "Multi-Edit"
+	case tools.PatchToolName:
+		return "Patch"
+	case tools.ReplaceToolName:
+		return "Replace"
 	case tools.FetchToolName:
 		return "Fetch"
 	case tools.AgenticFetchToolName:
@@ -1300,8 +1616,16 @@ func prettifyToolName(name string) string {
 		return "List"
 	case tools.SourcegraphToolName:
 		return "Sourcegraph"
+	case tools.SymbolsToolName:
+		return "Symbols"
+	case tools.SemanticSearchToolName:
+		return "Semantic Search"
 	case tools.TodosToolName:
 		return "To-Do"
+	case tools.PlanToolName:
+		return "Plan"
+	case tools.ToolOutputToolName:
+		return "Tool Output"
 	case tools.ViewToolName:
 		return "View"
 	case tools.WriteToolName:
@@ -1401,3 +1725,44 @@ func (tr todosRenderer) Render(v *toolCallCmp) string {
 		return body
 	})
 }
+
+// -----------------------------------------------------------------------------
+//  Plan renderer
+// -----------------------------------------------------------------------------
+
+type planRenderer struct {
+	baseRenderer
+}
+
+// Render displays the proposed plan and whether the user approved it.
+func (pr planRenderer) Render(v *toolCallCmp) string {
+	t := styles.CurrentTheme()
+	var params tools.PlanParams
+	pr.unmarshalParams(v.call.Input, &params)
+
+	steps := params.Steps
+	headerText := fmt.Sprintf("%d steps", len(steps))
+	if v.result.ToolCallID == "" {
+		headerText += " · awaiting review"
+	}
+
+	var meta tools.PlanResponseMetadata
+	if v.result.Metadata != "" && pr.unmarshalParams(v.result.Metadata, &meta) == nil {
+		steps = meta.Steps
+		status := "rejected"
+		if meta.Approved {
+			status = "approved"
+		}
+		headerText = fmt.Sprintf("%d steps · %s", len(steps), status)
+	}
+
+	args := newParamBuilder().addMain(headerText).build()
+	return pr.renderWithParams(v, "Plan", args, func() string {
+		lines := make([]string, len(steps))
+		for i, step := range steps {
+			number := t.S().Subtle.Render(fmt.Sprintf("%d.", i+1))
+			lines[i] = ansi.Truncate(number+" "+t.S().Base.Foreground(t.FgBase).Render(step), v.textWidth(), "…")
+		}
+		return strings.Join(lines, "\n")
+	})
+}
@@ -10,15 +10,17 @@ import (
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/diff"
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/tui/components/anim"
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/toolinspect"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
 	"github.com/charmbracelet/x/ansi"
@@ -43,6 +45,8 @@ type ToolCallCmp interface {
 	ID() string
 	SetPermissionRequested() // Mark permission request
 	SetPermissionGranted()   // Mark permission granted
+	Pinned() bool            // Whether the parent message is pinned
+	SetPinned(bool)          // Mark the parent message as pinned
 }
 
 // toolCallCmp implements the ToolCallCmp interface for displaying tool calls.
@@ -59,6 +63,8 @@ type toolCallCmp struct {
 	cancelled           bool               // Whether the tool call was cancelled
 	permissionRequested bool
 	permissionGranted   bool
+	collapsed           bool // Whether only the header is shown
+	pinned              bool // Whether the parent message is pinned
 
 	// Animation state for pending tool calls
 	spinning bool       // Whether to show loading animation
@@ -96,6 +102,13 @@ func WithToolCallNestedCalls(calls []ToolCallCmp) ToolCallOption {
 	}
 }
 
+// WithToolCallPinned marks the parent message as pinned
+func WithToolCallPinned() ToolCallOption {
+	return func(m *toolCallCmp) {
+		m.pinned = true
+	}
+}
+
 func WithToolPermissionRequested() ToolCallOption {
 	return func(m *toolCallCmp) {
 		m.permissionRequested = true
@@ -114,6 +127,7 @@ func NewToolCallCmp(parentMessageID string, tc message.ToolCall, permissions per
 	m := &toolCallCmp{
 		call:            tc,
 		parentMessageID: parentMessageID,
+		collapsed:       config.Get().Options.TUI.CollapseToolCalls,
 	}
 	for _, opt := range opts {
 		opt(m)
@@ -165,8 +179,15 @@ func (m *toolCallCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		}
 		return m, tea.Batch(cmds...)
 	case tea.KeyPressMsg:
-		if key.Matches(msg, CopyKey) {
+		switch {
+		case key.Matches(msg, CopyKey):
 			return m, m.copyTool()
+		case key.Matches(msg, ToggleToolKey):
+			m.collapsed = !m.collapsed
+		case key.Matches(msg, InspectToolKey):
+			return m, util.CmdHandler(dialogs.OpenDialogMsg{
+				Model: toolinspect.NewToolInspectDialogCmp(m.call, m.result, tools.NewToolResults(config.Get().Options.DataDirectory)),
+			})
 		}
 	}
 	return m, nil
@@ -186,9 +207,24 @@ func (m *toolCallCmp) View() string {
 	if m.isNested {
 		return box.Render(r.Render(m))
 	}
+	if m.collapsed {
+		return box.Render(m.collapse(r.Render(m)))
+	}
 	return box.Render(r.Render(m))
 }
 
+// collapse keeps the header of the rendered tool call, noting how many
+// lines are hidden.
+func (m *toolCallCmp) collapse(rendered string) string {
+	header, body, ok := strings.Cut(rendered, "\n")
+	if !ok {
+		return header
+	}
+	t := styles.CurrentTheme()
+	hint := t.S().Subtle.Render(fmt.Sprintf(" +%d lines", lipgloss.Height(body)))
+	return ansi.Truncate(header, m.textWidth()-lipgloss.Width(hint), "…") + hint
+}
+
 // State management methods
 
 // SetCancelled marks the tool call as cancelled
@@ -198,14 +234,7 @@ func (m *toolCallCmp) SetCancelled() {
 
 func (m *toolCallCmp) copyTool() tea.Cmd {
 	content := m.formatToolForCopy()
-	return tea.Sequence(
-		tea.SetClipboard(content),
-		func() tea.Msg {
-			_ = clipboard.WriteAll(content)
-			return nil
-		},
-		util.ReportInfo("Tool content copied to clipboard"),
-	)
+	return util.CopyToClipboard(content, "Tool content")
 }
 
 func (m *toolCallCmp) formatToolForCopy() string {
@@ -266,7 +295,8 @@ func (m *toolCallCmp) formatParametersForCopy() string {
 			}
 			return strings.Join(parts, "\n")
 		}
-	case tools.EditToolName:
+	case tools.EditToolName, tools.PatchToolName:
+		// Both take the file_path.
 		var params tools.EditParams
 		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
 			return fmt.Sprintf("**File:** %s", fsext.PrettyPath(params.FilePath))
@@ -279,6 +309,20 @@ func (m *toolCallCmp) formatParametersForCopy() string {
 			parts = append(parts, fmt.Sprintf("**Edits:** %d", len(params.Edits)))
 			return strings.Join(parts, "\n")
 		}
+	case tools.ReplaceToolName:
+		var params tools.ReplaceParams
+		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			var parts []string
+			parts = append(parts, fmt.Sprintf("**Pattern:** %s", params.Pattern))
+			parts = append(parts, fmt.Sprintf("**Replacement:** %s", params.Replacement))
+			if len(params.Include) > 0 {
+				parts = append(parts, fmt.Sprintf("**Include:** %s", strings.Join(params.Include, ", ")))
+			}
+			if len(params.Exclude) > 0 {
+				parts = append(parts, fmt.Sprintf("**Exclude:** %s", strings.Join(params.Exclude, ", ")))
+			}
+			return strings.Join(parts, "\n")
+		}
 	case tools.WriteToolName:
 		var params tools.WriteParams
 		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
@@ -340,6 +384,24 @@ func (m *toolCallCmp) formatParametersForCopy() string {
 			}
 			return strings.Join(parts, "\n")
 		}
+	case tools.SemanticSearchToolName:
+		var params tools.SemanticSearchParams
+		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			return fmt.Sprintf("**Query:** %s", params.Query)
+		}
+	case tools.SymbolsToolName:
+		var params tools.SymbolsParams
+		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			var parts []string
+			parts = append(parts, fmt.Sprintf("**Query:** %s", params.Query))
+			if params.Kind != "" {
+				parts = append(parts, fmt.Sprintf("**Kind:** %s", params.Kind))
+			}
+			if params.Path != "" {
+				parts = append(parts, fmt.Sprintf("**Path:** %s", params.Path))
+			}
+			return strings.Join(parts, "\n")
+		}
 	case tools.LSToolName:
 		var params tools.LSParams
 		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
@@ -378,6 +440,9 @@ func (m *toolCallCmp) formatParametersForCopy() string {
 	case agent.AgentToolName:
 		var params agent.AgentParams
 		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			if params.Agent != "" {
+				return fmt.Sprintf("**Agent:** %s\n**Task:**\n%s", params.Agent, params.Prompt)
+			}
 			return fmt.Sprintf("**Task:**\n%s", params.Prompt)
 		}
 	}
@@ -411,7 +476,8 @@ func (m *toolCallCmp) formatResultForCopy() string {
 		return m.formatBashResultForCopy()
 	case tools.ViewToolName:
 		return m.formatViewResultForCopy()
-	case tools.EditToolName:
+	case tools.EditToolName, tools.PatchToolName:
+		// Both return the old and new content.
 		return m.formatEditResultForCopy()
 	case tools.MultiEditToolName:
 		return m.formatMultiEditResultForCopy()
@@ -425,7 +491,7 @@ func (m *toolCallCmp) formatResultForCopy() string {
 		return m.formatWebFetchResultForCopy()
 	case agent.AgentToolName:
 		return m.formatAgentResultForCopy()
-	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName, tools.TodosToolName:
+	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.SymbolsToolName, tools.SemanticSearchToolName, tools.ReplaceToolName, tools.DiagnosticsToolName, tools.TodosToolName, tools.PlanToolName:
 		return fmt.Sprintf("```\n%s\n```", m.result.Content)
 	default:
 		return m.result.Content
@@ -719,6 +785,16 @@ func (m *toolCallCmp) SetToolCall(call message.ToolCall) {
 	}
 }
 
+// Pinned reports whether the message that initiated this tool call is pinned
+func (m *toolCallCmp) Pinned() bool {
+	return m.pinned
+}
+
+// SetPinned marks the message that initiated this tool call as pinned
+func (m *toolCallCmp) SetPinned(pinned bool) {
+	m.pinned = pinned
+}
+
 // ParentMessageID returns the ID of the message that initiated this tool call
 func (m *toolCallCmp) ParentMessageID() string {
 	return m.parentMessageID
@@ -0,0 +1,132 @@
+package chat
+
+import (
+	"context"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/chat/messages"
+	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+// Key bindings of the pinned messages of the chat.
+var (
+	PinKey     = key.NewBinding(key.WithKeys("p", "P"), key.WithHelp("p", "pin/unpin"))
+	NextPinKey = key.NewBinding(key.WithKeys("]"), key.WithHelp("]/[", "next/prev pin"))
+	PrevPinKey = key.NewBinding(key.WithKeys("["), key.WithHelp("]/[", "next/prev pin"))
+)
+
+// pinnedMsg reports that a message was pinned or unpinned.
+type pinnedMsg struct {
+	message message.Message
+}
+
+// itemPin returns the ID of the message an item shows, a tool call being
+// part of the message that called it, and whether it's pinned.
+func itemPin(item list.Item) (string, bool) {
+	switch item := item.(type) {
+	case messages.MessageCmp:
+		msg := item.GetMessage()
+		return msg.ID, msg.Pinned
+	case messages.ToolCallCmp:
+		return item.ParentMessageID(), item.Pinned()
+	}
+	return "", false
+}
+
+// togglePin pins the message of the selected item, or unpins it.
+func (m *messageListCmp) togglePin() tea.Cmd {
+	item := m.listCmp.SelectedItem()
+	if item == nil {
+		return nil
+	}
+	id, pinned := itemPin(*item)
+	if id == "" {
+		return util.ReportInfo("Only messages and tool calls can be pinned")
+	}
+	messages := m.app.Messages
+	return func() tea.Msg {
+		msg, err := messages.SetPinned(context.Background(), id, !pinned)
+		if err != nil {
+			return util.InfoMsg{
+				Type: util.InfoTypeError,
+				Msg:  "Failed to pin the message: " + err.Error(),
+			}
+		}
+		return pinnedMsg{message: msg}
+	}
+}
+
+// setPinned shows the pin of msg on its items.
+func (m *messageListCmp) setPinned(msg message.Message) {
+	for _, item := range m.listCmp.Items() {
+		switch item := item.(type) {
+		case messages.MessageCmp:
+			if current := item.GetMessage(); current.ID == msg.ID {
+				current.Pinned = msg.Pinned
+				item.SetMessage(current)
+				m.listCmp.UpdateItem(item.ID(), item)
+			}
+		case messages.ToolCallCmp:
+			if item.ParentMessageID() == msg.ID {
+				item.SetPinned(msg.Pinned)
+				m.listCmp.UpdateItem(item.ID(), item)
+			}
+		}
+	}
+}
+
+// findPin selects the first item of the next pinned message, below the
+// selected one or above it when backward, wrapping around the transcript.
+func (m *messageListCmp) findPin(backward bool) tea.Cmd {
+	items := m.listCmp.Items()
+	if len(items) == 0 {
+		return nil
+	}
+	current := len(items) - 1
+	if item := m.listCmp.SelectedItem(); item != nil {
+		for i, it := range items {
+			if it.ID() == (*item).ID() {
+				current = i
+				break
+			}
+		}
+	}
+	idx := pinIndex(items, current, backward)
+	if idx == NotFound {
+		return util.ReportInfo("No pinned messages")
+	}
+	cmd := m.listCmp.SetSelected(items[idx].ID())
+	m.updateVisual()
+	return cmd
+}
+
+// pinIndex returns the index of the first item of the next pinned message
+// after current, or before it when backward, skipping the other items of
+// the message at current.
+func pinIndex(items []list.Item, current int, backward bool) int {
+	step := 1
+	if backward {
+		step = -1
+	}
+	n := len(items)
+	currentID, _ := itemPin(items[current])
+	for i := 1; i <= n; i++ {
+		idx := ((current+step*i)%n + n) % n
+		id, pinned := itemPin(items[idx])
+		if !pinned || (id == currentID && idx != current) {
+			continue
+		}
+		// Go to the first item of the message.
+		for idx > 0 {
+			if prev, _ := itemPin(items[idx-1]); prev != id {
+				break
+			}
+			idx--
+		}
+		return idx
+	}
+	return NotFound
+}
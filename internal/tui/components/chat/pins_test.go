@@ -0,0 +1,31 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/chat/messages"
+	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinIndex(t *testing.T) {
+	t.Parallel()
+
+	item := func(id string, pinned bool) list.Item {
+		return messages.NewMessageCmp(message.Message{ID: id, Role: message.User, Pinned: pinned})
+	}
+	items := []list.Item{
+		item("1", false),
+		item("2", true),
+		item("3", false),
+		item("4", true),
+	}
+
+	require.Equal(t, 1, pinIndex(items, 0, false))
+	require.Equal(t, 3, pinIndex(items, 1, false))
+	require.Equal(t, 1, pinIndex(items, 3, false), "wraps around")
+	require.Equal(t, 1, pinIndex(items, 3, true))
+	require.Equal(t, 3, pinIndex(items, 0, true), "wraps around backward")
+	require.Equal(t, NotFound, pinIndex([]list.Item{item("1", false)}, 0, false))
+}
@@ -3,9 +3,11 @@ package sidebar
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"slices"
 	"strings"
 
+	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
@@ -17,8 +19,10 @@ import (
 	"github.com/charmbracelet/crush/internal/home"
 	"github.com/charmbracelet/crush/internal/lsp"
 	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/remote"
 	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
+	"github.com/charmbracelet/crush/internal/tui/components/chat/todos"
 	"github.com/charmbracelet/crush/internal/tui/components/core"
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
 	"github.com/charmbracelet/crush/internal/tui/components/files"
@@ -60,10 +64,18 @@ type SessionFilesMsg struct {
 type Sidebar interface {
 	util.Model
 	layout.Sizeable
+	layout.Focusable
 	SetSession(session session.Session) tea.Cmd
 	SetCompactMode(bool)
 }
 
+// Keys of the sidebar when it's focused.
+var (
+	PreviousFileKey = key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑↓", "choose file"))
+	NextFileKey     = key.NewBinding(key.WithKeys("down", "j"))
+	OpenFileKey     = key.NewBinding(key.WithKeys("enter", "o"), key.WithHelp("enter", "open in editor"))
+)
+
 type sidebarCmp struct {
 	width, height int
 	session       session.Session
@@ -73,6 +85,11 @@ type sidebarCmp struct {
 	compactMode   bool
 	history       history.Service
 	files         *csync.Map[string, SessionFile]
+
+	// selected is the path of the modified file chosen while the sidebar
+	// is focused.
+	focused  bool
+	selected string
 }
 
 func New(history history.Service, lspClients *csync.Map[string, *lsp.Client], compact bool) Sidebar {
@@ -97,8 +114,13 @@ func (m *sidebarCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case tea.KeyPressMsg:
+		if m.focused {
+			return m, m.handleKey(msg)
+		}
 	case chat.SessionClearedMsg:
 		m.session = session.Session{}
+		m.selected = ""
 	case pubsub.Event[history.File]:
 		return m, m.handleFileHistoryEvent(msg)
 	case pubsub.Event[session.Session]:
@@ -160,6 +182,9 @@ func (m *sidebarCmp) View() string {
 	} else {
 		// Vertical layout (default)
 		if m.session.ID != "" {
+			if len(m.session.Todos) > 0 {
+				parts = append(parts, "", m.planBlock())
+			}
 			parts = append(parts, "", m.filesBlock())
 		}
 		parts = append(parts,
@@ -318,6 +343,10 @@ func (m *sidebarCmp) calculateAvailableHeight() int {
 
 	usedHeight += 2 // Model info
 
+	if steps := len(m.session.Todos); steps > 0 {
+		usedHeight += 2 + steps // Plan section header, empty line and steps
+	}
+
 	usedHeight += 6 // 3 sections × 2 lines each (header + empty line)
 
 	// Base padding
@@ -384,20 +413,7 @@ func (m *sidebarCmp) renderSectionsHorizontal() string {
 
 // filesBlockCompact renders the files block with limited width and height for horizontal layout
 func (m *sidebarCmp) filesBlockCompact(maxWidth int) string {
-	// Convert map to slice and handle type conversion
-	sessionFiles := slices.Collect(m.files.Seq())
-	fileSlice := make([]files.SessionFile, len(sessionFiles))
-	for i, sf := range sessionFiles {
-		fileSlice[i] = files.SessionFile{
-			History: files.FileHistory{
-				InitialVersion: sf.History.initialVersion,
-				LatestVersion:  sf.History.latestVersion,
-			},
-			FilePath:  sf.FilePath,
-			Additions: sf.Additions,
-			Deletions: sf.Deletions,
-		}
-	}
+	fileSlice := m.sessionFiles()
 
 	// Limit items for horizontal layout
 	maxItems := min(5, len(fileSlice))
@@ -450,7 +466,27 @@ func (m *sidebarCmp) mcpBlockCompact(maxWidth int) string {
 }
 
 func (m *sidebarCmp) filesBlock() string {
-	// Convert map to slice and handle type conversion
+	fileSlice := m.sessionFiles()
+
+	// Limit the number of files shown
+	maxFiles, _, _ := m.getDynamicLimits()
+	maxFiles = min(len(fileSlice), maxFiles)
+
+	selected := ""
+	if m.focused {
+		selected = m.selected
+	}
+	return files.RenderFileBlock(fileSlice, files.RenderOptions{
+		MaxWidth:    m.getMaxWidth(),
+		MaxItems:    maxFiles,
+		ShowSection: true,
+		SectionName: core.Section("Modified Files", m.getMaxWidth()),
+		Selected:    selected,
+	}, true)
+}
+
+// sessionFiles returns the files of the session for rendering.
+func (m *sidebarCmp) sessionFiles() []files.SessionFile {
 	sessionFiles := slices.Collect(m.files.Seq())
 	fileSlice := make([]files.SessionFile, len(sessionFiles))
 	for i, sf := range sessionFiles {
@@ -464,17 +500,63 @@ func (m *sidebarCmp) filesBlock() string {
 			Deletions: sf.Deletions,
 		}
 	}
+	return fileSlice
+}
 
-	// Limit the number of files shown
-	maxFiles, _, _ := m.getDynamicLimits()
-	maxFiles = min(len(fileSlice), maxFiles)
+// handleKey chooses a modified file and opens it in the editor.
+func (m *sidebarCmp) handleKey(msg tea.KeyPressMsg) tea.Cmd {
+	changed := files.Changed(m.sessionFiles())
+	if len(changed) == 0 {
+		return nil
+	}
+	i := slices.IndexFunc(changed, func(f files.SessionFile) bool { return f.FilePath == m.selected })
+	switch {
+	case key.Matches(msg, PreviousFileKey):
+		m.selected = changed[max(0, i-1)].FilePath
+	case key.Matches(msg, NextFileKey):
+		m.selected = changed[min(len(changed)-1, i+1)].FilePath
+	case key.Matches(msg, OpenFileKey) && i >= 0:
+		return util.OpenInEditor(m.selected, 0)
+	}
+	return nil
+}
 
-	return files.RenderFileBlock(fileSlice, files.RenderOptions{
-		MaxWidth:    m.getMaxWidth(),
-		MaxItems:    maxFiles,
-		ShowSection: true,
-		SectionName: core.Section("Modified Files", m.getMaxWidth()),
-	}, true)
+// Focus implements layout.Focusable.
+func (m *sidebarCmp) Focus() tea.Cmd {
+	m.focused = true
+	if changed := files.Changed(m.sessionFiles()); len(changed) > 0 && m.selected == "" {
+		m.selected = changed[0].FilePath
+	}
+	return nil
+}
+
+// Blur implements layout.Focusable.
+func (m *sidebarCmp) Blur() tea.Cmd {
+	m.focused = false
+	return nil
+}
+
+// IsFocused implements layout.Focusable.
+func (m *sidebarCmp) IsFocused() bool {
+	return m.focused
+}
+
+// planBlock renders the progress made against the session's plan.
+func (m *sidebarCmp) planBlock() string {
+	t := styles.CurrentTheme()
+	completed := 0
+	for _, todo := range m.session.Todos {
+		if todo.Status == session.TodoStatusCompleted {
+			completed++
+		}
+	}
+	info := t.S().Subtle.Render(fmt.Sprintf("%d/%d", completed, len(m.session.Todos)))
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		core.SectionWithInfo("Plan", m.getMaxWidth(), info),
+		"",
+		todos.FormatTodosList(m.session.Todos, styles.ArrowRightIcon, t, m.getMaxWidth()),
+	)
 }
 
 func (m *sidebarCmp) lspBlock() string {
@@ -505,9 +587,8 @@ func (m *sidebarCmp) mcpBlock() string {
 	}, true)
 }
 
-func formatTokensAndCost(tokens, contextWindow int64, cost float64) string {
-	t := styles.CurrentTheme()
-	// Format tokens in human-readable format (e.g., 110K, 1.2M)
+// formatTokens formats tokens in human-readable format (e.g., 110K, 1.2M).
+func formatTokens(tokens int64) string {
 	var formattedTokens string
 	switch {
 	case tokens >= 1_000_000:
@@ -525,6 +606,12 @@ func formatTokensAndCost(tokens, contextWindow int64, cost float64) string {
 	if strings.HasSuffix(formattedTokens, ".0M") {
 		formattedTokens = strings.Replace(formattedTokens, ".0M", "M", 1)
 	}
+	return formattedTokens
+}
+
+func formatTokensAndCost(tokens, contextWindow int64, cost float64) string {
+	t := styles.CurrentTheme()
+	formattedTokens := formatTokens(tokens)
 
 	percentage := (float64(tokens) / float64(contextWindow)) * 100
 
@@ -543,6 +630,17 @@ func formatTokensAndCost(tokens, contextWindow int64, cost float64) string {
 	return fmt.Sprintf("%s %s", formattedTokens, formattedCost)
 }
 
+// formatCacheSavings tells how many prompt tokens were read from the cache,
+// and what that saved.
+func formatCacheSavings(tokens int64, savings float64) string {
+	t := styles.CurrentTheme()
+	formatted := t.S().Base.Foreground(t.FgSubtle).Render(fmt.Sprintf("Cached %s", formatTokens(tokens)))
+	if savings <= 0 {
+		return formatted
+	}
+	return fmt.Sprintf("%s %s", formatted, t.S().Base.Foreground(t.FgMuted).Render(fmt.Sprintf("saved $%.2f", savings)))
+}
+
 func (s *sidebarCmp) currentModelBlock() string {
 	cfg := config.Get()
 	agentCfg := cfg.Agents[config.AgentCoder]
@@ -589,6 +687,9 @@ func (s *sidebarCmp) currentModelBlock() string {
 			),
 		)
 	}
+	if s.session.CacheReadTokens > 0 {
+		parts = append(parts, "  "+formatCacheSavings(s.session.CacheReadTokens, s.session.CacheSavings))
+	}
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		parts...,
@@ -607,7 +708,18 @@ func (m *sidebarCmp) SetCompactMode(compact bool) {
 }
 
 func cwd() string {
-	cwd := config.Get().WorkingDir()
 	t := styles.CurrentTheme()
-	return t.S().Muted.Render(home.Short(cwd))
+	if h := remote.Current(); h != nil {
+		return t.S().Muted.Render(h.String())
+	}
+	roots := config.Get().Roots()
+	cwd := t.S().Muted.Render(home.Short(roots[0]))
+	if len(roots) == 1 {
+		return cwd
+	}
+	names := make([]string, 0, len(roots)-1)
+	for _, root := range roots[1:] {
+		names = append(names, filepath.Base(root))
+	}
+	return cwd + t.S().Subtle.Render(" + "+strings.Join(names, ", "))
 }
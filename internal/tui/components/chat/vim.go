@@ -0,0 +1,206 @@
+package chat
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/tui/components/chat/messages"
+	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+// InsertModeMsg asks to leave the normal mode of the chat and focus the
+// editor.
+type InsertModeMsg struct{}
+
+// Key bindings of the vim-style normal mode of the chat.
+var (
+	InsertKey         = key.NewBinding(key.WithKeys("i", "a"), key.WithHelp("i", "insert"))
+	VisualKey         = key.NewBinding(key.WithKeys("v", "V"), key.WithHelp("v", "select messages"))
+	SearchKey         = key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search"))
+	SearchBackwardKey = key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "search backward"))
+	NextMatchKey      = key.NewBinding(key.WithKeys("n"), key.WithHelp("n/N", "next/prev match"))
+	PrevMatchKey      = key.NewBinding(key.WithKeys("N"), key.WithHelp("n/N", "next/prev match"))
+)
+
+// vimState is the state of the vim-style normal mode of the chat, which is
+// the chat while it is focused.
+type vimState struct {
+	enabled bool
+
+	// pendingG is set after a first g, which a second one completes.
+	pendingG bool
+	// anchor is the item a visual selection started at; empty outside of
+	// the visual mode.
+	anchor string
+
+	searching bool
+	input     textinput.Model
+	query     string
+	backward  bool
+}
+
+// vimKeyMap changes the list keys to the vim ones: j and k move between
+// messages, and the top is gg instead of g.
+func vimKeyMap(keyMap list.KeyMap) list.KeyMap {
+	keyMap.Down.SetKeys("down", "ctrl+j", "ctrl+n")
+	keyMap.Up.SetKeys("up", "ctrl+k", "ctrl+p")
+	keyMap.DownOneItem.SetKeys("shift+down", "J", "j")
+	keyMap.DownOneItem.SetHelp("j", "next message")
+	keyMap.UpOneItem.SetKeys("shift+up", "K", "k")
+	keyMap.UpOneItem.SetHelp("k", "previous message")
+	keyMap.Home.SetKeys("home")
+	keyMap.Home.SetHelp("gg", "top")
+	return keyMap
+}
+
+// handleVimKey handles the keys of the normal mode, and reports whether the
+// key was handled.
+func (m *messageListCmp) handleVimKey(msg tea.KeyPressMsg) (tea.Cmd, bool) {
+	if m.vim.searching {
+		return m.updateSearch(msg), true
+	}
+
+	pendingG := m.vim.pendingG
+	m.vim.pendingG = false
+	switch {
+	case msg.String() == "g":
+		if !pendingG {
+			m.vim.pendingG = true
+			return nil, true
+		}
+		cmd := m.listCmp.GoToTop()
+		m.updateVisual()
+		return cmd, true
+	case key.Matches(msg, InsertKey):
+		m.SelectionClear()
+		return util.CmdHandler(InsertModeMsg{}), true
+	case key.Matches(msg, VisualKey):
+		if m.vim.anchor != "" {
+			return m.SelectionClear(), true
+		}
+		if item := m.listCmp.SelectedItem(); item != nil {
+			m.vim.anchor = (*item).ID()
+			m.updateVisual()
+		}
+		return nil, true
+	case key.Matches(msg, SearchKey, SearchBackwardKey):
+		m.vim.searching = true
+		m.vim.backward = key.Matches(msg, SearchBackwardKey)
+		m.vim.input.Reset()
+		m.vim.input.Prompt = msg.String()
+		return m.vim.input.Focus(), true
+	case key.Matches(msg, NextMatchKey):
+		return m.findMatch(m.vim.backward), true
+	case key.Matches(msg, PrevMatchKey):
+		return m.findMatch(!m.vim.backward), true
+	}
+	return nil, false
+}
+
+// updateVisual selects the messages from the anchor of the visual mode to
+// the selected one.
+func (m *messageListCmp) updateVisual() {
+	if m.vim.anchor == "" {
+		return
+	}
+	if item := m.listCmp.SelectedItem(); item != nil {
+		m.listCmp.SelectItems(m.vim.anchor, (*item).ID())
+	}
+}
+
+// updateSearch handles keys while the search is typed.
+func (m *messageListCmp) updateSearch(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.vim.searching = false
+		m.vim.input.Blur()
+		return nil
+	case "enter":
+		m.vim.searching = false
+		m.vim.input.Blur()
+		if query := strings.TrimSpace(m.vim.input.Value()); query != "" {
+			m.vim.query = query
+		}
+		return m.findMatch(m.vim.backward)
+	}
+	var cmd tea.Cmd
+	m.vim.input, cmd = m.vim.input.Update(msg)
+	return cmd
+}
+
+// findMatch selects the next item, below the selected one or above it when
+// backward, containing the search query, wrapping around the transcript.
+func (m *messageListCmp) findMatch(backward bool) tea.Cmd {
+	if m.vim.query == "" {
+		return nil
+	}
+	items := m.listCmp.Items()
+	if len(items) == 0 {
+		return nil
+	}
+	current := len(items) - 1
+	if item := m.listCmp.SelectedItem(); item != nil {
+		for i, it := range items {
+			if it.ID() == (*item).ID() {
+				current = i
+				break
+			}
+		}
+	}
+	idx := matchIndex(items, current, strings.ToLower(m.vim.query), backward)
+	if idx == NotFound {
+		return util.ReportWarn("Pattern not found: " + m.vim.query)
+	}
+	cmd := m.listCmp.SetSelected(items[idx].ID())
+	m.updateVisual()
+	return cmd
+}
+
+// matchIndex returns the index of the first item after current, or before
+// it when backward, whose text contains query, wrapping around.
+func matchIndex(items []list.Item, current int, query string, backward bool) int {
+	step := 1
+	if backward {
+		step = -1
+	}
+	n := len(items)
+	for i := 1; i <= n; i++ {
+		idx := ((current+step*i)%n + n) % n
+		if strings.Contains(strings.ToLower(itemText(items[idx])), query) {
+			return idx
+		}
+	}
+	return NotFound
+}
+
+// itemText returns the text of an item that the search looks at.
+func itemText(item list.Item) string {
+	switch item := item.(type) {
+	case messages.MessageCmp:
+		msg := item.GetMessage()
+		return msg.Content().Text
+	case messages.ToolCallCmp:
+		return item.GetToolCall().Name + "\n" + item.GetToolCall().Input + "\n" + item.GetToolResult().Content
+	}
+	return ""
+}
+
+// searchView renders the search being typed over the last line of the chat.
+func (m *messageListCmp) searchView(view string) string {
+	if !m.vim.searching {
+		return view
+	}
+	t := styles.CurrentTheme()
+	lines := strings.Split(view, "\n")
+	lines[len(lines)-1] = t.S().Base.Width(m.width - 2).Render(m.vim.input.View())
+	return strings.Join(lines, "\n")
+}
+
+// Searching reports whether a search is being typed.
+func (m *messageListCmp) Searching() bool {
+	return m.vim.searching
+}
@@ -0,0 +1,47 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/chat/messages"
+	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchIndex(t *testing.T) {
+	t.Parallel()
+
+	text := func(id, content string) list.Item {
+		return messages.NewMessageCmp(message.Message{
+			ID:    id,
+			Role:  message.User,
+			Parts: []message.ContentPart{message.TextContent{Text: content}},
+		})
+	}
+	items := []list.Item{
+		text("1", "Fix the parser"),
+		text("2", "Done"),
+		text("3", "The PARSER is fixed"),
+		text("4", "Thanks"),
+	}
+
+	require.Equal(t, 2, matchIndex(items, 0, "parser", false))
+	require.Equal(t, 0, matchIndex(items, 2, "parser", false), "wraps around")
+	require.Equal(t, 2, matchIndex(items, 3, "parser", true))
+	require.Equal(t, 2, matchIndex(items, 0, "parser", true), "wraps around backward")
+	require.Equal(t, 1, matchIndex(items, 1, "done", false), "the current item matches last")
+	require.Equal(t, NotFound, matchIndex(items, 0, "lexer", false))
+}
+
+func TestVimKeyMap(t *testing.T) {
+	t.Parallel()
+
+	keyMap := vimKeyMap(list.DefaultKeyMap())
+	require.Contains(t, keyMap.DownOneItem.Keys(), "j")
+	require.Contains(t, keyMap.UpOneItem.Keys(), "k")
+	require.NotContains(t, keyMap.Down.Keys(), "j")
+	require.NotContains(t, keyMap.Up.Keys(), "k")
+	require.NotContains(t, keyMap.Home.Keys(), "g", "the top is gg")
+	require.Contains(t, list.DefaultKeyMap().Down.Keys(), "j", "the default key map is left alone")
+}
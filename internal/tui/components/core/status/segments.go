@@ -0,0 +1,206 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/ci"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// defaultInterval is how often the command of a segment runs when its
+	// interval isn't set.
+	defaultInterval = 30 * time.Second
+	// commandTimeout bounds how long the command of a segment may run.
+	commandTimeout = 10 * time.Second
+	// maxSegmentWidth bounds the width of a single segment.
+	maxSegmentWidth = 40
+)
+
+// branchSegment is the branch segment, which is refreshed like a custom
+// segment.
+var branchSegment = config.StatusSegment{
+	Command:  "git branch --show-current 2>/dev/null",
+	Interval: 10,
+}
+
+// segmentDueMsg asks to run the command of a segment again.
+type segmentDueMsg struct {
+	name string
+}
+
+// segmentOutputMsg carries the output of the command of a segment. The
+// outputs of runs made once don't schedule the segment again, as it
+// already is.
+type segmentOutputMsg struct {
+	name   string
+	output string
+	once   bool
+}
+
+// clockMsg updates the time segment.
+type clockMsg time.Time
+
+// commands returns the segments shown that run a command, by name.
+func commands(bar config.StatusBar) map[string]config.StatusSegment {
+	cmds := make(map[string]config.StatusSegment)
+	for _, name := range bar.Ordered() {
+		if name == config.StatusSegmentBranch {
+			cmds[name] = branchSegment
+		} else if seg, ok := bar.Custom[name]; ok && seg.Command != "" {
+			cmds[name] = seg
+		}
+	}
+	return cmds
+}
+
+// interval returns how often the command of seg runs.
+func interval(seg config.StatusSegment) time.Duration {
+	if seg.Interval <= 0 {
+		return defaultInterval
+	}
+	return time.Duration(seg.Interval) * time.Second
+}
+
+// runSegment runs the command of a segment in dir.
+func runSegment(dir, name string, seg config.StatusSegment) tea.Cmd {
+	return runSegmentOnce(dir, name, seg, false)
+}
+
+// runSegmentOnce runs the command of a segment in dir, scheduling it again
+// unless once.
+func runSegmentOnce(dir, name string, seg config.StatusSegment, once bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+		defer cancel()
+		out, _, err := shell.NewShell(&shell.Options{WorkingDir: dir}).Exec(ctx, seg.Command)
+		if err != nil {
+			slog.Debug("Status bar segment failed", "segment", name, "error", err)
+		}
+		return segmentOutputMsg{name: name, output: firstLine(out), once: once}
+	}
+}
+
+// scheduleSegment runs the command of a segment again once its interval
+// passed.
+func scheduleSegment(name string, seg config.StatusSegment) tea.Cmd {
+	return tea.Tick(interval(seg), func(time.Time) tea.Msg {
+		return segmentDueMsg{name: name}
+	})
+}
+
+// tickClock updates the time segment at the start of the next minute.
+func tickClock() tea.Cmd {
+	return tea.Every(time.Minute, func(t time.Time) tea.Msg {
+		return clockMsg(t)
+	})
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(ansi.Strip(s))
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
+
+// segments renders the segments of the status bar, or nothing when none
+// of them has anything to show.
+func (m *statusCmp) segments() string {
+	t := styles.CurrentTheme()
+	var parts []string
+	for _, name := range m.bar.Ordered() {
+		if part := m.segment(name); part != "" {
+			parts = append(parts, ansi.Truncate(part, maxSegmentWidth, "…"))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return t.S().Base.PaddingRight(1).Render(strings.Join(parts, t.S().Subtle.Render(" · ")))
+}
+
+// segment renders a single segment.
+func (m *statusCmp) segment(name string) string {
+	t := styles.CurrentTheme()
+	switch name {
+	case config.StatusSegmentModel:
+		if cfg := config.Get(); cfg != nil {
+			if model := cfg.LargeModel(); model != nil {
+				return t.S().Muted.Render(styles.ModelIcon + " " + model.Name)
+			}
+		}
+		return ""
+	case config.StatusSegmentCost:
+		if m.session.ID == "" {
+			return ""
+		}
+		return t.S().Muted.Render(fmt.Sprintf("$%.2f", m.session.Cost))
+	case config.StatusSegmentCI:
+		return m.ciIndicator()
+	case config.StatusSegmentTasks:
+		running, unread := jobs()
+		if running == 0 && !unread {
+			return ""
+		}
+		segment := t.S().Subtle.Render("Jobs ") + t.S().Muted.Render(fmt.Sprint(running))
+		if unread {
+			// Some job wrote or exited since the jobs were last looked at.
+			segment += t.S().Base.Foreground(t.Warning).Render(" " + styles.UnreadIcon)
+		}
+		return segment
+	case config.StatusSegmentRoot:
+		cfg := config.Get()
+		if cfg == nil || len(cfg.Roots()) < 2 {
+			return ""
+		}
+		return t.S().Subtle.Render("Root ") + t.S().Muted.Render(filepath.Base(m.dir))
+	case config.StatusSegmentTime:
+		if m.now.IsZero() {
+			return ""
+		}
+		return t.S().Muted.Render(m.now.Format("15:04"))
+	default:
+		if m.outputs[name] == "" {
+			return ""
+		}
+		return t.S().Muted.Render(m.outputs[name])
+	}
+}
+
+// ciIndicator renders the CI status of the branch, or nothing when it has
+// no CI jobs.
+func (m *statusCmp) ciIndicator() string {
+	t := styles.CurrentTheme()
+	var icon string
+	switch m.ci.Status {
+	case ci.StatusPass:
+		icon = t.S().Base.Foreground(t.Success).Render(styles.CheckIcon)
+	case ci.StatusFail:
+		icon = t.S().Base.Foreground(t.Error).Render(styles.ErrorIcon)
+	case ci.StatusPending:
+		icon = t.S().Base.Foreground(t.Warning).Render(styles.LoadingIcon)
+	default:
+		return ""
+	}
+	return t.S().Subtle.Render("CI ") + icon
+}
+
+// jobs counts the background shells still running, and tells whether any
+// has output or an exit not looked at.
+func jobs() (running int, unread bool) {
+	for _, bs := range shell.GetBackgroundShellManager().Jobs() {
+		if !bs.IsDone() {
+			running++
+		}
+		unread = unread || bs.Unread()
+	}
+	return running, unread
+}
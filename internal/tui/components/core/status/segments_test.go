@@ -0,0 +1,70 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrdered(t *testing.T) {
+	t.Parallel()
+
+	custom := map[string]config.StatusSegment{
+		"kube": {Command: "kubectl config current-context"},
+		"aws":  {Command: "echo $AWS_PROFILE"},
+	}
+	require.Equal(t, []string{"root", "ci"}, config.StatusBar{}.Ordered())
+	require.Equal(t, []string{"root", "ci", "aws", "kube"}, config.StatusBar{Custom: custom}.Ordered())
+	require.Equal(t, []string{"kube", "time"}, config.StatusBar{Segments: []string{"kube", "time"}, Custom: custom}.Ordered())
+}
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	bar := config.StatusBar{
+		Segments: []string{"model", "branch", "kube", "missing"},
+		Custom: map[string]config.StatusSegment{
+			"kube":   {Command: "kubectl config current-context", Interval: 60},
+			"aws":    {Command: "echo $AWS_PROFILE"},
+			"branch": {Command: "echo shadowed"},
+		},
+	}
+	cmds := commands(bar)
+	require.Len(t, cmds, 2, "only the segments shown run commands")
+	require.Equal(t, branchSegment, cmds["branch"], "built-in segments take precedence")
+	require.Equal(t, time.Minute, interval(cmds["kube"]))
+	require.Equal(t, defaultInterval, interval(bar.Custom["aws"]))
+}
+
+func TestFirstLine(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "", firstLine(""))
+	require.Equal(t, "main", firstLine("main\n"))
+	require.Equal(t, "prod", firstLine("\n\x1b[32mprod\x1b[0m  \nother\n"))
+}
+
+func TestSegments(t *testing.T) {
+	t.Parallel()
+
+	m := &statusCmp{
+		bar: config.StatusBar{
+			Segments: []string{"cost", "kube", "time", "tasks"},
+			Custom: map[string]config.StatusSegment{
+				"kube": {Command: "kubectl config current-context"},
+			},
+		},
+		outputs: make(map[string]string),
+	}
+	m.commands = commands(m.bar)
+	require.Empty(t, m.segments(), "nothing to show yet")
+
+	m.Update(segmentOutputMsg{name: "kube", output: "prod"})
+	m.Update(session.Session{ID: "1", Cost: 1.5})
+	m.Update(clockMsg(time.Date(2025, 1, 2, 15, 4, 5, 0, time.Local)))
+	require.Equal(t, "$1.50 · prod · 15:04 ", ansi.Strip(m.segments()))
+}
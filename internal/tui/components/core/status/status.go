@@ -1,11 +1,18 @@
 package status
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
 	"charm.land/bubbles/v2/help"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/ci"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/ratelimit"
+	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
 	"github.com/charmbracelet/x/ansi"
@@ -18,8 +25,24 @@ type StatusCmp interface {
 }
 
 type statusCmp struct {
-	info       util.InfoMsg
-	width      int
+	info  util.InfoMsg
+	ci    ci.Summary
+	width int
+
+	// bar is the configuration of the segments, and dir is where their
+	// commands run: the active root of the workspace.
+	bar      config.StatusBar
+	dir      string
+	commands map[string]config.StatusSegment
+	outputs  map[string]string
+	session  session.Session
+	now      time.Time
+
+	// rateLimit is the latest wait for a provider's rate limits, counted
+	// down until it's over.
+	rateLimit ratelimit.Event
+	countdown time.Time
+
 	messageTTL time.Duration
 	help       help.Model
 	keyMap     help.KeyMap
@@ -33,14 +56,41 @@ func (m *statusCmp) clearMessageCmd(ttl time.Duration) tea.Cmd {
 }
 
 func (m *statusCmp) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	for name, seg := range m.commands {
+		cmds = append(cmds, runSegment(m.dir, name, seg))
+	}
+	if slices.Contains(m.bar.Ordered(), config.StatusSegmentTime) {
+		m.now = time.Now()
+		cmds = append(cmds, tickClock())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *statusCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	return model, tea.Batch(cmd, m.followActiveRoot())
+}
+
+// followActiveRoot runs the commands of the segments again in the active
+// root of the workspace, when the agent moved to another one.
+func (m *statusCmp) followActiveRoot() tea.Cmd {
+	cfg := config.Get()
+	if cfg == nil || m.dir == "" || cfg.ActiveRoot() == m.dir {
+		return nil
+	}
+	m.dir = cfg.ActiveRoot()
+	var cmds []tea.Cmd
+	for name, seg := range m.commands {
+		cmds = append(cmds, runSegmentOnce(m.dir, name, seg, true))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *statusCmp) update(msg tea.Msg) (util.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
-		m.help.SetWidth(msg.Width - 2)
 		return m, nil
 
 	// Handle status info
@@ -53,42 +103,104 @@ func (m *statusCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		return m, m.clearMessageCmd(ttl)
 	case util.ClearStatusMsg:
 		m.info = util.InfoMsg{}
+	case pubsub.Event[ci.Summary]:
+		m.ci = msg.Payload
+	case session.Session:
+		m.session = msg
+	case pubsub.Event[session.Session]:
+		if msg.Payload.ID == m.session.ID {
+			m.session = msg.Payload
+		}
+	case segmentDueMsg:
+		if seg, ok := m.commands[msg.name]; ok {
+			return m, runSegment(m.dir, msg.name, seg)
+		}
+	case segmentOutputMsg:
+		m.outputs[msg.name] = msg.output
+		if msg.once {
+			return m, nil
+		}
+		return m, scheduleSegment(msg.name, m.commands[msg.name])
+	case clockMsg:
+		m.now = time.Time(msg)
+		return m, tickClock()
+	case pubsub.Event[ratelimit.Event]:
+		counting := m.waiting()
+		m.rateLimit = msg.Payload
+		m.countdown = time.Now()
+		if !counting {
+			return m, tickCountdown()
+		}
+	case countdownMsg:
+		m.countdown = time.Time(msg)
+		if m.waiting() {
+			return m, tickCountdown()
+		}
 	}
 	return m, nil
 }
 
 func (m *statusCmp) View() string {
 	t := styles.CurrentTheme()
-	status := t.S().Base.Padding(0, 1, 1, 1).Render(m.help.View(m.keyMap))
 	if m.info.Msg != "" {
-		status = m.infoMsg()
+		return m.infoMsg(m.info)
 	}
-	return status
+	if m.waiting() {
+		wait := m.rateLimit.Until.Sub(m.countdown).Round(time.Second)
+		return m.infoMsg(util.InfoMsg{
+			Type: util.InfoTypeWarn,
+			Msg:  fmt.Sprintf("Rate limit of %s reached (%s), resuming in %s", m.rateLimit.Host, m.rateLimit.Reason, wait),
+		})
+	}
+	segments := m.segments()
+	m.help.SetWidth(m.width - 2 - lipgloss.Width(segments))
+	if segments == "" {
+		return t.S().Base.Padding(0, 1, 1, 1).Render(m.help.View(m.keyMap))
+	}
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		t.S().Base.Width(m.width-lipgloss.Width(segments)).Padding(0, 1, 1, 1).Render(m.help.View(m.keyMap)),
+		segments,
+	)
+}
+
+// countdownMsg updates the countdown of a rate limit wait.
+type countdownMsg time.Time
+
+func tickCountdown() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return countdownMsg(t)
+	})
 }
 
-func (m *statusCmp) infoMsg() string {
+// waiting reports whether requests are held back for a rate limit.
+func (m *statusCmp) waiting() bool {
+	return m.countdown.Before(m.rateLimit.Until)
+}
+
+func (m *statusCmp) infoMsg(info util.InfoMsg) string {
 	t := styles.CurrentTheme()
 	message := ""
 	infoType := ""
-	switch m.info.Type {
+	switch info.Type {
 	case util.InfoTypeError:
 		infoType = t.S().Base.Background(t.Red).Padding(0, 1).Render("ERROR")
 		widthLeft := m.width - (lipgloss.Width(infoType) + 2)
-		info := ansi.Truncate(m.info.Msg, widthLeft, "…")
+		info := ansi.Truncate(info.Msg, widthLeft, "…")
 		message = t.S().Base.Background(t.Error).Width(widthLeft+2).Foreground(t.White).Padding(0, 1).Render(info)
 	case util.InfoTypeWarn:
 		infoType = t.S().Base.Foreground(t.BgOverlay).Background(t.Yellow).Padding(0, 1).Render("WARNING")
 		widthLeft := m.width - (lipgloss.Width(infoType) + 2)
-		info := ansi.Truncate(m.info.Msg, widthLeft, "…")
+		info := ansi.Truncate(info.Msg, widthLeft, "…")
 		message = t.S().Base.Foreground(t.BgOverlay).Width(widthLeft+2).Background(t.Warning).Padding(0, 1).Render(info)
 	default:
 		note := "OKAY!"
-		if m.info.Type == util.InfoTypeUpdate {
+		if info.Type == util.InfoTypeUpdate {
 			note = "HEY!"
 		}
 		infoType = t.S().Base.Foreground(t.BgSubtle).Background(t.Green).Padding(0, 1).Bold(true).Render(note)
 		widthLeft := m.width - (lipgloss.Width(infoType) + 2)
-		info := ansi.Truncate(m.info.Msg, widthLeft, "…")
+		info := ansi.Truncate(info.Msg, widthLeft, "…")
 		message = t.S().Base.Background(t.GreenDark).Width(widthLeft+2).Foreground(t.BgSubtle).Padding(0, 1).Render(info)
 	}
 	return ansi.Truncate(infoType+message, m.width, "…")
@@ -106,8 +218,15 @@ func NewStatusCmp() StatusCmp {
 	t := styles.CurrentTheme()
 	help := help.New()
 	help.Styles = t.S().Help
-	return &statusCmp{
+	m := &statusCmp{
 		messageTTL: 5 * time.Second,
 		help:       help,
+		outputs:    make(map[string]string),
+	}
+	if cfg := config.Get(); cfg != nil && cfg.Options != nil && cfg.Options.TUI != nil {
+		m.bar = cfg.Options.TUI.StatusBar
+		m.dir = cfg.ActiveRoot()
 	}
+	m.commands = commands(m.bar)
+	return m
 }
@@ -0,0 +1,171 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/crush/internal/update"
+	"github.com/charmbracelet/crush/internal/version"
+	"github.com/pkg/browser"
+)
+
+const ChangelogDialogID dialogs.DialogID = "changelog"
+
+// ChangelogDialog shows what's new in the latest release of Crush and how
+// to update to it.
+type ChangelogDialog interface {
+	dialogs.DialogModel
+}
+
+type checkedMsg struct {
+	info update.Info
+	err  error
+}
+
+type changelogDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	loading bool
+	info    update.Info
+	err     error
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+func NewChangelogDialogCmp() ChangelogDialog {
+	return &changelogDialogCmp{
+		loading:  true,
+		viewport: viewport.New(),
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+}
+
+func (d *changelogDialogCmp) Init() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		info, err := update.Check(ctx, version.Version, update.Default)
+		return checkedMsg{info: info, err: err}
+	}
+}
+
+func (d *changelogDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.viewport.SetContent(d.content())
+	case checkedMsg:
+		d.loading = false
+		d.info, d.err = msg.info, msg.err
+		d.viewport.SetContent(d.content())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Open):
+			url := d.info.URL
+			if url == "" {
+				return d, nil
+			}
+			return d, func() tea.Msg {
+				if err := browser.OpenURL(url); err != nil {
+					return util.ReportError(fmt.Errorf("failed to open browser: %w", err))()
+				}
+				return nil
+			}
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// content renders the changelog of the latest release in the viewport.
+func (d *changelogDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	switch {
+	case d.loading:
+		return t.S().Subtle.Render("Checking for updates...")
+	case d.err != nil:
+		return t.S().Error.Width(width).Render(d.err.Error())
+	}
+
+	var status string
+	switch {
+	case d.info.IsDevelopment():
+		status = fmt.Sprintf("This is a development version of Crush. The latest release is v%s.", d.info.Latest)
+	case d.info.Available():
+		status = fmt.Sprintf("Crush v%s is available, you have v%s. Run `crush update` to install it.", d.info.Latest, d.info.Current)
+	default:
+		status = fmt.Sprintf("Crush v%s is the latest release.", d.info.Current)
+	}
+	changelog := strings.TrimSpace(d.info.Changelog)
+	if changelog == "" {
+		changelog = "_No changelog for this release._"
+	}
+	rendered, err := styles.GetMarkdownRenderer(width).Render(status + "\n\n## v" + d.info.Latest + "\n\n" + changelog)
+	if err != nil {
+		return t.S().Error.Width(width).Render(err.Error())
+	}
+	return strings.TrimSuffix(rendered, "\n")
+}
+
+func (d *changelogDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "What's New"
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *changelogDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *changelogDialogCmp) ID() dialogs.DialogID {
+	return ChangelogDialogID
+}
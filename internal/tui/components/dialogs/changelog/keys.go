@@ -0,0 +1,48 @@
+package changelog
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the changelog.
+type KeyMap struct {
+	Scroll,
+	Open,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Scroll: key.NewBinding(
+			key.WithKeys("up", "down"),
+			key.WithHelp("↑↓/pgup/pgdn", "scroll"),
+		),
+		Open: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "open release"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc", "q"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Scroll,
+		k.Open,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return k.KeyBindings()
+}
@@ -0,0 +1,240 @@
+package cistatus
+
+import (
+	"context"
+	"fmt"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/ci"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pkg/browser"
+)
+
+const (
+	CIStatusDialogID dialogs.DialogID = "ci_status"
+
+	// listHeight is the number of jobs listed at once.
+	listHeight = 12
+)
+
+// LogPrompt builds the message asking the agent to fix a failing job.
+func LogPrompt(job ci.Job, log string) string {
+	return fmt.Sprintf("The CI job %q failed (%s). These are the last lines of its log:\n\n```\n%s\n```\n\nFind the cause of the failure and fix it.", job.Name, job.URL, log)
+}
+
+// CIStatusDialog lists the CI jobs of the current branch and sends the log
+// of a failing one to the agent.
+type CIStatusDialog interface {
+	dialogs.DialogModel
+}
+
+type (
+	refreshedMsg struct {
+		summary ci.Summary
+	}
+	logMsg struct {
+		job ci.Job
+		log string
+		err error
+	}
+)
+
+type ciStatusDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	service  ci.Service
+	summary  ci.Summary
+	loading  bool
+	fetching bool
+	selected int
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewCIStatusDialogCmp creates the dialog showing the jobs known to service.
+func NewCIStatusDialogCmp(service ci.Service) CIStatusDialog {
+	return &ciStatusDialogCmp{
+		service: service,
+		summary: service.Current(),
+		keyMap:  DefaultKeyMap(),
+		help:    help.New(),
+	}
+}
+
+func (c *ciStatusDialogCmp) Init() tea.Cmd {
+	return c.refresh()
+}
+
+func (c *ciStatusDialogCmp) refresh() tea.Cmd {
+	c.loading = true
+	service := c.service
+	return func() tea.Msg {
+		return refreshedMsg{summary: service.Refresh(context.Background())}
+	}
+}
+
+func (c *ciStatusDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.wWidth = msg.Width
+		c.wHeight = msg.Height
+		c.width = min(90, c.wWidth-4)
+	case refreshedMsg:
+		c.loading = false
+		c.summary = msg.summary
+		c.selected = min(c.selected, max(0, len(c.summary.Jobs)-1))
+		if c.selected == 0 {
+			c.selected = c.firstFailing()
+		}
+	case logMsg:
+		c.fetching = false
+		if msg.err != nil {
+			return c, util.ReportError(msg.err)
+		}
+		return c, tea.Sequence(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			util.CmdHandler(commands.CommandRunCustomMsg{Content: LogPrompt(msg.job, msg.log)}),
+		)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, c.keyMap.Close):
+			return c, util.CmdHandler(dialogs.CloseDialogMsg{})
+		case key.Matches(msg, c.keyMap.Refresh):
+			return c, c.refresh()
+		case len(c.summary.Jobs) == 0:
+			return c, nil
+		case key.Matches(msg, c.keyMap.Next):
+			c.selected = (c.selected + 1) % len(c.summary.Jobs)
+		case key.Matches(msg, c.keyMap.Previous):
+			c.selected = (c.selected - 1 + len(c.summary.Jobs)) % len(c.summary.Jobs)
+		case key.Matches(msg, c.keyMap.OpenBrowser):
+			url := c.summary.Jobs[c.selected].URL
+			return c, func() tea.Msg {
+				if err := browser.OpenURL(url); err != nil {
+					return util.ReportError(fmt.Errorf("failed to open browser: %w", err))()
+				}
+				return nil
+			}
+		case key.Matches(msg, c.keyMap.SendLog):
+			return c, c.sendLog()
+		}
+	}
+	return c, nil
+}
+
+func (c *ciStatusDialogCmp) firstFailing() int {
+	for i, job := range c.summary.Jobs {
+		if job.Status == ci.StatusFail {
+			return i
+		}
+	}
+	return 0
+}
+
+func (c *ciStatusDialogCmp) sendLog() tea.Cmd {
+	job := c.summary.Jobs[c.selected]
+	if job.Status != ci.StatusFail {
+		return util.ReportWarn("Only the logs of failed jobs can be sent")
+	}
+	if c.fetching {
+		return nil
+	}
+	c.fetching = true
+	service := c.service
+	return func() tea.Msg {
+		log, err := service.Log(context.Background(), job)
+		return logMsg{job: job, log: log, err: err}
+	}
+}
+
+func (c *ciStatusDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := c.width - 4
+
+	title := "CI Status"
+	if c.summary.Provider != "" {
+		title = c.summary.Provider + " CI Status"
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, width))
+
+	var body []string
+	switch {
+	case c.summary.Error != "":
+		body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render(c.summary.Error))
+	case len(c.summary.Jobs) == 0 && c.loading:
+		body = append(body, t.S().Subtle.Render("Checking the CI status…"))
+	case len(c.summary.Jobs) == 0:
+		body = append(body, t.S().Subtle.Render("No CI jobs for this branch."))
+	default:
+		body = append(body, c.listView(width)...)
+	}
+	if c.fetching {
+		body = append(body, "", t.S().Subtle.Render("Fetching the log…"))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(c.width-2).PaddingLeft(1).Render(c.help.View(c.keyMap)),
+	)
+	return t.S().Base.
+		Width(c.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+// listView renders the jobs, scrolled to keep the selected one visible.
+func (c *ciStatusDialogCmp) listView(width int) []string {
+	t := styles.CurrentTheme()
+	jobs := c.summary.Jobs
+	var lines []string
+	start := max(0, min(c.selected-listHeight/2, len(jobs)-listHeight))
+	for i := start; i < min(start+listHeight, len(jobs)); i++ {
+		job := jobs[i]
+		var icon string
+		switch job.Status {
+		case ci.StatusPass:
+			icon = t.S().Base.Foreground(t.Success).Render(styles.CheckIcon)
+		case ci.StatusFail:
+			icon = t.S().Base.Foreground(t.Error).Render(styles.ErrorIcon)
+		case ci.StatusPending:
+			icon = t.S().Base.Foreground(t.Warning).Render(styles.LoadingIcon)
+		default:
+			icon = t.S().Subtle.Render("-")
+		}
+		name := t.S().Text.Render(job.Name)
+		if i == c.selected {
+			name = t.S().Base.Foreground(t.Primary).Bold(true).Render(job.Name)
+		}
+		line := icon + " " + name
+		if job.Workflow != "" && job.Workflow != job.Name {
+			line += " " + t.S().Muted.Render(job.Workflow)
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lines
+}
+
+func (c *ciStatusDialogCmp) Position() (int, int) {
+	row := c.wHeight/4 - 2 // just a bit above the center
+	col := c.wWidth / 2
+	col -= c.width / 2
+	return max(0, row), col
+}
+
+func (c *ciStatusDialogCmp) ID() dialogs.DialogID {
+	return CIStatusDialogID
+}
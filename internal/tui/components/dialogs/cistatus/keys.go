@@ -0,0 +1,71 @@
+package cistatus
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the CI status dialog.
+type KeyMap struct {
+	Next,
+	Previous,
+	SendLog,
+	OpenBrowser,
+	Refresh,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "ctrl+n", "j"),
+			key.WithHelp("↓", "next"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "ctrl+p", "k"),
+			key.WithHelp("↑", "previous"),
+		),
+		SendLog: key.NewBinding(
+			key.WithKeys("enter", "l"),
+			key.WithHelp("enter", "send log to agent"),
+		),
+		OpenBrowser: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "open in browser"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refresh"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.SendLog,
+		k.OpenBrowser,
+		k.Refresh,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.SendLog,
+		k.OpenBrowser,
+		k.Refresh,
+		k.Close,
+	}
+}
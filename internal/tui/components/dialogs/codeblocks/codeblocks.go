@@ -0,0 +1,435 @@
+package codeblocks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/remote"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/execfailed"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	CodeBlocksDialogID dialogs.DialogID = "code_blocks"
+
+	// listHeight is the number of blocks listed at most.
+	listHeight = 6
+	// previewHeight is the number of lines of the selected block shown.
+	previewHeight = 12
+)
+
+// Block is a fenced code block of a message.
+type Block struct {
+	Lang string
+	Code string
+}
+
+// Parse returns the fenced code blocks of markdown text.
+func Parse(text string) []Block {
+	var blocks []Block
+	var current Block
+	var lines []string
+	fence := ""
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" {
+			if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+				fence = trimmed[:3]
+				info := strings.Fields(strings.TrimLeft(trimmed, fence[:1]))
+				current = Block{}
+				if len(info) > 0 {
+					current.Lang = strings.ToLower(info[0])
+				}
+				lines = nil
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			current.Code = strings.Join(lines, "\n")
+			blocks = append(blocks, current)
+			fence = ""
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return blocks
+}
+
+// shells maps the languages of the blocks that can be run to their shell.
+var shells = map[string]string{
+	"":        "sh",
+	"sh":      "sh",
+	"shell":   "sh",
+	"console": "sh",
+	"bash":    "bash",
+	"zsh":     "zsh",
+	"fish":    "fish",
+}
+
+// extensions maps the languages whose name isn't their file extension.
+var extensions = map[string]string{
+	"":           "txt",
+	"text":       "txt",
+	"shell":      "sh",
+	"bash":       "sh",
+	"zsh":        "sh",
+	"console":    "sh",
+	"python":     "py",
+	"javascript": "js",
+	"typescript": "ts",
+	"golang":     "go",
+	"rust":       "rs",
+	"ruby":       "rb",
+	"markdown":   "md",
+	"yml":        "yaml",
+}
+
+// Extension returns the file extension of the block's language.
+func (b Block) Extension() string {
+	if ext, ok := extensions[b.Lang]; ok {
+		return ext
+	}
+	return b.Lang
+}
+
+// Runnable reports whether the block is a shell script.
+func (b Block) Runnable() bool {
+	_, ok := shells[b.Lang]
+	return ok
+}
+
+// Script returns the command running the block in dir, keeping the output
+// on screen until enter is pressed.
+func (b Block) Script(dir string) string {
+	code := b.Code
+	if b.Lang == "console" {
+		// Console blocks show prompts before the commands and their output
+		// after, only the commands are run.
+		var commands []string
+		for line := range strings.SplitSeq(code, "\n") {
+			if cmd, ok := strings.CutPrefix(strings.TrimSpace(line), "$ "); ok {
+				commands = append(commands, cmd)
+			}
+		}
+		code = strings.Join(commands, "\n")
+	}
+	script := fmt.Sprintf(
+		"cd %s && %s -c %s; printf '\\n[exited with code %%d, press enter to return]' $?; read _ || true",
+		quote(dir), shells[b.Lang], quote(code),
+	)
+	return "sh -c " + quote(script)
+}
+
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// QuoteMsg asks to quote a block in the prompt.
+type QuoteMsg struct {
+	Block Block
+}
+
+type mode int
+
+const (
+	modeList mode = iota
+	modeSave
+	modeRun
+)
+
+// CodeBlocksDialog lists the code blocks of a message and copies, saves or
+// runs them.
+type CodeBlocksDialog interface {
+	dialogs.DialogModel
+}
+
+type codeBlocksDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	workingDir string
+	blocks     []Block
+	selected   int
+	mode       mode
+	err        error
+
+	input  textinput.Model
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewCodeBlocksDialogCmp creates the dialog for the given blocks, saving and
+// running them in workingDir.
+func NewCodeBlocksDialogCmp(workingDir string, blocks []Block) CodeBlocksDialog {
+	t := styles.CurrentTheme()
+	input := textinput.New()
+	input.SetVirtualCursor(false)
+	input.Placeholder = "Path to save the block to"
+	input.SetStyles(t.S().TextInput)
+
+	return &codeBlocksDialogCmp{
+		workingDir: workingDir,
+		blocks:     blocks,
+		input:      input,
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (d *codeBlocksDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *codeBlocksDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+		d.input.SetWidth(d.width - 6)
+	case tea.KeyPressMsg:
+		switch d.mode {
+		case modeSave:
+			return d, d.updateSave(msg)
+		case modeRun:
+			return d, d.updateRun(msg)
+		}
+		return d, d.updateList(msg)
+	case tea.PasteMsg:
+		if d.mode == modeSave {
+			var cmd tea.Cmd
+			d.input, cmd = d.input.Update(msg)
+			return d, cmd
+		}
+	}
+	return d, nil
+}
+
+func (d *codeBlocksDialogCmp) updateList(msg tea.KeyPressMsg) tea.Cmd {
+	block := d.blocks[d.selected]
+	switch {
+	case key.Matches(msg, d.keyMap.Next):
+		d.selected = (d.selected + 1) % len(d.blocks)
+	case key.Matches(msg, d.keyMap.Previous):
+		d.selected = (d.selected - 1 + len(d.blocks)) % len(d.blocks)
+	case key.Matches(msg, d.keyMap.Copy):
+		return tea.Sequence(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			util.CopyToClipboard(block.Code, "Code block"),
+		)
+	case key.Matches(msg, d.keyMap.Quote):
+		return tea.Sequence(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			util.CmdHandler(QuoteMsg{Block: block}),
+		)
+	case key.Matches(msg, d.keyMap.Save):
+		d.mode = modeSave
+		d.err = nil
+		d.input.SetValue(fmt.Sprintf("snippet-%d.%s", d.selected+1, block.Extension()))
+		d.input.CursorEnd()
+		return d.input.Focus()
+	case key.Matches(msg, d.keyMap.Run):
+		if !block.Runnable() {
+			return util.ReportWarn("Only shell blocks can be run")
+		}
+		d.mode = modeRun
+	case key.Matches(msg, d.keyMap.Close):
+		return util.CmdHandler(dialogs.CloseDialogMsg{})
+	}
+	return nil
+}
+
+func (d *codeBlocksDialogCmp) updateSave(msg tea.KeyPressMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, d.keyMap.Confirm):
+		path, err := d.save(d.blocks[d.selected], strings.TrimSpace(d.input.Value()))
+		if err != nil {
+			d.err = err
+			return nil
+		}
+		return tea.Sequence(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			util.ReportInfo("Saved the code block to "+path),
+		)
+	case key.Matches(msg, d.keyMap.Back):
+		d.mode = modeList
+		d.err = nil
+		d.input.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return cmd
+}
+
+// save writes the block to path, relative to the working directory, never
+// overwriting an existing file.
+func (d *codeBlocksDialogCmp) save(block Block, path string) (string, error) {
+	if path == "" {
+		return "", errors.New("a path is required")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(d.workingDir, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("%s already exists", path)
+		}
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(block.Code + "\n"); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (d *codeBlocksDialogCmp) updateRun(msg tea.KeyPressMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, d.keyMap.Confirm):
+		dir := d.workingDir
+		if remote.Current() != nil {
+			// The command runs in the directory of the workspace already.
+			dir = "."
+		}
+		script := d.blocks[d.selected].Script(dir)
+		return tea.Sequence(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			execfailed.Run(config.ExternalToolShell, script),
+		)
+	case key.Matches(msg, d.keyMap.Back):
+		d.mode = modeList
+	}
+	return nil
+}
+
+func (d *codeBlocksDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Code Blocks", width))
+
+	body := d.listView(width)
+	body = append(body, "", d.previewView(d.blocks[d.selected], width))
+	var helpView string
+	switch d.mode {
+	case modeSave:
+		body = append(body, "", d.input.View())
+		if d.err != nil {
+			body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render(d.err.Error()))
+		}
+		helpView = d.help.View(promptKeyMap{Confirm: d.keyMap.Confirm, Back: d.keyMap.Back})
+	case modeRun:
+		body = append(body, "", t.S().Base.Foreground(t.Warning).Width(width).Render(
+			fmt.Sprintf("Run this block with %s in %s?", shells[d.blocks[d.selected].Lang], d.workingDir),
+		))
+		helpView = d.help.View(promptKeyMap{Confirm: d.keyMap.Confirm, Back: d.keyMap.Back})
+	default:
+		helpView = d.help.View(d.keyMap)
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(helpView),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+// listView renders the blocks, scrolled to keep the selected one visible.
+func (d *codeBlocksDialogCmp) listView(width int) []string {
+	t := styles.CurrentTheme()
+	var lines []string
+	start := max(0, min(d.selected-listHeight/2, len(d.blocks)-listHeight))
+	for i := start; i < min(start+listHeight, len(d.blocks)); i++ {
+		block := d.blocks[i]
+		lang := block.Lang
+		if lang == "" {
+			lang = "text"
+		}
+		first, _, _ := strings.Cut(strings.TrimSpace(block.Code), "\n")
+		label := fmt.Sprintf("%d. %s", i+1, lang)
+		if i == d.selected {
+			label = t.S().Base.Foreground(t.Primary).Bold(true).Render(label)
+		} else {
+			label = t.S().Text.Render(label)
+		}
+		line := label + " " + t.S().Subtle.Render(first)
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lines
+}
+
+// previewView renders the start of the selected block.
+func (d *codeBlocksDialogCmp) previewView(block Block, width int) string {
+	t := styles.CurrentTheme()
+	lines := strings.Split(block.Code, "\n")
+	more := len(lines) - previewHeight
+	if more > 0 {
+		lines = lines[:previewHeight]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(strings.ReplaceAll(line, "\t", "    "), width-2, "…")
+	}
+	if more > 0 {
+		lines = append(lines, fmt.Sprintf("… %d more lines", more))
+	}
+	return t.S().Base.
+		Foreground(t.FgMuted).
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(t.Border).
+		PaddingLeft(1).
+		Render(strings.Join(lines, "\n"))
+}
+
+func (d *codeBlocksDialogCmp) Cursor() *tea.Cursor {
+	if d.mode != modeSave {
+		return nil
+	}
+	cursor := d.input.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	// The input follows the header, the list, the preview and the blank
+	// lines between them.
+	row, col := d.Position()
+	listLines := min(listHeight, len(d.blocks))
+	previewLines := lipgloss.Height(d.previewView(d.blocks[d.selected], d.width-4))
+	cursor.Y += row + 1 + 2 + listLines + 1 + previewLines + 1
+	cursor.X += col + 2 // border and padding
+	return cursor
+}
+
+func (d *codeBlocksDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *codeBlocksDialogCmp) ID() dialogs.DialogID {
+	return CodeBlocksDialogID
+}
@@ -0,0 +1,67 @@
+package codeblocks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	text := "Run this:\n\n```Bash title=\"x\"\ngo test ./...\n```\n\nThen:\n\n~~~go\nfunc main() {\n\tfmt.Println(\"```\")\n}\n~~~\n\n```\nunterminated"
+	require.Equal(t, []Block{
+		{Lang: "bash", Code: "go test ./..."},
+		{Lang: "go", Code: "func main() {\n\tfmt.Println(\"```\")\n}"},
+	}, Parse(text))
+	require.Empty(t, Parse("no code here"))
+}
+
+func TestBlock(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "sh", Block{Lang: "bash"}.Extension())
+	require.Equal(t, "txt", Block{}.Extension())
+	require.Equal(t, "toml", Block{Lang: "toml"}.Extension())
+	require.True(t, Block{Lang: "console"}.Runnable())
+	require.False(t, Block{Lang: "go"}.Runnable())
+}
+
+func TestScript(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	dir := t.TempDir()
+	block := Block{Lang: "console", Code: "$ echo 'it works' > out.txt\nit works"}
+	cmd := exec.Command("sh", "-c", block.Script(dir))
+	cmd.Stdin = strings.NewReader("\n")
+	require.NoError(t, cmd.Run())
+
+	out, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "it works\n", string(out))
+}
+
+func TestSave(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	d := NewCodeBlocksDialogCmp(dir, nil).(*codeBlocksDialogCmp)
+
+	path, err := d.save(Block{Code: "hello"}, "notes/hello.txt")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "notes", "hello.txt"), path)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", string(content))
+
+	_, err = d.save(Block{Code: "again"}, "notes/hello.txt")
+	require.ErrorContains(t, err, "already exists")
+}
@@ -0,0 +1,104 @@
+package codeblocks
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the code blocks dialog.
+type KeyMap struct {
+	Next,
+	Previous,
+	Copy,
+	Quote,
+	Save,
+	Run,
+	Confirm,
+	Back,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "j", "tab"),
+			key.WithHelp("↓", "next"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "k", "shift+tab"),
+			key.WithHelp("↑", "previous"),
+		),
+		Copy: key.NewBinding(
+			key.WithKeys("c", "y", "enter"),
+			key.WithHelp("c", "copy"),
+		),
+		Quote: key.NewBinding(
+			key.WithKeys("q"),
+			key.WithHelp("q", "quote in prompt"),
+		),
+		Save: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "save to file"),
+		),
+		Run: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "run"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "confirm"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "back"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Copy,
+		k.Quote,
+		k.Save,
+		k.Run,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Copy,
+		k.Quote,
+		k.Save,
+		k.Run,
+		k.Close,
+	}
+}
+
+// promptKeyMap is the help shown while saving or confirming a run.
+type promptKeyMap struct {
+	Confirm,
+	Back key.Binding
+}
+
+// ShortHelp implements help.KeyMap.
+func (k promptKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Confirm, k.Back}
+}
+
+// FullHelp implements help.KeyMap.
+func (k promptKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
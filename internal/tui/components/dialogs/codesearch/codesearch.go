@@ -0,0 +1,224 @@
+package codesearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/semantic"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	CodeSearchDialogID dialogs.DialogID = "code_search"
+
+	// maxResults is the number of results listed.
+	maxResults = 10
+	// previewLines is the number of lines of the selected result shown.
+	previewLines = 8
+)
+
+// CodeSearchDialog searches the workspace by meaning and opens the chosen
+// result in the editor.
+type CodeSearchDialog interface {
+	dialogs.DialogModel
+}
+
+type resultsMsg struct {
+	query   string
+	results []semantic.Result
+	err     error
+}
+
+type codeSearchDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	index *semantic.Index
+
+	input     textinput.Model
+	searching bool
+	// query is the query the results are for. Pressing enter opens the
+	// selected result when the input still matches it, and searches again
+	// otherwise.
+	query    string
+	results  []semantic.Result
+	err      error
+	selected int
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewCodeSearchDialogCmp creates the code search dialog over the given
+// index.
+func NewCodeSearchDialogCmp(index *semantic.Index) CodeSearchDialog {
+	t := styles.CurrentTheme()
+	input := textinput.New()
+	input.SetVirtualCursor(false)
+	input.Placeholder = "Describe the code you're looking for"
+	input.SetStyles(t.S().TextInput)
+	input.Focus()
+
+	return &codeSearchDialogCmp{
+		index:  index,
+		input:  input,
+		keyMap: DefaultKeyMap(),
+		help:   help.New(),
+	}
+}
+
+func (c *codeSearchDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (c *codeSearchDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.wWidth = msg.Width
+		c.wHeight = msg.Height
+		c.width = min(100, c.wWidth-4)
+		c.input.SetWidth(c.width - 6)
+	case resultsMsg:
+		c.searching = false
+		c.query, c.results, c.err = msg.query, msg.results, msg.err
+		c.selected = 0
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, c.keyMap.Next):
+			c.selected = min(c.selected+1, len(c.results)-1)
+		case key.Matches(msg, c.keyMap.Previous):
+			c.selected = max(c.selected-1, 0)
+		case key.Matches(msg, c.keyMap.Select):
+			query := strings.TrimSpace(c.input.Value())
+			if query != c.query || c.err != nil {
+				return c, c.search(query)
+			}
+			if len(c.results) == 0 {
+				return c, nil
+			}
+			r := c.results[c.selected]
+			return c, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.OpenInEditor(r.Path, r.StartLine),
+			)
+		case key.Matches(msg, c.keyMap.Close):
+			return c, util.CmdHandler(dialogs.CloseDialogMsg{})
+		default:
+			var cmd tea.Cmd
+			c.input, cmd = c.input.Update(msg)
+			return c, cmd
+		}
+	case tea.PasteMsg:
+		var cmd tea.Cmd
+		c.input, cmd = c.input.Update(msg)
+		return c, cmd
+	}
+	return c, nil
+}
+
+func (c *codeSearchDialogCmp) search(query string) tea.Cmd {
+	if query == "" || c.searching {
+		return nil
+	}
+	c.searching = true
+	index := c.index
+	return func() tea.Msg {
+		results, err := index.Search(context.Background(), query, maxResults)
+		return resultsMsg{query: query, results: results, err: err}
+	}
+}
+
+func (c *codeSearchDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := c.width - 4
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Code Search", width))
+	input := t.S().Base.Padding(0, 1, 1, 1).Render(c.input.View())
+
+	var body []string
+	switch {
+	case c.searching:
+		body = append(body, t.S().Subtle.Render("Searching…"))
+	case c.err != nil:
+		body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render(c.err.Error()))
+	case c.query == "":
+		body = append(body, t.S().Subtle.Render("Press enter to search."))
+	case len(c.results) == 0:
+		body = append(body, t.S().Subtle.Render("No results."))
+	}
+	if !c.searching && c.err == nil {
+		for i, r := range c.results {
+			location := fmt.Sprintf("%s:%d-%d", fsext.PrettyPath(r.Path), r.StartLine, r.EndLine)
+			if i == c.selected {
+				location = t.S().Base.Foreground(t.Primary).Bold(true).Render(location)
+			} else {
+				location = t.S().Text.Render(location)
+			}
+			line := t.S().Muted.Render(fmt.Sprintf("%.2f", r.Score)) + " " + location
+			body = append(body, ansi.Truncate(line, width, "…"))
+		}
+		if len(c.results) > 0 {
+			body = append(body, "", c.previewView(c.results[c.selected], width))
+		}
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		input,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(c.width-2).PaddingLeft(1).Render(c.help.View(c.keyMap)),
+	)
+	return t.S().Base.
+		Width(c.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+// previewView renders the first lines of the result, skipping leading blank
+// lines.
+func (c *codeSearchDialogCmp) previewView(r semantic.Result, width int) string {
+	t := styles.CurrentTheme()
+	lines := strings.Split(strings.TrimLeft(r.Snippet, "\n"), "\n")
+	lines = lines[:min(previewLines, len(lines))]
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		lines[i] = ansi.Truncate(t.S().Subtle.Render(line), width, "…")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (c *codeSearchDialogCmp) Cursor() *tea.Cursor {
+	cursor := c.input.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := c.Position()
+	cursor.Y += row + 3 // border, title and padding
+	cursor.X += col + 2 // border and padding
+	return cursor
+}
+
+func (c *codeSearchDialogCmp) Position() (int, int) {
+	row := c.wHeight/4 - 2 // just a bit above the center
+	col := c.wWidth / 2
+	col -= c.width / 2
+	return max(0, row), col
+}
+
+func (c *codeSearchDialogCmp) ID() dialogs.DialogID {
+	return CodeSearchDialogID
+}
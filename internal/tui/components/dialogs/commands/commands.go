@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"slices"
@@ -17,6 +18,7 @@ import (
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/core"
@@ -81,9 +83,88 @@ type (
 	OpenReasoningDialogMsg struct{}
 	OpenExternalEditorMsg  struct{}
 	ToggleYoloModeMsg      struct{}
+	TogglePlanModeMsg      struct{}
+	OpenMCPManagerMsg      struct{}
+	OpenLSPManagerMsg      struct{}
+	OpenProviderHealthMsg  struct{}
+	OpenCredentialsMsg     struct{}
+	OpenConfigSourcesMsg   struct{}
+	OpenIgnoreRulesMsg     struct{}
+	OpenContextInspectMsg  struct{}
+	OpenDoctorMsg          struct{}
+	OpenLogsMsg            struct{}
+	OpenChangelogMsg       struct{}
+	OpenDashboardMsg       struct{}
+	OpenJobsMsg            struct{}
+	OpenSchedulesMsg       struct{}
+	OpenOnboardingMsg      struct{}
+	OpenSymbolPickerMsg    struct{}
+	OpenCodeSearchMsg      struct{}
+	OpenFindReplaceMsg     struct{}
+	OpenCommitMsg          struct{}
+	OpenPullRequestMsg     struct{}
+	OpenReviewsMsg         struct{}
+	OpenCIStatusMsg        struct{}
+	OpenIssuesMsg          struct{}
+	OpenMCPResourcesMsg    struct{}
+	OpenDiagnosticsMsg     struct{}
 	CompactMsg             struct {
 		SessionID string
 	}
+	FindSymbolMsg struct {
+		References bool
+	}
+	// StartSessionMsg starts a new session with a first message.
+	StartSessionMsg struct {
+		Content     string
+		Attachments []message.Attachment
+	}
+	// PullOllamaModelMsg pulls a model on the local Ollama server.
+	PullOllamaModelMsg struct {
+		Model string
+	}
+	// OpenRequestsMsg opens the inspector of the requests sent in a
+	// session.
+	OpenRequestsMsg struct {
+		SessionID string
+	}
+	// OpenTasksMsg opens the task list of a session.
+	OpenTasksMsg struct {
+		SessionID string
+	}
+	// PinProviderMsg pins a session to a provider, or unpins it when the
+	// provider is empty.
+	PinProviderMsg struct {
+		SessionID string
+		Provider  string
+	}
+	// FetchURLMsg attaches the main content of a web page to the prompt.
+	FetchURLMsg struct {
+		URL string
+	}
+	// RenameSessionMsg replaces the title of a session.
+	RenameSessionMsg struct {
+		SessionID string
+		Title     string
+	}
+	// TagSessionMsg adds tags to a session, or removes those starting
+	// with a -.
+	TagSessionMsg struct {
+		SessionID string
+		Edits     []string
+	}
+	// ShareSessionMsg writes a session to .crush/sessions for the team to
+	// read.
+	ShareSessionMsg struct {
+		SessionID string
+	}
+	// OpenSharedSessionsMsg opens the viewer of the sessions shared in the
+	// repository.
+	OpenSharedSessionsMsg struct{}
+	// StartJobMsg starts the job profile of the config with the name.
+	StartJobMsg struct {
+		Name string
+	}
 )
 
 func NewCommandDialog(sessionID string) CommandsDialog {
@@ -357,6 +438,91 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 
 	// Add reasoning toggle for models that support it
 	cfg := config.Get()
+	if c.sessionID != "" {
+		commands = append(commands, Command{
+			ID:          "inspect_requests",
+			Title:       "Inspect Requests",
+			Description: "Browse the raw requests sent to the provider in this session",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenRequestsMsg{SessionID: c.sessionID})
+			},
+		})
+		commands = append(commands, Command{
+			ID:          "show_tasks",
+			Title:       "Show Tasks",
+			Description: "See the tasks of the agent's plan, mark them done and continue with one",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenTasksMsg{SessionID: c.sessionID})
+			},
+		})
+		commands = append(commands, Command{
+			ID:          "rename_session",
+			Title:       "Rename Session",
+			Description: "Replace the title given to the session after its first exchange",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(ShowArgumentsDialogMsg{
+					CommandID:   "rename_session",
+					Description: "New title of the session",
+					ArgNames:    []string{"title"},
+					OnSubmit: func(args map[string]string) tea.Cmd {
+						return util.CmdHandler(RenameSessionMsg{SessionID: c.sessionID, Title: args["title"]})
+					},
+				})
+			},
+		})
+		commands = append(commands, Command{
+			ID:          "tag_session",
+			Title:       "Tag Session",
+			Description: "Add tags to the session to group it with others in the sessions",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(ShowArgumentsDialogMsg{
+					CommandID:   "tag_session",
+					Description: "Tags separated by spaces; -tag removes one",
+					ArgNames:    []string{"tags"},
+					OnSubmit: func(args map[string]string) tea.Cmd {
+						return util.CmdHandler(TagSessionMsg{SessionID: c.sessionID, Edits: strings.Fields(args["tags"])})
+					},
+				})
+			},
+		})
+		commands = append(commands, Command{
+			ID:          "share_session",
+			Title:       "Share Session",
+			Description: "Write the session, secrets masked, to .crush/sessions for the team to read",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(ShareSessionMsg{SessionID: c.sessionID})
+			},
+		})
+	}
+	commands = append(commands, Command{
+		ID:          "shared_sessions",
+		Title:       "Shared Sessions",
+		Description: "Read the sessions the team shared in .crush/sessions",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(OpenSharedSessionsMsg{})
+		},
+	})
+	if c.sessionID != "" && cfg.Options.Failover != nil {
+		commands = append(commands, Command{
+			ID:          "pin_provider",
+			Title:       "Pin Provider",
+			Description: "Stop falling back to other providers in this session",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(ShowArgumentsDialogMsg{
+					CommandID:   "pin_provider",
+					Description: "Provider to pin the session to; leave empty to unpin it",
+					ArgNames:    []string{"provider"},
+					OnSubmit: func(args map[string]string) tea.Cmd {
+						return util.CmdHandler(PinProviderMsg{
+							SessionID: c.sessionID,
+							Provider:  strings.TrimSpace(args["provider"]),
+						})
+					},
+				})
+			},
+		})
+	}
+
 	if agentCfg, ok := cfg.Agents[config.AgentCoder]; ok {
 		providerCfg := cfg.GetProviderForModel(agentCfg.Model)
 		model := cfg.GetModelByType(agentCfg.Model)
@@ -392,6 +558,17 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 			}
 		}
 	}
+	for _, name := range cfg.JobNames() {
+		job := cfg.Jobs[name]
+		commands = append(commands, Command{
+			ID:          "job_" + name,
+			Title:       "Start " + cmp.Or(job.Title, name),
+			Description: "Start " + job.Command + " as a background job",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(StartJobMsg{Name: name})
+			},
+		})
+	}
 	// Only show toggle compact mode command if window width is larger than compact breakpoint (90)
 	if c.wWidth > 120 && c.sessionID != "" {
 		commands = append(commands, Command{
@@ -419,12 +596,12 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 		}
 	}
 
-	// Add external editor command if $EDITOR is available
-	if os.Getenv("EDITOR") != "" {
+	// Add external editor command if $VISUAL or $EDITOR is available
+	if os.Getenv("VISUAL") != "" || os.Getenv("EDITOR") != "" {
 		commands = append(commands, Command{
 			ID:          "open_external_editor",
 			Title:       "Open External Editor",
-			Shortcut:    "ctrl+o",
+			Shortcut:    "ctrl+e",
 			Description: "Open external editor to compose message",
 			Handler: func(cmd Command) tea.Cmd {
 				return util.CmdHandler(OpenExternalEditorMsg{})
@@ -432,6 +609,155 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 		})
 	}
 
+	if c.sessionID != "" {
+		commands = append(commands, Command{
+			ID:          "show_diagnostics",
+			Title:       "Show Diagnostics",
+			Description: "Show LSP diagnostics for the files edited in this session",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenDiagnosticsMsg{})
+			},
+		})
+	}
+
+	commands = append(commands, Command{
+		ID:          "fetch",
+		Title:       "Fetch URL",
+		Description: "Attach the main content of a web page to the prompt",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(ShowArgumentsDialogMsg{
+				CommandID:   "fetch",
+				Description: "URL of the page to attach",
+				ArgNames:    []string{"url"},
+				OnSubmit: func(args map[string]string) tea.Cmd {
+					return util.CmdHandler(FetchURLMsg{URL: args["url"]})
+				},
+			})
+		},
+	})
+
+	commands = append(commands, Command{
+		ID:          "pull_ollama_model",
+		Title:       "Pull Ollama Model",
+		Description: "Download a model to run locally with Ollama",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(ShowArgumentsDialogMsg{
+				CommandID:   "pull_ollama_model",
+				Description: "Name of the model to pull, like qwen3:8b",
+				ArgNames:    []string{"model"},
+				OnSubmit: func(args map[string]string) tea.Cmd {
+					return util.CmdHandler(PullOllamaModelMsg{Model: args["model"]})
+				},
+			})
+		},
+	})
+
+	commands = append(commands, Command{
+		ID:          "search_symbols",
+		Title:       "Search Symbols",
+		Description: "Find a function, type or class by name and open it in your editor",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(OpenSymbolPickerMsg{})
+		},
+	})
+
+	commands = append(commands, Command{
+		ID:          "find_replace",
+		Title:       "Find and Replace",
+		Description: "Replace text across the project after previewing every change",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(OpenFindReplaceMsg{})
+		},
+	})
+
+	commands = append(commands, Command{
+		ID:          "git_commit",
+		Title:       "Commit Staged Changes",
+		Description: "Draft a commit message from the staged changes, edit it and commit",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(OpenCommitMsg{})
+		},
+	})
+
+	commands = append(commands, Command{
+		ID:          "create_pr",
+		Title:       "Create Pull Request",
+		Description: "Draft a pull request for the current branch and open it with gh",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(OpenPullRequestMsg{})
+		},
+	})
+
+	commands = append(commands, Command{
+		ID:          "import_reviews",
+		Title:       "Import Review Comments",
+		Description: "Send the unresolved review comments on the branch's pull request to the agent",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(OpenReviewsMsg{})
+		},
+	})
+
+	commands = append(commands, Command{
+		ID:          "ci_status",
+		Title:       "CI Status",
+		Description: "List the CI jobs of the current branch and send a failing job's log to the agent",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(OpenCIStatusMsg{})
+		},
+	})
+
+	commands = append(commands, Command{
+		ID:          "issues",
+		Title:       "Browse Issues",
+		Description: "Pick an open issue of the repository and start a session working on it",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(OpenIssuesMsg{})
+		},
+	})
+
+	if config.Get().Tools.SemanticSearch.Enabled() {
+		commands = append(commands, Command{
+			ID:          "search_code",
+			Title:       "Search Code",
+			Description: "Find code by describing what it does and open it in your editor",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenCodeSearchMsg{})
+			},
+		})
+	}
+
+	if len(config.Get().LSP) > 0 {
+		commands = append(commands,
+			Command{
+				ID:          "go_to_definition",
+				Title:       "Go to Definition",
+				Description: "Find where a symbol is defined and open it in your editor",
+				Handler: func(cmd Command) tea.Cmd {
+					return util.CmdHandler(FindSymbolMsg{})
+				},
+			},
+			Command{
+				ID:          "find_references",
+				Title:       "Find References",
+				Description: "List the references to a symbol and open one in your editor",
+				Handler: func(cmd Command) tea.Cmd {
+					return util.CmdHandler(FindSymbolMsg{References: true})
+				},
+			},
+		)
+	}
+
+	if hasMCPResources() {
+		commands = append(commands, Command{
+			ID:          "browse_mcp_resources",
+			Title:       "Browse MCP Resources",
+			Description: "Preview resources from MCP servers and attach them to the prompt",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenMCPResourcesMsg{})
+			},
+		})
+	}
+
 	return append(commands, []Command{
 		{
 			ID:          "toggle_yolo",
@@ -441,6 +767,126 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 				return util.CmdHandler(ToggleYoloModeMsg{})
 			},
 		},
+		{
+			ID:          "manage_mcp",
+			Title:       "Manage MCP Servers",
+			Description: "View, enable, disable, restart, or add MCP servers",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenMCPManagerMsg{})
+			},
+		},
+		{
+			ID:          "manage_lsp",
+			Title:       "Manage LSP Servers",
+			Description: "View the state and logs of LSP servers and restart them",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenLSPManagerMsg{})
+			},
+		},
+		{
+			ID:          "check_providers",
+			Title:       "Check Providers",
+			Description: "Check that every configured provider can be reached",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenProviderHealthMsg{})
+			},
+		},
+		{
+			ID:          "manage_credentials",
+			Title:       "Manage Credentials",
+			Description: "See where API keys and MCP tokens are kept and move them to the keychain",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenCredentialsMsg{})
+			},
+		},
+		{
+			ID:          "config_sources",
+			Title:       "Show Config Sources",
+			Description: "See the config files and profile in effect and where each setting comes from",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenConfigSourcesMsg{})
+			},
+		},
+		{
+			ID:          "inspect_context",
+			Title:       "Inspect Context",
+			Description: "Preview what the next request sends, with token counts, and drop parts of it",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenContextInspectMsg{})
+			},
+		},
+		{
+			ID:          "ignore_rules",
+			Title:       "Show Ignore Rules",
+			Description: "See the .crushignore rules keeping paths away from the agent",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenIgnoreRulesMsg{})
+			},
+		},
+		{
+			ID:          "doctor",
+			Title:       "Run Doctor",
+			Description: "Check the providers, MCP and LSP servers, programs, terminal and database",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenDoctorMsg{})
+			},
+		},
+		{
+			ID:          "show_stats",
+			Title:       "Show Stats",
+			Description: "Chart the sessions, tokens, tools, edits and latency of the last weeks, kept local",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenDashboardMsg{})
+			},
+		},
+		{
+			ID:          "background_jobs",
+			Title:       "Background Jobs",
+			Description: "Follow the output of the background jobs and attach it to the message",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenJobsMsg{})
+			},
+		},
+		{
+			ID:          "view_logs",
+			Title:       "View Logs",
+			Description: "Read the logs as they're written, and turn debug logging on or off",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenLogsMsg{})
+			},
+		},
+		{
+			ID:          "whats_new",
+			Title:       "What's New",
+			Description: "Check for a new release of Crush and read its changelog",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenChangelogMsg{})
+			},
+		},
+		{
+			ID:          "scheduled_runs",
+			Title:       "Show Scheduled Runs",
+			Description: "See the past runs of the schedules and open their sessions",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenSchedulesMsg{})
+			},
+		},
+		{
+			ID:          "setup_wizard",
+			Title:       "Run Setup Wizard",
+			Description: "Pick the provider, API key, theme and editor again",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenOnboardingMsg{})
+			},
+		},
+		{
+			ID:          "toggle_plan",
+			Title:       "Toggle Plan Mode",
+			Description: "Have the agent propose a plan for review before making changes",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(TogglePlanModeMsg{})
+			},
+		},
 		{
 			ID:          "toggle_help",
 			Title:       "Toggle Help",
@@ -479,3 +925,13 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 func (c *commandDialogCmp) ID() dialogs.DialogID {
 	return CommandsDialogID
 }
+
+func hasMCPResources() bool {
+	for range mcp.Resources() {
+		return true
+	}
+	for range mcp.ResourceTemplates() {
+		return true
+	}
+	return false
+}
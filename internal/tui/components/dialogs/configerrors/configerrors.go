@@ -0,0 +1,147 @@
+package configerrors
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const ConfigErrorsDialogID dialogs.DialogID = "config_errors"
+
+// ConfigErrorsDialog lists the mistakes that kept changed config files from
+// being applied.
+type ConfigErrorsDialog interface {
+	dialogs.DialogModel
+}
+
+type configErrorsDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	errs []config.ValidationError
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewConfigErrorsDialogCmp creates the list of the mistakes in the config
+// files.
+func NewConfigErrorsDialogCmp(errs []config.ValidationError) ConfigErrorsDialog {
+	return &configErrorsDialogCmp{
+		errs:     errs,
+		viewport: viewport.New(),
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+}
+
+func (d *configErrorsDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *configErrorsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetContent(d.content())
+		d.height = min(d.viewport.TotalLineCount()+8, max(10, d.wHeight*3/4))
+		d.viewport.SetHeight(d.height - 8) // border, title, note and help
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Copy):
+			return d, util.CopyToClipboard(d.plain(), "Config errors")
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// content renders each mistake with where it is.
+func (d *configErrorsDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	var lines []string
+	for i, err := range d.errs {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		location := err.Path
+		if err.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", err.Path, err.Line, err.Column)
+		}
+		if location != "" {
+			lines = append(lines, t.S().Base.Foreground(t.Primary).Bold(true).Width(width).Render(location))
+		}
+		lines = append(lines, t.S().Error.Width(width).Render(err.Message))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// plain is what gets copied: one mistake per line.
+func (d *configErrorsDialogCmp) plain() string {
+	var b strings.Builder
+	for _, err := range d.errs {
+		b.WriteString(err.Error())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (d *configErrorsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Config Errors"
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+	note := t.S().Subtle.Render("The changes are applied once these are fixed; the previous configuration stays in effect until then.")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(note),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *configErrorsDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *configErrorsDialogCmp) ID() dialogs.DialogID {
+	return ConfigErrorsDialogID
+}
@@ -0,0 +1,48 @@
+package configerrors
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the list of config errors.
+type KeyMap struct {
+	Scroll,
+	Copy,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Scroll: key.NewBinding(
+			key.WithKeys("up", "down"),
+			key.WithHelp("↑↓/pgup/pgdn", "scroll"),
+		),
+		Copy: key.NewBinding(
+			key.WithKeys("c", "y"),
+			key.WithHelp("c", "copy"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc", "q"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Scroll,
+		k.Copy,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return k.KeyBindings()
+}
@@ -0,0 +1,175 @@
+package configsources
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const ConfigSourcesDialogID dialogs.DialogID = "config_sources"
+
+// ConfigSourcesDialog shows the config files merged into the configuration
+// and which of them each setting comes from.
+type ConfigSourcesDialog interface {
+	dialogs.DialogModel
+}
+
+type configSourcesDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	layers  []config.Layer
+	profile string
+	origins []config.Origin
+	err     error
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewConfigSourcesDialogCmp creates the viewer of where the settings come
+// from.
+func NewConfigSourcesDialogCmp() ConfigSourcesDialog {
+	d := &configSourcesDialogCmp{
+		viewport: viewport.New(),
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+	if cfg := config.Get(); cfg != nil {
+		d.layers = cfg.Layers()
+		d.profile = cfg.Profile()
+		d.origins, d.err = cfg.Origins()
+	}
+	return d
+}
+
+func (d *configSourcesDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *configSourcesDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(120, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.viewport.SetContent(d.content())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Copy):
+			return d, util.CopyToClipboard(d.plain(), "Config sources")
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// content renders the files merged, then the settings.
+func (d *configSourcesDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+
+	lines := []string{t.S().Base.Foreground(t.Primary).Bold(true).Render("Files, from the least to the most important"), ""}
+	for _, layer := range d.layers {
+		icon := t.ItemOnlineIcon.String()
+		description := layer.Path
+		if !layer.Exists() {
+			icon = t.ItemOfflineIcon.String()
+			description += " (missing)"
+		}
+		lines = append(lines, core.Status(core.StatusOpts{
+			Icon:        icon,
+			Title:       layer.Name,
+			Description: t.S().Subtle.Render(description),
+		}, width))
+	}
+
+	lines = append(lines, "", t.S().Base.Foreground(t.Primary).Bold(true).Render("Settings"), "")
+	switch {
+	case d.err != nil:
+		lines = append(lines, t.S().Error.Render(d.err.Error()))
+	case len(d.origins) == 0:
+		lines = append(lines, t.S().Subtle.Render("No settings in the config files."))
+	}
+	for _, origin := range d.origins {
+		lines = append(lines, t.S().Text.Width(width).Render(fmt.Sprintf(
+			"%s = %s %s",
+			origin.Key,
+			origin.Value,
+			t.S().Subtle.Render("("+strings.Join(origin.Layers, " < ")+")"),
+		)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// plain is what gets copied: the files and the settings, as text.
+func (d *configSourcesDialogCmp) plain() string {
+	var b strings.Builder
+	for _, layer := range d.layers {
+		fmt.Fprintf(&b, "%s: %s\n", layer.Name, layer.Path)
+	}
+	b.WriteString("\n")
+	for _, origin := range d.origins {
+		fmt.Fprintf(&b, "%s = %s (%s)\n", origin.Key, origin.Value, strings.Join(origin.Layers, " < "))
+	}
+	return b.String()
+}
+
+func (d *configSourcesDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Config Sources"
+	if d.profile != "" {
+		title += " · profile " + d.profile
+	}
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *configSourcesDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *configSourcesDialogCmp) ID() dialogs.DialogID {
+	return ConfigSourcesDialogID
+}
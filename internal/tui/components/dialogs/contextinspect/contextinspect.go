@@ -0,0 +1,324 @@
+package contextinspect
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/instructions"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const ContextInspectDialogID dialogs.DialogID = "context_inspect"
+
+// DropSectionsMsg leaves the sections with IDs out of the next request,
+// bringing back the others.
+type DropSectionsMsg struct {
+	IDs []string
+}
+
+// ContextInspectDialog previews the next request of a session by section,
+// with their token counts, and drops sections from it.
+type ContextInspectDialog interface {
+	dialogs.DialogModel
+}
+
+type previewMsg struct {
+	sections []agent.Section
+	err      error
+}
+
+type contextInspectDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	coordinator agent.Coordinator
+	sessionID   string
+	prompt      string
+	attachments []message.Attachment
+
+	loading  bool
+	sections []agent.Section
+	dropped  []string
+	selected int
+	// files are the instruction files, to tell what they include.
+	files  []instructions.File
+	nested []instructions.File
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewContextInspectDialogCmp creates the preview of the next request of
+// sessionID, sending prompt and attachments, with the sections of dropped
+// already left out.
+func NewContextInspectDialogCmp(coordinator agent.Coordinator, sessionID, prompt string, attachments []message.Attachment, dropped []string) ContextInspectDialog {
+	d := &contextInspectDialogCmp{
+		coordinator: coordinator,
+		sessionID:   sessionID,
+		prompt:      prompt,
+		attachments: attachments,
+		dropped:     slices.Clone(dropped),
+		loading:     true,
+		viewport:    viewport.New(),
+		keyMap:      DefaultKeyMap(),
+		help:        help.New(),
+	}
+	if cfg := config.Get(); cfg != nil {
+		d.files = instructions.Load(cfg)
+	}
+	if sessionID != "" {
+		d.nested = instructions.Delivered(sessionID)
+	}
+	return d
+}
+
+func (d *contextInspectDialogCmp) Init() tea.Cmd {
+	return func() tea.Msg {
+		sections, err := d.coordinator.Preview(context.Background(), d.sessionID, d.prompt, d.attachments...)
+		return previewMsg{sections: sections, err: err}
+	}
+}
+
+func (d *contextInspectDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(120, d.wWidth-4)
+		d.height = max(16, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - d.listHeight() - 8) // border, title, list, gaps and help
+		d.viewport.SetContent(d.content())
+	case previewMsg:
+		d.loading = false
+		if msg.err != nil {
+			return d, util.ReportError(msg.err)
+		}
+		d.sections = msg.sections
+		d.viewport.SetHeight(d.height - d.listHeight() - 8)
+		d.viewport.SetContent(d.content())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Previous):
+			d.show(d.selected - 1)
+			return d, nil
+		case key.Matches(msg, d.keyMap.Next):
+			d.show(d.selected + 1)
+			return d, nil
+		case key.Matches(msg, d.keyMap.Toggle):
+			d.toggle()
+			return d, nil
+		case key.Matches(msg, d.keyMap.Apply):
+			return d, d.apply()
+		case key.Matches(msg, d.keyMap.Copy):
+			if len(d.sections) == 0 {
+				return d, nil
+			}
+			return d, util.CopyToClipboard(d.sections[d.selected].Content, "Section")
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// show selects the section at i, when there's one.
+func (d *contextInspectDialogCmp) show(i int) {
+	if i < 0 || i >= len(d.sections) || i == d.selected {
+		return
+	}
+	d.selected = i
+	d.viewport.SetContent(d.content())
+	d.viewport.GotoTop()
+}
+
+// toggle drops the selected section, or keeps it when it was dropped.
+func (d *contextInspectDialogCmp) toggle() {
+	if len(d.sections) == 0 || !d.sections[d.selected].Droppable {
+		return
+	}
+	id := d.sections[d.selected].ID
+	if i := slices.Index(d.dropped, id); i >= 0 {
+		d.dropped = slices.Delete(d.dropped, i, i+1)
+	} else {
+		d.dropped = append(d.dropped, id)
+	}
+}
+
+// apply closes the dialog, leaving the dropped sections out of the next
+// request.
+func (d *contextInspectDialogCmp) apply() tea.Cmd {
+	// Sections gone since they were dropped, like sent attachments, are
+	// forgotten.
+	dropped := slices.DeleteFunc(slices.Clone(d.dropped), func(id string) bool {
+		return !slices.ContainsFunc(d.sections, func(s agent.Section) bool { return s.ID == id })
+	})
+	cmds := []tea.Cmd{
+		util.CmdHandler(dialogs.CloseDialogMsg{}),
+		util.CmdHandler(DropSectionsMsg{IDs: dropped}),
+	}
+	if len(dropped) > 0 {
+		cmds = append(cmds, util.ReportInfo(fmt.Sprintf("%d section(s) will be left out of the next request", len(dropped))))
+	}
+	return tea.Sequence(cmds...)
+}
+
+// total returns the tokens of the sections sent.
+func (d *contextInspectDialogCmp) total() int64 {
+	var total int64
+	for _, s := range d.sections {
+		if !slices.Contains(d.dropped, s.ID) {
+			total += s.Tokens
+		}
+	}
+	return total
+}
+
+// listHeight is the height of the list of sections.
+func (d *contextInspectDialogCmp) listHeight() int {
+	return max(1, min(len(d.sections), d.height/3))
+}
+
+// list renders the sections around the selected one.
+func (d *contextInspectDialogCmp) list() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	if d.loading {
+		return t.S().Subtle.Render("Assembling the next request...")
+	}
+	height := d.listHeight()
+	start := max(0, min(d.selected-height/2, len(d.sections)-height))
+	var lines []string
+	for i := start; i < min(len(d.sections), start+height); i++ {
+		s := d.sections[i]
+		mark := "   "
+		switch {
+		case slices.Contains(d.dropped, s.ID):
+			mark = "[ ]"
+		case s.Droppable:
+			mark = "[x]"
+		}
+		tokens := fmt.Sprintf("~%s", formatTokens(s.Tokens))
+		label := fmt.Sprintf("%s %-12s %s", mark, s.Kind, s.Title)
+		label = ansi.Truncate(label, width-len(tokens)-1, "…")
+		line := label + strings.Repeat(" ", max(1, width-lipgloss.Width(label)-len(tokens))) + tokens
+		style := t.S().Text
+		if slices.Contains(d.dropped, s.ID) {
+			style = t.S().Subtle.Strikethrough(true)
+		}
+		if i == d.selected {
+			style = style.Background(t.Primary).Foreground(t.FgBase)
+		}
+		lines = append(lines, style.Render(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// content renders the selected section in the viewport.
+func (d *contextInspectDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	if len(d.sections) == 0 {
+		return ""
+	}
+	s := d.sections[d.selected]
+	var lines []string
+	if s.Kind == agent.SectionInstructions {
+		for _, f := range d.files {
+			if string(agent.SectionInstructions)+":"+f.Path != s.ID {
+				continue
+			}
+			for _, include := range f.Includes {
+				lines = append(lines, t.S().Subtle.Width(width).Render("includes "+home.Short(include)))
+			}
+			for _, err := range f.Errors {
+				lines = append(lines, t.S().Error.Width(width).Render(err))
+			}
+		}
+	}
+	if s.Kind == agent.SectionSystem && len(d.nested) > 0 {
+		var names []string
+		for _, f := range d.nested {
+			names = append(names, home.Short(f.Path))
+		}
+		lines = append(lines, t.S().Subtle.Width(width).Render("Given with the files viewed in subdirectories: "+strings.Join(names, ", ")))
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, t.S().Text.Width(width).Render(strings.ReplaceAll(s.Content, "\t", "    ")))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (d *contextInspectDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Next Request"
+	if !d.loading {
+		title = fmt.Sprintf("%s · ~%s tokens", title, formatTokens(d.total()))
+	}
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.list()),
+		"",
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *contextInspectDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *contextInspectDialogCmp) ID() dialogs.DialogID {
+	return ContextInspectDialogID
+}
+
+// formatTokens formats tokens in a short form, like 1.2K.
+func formatTokens(tokens int64) string {
+	switch {
+	case tokens >= 1_000_000:
+		return strings.Replace(fmt.Sprintf("%.1fM", float64(tokens)/1_000_000), ".0M", "M", 1)
+	case tokens >= 1_000:
+		return strings.Replace(fmt.Sprintf("%.1fK", float64(tokens)/1_000), ".0K", "K", 1)
+	}
+	return fmt.Sprintf("%d", tokens)
+}
@@ -0,0 +1,70 @@
+package contextinspect
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the context inspector.
+type KeyMap struct {
+	Previous,
+	Next,
+	Toggle,
+	Apply,
+	Scroll,
+	Copy,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Previous: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑↓", "choose section"),
+		),
+		Next: key.NewBinding(
+			key.WithKeys("down", "j"),
+		),
+		Toggle: key.NewBinding(
+			key.WithKeys("space", "x"),
+			key.WithHelp("space", "drop/keep"),
+		),
+		Apply: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "apply"),
+		),
+		Scroll: key.NewBinding(
+			key.WithKeys("pgup", "pgdown"),
+			key.WithHelp("pgup/pgdn", "scroll"),
+		),
+		Copy: key.NewBinding(
+			key.WithKeys("c", "y"),
+			key.WithHelp("c", "copy section"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc", "q"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Previous,
+		k.Toggle,
+		k.Apply,
+		k.Scroll,
+		k.Copy,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return k.KeyBindings()
+}
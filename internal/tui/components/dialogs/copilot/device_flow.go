@@ -233,10 +233,7 @@ func (d *DeviceFlow) CopyCode() tea.Cmd {
 	if d.State != DeviceFlowStateDisplay {
 		return nil
 	}
-	return tea.Sequence(
-		tea.SetClipboard(d.deviceCode.UserCode),
-		util.ReportInfo("Code copied to clipboard"),
-	)
+	return util.CopyToClipboard(d.deviceCode.UserCode, "Code")
 }
 
 // Cancel cancels the device flow polling.
@@ -0,0 +1,244 @@
+package credentials
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/keyring"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const CredentialsDialogID dialogs.DialogID = "credentials"
+
+// CredentialsDialog lists where the API keys of providers and the tokens of
+// MCP servers are kept, moves plaintext keys to the keychain, and forgets
+// them.
+type CredentialsDialog interface {
+	dialogs.DialogModel
+}
+
+// item is the credential of a provider or of an MCP server.
+type item struct {
+	provider config.Credential
+	// mcp is the name of the MCP server, when the item is one.
+	mcp        string
+	authorized bool
+}
+
+func (i item) name() string {
+	if i.mcp != "" {
+		return i.mcp
+	}
+	return i.provider.Name
+}
+
+type (
+	migratedMsg struct {
+		providers []string
+		err       error
+	}
+	forgottenMsg struct {
+		name string
+		err  error
+	}
+)
+
+type credentialsDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	items    []item
+	selected int
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewCredentialsDialogCmp creates the credentials management dialog.
+func NewCredentialsDialogCmp() CredentialsDialog {
+	m := &credentialsDialogCmp{
+		keyMap: DefaultKeyMap(),
+		help:   help.New(),
+	}
+	m.refresh()
+	return m
+}
+
+func (m *credentialsDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+// refresh lists the credentials of the providers, then of the MCP servers
+// reached over HTTP.
+func (m *credentialsDialogCmp) refresh() {
+	cfg := config.Get()
+	m.items = nil
+	if cfg == nil {
+		return
+	}
+	for _, credential := range cfg.Credentials() {
+		m.items = append(m.items, item{provider: credential})
+	}
+	var servers []item
+	for name, server := range cfg.MCP {
+		if server.Type == config.MCPStdio {
+			continue
+		}
+		servers = append(servers, item{mcp: name, authorized: mcp.HasCredentials(name)})
+	}
+	slices.SortFunc(servers, func(a, b item) int {
+		return cmp.Compare(a.mcp, b.mcp)
+	})
+	m.items = append(m.items, servers...)
+	m.selected = max(0, min(m.selected, len(m.items)-1))
+}
+
+func (m *credentialsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(90, m.wWidth-4)
+	case migratedMsg:
+		m.refresh()
+		switch {
+		case msg.err != nil:
+			return m, util.ReportError(msg.err)
+		case len(msg.providers) == 0:
+			return m, util.ReportInfo("No plaintext API keys to move")
+		default:
+			return m, util.ReportInfo(fmt.Sprintf("Moved the API keys of %s to the %s", strings.Join(msg.providers, ", "), keyring.Backend()))
+		}
+	case forgottenMsg:
+		m.refresh()
+		if msg.err != nil {
+			return m, util.ReportError(msg.err)
+		}
+		return m, util.ReportInfo("Forgot the credentials of " + msg.name)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, m.keyMap.Next):
+			m.selected = min(m.selected+1, len(m.items)-1)
+		case key.Matches(msg, m.keyMap.Previous):
+			m.selected = max(m.selected-1, 0)
+		case key.Matches(msg, m.keyMap.Migrate):
+			return m, func() tea.Msg {
+				providers, err := config.Get().MigrateCredentials()
+				return migratedMsg{providers: providers, err: err}
+			}
+		case key.Matches(msg, m.keyMap.Forget):
+			if len(m.items) == 0 {
+				return m, nil
+			}
+			return m, forget(m.items[m.selected])
+		case key.Matches(msg, m.keyMap.Close):
+			return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return m, nil
+}
+
+// forget removes the stored credentials of i.
+func forget(i item) tea.Cmd {
+	return func() tea.Msg {
+		if i.mcp != "" {
+			return forgottenMsg{name: i.mcp, err: mcp.Logout(i.mcp)}
+		}
+		switch i.provider.Source {
+		case config.CredentialKeychain, config.CredentialPlaintext:
+			return forgottenMsg{name: i.provider.Name, err: config.Get().ForgetAPIKey(i.provider.Provider)}
+		default:
+			return forgottenMsg{
+				name: i.provider.Name,
+				err:  fmt.Errorf("the API key of %s isn't stored by Crush", i.provider.Name),
+			}
+		}
+	}
+}
+
+func (m *credentialsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Credentials", m.width-4))
+
+	body := []string{
+		t.S().Subtle.Render("Secrets are kept in the " + keyring.Backend() + "."),
+		"",
+	}
+	if len(m.items) == 0 {
+		body = append(body, t.S().Subtle.Render("No providers or MCP servers configured."))
+	}
+	for i, it := range m.items {
+		icon, description := label(it)
+		title := it.name()
+		if i == m.selected {
+			title = t.S().Base.Foreground(t.Primary).Bold(true).Render(title)
+		}
+		body = append(body, core.Status(core.StatusOpts{
+			Icon:        icon,
+			Title:       title,
+			Description: description,
+		}, m.width-4))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+// label returns the icon and the description of where the credentials of
+// i are kept.
+func label(i item) (string, string) {
+	t := styles.CurrentTheme()
+	if i.mcp != "" {
+		if i.authorized {
+			return t.ItemOnlineIcon.String(), t.S().Subtle.Render("MCP token in keychain")
+		}
+		return t.ItemOfflineIcon.String(), t.S().Subtle.Render("MCP server not authorized")
+	}
+	switch i.provider.Source {
+	case config.CredentialKeychain:
+		return t.ItemOnlineIcon.String(), t.S().Subtle.Render("API key in keychain")
+	case config.CredentialPlaintext:
+		description := "API key in plaintext config"
+		if i.provider.Migratable {
+			description += ", press m to move it"
+		}
+		return t.ItemErrorIcon.String(), t.S().Subtle.Render(description)
+	case config.CredentialVariable:
+		return t.ItemOnlineIcon.String(), t.S().Subtle.Render("API key from environment or command")
+	case config.CredentialOAuth:
+		return t.ItemOnlineIcon.String(), t.S().Subtle.Render("OAuth token")
+	default:
+		return t.ItemOfflineIcon.String(), t.S().Subtle.Render("no API key")
+	}
+}
+
+func (m *credentialsDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *credentialsDialogCmp) ID() dialogs.DialogID {
+	return CredentialsDialogID
+}
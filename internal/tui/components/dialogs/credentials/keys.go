@@ -0,0 +1,64 @@
+package credentials
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the credentials dialog.
+type KeyMap struct {
+	Next,
+	Previous,
+	Migrate,
+	Forget,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "ctrl+n", "j"),
+			key.WithHelp("↓", "next credential"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "ctrl+p", "k"),
+			key.WithHelp("↑", "previous credential"),
+		),
+		Migrate: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "move plaintext keys to keychain"),
+		),
+		Forget: key.NewBinding(
+			key.WithKeys("d", "delete"),
+			key.WithHelp("d", "forget"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Migrate,
+		k.Forget,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Migrate,
+		k.Forget,
+		k.Close,
+	}
+}
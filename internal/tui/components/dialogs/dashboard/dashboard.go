@@ -0,0 +1,220 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/stats"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const DashboardDialogID dialogs.DialogID = "dashboard"
+
+// maxRows is how many models, tools and files are listed.
+const maxRows = 8
+
+// DashboardDialog charts how the project was worked on in the last weeks,
+// from the local database only.
+type DashboardDialog interface {
+	dialogs.DialogModel
+}
+
+type loadedMsg struct {
+	stats stats.Stats
+	err   error
+}
+
+type dashboardDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	service    stats.Service
+	workingDir string
+	loading    bool
+	stats      stats.Stats
+	err        error
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewDashboardDialogCmp creates the dashboard of the statistics of service;
+// files are shown relative to workingDir.
+func NewDashboardDialogCmp(service stats.Service, workingDir string) DashboardDialog {
+	return &dashboardDialogCmp{
+		service:    service,
+		workingDir: workingDir,
+		loading:    true,
+		viewport:   viewport.New(),
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (d *dashboardDialogCmp) Init() tea.Cmd {
+	return func() tea.Msg {
+		st, err := d.service.Load(context.Background(), time.Now())
+		return loadedMsg{stats: st, err: err}
+	}
+}
+
+func (d *dashboardDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(80, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.viewport.SetContent(d.content())
+	case loadedMsg:
+		d.loading = false
+		d.stats, d.err = msg.stats, msg.err
+		d.viewport.SetContent(d.content())
+	case tea.KeyPressMsg:
+		if key.Matches(msg, d.keyMap.Close) {
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// content renders the charts in the viewport.
+func (d *dashboardDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	switch {
+	case d.loading:
+		return t.S().Subtle.Render("Counting...")
+	case d.err != nil:
+		return t.S().Error.Width(width).Render(d.err.Error())
+	}
+
+	st := d.stats
+	files := make([]stats.Series, len(st.Files))
+	for i, f := range st.Files {
+		if rel, err := filepath.Rel(d.workingDir, f.Name); err == nil && !strings.HasPrefix(rel, "..") {
+			f.Name = rel
+		}
+		files[i] = f
+	}
+	sections := []struct {
+		title  string
+		series []stats.Series
+		format func(int64) string
+	}{
+		{"Tokens per model", st.Models, formatTokens},
+		{"Tool calls", st.Tools, formatCount},
+		{"Most edited files", files, formatCount},
+	}
+
+	// Names are as wide as the longest shown, within what the sparkline
+	// and the total leave.
+	nameWidth := len("Turn latency")
+	for _, sec := range sections {
+		for _, s := range sec.series[:min(len(sec.series), maxRows)] {
+			nameWidth = max(nameWidth, ansi.StringWidth(s.Name))
+		}
+	}
+	nameWidth = min(nameWidth, max(12, width-stats.Weeks-12))
+	row := func(name string, weekly []int64, total string) string {
+		name = ansi.Truncate(name, nameWidth, "…")
+		return t.S().Text.Render(name+strings.Repeat(" ", nameWidth-ansi.StringWidth(name))) + "  " +
+			t.S().Base.Foreground(t.Primary).Render(sparkline(weekly)) + "  " +
+			t.S().Subtle.Render(total)
+	}
+
+	lines := []string{
+		t.S().Subtle.Width(width).Render(fmt.Sprintf("Last %d weeks, since %s. Nothing here leaves your machine.", stats.Weeks, st.Since.Local().Format("Jan 2"))),
+		"",
+		row("Sessions", st.Sessions.Weekly, formatCount(st.Sessions.Total)),
+		row("Turn latency", st.Latency.Weekly, formatLatency(st.AvgLatency)+" avg"),
+	}
+	for _, sec := range sections {
+		lines = append(lines, "", core.Section(sec.title, width))
+		if len(sec.series) == 0 {
+			lines = append(lines, t.S().Subtle.Render("Nothing yet"))
+		}
+		for _, s := range sec.series[:min(len(sec.series), maxRows)] {
+			lines = append(lines, row(s.Name, s.Weekly, sec.format(s.Total)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatCount(n int64) string {
+	return fmt.Sprintf("%d", n)
+}
+
+// formatTokens formats tokens in a short form, like 1.2K.
+func formatTokens(tokens int64) string {
+	switch {
+	case tokens >= 1_000_000:
+		return strings.Replace(fmt.Sprintf("%.1fM", float64(tokens)/1_000_000), ".0M", "M", 1)
+	case tokens >= 1_000:
+		return strings.Replace(fmt.Sprintf("%.1fK", float64(tokens)/1_000), ".0K", "K", 1)
+	}
+	return fmt.Sprintf("%d", tokens)
+}
+
+func formatLatency(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+func (d *dashboardDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Stats"
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *dashboardDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *dashboardDialogCmp) ID() dialogs.DialogID {
+	return DashboardDialogID
+}
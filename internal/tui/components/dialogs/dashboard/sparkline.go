@@ -0,0 +1,26 @@
+package dashboard
+
+import (
+	"slices"
+	"strings"
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as bars of eighths of a cell, scaled to the
+// largest. Anything above zero shows above the baseline.
+func sparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	top := max(slices.Max(values), 1)
+	var sb strings.Builder
+	for _, v := range values {
+		i := 0
+		if v > 0 {
+			i = max(1, int(v*int64(len(sparks)-1)/top))
+		}
+		sb.WriteRune(sparks[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,16 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "▁▂▅█", sparkline([]int64{0, 1, 4, 7}))
+	require.Equal(t, "▁▂█", sparkline([]int64{0, 1, 1000}), "small values show above the baseline")
+	require.Equal(t, "▁▁", sparkline([]int64{0, 0}))
+	require.Empty(t, sparkline(nil))
+}
@@ -0,0 +1,289 @@
+package diagnostics
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+const (
+	DiagnosticsDialogID dialogs.DialogID = "diagnostics"
+
+	// listHeight is the number of diagnostics visible at once.
+	listHeight = 12
+)
+
+// Item is a single diagnostic reported by an LSP server.
+type Item struct {
+	Path     string
+	Line     int
+	Column   int
+	Severity protocol.DiagnosticSeverity
+	Source   string
+	Message  string
+}
+
+// Location returns the 1-based file position of the diagnostic.
+func (i Item) Location() string {
+	return fmt.Sprintf("%s:%d:%d", i.Path, i.Line, i.Column)
+}
+
+// Collect returns the diagnostics reported for the given files, errors
+// first.
+func Collect(lspClients *csync.Map[string, *lsp.Client], paths []string) []Item {
+	var items []Item
+	for name, client := range lspClients.Seq2() {
+		for _, path := range paths {
+			for _, d := range client.GetFileDiagnostics(protocol.URIFromPath(path)) {
+				items = append(items, Item{
+					Path:     path,
+					Line:     int(d.Range.Start.Line) + 1,
+					Column:   int(d.Range.Start.Character) + 1,
+					Severity: d.Severity,
+					Source:   cmp.Or(d.Source, name),
+					Message:  d.Message,
+				})
+			}
+		}
+	}
+	Sort(items)
+	return items
+}
+
+// Sort orders diagnostics by severity, then by location.
+func Sort(items []Item) {
+	slices.SortStableFunc(items, func(a, b Item) int {
+		return cmp.Or(
+			cmp.Compare(severityRank(a.Severity), severityRank(b.Severity)),
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+}
+
+// severityRank treats a missing severity as an error, as the LSP spec
+// leaves its interpretation to the client.
+func severityRank(s protocol.DiagnosticSeverity) int {
+	if s == 0 {
+		return int(protocol.SeverityError)
+	}
+	return int(s)
+}
+
+// Errors returns only the error diagnostics.
+func Errors(items []Item) []Item {
+	var errs []Item
+	for _, item := range items {
+		if severityRank(item.Severity) == int(protocol.SeverityError) {
+			errs = append(errs, item)
+		}
+	}
+	return errs
+}
+
+// FixPrompt builds the message asking the agent to fix the diagnostics.
+func FixPrompt(items []Item) string {
+	var sb strings.Builder
+	noun := "diagnostics"
+	if len(items) == 1 {
+		noun = "diagnostic"
+	}
+	fmt.Fprintf(&sb, "Fix these %d %s reported by the language server:\n\n", len(items), noun)
+	for _, item := range items {
+		fmt.Fprintf(&sb, "- %s: %s", item.Location(), item.Message)
+		if item.Source != "" {
+			fmt.Fprintf(&sb, " [%s]", item.Source)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// DiagnosticsDialog lists LSP diagnostics for the files edited in the
+// current session.
+type DiagnosticsDialog interface {
+	dialogs.DialogModel
+}
+
+type diagnosticsDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	items    []Item
+	selected int
+	offset   int
+
+	// offer is set when the dialog is opened automatically after an agent
+	// turn left new errors behind.
+	offer bool
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewDiagnosticsDialogCmp creates the diagnostics panel. When offer is true
+// the dialog asks whether the errors should be sent back to the agent.
+func NewDiagnosticsDialogCmp(items []Item, offer bool) DiagnosticsDialog {
+	return &diagnosticsDialogCmp{
+		items:  items,
+		offer:  offer,
+		keyMap: DefaultKeyMap(),
+		help:   help.New(),
+	}
+}
+
+func (d *diagnosticsDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *diagnosticsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Next):
+			d.move(1)
+		case key.Matches(msg, d.keyMap.Previous):
+			d.move(-1)
+		case key.Matches(msg, d.keyMap.Fix):
+			toFix := d.toFix()
+			if len(toFix) == 0 {
+				return d, util.ReportInfo("No diagnostics to fix")
+			}
+			return d, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.CmdHandler(commands.CommandRunCustomMsg{Content: FixPrompt(toFix)}),
+			)
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return d, nil
+}
+
+// toFix returns the errors, or every diagnostic when there are no errors.
+func (d *diagnosticsDialogCmp) toFix() []Item {
+	if errs := Errors(d.items); len(errs) > 0 {
+		return errs
+	}
+	return d.items
+}
+
+func (d *diagnosticsDialogCmp) move(delta int) {
+	if len(d.items) == 0 {
+		return
+	}
+	d.selected = max(0, min(d.selected+delta, len(d.items)-1))
+	if d.selected < d.offset {
+		d.offset = d.selected
+	}
+	if d.selected >= d.offset+listHeight {
+		d.offset = d.selected - listHeight + 1
+	}
+}
+
+func (d *diagnosticsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+
+	title := "Diagnostics"
+	if d.offer {
+		title = "New Diagnostics"
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, width))
+
+	var body []string
+	if d.offer {
+		n := len(d.toFix())
+		body = append(body, t.S().Text.Width(width).Render(fmt.Sprintf(
+			"The last changes left %d %s. Press enter to send them back to the agent.",
+			n, plural(n, "problem", "problems"),
+		)), "")
+	}
+	if len(d.items) == 0 {
+		body = append(body, t.S().Subtle.Render("No diagnostics in the files edited in this session."))
+	}
+
+	end := min(d.offset+listHeight, len(d.items))
+	for i := d.offset; i < end; i++ {
+		body = append(body, d.itemView(d.items[i], i == d.selected, width))
+	}
+	if len(d.items) > listHeight {
+		body = append(body, t.S().Subtle.Render(fmt.Sprintf("%d/%d", d.selected+1, len(d.items))))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *diagnosticsDialogCmp) itemView(item Item, selected bool, width int) string {
+	t := styles.CurrentTheme()
+	var icon string
+	switch severityRank(item.Severity) {
+	case int(protocol.SeverityError):
+		icon = t.S().Base.Foreground(t.Error).Render(styles.ErrorIcon)
+	case int(protocol.SeverityWarning):
+		icon = t.S().Base.Foreground(t.Warning).Render(styles.WarningIcon)
+	case int(protocol.SeverityHint):
+		icon = t.S().Base.Foreground(t.FgHalfMuted).Render(styles.HintIcon)
+	default:
+		icon = t.S().Base.Foreground(t.FgHalfMuted).Render(styles.InfoIcon)
+	}
+
+	location := fmt.Sprintf("%s:%d:%d", fsext.PrettyPath(item.Path), item.Line, item.Column)
+	locationStyle := t.S().Subtle
+	if selected {
+		locationStyle = t.S().Base.Foreground(t.Primary).Bold(true)
+	}
+	message := strings.ReplaceAll(item.Message, "\n", " ")
+	line := fmt.Sprintf("%s %s %s", icon, locationStyle.Render(location), t.S().Text.Render(message))
+	return ansi.Truncate(line, width, "…")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+func (d *diagnosticsDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *diagnosticsDialogCmp) ID() dialogs.DialogID {
+	return DiagnosticsDialogID
+}
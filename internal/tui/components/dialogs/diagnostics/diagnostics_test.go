@@ -0,0 +1,40 @@
+package diagnostics
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSort(t *testing.T) {
+	t.Parallel()
+
+	items := []Item{
+		{Path: "b.go", Line: 1, Severity: protocol.SeverityWarning},
+		{Path: "b.go", Line: 3, Severity: protocol.SeverityError},
+		{Path: "a.go", Line: 9},
+		{Path: "a.go", Line: 2, Severity: protocol.SeverityHint},
+	}
+	Sort(items)
+
+	require.Equal(t, []Item{
+		{Path: "a.go", Line: 9},
+		{Path: "b.go", Line: 3, Severity: protocol.SeverityError},
+		{Path: "b.go", Line: 1, Severity: protocol.SeverityWarning},
+		{Path: "a.go", Line: 2, Severity: protocol.SeverityHint},
+	}, items)
+	require.Len(t, Errors(items), 2)
+}
+
+func TestFixPrompt(t *testing.T) {
+	t.Parallel()
+
+	prompt := FixPrompt([]Item{
+		{Path: "main.go", Line: 4, Column: 2, Message: "undefined: foo", Source: "compiler"},
+		{Path: "main.go", Line: 7, Column: 1, Message: "missing return"},
+	})
+	require.Equal(t, "Fix these 2 diagnostics reported by the language server:\n\n"+
+		"- main.go:4:2: undefined: foo [compiler]\n"+
+		"- main.go:7:1: missing return\n", prompt)
+}
@@ -0,0 +1,54 @@
+package diagnostics
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the diagnostics dialog.
+type KeyMap struct {
+	Next,
+	Previous,
+	Fix,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "ctrl+n", "j"),
+			key.WithHelp("↓", "next"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "ctrl+p", "k"),
+			key.WithHelp("↑", "previous"),
+		),
+		Fix: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "send to agent"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "close"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Fix,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return k.KeyBindings()
+}
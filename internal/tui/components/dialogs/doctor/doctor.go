@@ -0,0 +1,231 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/doctor"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/termcap"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const DoctorDialogID dialogs.DialogID = "doctor"
+
+// DoctorDialog shows the outcome of the checks of crush doctor.
+type DoctorDialog interface {
+	dialogs.DialogModel
+}
+
+type checkedMsg struct {
+	checks []doctor.Check
+}
+
+type doctorDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	lspClients *csync.Map[string, *lsp.Client]
+	checks     []doctor.Check
+	running    bool
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewDoctorDialogCmp creates the dialog running the checks, looking at the
+// state of the running MCP servers and of lspClients rather than starting
+// them again.
+func NewDoctorDialogCmp(lspClients *csync.Map[string, *lsp.Client]) DoctorDialog {
+	return &doctorDialogCmp{
+		lspClients: lspClients,
+		viewport:   viewport.New(),
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (d *doctorDialogCmp) Init() tea.Cmd {
+	return d.run()
+}
+
+// run runs the checks in the background.
+func (d *doctorDialogCmp) run() tea.Cmd {
+	d.running = true
+	caps := termcap.Get()
+	opts := doctor.Options{
+		MCP:      make(map[string]error),
+		LSP:      make(map[string]error),
+		Terminal: &caps,
+	}
+	for name, info := range mcp.GetStates() {
+		switch info.State {
+		case mcp.StateConnected:
+			opts.MCP[name] = nil
+		case mcp.StateError:
+			opts.MCP[name] = cmpError(info.Error, "the server failed to start")
+		}
+	}
+	if d.lspClients != nil {
+		for name, client := range d.lspClients.Seq2() {
+			switch client.GetServerState() {
+			case lsp.StateReady:
+				opts.LSP[name] = nil
+			case lsp.StateError:
+				opts.LSP[name] = errors.New("the server failed to start")
+			}
+		}
+	}
+	cfg := config.Get()
+	return func() tea.Msg {
+		return checkedMsg{checks: doctor.Run(context.Background(), cfg, opts)}
+	}
+}
+
+// cmpError returns err, or one with message when it's nil.
+func cmpError(err error, message string) error {
+	if err == nil {
+		return errors.New(message)
+	}
+	return err
+}
+
+func (d *doctorDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.resize()
+	case checkedMsg:
+		d.checks = msg.checks
+		d.running = false
+		d.resize()
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Rerun):
+			if d.running {
+				return d, nil
+			}
+			cmd := d.run()
+			d.resize()
+			return d, cmd
+		case key.Matches(msg, d.keyMap.Copy):
+			var b strings.Builder
+			doctor.Print(&b, d.checks)
+			return d, util.CopyToClipboard(b.String(), "Doctor report")
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// resize fits the dialog to the window and to the checks.
+func (d *doctorDialogCmp) resize() {
+	d.width = min(100, d.wWidth-4)
+	d.viewport.SetWidth(d.width - 4)
+	d.viewport.SetContent(d.content())
+	d.height = min(d.viewport.TotalLineCount()+6, max(10, d.wHeight*3/4))
+	d.viewport.SetHeight(d.height - 6) // border, title and help
+}
+
+// content renders the checks by category, with the fixes of the ones that
+// aren't OK.
+func (d *doctorDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	if d.running {
+		return t.S().Subtle.Render("Running the checks...")
+	}
+
+	icons := map[doctor.Status]string{
+		doctor.OK:   t.ItemOnlineIcon.String(),
+		doctor.Warn: t.S().Base.Foreground(t.Warning).Render("●"),
+		doctor.Fail: t.ItemErrorIcon.String(),
+		doctor.Skip: t.ItemOfflineIcon.String(),
+	}
+	var lines []string
+	for _, category := range doctor.Categories {
+		var rows []string
+		for _, check := range d.checks {
+			if check.Category != category {
+				continue
+			}
+			rows = append(rows, core.Status(core.StatusOpts{
+				Icon:        icons[check.Status],
+				Title:       check.Name,
+				Description: strings.ReplaceAll(check.Detail, "\n", " "),
+			}, width))
+			if check.Fix != "" {
+				rows = append(rows, t.S().Subtle.PaddingLeft(2).Width(width).Render(styles.ArrowRightIcon+" "+check.Fix))
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, t.S().Base.Foreground(t.Primary).Bold(true).Render(string(category)))
+		lines = append(lines, rows...)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (d *doctorDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Doctor"
+	if doctor.Failed(d.checks) {
+		title = "Doctor: some checks failed"
+	}
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *doctorDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *doctorDialogCmp) ID() dialogs.DialogID {
+	return DoctorDialogID
+}
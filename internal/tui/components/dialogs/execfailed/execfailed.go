@@ -0,0 +1,297 @@
+package execfailed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+	"mvdan.cc/sh/v3/shell"
+)
+
+const ExecFailedDialogID dialogs.DialogID = "exec_failed"
+
+const (
+	// maxRestarts is how many times in a row a tool is started again.
+	maxRestarts = 3
+	// restartDelay is the delay before the first restart, doubling after
+	// each one.
+	restartDelay = time.Second
+)
+
+// ExecFailedDialog shows why an external tool failed: its exit code, what
+// it wrote to stderr and what may fix it, offering to start it again.
+type ExecFailedDialog interface {
+	dialogs.DialogModel
+}
+
+type tickMsg struct{}
+
+type execFailedDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	err   *util.ExecError
+	early bool
+	hints []string
+
+	// restart starts the tool again, nil when it isn't offered. With a
+	// deadline, it's started again then.
+	restart  func() tea.Cmd
+	deadline time.Time
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewExecFailedDialogCmp creates the dialog for the command that failed to
+// start, with hints of the caller going first.
+func NewExecFailedDialogCmp(err *util.ExecError, hints ...string) ExecFailedDialog {
+	return newExecFailedDialogCmp(err, true, hints, nil, 0)
+}
+
+func newExecFailedDialogCmp(err *util.ExecError, early bool, hints []string, restart func() tea.Cmd, delay time.Duration) *execFailedDialogCmp {
+	keyMap := DefaultKeyMap()
+	keyMap.Restart.SetEnabled(restart != nil)
+	d := &execFailedDialogCmp{
+		err:      err,
+		early:    early,
+		hints:    append(hints, Hints(err)...),
+		restart:  restart,
+		viewport: viewport.New(),
+		keyMap:   keyMap,
+		help:     help.New(),
+	}
+	if delay > 0 {
+		d.deadline = time.Now().Add(delay)
+	}
+	return d
+}
+
+// Report returns the message reporting err from the callback of
+// util.ExecShell: the dialog when the command failed to start, an error in
+// the status bar otherwise.
+func Report(err error, hints ...string) tea.Msg {
+	var execErr *util.ExecError
+	if errors.As(err, &execErr) {
+		return dialogs.OpenDialogMsg{Model: NewExecFailedDialogCmp(execErr, hints...)}
+	}
+	return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+}
+
+// Run runs the command of the external tool with util.ExecRemoteShell,
+// reporting its failures with Report, and offering to start it again or
+// doing it after a growing delay when its restart policy says so.
+func Run(tool, command string, hints ...string) tea.Cmd {
+	return run(tool, command, hints, 0)
+}
+
+func run(tool, command string, hints []string, restarts int) tea.Cmd {
+	return util.ExecRemoteShell(context.TODO(), tool, command, func(err error) tea.Msg {
+		if err == nil {
+			return nil
+		}
+		policy := config.RestartNever
+		if cfg := config.Get(); cfg != nil && cfg.Options != nil && cfg.Options.TUI != nil {
+			policy = cfg.Options.TUI.ToolRestartFor(tool)
+		}
+		if policy == config.RestartNever || restarts >= maxRestarts {
+			return Report(err, hints...)
+		}
+
+		// Errors after running for a while aren't ExecErrors, what the
+		// tool wrote went to its screen.
+		var execErr *util.ExecError
+		early := errors.As(err, &execErr)
+		if !early {
+			execErr = &util.ExecError{Command: command, Err: err}
+		}
+		var delay time.Duration
+		if policy == config.RestartAlways {
+			delay = restartDelay << restarts
+		}
+		restart := func() tea.Cmd {
+			return run(tool, command, hints, restarts+1)
+		}
+		return dialogs.OpenDialogMsg{Model: newExecFailedDialogCmp(execErr, early, hints, restart, delay)}
+	})
+}
+
+// Hints guesses what may fix the command from its exit code and errors.
+func Hints(err *util.ExecError) []string {
+	name := "the command"
+	if fields, _ := shell.Fields(err.Command, nil); len(fields) > 0 {
+		name = filepath.Base(fields[0])
+	}
+	stderr := strings.ToLower(err.Stderr)
+	var hints []string
+	switch {
+	case err.ExitCode() == 127, strings.Contains(err.Err.Error(), "executable file not found"), strings.Contains(stderr, "command not found"):
+		hints = append(hints, fmt.Sprintf("Check %s is installed and in the $PATH Crush was started with.", name))
+	case err.ExitCode() == 126:
+		hints = append(hints, fmt.Sprintf("Check %s is executable.", name))
+	}
+	if strings.Contains(stderr, "not a git repository") {
+		hints = append(hints, "It needs a git repository: switch to the root of one, or pick it when asked.")
+	}
+	if strings.Contains(stderr, "gh auth login") || strings.Contains(stderr, "authentication") {
+		hints = append(hints, "Log gh in with gh auth login, or check options.github in the config.")
+	}
+	if strings.Contains(stderr, "config") && (strings.Contains(stderr, "yaml") || strings.Contains(stderr, "parse") || strings.Contains(stderr, "invalid")) {
+		hints = append(hints, "Fix the config file the error points to.")
+	}
+	return hints
+}
+
+func (d *execFailedDialogCmp) Init() tea.Cmd {
+	if d.deadline.IsZero() {
+		return nil
+	}
+	return d.tick()
+}
+
+func (d *execFailedDialogCmp) tick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return tickMsg{}
+	})
+}
+
+// startAgain closes the dialog and starts the tool again.
+func (d *execFailedDialogCmp) startAgain() tea.Cmd {
+	return tea.Sequence(
+		util.CmdHandler(dialogs.CloseDialogMsg{}),
+		d.restart(),
+	)
+}
+
+func (d *execFailedDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetContent(d.content())
+		d.height = min(d.viewport.TotalLineCount()+6, max(10, d.wHeight*3/4))
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.viewport.GotoBottom()
+	case tickMsg:
+		if d.deadline.IsZero() {
+			return d, nil
+		}
+		if !time.Now().Before(d.deadline) {
+			return d, d.startAgain()
+		}
+		return d, d.tick()
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Restart) && d.restart != nil:
+			return d, d.startAgain()
+		case key.Matches(msg, d.keyMap.Copy):
+			return d, util.CopyToClipboard(d.plain(), "Errors")
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// content renders the command, how it exited, the hints and its errors,
+// the end of which is in view first.
+func (d *execFailedDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	lines := []string{
+		t.S().Text.Width(width).Render("$ " + d.err.Command),
+		t.S().Error.Width(width).Render(d.status()),
+	}
+	if len(d.hints) > 0 {
+		lines = append(lines, "")
+		for _, hint := range d.hints {
+			lines = append(lines, t.S().Base.Foreground(t.Primary).Width(width).Render("• "+hint))
+		}
+	}
+	lines = append(lines, "")
+	if d.err.Stderr != "" {
+		lines = append(lines, t.S().Base.Width(width).Render(ansi.Strip(d.err.Stderr)))
+	} else if d.early {
+		lines = append(lines, t.S().Subtle.Width(width).Render("It wrote no errors."))
+	} else {
+		lines = append(lines, t.S().Subtle.Width(width).Render("What it wrote was on the screen it had."))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (d *execFailedDialogCmp) status() string {
+	code := d.err.ExitCode()
+	switch {
+	case code < 0:
+		return d.err.Err.Error()
+	case d.early:
+		return fmt.Sprintf("Exited with code %d right after starting", code)
+	}
+	return fmt.Sprintf("Exited with code %d", code)
+}
+
+// plain is what gets copied: the command, how it exited and its errors.
+func (d *execFailedDialogCmp) plain() string {
+	return fmt.Sprintf("$ %s\n%s\n\n%s\n", d.err.Command, d.status(), ansi.Strip(d.err.Stderr))
+}
+
+func (d *execFailedDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Command Failed"
+	if !d.deadline.IsZero() {
+		title = fmt.Sprintf("%s, Starting Again in %s", title, max(0, time.Until(d.deadline)).Round(time.Second))
+	}
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *execFailedDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *execFailedDialogCmp) ID() dialogs.DialogID {
+	return ExecFailedDialogID
+}
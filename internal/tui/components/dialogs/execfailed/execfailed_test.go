@@ -0,0 +1,59 @@
+package execfailed
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/crush/internal/uiutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHints(t *testing.T) {
+	t.Parallel()
+
+	missing := &util.ExecError{Command: "lazygit --debug", Err: exec.ErrNotFound}
+	require.Equal(t, []string{"Check lazygit is installed and in the $PATH Crush was started with."}, Hints(missing))
+	require.Equal(t, -1, missing.ExitCode())
+
+	err := exec.Command("sh", "-c", "exit 1").Run()
+	notRepo := &util.ExecError{Command: "gh dash", Err: err, Stderr: "fatal: not a git repository (or any of the parent directories): .git"}
+	require.Equal(t, []string{"It needs a git repository: switch to the root of one, or pick it when asked."}, Hints(notRepo))
+	require.Equal(t, 1, notRepo.ExitCode())
+	require.Equal(t, "exit status 1: fatal: not a git repository (or any of the parent directories): .git", notRepo.Error())
+}
+
+func TestNewExecError(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New("exit status 1")
+	var execErr *util.ExecError
+	require.ErrorAs(t, uiutil.NewExecError("gh dash", err, "boom\n", time.Second), &execErr)
+	require.Equal(t, "boom", execErr.Stderr)
+
+	require.Same(t, err, uiutil.NewExecError("gh dash", err, "boom\n", time.Minute), "failures after running a while are left as they are")
+	require.NoError(t, uiutil.NewExecError("gh dash", nil, "", time.Second))
+}
+
+func TestRestart(t *testing.T) {
+	t.Parallel()
+
+	err := &util.ExecError{Command: "gh dash", Err: errors.New("exit status 1")}
+	require.False(t, newExecFailedDialogCmp(err, true, nil, nil, 0).keyMap.Restart.Enabled(), "restarting isn't offered without restart")
+
+	var restarted bool
+	d := newExecFailedDialogCmp(err, false, nil, func() tea.Cmd {
+		restarted = true
+		return nil
+	}, time.Millisecond)
+	require.True(t, d.keyMap.Restart.Enabled())
+	require.NotNil(t, d.Init(), "it counts down to the restart")
+
+	time.Sleep(time.Millisecond)
+	_, cmd := d.Update(tickMsg{})
+	require.NotNil(t, cmd)
+	require.True(t, restarted, "it restarts once the delay passed")
+}
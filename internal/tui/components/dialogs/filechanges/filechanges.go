@@ -0,0 +1,164 @@
+package filechanges
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	FileChangesDialogID dialogs.DialogID = "file_changes"
+
+	// listHeight is the number of files listed at most.
+	listHeight = 10
+	// maxAttachmentSize matches the limit of the editor attachments.
+	maxAttachmentSize = 5 * 1024 * 1024
+)
+
+// NotifyPrompt builds the message telling the agent which files changed
+// outside Crush.
+func NotifyPrompt(paths []string) string {
+	var sb strings.Builder
+	sb.WriteString("These files were changed outside Crush since you last read them, so your copy is out of date:\n\n")
+	for _, path := range paths {
+		fmt.Fprintf(&sb, "- %s\n", path)
+	}
+	sb.WriteString("\nRead them again before relying on their content or editing them.")
+	return sb.String()
+}
+
+// FileChangesDialog lists the files changed outside Crush and offers to
+// bring the agent up to date.
+type FileChangesDialog interface {
+	dialogs.DialogModel
+}
+
+type fileChangesDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	paths []string
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewFileChangesDialogCmp creates the dialog for the changed files.
+func NewFileChangesDialogCmp(paths []string) FileChangesDialog {
+	return &fileChangesDialogCmp{
+		paths:  paths,
+		keyMap: DefaultKeyMap(),
+		help:   help.New(),
+	}
+}
+
+func (f *fileChangesDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (f *fileChangesDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		f.wWidth = msg.Width
+		f.wHeight = msg.Height
+		f.width = min(80, f.wWidth-4)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, f.keyMap.Notify):
+			return f, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.CmdHandler(commands.CommandRunCustomMsg{Content: NotifyPrompt(f.paths)}),
+			)
+		case key.Matches(msg, f.keyMap.Refresh):
+			cmds := []tea.Cmd{util.CmdHandler(dialogs.CloseDialogMsg{})}
+			for _, path := range f.paths {
+				cmds = append(cmds, attach(path))
+			}
+			return f, tea.Sequence(cmds...)
+		case key.Matches(msg, f.keyMap.Close):
+			return f, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return f, nil
+}
+
+// attach adds the current content of the file to the next message,
+// replacing an older attachment of the same file.
+func attach(path string) tea.Cmd {
+	return func() tea.Msg {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		if len(content) > maxAttachmentSize {
+			return util.InfoMsg{Type: util.InfoTypeWarn, Msg: fmt.Sprintf("%s is too big to attach (>5mb)", filepath.Base(path))}
+		}
+		return filepicker.FilePickedMsg{
+			Attachment: message.Attachment{
+				FilePath: path,
+				FileName: filepath.Base(path),
+				MimeType: http.DetectContentType(content[:min(512, len(content))]),
+				Content:  content,
+			},
+		}
+	}
+}
+
+func (f *fileChangesDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := f.width - 4
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Files Changed Outside Crush", width))
+	body := []string{
+		t.S().Text.Width(width).Render("The agent's copy of these files is out of date. Tell it to read them again, or attach their new content to your next message."),
+		"",
+	}
+	for i, path := range f.paths {
+		if i == listHeight {
+			body = append(body, t.S().Subtle.Render(fmt.Sprintf("… and %d more", len(f.paths)-i)))
+			break
+		}
+		line := t.S().Base.Foreground(t.Warning).Render("●") + " " + t.S().Text.Render(fsext.PrettyPath(path))
+		body = append(body, ansi.Truncate(line, width, "…"))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(f.width-2).PaddingLeft(1).Render(f.help.View(f.keyMap)),
+	)
+	return t.S().Base.
+		Width(f.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (f *fileChangesDialogCmp) Position() (int, int) {
+	row := f.wHeight/4 - 2 // just a bit above the center
+	col := f.wWidth / 2
+	col -= f.width / 2
+	return max(0, row), col
+}
+
+func (f *fileChangesDialogCmp) ID() dialogs.DialogID {
+	return FileChangesDialogID
+}
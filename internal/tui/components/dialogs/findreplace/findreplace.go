@@ -0,0 +1,388 @@
+package findreplace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/replace"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	FindReplaceDialogID dialogs.DialogID = "find_replace"
+
+	// listHeight is the number of files listed at once.
+	listHeight = 10
+	// previewLines is the number of changed lines shown for the selected
+	// file.
+	previewLines = 6
+	// labelWidth is the width of the labels in front of the inputs.
+	labelWidth = 9
+)
+
+// Focus targets, in tab order.
+const (
+	focusFind = iota
+	focusReplace
+	focusFiles
+	focusList
+)
+
+// FindReplaceDialog replaces text across the workspace after previewing
+// every change, letting the user skip files.
+type FindReplaceDialog interface {
+	dialogs.DialogModel
+}
+
+type previewMsg struct {
+	changes []replace.FileChange
+	err     error
+}
+
+type findReplaceDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	workingDir string
+
+	inputs     [3]textinput.Model
+	focus      int
+	regex      bool
+	ignoreCase bool
+
+	searching bool
+	err       error
+	changes   []replace.FileChange
+	// skipped holds the indexes of the files the user opted out of.
+	skipped  map[int]bool
+	selected int
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewFindReplaceDialogCmp creates the find and replace dialog for the
+// workspace at workingDir.
+func NewFindReplaceDialogCmp(workingDir string) FindReplaceDialog {
+	t := styles.CurrentTheme()
+	placeholders := [3]string{
+		"Text or regex to find",
+		"Replacement",
+		"Files, e.g. *.go, internal/**, !*_test.go",
+	}
+	var inputs [3]textinput.Model
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].SetVirtualCursor(false)
+		inputs[i].Placeholder = placeholders[i]
+		inputs[i].SetStyles(t.S().TextInput)
+	}
+	inputs[focusFind].Focus()
+
+	return &findReplaceDialogCmp{
+		workingDir: workingDir,
+		inputs:     inputs,
+		skipped:    map[int]bool{},
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (f *findReplaceDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (f *findReplaceDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		f.wWidth = msg.Width
+		f.wHeight = msg.Height
+		f.width = min(100, f.wWidth-4)
+		for i := range f.inputs {
+			f.inputs[i].SetWidth(f.width - 6 - labelWidth)
+		}
+	case previewMsg:
+		f.searching = false
+		f.changes, f.err = msg.changes, msg.err
+		if f.changes == nil && f.err == nil {
+			f.changes = []replace.FileChange{} // Searched, but nothing matched.
+		}
+		f.skipped = map[int]bool{}
+		f.selected = 0
+		if len(f.changes) > 0 {
+			f.setFocus(focusList)
+		}
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, f.keyMap.Close):
+			return f, util.CmdHandler(dialogs.CloseDialogMsg{})
+		case key.Matches(msg, f.keyMap.NextField):
+			next := (f.focus + 1) % (focusList + 1)
+			if next == focusList && len(f.changes) == 0 {
+				next = focusFind
+			}
+			f.setFocus(next)
+		case key.Matches(msg, f.keyMap.ToggleRegex):
+			f.regex = !f.regex
+			return f, f.preview()
+		case key.Matches(msg, f.keyMap.ToggleCase):
+			f.ignoreCase = !f.ignoreCase
+			return f, f.preview()
+		case key.Matches(msg, f.keyMap.Apply):
+			return f, f.apply()
+		case key.Matches(msg, f.keyMap.Preview) && f.focus != focusList:
+			return f, f.preview()
+		case f.focus == focusList:
+			switch {
+			case key.Matches(msg, f.keyMap.Next):
+				f.selected = min(f.selected+1, len(f.changes)-1)
+			case key.Matches(msg, f.keyMap.Previous):
+				f.selected = max(f.selected-1, 0)
+			case key.Matches(msg, f.keyMap.Toggle):
+				f.skipped[f.selected] = !f.skipped[f.selected]
+			}
+		default:
+			return f, f.updateInput(msg)
+		}
+	case tea.PasteMsg:
+		if f.focus == focusList {
+			return f, nil
+		}
+		return f, f.updateInput(msg)
+	}
+	return f, nil
+}
+
+// updateInput passes the message to the focused input. Editing any of them
+// discards the preview, so what's applied is always what was shown.
+func (f *findReplaceDialogCmp) updateInput(msg tea.Msg) tea.Cmd {
+	value := f.inputs[f.focus].Value()
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	if f.inputs[f.focus].Value() != value {
+		f.changes, f.err = nil, nil
+	}
+	return cmd
+}
+
+func (f *findReplaceDialogCmp) setFocus(focus int) {
+	f.focus = focus
+	for i := range f.inputs {
+		if i == focus {
+			f.inputs[i].Focus()
+		} else {
+			f.inputs[i].Blur()
+		}
+	}
+}
+
+func (f *findReplaceDialogCmp) options() replace.Options {
+	opts := replace.Options{
+		Pattern:     f.inputs[focusFind].Value(),
+		Replacement: f.inputs[focusReplace].Value(),
+		Regex:       f.regex,
+		IgnoreCase:  f.ignoreCase,
+	}
+	for _, pattern := range strings.FieldsFunc(f.inputs[focusFiles].Value(), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if exclude, ok := strings.CutPrefix(pattern, "!"); ok {
+			opts.Exclude = append(opts.Exclude, exclude)
+		} else {
+			opts.Include = append(opts.Include, pattern)
+		}
+	}
+	return opts
+}
+
+func (f *findReplaceDialogCmp) preview() tea.Cmd {
+	opts := f.options()
+	if opts.Pattern == "" || f.searching {
+		return nil
+	}
+	f.searching = true
+	workingDir := f.workingDir
+	return func() tea.Msg {
+		changes, err := replace.Find(context.Background(), workingDir, opts)
+		return previewMsg{changes: changes, err: err}
+	}
+}
+
+func (f *findReplaceDialogCmp) apply() tea.Cmd {
+	var selected []replace.FileChange
+	count := 0
+	for i, c := range f.changes {
+		if !f.skipped[i] {
+			selected = append(selected, c)
+			count += c.Count
+		}
+	}
+	if len(selected) == 0 {
+		return util.ReportWarn("No files to change, preview the replacement first")
+	}
+	return tea.Sequence(
+		util.CmdHandler(dialogs.CloseDialogMsg{}),
+		func() tea.Msg {
+			written, err := replace.Apply(selected)
+			if err != nil {
+				return util.InfoMsg{
+					Type: util.InfoTypeError,
+					Msg:  fmt.Sprintf("Changed %d of %d files: %v", len(written), len(selected), err),
+				}
+			}
+			return util.InfoMsg{
+				Type: util.InfoTypeInfo,
+				Msg:  fmt.Sprintf("Replaced %d occurrence(s) in %d file(s)", count, len(written)),
+			}
+		},
+	)
+}
+
+func (f *findReplaceDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := f.width - 4
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Find and Replace", width))
+	labels := [3]string{"Find", "Replace", "Files"}
+	var fields []string
+	for i, input := range f.inputs {
+		label := t.S().Muted.Width(labelWidth).Render(labels[i])
+		if i == f.focus {
+			label = t.S().Base.Foreground(t.Primary).Width(labelWidth).Render(labels[i])
+		}
+		fields = append(fields, label+input.View())
+	}
+	fields = append(fields, f.optionsView())
+
+	var body []string
+	switch {
+	case f.searching:
+		body = append(body, t.S().Subtle.Render("Searching…"))
+	case f.err != nil:
+		body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render(f.err.Error()))
+	case f.changes == nil:
+		body = append(body, t.S().Subtle.Render("Press enter to preview the changes."))
+	case len(f.changes) == 0:
+		body = append(body, t.S().Subtle.Render("No matches."))
+	default:
+		body = append(body, f.listView(width)...)
+		body = append(body, "", f.matchesView(f.changes[f.selected], width))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, fields...)),
+		"",
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(f.width-2).PaddingLeft(1).Render(f.help.View(f.keyMap)),
+	)
+	return t.S().Base.
+		Width(f.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (f *findReplaceDialogCmp) optionsView() string {
+	t := styles.CurrentTheme()
+	option := func(name string, on bool) string {
+		if on {
+			return t.S().Base.Foreground(t.Success).Render("● " + name)
+		}
+		return t.S().Subtle.Render("○ " + name)
+	}
+	return strings.Repeat(" ", labelWidth) + option("regex", f.regex) + "  " + option("ignore case", f.ignoreCase)
+}
+
+// listView renders the files with a match, scrolled to keep the selected
+// one visible.
+func (f *findReplaceDialogCmp) listView(width int) []string {
+	t := styles.CurrentTheme()
+	files, count := 0, 0
+	for i, c := range f.changes {
+		if !f.skipped[i] {
+			files++
+			count += c.Count
+		}
+	}
+	lines := []string{t.S().Subtle.Render(fmt.Sprintf("%d replacement(s) in %d of %d file(s)", count, files, len(f.changes)))}
+
+	start := max(0, min(f.selected-listHeight/2, len(f.changes)-listHeight))
+	for i := start; i < min(start+listHeight, len(f.changes)); i++ {
+		c := f.changes[i]
+		check := t.S().Base.Foreground(t.Success).Render("[x]")
+		if f.skipped[i] {
+			check = t.S().Subtle.Render("[ ]")
+		}
+		name := fsext.PrettyPath(c.Path)
+		switch {
+		case i == f.selected && f.focus == focusList:
+			name = t.S().Base.Foreground(t.Primary).Bold(true).Render(name)
+		case f.skipped[i]:
+			name = t.S().Subtle.Render(name)
+		default:
+			name = t.S().Text.Render(name)
+		}
+		line := check + " " + name + " " + t.S().Muted.Render(fmt.Sprintf("(%d)", c.Count))
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lines
+}
+
+// matchesView renders the changed lines of a file.
+func (f *findReplaceDialogCmp) matchesView(c replace.FileChange, width int) string {
+	t := styles.CurrentTheme()
+	var lines []string
+	for i, m := range c.Matches {
+		if i == previewLines {
+			lines = append(lines, t.S().Subtle.Render(fmt.Sprintf("… %d more", len(c.Matches)-i)))
+			break
+		}
+		number := t.S().Subtle.Render(fmt.Sprintf("%5d ", m.Line))
+		before := strings.ReplaceAll(strings.ReplaceAll(m.Before, "\t", "  "), "\n", "⏎")
+		after := strings.ReplaceAll(strings.ReplaceAll(m.After, "\t", "  "), "\n", "⏎")
+		lines = append(lines,
+			ansi.Truncate(number+t.S().Base.Foreground(t.Error).Render("- "+before), width, "…"),
+			ansi.Truncate(number+t.S().Base.Foreground(t.Success).Render("+ "+after), width, "…"),
+		)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (f *findReplaceDialogCmp) Cursor() *tea.Cursor {
+	if f.focus == focusList {
+		return nil
+	}
+	cursor := f.inputs[f.focus].Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := f.Position()
+	cursor.Y += row + 3 + f.focus    // border, title and padding, then the fields above
+	cursor.X += col + 2 + labelWidth // border, padding and label
+	return cursor
+}
+
+func (f *findReplaceDialogCmp) Position() (int, int) {
+	row := f.wHeight/4 - 2 // just a bit above the center
+	col := f.wWidth / 2
+	col -= f.width / 2
+	return max(0, row), col
+}
+
+func (f *findReplaceDialogCmp) ID() dialogs.DialogID {
+	return FindReplaceDialogID
+}
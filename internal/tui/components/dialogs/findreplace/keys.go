@@ -0,0 +1,91 @@
+package findreplace
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the find and replace dialog.
+type KeyMap struct {
+	NextField,
+	Next,
+	Previous,
+	Preview,
+	Toggle,
+	ToggleRegex,
+	ToggleCase,
+	Apply,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		NextField: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next field"),
+		),
+		Next: key.NewBinding(
+			key.WithKeys("down", "ctrl+n"),
+			key.WithHelp("↓", "next file"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "ctrl+p"),
+			key.WithHelp("↑", "previous file"),
+		),
+		Preview: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "preview"),
+		),
+		Toggle: key.NewBinding(
+			key.WithKeys("space"),
+			key.WithHelp("space", "include/skip file"),
+		),
+		ToggleRegex: key.NewBinding(
+			key.WithKeys("alt+r"),
+			key.WithHelp("alt+r", "regex"),
+		),
+		ToggleCase: key.NewBinding(
+			key.WithKeys("alt+c"),
+			key.WithHelp("alt+c", "ignore case"),
+		),
+		Apply: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "apply"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.NextField,
+		k.Next,
+		k.Previous,
+		k.Preview,
+		k.Toggle,
+		k.ToggleRegex,
+		k.ToggleCase,
+		k.Apply,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Preview,
+		k.Toggle,
+		k.ToggleRegex,
+		k.ToggleCase,
+		k.Apply,
+		k.Close,
+	}
+}
@@ -0,0 +1,131 @@
+package ghlogin
+
+import (
+	"context"
+	"fmt"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/gh"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/execfailed"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const GitHubLoginDialogID dialogs.DialogID = "ghlogin"
+
+// GitHubLoginDialog tells gh isn't logged in and offers to run gh auth
+// login, opening what needed it once logged in.
+type GitHubLoginDialog interface {
+	dialogs.DialogModel
+}
+
+type gitHubLoginDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	err  error
+	then tea.Msg
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewGitHubLoginDialogCmp creates the dialog for the error of
+// gh.AuthStatus, sending then once logged in.
+func NewGitHubLoginDialogCmp(err error, then tea.Msg) GitHubLoginDialog {
+	return &gitHubLoginDialogCmp{
+		err:    err,
+		then:   then,
+		keyMap: DefaultKeyMap(),
+		help:   help.New(),
+	}
+}
+
+// Check checks gh is logged in before sending then, opening the dialog
+// instead when it isn't.
+func Check(dir string, then tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		if err := gh.AuthStatus(context.Background(), dir); err != nil {
+			return dialogs.OpenDialogMsg{Model: NewGitHubLoginDialogCmp(err, then)}
+		}
+		return then
+	}
+}
+
+func (d *gitHubLoginDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *gitHubLoginDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(80, d.wWidth-4)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Login):
+			then := d.then
+			return d, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.ExecRemoteShell(context.TODO(), config.ExternalToolGHDash, gh.LoginCommand(), func(err error) tea.Msg {
+					if err != nil {
+						return execfailed.Report(fmt.Errorf("gh auth login failed: %w", err))
+					}
+					return then
+				}),
+			)
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return d, nil
+}
+
+func (d *gitHubLoginDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	account := gh.CurrentAccount()
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("GitHub Login", d.width-4))
+
+	body := []string{
+		t.S().Text.Width(d.width - 4).Render(fmt.Sprintf("gh needs to be logged in to %s for this.", account)),
+		"",
+		t.S().Subtle.Width(d.width - 4).Render(d.err.Error()),
+		"",
+	}
+	hint := "Log in with gh auth login, then it opens again."
+	if account.User != "" {
+		hint = fmt.Sprintf("Log in as %s with gh auth login, or set another user in options.github of the config.", account.User)
+	}
+	body = append(body, t.S().Text.Width(d.width-4).Render(hint))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *gitHubLoginDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *gitHubLoginDialogCmp) ID() dialogs.DialogID {
+	return GitHubLoginDialogID
+}
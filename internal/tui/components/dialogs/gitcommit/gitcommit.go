@@ -0,0 +1,263 @@
+package gitcommit
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textarea"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/commit"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const (
+	CommitDialogID dialogs.DialogID = "git_commit"
+
+	// messageHeight is the number of lines of the message editor.
+	messageHeight = 8
+)
+
+// CommitDialog shows the commit message drafted for the staged changes,
+// lets the user edit it and commits on confirm.
+type CommitDialog interface {
+	dialogs.DialogModel
+}
+
+type (
+	draftedMsg struct {
+		message string
+		err     error
+	}
+	editedMsg struct {
+		message string
+	}
+	committedMsg struct {
+		summary string
+		err     error
+	}
+)
+
+type commitDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	workingDir string
+	generate   func(context.Context) (string, error)
+	cancel     context.CancelFunc
+
+	message    textarea.Model
+	drafting   bool
+	committing bool
+	err        error
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewCommitDialogCmp creates the commit dialog for the repository at
+// workingDir, drafting the message with generate.
+func NewCommitDialogCmp(workingDir string, generate func(context.Context) (string, error)) CommitDialog {
+	t := styles.CurrentTheme()
+	ta := textarea.New()
+	ta.SetStyles(t.S().TextArea)
+	ta.ShowLineNumbers = false
+	ta.CharLimit = -1
+	ta.SetVirtualCursor(false)
+	ta.SetHeight(messageHeight)
+	ta.Placeholder = "Commit message"
+	ta.Focus()
+
+	return &commitDialogCmp{
+		workingDir: workingDir,
+		generate:   generate,
+		message:    ta,
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (c *commitDialogCmp) Init() tea.Cmd {
+	return c.draft()
+}
+
+// draft asks for a new message, dropping any draft still in progress.
+func (c *commitDialogCmp) draft() tea.Cmd {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.drafting = true
+	c.err = nil
+	return func() tea.Msg {
+		message, err := c.generate(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return draftedMsg{message: message, err: err}
+	}
+}
+
+func (c *commitDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.wWidth = msg.Width
+		c.wHeight = msg.Height
+		c.width = min(80, c.wWidth-4)
+		c.message.SetWidth(c.width - 4)
+	case draftedMsg:
+		c.drafting = false
+		c.err = msg.err
+		if msg.err == nil {
+			c.message.SetValue(msg.message)
+			c.message.MoveToBegin()
+		}
+	case editedMsg:
+		c.message.SetValue(msg.message)
+		c.message.MoveToBegin()
+	case committedMsg:
+		c.committing = false
+		if msg.err != nil {
+			c.err = msg.err
+			return c, nil
+		}
+		return c, tea.Sequence(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			util.ReportInfo(msg.summary),
+		)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, c.keyMap.Close):
+			if c.cancel != nil {
+				c.cancel()
+			}
+			return c, util.CmdHandler(dialogs.CloseDialogMsg{})
+		case key.Matches(msg, c.keyMap.Commit):
+			return c, c.commit()
+		case key.Matches(msg, c.keyMap.Regenerate):
+			if c.committing {
+				return c, nil
+			}
+			return c, c.draft()
+		case key.Matches(msg, c.keyMap.OpenEditor):
+			if c.drafting || c.committing {
+				return c, nil
+			}
+			return c, openEditor(c.message.Value())
+		}
+		if c.drafting || c.committing {
+			return c, nil
+		}
+		var cmd tea.Cmd
+		c.message, cmd = c.message.Update(msg)
+		return c, cmd
+	case tea.PasteMsg:
+		var cmd tea.Cmd
+		c.message, cmd = c.message.Update(msg)
+		return c, cmd
+	}
+	return c, nil
+}
+
+func (c *commitDialogCmp) commit() tea.Cmd {
+	message := strings.TrimSpace(c.message.Value())
+	if c.drafting || c.committing || message == "" {
+		return nil
+	}
+	c.committing = true
+	c.err = nil
+	workingDir := c.workingDir
+	return func() tea.Msg {
+		summary, err := commit.Commit(context.Background(), workingDir, message+"\n")
+		return committedMsg{summary: summary, err: err}
+	}
+}
+
+// openEditor lets the user edit the message in the editor.
+func openEditor(value string) tea.Cmd {
+	editor := util.Editor()
+
+	tmpfile, err := os.CreateTemp("", "COMMIT_EDITMSG_*")
+	if err != nil {
+		return util.ReportError(err)
+	}
+	defer tmpfile.Close() //nolint:errcheck
+	if _, err := tmpfile.WriteString(value); err != nil {
+		return util.ReportError(err)
+	}
+	return util.ExecShell(context.TODO(), config.ExternalToolEditor, editor+" "+tmpfile.Name(), func(err error) tea.Msg {
+		defer os.Remove(tmpfile.Name())
+		if err != nil {
+			return util.ReportError(err)
+		}
+		content, err := os.ReadFile(tmpfile.Name())
+		if err != nil {
+			return util.ReportError(err)
+		}
+		return editedMsg{message: strings.TrimSpace(string(content))}
+	})
+}
+
+func (c *commitDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Commit Staged Changes", c.width-4))
+
+	var status string
+	switch {
+	case c.drafting:
+		status = t.S().Subtle.Render("Drafting a message from the staged changes…")
+	case c.committing:
+		status = t.S().Subtle.Render("Committing…")
+	case c.err != nil:
+		status = t.S().Base.Foreground(t.Error).Width(c.width - 4).Render(c.err.Error())
+	}
+
+	parts := []string{
+		header,
+		t.S().Base.PaddingLeft(1).Render(c.message.View()),
+	}
+	if status != "" {
+		parts = append(parts, "", t.S().Base.PaddingLeft(1).Render(status))
+	}
+	parts = append(parts, "", t.S().Base.Width(c.width-2).PaddingLeft(1).Render(c.help.View(c.keyMap)))
+
+	return t.S().Base.
+		Width(c.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+func (c *commitDialogCmp) Cursor() *tea.Cursor {
+	if c.drafting || c.committing {
+		return nil
+	}
+	cursor := c.message.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := c.Position()
+	cursor.Y += row + 3 // border, title and padding
+	cursor.X += col + 2 // border and padding
+	return cursor
+}
+
+func (c *commitDialogCmp) Position() (int, int) {
+	row := c.wHeight/4 - 2 // just a bit above the center
+	col := c.wWidth / 2
+	col -= c.width / 2
+	return max(0, row), col
+}
+
+func (c *commitDialogCmp) ID() dialogs.DialogID {
+	return CommitDialogID
+}
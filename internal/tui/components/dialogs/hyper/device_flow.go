@@ -200,10 +200,7 @@ func (d *DeviceFlow) CopyCode() tea.Cmd {
 	if d.State != DeviceFlowStateDisplay {
 		return nil
 	}
-	return tea.Sequence(
-		tea.SetClipboard(d.userCode),
-		util.ReportInfo("Code copied to clipboard"),
-	)
+	return util.CopyToClipboard(d.userCode, "Code")
 }
 
 // Cancel cancels the device flow polling.
@@ -0,0 +1,158 @@
+package ignorerules
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const IgnoreRulesDialogID dialogs.DialogID = "ignore_rules"
+
+// IgnoreRulesDialog shows the .crushignore rules keeping paths away from the
+// agent.
+type IgnoreRulesDialog interface {
+	dialogs.DialogModel
+}
+
+type ignoreRulesDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	rules []fsext.IgnoreRule
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewIgnoreRulesDialogCmp creates the viewer of the rules of the .crushignore
+// files of the workspace and of ~/.config/crush/ignore.
+func NewIgnoreRulesDialogCmp() IgnoreRulesDialog {
+	d := &ignoreRulesDialogCmp{
+		viewport: viewport.New(),
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+	if cfg := config.Get(); cfg != nil {
+		d.rules = fsext.CrushIgnoreRules(cfg.Roots()...)
+	}
+	return d
+}
+
+func (d *ignoreRulesDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *ignoreRulesDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.viewport.SetContent(d.content())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Copy):
+			return d, util.CopyToClipboard(d.plain(), "Ignore rules")
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// content renders the rules, under the file each one is in.
+func (d *ignoreRulesDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+
+	var lines []string
+	if len(d.rules) == 0 {
+		lines = append(lines, t.S().Subtle.Render("No .crushignore rules. Add a .crushignore file, in gitignore syntax, to keep paths away from the agent."))
+	}
+	file := ""
+	for _, rule := range d.rules {
+		if rule.File != file {
+			if file != "" {
+				lines = append(lines, "")
+			}
+			file = rule.File
+			lines = append(lines, t.S().Base.Foreground(t.Primary).Bold(true).Render(home.Short(file)))
+		}
+		lines = append(lines, t.S().Text.Width(width).Render(fmt.Sprintf(
+			"%s %s",
+			t.S().Subtle.Render(fmt.Sprintf("%4d", rule.Line)),
+			rule.Pattern,
+		)))
+	}
+	lines = append(lines, "", t.S().Subtle.Width(width).Render(
+		"These paths are left out of the file tools, the file picker, the semantic index and the context files. "+
+			"Paths in .gitignore files are also left out of listings and searches, but the agent can still read them.",
+	))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// plain is what gets copied: the rules, as text.
+func (d *ignoreRulesDialogCmp) plain() string {
+	var b strings.Builder
+	for _, rule := range d.rules {
+		fmt.Fprintf(&b, "%s:%d: %s\n", rule.File, rule.Line, rule.Pattern)
+	}
+	return b.String()
+}
+
+func (d *ignoreRulesDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Ignore Rules"
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *ignoreRulesDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *ignoreRulesDialogCmp) ID() dialogs.DialogID {
+	return IgnoreRulesDialogID
+}
@@ -0,0 +1,280 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/issues"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pkg/browser"
+)
+
+const (
+	IssuesDialogID dialogs.DialogID = "issues"
+
+	// listHeight is the number of issues listed at once.
+	listHeight = 10
+	// previewLines is the number of lines of the selected issue shown.
+	previewLines = 6
+	// maxAttachmentSize matches the limit of the editor attachments.
+	maxAttachmentSize = 5 * 1024 * 1024
+)
+
+// IssuesDialog lists the open issues of the repository and starts a session
+// working on the chosen one.
+type IssuesDialog interface {
+	dialogs.DialogModel
+}
+
+type listedMsg struct {
+	issues []issues.Issue
+	err    error
+}
+
+type issuesDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	workingDir string
+	loading    bool
+	err        error
+	all        []issues.Issue
+
+	input    textinput.Model
+	matches  []issues.Issue
+	selected int
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewIssuesDialogCmp creates the issue browser for the repository at
+// workingDir.
+func NewIssuesDialogCmp(workingDir string) IssuesDialog {
+	t := styles.CurrentTheme()
+	input := textinput.New()
+	input.SetVirtualCursor(false)
+	input.Placeholder = "Type to filter issues"
+	input.SetStyles(t.S().TextInput)
+	input.Focus()
+
+	return &issuesDialogCmp{
+		workingDir: workingDir,
+		loading:    true,
+		input:      input,
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (d *issuesDialogCmp) Init() tea.Cmd {
+	workingDir := d.workingDir
+	return func() tea.Msg {
+		list, err := issues.List(context.Background(), workingDir)
+		return listedMsg{issues: list, err: err}
+	}
+}
+
+func (d *issuesDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+		d.input.SetWidth(d.width - 6)
+	case listedMsg:
+		d.loading = false
+		d.all, d.err = msg.issues, msg.err
+		d.filter()
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Next):
+			d.selected = min(d.selected+1, max(0, len(d.matches)-1))
+		case key.Matches(msg, d.keyMap.Previous):
+			d.selected = max(d.selected-1, 0)
+		case key.Matches(msg, d.keyMap.OpenBrowser):
+			if len(d.matches) == 0 {
+				return d, nil
+			}
+			url := d.matches[d.selected].URL
+			return d, func() tea.Msg {
+				if err := browser.OpenURL(url); err != nil {
+					return util.ReportError(fmt.Errorf("failed to open browser: %w", err))()
+				}
+				return nil
+			}
+		case key.Matches(msg, d.keyMap.Work):
+			if len(d.matches) == 0 {
+				return d, nil
+			}
+			return d, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				workOn(d.workingDir, d.matches[d.selected]),
+			)
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		default:
+			var cmd tea.Cmd
+			d.input, cmd = d.input.Update(msg)
+			d.filter()
+			return d, cmd
+		}
+	case tea.PasteMsg:
+		var cmd tea.Cmd
+		d.input, cmd = d.input.Update(msg)
+		d.filter()
+		return d, cmd
+	}
+	return d, nil
+}
+
+// workOn starts a session seeded with the issue, attaching the files it
+// mentions.
+func workOn(workingDir string, issue issues.Issue) tea.Cmd {
+	return func() tea.Msg {
+		var attachments []message.Attachment
+		for _, path := range issues.LinkedFiles(workingDir, issue) {
+			content, err := os.ReadFile(path)
+			if err != nil || len(content) > maxAttachmentSize {
+				continue
+			}
+			attachments = append(attachments, message.Attachment{
+				FilePath: path,
+				FileName: filepath.Base(path),
+				MimeType: http.DetectContentType(content[:min(512, len(content))]),
+				Content:  content,
+			})
+		}
+		return commands.StartSessionMsg{
+			Content:     issues.Prompt(issue),
+			Attachments: attachments,
+		}
+	}
+}
+
+func (d *issuesDialogCmp) filter() {
+	query := strings.ToLower(strings.TrimSpace(d.input.Value()))
+	d.matches = d.matches[:0]
+	for _, issue := range d.all {
+		haystack := strings.ToLower("#" + strconv.Itoa(issue.Number) + " " + issue.Title + " " + strings.Join(issue.Labels, " "))
+		if strings.Contains(haystack, query) {
+			d.matches = append(d.matches, issue)
+		}
+	}
+	d.selected = 0
+}
+
+func (d *issuesDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Issues", width))
+	input := t.S().Base.Padding(0, 1, 1, 1).Render(d.input.View())
+
+	var body []string
+	switch {
+	case d.loading:
+		body = append(body, t.S().Subtle.Render("Fetching the open issues…"))
+	case d.err != nil:
+		body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render(d.err.Error()))
+	case len(d.all) == 0:
+		body = append(body, t.S().Subtle.Render("No open issues."))
+	case len(d.matches) == 0:
+		body = append(body, t.S().Subtle.Render("No matching issues."))
+	default:
+		body = append(body, d.listView(width)...)
+		body = append(body, "", d.previewView(d.matches[d.selected], width))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		input,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+// listView renders the matching issues, scrolled to keep the selected one
+// visible.
+func (d *issuesDialogCmp) listView(width int) []string {
+	t := styles.CurrentTheme()
+	var lines []string
+	start := max(0, min(d.selected-listHeight/2, len(d.matches)-listHeight))
+	for i := start; i < min(start+listHeight, len(d.matches)); i++ {
+		issue := d.matches[i]
+		title := t.S().Text.Render(issue.Title)
+		if i == d.selected {
+			title = t.S().Base.Foreground(t.Primary).Bold(true).Render(issue.Title)
+		}
+		line := t.S().Muted.Render(fmt.Sprintf("#%-5d", issue.Number)) + " " + title
+		if len(issue.Labels) > 0 {
+			line += " " + t.S().Subtle.Render(strings.Join(issue.Labels, ", "))
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lines
+}
+
+// previewView renders the start of the issue body.
+func (d *issuesDialogCmp) previewView(issue issues.Issue, width int) string {
+	t := styles.CurrentTheme()
+	lines := []string{t.S().Subtle.Render("@" + issue.Author)}
+	body := strings.TrimSpace(issue.Body)
+	if body == "" {
+		body = "No description."
+	}
+	wrapped := strings.Split(ansi.Wrap(body, width, ""), "\n")
+	for i, line := range wrapped {
+		if i == previewLines {
+			lines = append(lines, t.S().Subtle.Render("…"))
+			break
+		}
+		lines = append(lines, t.S().Text.Render(line))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (d *issuesDialogCmp) Cursor() *tea.Cursor {
+	cursor := d.input.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := d.Position()
+	cursor.Y += row + 3 // border, title and padding
+	cursor.X += col + 2 // border and padding
+	return cursor
+}
+
+func (d *issuesDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *issuesDialogCmp) ID() dialogs.DialogID {
+	return IssuesDialogID
+}
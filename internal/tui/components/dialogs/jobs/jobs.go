@@ -0,0 +1,463 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const JobsDialogID dialogs.DialogID = "jobs"
+
+const (
+	// refreshInterval is how often the output is read again.
+	refreshInterval = time.Second
+	// maxAttachmentSize bounds the output attached, its end is kept.
+	maxAttachmentSize = 5 * 1024 * 1024
+)
+
+// JobsDialog shows the output of the background jobs as they run, to attach
+// it to the next message.
+type JobsDialog interface {
+	dialogs.DialogModel
+}
+
+type tickMsg struct{}
+
+type jobsDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	jobs     []*shell.BackgroundShell
+	selected int
+	// id is the job to select first, the latest when empty.
+	id string
+
+	// input is typed to the selected interactive job, or to the jobs in
+	// broadcast, by ID, when there are any.
+	input     textinput.Model
+	typing    bool
+	broadcast map[string]bool
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewJobsDialogCmp creates the viewer of the background jobs.
+func NewJobsDialogCmp() JobsDialog {
+	t := styles.CurrentTheme()
+	input := textinput.New()
+	input.SetVirtualCursor(false)
+	input.SetStyles(t.S().TextInput)
+	return &jobsDialogCmp{
+		input:     input,
+		broadcast: make(map[string]bool),
+		viewport:  viewport.New(),
+		keyMap:    DefaultKeyMap(),
+		help:      help.New(),
+	}
+}
+
+// NewJobDialogCmp creates the viewer of the background jobs showing the job
+// with the ID first.
+func NewJobDialogCmp(id string) JobsDialog {
+	d := NewJobsDialogCmp().(*jobsDialogCmp)
+	d.id = id
+	return d
+}
+
+func (d *jobsDialogCmp) Init() tea.Cmd {
+	d.jobs = shell.GetBackgroundShellManager().Jobs()
+	// The latest job is likely the one to look at.
+	d.selected = max(0, len(d.jobs)-1)
+	for i, job := range d.jobs {
+		if job.ID == d.id {
+			d.selected = i
+		}
+	}
+	d.updateKeys()
+	return d.tick()
+}
+
+func (d *jobsDialogCmp) tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+		return tickMsg{}
+	})
+}
+
+func (d *jobsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(120, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.input.SetWidth(d.width - 6)
+		d.refresh()
+		d.viewport.GotoBottom()
+	case tickMsg:
+		d.reload()
+		return d, d.tick()
+	case tea.PasteMsg:
+		if d.typing {
+			var cmd tea.Cmd
+			d.input, cmd = d.input.Update(msg)
+			return d, cmd
+		}
+	case tea.KeyPressMsg:
+		if d.typing {
+			return d, d.updateTyping(msg)
+		}
+		switch {
+		case key.Matches(msg, d.keyMap.Previous):
+			d.show(d.selected - 1)
+			return d, nil
+		case key.Matches(msg, d.keyMap.Next):
+			d.show(d.selected + 1)
+			return d, nil
+		case key.Matches(msg, d.keyMap.Attach):
+			if job := d.job(); job != nil {
+				return d, tea.Sequence(
+					util.CmdHandler(dialogs.CloseDialogMsg{}),
+					util.CmdHandler(filepicker.FilePickedMsg{Attachment: Attachment(job)}),
+				)
+			}
+			return d, nil
+		case key.Matches(msg, d.keyMap.Input):
+			d.typing = true
+			d.updateKeys()
+			return d, d.input.Focus()
+		case key.Matches(msg, d.keyMap.Broadcast):
+			if job := d.job(); job != nil {
+				if d.broadcast[job.ID] {
+					delete(d.broadcast, job.ID)
+				} else {
+					d.broadcast[job.ID] = true
+				}
+				d.refresh()
+			}
+			return d, nil
+		case key.Matches(msg, d.keyMap.Keep):
+			if job := d.job(); job != nil && job.Runaway() {
+				job.Keep()
+				d.refresh()
+			}
+			return d, nil
+		case key.Matches(msg, d.keyMap.Kill):
+			if job := d.job(); job != nil && !job.IsDone() {
+				if err := shell.GetBackgroundShellManager().Kill(job.ID); err != nil {
+					return d, util.ReportError(err)
+				}
+				d.reload()
+				return d, util.ReportInfo(fmt.Sprintf("Job %s killed", job.ID))
+			}
+			return d, nil
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// updateTyping sends what's typed to the jobs it's for.
+func (d *jobsDialogCmp) updateTyping(msg tea.KeyPressMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, d.keyMap.Send):
+		line := d.input.Value()
+		d.input.Reset()
+		return d.send(line+"\n", false)
+	case key.Matches(msg, d.keyMap.CloseInput):
+		return d.send("", true)
+	case key.Matches(msg, d.keyMap.StopTyping):
+		d.stopTyping()
+		return nil
+	}
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return cmd
+}
+
+func (d *jobsDialogCmp) stopTyping() {
+	d.typing = false
+	d.input.Blur()
+	d.updateKeys()
+}
+
+// targets returns the jobs input is typed to: the running jobs in
+// broadcast, or else the selected job, when they take input.
+func (d *jobsDialogCmp) targets() []*shell.BackgroundShell {
+	var targets []*shell.BackgroundShell
+	for _, job := range d.jobs {
+		if len(d.broadcast) > 0 && !d.broadcast[job.ID] || len(d.broadcast) == 0 && job != d.job() {
+			continue
+		}
+		if job.Interactive() && !job.IsDone() {
+			targets = append(targets, job)
+		}
+	}
+	return targets
+}
+
+// send writes input to the targets, closing their input after when asked.
+// It's done in a command as jobs not reading their input block it for a
+// while.
+func (d *jobsDialogCmp) send(input string, closeInput bool) tea.Cmd {
+	targets := d.targets()
+	return func() tea.Msg {
+		var errs []error
+		for _, job := range targets {
+			var err error
+			if input != "" {
+				err = job.Send(input)
+			}
+			if err == nil && closeInput {
+				err = job.CloseInput()
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %w", job.ID, err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		return nil
+	}
+}
+
+// reload reads the jobs again, keeping the selected one selected.
+func (d *jobsDialogCmp) reload() {
+	var id string
+	if job := d.job(); job != nil {
+		id = job.ID
+	}
+	d.jobs = shell.GetBackgroundShellManager().Jobs()
+	d.selected = min(d.selected, max(0, len(d.jobs)-1))
+	for i, job := range d.jobs {
+		if job.ID == id {
+			d.selected = i
+		}
+	}
+	for id := range d.broadcast {
+		if job, ok := shell.GetBackgroundShellManager().Get(id); !ok || job.IsDone() {
+			delete(d.broadcast, id)
+		}
+	}
+	if d.typing && len(d.targets()) == 0 {
+		d.stopTyping()
+	}
+	d.refresh()
+}
+
+// updateKeys offers keeping and killing the selected job when it makes
+// sense.
+func (d *jobsDialogCmp) updateKeys() {
+	job := d.job()
+	d.keyMap.Previous.SetEnabled(!d.typing)
+	d.keyMap.Next.SetEnabled(!d.typing)
+	d.keyMap.Attach.SetEnabled(!d.typing)
+	d.keyMap.Input.SetEnabled(!d.typing && len(d.targets()) > 0)
+	d.keyMap.Broadcast.SetEnabled(!d.typing && job != nil && job.Interactive() && !job.IsDone())
+	d.keyMap.Send.SetEnabled(d.typing)
+	d.keyMap.CloseInput.SetEnabled(d.typing)
+	d.keyMap.StopTyping.SetEnabled(d.typing)
+	d.keyMap.Keep.SetEnabled(!d.typing && job != nil && job.Runaway())
+	d.keyMap.Kill.SetEnabled(!d.typing && job != nil && !job.IsDone())
+	d.keyMap.Scroll.SetEnabled(!d.typing)
+	d.keyMap.Close.SetEnabled(!d.typing)
+}
+
+// refresh renders the selected job again, following its output when the
+// end of it is in view.
+func (d *jobsDialogCmp) refresh() {
+	follow := d.viewport.AtBottom()
+	d.updateKeys()
+	d.markSeen()
+	d.viewport.SetContent(d.content())
+	if follow {
+		d.viewport.GotoBottom()
+	}
+}
+
+// show selects the job at i, when there's one.
+func (d *jobsDialogCmp) show(i int) {
+	if i < 0 || i >= len(d.jobs) || i == d.selected {
+		return
+	}
+	d.selected = i
+	d.updateKeys()
+	d.markSeen()
+	d.viewport.SetContent(d.content())
+	d.viewport.GotoBottom()
+}
+
+// markSeen marks the output of the selected job as looked at, before it's
+// read to be shown.
+func (d *jobsDialogCmp) markSeen() {
+	if job := d.job(); job != nil {
+		job.MarkSeen()
+	}
+}
+
+func (d *jobsDialogCmp) job() *shell.BackgroundShell {
+	if d.selected >= len(d.jobs) {
+		return nil
+	}
+	return d.jobs[d.selected]
+}
+
+// content renders the command, the status and the output of the selected
+// job in the viewport.
+func (d *jobsDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	job := d.job()
+	if job == nil {
+		return t.S().Subtle.Width(width).Render("No background jobs. The agent starts them for servers, watchers and long builds.")
+	}
+
+	lines := []string{t.S().Text.Width(width).Render("$ " + job.Command)}
+	if job.Description != "" {
+		lines = append(lines, t.S().Subtle.Width(width).Render(job.Description))
+	}
+	lines = append(lines, status(job))
+	if d.broadcast[job.ID] {
+		lines = append(lines, t.S().Base.Foreground(t.Warning).Render("Gets the input typed to the jobs in broadcast"))
+	}
+	lines = append(lines, "")
+	if out := Output(job); out != "" {
+		lines = append(lines, t.S().Base.Width(width).Render(out))
+	} else {
+		lines = append(lines, t.S().Subtle.Render("No output yet"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// status tells whether the job runs, or how it exited.
+func status(job *shell.BackgroundShell) string {
+	t := styles.CurrentTheme()
+	_, _, done, err := job.GetOutput()
+	switch {
+	case !done && job.Runaway():
+		return t.S().Base.Foreground(t.Warning).Render(fmt.Sprintf("Writing in a loop, slowed down to %d KB/s: keep it running or kill it", shell.ThrottledRate/1024))
+	case !done && job.Interactive():
+		return t.S().Base.Foreground(t.Success).Render("Running, driven by the agent")
+	case !done:
+		return t.S().Base.Foreground(t.Success).Render("Running")
+	case shell.ExitCode(err) != 0:
+		return t.S().Error.Render(fmt.Sprintf("Exited with code %d", shell.ExitCode(err)))
+	}
+	return t.S().Subtle.Render("Done")
+}
+
+// Output returns the output of the job as plain text, its errors after.
+func Output(job *shell.BackgroundShell) string {
+	stdout, stderr, _, _ := job.GetOutput()
+	var parts []string
+	for _, out := range []string{stdout, stderr} {
+		if out = strings.TrimRight(ansi.Strip(out), "\n"); out != "" {
+			parts = append(parts, out)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Attachment attaches the command and output of the job to a message,
+// keeping the end of the output when it's too long.
+func Attachment(job *shell.BackgroundShell) message.Attachment {
+	content := "$ " + job.Command + "\n" + Output(job) + "\n"
+	if len(content) > maxAttachmentSize {
+		content = content[len(content)-maxAttachmentSize:]
+	}
+	return message.Attachment{
+		FilePath: "job " + job.ID,
+		FileName: "job " + job.ID + " output",
+		MimeType: "text/plain",
+		Content:  []byte(content),
+	}
+}
+
+func (d *jobsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Background Jobs"
+	if job := d.job(); job != nil {
+		title = fmt.Sprintf("Job %s %d/%d", job.ID, d.selected+1, len(d.jobs))
+	}
+	border := t.BorderFocus
+	if n := len(d.broadcast); n > 0 {
+		// Typing goes to several jobs at once, which had better be obvious.
+		title = fmt.Sprintf("%s, Broadcast to %d", title, n)
+		border = t.Warning
+	}
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	input := ""
+	if d.typing {
+		input = t.S().Base.Padding(0, 1).Render(d.input.View())
+	}
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		input,
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Render(content)
+}
+
+func (d *jobsDialogCmp) Cursor() *tea.Cursor {
+	if !d.typing {
+		return nil
+	}
+	cursor := d.input.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := d.Position()
+	cursor.Y += row + 3 + d.viewport.Height() // border, title and padding
+	cursor.X += col + 2                       // border and padding
+	return cursor
+}
+
+func (d *jobsDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *jobsDialogCmp) ID() dialogs.DialogID {
+	return JobsDialogID
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcast(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping flacky test on windows")
+	}
+	t.Parallel()
+
+	manager := shell.GetBackgroundShellManager()
+	var jobs []*shell.BackgroundShell
+	for range 2 {
+		job, err := manager.StartInteractive(context.Background(), t.TempDir(), nil, "while read line; do echo \"got $line\"; done", "")
+		require.NoError(t, err)
+		t.Cleanup(func() { manager.Kill(job.ID) })
+		jobs = append(jobs, job)
+	}
+	plain, err := manager.Start(context.Background(), t.TempDir(), nil, "sleep 5", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Kill(plain.ID) })
+
+	d := NewJobsDialogCmp().(*jobsDialogCmp)
+	d.jobs = append(jobs, plain)
+	d.selected = 2
+	require.Empty(t, d.targets(), "jobs that aren't interactive take no input")
+
+	d.selected = 0
+	require.Equal(t, jobs[:1], d.targets())
+
+	d.broadcast[jobs[0].ID] = true
+	d.broadcast[jobs[1].ID] = true
+	require.Equal(t, jobs, d.targets(), "the jobs in broadcast get the input, whichever is selected")
+
+	require.Nil(t, d.send("hello\n", false)())
+	for _, job := range jobs {
+		require.Eventually(t, func() bool {
+			stdout, _, _, _ := job.GetOutput()
+			return stdout == "got hello\n"
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+}
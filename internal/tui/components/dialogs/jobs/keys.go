@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the background jobs.
+type KeyMap struct {
+	Previous,
+	Next,
+	Attach,
+	Input,
+	Broadcast,
+	Send,
+	CloseInput,
+	StopTyping,
+	Keep,
+	Kill,
+	Scroll,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Previous: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/→", "previous/next job"),
+		),
+		Next: key.NewBinding(
+			key.WithKeys("right", "l"),
+		),
+		Attach: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "attach output"),
+		),
+		Input: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "type input"),
+		),
+		Broadcast: key.NewBinding(
+			key.WithKeys("space"),
+			key.WithHelp("space", "broadcast to"),
+		),
+		Send: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "send"),
+		),
+		CloseInput: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "close input"),
+		),
+		StopTyping: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "stop typing"),
+		),
+		Keep: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "keep running"),
+		),
+		Kill: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "kill"),
+		),
+		Scroll: key.NewBinding(
+			key.WithKeys("up", "down"),
+			key.WithHelp("↑↓/pgup/pgdn", "scroll"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc", "q"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Previous,
+		k.Attach,
+		k.Input,
+		k.Broadcast,
+		k.Send,
+		k.CloseInput,
+		k.StopTyping,
+		k.Keep,
+		k.Kill,
+		k.Scroll,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return k.KeyBindings()
+}
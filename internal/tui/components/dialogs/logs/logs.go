@@ -0,0 +1,281 @@
+package logs
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/log"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const LogsDialogID dialogs.DialogID = "logs"
+
+const (
+	// followInterval is how often the log file is read when following it.
+	followInterval = time.Second
+	// maxEntries is how many records are kept, the oldest are dropped.
+	maxEntries = 5000
+)
+
+// levels are the levels to filter by, in the order they're cycled through.
+var levels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// LogsDialog shows the records of the log file, filtered by level, following
+// the new ones.
+type LogsDialog interface {
+	dialogs.DialogModel
+}
+
+type readMsg struct {
+	entries []log.Entry
+	offset  int64
+	err     error
+}
+
+type tickMsg struct {
+	// follow is the follow mode the tick was scheduled for, the ticks of
+	// the previous ones are dropped.
+	follow int
+}
+
+type logsDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	path    string
+	entries []log.Entry
+	offset  int64
+	err     error
+	level   int
+	follow  bool
+	// following counts the times follow mode was turned on.
+	following int
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewLogsDialogCmp creates the log viewer, following the log file.
+func NewLogsDialogCmp() LogsDialog {
+	return &logsDialogCmp{
+		path:     log.File(),
+		offset:   -1,
+		follow:   true,
+		viewport: viewport.New(),
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+}
+
+func (d *logsDialogCmp) Init() tea.Cmd {
+	return tea.Batch(d.read(), d.tick())
+}
+
+// read reads the records logged since the last read.
+func (d *logsDialogCmp) read() tea.Cmd {
+	path, offset := d.path, d.offset
+	return func() tea.Msg {
+		if path == "" {
+			return readMsg{offset: offset}
+		}
+		entries, offset, err := log.Read(path, offset)
+		return readMsg{entries: entries, offset: offset, err: err}
+	}
+}
+
+func (d *logsDialogCmp) tick() tea.Cmd {
+	follow := d.following
+	return tea.Tick(followInterval, func(time.Time) tea.Msg {
+		return tickMsg{follow: follow}
+	})
+}
+
+func (d *logsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(140, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.refresh()
+	case readMsg:
+		d.err = msg.err
+		d.offset = msg.offset
+		if len(msg.entries) > 0 {
+			d.entries = append(d.entries, msg.entries...)
+			d.entries = d.entries[max(0, len(d.entries)-maxEntries):]
+			d.refresh()
+		}
+	case tickMsg:
+		if !d.follow || msg.follow != d.following {
+			return d, nil
+		}
+		return d, tea.Batch(d.read(), d.tick())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Level):
+			d.level = (d.level + 1) % len(levels)
+			d.refresh()
+			return d, nil
+		case key.Matches(msg, d.keyMap.Follow):
+			d.follow = !d.follow
+			if !d.follow {
+				return d, nil
+			}
+			d.following++
+			d.viewport.GotoBottom()
+			return d, tea.Batch(d.read(), d.tick())
+		case key.Matches(msg, d.keyMap.Debug):
+			if log.Level() <= slog.LevelDebug {
+				log.SetLevel(slog.LevelInfo)
+				return d, util.ReportInfo("Debug logging off")
+			}
+			log.SetLevel(slog.LevelDebug)
+			return d, util.ReportInfo("Debug logging on")
+		case key.Matches(msg, d.keyMap.Copy):
+			return d, util.CopyToClipboard(d.plain(), "Logs")
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		// Scrolling up stops following, so that new records don't move
+		// what's being read.
+		if d.follow && !d.viewport.AtBottom() {
+			d.follow = false
+		}
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		if d.follow && !d.viewport.AtBottom() {
+			d.follow = false
+		}
+		return d, cmd
+	}
+	return d, nil
+}
+
+// visible returns the records at the selected level or above.
+func (d *logsDialogCmp) visible() []log.Entry {
+	var entries []log.Entry
+	for _, entry := range d.entries {
+		if entry.Level >= levels[d.level] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// refresh renders the records again, keeping the bottom in view when
+// following.
+func (d *logsDialogCmp) refresh() {
+	d.viewport.SetContent(d.content())
+	if d.follow {
+		d.viewport.GotoBottom()
+	}
+}
+
+func (d *logsDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	switch {
+	case d.path == "":
+		return t.S().Subtle.Render("Logging isn't set up.")
+	case d.err != nil:
+		return t.S().Error.Width(width).Render(d.err.Error())
+	}
+	entries := d.visible()
+	if len(entries) == 0 {
+		return t.S().Subtle.Render("No records at this level yet.")
+	}
+
+	levelStyles := map[slog.Level]lipgloss.Style{
+		slog.LevelDebug: t.S().Subtle,
+		slog.LevelInfo:  t.S().Base.Foreground(t.Info),
+		slog.LevelWarn:  t.S().Warning,
+		slog.LevelError: t.S().Error,
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		level, ok := levelStyles[entry.Level]
+		if !ok {
+			level = t.S().Base
+		}
+		line := t.S().Subtle.Render(entry.Time.Local().Format("15:04:05")) + " " +
+			level.Width(5).Render(entry.Level.String()) + " " +
+			t.S().Text.Render(entry.Message)
+		for _, attr := range entry.Attrs {
+			line += " " + t.S().Muted.Render(attr.Key+"=") + t.S().Subtle.Render(attr.Value)
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plain is what gets copied: the visible records in full, one per line.
+func (d *logsDialogCmp) plain() string {
+	var b strings.Builder
+	for _, entry := range d.visible() {
+		fmt.Fprintf(&b, "%s %s %s", entry.Time.Format(time.RFC3339), entry.Level, entry.Message)
+		for _, attr := range entry.Attrs {
+			fmt.Fprintf(&b, " %s=%s", attr.Key, attr.Value)
+		}
+		if entry.Source != "" {
+			fmt.Fprintf(&b, " source=%s", entry.Source)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (d *logsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := fmt.Sprintf("Logs: %s and above", levels[d.level])
+	if d.follow {
+		title += ", following"
+	}
+	if log.Level() <= slog.LevelDebug {
+		title += ", debug on"
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *logsDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *logsDialogCmp) ID() dialogs.DialogID {
+	return LogsDialogID
+}
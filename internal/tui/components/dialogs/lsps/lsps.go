@@ -0,0 +1,258 @@
+package lsps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	LSPManagerDialogID dialogs.DialogID = "lsp_manager"
+
+	// logLines is the number of log lines shown for the selected server.
+	logLines = 8
+
+	// refreshInterval is how often the memory use of the running servers is
+	// sampled while the dialog is open.
+	refreshInterval = 3 * time.Second
+)
+
+// LSPManagerDialog lists the configured LSP servers with their state and
+// lets the user restart them.
+type LSPManagerDialog interface {
+	dialogs.DialogModel
+}
+
+type memoryMsg struct {
+	usage map[string]uint64
+}
+
+type refreshMsg struct{}
+
+type lspManagerDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	app    *app.App
+	memory map[string]uint64
+
+	selected int
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewLSPManagerDialogCmp creates the LSP server manager dialog.
+func NewLSPManagerDialogCmp(app *app.App) LSPManagerDialog {
+	return &lspManagerDialogCmp{
+		app:    app,
+		memory: map[string]uint64{},
+		keyMap: DefaultKeyMap(),
+		help:   help.New(),
+	}
+}
+
+func (m *lspManagerDialogCmp) Init() tea.Cmd {
+	return m.sampleMemory()
+}
+
+func (m *lspManagerDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(90, m.wWidth-4)
+	case memoryMsg:
+		m.memory = msg.usage
+		return m, tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+			return refreshMsg{}
+		})
+	case refreshMsg:
+		return m, m.sampleMemory()
+	case tea.KeyPressMsg:
+		servers := config.Get().LSP.Sorted()
+		switch {
+		case key.Matches(msg, m.keyMap.Next):
+			m.selected = min(m.selected+1, len(servers)-1)
+		case key.Matches(msg, m.keyMap.Previous):
+			m.selected = max(m.selected-1, 0)
+		case key.Matches(msg, m.keyMap.Restart):
+			if len(servers) == 0 {
+				return m, nil
+			}
+			return m, m.restartServer(servers[m.selected])
+		case key.Matches(msg, m.keyMap.Close):
+			return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return m, nil
+}
+
+func (m *lspManagerDialogCmp) restartServer(server config.LSP) tea.Cmd {
+	if server.LSP.Disabled {
+		return util.ReportWarn(fmt.Sprintf("LSP %s is disabled in the configuration", server.Name))
+	}
+	return func() tea.Msg {
+		if err := m.app.RestartLSPClient(context.Background(), server.Name); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Restarting LSP %s", server.Name)}
+	}
+}
+
+// sampleMemory looks up the memory use of every running server.
+func (m *lspManagerDialogCmp) sampleMemory() tea.Cmd {
+	clients := m.app.LSPClients
+	return func() tea.Msg {
+		usage := map[string]uint64{}
+		for name, client := range clients.Seq2() {
+			if bytes, err := client.MemoryUsage(context.Background()); err == nil {
+				usage[name] = bytes
+			}
+		}
+		return memoryMsg{usage: usage}
+	}
+}
+
+func (m *lspManagerDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("LSP Servers", m.width-4))
+	servers := config.Get().LSP.Sorted()
+	m.selected = max(0, min(m.selected, len(servers)-1))
+
+	var body []string
+	if len(servers) == 0 {
+		body = append(body, t.S().Subtle.Render("No LSP servers configured."))
+	}
+	states := app.GetLSPStates()
+	for i, server := range servers {
+		state, ok := states[server.Name]
+		icon, description := stateLabel(state, ok, server.LSP.Disabled)
+		title := server.Name
+		if i == m.selected {
+			title = t.S().Base.Foreground(t.Primary).Bold(true).Render(title)
+		}
+		body = append(body, core.Status(core.StatusOpts{
+			Icon:        icon,
+			Title:       title,
+			Description: description,
+		}, m.width-4))
+	}
+	if len(servers) > 0 {
+		body = append(body, "", m.detailsView(servers[m.selected], states))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func stateLabel(info app.LSPClientInfo, ok, disabled bool) (string, string) {
+	t := styles.CurrentTheme()
+	if disabled {
+		return t.ItemOfflineIcon.String(), t.S().Subtle.Render("disabled")
+	}
+	if !ok {
+		return t.ItemOfflineIcon.String(), ""
+	}
+	switch info.State {
+	case lsp.StateStarting:
+		return t.ItemBusyIcon.String(), t.S().Subtle.Render("starting...")
+	case lsp.StateReady:
+		return t.ItemOnlineIcon.String(), t.S().Subtle.Render("ready")
+	case lsp.StateError:
+		return t.ItemErrorIcon.String(), t.S().Subtle.Render("error")
+	case lsp.StateCrashed:
+		return t.ItemErrorIcon.String(), t.S().Subtle.Render("crashed")
+	default:
+		return t.ItemOfflineIcon.String(), t.S().Subtle.Render("inactive")
+	}
+}
+
+// detailsView renders the command, resource use and log tail of the
+// selected server.
+func (m *lspManagerDialogCmp) detailsView(server config.LSP, states map[string]app.LSPClientInfo) string {
+	t := styles.CurrentTheme()
+	width := m.width - 4
+	label := func(s string) string {
+		return t.S().Subtle.Render(s + ": ")
+	}
+
+	command := strings.Join(append([]string{server.LSP.Command}, server.LSP.Args...), " ")
+	lines := []string{
+		core.Section(server.Name, width),
+		ansi.Truncate(label("Command")+t.S().Text.Render(command), width, "…"),
+	}
+
+	state := states[server.Name]
+	if state.State == lsp.StateReady && !state.ConnectedAt.IsZero() {
+		uptime := time.Since(state.ConnectedAt).Truncate(time.Second)
+		lines = append(lines, label("Uptime")+t.S().Text.Render(uptime.String()))
+	}
+	if bytes, ok := m.memory[server.Name]; ok {
+		lines = append(lines, label("Memory")+t.S().Text.Render(formatBytes(bytes)))
+	}
+	lines = append(lines, label("Diagnostics")+t.S().Text.Render(fmt.Sprintf("%d", state.DiagnosticCount)))
+	if state.Error != nil {
+		lines = append(lines, ansi.Truncate(label("Error")+t.S().Error.Render(state.Error.Error()), width, "…"))
+	}
+
+	lines = append(lines, "", core.Section("Log", width))
+	entries := lsp.Logs(server.Name)
+	if len(entries) == 0 {
+		lines = append(lines, t.S().Subtle.Render("No log entries"))
+	}
+	for _, entry := range entries[max(0, len(entries)-logLines):] {
+		line := t.S().Subtle.Render(entry.Time.Format("15:04:05")) + " " +
+			t.S().Muted.Render(entry.Level) + " " +
+			t.S().Text.Render(strings.ReplaceAll(entry.Message, "\n", " "))
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func (m *lspManagerDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *lspManagerDialogCmp) ID() dialogs.DialogID {
+	return LSPManagerDialogID
+}
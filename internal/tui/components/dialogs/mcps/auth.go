@@ -0,0 +1,159 @@
+package mcps
+
+import (
+	"context"
+	"fmt"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/pkg/browser"
+)
+
+const MCPAuthDialogID dialogs.DialogID = "mcp_auth"
+
+// AuthDialogID is the ID of the authorization dialog of the named server,
+// so that each server gets its own.
+func AuthDialogID(name string) dialogs.DialogID {
+	return MCPAuthDialogID + dialogs.DialogID(":"+name)
+}
+
+// MCPAuthDialog asks the user to authorize Crush against an MCP server.
+type MCPAuthDialog interface {
+	dialogs.DialogModel
+}
+
+type authResultMsg struct {
+	name string
+	err  error
+}
+
+type mcpAuthDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	name    string
+	waiting bool
+	err     error
+	cancel  context.CancelFunc
+
+	keyMap AuthKeyMap
+	help   help.Model
+}
+
+// NewMCPAuthDialogCmp creates the dialog shown when the given MCP server
+// requires authorization.
+func NewMCPAuthDialogCmp(name string) MCPAuthDialog {
+	return &mcpAuthDialogCmp{
+		name:   name,
+		keyMap: DefaultAuthKeyMap(),
+		help:   help.New(),
+	}
+}
+
+func (m *mcpAuthDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (m *mcpAuthDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(70, m.wWidth-4)
+	case authResultMsg:
+		if msg.name != m.name {
+			return m, nil
+		}
+		m.waiting = false
+		m.cancel = nil
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		return m, tea.Batch(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			util.ReportInfo(fmt.Sprintf("Authorized MCP server %s", m.name)),
+		)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, m.keyMap.Authorize):
+			if m.waiting {
+				return m, nil
+			}
+			return m, m.authorize()
+		case key.Matches(msg, m.keyMap.Close):
+			if m.cancel != nil {
+				m.cancel()
+			}
+			return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return m, nil
+}
+
+func (m *mcpAuthDialogCmp) authorize() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.waiting = true
+	m.err = nil
+	name := m.name
+	return func() tea.Msg {
+		defer cancel()
+		return authResultMsg{name: name, err: mcp.Authorize(ctx, name, browser.OpenURL)}
+	}
+}
+
+func (m *mcpAuthDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("MCP Authorization", m.width-4))
+
+	var body string
+	switch {
+	case m.waiting:
+		body = t.S().Text.Render("Waiting for you to finish signing in to ") +
+			t.S().Base.Foreground(t.Primary).Render(m.name) +
+			t.S().Text.Render(" in your browser...")
+	case m.err != nil:
+		body = lipgloss.JoinVertical(
+			lipgloss.Left,
+			t.S().Base.Foreground(t.Error).Render(m.err.Error()),
+			"",
+			t.S().Subtle.Render("Press enter to try again."),
+		)
+	default:
+		body = t.S().Text.Render("The MCP server ") +
+			t.S().Base.Foreground(t.Primary).Render(m.name) +
+			t.S().Text.Render(" requires authorization. Press enter to sign in with your browser.")
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Width(m.width-2).Render(body),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (m *mcpAuthDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *mcpAuthDialogCmp) ID() dialogs.DialogID {
+	return AuthDialogID(m.name)
+}
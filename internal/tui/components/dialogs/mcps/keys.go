@@ -0,0 +1,203 @@
+package mcps
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the MCP server manager.
+type KeyMap struct {
+	Next,
+	Previous,
+	Toggle,
+	Restart,
+	Authorize,
+	Add,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "ctrl+n", "j"),
+			key.WithHelp("↓", "next server"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "ctrl+p", "k"),
+			key.WithHelp("↑", "previous server"),
+		),
+		Toggle: key.NewBinding(
+			key.WithKeys("space", " "),
+			key.WithHelp("space", "enable/disable"),
+		),
+		Restart: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "restart"),
+		),
+		Authorize: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "sign in"),
+		),
+		Add: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "add server"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Toggle,
+		k.Restart,
+		k.Authorize,
+		k.Add,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	m := [][]key.Binding{}
+	slice := k.KeyBindings()
+	for i := 0; i < len(slice); i += 4 {
+		end := min(i+4, len(slice))
+		m = append(m, slice[i:end])
+	}
+	return m
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Toggle,
+		k.Restart,
+		k.Add,
+		k.Close,
+	}
+}
+
+// FormKeyMap defines the keyboard bindings for the add server form.
+type FormKeyMap struct {
+	Next,
+	Previous,
+	Submit,
+	Cancel key.Binding
+}
+
+func DefaultFormKeyMap() FormKeyMap {
+	return FormKeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("tab", "down"),
+			key.WithHelp("tab", "next field"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("shift+tab", "up"),
+			key.WithHelp("shift+tab", "previous field"),
+		),
+		Submit: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "add"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k FormKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k FormKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Submit,
+		k.Cancel,
+	}
+}
+
+// AuthKeyMap defines the keyboard bindings for the MCP authorization dialog.
+type AuthKeyMap struct {
+	Authorize,
+	Close key.Binding
+}
+
+func DefaultAuthKeyMap() AuthKeyMap {
+	return AuthKeyMap{
+		Authorize: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "sign in"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "dismiss"),
+		),
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k AuthKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k AuthKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Authorize,
+		k.Close,
+	}
+}
+
+// ResourcesKeyMap defines the keyboard bindings for the MCP resource browser.
+type ResourcesKeyMap struct {
+	Next,
+	Previous,
+	Attach,
+	Close key.Binding
+}
+
+func DefaultResourcesKeyMap() ResourcesKeyMap {
+	return ResourcesKeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "ctrl+n"),
+			key.WithHelp("↓", "next resource"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "ctrl+p"),
+			key.WithHelp("↑", "previous resource"),
+		),
+		Attach: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "attach"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k ResourcesKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k ResourcesKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Attach,
+		k.Close,
+	}
+}
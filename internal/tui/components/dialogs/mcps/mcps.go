@@ -0,0 +1,433 @@
+package mcps
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	MCPManagerDialogID dialogs.DialogID = "mcp_manager"
+
+	// logLines is the number of connection log lines shown for the selected
+	// server.
+	logLines = 6
+)
+
+// Form fields of the add server form.
+const (
+	fieldName = iota
+	fieldType
+	fieldTarget
+	fieldArgs
+)
+
+// MCPManagerDialog lists the configured MCP servers and lets the user
+// manage them.
+type MCPManagerDialog interface {
+	dialogs.DialogModel
+}
+
+type mcpManagerDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	selected int
+	keyMap   KeyMap
+	help     help.Model
+
+	adding  bool
+	inputs  []textinput.Model
+	focused int
+	formMap FormKeyMap
+}
+
+// NewMCPManagerDialogCmp creates the MCP server manager dialog.
+func NewMCPManagerDialogCmp() MCPManagerDialog {
+	return &mcpManagerDialogCmp{
+		keyMap:  DefaultKeyMap(),
+		formMap: DefaultFormKeyMap(),
+		help:    help.New(),
+	}
+}
+
+func (m *mcpManagerDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (m *mcpManagerDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(90, m.wWidth-4)
+		for i := range m.inputs {
+			m.inputs[i].SetWidth(m.width - 6)
+		}
+	case tea.PasteMsg:
+		if m.adding {
+			var cmd tea.Cmd
+			m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+			return m, cmd
+		}
+	case tea.KeyPressMsg:
+		if m.adding {
+			return m, m.handleFormKey(msg)
+		}
+		servers := config.Get().MCP.Sorted()
+		// Servers may have been removed from the config since the last key.
+		m.selected = max(0, min(m.selected, len(servers)-1))
+		switch {
+		case key.Matches(msg, m.keyMap.Next):
+			m.selected = max(0, min(m.selected+1, len(servers)-1))
+		case key.Matches(msg, m.keyMap.Previous):
+			m.selected = max(m.selected-1, 0)
+		case key.Matches(msg, m.keyMap.Toggle):
+			if len(servers) == 0 {
+				return m, nil
+			}
+			return m, toggleServer(servers[m.selected])
+		case key.Matches(msg, m.keyMap.Restart):
+			if len(servers) == 0 {
+				return m, nil
+			}
+			return m, restartServer(servers[m.selected].Name)
+		case key.Matches(msg, m.keyMap.Authorize):
+			if len(servers) == 0 || servers[m.selected].MCP.Type == config.MCPStdio {
+				return m, nil
+			}
+			return m, util.CmdHandler(dialogs.OpenDialogMsg{
+				Model: NewMCPAuthDialogCmp(servers[m.selected].Name),
+			})
+		case key.Matches(msg, m.keyMap.Add):
+			return m, m.startAdding()
+		case key.Matches(msg, m.keyMap.Close):
+			return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return m, nil
+}
+
+// toggleServer enables or disables the server. The config is changed here,
+// in Update, as the views and the agent read it; only starting or stopping
+// the client is left to the command.
+func toggleServer(server config.MCP) tea.Cmd {
+	disable := !server.MCP.Disabled
+	if err := config.Get().SetMCPDisabled(server.Name, disable); err != nil {
+		return util.ReportError(err)
+	}
+	return func() tea.Msg {
+		if disable {
+			mcp.Disable(server.Name)
+			return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Disabled MCP server %s", server.Name)}
+		}
+		if err := mcp.Restart(context.Background(), server.Name); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("MCP server %s failed to start: %v", server.Name, err)}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Enabled MCP server %s", server.Name)}
+	}
+}
+
+func restartServer(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := mcp.Restart(context.Background(), name); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("MCP server %s failed to restart: %v", name, err)}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Restarted MCP server %s", name)}
+	}
+}
+
+func (m *mcpManagerDialogCmp) startAdding() tea.Cmd {
+	t := styles.CurrentTheme()
+	placeholders := []string{
+		"Name, e.g. github",
+		"Type: stdio, http or sse",
+		"Command for stdio, URL for http and sse",
+		"Arguments, separated by spaces",
+	}
+	m.inputs = make([]textinput.Model, len(placeholders))
+	for i, placeholder := range placeholders {
+		ti := textinput.New()
+		ti.Placeholder = placeholder
+		ti.SetWidth(m.width - 6)
+		ti.SetVirtualCursor(false)
+		ti.Prompt = ""
+		ti.SetStyles(t.S().TextInput)
+		m.inputs[i] = ti
+	}
+	m.inputs[fieldType].SetValue(string(config.MCPStdio))
+	m.adding = true
+	m.focused = fieldName
+	return m.inputs[m.focused].Focus()
+}
+
+func (m *mcpManagerDialogCmp) handleFormKey(msg tea.KeyPressMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.formMap.Cancel):
+		m.adding = false
+		return nil
+	case key.Matches(msg, m.formMap.Submit):
+		if m.focused < len(m.inputs)-1 {
+			return m.focus(m.focused + 1)
+		}
+		return m.submit()
+	case key.Matches(msg, m.formMap.Next):
+		return m.focus((m.focused + 1) % len(m.inputs))
+	case key.Matches(msg, m.formMap.Previous):
+		return m.focus((m.focused - 1 + len(m.inputs)) % len(m.inputs))
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	return cmd
+}
+
+func (m *mcpManagerDialogCmp) focus(i int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = i
+	return m.inputs[m.focused].Focus()
+}
+
+func (m *mcpManagerDialogCmp) submit() tea.Cmd {
+	name := strings.TrimSpace(m.inputs[fieldName].Value())
+	mcpType := config.MCPType(strings.TrimSpace(m.inputs[fieldType].Value()))
+	target := strings.TrimSpace(m.inputs[fieldTarget].Value())
+	args := strings.Fields(m.inputs[fieldArgs].Value())
+
+	switch {
+	case name == "":
+		return util.ReportWarn("A name is required")
+	case target == "":
+		return util.ReportWarn("A command or URL is required")
+	}
+
+	server := config.MCPConfig{Type: mcpType}
+	switch mcpType {
+	case config.MCPStdio:
+		server.Command = target
+		server.Args = args
+	case config.MCPHttp, config.MCPSSE:
+		server.URL = target
+	default:
+		return util.ReportWarn(fmt.Sprintf("Unknown MCP type %q", mcpType))
+	}
+
+	if err := config.Get().AddMCP(name, server); err != nil {
+		return util.ReportError(err)
+	}
+	m.adding = false
+	m.selected = max(0, slices.IndexFunc(config.Get().MCP.Sorted(), func(s config.MCP) bool {
+		return s.Name == name
+	}))
+	return restartServer(name)
+}
+
+func (m *mcpManagerDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	if m.adding {
+		return m.style().Render(m.formView())
+	}
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("MCP Servers", m.width-4))
+	servers := config.Get().MCP.Sorted()
+	// The config may have lost servers since the last key; Update clamps
+	// the selection on the next one.
+	selected := max(0, min(m.selected, len(servers)-1))
+
+	var body []string
+	if len(servers) == 0 {
+		body = append(body, t.S().Subtle.Render("No MCP servers configured. Press a to add one."))
+	}
+	states := mcp.GetStates()
+	for i, server := range servers {
+		state, ok := states[server.Name]
+		icon, description := stateLabel(state, ok, server.MCP.Disabled)
+		title := server.Name
+		if i == selected {
+			title = t.S().Base.Foreground(t.Primary).Bold(true).Render(title)
+		}
+		body = append(body, core.Status(core.StatusOpts{
+			Icon:        icon,
+			Title:       title,
+			Description: description,
+		}, m.width-4))
+	}
+	if len(servers) > 0 {
+		body = append(body, "", m.detailsView(servers[selected], states))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return m.style().Render(content)
+}
+
+func stateLabel(state mcp.ClientInfo, ok, disabled bool) (string, string) {
+	t := styles.CurrentTheme()
+	if !ok {
+		if disabled {
+			return t.ItemOfflineIcon.String(), t.S().Subtle.Render("disabled")
+		}
+		return t.ItemOfflineIcon.String(), ""
+	}
+	switch state.State {
+	case mcp.StateStarting:
+		return t.ItemBusyIcon.String(), t.S().Subtle.Render("starting...")
+	case mcp.StateConnected:
+		return t.ItemOnlineIcon.String(), t.S().Subtle.Render("connected")
+	case mcp.StateError:
+		return t.ItemErrorIcon.String(), t.S().Subtle.Render("error")
+	default:
+		return t.ItemOfflineIcon.String(), t.S().Subtle.Render("disabled")
+	}
+}
+
+// detailsView renders what the selected server exposes along with the tail
+// of its connection log.
+func (m *mcpManagerDialogCmp) detailsView(server config.MCP, states map[string]mcp.ClientInfo) string {
+	t := styles.CurrentTheme()
+	width := m.width - 4
+	label := func(s string) string {
+		return t.S().Subtle.Render(s + ": ")
+	}
+
+	target := server.MCP.URL
+	if server.MCP.Type == config.MCPStdio || server.MCP.Type == "" {
+		target = strings.Join(append([]string{server.MCP.Command}, server.MCP.Args...), " ")
+	}
+	lines := []string{
+		core.Section(server.Name, width),
+		label("Type") + t.S().Text.Render(string(cmp.Or(server.MCP.Type, config.MCPStdio))),
+		ansi.Truncate(label("Target")+t.S().Text.Render(target), width, "…"),
+	}
+
+	state := states[server.Name]
+	if state.Error != nil {
+		lines = append(lines, ansi.Truncate(label("Error")+t.S().Error.Render(state.Error.Error()), width, "…"))
+	}
+
+	var toolNames, promptNames, resourceNames []string
+	for name, tools := range mcp.Tools() {
+		if name == server.Name {
+			for _, tool := range tools {
+				toolNames = append(toolNames, tool.Name)
+			}
+		}
+	}
+	for name, prompts := range mcp.Prompts() {
+		if name == server.Name {
+			for _, prompt := range prompts {
+				promptNames = append(promptNames, prompt.Name)
+			}
+		}
+	}
+	for name, resources := range mcp.Resources() {
+		if name == server.Name {
+			for _, resource := range resources {
+				resourceNames = append(resourceNames, cmp.Or(resource.Name, resource.URI))
+			}
+		}
+	}
+	for _, group := range []struct {
+		name  string
+		items []string
+	}{
+		{"Tools", toolNames},
+		{"Prompts", promptNames},
+		{"Resources", resourceNames},
+	} {
+		slices.Sort(group.items)
+		value := fmt.Sprintf("%d", len(group.items))
+		if len(group.items) > 0 {
+			value += " · " + strings.Join(group.items, ", ")
+		}
+		lines = append(lines, ansi.Truncate(label(group.name)+t.S().Text.Render(value), width, "…"))
+	}
+
+	lines = append(lines, "", core.Section("Log", width))
+	entries := mcp.Logs(server.Name)
+	if len(entries) == 0 {
+		lines = append(lines, t.S().Subtle.Render("No log entries"))
+	}
+	for _, entry := range entries[max(0, len(entries)-logLines):] {
+		line := t.S().Subtle.Render(entry.Time.Format("15:04:05")) + " " +
+			t.S().Muted.Render(entry.Level) + " " +
+			t.S().Text.Render(strings.ReplaceAll(entry.Message, "\n", " "))
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m *mcpManagerDialogCmp) formView() string {
+	t := styles.CurrentTheme()
+	labels := []string{"Name", "Type", "Command or URL", "Arguments"}
+
+	elements := []string{t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Add MCP Server", m.width-4))}
+	for i, input := range m.inputs {
+		labelStyle := t.S().Base.Padding(0, 1)
+		if i == m.focused {
+			labelStyle = labelStyle.Foreground(t.FgBase).Bold(true)
+		} else {
+			labelStyle = labelStyle.Foreground(t.FgMuted)
+		}
+		elements = append(elements,
+			labelStyle.Render(labels[i]+":"),
+			t.S().Text.Padding(0, 1).Render(input.View()),
+			"",
+		)
+	}
+	elements = append(elements, t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.formMap)))
+	return lipgloss.JoinVertical(lipgloss.Left, elements...)
+}
+
+func (m *mcpManagerDialogCmp) Cursor() *tea.Cursor {
+	if !m.adding {
+		return nil
+	}
+	cursor := m.inputs[m.focused].Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := m.Position()
+	// Border and header, then three lines per field.
+	cursor.Y += row + 1 + 2 + m.focused*3 + 1
+	cursor.X += col + 2
+	return cursor
+}
+
+func (m *mcpManagerDialogCmp) style() lipgloss.Style {
+	t := styles.CurrentTheme()
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus)
+}
+
+func (m *mcpManagerDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *mcpManagerDialogCmp) ID() dialogs.DialogID {
+	return MCPManagerDialogID
+}
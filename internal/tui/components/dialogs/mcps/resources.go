@@ -0,0 +1,411 @@
+package mcps
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	MCPResourcesDialogID dialogs.DialogID = "mcp_resources"
+
+	// listHeight is the number of resources visible at once.
+	listHeight = 10
+	// previewLines is the number of lines of resource content shown in the
+	// preview.
+	previewLines = 8
+)
+
+// MCPResourcesDialog lets the user browse the resources exposed by the
+// connected MCP servers and attach them to the prompt.
+type MCPResourcesDialog interface {
+	dialogs.DialogModel
+}
+
+// resourceEntry is either a resource or a resource template.
+type resourceEntry struct {
+	server   string
+	resource *mcp.Resource
+	template *mcp.ResourceTemplate
+}
+
+func (e resourceEntry) name() string {
+	if e.template != nil {
+		return cmp.Or(e.template.Title, e.template.Name)
+	}
+	return cmp.Or(e.resource.Title, e.resource.Name)
+}
+
+func (e resourceEntry) uri() string {
+	if e.template != nil {
+		return e.template.URITemplate
+	}
+	return e.resource.URI
+}
+
+func (e resourceEntry) description() string {
+	if e.template != nil {
+		return e.template.Description
+	}
+	return e.resource.Description
+}
+
+type resourcePreviewMsg struct {
+	uri     string
+	preview string
+	err     error
+}
+
+type mcpResourcesDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	entries  []resourceEntry
+	selected int
+	offset   int
+
+	previewURI    string
+	preview       string
+	previewErr    error
+	previewLoaded bool
+
+	// uriInput is used to fill in the variables of a resource template.
+	uriInput  textinput.Model
+	expanding bool
+
+	keyMap ResourcesKeyMap
+	help   help.Model
+}
+
+// NewMCPResourcesDialogCmp creates the MCP resource browser dialog.
+func NewMCPResourcesDialogCmp() MCPResourcesDialog {
+	t := styles.CurrentTheme()
+	uriInput := textinput.New()
+	uriInput.SetVirtualCursor(false)
+	uriInput.Prompt = ""
+	uriInput.SetStyles(t.S().TextInput)
+
+	return &mcpResourcesDialogCmp{
+		entries:  collectResources(),
+		uriInput: uriInput,
+		keyMap:   DefaultResourcesKeyMap(),
+		help:     help.New(),
+	}
+}
+
+func collectResources() []resourceEntry {
+	var entries []resourceEntry
+	for server, resources := range mcp.Resources() {
+		for _, r := range resources {
+			entries = append(entries, resourceEntry{server: server, resource: r})
+		}
+	}
+	for server, templates := range mcp.ResourceTemplates() {
+		for _, tmpl := range templates {
+			entries = append(entries, resourceEntry{server: server, template: tmpl})
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b resourceEntry) int {
+		return cmp.Or(
+			cmp.Compare(a.server, b.server),
+			cmp.Compare(a.name(), b.name()),
+		)
+	})
+	return entries
+}
+
+func (m *mcpResourcesDialogCmp) Init() tea.Cmd {
+	return m.loadPreview()
+}
+
+func (m *mcpResourcesDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(100, m.wWidth-4)
+		m.uriInput.SetWidth(m.width - 6)
+	case resourcePreviewMsg:
+		if msg.uri == m.previewURI {
+			m.preview, m.previewErr = msg.preview, msg.err
+			m.previewLoaded = true
+		}
+	case tea.PasteMsg:
+		if m.expanding {
+			var cmd tea.Cmd
+			m.uriInput, cmd = m.uriInput.Update(msg)
+			return m, cmd
+		}
+	case tea.KeyPressMsg:
+		if m.expanding {
+			return m, m.handleExpandKey(msg)
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.Next):
+			m.move(1)
+			return m, m.loadPreview()
+		case key.Matches(msg, m.keyMap.Previous):
+			m.move(-1)
+			return m, m.loadPreview()
+		case key.Matches(msg, m.keyMap.Attach):
+			if len(m.entries) == 0 {
+				return m, nil
+			}
+			entry := m.entries[m.selected]
+			if entry.template != nil {
+				m.expanding = true
+				m.uriInput.SetValue(entry.template.URITemplate)
+				m.uriInput.CursorEnd()
+				return m, m.uriInput.Focus()
+			}
+			return m, attachResource(entry.server, entry.name(), entry.uri())
+		case key.Matches(msg, m.keyMap.Close):
+			return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return m, nil
+}
+
+func (m *mcpResourcesDialogCmp) handleExpandKey(msg tea.KeyPressMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keyMap.Close):
+		m.expanding = false
+		m.uriInput.Blur()
+		return nil
+	case key.Matches(msg, m.keyMap.Attach):
+		uri := strings.TrimSpace(m.uriInput.Value())
+		if uri == "" || strings.ContainsAny(uri, "{}") {
+			return util.ReportWarn("Fill in all template variables first")
+		}
+		entry := m.entries[m.selected]
+		return attachResource(entry.server, entry.name(), uri)
+	}
+	var cmd tea.Cmd
+	m.uriInput, cmd = m.uriInput.Update(msg)
+	return cmd
+}
+
+func (m *mcpResourcesDialogCmp) move(delta int) {
+	if len(m.entries) == 0 {
+		return
+	}
+	m.selected = max(0, min(m.selected+delta, len(m.entries)-1))
+	if m.selected < m.offset {
+		m.offset = m.selected
+	}
+	if m.selected >= m.offset+listHeight {
+		m.offset = m.selected - listHeight + 1
+	}
+}
+
+// loadPreview fetches the contents of the selected resource. Templates have
+// no contents until their variables are filled in.
+func (m *mcpResourcesDialogCmp) loadPreview() tea.Cmd {
+	m.preview, m.previewErr, m.previewLoaded = "", nil, false
+	if len(m.entries) == 0 {
+		m.previewURI = ""
+		return nil
+	}
+	entry := m.entries[m.selected]
+	m.previewURI = entry.uri()
+	if entry.template != nil {
+		return nil
+	}
+	server, uri := entry.server, entry.uri()
+	return func() tea.Msg {
+		contents, err := mcp.ReadResource(context.Background(), server, uri)
+		if err != nil {
+			return resourcePreviewMsg{uri: uri, err: err}
+		}
+		return resourcePreviewMsg{uri: uri, preview: previewText(contents)}
+	}
+}
+
+func previewText(contents []*mcp.ResourceContents) string {
+	var parts []string
+	for _, c := range contents {
+		if c.Blob != nil {
+			parts = append(parts, fmt.Sprintf("[%s, %d bytes]", cmp.Or(c.MIMEType, "binary"), len(c.Blob)))
+			continue
+		}
+		parts = append(parts, c.Text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// attachResource reads the resource and attaches it to the prompt being
+// composed.
+func attachResource(server, name, uri string) tea.Cmd {
+	return tea.Sequence(
+		util.CmdHandler(dialogs.CloseDialogMsg{}),
+		func() tea.Msg {
+			contents, err := mcp.ReadResource(context.Background(), server, uri)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("Failed to read resource: %v", err)}
+			}
+			attachment, err := resourceAttachment(name, uri, contents)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+			}
+			return filepicker.FilePickedMsg{Attachment: attachment}
+		},
+	)
+}
+
+func resourceAttachment(name, uri string, contents []*mcp.ResourceContents) (message.Attachment, error) {
+	if len(contents) == 0 {
+		return message.Attachment{}, errors.New("resource is empty")
+	}
+	attachment := message.Attachment{
+		FilePath: uri,
+		FileName: name,
+	}
+	if len(contents) == 1 && contents[0].Blob != nil {
+		attachment.Content = contents[0].Blob
+		attachment.MimeType = cmp.Or(contents[0].MIMEType, http.DetectContentType(contents[0].Blob))
+	} else {
+		attachment.Content = []byte(previewText(contents))
+		attachment.MimeType = cmp.Or(contents[0].MIMEType, "text/plain")
+		if !attachment.IsText() {
+			attachment.MimeType = "text/plain"
+		}
+	}
+	if !attachment.IsText() && !attachment.IsImage() {
+		return message.Attachment{}, fmt.Errorf("unsupported resource content type: %s", attachment.MimeType)
+	}
+	if int64(len(attachment.Content)) > filepicker.MaxAttachmentSize {
+		return message.Attachment{}, errors.New("resource too large, max 5MB")
+	}
+	return attachment, nil
+}
+
+func (m *mcpResourcesDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := m.width - 4
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("MCP Resources", width))
+
+	var body []string
+	if len(m.entries) == 0 {
+		body = append(body, t.S().Subtle.Render("No resources exposed by the connected MCP servers."))
+	}
+	end := min(m.offset+listHeight, len(m.entries))
+	for i := m.offset; i < end; i++ {
+		entry := m.entries[i]
+		title := entry.name()
+		if i == m.selected {
+			title = t.S().Base.Foreground(t.Primary).Bold(true).Render(title)
+		}
+		icon := t.ItemOnlineIcon.String()
+		if entry.template != nil {
+			icon = t.ItemBusyIcon.String()
+		}
+		body = append(body, core.Status(core.StatusOpts{
+			Icon:        icon,
+			Title:       title,
+			Description: t.S().Subtle.Render(entry.server),
+		}, width))
+	}
+	if len(m.entries) > 0 {
+		body = append(body, "", m.detailsView())
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (m *mcpResourcesDialogCmp) detailsView() string {
+	t := styles.CurrentTheme()
+	width := m.width - 4
+	entry := m.entries[m.selected]
+
+	lines := []string{t.S().Subtle.Render("URI: ") + ansi.Truncate(entry.uri(), width-5, "…")}
+	if desc := entry.description(); desc != "" {
+		lines = append(lines, t.S().Muted.Width(width).Render(desc))
+	}
+	lines = append(lines, "")
+
+	switch {
+	case m.expanding:
+		lines = append(lines,
+			t.S().Subtle.Render("Fill in the template variables:"),
+			m.uriInput.View(),
+		)
+	case entry.template != nil:
+		lines = append(lines, t.S().Subtle.Render("Resource template, press enter to fill in its variables."))
+	case m.previewErr != nil:
+		lines = append(lines, t.S().Base.Foreground(t.Error).Render(m.previewErr.Error()))
+	case !m.previewLoaded:
+		lines = append(lines, t.S().Subtle.Render("Loading preview..."))
+	case m.preview == "":
+		lines = append(lines, t.S().Subtle.Render("(empty)"))
+	default:
+		preview := strings.Split(m.preview, "\n")
+		if len(preview) > previewLines {
+			preview = append(preview[:previewLines], "…")
+		}
+		for _, line := range preview {
+			lines = append(lines, t.S().Muted.Render(ansi.Truncate(line, width, "…")))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m *mcpResourcesDialogCmp) Cursor() *tea.Cursor {
+	if !m.expanding {
+		return nil
+	}
+	cursor := m.uriInput.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := m.Position()
+	// Everything above the input: border, header, the visible list, the
+	// blank line, the URI and description lines and the input label.
+	visible := min(listHeight, len(m.entries)-m.offset)
+	above := 1 + 2 + visible + 1 + 1 + 1 + 1
+	if desc := m.entries[m.selected].description(); desc != "" {
+		above += lipgloss.Height(styles.CurrentTheme().S().Muted.Width(m.width - 4).Render(desc))
+	}
+	cursor.Y += row + above
+	cursor.X += col + 2
+	return cursor
+}
+
+func (m *mcpResourcesDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *mcpResourcesDialogCmp) ID() dialogs.DialogID {
+	return MCPResourcesDialogID
+}
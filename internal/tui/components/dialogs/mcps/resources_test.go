@@ -0,0 +1,70 @@
+package mcps
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceAttachment(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text contents are joined", func(t *testing.T) {
+		t.Parallel()
+
+		attachment, err := resourceAttachment("notes", "file:///notes", []*mcp.ResourceContents{
+			{URI: "file:///notes/a", MIMEType: "text/markdown", Text: "# A"},
+			{URI: "file:///notes/b", Text: "# B"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "file:///notes", attachment.FilePath)
+		require.Equal(t, "notes", attachment.FileName)
+		require.Equal(t, "text/markdown", attachment.MimeType)
+		require.Equal(t, "# A\n# B", string(attachment.Content))
+	})
+
+	t.Run("json is attached as text", func(t *testing.T) {
+		t.Parallel()
+
+		attachment, err := resourceAttachment("schema", "db://schema", []*mcp.ResourceContents{
+			{URI: "db://schema", MIMEType: "application/json", Text: "{}"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "text/plain", attachment.MimeType)
+	})
+
+	t.Run("images are kept as binary", func(t *testing.T) {
+		t.Parallel()
+
+		attachment, err := resourceAttachment("logo", "img://logo", []*mcp.ResourceContents{
+			{URI: "img://logo", MIMEType: "image/png", Blob: []byte{0x89, 'P', 'N', 'G'}},
+		})
+		require.NoError(t, err)
+		require.True(t, attachment.IsImage())
+	})
+
+	t.Run("other binaries are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := resourceAttachment("archive", "zip://a", []*mcp.ResourceContents{
+			{URI: "zip://a", MIMEType: "application/zip", Blob: []byte{1, 2, 3}},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestResourcePreview(t *testing.T) {
+	t.Parallel()
+
+	m := NewMCPResourcesDialogCmp().(*mcpResourcesDialogCmp)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.entries = []resourceEntry{{server: "notes", resource: &mcp.Resource{Name: "empty", URI: "file:///empty"}}}
+	m.previewURI = "file:///empty"
+	require.Contains(t, m.View(), "Loading preview...")
+
+	m.Update(resourcePreviewMsg{uri: "file:///empty"})
+	require.NotContains(t, m.View(), "Loading preview...")
+	require.Contains(t, m.View(), "(empty)", "resources without contents are told apart from loading ones")
+}
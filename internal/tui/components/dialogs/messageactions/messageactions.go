@@ -0,0 +1,231 @@
+package messageactions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/chat"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/crush/internal/uicmd"
+)
+
+const MessageActionsDialogID dialogs.DialogID = "message_actions"
+
+// EditMessageMsg asks to remove the prompt that led to a message, and what
+// came after it, to edit the prompt and send it again.
+type EditMessageMsg struct {
+	Message message.Message
+}
+
+// MessageActionsDialog offers the actions on a message of the chat.
+type MessageActionsDialog interface {
+	dialogs.DialogModel
+}
+
+type action struct {
+	title       string
+	description string
+	run         func(message.Message) tea.Cmd
+}
+
+type messageActionsDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	message message.Message
+	actions []action
+
+	selected int
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewMessageActionsDialogCmp creates the dialog of the actions on msg.
+func NewMessageActionsDialogCmp(msg message.Message) MessageActionsDialog {
+	return &messageActionsDialogCmp{
+		message: msg,
+		actions: actionsFor(msg),
+		keyMap:  DefaultKeyMap(),
+		help:    help.New(),
+	}
+}
+
+// actionsFor returns the actions that apply to msg, the ones about its text
+// only when it has some.
+func actionsFor(msg message.Message) []action {
+	edit := action{
+		title:       "Edit and regenerate",
+		description: "remove the prompt and what followed, to send it again",
+		run: func(msg message.Message) tea.Cmd {
+			return util.CmdHandler(EditMessageMsg{Message: msg})
+		},
+	}
+	if msg.Role == message.Assistant {
+		edit.title = "Edit prompt and regenerate"
+	}
+	export := action{
+		title:       "Export",
+		description: "save as Markdown in the working directory",
+		run:         export,
+	}
+	if strings.TrimSpace(msg.Content().Text) == "" {
+		return []action{edit, export}
+	}
+	return []action{
+		{
+			title:       "Copy",
+			description: "copy the text",
+			run: func(msg message.Message) tea.Cmd {
+				return util.CopyToClipboard(msg.Content().Text, "Message")
+			},
+		},
+		{
+			title:       "Quote reply",
+			description: "quote it in the prompt",
+			run: func(msg message.Message) tea.Cmd {
+				return util.CmdHandler(chat.QuoteMsg{Text: msg.Content().Text})
+			},
+		},
+		edit,
+		export,
+		{
+			title:       "Create template",
+			description: "save as a user command",
+			run: func(msg message.Message) tea.Cmd {
+				id, err := uicmd.SaveUserCommand(msg.Content().Text)
+				if err != nil {
+					return util.ReportError(fmt.Errorf("failed to save the template: %w", err))
+				}
+				return util.ReportInfo("Saved as " + id)
+			},
+		},
+		{
+			title:       "Send to new session",
+			description: "start a new session with it",
+			run: func(msg message.Message) tea.Cmd {
+				return util.CmdHandler(commands.StartSessionMsg{Content: msg.Content().Text})
+			},
+		},
+	}
+}
+
+// export saves msg as Markdown in the working directory.
+func export(msg message.Message) tea.Cmd {
+	id := msg.ID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	path := filepath.Join(config.Get().WorkingDir(), "crush-message-"+id+".md")
+	if err := os.WriteFile(path, []byte(markdown(msg)), 0o644); err != nil {
+		return util.ReportError(fmt.Errorf("failed to export the message: %w", err))
+	}
+	return util.ReportInfo("Exported to " + filepath.Base(path))
+}
+
+// markdown renders msg, with the tools it called.
+func markdown(msg message.Message) string {
+	var sb strings.Builder
+	if msg.Role == message.User {
+		sb.WriteString("## User\n")
+	} else {
+		sb.WriteString("## Assistant\n")
+	}
+	if text := strings.TrimSpace(msg.Content().Text); text != "" {
+		sb.WriteString("\n" + text + "\n")
+	}
+	for _, bc := range msg.BinaryContent() {
+		fmt.Fprintf(&sb, "\nAttached: `%s`\n", bc.Path)
+	}
+	for _, tc := range msg.ToolCalls() {
+		fmt.Fprintf(&sb, "\n**Tool call** `%s`\n\n```json\n%s\n```\n", tc.Name, tc.Input)
+	}
+	return sb.String()
+}
+
+func (m *messageActionsDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (m *messageActionsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(70, m.wWidth-4)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, m.keyMap.Next):
+			m.selected = (m.selected + 1) % len(m.actions)
+		case key.Matches(msg, m.keyMap.Previous):
+			m.selected = (m.selected - 1 + len(m.actions)) % len(m.actions)
+		case key.Matches(msg, m.keyMap.Select):
+			return m, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				m.actions[m.selected].run(m.message),
+			)
+		case key.Matches(msg, m.keyMap.Close):
+			return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return m, nil
+}
+
+func (m *messageActionsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	title := "Prompt Actions"
+	if m.message.Role == message.Assistant {
+		title = "Response Actions"
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, m.width-4))
+
+	var rows []string
+	for i, a := range m.actions {
+		opts := core.StatusOpts{
+			Icon:        " ",
+			Title:       a.title,
+			TitleColor:  t.FgBase,
+			Description: a.description,
+		}
+		if i == m.selected {
+			opts.Icon = t.S().Base.Foreground(t.Primary).Render(styles.ArrowRightIcon)
+			opts.TitleColor = t.Primary
+		}
+		rows = append(rows, core.Status(opts, m.width-4))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, rows...)),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (m *messageActionsDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *messageActionsDialogCmp) ID() dialogs.DialogID {
+	return MessageActionsDialogID
+}
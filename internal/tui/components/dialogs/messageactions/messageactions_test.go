@@ -0,0 +1,43 @@
+package messageactions
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func titles(actions []action) []string {
+	var titles []string
+	for _, a := range actions {
+		titles = append(titles, a.title)
+	}
+	return titles
+}
+
+func TestActionsFor(t *testing.T) {
+	t.Parallel()
+
+	prompt := message.Message{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Fix the parser"}}}
+	require.Equal(t, []string{
+		"Copy",
+		"Quote reply",
+		"Edit and regenerate",
+		"Export",
+		"Create template",
+		"Send to new session",
+	}, titles(actionsFor(prompt)))
+
+	toolsOnly := message.Message{Role: message.Assistant, Parts: []message.ContentPart{message.ToolCall{ID: "1", Name: "view"}}}
+	require.Equal(t, []string{"Edit prompt and regenerate", "Export"}, titles(actionsFor(toolsOnly)))
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	msg := message.Message{Role: message.Assistant, Parts: []message.ContentPart{
+		message.TextContent{Text: "Let me look."},
+		message.ToolCall{ID: "1", Name: "view", Input: `{"file_path":"main.go"}`},
+	}}
+	require.Equal(t, "## Assistant\n\nLet me look.\n\n**Tool call** `view`\n\n```json\n{\"file_path\":\"main.go\"}\n```\n", markdown(msg))
+}
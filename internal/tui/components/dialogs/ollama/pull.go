@@ -0,0 +1,197 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/ollama"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const PullDialogID dialogs.DialogID = "ollama_pull"
+
+// PullDialog pulls a model on the local Ollama server, showing how far it
+// got, and makes it selectable once pulled.
+type PullDialog interface {
+	dialogs.DialogModel
+}
+
+type progressMsg struct {
+	progress ollama.Progress
+}
+
+type pulledMsg struct {
+	model ollama.Model
+	err   error
+}
+
+type pullDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	model    string
+	client   *ollama.Client
+	ctx      context.Context
+	cancel   context.CancelFunc
+	msgs     chan tea.Msg
+	progress ollama.Progress
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewPullDialogCmp creates the dialog pulling model.
+func NewPullDialogCmp(model string) PullDialog {
+	return &pullDialogCmp{
+		model:  strings.TrimSpace(model),
+		client: ollama.New(config.Get().OllamaBaseURL()),
+		msgs:   make(chan tea.Msg),
+		keyMap: DefaultKeyMap(),
+		help:   help.New(),
+	}
+}
+
+func (m *pullDialogCmp) Init() tea.Cmd {
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	go m.pull(m.ctx)
+	return m.next()
+}
+
+// pull pulls the model, sending its progress and then its outcome.
+func (m *pullDialogCmp) pull(ctx context.Context) {
+	send := func(msg tea.Msg) {
+		select {
+		case m.msgs <- msg:
+		case <-ctx.Done():
+		}
+	}
+	err := m.client.Pull(ctx, m.model, func(p ollama.Progress) {
+		send(progressMsg{progress: p})
+	})
+	var model ollama.Model
+	if err == nil {
+		model, err = m.client.Show(ctx, m.model)
+	}
+	send(pulledMsg{model: model, err: err})
+}
+
+// next waits for the next update of the pull, until it is stopped.
+func (m *pullDialogCmp) next() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-m.msgs:
+			return msg
+		case <-m.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *pullDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(70, m.wWidth-4)
+	case progressMsg:
+		m.progress = msg.progress
+		return m, m.next()
+	case pulledMsg:
+		m.cancel()
+		closeDialog := util.CmdHandler(dialogs.CloseDialogMsg{})
+		if msg.err != nil {
+			return m, tea.Batch(closeDialog, util.ReportError(fmt.Errorf("couldn't pull %s: %w", m.model, msg.err)))
+		}
+		if err := config.Get().AddPulledOllamaModel(msg.model); err != nil {
+			return m, tea.Batch(closeDialog, util.ReportError(err))
+		}
+		info := fmt.Sprintf("Pulled %s, switch to it with Switch Model", m.model)
+		if !msg.model.Supports(ollama.CapabilityTools) {
+			info = fmt.Sprintf("Pulled %s; it can't call tools, so the agent will only chat", m.model)
+		}
+		return m, tea.Batch(closeDialog, util.ReportInfo(info))
+	case tea.KeyPressMsg:
+		if key.Matches(msg, m.keyMap.Cancel) {
+			m.cancel()
+			return m, tea.Batch(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.ReportWarn("Stopped pulling "+m.model),
+			)
+		}
+	}
+	return m, nil
+}
+
+func (m *pullDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := m.width - 4
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Pull "+m.model, width))
+
+	status := m.progress.Status
+	if status == "" {
+		status = "connecting to ollama..."
+	}
+	lines := []string{t.S().Text.Render(status)}
+	if m.progress.Total > 0 {
+		fraction := m.progress.Fraction()
+		lines = append(lines,
+			progressBar(fraction, width),
+			t.S().Subtle.Render(fmt.Sprintf("%s / %s (%.0f%%)",
+				formatBytes(m.progress.Completed), formatBytes(m.progress.Total), fraction*100)),
+		)
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func progressBar(fraction float64, width int) string {
+	t := styles.CurrentTheme()
+	width = max(0, width)
+	filled := int(fraction * float64(width))
+	return t.S().Base.Foreground(t.Primary).Render(strings.Repeat("█", filled)) +
+		t.S().Subtle.Render(strings.Repeat("░", width-filled))
+}
+
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
+
+func (m *pullDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *pullDialogCmp) ID() dialogs.DialogID {
+	return PullDialogID
+}
@@ -0,0 +1,437 @@
+package onboarding
+
+import (
+	"cmp"
+	"fmt"
+	"os/exec"
+	"slices"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/keyring"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const OnboardingDialogID dialogs.DialogID = "onboarding"
+
+// maxRows is how many providers are listed at once.
+const maxRows = 10
+
+// OnboardingDialog walks through setting Crush up: the provider, its API
+// key, the theme and the editor, then writes the configuration.
+type OnboardingDialog interface {
+	dialogs.DialogModel
+}
+
+type step int
+
+const (
+	stepProvider step = iota
+	stepAPIKey
+	stepTheme
+	stepTools
+)
+
+var stepTitles = []string{"Provider", "API Key", "Theme", "Tools"}
+
+// SetupCompleteMsg is sent once the wizard wrote the configuration.
+type SetupCompleteMsg struct{}
+
+type (
+	verifiedMsg struct {
+		err error
+	}
+	finishedMsg struct {
+		err error
+	}
+)
+
+type onboardingDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	step     step
+	selected int
+
+	providers []catwalk.Provider
+	// provider is the one picked in the first step.
+	provider catwalk.Provider
+	themes   []string
+	tools    []tool
+	editors  []string
+
+	apiKey    textinput.Model
+	verifying bool
+	keyErr    error
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewOnboardingDialogCmp creates the setup wizard.
+func NewOnboardingDialogCmp() OnboardingDialog {
+	t := styles.CurrentTheme()
+	apiKey := textinput.New()
+	apiKey.SetVirtualCursor(false)
+	apiKey.Placeholder = "Paste your API key"
+	apiKey.EchoMode = textinput.EchoPassword
+	apiKey.SetStyles(t.S().TextInput)
+
+	tools := detectTools(exec.LookPath)
+	themes := styles.DefaultManager().List()
+	slices.Sort(themes)
+	d := &onboardingDialogCmp{
+		themes:  themes,
+		tools:   tools,
+		editors: editorChoices(tools),
+		apiKey:  apiKey,
+		keyMap:  DefaultKeyMap(),
+		help:    help.New(),
+	}
+	if providers, err := config.Providers(config.Get()); err == nil {
+		d.providers = providers
+	}
+	return d
+}
+
+func (d *onboardingDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+// configured reports whether the provider already has an API key, e.g.
+// from the environment.
+func configured(id catwalk.InferenceProvider) bool {
+	provider, ok := config.Get().Providers.Get(string(id))
+	return ok && provider.APIKey != ""
+}
+
+// options returns how many choices the current step has.
+func (d *onboardingDialogCmp) options() int {
+	switch d.step {
+	case stepProvider:
+		return len(d.providers)
+	case stepTheme:
+		return len(d.themes)
+	case stepTools:
+		return len(d.editors)
+	default:
+		return 0
+	}
+}
+
+// goTo moves to the step, selecting what's in effect.
+func (d *onboardingDialogCmp) goTo(s step) tea.Cmd {
+	d.step = s
+	d.selected = 0
+	switch s {
+	case stepAPIKey:
+		d.keyErr = nil
+		d.apiKey.SetValue("")
+		return d.apiKey.Focus()
+	case stepTheme:
+		d.selected = max(0, slices.Index(d.themes, styles.CurrentTheme().Name))
+	case stepProvider:
+		d.selected = max(0, slices.IndexFunc(d.providers, func(p catwalk.Provider) bool { return p.ID == d.provider.ID }))
+	case stepTools:
+		d.selected = max(0, slices.Index(d.editors, config.Get().Options.TUI.Editor))
+	}
+	d.apiKey.Blur()
+	return nil
+}
+
+func (d *onboardingDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(80, d.wWidth-4)
+		d.apiKey.SetWidth(d.width - 6)
+	case verifiedMsg:
+		d.verifying = false
+		if msg.err != nil {
+			d.keyErr = msg.err
+			return d, nil
+		}
+		if err := config.Get().SetProviderAPIKey(string(d.provider.ID), d.apiKey.Value()); err != nil {
+			d.keyErr = err
+			return d, nil
+		}
+		return d, d.goTo(stepTheme)
+	case finishedMsg:
+		if msg.err != nil {
+			return d, util.ReportError(msg.err)
+		}
+		return d, tea.Sequence(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			util.ReportInfo("Crush is set up, change any of it later in the command palette"),
+			util.CmdHandler(SetupCompleteMsg{}),
+		)
+	case tea.PasteMsg:
+		if d.step == stepAPIKey {
+			var cmd tea.Cmd
+			d.apiKey, cmd = d.apiKey.Update(msg)
+			return d, cmd
+		}
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Close):
+			if d.step == stepProvider {
+				return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+			}
+			previous := d.step - 1
+			if previous == stepAPIKey && configured(d.provider.ID) {
+				previous = stepProvider
+			}
+			return d, d.goTo(previous)
+		case key.Matches(msg, d.keyMap.Select):
+			return d, d.next()
+		case d.step == stepAPIKey:
+			var cmd tea.Cmd
+			d.apiKey, cmd = d.apiKey.Update(msg)
+			return d, cmd
+		case key.Matches(msg, d.keyMap.Next):
+			d.selected = min(d.selected+1, d.options()-1)
+			d.preview()
+		case key.Matches(msg, d.keyMap.Previous):
+			d.selected = max(d.selected-1, 0)
+			d.preview()
+		}
+	}
+	return d, nil
+}
+
+// preview applies the theme selected right away.
+func (d *onboardingDialogCmp) preview() {
+	if d.step == stepTheme && len(d.themes) > 0 {
+		_ = styles.DefaultManager().SetTheme(d.themes[d.selected])
+	}
+}
+
+// next validates the current step and moves on, writing the
+// configuration after the last one.
+func (d *onboardingDialogCmp) next() tea.Cmd {
+	switch d.step {
+	case stepProvider:
+		if len(d.providers) == 0 {
+			return util.ReportError(fmt.Errorf("no providers available, check your connection"))
+		}
+		d.provider = d.providers[d.selected]
+		if configured(d.provider.ID) {
+			return d.goTo(stepTheme)
+		}
+		return d.goTo(stepAPIKey)
+	case stepAPIKey:
+		if d.apiKey.Value() == "" || d.verifying {
+			return nil
+		}
+		d.verifying = true
+		d.keyErr = nil
+		provider := d.provider
+		providerConfig := config.ProviderConfig{
+			ID:      string(provider.ID),
+			Name:    provider.Name,
+			APIKey:  d.apiKey.Value(),
+			Type:    provider.Type,
+			BaseURL: provider.APIEndpoint,
+		}
+		return func() tea.Msg {
+			return verifiedMsg{err: providerConfig.TestConnection(config.Get().Resolver())}
+		}
+	case stepTheme:
+		return d.goTo(stepTools)
+	default:
+		return d.finish()
+	}
+}
+
+// finish writes the models of the provider, the theme and the editor.
+func (d *onboardingDialogCmp) finish() tea.Cmd {
+	provider := d.provider
+	theme := styles.CurrentTheme().Name
+	editor := ""
+	if len(d.editors) > 0 {
+		editor = d.editors[d.selected]
+	}
+	return func() tea.Msg {
+		cfg := config.Get()
+		err := cmp.Or(
+			selectModel(cfg, provider, config.SelectedModelTypeLarge, provider.DefaultLargeModelID),
+			selectModel(cfg, provider, config.SelectedModelTypeSmall, provider.DefaultSmallModelID),
+			cfg.SetTheme(theme),
+		)
+		if err == nil && editor != "" {
+			err = cfg.SetEditor(editor)
+		}
+		if err == nil {
+			cfg.SetupAgents()
+		}
+		return finishedMsg{err: err}
+	}
+}
+
+func selectModel(cfg *config.Config, provider catwalk.Provider, modelType config.SelectedModelType, modelID string) error {
+	model := cfg.GetModel(string(provider.ID), modelID)
+	if model == nil {
+		return fmt.Errorf("model %s not found for provider %s", modelID, provider.Name)
+	}
+	return cfg.UpdatePreferredModel(modelType, config.SelectedModel{
+		Model:           modelID,
+		Provider:        string(provider.ID),
+		ReasoningEffort: model.DefaultReasoningEffort,
+		MaxTokens:       model.DefaultMaxTokens,
+	})
+}
+
+func (d *onboardingDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+
+	title := fmt.Sprintf("Set Up Crush · %d/%d %s", d.step+1, len(stepTitles), stepTitles[d.step])
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, width))
+
+	var body []string
+	switch d.step {
+	case stepProvider:
+		body = d.providersView(width)
+	case stepAPIKey:
+		body = d.apiKeyView(width)
+	case stepTheme:
+		body = append(body, t.S().Subtle.Render("Pick the colors of the interface."), "")
+		for i, theme := range d.themes {
+			body = append(body, d.row(i, theme, "", width))
+		}
+	case stepTools:
+		body = d.toolsView(width)
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+// row renders an option, highlighted when it's selected.
+func (d *onboardingDialogCmp) row(i int, title, description string, width int) string {
+	t := styles.CurrentTheme()
+	icon := t.ItemOfflineIcon.String()
+	if i == d.selected {
+		icon = t.ItemOnlineIcon.String()
+		title = t.S().Base.Foreground(t.Primary).Bold(true).Render(title)
+	}
+	return core.Status(core.StatusOpts{
+		Icon:        icon,
+		Title:       title,
+		Description: t.S().Subtle.Render(description),
+	}, width)
+}
+
+func (d *onboardingDialogCmp) providersView(width int) []string {
+	t := styles.CurrentTheme()
+	body := []string{t.S().Subtle.Render("Pick the provider of the models Crush talks to."), ""}
+	if len(d.providers) == 0 {
+		return append(body, t.S().Subtle.Render("No providers available, check your connection."))
+	}
+	start := max(0, min(d.selected-maxRows/2, len(d.providers)-maxRows))
+	end := min(len(d.providers), start+maxRows)
+	for i := start; i < end; i++ {
+		description := ""
+		if configured(d.providers[i].ID) {
+			description = "already configured"
+		}
+		body = append(body, d.row(i, d.providers[i].Name, description, width))
+	}
+	return body
+}
+
+func (d *onboardingDialogCmp) apiKeyView(width int) []string {
+	t := styles.CurrentTheme()
+	body := []string{
+		t.S().Text.Width(width).Render(fmt.Sprintf("Enter your %s API key. It's kept in the %s.", d.provider.Name, keyring.Backend())),
+		"",
+		d.apiKey.View(),
+		"",
+	}
+	switch {
+	case d.verifying:
+		body = append(body, t.S().Subtle.Render("Verifying the key…"))
+	case d.keyErr != nil:
+		body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render("The key didn't work: "+d.keyErr.Error()))
+	}
+	return body
+}
+
+func (d *onboardingDialogCmp) toolsView(width int) []string {
+	t := styles.CurrentTheme()
+	body := []string{t.S().Subtle.Render("Programs Crush works with:"), ""}
+	for _, tool := range d.tools {
+		if tool.editor != "" && !tool.found {
+			continue
+		}
+		icon := t.ItemOfflineIcon.String()
+		description := "not installed, for " + tool.purpose
+		if tool.found {
+			icon = t.ItemOnlineIcon.String()
+			description = "for " + tool.purpose
+		}
+		body = append(body, core.Status(core.StatusOpts{
+			Icon:        icon,
+			Title:       tool.name,
+			Description: t.S().Subtle.Render(description),
+		}, width))
+	}
+	body = append(body, "")
+	if len(d.editors) == 0 {
+		return append(body, t.S().Subtle.Render("No editor found, prompts are edited in "+util.Editor()+"."))
+	}
+	body = append(body, t.S().Text.Render("Edit prompts and commit messages with:"), "")
+	for i, editor := range d.editors {
+		title := editor
+		if editor == "" {
+			title = ansi.Truncate("$EDITOR ("+util.Editor()+")", width-4, "…")
+		}
+		body = append(body, d.row(i, title, "", width))
+	}
+	return body
+}
+
+func (d *onboardingDialogCmp) Cursor() *tea.Cursor {
+	if d.step != stepAPIKey {
+		return nil
+	}
+	cursor := d.apiKey.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := d.Position()
+	cursor.Y += row + 5 // border, title, padding and the explanation
+	cursor.X += col + 2 // border and padding
+	return cursor
+}
+
+func (d *onboardingDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *onboardingDialogCmp) ID() dialogs.DialogID {
+	return OnboardingDialogID
+}
@@ -0,0 +1,57 @@
+package onboarding
+
+import (
+	"os"
+)
+
+// tool is an external program Crush works with.
+type tool struct {
+	name string
+	// purpose tells what it's good for alongside Crush.
+	purpose string
+	// editor is the command editing files with it, when it's an editor.
+	editor string
+	found  bool
+}
+
+// knownTools are the programs looked up, the editors in order of
+// preference.
+var knownTools = []tool{
+	{name: "gh", purpose: "pull requests, reviews, issues and CI status"},
+	{name: "lazygit", purpose: "reviewing and staging the changes of the agent"},
+	{name: "nvim", purpose: "editing prompts and commit messages", editor: "nvim"},
+	{name: "vim", purpose: "editing prompts and commit messages", editor: "vim"},
+	{name: "hx", purpose: "editing prompts and commit messages", editor: "hx"},
+	{name: "micro", purpose: "editing prompts and commit messages", editor: "micro"},
+	{name: "nano", purpose: "editing prompts and commit messages", editor: "nano"},
+	{name: "code", purpose: "editing prompts and commit messages", editor: "code --wait"},
+	{name: "zed", purpose: "editing prompts and commit messages", editor: "zed --wait"},
+}
+
+// detectTools returns the known tools with whether they're installed,
+// using lookPath to find them.
+func detectTools(lookPath func(string) (string, error)) []tool {
+	tools := make([]tool, len(knownTools))
+	for i, t := range knownTools {
+		_, err := lookPath(t.name)
+		t.found = err == nil
+		tools[i] = t
+	}
+	return tools
+}
+
+// editorChoices returns the editors to pick from: the one of the
+// environment, left empty so that it keeps following it, then the
+// installed ones.
+func editorChoices(tools []tool) []string {
+	var choices []string
+	if os.Getenv("VISUAL") != "" || os.Getenv("EDITOR") != "" {
+		choices = append(choices, "")
+	}
+	for _, t := range tools {
+		if t.editor != "" && t.found {
+			choices = append(choices, t.editor)
+		}
+	}
+	return choices
+}
@@ -0,0 +1,34 @@
+package onboarding
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectTools(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+
+	installed := []string{"gh", "hx", "code"}
+	tools := detectTools(func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	})
+
+	var found []string
+	for _, tool := range tools {
+		if tool.found {
+			found = append(found, tool.name)
+		}
+	}
+	require.Equal(t, []string{"gh", "hx", "code"}, found)
+	require.Equal(t, []string{"hx", "code --wait"}, editorChoices(tools))
+
+	t.Setenv("EDITOR", "emacs")
+	require.Equal(t, []string{"", "hx", "code --wait"}, editorChoices(tools))
+}
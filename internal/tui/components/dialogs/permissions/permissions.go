@@ -92,7 +92,7 @@ func (p *permissionDialogCmp) Init() tea.Cmd {
 }
 
 func (p *permissionDialogCmp) supportsDiffView() bool {
-	return p.permission.ToolName == tools.EditToolName || p.permission.ToolName == tools.WriteToolName || p.permission.ToolName == tools.MultiEditToolName
+	return p.permission.ToolName == tools.EditToolName || p.permission.ToolName == tools.WriteToolName || p.permission.ToolName == tools.MultiEditToolName || p.permission.ToolName == tools.PatchToolName
 }
 
 func (p *permissionDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
@@ -353,6 +353,21 @@ func (p *permissionDialogCmp) renderHeader() string {
 			baseStyle.Render(strings.Repeat(" ", p.width)),
 		)
 
+	case tools.PatchToolName:
+		params := p.permission.Params.(tools.PatchPermissionsParams)
+		fileKey := t.S().Muted.Render("File")
+		filePath := t.S().Text.
+			Width(p.width - lipgloss.Width(fileKey)).
+			Render(fmt.Sprintf(" %s", fsext.PrettyPath(params.FilePath)))
+		headerParts = append(headerParts,
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				fileKey,
+				filePath,
+			),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+		)
+
 	case tools.WriteToolName:
 		params := p.permission.Params.(tools.WritePermissionsParams)
 		fileKey := t.S().Muted.Render("File")
@@ -381,6 +396,29 @@ func (p *permissionDialogCmp) renderHeader() string {
 			),
 			baseStyle.Render(strings.Repeat(" ", p.width)),
 		)
+	case tools.ReplaceToolName:
+		params := p.permission.Params.(tools.ReplacePermissionsParams)
+		findKey := t.S().Muted.Render("Find")
+		findValue := t.S().Text.
+			Width(p.width - lipgloss.Width(findKey)).
+			Render(fmt.Sprintf(" %s", params.Pattern))
+		replaceKey := t.S().Muted.Render("Replace")
+		replaceValue := t.S().Text.
+			Width(p.width - lipgloss.Width(replaceKey)).
+			Render(fmt.Sprintf(" %s", params.Replacement))
+		headerParts = append(headerParts,
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				findKey,
+				findValue,
+			),
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				replaceKey,
+				replaceValue,
+			),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+		)
 	case tools.FetchToolName:
 		headerParts = append(headerParts,
 			baseStyle.Render(strings.Repeat(" ", p.width)),
@@ -391,6 +429,11 @@ func (p *permissionDialogCmp) renderHeader() string {
 			baseStyle.Render(strings.Repeat(" ", p.width)),
 			t.S().Muted.Width(p.width).Bold(true).Render("Web"),
 		)
+	case tools.WebSearchToolName:
+		headerParts = append(headerParts,
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+			t.S().Muted.Width(p.width).Bold(true).Render("Search"),
+		)
 	case tools.ViewToolName:
 		params := p.permission.Params.(tools.ViewPermissionsParams)
 		fileKey := t.S().Muted.Render("File")
@@ -443,10 +486,16 @@ func (p *permissionDialogCmp) getOrGenerateContent() string {
 		content = p.generateWriteContent()
 	case tools.MultiEditToolName:
 		content = p.generateMultiEditContent()
+	case tools.PatchToolName:
+		content = p.generatePatchContent()
+	case tools.ReplaceToolName:
+		content = p.generateReplaceContent()
 	case tools.FetchToolName:
 		content = p.generateFetchContent()
 	case tools.AgenticFetchToolName:
 		content = p.generateAgenticFetchContent()
+	case tools.WebSearchToolName:
+		content = p.generateWebSearchContent()
 	case tools.ViewToolName:
 		content = p.generateViewContent()
 	case tools.LSToolName:
@@ -548,6 +597,29 @@ func (p *permissionDialogCmp) generateWriteContent() string {
 	return ""
 }
 
+// generateReplaceContent renders the diff of every file changed by the
+// replacement, one after the other. The viewport scrolls through them.
+func (p *permissionDialogCmp) generateReplaceContent() string {
+	t := styles.CurrentTheme()
+	if pr, ok := p.permission.Params.(tools.ReplacePermissionsParams); ok {
+		var parts []string
+		for _, f := range pr.Files {
+			formatter := core.DiffFormatter().
+				Before(fsext.PrettyPath(f.FilePath), f.OldContent).
+				After(fsext.PrettyPath(f.FilePath), f.NewContent).
+				Width(p.contentViewPort.Width()).
+				Unified()
+			parts = append(parts,
+				t.S().Muted.Bold(true).Render(fsext.PrettyPath(f.FilePath)),
+				formatter.String(),
+				"",
+			)
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, parts...)
+	}
+	return ""
+}
+
 func (p *permissionDialogCmp) generateDownloadContent() string {
 	t := styles.CurrentTheme()
 	baseStyle := t.S().Base.Background(t.BgSubtle)
@@ -588,6 +660,25 @@ func (p *permissionDialogCmp) generateMultiEditContent() string {
 	return ""
 }
 
+func (p *permissionDialogCmp) generatePatchContent() string {
+	if pr, ok := p.permission.Params.(tools.PatchPermissionsParams); ok {
+		formatter := core.DiffFormatter().
+			Before(fsext.PrettyPath(pr.FilePath), pr.OldContent).
+			After(fsext.PrettyPath(pr.FilePath), pr.NewContent).
+			Height(p.contentViewPort.Height()).
+			Width(p.contentViewPort.Width()).
+			XOffset(p.diffXOffset).
+			YOffset(p.diffYOffset)
+		if p.useDiffSplitMode() {
+			formatter = formatter.Split()
+		} else {
+			formatter = formatter.Unified()
+		}
+		return formatter.String()
+	}
+	return ""
+}
+
 func (p *permissionDialogCmp) generateFetchContent() string {
 	t := styles.CurrentTheme()
 	baseStyle := t.S().Base.Background(t.BgSubtle)
@@ -620,6 +711,20 @@ func (p *permissionDialogCmp) generateAgenticFetchContent() string {
 	return ""
 }
 
+func (p *permissionDialogCmp) generateWebSearchContent() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base.Background(t.BgSubtle)
+	if pr, ok := p.permission.Params.(tools.WebSearchPermissionsParams); ok {
+		content := fmt.Sprintf("Engine: %s\n\nQuery: %s", pr.Provider, pr.Query)
+		finalContent := baseStyle.
+			Padding(1, 2).
+			Width(p.contentViewPort.Width()).
+			Render(content)
+		return finalContent
+	}
+	return ""
+}
+
 func (p *permissionDialogCmp) generateViewContent() string {
 	t := styles.CurrentTheme()
 	baseStyle := t.S().Base.Background(t.BgSubtle)
@@ -823,12 +928,21 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 	case tools.MultiEditToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.8)
+	case tools.PatchToolName:
+		p.width = int(float64(p.wWidth) * 0.8)
+		p.height = int(float64(p.wHeight) * 0.8)
+	case tools.ReplaceToolName:
+		p.width = int(float64(p.wWidth) * 0.8)
+		p.height = int(float64(p.wHeight) * 0.8)
 	case tools.FetchToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.3)
 	case tools.AgenticFetchToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.4)
+	case tools.WebSearchToolName:
+		p.width = int(float64(p.wWidth) * 0.8)
+		p.height = int(float64(p.wHeight) * 0.3)
 	case tools.ViewToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.4)
@@ -0,0 +1,125 @@
+package plans
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the plan dialog.
+type KeyMap struct {
+	Up,
+	Down,
+	MoveUp,
+	MoveDown,
+	Edit,
+	Add,
+	Delete,
+	Approve,
+	Reject,
+	Confirm,
+	Cancel key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "previous"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "next"),
+		),
+		MoveUp: key.NewBinding(
+			key.WithKeys("shift+up", "K"),
+			key.WithHelp("K", "move up"),
+		),
+		MoveDown: key.NewBinding(
+			key.WithKeys("shift+down", "J"),
+			key.WithHelp("J", "move down"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit"),
+		),
+		Add: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "add"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("d", "x"),
+			key.WithHelp("d", "remove"),
+		),
+		Approve: key.NewBinding(
+			key.WithKeys("enter", "ctrl+y"),
+			key.WithHelp("enter", "approve"),
+		),
+		Reject: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "reject"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "save step"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Up,
+		k.Down,
+		k.MoveUp,
+		k.MoveDown,
+		k.Edit,
+		k.Add,
+		k.Delete,
+		k.Approve,
+		k.Reject,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	m := [][]key.Binding{}
+	slice := k.KeyBindings()
+	for i := 0; i < len(slice); i += 4 {
+		end := min(i+4, len(slice))
+		m = append(m, slice[i:end])
+	}
+	return m
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Edit,
+		k.Add,
+		k.Delete,
+		k.MoveUp,
+		k.Approve,
+		k.Reject,
+	}
+}
+
+// editingKeyMap is shown while a step is being edited.
+type editingKeyMap struct {
+	KeyMap
+}
+
+// ShortHelp implements help.KeyMap.
+func (k editingKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Confirm,
+		k.Cancel,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k editingKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
@@ -0,0 +1,270 @@
+package plans
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/plan"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const PlanDialogID dialogs.DialogID = "plan"
+
+// PlanResponseMsg is sent when the user approves or rejects a plan.
+type PlanResponseMsg struct {
+	Request  plan.Request
+	Steps    []string
+	Approved bool
+}
+
+// PlanDialog lets the user review the steps proposed by the agent.
+type PlanDialog interface {
+	dialogs.DialogModel
+}
+
+type planDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	request plan.Request
+	steps   []string
+	cursor  int
+
+	// editing is true while the step under the cursor is being edited;
+	// adding is true when that step was just inserted.
+	editing bool
+	adding  bool
+	input   textinput.Model
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewPlanDialogCmp creates a dialog for reviewing the given plan.
+func NewPlanDialogCmp(request plan.Request) PlanDialog {
+	t := styles.CurrentTheme()
+	input := textinput.New()
+	input.SetVirtualCursor(false)
+	input.Prompt = ""
+	input.SetStyles(t.S().TextInput)
+
+	return &planDialogCmp{
+		request: request,
+		steps:   slices.Clone(request.Steps),
+		input:   input,
+		keyMap:  DefaultKeyMap(),
+		help:    help.New(),
+	}
+}
+
+func (p *planDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (p *planDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.wWidth = msg.Width
+		p.wHeight = msg.Height
+		p.width = min(90, p.wWidth-4)
+		p.input.SetWidth(p.width - 10)
+	case tea.PasteMsg:
+		if p.editing {
+			var cmd tea.Cmd
+			p.input, cmd = p.input.Update(msg)
+			return p, cmd
+		}
+	case tea.KeyPressMsg:
+		if p.editing {
+			return p, p.handleEditingKey(msg)
+		}
+		switch {
+		case key.Matches(msg, p.keyMap.Up):
+			p.cursor = max(0, p.cursor-1)
+		case key.Matches(msg, p.keyMap.Down):
+			p.cursor = max(0, min(len(p.steps)-1, p.cursor+1))
+		case key.Matches(msg, p.keyMap.MoveUp):
+			if p.cursor > 0 {
+				p.steps[p.cursor], p.steps[p.cursor-1] = p.steps[p.cursor-1], p.steps[p.cursor]
+				p.cursor--
+			}
+		case key.Matches(msg, p.keyMap.MoveDown):
+			if p.cursor < len(p.steps)-1 {
+				p.steps[p.cursor], p.steps[p.cursor+1] = p.steps[p.cursor+1], p.steps[p.cursor]
+				p.cursor++
+			}
+		case key.Matches(msg, p.keyMap.Edit):
+			if len(p.steps) == 0 {
+				return p, nil
+			}
+			return p, p.startEditing(p.steps[p.cursor], false)
+		case key.Matches(msg, p.keyMap.Add):
+			if len(p.steps) > 0 {
+				p.cursor++
+			}
+			p.steps = slices.Insert(p.steps, p.cursor, "")
+			return p, p.startEditing("", true)
+		case key.Matches(msg, p.keyMap.Delete):
+			if len(p.steps) == 0 {
+				return p, nil
+			}
+			p.steps = slices.Delete(p.steps, p.cursor, p.cursor+1)
+			p.cursor = max(0, min(p.cursor, len(p.steps)-1))
+		case key.Matches(msg, p.keyMap.Approve):
+			if len(p.steps) == 0 {
+				return p, util.ReportWarn("The plan needs at least one step")
+			}
+			return p, p.respond(true)
+		case key.Matches(msg, p.keyMap.Reject):
+			return p, p.respond(false)
+		}
+	}
+	return p, nil
+}
+
+func (p *planDialogCmp) handleEditingKey(msg tea.KeyPressMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keyMap.Confirm):
+		value := strings.TrimSpace(p.input.Value())
+		if value == "" {
+			p.stopEditing(true)
+			return nil
+		}
+		p.steps[p.cursor] = value
+		p.stopEditing(false)
+		return nil
+	case key.Matches(msg, p.keyMap.Cancel):
+		p.stopEditing(p.adding)
+		return nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+func (p *planDialogCmp) startEditing(value string, adding bool) tea.Cmd {
+	p.editing = true
+	p.adding = adding
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+	return p.input.Focus()
+}
+
+// stopEditing leaves edit mode, dropping the step under the cursor when
+// remove is true.
+func (p *planDialogCmp) stopEditing(remove bool) {
+	if remove {
+		p.steps = slices.Delete(p.steps, p.cursor, p.cursor+1)
+		p.cursor = max(0, min(p.cursor, len(p.steps)-1))
+	}
+	p.editing = false
+	p.adding = false
+	p.input.Blur()
+}
+
+func (p *planDialogCmp) respond(approved bool) tea.Cmd {
+	return tea.Sequence(
+		util.CmdHandler(dialogs.CloseDialogMsg{}),
+		util.CmdHandler(PlanResponseMsg{
+			Request:  p.request,
+			Steps:    slices.Clone(p.steps),
+			Approved: approved,
+		}),
+	)
+}
+
+func (p *planDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base
+
+	header := baseStyle.Padding(0, 1, 1, 1).Render(core.Title("Review Plan", p.width-4))
+
+	var rows []string
+	for i, step := range p.steps {
+		number := fmt.Sprintf("%2d.", i+1)
+		selected := i == p.cursor
+		if selected && p.editing {
+			rows = append(rows, fmt.Sprintf("%s %s", t.S().Base.Foreground(t.Primary).Render(number), p.input.View()))
+			continue
+		}
+		style := t.S().Muted
+		if selected {
+			style = t.S().Text.Bold(true)
+			number = t.S().Base.Foreground(t.Primary).Render(number)
+		} else {
+			number = t.S().Subtle.Render(number)
+		}
+		rows = append(rows, fmt.Sprintf("%s %s", number, style.Width(p.width-10).Render(step)))
+	}
+	if len(rows) == 0 {
+		rows = append(rows, t.S().Subtle.Render("No steps. Press a to add one."))
+	}
+
+	var keyMap help.KeyMap = p.keyMap
+	if p.editing {
+		keyMap = editingKeyMap{p.keyMap}
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		baseStyle.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, rows...)),
+		"",
+		baseStyle.Width(p.width-2).PaddingLeft(1).Render(p.help.View(keyMap)),
+	)
+	return p.style().Render(content)
+}
+
+func (p *planDialogCmp) Cursor() *tea.Cursor {
+	if !p.editing {
+		return nil
+	}
+	cursor := p.input.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := p.Position()
+	// Border, header and padding above the list.
+	cursor.Y += row + 3 + p.lineOffset()
+	// Border, padding and the step number.
+	cursor.X += col + 2 + 4
+	return cursor
+}
+
+// lineOffset returns the rendered line of the step under the cursor, taking
+// wrapped steps above it into account.
+func (p *planDialogCmp) lineOffset() int {
+	offset := 0
+	for _, step := range p.steps[:p.cursor] {
+		offset += lipgloss.Height(lipgloss.NewStyle().Width(p.width - 10).Render(step))
+	}
+	return offset
+}
+
+func (p *planDialogCmp) style() lipgloss.Style {
+	t := styles.CurrentTheme()
+	return t.S().Base.
+		Width(p.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus)
+}
+
+func (p *planDialogCmp) Position() (int, int) {
+	row := p.wHeight/4 - 2 // just a bit above the center
+	col := p.wWidth / 2
+	col -= p.width / 2
+	return row, col
+}
+
+func (p *planDialogCmp) ID() dialogs.DialogID {
+	return PlanDialogID
+}
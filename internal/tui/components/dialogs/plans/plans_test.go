@@ -0,0 +1,25 @@
+package plans
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmptyPlan(t *testing.T) {
+	t.Parallel()
+
+	p := NewPlanDialogCmp(plan.Request{}).(*planDialogCmp)
+	p.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	require.Zero(t, p.cursor)
+
+	p.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	require.True(t, p.editing)
+	require.Equal(t, []string{""}, p.steps)
+
+	p.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	require.False(t, p.editing)
+	require.Empty(t, p.steps, "cancelling a new step drops it")
+}
@@ -0,0 +1,245 @@
+package providers
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const ProviderHealthDialogID dialogs.DialogID = "provider_health"
+
+// ProviderHealthDialog lists the configured providers and checks that each
+// of them can be reached with its API key.
+type ProviderHealthDialog interface {
+	dialogs.DialogModel
+}
+
+// health is the outcome of checking a provider.
+type health struct {
+	checking bool
+	err      error
+	latency  time.Duration
+}
+
+type checkedMsg struct {
+	id      string
+	err     error
+	latency time.Duration
+}
+
+type providerHealthDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	health map[string]health
+
+	selected int
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewProviderHealthDialogCmp creates the provider health check dialog.
+func NewProviderHealthDialogCmp() ProviderHealthDialog {
+	return &providerHealthDialogCmp{
+		health: map[string]health{},
+		keyMap: DefaultKeyMap(),
+		help:   help.New(),
+	}
+}
+
+func (m *providerHealthDialogCmp) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, provider := range sortedProviders() {
+		cmds = append(cmds, m.check(provider))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *providerHealthDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(90, m.wWidth-4)
+	case checkedMsg:
+		m.health[msg.id] = health{err: msg.err, latency: msg.latency}
+	case tea.KeyPressMsg:
+		providers := sortedProviders()
+		switch {
+		case key.Matches(msg, m.keyMap.Next):
+			m.selected = min(m.selected+1, len(providers)-1)
+		case key.Matches(msg, m.keyMap.Previous):
+			m.selected = max(m.selected-1, 0)
+		case key.Matches(msg, m.keyMap.Check):
+			if len(providers) == 0 {
+				return m, nil
+			}
+			return m, m.check(providers[m.selected])
+		case key.Matches(msg, m.keyMap.Close):
+			return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return m, nil
+}
+
+// sortedProviders returns the configured providers, by ID.
+func sortedProviders() []config.ProviderConfig {
+	cfg := config.Get()
+	if cfg == nil || cfg.Providers == nil {
+		return nil
+	}
+	providers := slices.Collect(cfg.Providers.Seq())
+	slices.SortFunc(providers, func(a, b config.ProviderConfig) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return providers
+}
+
+// checkable reports whether the connection to provider can be tested.
+func checkable(provider config.ProviderConfig) bool {
+	switch provider.Type {
+	case catwalk.TypeOpenAI, catwalk.TypeOpenAICompat, catwalk.TypeOpenRouter, catwalk.TypeAnthropic, catwalk.TypeGoogle:
+		return !provider.Disable
+	default:
+		return false
+	}
+}
+
+// check tests the connection to provider in the background.
+func (m *providerHealthDialogCmp) check(provider config.ProviderConfig) tea.Cmd {
+	if !checkable(provider) {
+		return nil
+	}
+	m.health[provider.ID] = health{checking: true}
+	resolver := config.Get().Resolver()
+	return func() tea.Msg {
+		start := time.Now()
+		err := provider.TestConnection(resolver)
+		return checkedMsg{id: provider.ID, err: err, latency: time.Since(start)}
+	}
+}
+
+func (m *providerHealthDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Providers", m.width-4))
+	providers := sortedProviders()
+	m.selected = max(0, min(m.selected, len(providers)-1))
+
+	var body []string
+	if len(providers) == 0 {
+		body = append(body, t.S().Subtle.Render("No providers configured."))
+	}
+	for i, provider := range providers {
+		icon, description := m.healthLabel(provider)
+		title := cmp.Or(provider.Name, provider.ID)
+		if i == m.selected {
+			title = t.S().Base.Foreground(t.Primary).Bold(true).Render(title)
+		}
+		body = append(body, core.Status(core.StatusOpts{
+			Icon:        icon,
+			Title:       title,
+			Description: description,
+		}, m.width-4))
+	}
+	if len(providers) > 0 {
+		body = append(body, "", m.detailsView(providers[m.selected]))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (m *providerHealthDialogCmp) healthLabel(provider config.ProviderConfig) (string, string) {
+	t := styles.CurrentTheme()
+	if provider.Disable {
+		return t.ItemOfflineIcon.String(), t.S().Subtle.Render("disabled")
+	}
+	if !checkable(provider) {
+		return t.ItemOfflineIcon.String(), t.S().Subtle.Render("not checked")
+	}
+	h, ok := m.health[provider.ID]
+	switch {
+	case !ok || h.checking:
+		return t.ItemBusyIcon.String(), t.S().Subtle.Render("checking...")
+	case h.err != nil:
+		return t.ItemErrorIcon.String(), t.S().Subtle.Render("unreachable")
+	default:
+		return t.ItemOnlineIcon.String(), t.S().Subtle.Render(fmt.Sprintf("ok in %s", h.latency.Round(time.Millisecond)))
+	}
+}
+
+// detailsView renders how the selected provider is configured, without its
+// secrets, and why it couldn't be reached.
+func (m *providerHealthDialogCmp) detailsView(provider config.ProviderConfig) string {
+	t := styles.CurrentTheme()
+	width := m.width - 4
+	label := func(s string) string {
+		return t.S().Subtle.Render(s + ": ")
+	}
+	line := func(name, value string) string {
+		return ansi.Truncate(label(name)+t.S().Text.Render(value), width, "…")
+	}
+
+	lines := []string{
+		core.Section(provider.ID, width),
+		line("Type", string(provider.Type)),
+	}
+	if provider.BaseURL != "" {
+		lines = append(lines, line("Base URL", provider.BaseURL))
+	}
+	if len(provider.ExtraHeaders) > 0 {
+		names := slices.Sorted(maps.Keys(provider.ExtraHeaders))
+		lines = append(lines, line("Headers", strings.Join(names, ", ")))
+	}
+	var transforms []string
+	if provider.RequestTransform != nil {
+		transforms = append(transforms, "request")
+	}
+	if provider.ResponseTransform != nil {
+		transforms = append(transforms, "response")
+	}
+	if len(transforms) > 0 {
+		lines = append(lines, line("Transforms", strings.Join(transforms, ", ")))
+	}
+	lines = append(lines, line("Models", fmt.Sprintf("%d", len(provider.Models))))
+	if h := m.health[provider.ID]; h.err != nil {
+		lines = append(lines, ansi.Truncate(label("Error")+t.S().Error.Render(h.err.Error()), width, "…"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m *providerHealthDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *providerHealthDialogCmp) ID() dialogs.DialogID {
+	return ProviderHealthDialogID
+}
@@ -0,0 +1,102 @@
+package pullrequest
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the pull request dialog.
+type KeyMap struct {
+	NextField,
+	Create,
+	ToggleDraft,
+	Regenerate,
+	OpenEditor,
+	OpenBrowser,
+	OpenDash,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		NextField: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next field"),
+		),
+		Create: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "create"),
+		),
+		ToggleDraft: key.NewBinding(
+			key.WithKeys("alt+d"),
+			key.WithHelp("alt+d", "draft"),
+		),
+		Regenerate: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "redraft"),
+		),
+		OpenEditor: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "open editor"),
+		),
+		OpenBrowser: key.NewBinding(
+			key.WithKeys("o", "enter"),
+			key.WithHelp("o", "open in browser"),
+		),
+		OpenDash: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "watch checks in gh-dash"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "close"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.NextField,
+		k.Create,
+		k.ToggleDraft,
+		k.Regenerate,
+		k.OpenEditor,
+		k.OpenBrowser,
+		k.OpenDash,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.NextField,
+		k.Create,
+		k.ToggleDraft,
+		k.Regenerate,
+		k.OpenEditor,
+		k.Close,
+	}
+}
+
+// createdHelp lists the bindings once the pull request is open.
+type createdHelp KeyMap
+
+// FullHelp implements help.KeyMap.
+func (k createdHelp) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k createdHelp) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.OpenBrowser,
+		k.OpenDash,
+		k.Close,
+	}
+}
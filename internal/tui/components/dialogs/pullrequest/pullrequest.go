@@ -0,0 +1,374 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textarea"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/gh"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/execfailed"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/pkg/browser"
+)
+
+const (
+	PullRequestDialogID dialogs.DialogID = "pull_request"
+
+	// bodyHeight is the number of lines of the body editor.
+	bodyHeight = 12
+	// labelWidth is the width of the labels in front of the fields.
+	labelWidth = 7
+)
+
+// Fields, in tab order.
+const (
+	focusTitle = iota
+	focusBody
+)
+
+// PullRequestDialog shows the pull request drafted for the current branch,
+// lets the user edit it and opens it with gh.
+type PullRequestDialog interface {
+	dialogs.DialogModel
+}
+
+type (
+	draftedMsg struct {
+		pr  gh.PullRequest
+		err error
+	}
+	editedMsg struct {
+		text string
+	}
+	createdMsg struct {
+		url string
+		err error
+	}
+)
+
+type pullRequestDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	workingDir string
+	generate   func(context.Context) (gh.PullRequest, error)
+	cancel     context.CancelFunc
+
+	title    textinput.Model
+	body     textarea.Model
+	focus    int
+	draft    bool
+	drafting bool
+	creating bool
+	url      string
+	err      error
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewPullRequestDialogCmp creates the pull request dialog for the
+// repository at workingDir, drafting the pull request with generate.
+func NewPullRequestDialogCmp(workingDir string, generate func(context.Context) (gh.PullRequest, error)) PullRequestDialog {
+	t := styles.CurrentTheme()
+	title := textinput.New()
+	title.SetVirtualCursor(false)
+	title.Placeholder = "Title"
+	title.SetStyles(t.S().TextInput)
+	title.Focus()
+
+	body := textarea.New()
+	body.SetStyles(t.S().TextArea)
+	body.ShowLineNumbers = false
+	body.CharLimit = -1
+	body.SetVirtualCursor(false)
+	body.SetHeight(bodyHeight)
+	body.Placeholder = "Description"
+
+	return &pullRequestDialogCmp{
+		workingDir: workingDir,
+		generate:   generate,
+		title:      title,
+		body:       body,
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (p *pullRequestDialogCmp) Init() tea.Cmd {
+	return p.redraft()
+}
+
+// redraft asks for a new draft, dropping any draft still in progress.
+func (p *pullRequestDialogCmp) redraft() tea.Cmd {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.drafting = true
+	p.err = nil
+	return func() tea.Msg {
+		pr, err := p.generate(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return draftedMsg{pr: pr, err: err}
+	}
+}
+
+func (p *pullRequestDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.wWidth = msg.Width
+		p.wHeight = msg.Height
+		p.width = min(100, p.wWidth-4)
+		p.title.SetWidth(p.width - 6 - labelWidth)
+		p.body.SetWidth(p.width - 4)
+	case draftedMsg:
+		p.drafting = false
+		p.err = msg.err
+		if msg.err == nil {
+			p.setPullRequest(msg.pr.Title, msg.pr.Body)
+		}
+	case editedMsg:
+		p.setPullRequest(gh.SplitPullRequest(msg.text))
+	case createdMsg:
+		p.creating = false
+		p.url, p.err = msg.url, msg.err
+	case tea.KeyPressMsg:
+		if key.Matches(msg, p.keyMap.Close) {
+			if p.cancel != nil {
+				p.cancel()
+			}
+			return p, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		if p.url != "" {
+			return p, p.handleCreatedKey(msg)
+		}
+		if p.creating {
+			return p, nil
+		}
+		switch {
+		case key.Matches(msg, p.keyMap.Regenerate):
+			return p, p.redraft()
+		case p.drafting:
+			return p, nil
+		case key.Matches(msg, p.keyMap.NextField):
+			p.setFocus((p.focus + 1) % 2)
+			return p, nil
+		case key.Matches(msg, p.keyMap.ToggleDraft):
+			p.draft = !p.draft
+			return p, nil
+		case key.Matches(msg, p.keyMap.Create):
+			return p, p.create()
+		case key.Matches(msg, p.keyMap.OpenEditor):
+			return p, openEditor(p.title.Value() + "\n\n" + p.body.Value())
+		}
+		return p, p.updateFocused(msg)
+	case tea.PasteMsg:
+		return p, p.updateFocused(msg)
+	}
+	return p, nil
+}
+
+func (p *pullRequestDialogCmp) handleCreatedKey(msg tea.KeyPressMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keyMap.OpenBrowser):
+		url := p.url
+		return func() tea.Msg {
+			if err := browser.OpenURL(url); err != nil {
+				return util.ReportError(fmt.Errorf("failed to open browser: %w", err))()
+			}
+			return nil
+		}
+	case key.Matches(msg, p.keyMap.OpenDash):
+		return tea.Sequence(
+			util.CmdHandler(dialogs.CloseDialogMsg{}),
+			execfailed.Run(config.ExternalToolGHDash, gh.ShellCommand("gh dash"), "Install gh-dash with gh extension install dlvhdr/gh-dash."),
+		)
+	}
+	return nil
+}
+
+func (p *pullRequestDialogCmp) updateFocused(msg tea.Msg) tea.Cmd {
+	if p.drafting || p.creating || p.url != "" {
+		return nil
+	}
+	var cmd tea.Cmd
+	if p.focus == focusTitle {
+		p.title, cmd = p.title.Update(msg)
+	} else {
+		p.body, cmd = p.body.Update(msg)
+	}
+	return cmd
+}
+
+func (p *pullRequestDialogCmp) setPullRequest(title, body string) {
+	p.title.SetValue(title)
+	p.title.CursorEnd()
+	p.body.SetValue(body)
+	p.body.MoveToBegin()
+}
+
+func (p *pullRequestDialogCmp) setFocus(focus int) {
+	p.focus = focus
+	if focus == focusTitle {
+		p.title.Focus()
+		p.body.Blur()
+	} else {
+		p.title.Blur()
+		p.body.Focus()
+	}
+}
+
+func (p *pullRequestDialogCmp) create() tea.Cmd {
+	pr := gh.PullRequest{
+		Title: strings.TrimSpace(p.title.Value()),
+		Body:  strings.TrimSpace(p.body.Value()),
+		Draft: p.draft,
+	}
+	if pr.Title == "" {
+		return util.ReportWarn("The pull request needs a title")
+	}
+	p.creating = true
+	p.err = nil
+	workingDir := p.workingDir
+	return func() tea.Msg {
+		url, err := gh.CreatePullRequest(context.Background(), workingDir, pr)
+		return createdMsg{url: url, err: err}
+	}
+}
+
+// openEditor lets the user edit the pull request in the editor, with the title
+// on the first line.
+func openEditor(value string) tea.Cmd {
+	editor := util.Editor()
+
+	tmpfile, err := os.CreateTemp("", "PULL_REQUEST_*.md")
+	if err != nil {
+		return util.ReportError(err)
+	}
+	defer tmpfile.Close() //nolint:errcheck
+	if _, err := tmpfile.WriteString(value); err != nil {
+		return util.ReportError(err)
+	}
+	return util.ExecShell(context.TODO(), config.ExternalToolEditor, editor+" "+tmpfile.Name(), func(err error) tea.Msg {
+		defer os.Remove(tmpfile.Name())
+		if err != nil {
+			return util.ReportError(err)
+		}
+		content, err := os.ReadFile(tmpfile.Name())
+		if err != nil {
+			return util.ReportError(err)
+		}
+		return editedMsg{text: string(content)}
+	})
+}
+
+func (p *pullRequestDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := p.width - 4
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Create Pull Request", width))
+
+	if p.url != "" {
+		body := lipgloss.JoinVertical(
+			lipgloss.Left,
+			t.S().Text.Render("Pull request created:"),
+			t.S().Base.Foreground(t.Primary).Render(p.url),
+		)
+		return p.frame(header, t.S().Base.Padding(0, 1).Render(body), createdHelp(p.keyMap))
+	}
+
+	label := func(s string, focused bool) string {
+		style := t.S().Subtle
+		if focused {
+			style = t.S().Text
+		}
+		return style.Width(labelWidth).Render(s)
+	}
+	draft := t.S().Subtle.Render("[ ] draft")
+	if p.draft {
+		draft = t.S().Text.Render("[x] draft")
+	}
+
+	parts := []string{
+		label("Title", p.focus == focusTitle) + p.title.View(),
+		"",
+		p.body.View(),
+		"",
+		draft,
+	}
+	switch {
+	case p.drafting:
+		parts = append(parts, "", t.S().Subtle.Render("Drafting the pull request from the branch changes…"))
+	case p.creating:
+		parts = append(parts, "", t.S().Subtle.Render("Pushing the branch and creating the pull request…"))
+	case p.err != nil:
+		parts = append(parts, "", t.S().Base.Foreground(t.Error).Width(width).Render(p.err.Error()))
+	}
+
+	return p.frame(header, t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, parts...)), p.keyMap)
+}
+
+func (p *pullRequestDialogCmp) frame(header, body string, keyMap help.KeyMap) string {
+	t := styles.CurrentTheme()
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		"",
+		t.S().Base.Width(p.width-2).PaddingLeft(1).Render(p.help.View(keyMap)),
+	)
+	return t.S().Base.
+		Width(p.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (p *pullRequestDialogCmp) Cursor() *tea.Cursor {
+	if p.drafting || p.creating || p.url != "" {
+		return nil
+	}
+	row, col := p.Position()
+	if p.focus == focusTitle {
+		cursor := p.title.Cursor()
+		if cursor == nil {
+			return nil
+		}
+		cursor.Y += row + 3              // border, title and padding
+		cursor.X += col + 2 + labelWidth // border, padding and label
+		return cursor
+	}
+	cursor := p.body.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	cursor.Y += row + 5 // border, title, padding and the title field
+	cursor.X += col + 2 // border and padding
+	return cursor
+}
+
+func (p *pullRequestDialogCmp) Position() (int, int) {
+	row := p.wHeight/4 - 2 // just a bit above the center
+	col := p.wWidth / 2
+	col -= p.width / 2
+	return max(0, row), col
+}
+
+func (p *pullRequestDialogCmp) ID() dialogs.DialogID {
+	return PullRequestDialogID
+}
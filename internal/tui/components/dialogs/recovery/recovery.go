@@ -0,0 +1,127 @@
+package recovery
+
+import (
+	"cmp"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/tui/components/chat"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const RecoveryDialogID dialogs.DialogID = "recovery"
+
+// continuePrompt is sent to pick up where an interrupted session stopped.
+const continuePrompt = "Crush exited while you were working on this. Continue where you left off."
+
+// RecoveryDialog offers to restore the sessions Crush was responding in when
+// it last exited without finishing.
+type RecoveryDialog interface {
+	dialogs.DialogModel
+}
+
+type recoveryDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	sessions []session.Session
+
+	selected int
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewRecoveryDialogCmp creates the dialog offering to restore sessions.
+func NewRecoveryDialogCmp(sessions []session.Session) RecoveryDialog {
+	return &recoveryDialogCmp{
+		sessions: sessions,
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+}
+
+func (m *recoveryDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (m *recoveryDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.wWidth = msg.Width
+		m.wHeight = msg.Height
+		m.width = min(80, m.wWidth-4)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, m.keyMap.Next):
+			m.selected = min(m.selected+1, len(m.sessions)-1)
+		case key.Matches(msg, m.keyMap.Previous):
+			m.selected = max(m.selected-1, 0)
+		case key.Matches(msg, m.keyMap.Restore, m.keyMap.Continue):
+			if len(m.sessions) == 0 {
+				return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+			}
+			cmds := []tea.Cmd{
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.CmdHandler(chat.SessionSelectedMsg(m.sessions[m.selected])),
+			}
+			if key.Matches(msg, m.keyMap.Continue) {
+				cmds = append(cmds, util.CmdHandler(chat.SendMsg{Text: continuePrompt}))
+			}
+			return m, tea.Sequence(cmds...)
+		case key.Matches(msg, m.keyMap.Close):
+			return m, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return m, nil
+}
+
+func (m *recoveryDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Restore Interrupted Session", m.width-4))
+	note := t.S().Subtle.Width(m.width - 4).Render("Crush exited while responding. What it said and did until then is kept; the tools it was running were stopped.")
+
+	body := []string{note, ""}
+	for i, sess := range m.sessions {
+		title := ansi.Truncate(cmp.Or(sess.Title, "Untitled"), m.width-30, "…")
+		if i == m.selected {
+			title = t.S().Base.Foreground(t.Primary).Bold(true).Render(title)
+		}
+		body = append(body, core.Status(core.StatusOpts{
+			Icon:        t.ItemErrorIcon.String(),
+			Title:       title,
+			Description: t.S().Subtle.Render(time.Unix(sess.UpdatedAt, 0).Format("Jan 2 15:04")),
+		}, m.width-4))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(m.width-2).PaddingLeft(1).Render(m.help.View(m.keyMap)),
+	)
+	return t.S().Base.
+		Width(m.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (m *recoveryDialogCmp) Position() (int, int) {
+	row := m.wHeight/4 - 2 // just a bit above the center
+	col := m.wWidth / 2
+	col -= m.width / 2
+	return max(0, row), col
+}
+
+func (m *recoveryDialogCmp) ID() dialogs.DialogID {
+	return RecoveryDialogID
+}
@@ -0,0 +1,140 @@
+package repos
+
+import (
+	"slices"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/remote"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const ReposDialogID dialogs.DialogID = "repos"
+
+// searchDepth is how many levels beneath the active root repositories are
+// looked for.
+const searchDepth = 3
+
+// ReposDialog asks which of the git repositories of a monorepo a git
+// dialog is for.
+type ReposDialog interface {
+	dialogs.DialogModel
+}
+
+type reposDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	roots []string
+	open  func(dir string) tea.Cmd
+
+	selected int
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewReposDialogCmp creates the picker of roots, opening the dialog with
+// open for the one selected.
+func NewReposDialogCmp(roots []string, selected string, open func(dir string) tea.Cmd) ReposDialog {
+	return &reposDialogCmp{
+		roots:    roots,
+		open:     open,
+		selected: max(0, slices.Index(roots, selected)),
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+}
+
+// Pick opens the dialog with open for the repository of dir, asking which
+// one first when there are several repositories beneath it.
+func Pick(dir string, open func(dir string) tea.Cmd) tea.Cmd {
+	if remote.Current() != nil {
+		// The files are on the host, there's nothing to look for here.
+		return open(dir)
+	}
+	return func() tea.Msg {
+		roots := fsext.GitRoots(dir, searchDepth)
+		if len(roots) < 2 {
+			return open(dir)()
+		}
+		return dialogs.OpenDialogMsg{Model: NewReposDialogCmp(roots, dir, open)}
+	}
+}
+
+func (d *reposDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *reposDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(80, d.wWidth-4)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Next):
+			d.selected = min(d.selected+1, len(d.roots)-1)
+		case key.Matches(msg, d.keyMap.Previous):
+			d.selected = max(d.selected-1, 0)
+		case key.Matches(msg, d.keyMap.Select):
+			return d, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				d.open(d.roots[d.selected]),
+			)
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return d, nil
+}
+
+func (d *reposDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Choose Repository", d.width-4))
+
+	var body []string
+	for i, root := range d.roots {
+		path := fsext.PrettyPath(root)
+		if over := ansi.StringWidth(path) - (d.width - 4); over > 0 {
+			path = ansi.TruncateLeft(path, over+1, "…")
+		}
+		if i == d.selected {
+			path = t.S().Base.Foreground(t.Primary).Bold(true).Render(path)
+		} else {
+			path = t.S().Text.Render(path)
+		}
+		body = append(body, path)
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *reposDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *reposDialogCmp) ID() dialogs.DialogID {
+	return ReposDialogID
+}
@@ -0,0 +1,241 @@
+package requests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/requestlog"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const RequestsDialogID dialogs.DialogID = "requests"
+
+// RequestsDialog browses the requests sent to providers in a session, with
+// their raw payloads, timings, token counts and stop reasons.
+type RequestsDialog interface {
+	dialogs.DialogModel
+}
+
+type loadedMsg struct {
+	entries []requestlog.Entry
+	err     error
+}
+
+type requestsDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	log       *requestlog.Log
+	sessionID string
+	enabled   bool
+	loading   bool
+	entries   []requestlog.Entry
+	selected  int
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewRequestsDialogCmp creates the inspector of the requests of sessionID,
+// recorded in log. enabled is whether requests are being recorded.
+func NewRequestsDialogCmp(log *requestlog.Log, sessionID string, enabled bool) RequestsDialog {
+	return &requestsDialogCmp{
+		log:       log,
+		sessionID: sessionID,
+		enabled:   enabled,
+		loading:   true,
+		viewport:  viewport.New(),
+		keyMap:    DefaultKeyMap(),
+		help:      help.New(),
+	}
+}
+
+func (d *requestsDialogCmp) Init() tea.Cmd {
+	return func() tea.Msg {
+		entries, err := d.log.Entries(d.sessionID)
+		return loadedMsg{entries: entries, err: err}
+	}
+}
+
+func (d *requestsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(120, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.viewport.SetContent(d.content())
+	case loadedMsg:
+		d.loading = false
+		if msg.err != nil {
+			return d, util.ReportError(msg.err)
+		}
+		d.entries = msg.entries
+		// The latest request is the one most likely looked for.
+		d.selected = max(0, len(d.entries)-1)
+		d.viewport.SetContent(d.content())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Previous):
+			d.show(d.selected - 1)
+			return d, nil
+		case key.Matches(msg, d.keyMap.Next):
+			d.show(d.selected + 1)
+			return d, nil
+		case key.Matches(msg, d.keyMap.Copy):
+			if len(d.entries) == 0 {
+				return d, nil
+			}
+			return d, util.CopyToClipboard(prettyJSON(d.entries[d.selected].Request), "Request payload")
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// show selects the request at i, when there's one.
+func (d *requestsDialogCmp) show(i int) {
+	if i < 0 || i >= len(d.entries) || i == d.selected {
+		return
+	}
+	d.selected = i
+	d.viewport.SetContent(d.content())
+	d.viewport.GotoTop()
+}
+
+// content renders the selected request in the viewport.
+func (d *requestsDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	switch {
+	case d.loading:
+		return t.S().Subtle.Render("Loading requests...")
+	case len(d.entries) == 0 && !d.enabled:
+		return t.S().Subtle.Width(width).Render("Requests aren't recorded. Set options.log_requests to true to record the next ones.")
+	case len(d.entries) == 0:
+		return t.S().Subtle.Render("No requests recorded in this session yet.")
+	}
+
+	e := d.entries[d.selected]
+	section := func(title, body string) string {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			t.S().Base.Foreground(t.Primary).Bold(true).Render(title),
+			"",
+			t.S().Text.Width(width).Render(strings.ReplaceAll(body, "\t", "    ")),
+		)
+	}
+	field := func(name, value string) string {
+		return t.S().Subtle.Render(name+": ") + t.S().Text.Render(value)
+	}
+
+	summary := []string{
+		field("Sent", e.Time.Local().Format(time.DateTime)),
+		field("Request", e.Method+" "+e.URL),
+	}
+	if e.Model != "" {
+		summary = append(summary, field("Model", e.Model))
+	}
+	if e.Status != 0 {
+		summary = append(summary, field("Status", fmt.Sprintf("%d", e.Status)))
+	}
+	summary = append(summary, field("Timing", fmt.Sprintf("first byte in %s, done in %s",
+		e.FirstByte.Round(time.Millisecond), e.Duration.Round(time.Millisecond))))
+	if e.InputTokens > 0 || e.OutputTokens > 0 {
+		summary = append(summary, field("Tokens", fmt.Sprintf("%d in, %d out", e.InputTokens, e.OutputTokens)))
+	}
+	if e.StopReason != "" {
+		summary = append(summary, field("Stop reason", e.StopReason))
+	}
+	if e.Error != "" {
+		summary = append(summary, t.S().Subtle.Render("Error: ")+t.S().Error.Render(e.Error))
+	}
+
+	var headers []string
+	for _, name := range slices.Sorted(maps.Keys(e.Headers)) {
+		headers = append(headers, name+": "+e.Headers[name])
+	}
+	response := e.Response
+	if e.Truncated {
+		response += "\n\n[truncated]"
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		t.S().Text.Width(width).Render(strings.Join(summary, "\n")),
+		"",
+		section("Headers", strings.Join(headers, "\n")),
+		"",
+		section("Payload", prettyJSON(e.Request)),
+		"",
+		section("Response", prettyJSON(response)),
+	)
+}
+
+func prettyJSON(input string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(input), "", "  "); err != nil {
+		return input
+	}
+	return buf.String()
+}
+
+func (d *requestsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Requests"
+	if len(d.entries) > 0 {
+		title = fmt.Sprintf("Request %d/%d", d.selected+1, len(d.entries))
+	}
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *requestsDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *requestsDialogCmp) ID() dialogs.DialogID {
+	return RequestsDialogID
+}
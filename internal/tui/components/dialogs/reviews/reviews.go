@@ -0,0 +1,246 @@
+package reviews
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/gh"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	ReviewsDialogID dialogs.DialogID = "reviews"
+
+	// listHeight is the number of threads listed at once.
+	listHeight = 8
+	// previewLines is the number of lines of the selected thread shown.
+	previewLines = 8
+)
+
+// ReviewPrompt builds the message asking the agent to address the review
+// threads.
+func ReviewPrompt(url string, threads []gh.ReviewThread) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Address these review comments from %s. Track each one as a task with the todos tool, make the change it asks for, or tell me why not if you disagree.\n", url)
+	for i, thread := range threads {
+		fmt.Fprintf(&sb, "\n## %d. %s\n\n", i+1, location(thread))
+		if thread.Outdated {
+			sb.WriteString("The code has changed since this comment was made, check whether it still applies.\n\n")
+		}
+		for _, c := range thread.Comments {
+			fmt.Fprintf(&sb, "@%s:\n%s\n\n", c.Author, c.Body)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func location(thread gh.ReviewThread) string {
+	if thread.Line == 0 {
+		return thread.Path
+	}
+	return fmt.Sprintf("%s:%d", thread.Path, thread.Line)
+}
+
+// ReviewsDialog lists the unresolved review comments on the current
+// branch's pull request and sends the selected ones to the agent.
+type ReviewsDialog interface {
+	dialogs.DialogModel
+}
+
+type loadedMsg struct {
+	url     string
+	threads []gh.ReviewThread
+	err     error
+}
+
+type reviewsDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	workingDir string
+
+	loading  bool
+	err      error
+	url      string
+	threads  []gh.ReviewThread
+	picked   map[int]bool
+	selected int
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewReviewsDialogCmp creates the dialog for the pull request of the branch
+// checked out in workingDir.
+func NewReviewsDialogCmp(workingDir string) ReviewsDialog {
+	return &reviewsDialogCmp{
+		workingDir: workingDir,
+		loading:    true,
+		picked:     map[int]bool{},
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (r *reviewsDialogCmp) Init() tea.Cmd {
+	workingDir := r.workingDir
+	return func() tea.Msg {
+		url, threads, err := gh.UnresolvedReviewThreads(context.Background(), workingDir)
+		return loadedMsg{url: url, threads: threads, err: err}
+	}
+}
+
+func (r *reviewsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		r.wWidth = msg.Width
+		r.wHeight = msg.Height
+		r.width = min(100, r.wWidth-4)
+	case loadedMsg:
+		r.loading = false
+		r.url, r.threads, r.err = msg.url, msg.threads, msg.err
+		for i := range r.threads {
+			r.picked[i] = true
+		}
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, r.keyMap.Close):
+			return r, util.CmdHandler(dialogs.CloseDialogMsg{})
+		case len(r.threads) == 0:
+			return r, nil
+		case key.Matches(msg, r.keyMap.Next):
+			r.selected = (r.selected + 1) % len(r.threads)
+		case key.Matches(msg, r.keyMap.Previous):
+			r.selected = (r.selected - 1 + len(r.threads)) % len(r.threads)
+		case key.Matches(msg, r.keyMap.Toggle):
+			r.picked[r.selected] = !r.picked[r.selected]
+		case key.Matches(msg, r.keyMap.ToggleAll):
+			all := r.pickedThreads()
+			for i := range r.threads {
+				r.picked[i] = len(all) != len(r.threads)
+			}
+		case key.Matches(msg, r.keyMap.Import):
+			threads := r.pickedThreads()
+			if len(threads) == 0 {
+				return r, util.ReportWarn("Select the comments to send first")
+			}
+			return r, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.CmdHandler(commands.CommandRunCustomMsg{Content: ReviewPrompt(r.url, threads)}),
+			)
+		}
+	}
+	return r, nil
+}
+
+func (r *reviewsDialogCmp) pickedThreads() []gh.ReviewThread {
+	var threads []gh.ReviewThread
+	for i, thread := range r.threads {
+		if r.picked[i] {
+			threads = append(threads, thread)
+		}
+	}
+	return threads
+}
+
+func (r *reviewsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := r.width - 4
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Review Comments", width))
+
+	var body []string
+	switch {
+	case r.loading:
+		body = append(body, t.S().Subtle.Render("Fetching the review comments…"))
+	case r.err != nil:
+		body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render(r.err.Error()))
+	case len(r.threads) == 0:
+		body = append(body, t.S().Subtle.Render("No unresolved review comments."))
+	default:
+		body = append(body, r.listView(width)...)
+		body = append(body, "", r.threadView(r.threads[r.selected], width))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(r.width-2).PaddingLeft(1).Render(r.help.View(r.keyMap)),
+	)
+	return t.S().Base.
+		Width(r.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+// listView renders the threads, scrolled to keep the selected one visible.
+func (r *reviewsDialogCmp) listView(width int) []string {
+	t := styles.CurrentTheme()
+	lines := []string{t.S().Subtle.Render(fmt.Sprintf("%d of %d unresolved thread(s) selected", len(r.pickedThreads()), len(r.threads)))}
+
+	start := max(0, min(r.selected-listHeight/2, len(r.threads)-listHeight))
+	for i := start; i < min(start+listHeight, len(r.threads)); i++ {
+		thread := r.threads[i]
+		check := t.S().Base.Foreground(t.Success).Render("[x]")
+		if !r.picked[i] {
+			check = t.S().Subtle.Render("[ ]")
+		}
+		name := location(thread)
+		switch {
+		case i == r.selected:
+			name = t.S().Base.Foreground(t.Primary).Bold(true).Render(name)
+		case !r.picked[i]:
+			name = t.S().Subtle.Render(name)
+		default:
+			name = t.S().Text.Render(name)
+		}
+		first := strings.ReplaceAll(thread.Comments[0].Body, "\n", " ")
+		line := check + " " + name + " " + t.S().Muted.Render(first)
+		if thread.Outdated {
+			line += " " + t.S().Base.Foreground(t.Warning).Render("outdated")
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lines
+}
+
+// threadView renders the comments of a thread.
+func (r *reviewsDialogCmp) threadView(thread gh.ReviewThread, width int) string {
+	t := styles.CurrentTheme()
+	var lines []string
+	for _, c := range thread.Comments {
+		lines = append(lines, t.S().Base.Foreground(t.Secondary).Render("@"+c.Author))
+		for line := range strings.SplitSeq(ansi.Wrap(c.Body, width, ""), "\n") {
+			lines = append(lines, t.S().Text.Render(line))
+		}
+	}
+	if len(lines) > previewLines {
+		rest := len(lines) - previewLines
+		lines = append(lines[:previewLines], t.S().Subtle.Render(fmt.Sprintf("… %d more line(s)", rest)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (r *reviewsDialogCmp) Position() (int, int) {
+	row := r.wHeight/4 - 2 // just a bit above the center
+	col := r.wWidth / 2
+	col -= r.width / 2
+	return max(0, row), col
+}
+
+func (r *reviewsDialogCmp) ID() dialogs.DialogID {
+	return ReviewsDialogID
+}
@@ -0,0 +1,193 @@
+package schedules
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/schedule"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/tui/components/chat"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const SchedulesDialogID dialogs.DialogID = "schedules"
+
+const (
+	// maxRows is how many runs are listed at once.
+	maxRows = 10
+	// responseLines is how much of the response of the selected run is
+	// shown.
+	responseLines = 6
+)
+
+// SchedulesDialog shows the past runs of the schedules of the project.
+type SchedulesDialog interface {
+	dialogs.DialogModel
+}
+
+type loadedMsg struct {
+	list *schedule.List
+	err  error
+}
+
+type schedulesDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	dataDir   string
+	sessions  session.Service
+	schedules int
+	// runs are the runs, most recent first.
+	runs   []schedule.Run
+	err    error
+	cursor int
+	offset int
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewSchedulesDialogCmp creates the dialog for the schedules kept in
+// dataDir, opening the sessions of their runs from sessions.
+func NewSchedulesDialogCmp(dataDir string, sessions session.Service) SchedulesDialog {
+	return &schedulesDialogCmp{
+		dataDir:  dataDir,
+		sessions: sessions,
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+}
+
+func (d *schedulesDialogCmp) Init() tea.Cmd {
+	dataDir := d.dataDir
+	return func() tea.Msg {
+		list, err := schedule.Load(dataDir)
+		return loadedMsg{list: list, err: err}
+	}
+}
+
+func (d *schedulesDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+	case loadedMsg:
+		d.err = msg.err
+		if msg.list != nil {
+			d.schedules = len(msg.list.Schedules)
+			d.runs = slices.Clone(msg.list.Runs)
+			slices.Reverse(d.runs)
+		}
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Up):
+			d.move(-1)
+		case key.Matches(msg, d.keyMap.Down):
+			d.move(1)
+		case key.Matches(msg, d.keyMap.Open):
+			if len(d.runs) == 0 || d.runs[d.cursor].SessionID == "" {
+				return d, nil
+			}
+			sess, err := d.sessions.Get(context.Background(), d.runs[d.cursor].SessionID)
+			if err != nil {
+				return d, util.ReportWarn("The session of this run was deleted")
+			}
+			return d, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.CmdHandler(chat.SessionSelectedMsg(sess)),
+			)
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return d, nil
+}
+
+// move moves the cursor by delta, scrolling the list to keep it in view.
+func (d *schedulesDialogCmp) move(delta int) {
+	d.cursor = max(0, min(len(d.runs)-1, d.cursor+delta))
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+maxRows {
+		d.offset = d.cursor - maxRows + 1
+	}
+}
+
+func (d *schedulesDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base
+	width := d.width - 4
+
+	info := t.S().Subtle.Render(fmt.Sprintf("%d schedules", d.schedules))
+	header := baseStyle.Padding(0, 1, 1, 1).Render(core.SectionWithInfo("Scheduled Runs", width, info))
+
+	var rows []string
+	switch {
+	case d.err != nil:
+		rows = append(rows, t.S().Error.Width(width).Render(d.err.Error()))
+	case len(d.runs) == 0:
+		rows = append(rows, t.S().Subtle.Width(width).Render(`No runs yet. Add schedules with crush schedule add, and run them with crush schedule run.`))
+	}
+	for i := d.offset; i < min(len(d.runs), d.offset+maxRows); i++ {
+		run := d.runs[i]
+		icon := t.ItemOnlineIcon.String()
+		if !run.Succeeded() {
+			icon = t.ItemErrorIcon.String()
+		}
+		started := t.S().Subtle.Render(run.StartedAt.Local().Format("Jan 02 15:04"))
+		style := t.S().Muted
+		if i == d.cursor {
+			style = t.S().Text.Bold(true)
+		}
+		prompt := ansi.Truncate(strings.ReplaceAll(run.Prompt, "\n", " "), width-16, "…")
+		rows = append(rows, fmt.Sprintf("%s %s %s", icon, started, style.Render(prompt)))
+	}
+	if len(d.runs) > 0 {
+		run := d.runs[d.cursor]
+		detail := run.Response
+		if run.Error != "" {
+			detail = run.Error
+		}
+		if detail == "" {
+			detail = run.Status
+		}
+		lines := strings.Split(t.S().Base.Width(width).Render(detail), "\n")
+		rows = append(rows, "", t.S().Muted.Render(strings.Join(lines[:min(len(lines), responseLines)], "\n")))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		baseStyle.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, rows...)),
+		"",
+		baseStyle.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return baseStyle.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *schedulesDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *schedulesDialogCmp) ID() dialogs.DialogID {
+	return SchedulesDialogID
+}
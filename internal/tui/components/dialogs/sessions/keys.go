@@ -8,6 +8,7 @@ type KeyMap struct {
 	Select,
 	Next,
 	Previous,
+	GroupByTag,
 	Close key.Binding
 }
 
@@ -25,6 +26,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("up", "ctrl+p"),
 			key.WithHelp("↑", "previous item"),
 		),
+		GroupByTag: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "group by tag"),
+		),
 		Close: key.NewBinding(
 			key.WithKeys("esc", "alt+esc"),
 			key.WithHelp("esc", "exit"),
@@ -38,6 +43,7 @@ func (k KeyMap) KeyBindings() []key.Binding {
 		k.Select,
 		k.Next,
 		k.Previous,
+		k.GroupByTag,
 		k.Close,
 	}
 }
@@ -62,6 +68,7 @@ func (k KeyMap) ShortHelp() []key.Binding {
 			key.WithHelp("↑↓", "choose"),
 		),
 		k.Select,
+		k.GroupByTag,
 		k.Close,
 	}
 }
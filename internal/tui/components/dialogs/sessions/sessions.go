@@ -1,6 +1,10 @@
 package sessions
 
 import (
+	"maps"
+	"slices"
+	"strings"
+
 	"charm.land/bubbles/v2/help"
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
@@ -9,6 +13,7 @@ import (
 	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
 	"github.com/charmbracelet/crush/internal/tui/exp/list"
 	"github.com/charmbracelet/crush/internal/tui/styles"
@@ -22,7 +27,10 @@ type SessionDialog interface {
 	dialogs.DialogModel
 }
 
-type SessionsList = list.FilterableList[list.CompletionItem[session.Session]]
+type SessionsList = list.FilterableGroupList[list.CompletionItem[session.Session]]
+
+// untagged is the group of the sessions without tags.
+const untagged = "Untagged"
 
 type sessionDialogCmp struct {
 	selectedInx       int
@@ -33,6 +41,11 @@ type sessionDialogCmp struct {
 	keyMap            KeyMap
 	sessionsList      SessionsList
 	help              help.Model
+
+	sessions []session.Session
+	// byTag groups the sessions by tag rather than listing them from the
+	// most recent; typing a tag then filters its sessions.
+	byTag bool
 }
 
 // NewSessionDialogCmp creates a new session switching dialog
@@ -45,16 +58,9 @@ func NewSessionDialogCmp(sessions []session.Session, selectedID string) SessionD
 	listKeyMap.DownOneItem = keyMap.Next
 	listKeyMap.UpOneItem = keyMap.Previous
 
-	items := make([]list.CompletionItem[session.Session], len(sessions))
-	if len(sessions) > 0 {
-		for i, session := range sessions {
-			items[i] = list.NewCompletionItem(session.Title, session, list.WithCompletionID(session.ID))
-		}
-	}
-
 	inputStyle := t.S().Base.PaddingLeft(1).PaddingBottom(1)
-	sessionsList := list.NewFilterableList(
-		items,
+	sessionsList := list.NewFilterableGroupedList(
+		groupSessions(sessions, false),
 		list.WithFilterPlaceholder("Enter a session name"),
 		list.WithFilterInputStyle(inputStyle),
 		list.WithFilterListOptions(
@@ -69,6 +75,7 @@ func NewSessionDialogCmp(sessions []session.Session, selectedID string) SessionD
 		keyMap:            DefaultKeyMap(),
 		sessionsList:      sessionsList,
 		help:              help,
+		sessions:          sessions,
 	}
 
 	return s
@@ -77,7 +84,9 @@ func NewSessionDialogCmp(sessions []session.Session, selectedID string) SessionD
 func (s *sessionDialogCmp) Init() tea.Cmd {
 	var cmds []tea.Cmd
 	cmds = append(cmds, s.sessionsList.Init())
-	cmds = append(cmds, s.sessionsList.Focus())
+	if focusable, ok := s.sessionsList.(layout.Focusable); ok {
+		cmds = append(cmds, focusable.Focus())
+	}
 	return tea.Sequence(cmds...)
 }
 
@@ -96,6 +105,14 @@ func (s *sessionDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		return s, tea.Batch(cmds...)
 	case tea.KeyPressMsg:
 		switch {
+		case key.Matches(msg, s.keyMap.GroupByTag):
+			s.byTag = !s.byTag
+			if s.byTag {
+				s.sessionsList.SetInputPlaceholder("Enter a tag or a session name")
+			} else {
+				s.sessionsList.SetInputPlaceholder("Enter a session name")
+			}
+			return s, s.sessionsList.SetGroups(groupSessions(s.sessions, s.byTag))
 		case key.Matches(msg, s.keyMap.Select):
 			selectedItem := s.sessionsList.SelectedItem()
 			if selectedItem != nil {
@@ -119,6 +136,50 @@ func (s *sessionDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 	return s, nil
 }
 
+// groupSessions lists the sessions from the most recent, or in a group per
+// tag, where a session appears under each of its tags.
+func groupSessions(sessions []session.Session, byTag bool) []list.Group[list.CompletionItem[session.Session]] {
+	item := func(sess session.Session, id string) list.CompletionItem[session.Session] {
+		var tags string
+		if len(sess.Tags) > 0 {
+			tags = "#" + strings.Join(sess.Tags, " #")
+		}
+		return list.NewCompletionItem(sess.Title, sess, list.WithCompletionID(id), list.WithCompletionShortcut(tags))
+	}
+	if !byTag {
+		group := list.Group[list.CompletionItem[session.Session]]{Section: list.NewItemSection("Recent")}
+		for _, sess := range sessions {
+			group.Items = append(group.Items, item(sess, sess.ID))
+		}
+		return []list.Group[list.CompletionItem[session.Session]]{group}
+	}
+
+	tagged := map[string][]session.Session{}
+	for _, sess := range sessions {
+		if len(sess.Tags) == 0 {
+			tagged[untagged] = append(tagged[untagged], sess)
+		}
+		for _, tag := range sess.Tags {
+			tagged[tag] = append(tagged[tag], sess)
+		}
+	}
+	// The sessions without tags come last.
+	tags := slices.Sorted(maps.Keys(tagged))
+	if i := slices.Index(tags, untagged); i >= 0 {
+		tags = append(slices.Delete(tags, i, i+1), untagged)
+	}
+	groups := make([]list.Group[list.CompletionItem[session.Session]], 0, len(tags))
+	for _, tag := range tags {
+		group := list.Group[list.CompletionItem[session.Session]]{Section: list.NewItemSection(tag)}
+		for _, sess := range tagged[tag] {
+			// A session is listed under each of its tags.
+			group.Items = append(group.Items, item(sess, tag+":"+sess.ID))
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
 func (s *sessionDialogCmp) View() string {
 	t := styles.CurrentTheme()
 	listView := s.sessionsList.View()
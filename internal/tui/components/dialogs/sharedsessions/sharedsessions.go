@@ -0,0 +1,167 @@
+package sharedsessions
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/sessionshare"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const SharedSessionsDialogID dialogs.DialogID = "shared_sessions"
+
+// SharedSessionsDialog reads, without opening them as sessions, the
+// sessions teammates shared in the repository.
+type SharedSessionsDialog interface {
+	dialogs.DialogModel
+}
+
+type loadedMsg struct {
+	shared []sessionshare.Shared
+	err    error
+}
+
+type sharedSessionsDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	workingDir string
+	loading    bool
+	shared     []sessionshare.Shared
+	selected   int
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewSharedSessionsDialogCmp creates the viewer of the sessions shared in
+// workingDir.
+func NewSharedSessionsDialogCmp(workingDir string) SharedSessionsDialog {
+	return &sharedSessionsDialogCmp{
+		workingDir: workingDir,
+		loading:    true,
+		viewport:   viewport.New(),
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (d *sharedSessionsDialogCmp) Init() tea.Cmd {
+	return func() tea.Msg {
+		shared, err := sessionshare.List(d.workingDir)
+		return loadedMsg{shared: shared, err: err}
+	}
+}
+
+func (d *sharedSessionsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(120, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.viewport.SetContent(d.content())
+	case loadedMsg:
+		d.loading = false
+		if msg.err != nil {
+			return d, util.ReportError(msg.err)
+		}
+		d.shared = msg.shared
+		d.viewport.SetContent(d.content())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Previous):
+			d.show(d.selected - 1)
+			return d, nil
+		case key.Matches(msg, d.keyMap.Next):
+			d.show(d.selected + 1)
+			return d, nil
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// show selects the shared session at i, when there's one.
+func (d *sharedSessionsDialogCmp) show(i int) {
+	if i < 0 || i >= len(d.shared) || i == d.selected {
+		return
+	}
+	d.selected = i
+	d.viewport.SetContent(d.content())
+	d.viewport.GotoTop()
+}
+
+// content renders the selected shared session in the viewport.
+func (d *sharedSessionsDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	switch {
+	case d.loading:
+		return t.S().Subtle.Render("Loading shared sessions...")
+	case len(d.shared) == 0:
+		return t.S().Subtle.Width(width).Render("No sessions shared in " + sessionshare.Dir + " yet. Share one with the Share Session command.")
+	}
+
+	rendered, err := styles.GetMarkdownRenderer(width).Render(sessionshare.Markdown(d.shared[d.selected]))
+	if err != nil {
+		return t.S().Error.Width(width).Render(err.Error())
+	}
+	return strings.TrimSuffix(rendered, "\n")
+}
+
+func (d *sharedSessionsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := "Shared Sessions"
+	if len(d.shared) > 0 {
+		title = fmt.Sprintf("Shared Session %d/%d", d.selected+1, len(d.shared))
+	}
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *sharedSessionsDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *sharedSessionsDialogCmp) ID() dialogs.DialogID {
+	return SharedSessionsDialogID
+}
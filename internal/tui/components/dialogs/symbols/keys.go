@@ -0,0 +1,116 @@
+package symbols
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the symbol locations dialog.
+type KeyMap struct {
+	Next,
+	Previous,
+	Select,
+	ToggleMode,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "ctrl+n"),
+			key.WithHelp("↓", "next"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "ctrl+p"),
+			key.WithHelp("↑", "previous"),
+		),
+		Select: key.NewBinding(
+			key.WithKeys("enter", "ctrl+y"),
+			key.WithHelp("enter", "search/open"),
+		),
+		ToggleMode: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "definitions/references"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Select,
+		k.ToggleMode,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Select,
+		k.ToggleMode,
+		k.Close,
+	}
+}
+
+// PickerKeyMap defines the keyboard bindings for the symbol picker.
+type PickerKeyMap struct {
+	Next,
+	Previous,
+	Open,
+	Close key.Binding
+}
+
+func DefaultPickerKeyMap() PickerKeyMap {
+	return PickerKeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "ctrl+n"),
+			key.WithHelp("↓", "next"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "ctrl+p"),
+			key.WithHelp("↑", "previous"),
+		),
+		Open: key.NewBinding(
+			key.WithKeys("enter", "ctrl+y"),
+			key.WithHelp("enter", "open"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "exit"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k PickerKeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Open,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k PickerKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k PickerKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Open,
+		k.Close,
+	}
+}
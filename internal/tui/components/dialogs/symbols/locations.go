@@ -0,0 +1,298 @@
+package symbols
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	LocationsDialogID dialogs.DialogID = "symbol_locations"
+
+	// listHeight is the number of locations visible at once.
+	listHeight = 12
+)
+
+// Location is a place in the workspace where a symbol is defined or used.
+type Location struct {
+	Path   string
+	Line   int
+	Column int
+	// Text is the trimmed source line at the location.
+	Text string
+}
+
+// LocationsDialog looks up the definitions or references of a symbol through
+// the LSP servers and opens them in the editor.
+type LocationsDialog interface {
+	dialogs.DialogModel
+}
+
+type locationsResultMsg struct {
+	query      string
+	references bool
+	locations  []Location
+	err        error
+}
+
+type locationsDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	lspClients *csync.Map[string, *lsp.Client]
+
+	input      textinput.Model
+	references bool
+
+	// query and searched describe the search the current results belong to.
+	query     string
+	searched  bool
+	searching bool
+	locations []Location
+	err       error
+	selected  int
+	offset    int
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewLocationsDialogCmp creates the dialog, searching for symbol right away
+// when it isn't empty. It lists references when references is set and
+// definitions otherwise.
+func NewLocationsDialogCmp(lspClients *csync.Map[string, *lsp.Client], symbol string, references bool) LocationsDialog {
+	t := styles.CurrentTheme()
+	input := textinput.New()
+	input.SetVirtualCursor(false)
+	input.Placeholder = "Symbol, e.g. UserService or config.Load"
+	input.SetStyles(t.S().TextInput)
+	input.SetValue(strings.TrimSpace(symbol))
+	input.Focus()
+
+	return &locationsDialogCmp{
+		lspClients: lspClients,
+		input:      input,
+		references: references,
+		keyMap:     DefaultKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (d *locationsDialogCmp) Init() tea.Cmd {
+	if d.input.Value() == "" {
+		return nil
+	}
+	return d.search()
+}
+
+func (d *locationsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(100, d.wWidth-4)
+		d.input.SetWidth(d.width - 6)
+	case locationsResultMsg:
+		if msg.query != d.query || msg.references != d.references {
+			return d, nil
+		}
+		d.searching = false
+		d.locations, d.err = msg.locations, msg.err
+		d.selected, d.offset = 0, 0
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Next):
+			d.move(1)
+		case key.Matches(msg, d.keyMap.Previous):
+			d.move(-1)
+		case key.Matches(msg, d.keyMap.ToggleMode):
+			d.references = !d.references
+			return d, d.search()
+		case key.Matches(msg, d.keyMap.Select):
+			if d.stale() {
+				return d, d.search()
+			}
+			if len(d.locations) == 0 {
+				return d, nil
+			}
+			loc := d.locations[d.selected]
+			return d, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.OpenInEditor(loc.Path, loc.Line),
+			)
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		default:
+			var cmd tea.Cmd
+			d.input, cmd = d.input.Update(msg)
+			return d, cmd
+		}
+	case tea.PasteMsg:
+		var cmd tea.Cmd
+		d.input, cmd = d.input.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// stale reports whether the results don't match the symbol being typed.
+func (d *locationsDialogCmp) stale() bool {
+	return !d.searched || d.query != strings.TrimSpace(d.input.Value())
+}
+
+func (d *locationsDialogCmp) search() tea.Cmd {
+	query := strings.TrimSpace(d.input.Value())
+	if query == "" {
+		return nil
+	}
+	if d.lspClients.Len() == 0 {
+		return util.ReportWarn("No LSP servers are running")
+	}
+	d.query, d.searched, d.searching = query, true, true
+	d.locations, d.err = nil, nil
+
+	references := d.references
+	return func() tea.Msg {
+		locations, err := tools.FindSymbol(context.Background(), d.lspClients, query, ".", !references)
+		result := locationsResultMsg{query: query, references: references, err: err}
+		for _, loc := range locations {
+			path, err := loc.URI.Path()
+			if err != nil {
+				continue
+			}
+			line := int(loc.Range.Start.Line) + 1
+			result.locations = append(result.locations, Location{
+				Path:   path,
+				Line:   line,
+				Column: int(loc.Range.Start.Character) + 1,
+				Text:   readLine(path, line),
+			})
+		}
+		return result
+	}
+}
+
+// readLine returns the trimmed 1-based line of the file, or an empty string
+// if it can't be read.
+func readLine(path string, line int) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for i := 1; scanner.Scan(); i++ {
+		if i == line {
+			return strings.TrimSpace(scanner.Text())
+		}
+	}
+	return ""
+}
+
+func (d *locationsDialogCmp) move(delta int) {
+	if len(d.locations) == 0 {
+		return
+	}
+	d.selected = max(0, min(d.selected+delta, len(d.locations)-1))
+	if d.selected < d.offset {
+		d.offset = d.selected
+	}
+	if d.selected >= d.offset+listHeight {
+		d.offset = d.selected - listHeight + 1
+	}
+}
+
+func (d *locationsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+
+	title := "Go to Definition"
+	if d.references {
+		title = "Find References"
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, width))
+	input := t.S().Base.Padding(0, 1, 1, 1).Render(d.input.View())
+
+	var body []string
+	switch {
+	case d.searching:
+		body = append(body, t.S().Subtle.Render("Searching…"))
+	case errors.Is(d.err, tools.ErrSymbolNotFound):
+		body = append(body, t.S().Subtle.Render(fmt.Sprintf("%q doesn't appear in the workspace.", d.query)))
+	case d.err != nil:
+		body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render(d.err.Error()))
+	case d.searched && len(d.locations) == 0:
+		body = append(body, t.S().Subtle.Render("No locations found."))
+	}
+
+	end := min(d.offset+listHeight, len(d.locations))
+	for i := d.offset; i < end; i++ {
+		body = append(body, d.locationView(d.locations[i], i == d.selected, width))
+	}
+	if len(d.locations) > listHeight {
+		body = append(body, t.S().Subtle.Render(fmt.Sprintf("%d/%d", d.selected+1, len(d.locations))))
+	}
+
+	parts := []string{header, input}
+	if len(body) > 0 {
+		parts = append(parts, t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)), "")
+	}
+	parts = append(parts, t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)))
+
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+func (d *locationsDialogCmp) locationView(loc Location, selected bool, width int) string {
+	t := styles.CurrentTheme()
+	location := fmt.Sprintf("%s:%d:%d", fsext.PrettyPath(loc.Path), loc.Line, loc.Column)
+	locationStyle := t.S().Subtle
+	if selected {
+		locationStyle = t.S().Base.Foreground(t.Primary).Bold(true)
+	}
+	line := locationStyle.Render(location) + " " + t.S().Text.Render(loc.Text)
+	return ansi.Truncate(line, width, "…")
+}
+
+func (d *locationsDialogCmp) Cursor() *tea.Cursor {
+	cursor := d.input.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := d.Position()
+	cursor.Y += row + 3 // border, title and padding
+	cursor.X += col + 2 // border and padding
+	return cursor
+}
+
+func (d *locationsDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *locationsDialogCmp) ID() dialogs.DialogID {
+	return LocationsDialogID
+}
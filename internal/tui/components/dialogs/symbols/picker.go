@@ -0,0 +1,191 @@
+package symbols
+
+import (
+	"context"
+	"fmt"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/symbols"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const PickerDialogID dialogs.DialogID = "symbol_picker"
+
+// PickerDialog searches the symbols declared in the workspace and opens the
+// chosen one in the editor.
+type PickerDialog interface {
+	dialogs.DialogModel
+}
+
+type indexedMsg struct {
+	symbols []symbols.Symbol
+	err     error
+}
+
+type pickerDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	workingDir string
+	index      []symbols.Symbol
+	indexing   bool
+	err        error
+
+	input    textinput.Model
+	matches  []symbols.Symbol
+	selected int
+
+	keyMap PickerKeyMap
+	help   help.Model
+}
+
+// NewPickerDialogCmp creates the symbol picker for the workspace at
+// workingDir.
+func NewPickerDialogCmp(workingDir string) PickerDialog {
+	t := styles.CurrentTheme()
+	input := textinput.New()
+	input.SetVirtualCursor(false)
+	input.Placeholder = "Type to search symbols"
+	input.SetStyles(t.S().TextInput)
+	input.Focus()
+
+	return &pickerDialogCmp{
+		workingDir: workingDir,
+		indexing:   true,
+		input:      input,
+		keyMap:     DefaultPickerKeyMap(),
+		help:       help.New(),
+	}
+}
+
+func (p *pickerDialogCmp) Init() tea.Cmd {
+	workingDir := p.workingDir
+	return func() tea.Msg {
+		index, err := symbols.Index(context.Background(), workingDir)
+		return indexedMsg{symbols: index, err: err}
+	}
+}
+
+func (p *pickerDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.wWidth = msg.Width
+		p.wHeight = msg.Height
+		p.width = min(100, p.wWidth-4)
+		p.input.SetWidth(p.width - 6)
+	case indexedMsg:
+		p.indexing = false
+		p.index, p.err = msg.symbols, msg.err
+		p.filter()
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, p.keyMap.Next):
+			p.selected = min(p.selected+1, len(p.matches)-1)
+		case key.Matches(msg, p.keyMap.Previous):
+			p.selected = max(p.selected-1, 0)
+		case key.Matches(msg, p.keyMap.Open):
+			if len(p.matches) == 0 {
+				return p, nil
+			}
+			s := p.matches[p.selected]
+			return p, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.OpenInEditor(s.Path, s.Line),
+			)
+		case key.Matches(msg, p.keyMap.Close):
+			return p, util.CmdHandler(dialogs.CloseDialogMsg{})
+		default:
+			var cmd tea.Cmd
+			p.input, cmd = p.input.Update(msg)
+			p.filter()
+			return p, cmd
+		}
+	case tea.PasteMsg:
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		p.filter()
+		return p, cmd
+	}
+	return p, nil
+}
+
+func (p *pickerDialogCmp) filter() {
+	p.matches = symbols.Filter(p.index, p.input.Value(), listHeight)
+	p.selected = 0
+}
+
+func (p *pickerDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	width := p.width - 4
+
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Symbols", width))
+	input := t.S().Base.Padding(0, 1, 1, 1).Render(p.input.View())
+
+	var body []string
+	switch {
+	case p.indexing:
+		body = append(body, t.S().Subtle.Render("Indexing workspace…"))
+	case p.err != nil:
+		body = append(body, t.S().Base.Foreground(t.Error).Width(width).Render(p.err.Error()))
+	case p.input.Value() == "":
+		body = append(body, t.S().Subtle.Render(fmt.Sprintf("%d symbols indexed.", len(p.index))))
+	case len(p.matches) == 0:
+		body = append(body, t.S().Subtle.Render("No matching symbols."))
+	}
+	for i, s := range p.matches {
+		name := s.Name
+		if i == p.selected {
+			name = t.S().Base.Foreground(t.Primary).Bold(true).Render(name)
+		} else {
+			name = t.S().Text.Render(name)
+		}
+		location := fmt.Sprintf("%s:%d", fsext.PrettyPath(s.Path), s.Line)
+		line := t.S().Muted.Render(fmt.Sprintf("%-9s", s.Kind)) + " " + name + " " + t.S().Subtle.Render(location)
+		body = append(body, ansi.Truncate(line, width, "…"))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		input,
+		t.S().Base.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		"",
+		t.S().Base.Width(p.width-2).PaddingLeft(1).Render(p.help.View(p.keyMap)),
+	)
+	return t.S().Base.
+		Width(p.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (p *pickerDialogCmp) Cursor() *tea.Cursor {
+	cursor := p.input.Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := p.Position()
+	cursor.Y += row + 3 // border, title and padding
+	cursor.X += col + 2 // border and padding
+	return cursor
+}
+
+func (p *pickerDialogCmp) Position() (int, int) {
+	row := p.wHeight/4 - 2 // just a bit above the center
+	col := p.wWidth / 2
+	col -= p.width / 2
+	return max(0, row), col
+}
+
+func (p *pickerDialogCmp) ID() dialogs.DialogID {
+	return PickerDialogID
+}
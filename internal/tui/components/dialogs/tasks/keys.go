@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyMap defines the keyboard bindings for the tasks dialog.
+type KeyMap struct {
+	Up,
+	Down,
+	Toggle,
+	Continue,
+	Close key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "previous"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "next"),
+		),
+		Toggle: key.NewBinding(
+			key.WithKeys("space", "x"),
+			key.WithHelp("space", "done/undone"),
+		),
+		Continue: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "continue with it"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "alt+esc"),
+			key.WithHelp("esc", "close"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Up,
+		k.Down,
+		k.Toggle,
+		k.Continue,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.KeyBindings()}
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.Toggle,
+		k.Continue,
+		k.Close,
+	}
+}
@@ -0,0 +1,178 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/task"
+	"github.com/charmbracelet/crush/internal/tui/components/chat"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const TasksDialogID dialogs.DialogID = "tasks"
+
+// TasksDialog shows the task list of a session, taken from the plans of
+// the agent, to mark tasks done and have the agent continue with one.
+type TasksDialog interface {
+	dialogs.DialogModel
+}
+
+type loadedMsg struct {
+	tasks []task.Task
+	err   error
+}
+
+type tasksDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	service   task.Service
+	sessionID string
+	tasks     []task.Task
+	loaded    bool
+	cursor    int
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewTasksDialogCmp creates the dialog for the tasks of the session.
+func NewTasksDialogCmp(service task.Service, sessionID string) TasksDialog {
+	return &tasksDialogCmp{
+		service:   service,
+		sessionID: sessionID,
+		keyMap:    DefaultKeyMap(),
+		help:      help.New(),
+	}
+}
+
+func (d *tasksDialogCmp) Init() tea.Cmd {
+	return d.load()
+}
+
+func (d *tasksDialogCmp) load() tea.Cmd {
+	return func() tea.Msg {
+		tasks, err := d.service.List(context.Background(), d.sessionID)
+		return loadedMsg{tasks: tasks, err: err}
+	}
+}
+
+func (d *tasksDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(90, d.wWidth-4)
+	case loadedMsg:
+		if msg.err != nil {
+			return d, util.ReportError(msg.err)
+		}
+		d.tasks = msg.tasks
+		d.loaded = true
+		d.cursor = max(0, min(d.cursor, len(d.tasks)-1))
+	case pubsub.Event[task.Task]:
+		if msg.Payload.SessionID == d.sessionID {
+			return d, d.load()
+		}
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Up):
+			d.cursor = max(0, d.cursor-1)
+		case key.Matches(msg, d.keyMap.Down):
+			d.cursor = max(0, min(len(d.tasks)-1, d.cursor+1))
+		case key.Matches(msg, d.keyMap.Toggle):
+			if len(d.tasks) == 0 {
+				return d, nil
+			}
+			selected := d.tasks[d.cursor]
+			updated, err := d.service.SetDone(context.Background(), selected.ID, !selected.Done)
+			if err != nil {
+				return d, util.ReportError(err)
+			}
+			d.tasks[d.cursor] = updated
+		case key.Matches(msg, d.keyMap.Continue):
+			if len(d.tasks) == 0 {
+				return d, nil
+			}
+			selected := d.tasks[d.cursor]
+			return d, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.CmdHandler(chat.SendMsg{
+					Text: fmt.Sprintf("Continue with task %d: %s", selected.Position+1, selected.Content),
+				}),
+			)
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return d, nil
+}
+
+func (d *tasksDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base
+
+	done := 0
+	for _, task := range d.tasks {
+		if task.Done {
+			done++
+		}
+	}
+	info := t.S().Subtle.Render(fmt.Sprintf("%d/%d", done, len(d.tasks)))
+	header := baseStyle.Padding(0, 1, 1, 1).Render(core.SectionWithInfo("Tasks", d.width-4, info))
+
+	var rows []string
+	for i, task := range d.tasks {
+		icon := t.S().Subtle.Render("○")
+		style := t.S().Muted
+		if task.Done {
+			icon = t.S().Base.Foreground(t.Green).Render("✓")
+			style = t.S().Subtle.Strikethrough(true)
+		}
+		number := fmt.Sprintf("%2d.", i+1)
+		if i == d.cursor {
+			number = t.S().Base.Foreground(t.Primary).Render(number)
+			if !task.Done {
+				style = t.S().Text.Bold(true)
+			}
+		} else {
+			number = t.S().Subtle.Render(number)
+		}
+		rows = append(rows, fmt.Sprintf("%s %s %s", number, icon, style.Width(d.width-12).Render(task.Content)))
+	}
+	if len(rows) == 0 && d.loaded {
+		rows = append(rows, t.S().Subtle.Render("No tasks yet. They're taken from the plans and checklists the agent writes."))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		baseStyle.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, rows...)),
+		"",
+		baseStyle.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return baseStyle.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *tasksDialogCmp) Position() (int, int) {
+	row := d.wHeight/4 - 2 // just a bit above the center
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return row, col
+}
+
+func (d *tasksDialogCmp) ID() dialogs.DialogID {
+	return TasksDialogID
+}
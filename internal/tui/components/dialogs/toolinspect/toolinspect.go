@@ -0,0 +1,207 @@
+package toolinspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const ToolInspectDialogID dialogs.DialogID = "tool_inspect"
+
+// RerunToolMsg asks the agent to call the tool again with the same
+// arguments, going through the usual permission checks.
+type RerunToolMsg struct {
+	Call message.ToolCall
+}
+
+// RerunPrompt builds the message asking the agent to call the tool again.
+func RerunPrompt(call message.ToolCall) string {
+	return fmt.Sprintf("Call the %s tool again with exactly these arguments and tell me what changed:\n\n```json\n%s\n```", call.Name, prettyJSON(call.Input))
+}
+
+// ToolInspectDialog shows the full arguments and output of a tool call.
+type ToolInspectDialog interface {
+	dialogs.DialogModel
+}
+
+// fullOutputMsg carries the full output of a truncated result.
+type fullOutputMsg struct {
+	content string
+}
+
+type toolInspectDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	call    message.ToolCall
+	result  message.ToolResult
+	results *tools.ToolResults
+	// full is the output of the tool before it was truncated for the agent.
+	full string
+
+	viewport viewport.Model
+	keyMap   KeyMap
+	help     help.Model
+}
+
+// NewToolInspectDialogCmp creates the inspector of a tool call. The result
+// is empty while the tool runs, and shown whole from results when it was
+// truncated.
+func NewToolInspectDialogCmp(call message.ToolCall, result message.ToolResult, results *tools.ToolResults) ToolInspectDialog {
+	return &toolInspectDialogCmp{
+		call:     call,
+		result:   result,
+		results:  results,
+		viewport: viewport.New(),
+		keyMap:   DefaultKeyMap(),
+		help:     help.New(),
+	}
+}
+
+func (d *toolInspectDialogCmp) Init() tea.Cmd {
+	if d.result.ToolCallID == "" {
+		return nil
+	}
+	results, id := d.results, d.result.ToolCallID
+	return func() tea.Msg {
+		content, ok := results.Load(id)
+		if !ok {
+			return nil
+		}
+		return fullOutputMsg{content: content}
+	}
+}
+
+func (d *toolInspectDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.wWidth = msg.Width
+		d.wHeight = msg.Height
+		d.width = min(120, d.wWidth-4)
+		d.height = max(10, d.wHeight*3/4)
+		d.viewport.SetWidth(d.width - 4)
+		d.viewport.SetHeight(d.height - 6) // border, title and help
+		d.viewport.SetContent(d.content())
+	case fullOutputMsg:
+		d.full = msg.content
+		d.viewport.SetContent(d.content())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Copy):
+			return d, util.CopyToClipboard(d.plain(), "Tool call")
+		case key.Matches(msg, d.keyMap.Rerun):
+			return d, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.CmdHandler(RerunToolMsg{Call: d.call}),
+			)
+		case key.Matches(msg, d.keyMap.Close):
+			return d, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		d.viewport, cmd = d.viewport.Update(msg)
+		return d, cmd
+	}
+	return d, nil
+}
+
+// output returns the label and content of the result section.
+func (d *toolInspectDialogCmp) output() (string, string) {
+	switch {
+	case d.result.ToolCallID == "":
+		return "Output", "The tool hasn't finished yet."
+	case d.result.IsError:
+		return "Error", d.result.Content
+	case d.full != "":
+		return "Full Output", d.full
+	case d.result.Data != "":
+		return "Output", fmt.Sprintf("%s\n\n[%s data, %d bytes]", d.result.Content, d.result.MIMEType, len(d.result.Data))
+	default:
+		return "Output", d.result.Content
+	}
+}
+
+// content renders the sections shown in the viewport.
+func (d *toolInspectDialogCmp) content() string {
+	t := styles.CurrentTheme()
+	width := d.width - 4
+	section := func(title, body string) string {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			t.S().Base.Foreground(t.Primary).Bold(true).Render(title),
+			"",
+			t.S().Text.Width(width).Render(strings.ReplaceAll(body, "\t", "    ")),
+		)
+	}
+	label, output := d.output()
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		section("Arguments", prettyJSON(d.call.Input)),
+		"",
+		section(label, output),
+	)
+}
+
+// plain is what gets copied: the arguments and the output, as Markdown.
+func (d *toolInspectDialogCmp) plain() string {
+	label, output := d.output()
+	return fmt.Sprintf("## %s\n\n### Arguments\n\n```json\n%s\n```\n\n### %s\n\n```\n%s\n```\n", d.call.Name, prettyJSON(d.call.Input), label, output)
+}
+
+func prettyJSON(input string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(input), "", "  "); err != nil {
+		return input
+	}
+	return buf.String()
+}
+
+func (d *toolInspectDialogCmp) View() string {
+	t := styles.CurrentTheme()
+
+	title := d.call.Name
+	if d.viewport.TotalLineCount() > d.viewport.Height() {
+		title = fmt.Sprintf("%s %d%%", title, int(d.viewport.ScrollPercent()*100))
+	}
+	header := t.S().Base.Padding(0, 1, 1, 1).Render(core.Title(title, d.width-4))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		t.S().Base.Padding(0, 1).Render(d.viewport.View()),
+		"",
+		t.S().Base.Width(d.width-2).PaddingLeft(1).Render(d.help.View(d.keyMap)),
+	)
+	return t.S().Base.
+		Width(d.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Render(content)
+}
+
+func (d *toolInspectDialogCmp) Position() (int, int) {
+	row := (d.wHeight - d.height) / 2
+	col := d.wWidth / 2
+	col -= d.width / 2
+	return max(0, row), col
+}
+
+func (d *toolInspectDialogCmp) ID() dialogs.DialogID {
+	return ToolInspectDialogID
+}
@@ -0,0 +1,48 @@
+package toolinspect
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlain(t *testing.T) {
+	t.Parallel()
+
+	call := message.ToolCall{ID: "call_1", Name: "bash", Input: `{"command":"ls"}`}
+	d := NewToolInspectDialogCmp(call, message.ToolResult{ToolCallID: "call_1", Content: "go.mod\nmain.go"}, nil).(*toolInspectDialogCmp)
+	require.Equal(t, "## bash\n\n### Arguments\n\n```json\n{\n  \"command\": \"ls\"\n}\n```\n\n### Output\n\n```\ngo.mod\nmain.go\n```\n", d.plain())
+
+	d = NewToolInspectDialogCmp(call, message.ToolResult{ToolCallID: "call_1", Content: "boom", IsError: true}, nil).(*toolInspectDialogCmp)
+	label, output := d.output()
+	require.Equal(t, "Error", label)
+	require.Equal(t, "boom", output)
+
+	d = NewToolInspectDialogCmp(call, message.ToolResult{}, nil).(*toolInspectDialogCmp)
+	_, output = d.output()
+	require.Equal(t, "The tool hasn't finished yet.", output)
+}
+
+func TestFullOutput(t *testing.T) {
+	t.Parallel()
+
+	results := tools.NewToolResults(t.TempDir())
+	require.NoError(t, results.Save("call_1", "the whole output"))
+
+	call := message.ToolCall{ID: "call_1", Name: "bash", Input: `{"command":"go test ./..."}`}
+	d := NewToolInspectDialogCmp(call, message.ToolResult{ToolCallID: "call_1", Content: "the ... output"}, results).(*toolInspectDialogCmp)
+	d.Update(d.Init()())
+	label, output := d.output()
+	require.Equal(t, "Full Output", label)
+	require.Equal(t, "the whole output", output)
+}
+
+func TestRerunPrompt(t *testing.T) {
+	t.Parallel()
+
+	prompt := RerunPrompt(message.ToolCall{Name: "grep", Input: "not json"})
+	require.Contains(t, prompt, "the grep tool")
+	require.Contains(t, prompt, "```json\nnot json\n```")
+}
@@ -2,8 +2,8 @@ package files
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"image/color"
+	"slices"
 	"sort"
 	"strings"
 
@@ -37,6 +37,25 @@ type RenderOptions struct {
 	MaxItems    int
 	ShowSection bool
 	SectionName string
+	// Selected is the path of the file highlighted, if any.
+	Selected string
+}
+
+// Changed returns the files with changes, the last changed first.
+func Changed(fileSlice []SessionFile) []SessionFile {
+	changed := make([]SessionFile, 0, len(fileSlice))
+	for _, file := range fileSlice {
+		if file.Additions > 0 || file.Deletions > 0 {
+			changed = append(changed, file)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		if changed[i].History.LatestVersion.CreatedAt == changed[j].History.LatestVersion.CreatedAt {
+			return strings.Compare(changed[i].FilePath, changed[j].FilePath) < 0
+		}
+		return changed[i].History.LatestVersion.CreatedAt > changed[j].History.LatestVersion.CreatedAt
+	})
+	return changed
 }
 
 // RenderFileList renders a list of file status items with the given options.
@@ -58,28 +77,19 @@ func RenderFileList(fileSlice []SessionFile, opts RenderOptions) []string {
 		return fileList
 	}
 
-	// Sort files by the latest version's created time
-	sort.Slice(fileSlice, func(i, j int) bool {
-		if fileSlice[i].History.LatestVersion.CreatedAt == fileSlice[j].History.LatestVersion.CreatedAt {
-			return strings.Compare(fileSlice[i].FilePath, fileSlice[j].FilePath) < 0
-		}
-		return fileSlice[i].History.LatestVersion.CreatedAt > fileSlice[j].History.LatestVersion.CreatedAt
-	})
-
 	// Determine how many items to show
-	maxItems := len(fileSlice)
+	changed := Changed(fileSlice)
+	maxItems := len(changed)
 	if opts.MaxItems > 0 {
-		maxItems = min(opts.MaxItems, len(fileSlice))
+		maxItems = min(opts.MaxItems, len(changed))
+	}
+	// The selected file is kept in view.
+	start := 0
+	if i := slices.IndexFunc(changed, func(f SessionFile) bool { return f.FilePath == opts.Selected }); i >= maxItems {
+		start = i - maxItems + 1
 	}
 
-	filesShown := 0
-	for _, file := range fileSlice {
-		if file.Additions == 0 && file.Deletions == 0 {
-			continue // skip files with no changes
-		}
-		if filesShown >= maxItems {
-			break
-		}
+	for _, file := range changed[start : start+maxItems] {
 
 		var statusParts []string
 		if file.Additions > 0 {
@@ -90,24 +100,25 @@ func RenderFileList(fileSlice []SessionFile, opts RenderOptions) []string {
 		}
 
 		extraContent := strings.Join(statusParts, " ")
-		cwd := config.Get().WorkingDir() + string(os.PathSeparator)
-		filePath := file.FilePath
-		if rel, err := filepath.Rel(cwd, filePath); err == nil {
-			filePath = rel
-		}
+		// Files of other roots of the workspace start with the name of their root.
+		filePath := config.Get().RelPath(file.FilePath)
 		filePath = fsext.DirTrim(fsext.PrettyPath(filePath), 2)
 		filePath = ansi.Truncate(filePath, opts.MaxWidth-lipgloss.Width(extraContent)-2, "…")
 
+		var titleColor color.Color
+		if file.FilePath == opts.Selected {
+			titleColor = t.Primary
+		}
 		fileList = append(fileList,
 			core.Status(
 				core.StatusOpts{
 					Title:        filePath,
+					TitleColor:   titleColor,
 					ExtraContent: extraContent,
 				},
 				opts.MaxWidth,
 			),
 		)
-		filesShown++
 	}
 
 	return fileList
@@ -128,6 +128,8 @@ func iconAndDescription(l config.LSP, t *styles.Theme, states map[string]app.LSP
 		return t.ItemErrorIcon, description
 	case lsp.StateDisabled:
 		return t.ItemOfflineIcon.Foreground(t.FgMuted), t.S().Subtle.Render("inactive")
+	case lsp.StateCrashed:
+		return t.ItemErrorIcon, t.S().Subtle.Render("crashed")
 	default:
 		return t.ItemOfflineIcon, ""
 	}
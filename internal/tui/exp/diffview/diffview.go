@@ -12,6 +12,8 @@ import (
 	"github.com/aymanbagabas/go-udiff"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/zeebo/xxh3"
+
+	"github.com/charmbracelet/crush/internal/tui/highlight/cache"
 )
 
 const (
@@ -64,9 +66,8 @@ type DiffView struct {
 	// Cache lexer to avoid expensive file pattern matching on every line
 	cachedLexer chroma.Lexer
 
-	// Cache highlighted lines to avoid re-highlighting the same content
-	// Key: hash of (content + background color), Value: highlighted string
-	syntaxCache map[string]string
+	// Fingerprint of chromaStyle, highlighted lines are cached by it
+	chromaStyleKey string
 }
 
 // New creates a new DiffView with default settings.
@@ -76,7 +77,6 @@ func New() *DiffView {
 		contextLines: udiff.DefaultContextLines,
 		lineNumbers:  true,
 		tabWidth:     8,
-		syntaxCache:  make(map[string]string),
 	}
 	dv.style = DefaultDarkStyle()
 	return dv
@@ -113,7 +113,6 @@ func (dv *DiffView) After(path, content string) *DiffView {
 // clearCaches clears all caches when content or major settings change.
 func (dv *DiffView) clearCaches() {
 	dv.cachedLexer = nil
-	dv.clearSyntaxCache()
 	dv.isComputed = false
 }
 
@@ -176,19 +175,21 @@ func (dv *DiffView) TabWidth(tabWidth int) *DiffView {
 // If nil, no syntax highlighting will be applied.
 func (dv *DiffView) ChromaStyle(style *chroma.Style) *DiffView {
 	dv.chromaStyle = style
-	// Clear syntax cache when style changes since highlighting will be different
-	dv.clearSyntaxCache()
+	// Highlighting changes with the style, so it is part of the cache key
+	dv.chromaStyleKey = styleKey(style)
 	return dv
 }
 
-// clearSyntaxCache clears the syntax highlighting cache.
-func (dv *DiffView) clearSyntaxCache() {
-	if dv.syntaxCache != nil {
-		// Clear the map but keep it allocated
-		for k := range dv.syntaxCache {
-			delete(dv.syntaxCache, k)
-		}
+// styleKey returns a fingerprint of the style's entries.
+func styleKey(style *chroma.Style) string {
+	if style == nil {
+		return ""
+	}
+	h := xxh3.New()
+	for _, t := range style.Types() {
+		fmt.Fprintf(h, "%d=%s;", t, style.Get(t))
 	}
+	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
 // String returns the string representation of the DiffView.
@@ -708,49 +709,27 @@ func (dv *DiffView) hightlightCode(source string, bgColor color.Color) string {
 		return source
 	}
 
-	// Create cache key from content and background color
-	cacheKey := dv.createSyntaxCacheKey(source, bgColor)
-
-	// Check if we already have this highlighted
-	if cached, exists := dv.syntaxCache[cacheKey]; exists {
-		return cached
-	}
-
 	l := dv.getChromaLexer()
-	f := dv.getChromaFormatter(bgColor)
 
-	it, err := l.Tokenise(nil, source)
+	// Highlighted lines are shared with other diffs of the same file type
+	key := cache.NewKey(source, l.Config().Name, dv.chromaStyleKey, "diffview", bgColor)
+	result, err := cache.Highlight(key, func() (string, error) {
+		it, err := l.Tokenise(nil, source)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		if err := dv.getChromaFormatter(bgColor).Format(&b, dv.chromaStyle, it); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	})
 	if err != nil {
 		return source
 	}
-
-	var b strings.Builder
-	if err := f.Format(&b, dv.chromaStyle, it); err != nil {
-		return source
-	}
-
-	result := b.String()
-
-	// Cache the result for future use
-	dv.syntaxCache[cacheKey] = result
-
 	return result
 }
 
-// createSyntaxCacheKey creates a cache key from source content and background color.
-// We use a simple hash to keep memory usage reasonable.
-func (dv *DiffView) createSyntaxCacheKey(source string, bgColor color.Color) string {
-	// Convert color to string representation
-	r, g, b, a := bgColor.RGBA()
-	colorStr := fmt.Sprintf("%d,%d,%d,%d", r, g, b, a)
-
-	// Create a hash of the content + color to use as cache key
-	h := xxh3.New()
-	h.Write([]byte(source))
-	h.Write([]byte(colorStr))
-	return fmt.Sprintf("%x", h.Sum(nil))
-}
-
 func (dv *DiffView) getChromaLexer() chroma.Lexer {
 	if dv.cachedLexer != nil {
 		return dv.cachedLexer
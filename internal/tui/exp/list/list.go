@@ -72,6 +72,7 @@ type List[T Item] interface {
 	SelectionClear()
 	SelectWord(col, line int)
 	SelectParagraph(col, line int)
+	SelectItems(fromID, toID string)
 	GetSelectedText(paddingLeft int) string
 	HasSelection() bool
 }
@@ -117,6 +118,11 @@ type list[T Item] struct {
 	items         []T
 	renderedItems map[string]renderedItem
 
+	// window is how many items, counting from the bottom, a backward list
+	// renders. The ones above it are only rendered once scrolled to, so long
+	// chats don't render every message.
+	window int
+
 	rendered       string
 	renderedHeight int   // cached height of rendered content
 	lineOffsets    []int // cached byte offsets for each line (for fast slicing)
@@ -137,6 +143,10 @@ type list[T Item] struct {
 
 type ListOption func(*confOptions)
 
+// overscanScreens is how many screens of items past the viewport backward
+// lists render, so scrolling up doesn't have to render on every line.
+const overscanScreens = 5
+
 // WithSize sets the size of the list.
 func WithSize(width, height int) ListOption {
 	return func(l *confOptions) {
@@ -661,9 +671,14 @@ func (l *list[T]) render() tea.Cmd {
 	} else {
 		focusChangeCmd = l.blurSelectedItem()
 	}
+	if l.direction == DirectionBackward && l.selectedItemIdx >= 0 {
+		// The selected item has to be rendered to scroll to it.
+		l.window = max(l.window, len(l.items)-l.selectedItemIdx)
+	}
 	if l.rendered != "" {
-		rendered, _ := l.renderIterator(0, false, "")
+		rendered, finishIndex := l.renderIterator(0, false, "", l.linesToRender(l.offset))
 		l.setRendered(rendered)
+		l.window = finishIndex
 		if l.direction == DirectionBackward {
 			l.recalculateItemPositions()
 		}
@@ -672,15 +687,16 @@ func (l *list[T]) render() tea.Cmd {
 		}
 		return focusChangeCmd
 	}
-	rendered, finishIndex := l.renderIterator(0, true, "")
+	rendered, finishIndex := l.renderIterator(0, true, "", 0)
 	l.setRendered(rendered)
 	if l.direction == DirectionBackward {
 		l.recalculateItemPositions()
 	}
 
 	l.offset = 0
-	rendered, _ = l.renderIterator(finishIndex, false, l.rendered)
+	rendered, finishIndex = l.renderIterator(finishIndex, false, l.rendered, l.linesToRender(l.offset))
 	l.setRendered(rendered)
+	l.window = finishIndex
 	if l.direction == DirectionBackward {
 		l.recalculateItemPositions()
 	}
@@ -691,6 +707,24 @@ func (l *list[T]) render() tea.Cmd {
 	return focusChangeCmd
 }
 
+// linesToRender is how many lines a backward list renders when its view is
+// offset lines from the bottom.
+func (l *list[T]) linesToRender(offset int) int {
+	return offset + l.height*(overscanScreens+1)
+}
+
+// renderAbove renders more of the items above the window of a backward list
+// when scrolling up to offset gets close to the top of what is rendered.
+func (l *list[T]) renderAbove(offset int) {
+	if l.direction != DirectionBackward || l.rendered == "" || l.window >= len(l.items) || offset+2*l.height <= l.renderedHeight {
+		return
+	}
+	rendered, finishIndex := l.renderIterator(l.window, false, l.rendered, l.linesToRender(offset))
+	l.setRendered(rendered)
+	l.window = finishIndex
+	l.recalculateItemPositions()
+}
+
 func (l *list[T]) setDefaultSelected() {
 	if l.selectedItemIdx < 0 {
 		if l.direction == DirectionForward {
@@ -955,7 +989,8 @@ type renderFragment struct {
 // renderIterator renders items starting from the specific index and limits height if limitHeight != -1
 // returns the last index and the rendered content so far
 // we pass the rendered content around and don't use l.rendered to prevent jumping of the content
-func (l *list[T]) renderIterator(startInx int, limitHeight bool, rendered string) (string, int) {
+// backward lists also stop once the window and renderLines lines are rendered, when renderLines > 0
+func (l *list[T]) renderIterator(startInx int, limitHeight bool, rendered string, renderLines int) (string, int) {
 	// Pre-allocate fragments with expected capacity
 	itemsLen := len(l.items)
 	expectedFragments := itemsLen - startInx
@@ -966,6 +1001,10 @@ func (l *list[T]) renderIterator(startInx int, limitHeight bool, rendered string
 
 	currentContentHeight := lipgloss.Height(rendered) - 1
 	finalIndex := itemsLen
+	lines := 0
+	if rendered != "" {
+		lines = lipgloss.Height(rendered)
+	}
 
 	// first pass: accumulate all fragments to render until the height limit is
 	// reached
@@ -974,6 +1013,10 @@ func (l *list[T]) renderIterator(startInx int, limitHeight bool, rendered string
 			finalIndex = i
 			break
 		}
+		if l.direction == DirectionBackward && renderLines > 0 && i >= l.window && lines >= renderLines {
+			finalIndex = i
+			break
+		}
 		// cool way to go through the list in both directions
 		inx := i
 
@@ -1005,6 +1048,7 @@ func (l *list[T]) renderIterator(startInx int, limitHeight bool, rendered string
 		fragments = append(fragments, renderFragment{view: rItem.view, gap: gap})
 
 		currentContentHeight = rItem.end + 1 + l.gap
+		lines += rItem.height + l.gap
 	}
 
 	// second pass: build rendered string efficiently
@@ -1073,6 +1117,10 @@ func (l *list[T]) AppendItem(item T) tea.Cmd {
 	newIndex := len(l.items)
 	l.items = append(l.items, item)
 	l.indexMap[item.ID()] = newIndex
+	if l.direction == DirectionBackward && l.window > 0 {
+		// The new item is at the bottom, keep the ones above it rendered.
+		l.window++
+	}
 
 	if l.width > 0 && l.height > 0 {
 		cmd = item.SetSize(l.width, l.height)
@@ -1116,6 +1164,9 @@ func (l *list[T]) DeleteItem(id string) tea.Cmd {
 	if !ok {
 		return nil
 	}
+	if len(l.items)-1-inx < l.window {
+		l.window--
+	}
 	l.items = append(l.items[:inx], l.items[inx+1:]...)
 	delete(l.renderedItems, id)
 	delete(l.indexMap, id)
@@ -1201,6 +1252,7 @@ func (l *list[T]) Items() []T {
 }
 
 func (l *list[T]) incrementOffset(n int) {
+	l.renderAbove(l.offset + n)
 	// no need for offset
 	if l.renderedHeight <= l.height {
 		return
@@ -1445,6 +1497,7 @@ func (l *list[T]) reset(selectedItemID string) tea.Cmd {
 	l.rendered = ""
 	l.renderedHeight = 0
 	l.offset = 0
+	l.window = 0
 	l.indexMap = make(map[string]int)
 	l.renderedItems = make(map[string]renderedItem)
 	itemsLen := len(l.items)
@@ -1570,6 +1623,28 @@ func (l *list[T]) SelectionClear() {
 	l.selectionActive = false
 }
 
+// SelectItems selects the text of the items from one to the other, both
+// included, as if the mouse was dragged over them.
+func (l *list[T]) SelectItems(fromID, toID string) {
+	from, ok := l.renderedItems[fromID]
+	if !ok {
+		return
+	}
+	to, ok := l.renderedItems[toID]
+	if !ok {
+		return
+	}
+	if from.start > to.start {
+		from, to = to, from
+	}
+	start, _ := l.viewPosition()
+	l.selectionStartCol = 0
+	l.selectionStartLine = from.start - start
+	l.selectionEndCol = l.width
+	l.selectionEndLine = to.end - start
+	l.selectionActive = false
+}
+
 func (l *list[T]) findWordBoundaries(col, line int) (startCol, endCol int) {
 	numLines := l.lineCount()
 
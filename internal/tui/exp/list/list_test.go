@@ -194,18 +194,16 @@ func TestList(t *testing.T) {
 		assert.Equal(t, 0, l.offset)
 		require.Equal(t, 30, len(l.indexMap))
 		require.Equal(t, 30, len(l.items))
-		require.Equal(t, 30, len(l.renderedItems))
-		expectedLines := 0
-		for i := range 30 {
-			expectedLines += (i + 1) * 1
-		}
+		// only the items filling the viewport and the overscan are rendered
+		require.Equal(t, 3, len(l.renderedItems))
+		expectedLines := 28 + 29 + 30
 		assert.Equal(t, expectedLines, lipgloss.Height(l.rendered))
 		assert.NotEqual(t, "\n", string(l.rendered[len(l.rendered)-1]), "should not end in newline")
 		start, end := l.viewPosition()
 		assert.Equal(t, expectedLines-10, start)
 		assert.Equal(t, expectedLines-1, end)
 		currentPosition := 0
-		for i := range 30 {
+		for i := 27; i < 30; i++ {
 			rItem, ok := l.renderedItems[items[i].ID()]
 			require.True(t, ok)
 			assert.Equal(t, currentPosition, rItem.start)
@@ -253,6 +251,24 @@ func TestList(t *testing.T) {
 	})
 }
 
+func TestListSelectItems(t *testing.T) {
+	t.Parallel()
+	items := []Item{}
+	for i := range 5 {
+		items = append(items, NewSelectableItem(fmt.Sprintf("Item %d\nLine", i)))
+	}
+	l := New(items, WithDirectionBackward(), WithSize(10, 20)).(*list[Item])
+	execCmd(l, l.Init())
+
+	l.SelectItems(items[3].ID(), items[1].ID())
+	require.True(t, l.HasSelection())
+	require.Equal(t, "Item 1\nLine\nItem 2\nLine\nItem 3\nLine", l.GetSelectedText(1))
+
+	l.SelectionClear()
+	l.SelectItems(items[0].ID(), "missing")
+	require.False(t, l.HasSelection())
+}
+
 func TestListMovement(t *testing.T) {
 	t.Parallel()
 	t.Run("should move viewport up", func(t *testing.T) {
@@ -290,6 +306,33 @@ func TestListMovement(t *testing.T) {
 		assert.Equal(t, 0, l.offset)
 		golden.RequireEqual(t, []byte(l.View()))
 	})
+	t.Run("should render the items above when moving viewport up in backwards list", func(t *testing.T) {
+		t.Parallel()
+		items := []Item{}
+		for i := range 30 {
+			content := strings.Repeat(fmt.Sprintf("Item %d\n", i), i+1)
+			content = strings.TrimSuffix(content, "\n")
+			item := NewSelectableItem(content)
+			items = append(items, item)
+		}
+		l := New(items, WithDirectionBackward(), WithSize(10, 10)).(*list[Item])
+		execCmd(l, l.Init())
+		require.Equal(t, 3, len(l.renderedItems))
+
+		execCmd(l, l.MoveUp(80))
+
+		assert.Equal(t, 80, l.offset)
+		assert.Greater(t, len(l.renderedItems), 3)
+		assert.Contains(t, l.View(), "Item 26")
+
+		for range 50 {
+			execCmd(l, l.MoveUp(10))
+		}
+
+		require.Equal(t, 30, len(l.renderedItems))
+		assert.Equal(t, 465, lipgloss.Height(l.rendered))
+		assert.Contains(t, l.View(), "Item 0")
+	})
 
 	t.Run("should move viewport down", func(t *testing.T) {
 		t.Parallel()
@@ -0,0 +1,110 @@
+// Package cache keeps syntax highlighted code around, shared by the chat,
+// the diff view and the file views, so redraws don't highlight the same code
+// again. Code is mostly highlighted before it is wrapped or truncated, so the
+// width it is shown at is only part of the key of code highlighted along with
+// the text around it, like the code blocks of chat messages.
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"image/color"
+	"sync"
+
+	"github.com/zeebo/xxh3"
+)
+
+// maxBytes bounds the size of the highlighted code kept around. The least
+// recently used entries are dropped first.
+const maxBytes = 16 << 20
+
+// Key identifies highlighted code.
+type Key struct {
+	source     xxh3.Uint128
+	language   string
+	style      string
+	formatter  string
+	background string
+	width      int
+}
+
+// NewKey returns the key of source highlighted as language, with the given
+// style and background, by the formatter.
+func NewKey(source, language, style, formatter string, bg color.Color) Key {
+	key := Key{
+		source:    xxh3.HashString128(source),
+		language:  language,
+		style:     style,
+		formatter: formatter,
+	}
+	if bg != nil {
+		r, g, b, a := bg.RGBA()
+		key.background = fmt.Sprintf("%d,%d,%d,%d", r, g, b, a)
+	}
+	return key
+}
+
+// Width returns the key of the same code laid out at width.
+func (k Key) Width(width int) Key {
+	k.width = width
+	return k
+}
+
+type entry struct {
+	key         Key
+	highlighted string
+}
+
+var (
+	mu      sync.Mutex
+	entries = list.New()
+	index   = make(map[Key]*list.Element)
+	size    int
+)
+
+// Get returns the highlighted code for key, if it is cached.
+func Get(key Key) (string, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	el, ok := index[key]
+	if !ok {
+		return "", false
+	}
+	entries.MoveToFront(el)
+	return el.Value.(*entry).highlighted, true
+}
+
+// Set caches the highlighted code for key.
+func Set(key Key, highlighted string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if el, ok := index[key]; ok {
+		size += len(highlighted) - len(el.Value.(*entry).highlighted)
+		el.Value.(*entry).highlighted = highlighted
+		entries.MoveToFront(el)
+	} else {
+		index[key] = entries.PushFront(&entry{key: key, highlighted: highlighted})
+		size += len(highlighted)
+	}
+	for size > maxBytes && entries.Len() > 1 {
+		el := entries.Back()
+		e := el.Value.(*entry)
+		entries.Remove(el)
+		delete(index, e.key)
+		size -= len(e.highlighted)
+	}
+}
+
+// Highlight returns the cached highlighted code for key, calling highlight
+// and caching its result when there is none. Errors aren't cached.
+func Highlight(key Key, highlight func() (string, error)) (string, error) {
+	if highlighted, ok := Get(key); ok {
+		return highlighted, nil
+	}
+	highlighted, err := highlight()
+	if err != nil {
+		return highlighted, err
+	}
+	Set(key, highlighted)
+	return highlighted, nil
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlight(t *testing.T) {
+	t.Parallel()
+
+	black := color.RGBA{A: 0xff}
+	key := NewKey("package main", "Go", "dark", "test", black)
+
+	calls := 0
+	highlight := func() (string, error) {
+		calls++
+		return "highlighted", nil
+	}
+	for range 3 {
+		got, err := Highlight(key, highlight)
+		require.NoError(t, err)
+		require.Equal(t, "highlighted", got)
+	}
+	require.Equal(t, 1, calls)
+
+	for _, other := range []Key{
+		NewKey("package other", "Go", "dark", "test", black),
+		NewKey("package main", "Plaintext", "dark", "test", black),
+		NewKey("package main", "Go", "light", "test", black),
+		NewKey("package main", "Go", "dark", "other", black),
+		NewKey("package main", "Go", "dark", "test", color.RGBA{R: 0xff, A: 0xff}),
+		key.Width(80),
+	} {
+		require.NotEqual(t, key, other)
+		_, ok := Get(other)
+		require.False(t, ok)
+	}
+}
+
+func TestHighlightError(t *testing.T) {
+	t.Parallel()
+
+	key := NewKey("broken", "Go", "dark", "test-error", nil)
+	_, err := Highlight(key, func() (string, error) {
+		return "", errors.New("failed")
+	})
+	require.Error(t, err)
+
+	_, ok := Get(key)
+	require.False(t, ok, "errors aren't cached")
+}
+
+// TestSetEvicts doesn't run in parallel, as it empties the cache the other
+// tests use.
+func TestSetEvicts(t *testing.T) {
+	first := NewKey("first", "evict", "", "", nil)
+	Set(first, string(make([]byte, maxBytes/2)))
+	second := NewKey("second", "evict", "", "", nil)
+	Set(second, string(make([]byte, maxBytes/2+1)))
+
+	_, ok := Get(first)
+	require.False(t, ok, "the least recently used entry is dropped")
+	_, ok = Get(second)
+	require.True(t, ok)
+}
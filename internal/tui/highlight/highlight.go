@@ -8,10 +8,20 @@ import (
 	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/alecthomas/chroma/v2/lexers"
 	chromaStyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/crush/internal/tui/highlight/cache"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 )
 
+// SyntaxHighlight highlights source as the language of fileName, guessing it
+// from the source when the name doesn't tell. Results are cached.
 func SyntaxHighlight(source, fileName string, bg color.Color) (string, error) {
+	key := cache.NewKey(source, fileName, styles.CurrentTheme().Name, "terminal16m", bg)
+	return cache.Highlight(key, func() (string, error) {
+		return syntaxHighlight(source, fileName, bg)
+	})
+}
+
+func syntaxHighlight(source, fileName string, bg color.Color) (string, error) {
 	// Determine the language lexer to use
 	l := lexers.Match(fileName)
 	if l == nil {
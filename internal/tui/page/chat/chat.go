@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/help"
@@ -11,10 +13,13 @@ import (
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/filewatch"
 	"github.com/charmbracelet/crush/internal/history"
 	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/notify"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/session"
@@ -30,16 +35,24 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/claude"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/codeblocks"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/contextinspect"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/copilot"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/diagnostics"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filechanges"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/hyper"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/messageactions"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/models"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/reasoning"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/symbols"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/toolinspect"
 	"github.com/charmbracelet/crush/internal/tui/page"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
 	"github.com/charmbracelet/crush/internal/version"
+	"github.com/charmbracelet/x/ansi"
 )
 
 var ChatPageID page.PageID = "chat"
@@ -49,6 +62,13 @@ type (
 		Focused bool
 	}
 	CancelTimerExpiredMsg struct{}
+
+	// diagnosticsCheckedMsg carries the errors found in the session's files
+	// once the agent finishes a turn.
+	diagnosticsCheckedMsg struct {
+		sessionID string
+		items     []diagnostics.Item
+	}
 )
 
 type PanelType string
@@ -57,6 +77,9 @@ const (
 	PanelTypeChat   PanelType = "chat"
 	PanelTypeEditor PanelType = "editor"
 	PanelTypeSplash PanelType = "splash"
+	// PanelTypeSidebar is the sidebar, focusable when it's shown next to
+	// the chat.
+	PanelTypeSidebar PanelType = "sidebar"
 )
 
 // PillSection represents which pill section is focused when in pills panel.
@@ -134,6 +157,14 @@ type chatPage struct {
 
 	// Todo spinner
 	todoSpinner spinner.Model
+
+	// lastDiagnostics identifies the errors last offered to be sent back to
+	// the agent, so the same errors aren't offered after every turn.
+	lastDiagnostics string
+
+	// changedFiles holds the files changed outside Crush that weren't
+	// offered yet because the agent was busy.
+	changedFiles []string
 }
 
 func New(app *app.App) ChatPage {
@@ -218,13 +249,9 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			msg.Y -= 1
 		}
 		if p.isMouseOverChat(msg.X, msg.Y) {
-			p.focusedPane = PanelTypeChat
-			p.chat.Focus()
-			p.editor.Blur()
+			p.focus(PanelTypeChat)
 		} else {
-			p.focusedPane = PanelTypeEditor
-			p.editor.Focus()
-			p.chat.Blur()
+			p.focus(PanelTypeEditor)
 		}
 		u, cmd := p.chat.Update(msg)
 		p.chat = u.(chat.MessageListCmp)
@@ -267,8 +294,14 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		u, cmd := p.editor.Update(msg)
 		p.editor = u.(editor.Editor)
 		return p, cmd
+	case chat.QuoteMsg:
+		return p, p.quote(msg)
+	case codeblocks.QuoteMsg:
+		return p, p.quote(chat.QuoteMsg{Text: msg.Block.Code, Lang: msg.Block.Lang, Code: true})
+	case messageactions.EditMessageMsg:
+		return p, p.editMessage(msg.Message)
 	case chat.SendMsg:
-		return p, p.sendMessage(msg.Text, msg.Attachments)
+		return p, p.sendMessage(msg.Text, msg.Attachments, msg.Dropped)
 	case chat.SessionSelectedMsg:
 		return p, p.setSession(msg)
 	case splash.SubmitAPIKeyMsg:
@@ -293,7 +326,11 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		return p, p.openReasoningDialog()
 	case reasoning.ReasoningEffortSelectedMsg:
 		return p, p.handleReasoningEffortSelected(msg.Effort)
-	case commands.OpenExternalEditorMsg:
+	case commands.FindSymbolMsg:
+		return p, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: symbols.NewLocationsDialogCmp(p.app.LSPClients, p.selectedSymbol(), msg.References),
+		})
+	case commands.OpenExternalEditorMsg, commands.OpenContextInspectMsg, contextinspect.DropSectionsMsg:
 		u, cmd := p.editor.Update(msg)
 		p.editor = u.(editor.Editor)
 		return p, cmd
@@ -331,7 +368,9 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		return p, tea.Batch(cmds...)
 	case filepicker.FilePickedMsg,
 		completions.CompletionsClosedMsg,
-		completions.SelectCompletionMsg:
+		completions.SelectCompletionMsg,
+		commands.FetchURLMsg,
+		editor.OwnMsg:
 		u, cmd := p.editor.Update(msg)
 		p.editor = u.(editor.Editor)
 		cmds = append(cmds, cmd)
@@ -372,6 +411,13 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		if _, ok := msg.(pubsub.Event[message.Message]); ok && p.hasInProgressTodo() && agentBusy {
 			cmds = append(cmds, p.todoSpinner.Tick)
 		}
+		if event, ok := msg.(pubsub.Event[message.Message]); ok && p.isTurnEnd(event) {
+			cmds = append(cmds, p.checkDiagnostics(), p.showFileChanges(), util.CmdHandler(util.NotifyMsg{
+				Event: notify.EventTurnFinished,
+				Title: "Crush finished",
+				Body:  p.session.Title,
+			}))
+		}
 		if p.focusedPane == PanelTypeSplash {
 			u, cmd := p.splash.Update(msg)
 			p.splash = u.(splash.Splash)
@@ -383,6 +429,10 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 		}
 
 		return p, tea.Batch(cmds...)
+	case diagnosticsCheckedMsg:
+		return p, p.offerDiagnostics(msg)
+	case pubsub.Event[filewatch.Event]:
+		return p, p.offerFileChanges(msg.Payload.Paths)
 	case commands.ToggleYoloModeMsg:
 		// update the editor style
 		u, cmd := p.editor.Update(msg)
@@ -404,12 +454,16 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			return p, util.ReportWarn("Agent is busy, please wait before executing a command...")
 		}
 
-		cmd := p.sendMessage(msg.Content, nil)
+		cmd := p.sendMessage(msg.Content, nil, nil)
 		if cmd != nil {
 			return p, cmd
 		}
+	case toolinspect.RerunToolMsg:
+		return p, util.CmdHandler(commands.CommandRunCustomMsg{Content: toolinspect.RerunPrompt(msg.Call)})
 	case splash.OnboardingCompleteMsg:
 		p.splashFullScreen = false
+		// The setup wizard may have completed onboarding for the splash.
+		p.splash.SetOnboarding(false)
 		if b, _ := config.ProjectNeedsInitialization(); b {
 			p.splash.SetProjectInit(true)
 			p.splashFullScreen = true
@@ -428,7 +482,29 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			return p, util.ReportWarn("Agent is busy, please wait before starting a new session...")
 		}
 		return p, p.newSession()
+	case commands.StartSessionMsg:
+		if p.app.AgentCoordinator == nil {
+			return p, util.ReportError(fmt.Errorf("coder agent is not initialized"))
+		}
+		if p.app.AgentCoordinator.IsBusy() {
+			return p, util.ReportWarn("Agent is busy, please wait before starting a new session...")
+		}
+		// newSession clears the current session, so the message goes to a
+		// new one.
+		cleared := p.newSession()
+		return p, tea.Sequence(cleared, p.sendMessage(msg.Content, msg.Attachments, nil))
+	case chat.InsertModeMsg:
+		if p.focusedPane == PanelTypeChat {
+			return p, p.changeFocus()
+		}
+		return p, nil
 	case tea.KeyPressMsg:
+		if p.focusedPane == PanelTypeChat && p.chat.Searching() {
+			// The search being typed gets every key.
+			u, cmd := p.chat.Update(msg)
+			p.chat = u.(chat.MessageListCmp)
+			return p, cmd
+		}
 		switch {
 		case key.Matches(msg, p.keyMap.NewSession):
 			// if we have no agent do nothing
@@ -462,11 +538,26 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 				p.splash = u.(splash.Splash)
 				return p, cmd
 			}
-			return p, p.changeFocus()
+			return p, p.cycleFocus(1)
+		case key.Matches(msg, p.keyMap.FocusPrevious) && p.session.ID != "":
+			return p, p.cycleFocus(-1)
 		case key.Matches(msg, p.keyMap.Cancel):
+			// Leaving the insert mode of vim in the editor comes first.
+			if p.focusedPane == PanelTypeEditor && p.editor.HandlesEscape() {
+				u, cmd := p.editor.Update(msg)
+				p.editor = u.(editor.Editor)
+				return p, cmd
+			}
 			if p.session.ID != "" && p.app.AgentCoordinator.IsBusy() {
 				return p, p.cancel()
 			}
+			// In vim mode, esc leaves the editor for the normal mode, where
+			// the chat is navigated.
+			if p.session.ID != "" && p.focusedPane == PanelTypeEditor && config.Get().Options.TUI.VimMode {
+				u, cmd := p.editor.Update(msg)
+				p.editor = u.(editor.Editor)
+				return p, tea.Batch(cmd, p.changeFocus())
+			}
 		case key.Matches(msg, p.keyMap.Details):
 			p.toggleDetails()
 			return p, nil
@@ -493,6 +584,10 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			u, cmd := p.editor.Update(msg)
 			p.editor = u.(editor.Editor)
 			cmds = append(cmds, cmd)
+		case PanelTypeSidebar:
+			u, cmd := p.sidebar.Update(msg)
+			p.sidebar = u.(sidebar.Sidebar)
+			cmds = append(cmds, cmd)
 		case PanelTypeSplash:
 			u, cmd := p.splash.Update(msg)
 			p.splash = u.(splash.Splash)
@@ -553,8 +648,8 @@ func (p *chatPage) View() string {
 			)
 		}
 	} else {
-		messagesView := p.chat.View()
-		editorView := p.editor.View()
+		messagesView := focusGutter(p.chat.View(), p.focusedPane == PanelTypeChat)
+		editorView := focusGutter(p.editor.View(), p.focusedPane == PanelTypeEditor)
 
 		hasIncompleteTodos := hasIncompleteTodos(p.session.Todos)
 		hasQueue := p.promptQueue > 0
@@ -629,7 +724,7 @@ func (p *chatPage) View() string {
 			views = append(views, editorView)
 			chatView = lipgloss.JoinVertical(lipgloss.Left, views...)
 		} else {
-			sidebarView := p.sidebar.View()
+			sidebarView := focusGutter(p.sidebar.View(), p.focusedPane == PanelTypeSidebar)
 			var messagesColumn string
 			if pillsArea != "" {
 				messagesColumn = lipgloss.JoinVertical(
@@ -648,7 +743,7 @@ func (p *chatPage) View() string {
 			chatView = lipgloss.JoinVertical(
 				lipgloss.Left,
 				messages,
-				p.editor.View(),
+				editorView,
 			)
 		}
 	}
@@ -676,6 +771,20 @@ func (p *chatPage) View() string {
 	return canvas.Render()
 }
 
+// focusGutter marks the focused pane with a bar in the padding on its left.
+func focusGutter(view string, focused bool) string {
+	if !focused {
+		return view
+	}
+	t := styles.CurrentTheme()
+	bar := t.S().Base.Foreground(t.BorderFocus).Render("│")
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		lines[i] = bar + ansi.Cut(line, 1, lipgloss.Width(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (p *chatPage) updateCompactConfig(compact bool) tea.Cmd {
 	return func() tea.Msg {
 		err := config.Get().SetCompactMode(compact)
@@ -772,6 +881,10 @@ func (p *chatPage) setCompactMode(compact bool) {
 	p.compact = compact
 	if compact {
 		p.sidebar.SetCompactMode(true)
+		// The sidebar is only shown in the details then.
+		if p.focusedPane == PanelTypeSidebar {
+			p.focus(PanelTypeEditor)
+		}
 	} else {
 		p.setShowDetails(false)
 	}
@@ -842,9 +955,7 @@ func (p *chatPage) newSession() tea.Cmd {
 	}
 
 	p.session = session.Session{}
-	p.focusedPane = PanelTypeEditor
-	p.editor.Focus()
-	p.chat.Blur()
+	p.focus(PanelTypeEditor)
 	p.isCanceling = false
 	return tea.Batch(
 		util.CmdHandler(chat.SessionClearedMsg{}),
@@ -873,6 +984,36 @@ func (p *chatPage) setSession(sess session.Session) tea.Cmd {
 	return tea.Sequence(cmds...)
 }
 
+// quote quotes text in the prompt, focusing it to ask about the text.
+func (p *chatPage) quote(msg chat.QuoteMsg) tea.Cmd {
+	var cmd tea.Cmd
+	if p.focusedPane == PanelTypeChat {
+		cmd = p.changeFocus()
+	}
+	u, editorCmd := p.editor.Update(msg)
+	p.editor = u.(editor.Editor)
+	return tea.Batch(cmd, editorCmd)
+}
+
+// editMessage removes the prompt that led to msg, and what followed, putting
+// it back in the editor to be sent again.
+func (p *chatPage) editMessage(msg message.Message) tea.Cmd {
+	if p.app.AgentCoordinator != nil && p.app.AgentCoordinator.IsSessionBusy(p.session.ID) {
+		return util.ReportWarn("Agent is busy, please wait before editing a prompt...")
+	}
+	prompt, err := p.app.RewindSession(context.Background(), p.session.ID, msg.ID)
+	if err != nil {
+		return util.ReportError(err)
+	}
+	var cmd tea.Cmd
+	if p.focusedPane == PanelTypeChat {
+		cmd = p.changeFocus()
+	}
+	u, editorCmd := p.editor.Update(editor.OpenEditorMsg{Text: prompt.Content().Text})
+	p.editor = u.(editor.Editor)
+	return tea.Batch(cmd, editorCmd)
+}
+
 func (p *chatPage) changeFocus() tea.Cmd {
 	if p.session.ID == "" {
 		return nil
@@ -880,15 +1021,43 @@ func (p *chatPage) changeFocus() tea.Cmd {
 
 	switch p.focusedPane {
 	case PanelTypeEditor:
-		p.focusedPane = PanelTypeChat
-		p.chat.Focus()
-		p.editor.Blur()
+		p.focus(PanelTypeChat)
+	case PanelTypeChat, PanelTypeSidebar:
+		p.focus(PanelTypeEditor)
+	}
+	return nil
+}
+
+// cycleFocus moves the focus to the next pane of the session, or to the
+// previous one when delta is negative: the editor, the chat, and the
+// sidebar when it's shown.
+func (p *chatPage) cycleFocus(delta int) tea.Cmd {
+	if p.session.ID == "" {
+		return nil
+	}
+	panes := []PanelType{PanelTypeEditor, PanelTypeChat}
+	if !p.compact {
+		panes = append(panes, PanelTypeSidebar)
+	}
+	i := max(0, slices.Index(panes, p.focusedPane))
+	p.focus(panes[(i+delta+len(panes))%len(panes)])
+	return nil
+}
+
+// focus moves the focus to pane.
+func (p *chatPage) focus(pane PanelType) {
+	p.focusedPane = pane
+	p.chat.Blur()
+	p.editor.Blur()
+	p.sidebar.Blur()
+	switch pane {
 	case PanelTypeChat:
-		p.focusedPane = PanelTypeEditor
+		p.chat.Focus()
+	case PanelTypeEditor:
 		p.editor.Focus()
-		p.chat.Blur()
+	case PanelTypeSidebar:
+		p.sidebar.Focus()
 	}
-	return nil
 }
 
 func (p *chatPage) togglePillsExpanded() tea.Cmd {
@@ -957,7 +1126,7 @@ func (p *chatPage) toggleDetails() {
 	p.setShowDetails(!p.showingDetails)
 }
 
-func (p *chatPage) sendMessage(text string, attachments []message.Attachment) tea.Cmd {
+func (p *chatPage) sendMessage(text string, attachments []message.Attachment, dropped []string) tea.Cmd {
 	session := p.session
 	var cmds []tea.Cmd
 	if p.session.ID == "" {
@@ -973,7 +1142,8 @@ func (p *chatPage) sendMessage(text string, attachments []message.Attachment) te
 	}
 	cmds = append(cmds, p.chat.GoToBottom())
 	cmds = append(cmds, func() tea.Msg {
-		_, err := p.app.AgentCoordinator.Run(context.Background(), session.ID, text, attachments...)
+		ctx := agent.WithDroppedSections(context.Background(), dropped)
+		_, err := p.app.AgentCoordinator.Run(ctx, session.ID, text, attachments...)
 		if err != nil {
 			isCancelErr := errors.Is(err, context.Canceled)
 			isPermissionErr := errors.Is(err, permission.ErrorPermissionDenied)
@@ -990,6 +1160,113 @@ func (p *chatPage) sendMessage(text string, attachments []message.Attachment) te
 	return tea.Batch(cmds...)
 }
 
+// selectedSymbol returns the text selected in the chat when it looks like a
+// single symbol, so it can be looked up right away.
+func (p *chatPage) selectedSymbol() string {
+	if p.session.ID == "" {
+		return ""
+	}
+	text := strings.Trim(strings.TrimSpace(p.chat.GetSelectedText()), "`'\"()")
+	if text == "" || strings.ContainsAny(text, " \t\n") {
+		return ""
+	}
+	return text
+}
+
+// isTurnEnd reports whether the event is the agent finishing a turn in the
+// current session.
+func (p *chatPage) isTurnEnd(event pubsub.Event[message.Message]) bool {
+	msg := event.Payload
+	if event.Type != pubsub.UpdatedEvent || msg.SessionID != p.session.ID || msg.Role != message.Assistant {
+		return false
+	}
+	finish := msg.FinishPart()
+	return finish != nil && finish.Reason == message.FinishReasonEndTurn
+}
+
+// checkDiagnostics collects the LSP errors in the files edited during the
+// session, giving the servers a moment to catch up with the last changes.
+func (p *chatPage) checkDiagnostics() tea.Cmd {
+	cfg := config.Get()
+	if cfg.Options.TUI.DisableDiagnosticsFeedback || p.app.LSPClients.Len() == 0 {
+		return nil
+	}
+	sessionID := p.session.ID
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		files, err := p.app.History.ListLatestSessionFiles(ctx, sessionID)
+		if err != nil || len(files) == 0 {
+			return nil
+		}
+		paths := make([]string, 0, len(files))
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		for client := range p.app.LSPClients.Seq() {
+			client.WaitForDiagnostics(ctx, 2*time.Second)
+		}
+		return diagnosticsCheckedMsg{
+			sessionID: sessionID,
+			items:     diagnostics.Errors(diagnostics.Collect(p.app.LSPClients, paths)),
+		}
+	}
+}
+
+// offerDiagnostics opens the diagnostics dialog when the agent left errors
+// behind that haven't been offered yet.
+func (p *chatPage) offerDiagnostics(msg diagnosticsCheckedMsg) tea.Cmd {
+	if msg.sessionID != p.session.ID {
+		return nil
+	}
+	var sb strings.Builder
+	for _, item := range msg.items {
+		fmt.Fprintf(&sb, "%s:%s\n", item.Location(), item.Message)
+	}
+	key := msg.sessionID + "\n" + sb.String()
+	if len(msg.items) == 0 || key == p.lastDiagnostics {
+		p.lastDiagnostics = key
+		return nil
+	}
+	p.lastDiagnostics = key
+	if p.app.AgentCoordinator != nil && p.app.AgentCoordinator.IsSessionBusy(p.session.ID) {
+		return nil
+	}
+	return util.CmdHandler(dialogs.OpenDialogMsg{
+		Model: diagnostics.NewDiagnosticsDialogCmp(msg.items, true),
+	})
+}
+
+// offerFileChanges asks what to do about files changed outside Crush. While
+// the agent is working the files are put aside and offered when it finishes.
+func (p *chatPage) offerFileChanges(paths []string) tea.Cmd {
+	if p.session.ID == "" {
+		return nil
+	}
+	for _, path := range paths {
+		if !slices.Contains(p.changedFiles, path) {
+			p.changedFiles = append(p.changedFiles, path)
+		}
+	}
+	if p.app.AgentCoordinator != nil && p.app.AgentCoordinator.IsSessionBusy(p.session.ID) {
+		return nil
+	}
+	return p.showFileChanges()
+}
+
+// showFileChanges opens the dialog for the files put aside, if any.
+func (p *chatPage) showFileChanges() tea.Cmd {
+	if len(p.changedFiles) == 0 {
+		return nil
+	}
+	changed := p.changedFiles
+	p.changedFiles = nil
+	return util.CmdHandler(dialogs.OpenDialogMsg{
+		Model: filechanges.NewFileChangesDialogCmp(changed),
+	})
+}
+
 func (p *chatPage) Bindings() []key.Binding {
 	bindings := []key.Binding{
 		p.keyMap.NewSession,
@@ -1221,13 +1498,18 @@ func (p *chatPage) Help() help.KeyMap {
 		// we are in a session
 		if p.session.ID != "" {
 			var tabKey key.Binding
-			switch p.focusedPane {
-			case PanelTypeEditor:
+			switch {
+			case p.focusedPane == PanelTypeEditor:
 				tabKey = key.NewBinding(
 					key.WithKeys("tab"),
 					key.WithHelp("tab", "focus chat"),
 				)
-			case PanelTypeChat:
+			case p.focusedPane == PanelTypeChat && !p.compact:
+				tabKey = key.NewBinding(
+					key.WithKeys("tab"),
+					key.WithHelp("tab", "focus sidebar"),
+				)
+			case p.focusedPane == PanelTypeChat, p.focusedPane == PanelTypeSidebar:
 				tabKey = key.NewBinding(
 					key.WithKeys("tab"),
 					key.WithHelp("tab", "focus editor"),
@@ -1239,7 +1521,7 @@ func (p *chatPage) Help() help.KeyMap {
 				)
 			}
 			shortList = append(shortList, tabKey)
-			globalBindings = append(globalBindings, tabKey)
+			globalBindings = append(globalBindings, tabKey, p.keyMap.FocusPrevious)
 
 			// Show left/right to switch sections when expanded and both exist
 			hasTodos := hasIncompleteTodos(p.session.Todos)
@@ -1290,6 +1572,9 @@ func (p *chatPage) Help() help.KeyMap {
 		fullList = append(fullList, globalBindings)
 
 		switch p.focusedPane {
+		case PanelTypeSidebar:
+			shortList = append(shortList, sidebar.PreviousFileKey, sidebar.OpenFileKey)
+			fullList = append(fullList, []key.Binding{sidebar.PreviousFileKey, sidebar.OpenFileKey})
 		case PanelTypeChat:
 			shortList = append(shortList,
 				key.NewBinding(
@@ -1337,9 +1622,38 @@ func (p *chatPage) Help() help.KeyMap {
 				},
 				[]key.Binding{
 					messages.CopyKey,
+					messages.CodeBlocksKey,
+					messages.ToggleToolKey,
+					messages.InspectToolKey,
 					messages.ClearSelectionKey,
 				},
+				[]key.Binding{
+					chat.ActionsKey,
+					chat.QuoteKey,
+					chat.PinKey,
+					chat.NextPinKey,
+				},
 			)
+			if config.Get().Options.TUI.VimMode {
+				fullList = append(fullList, []key.Binding{
+					key.NewBinding(
+						key.WithKeys("j", "k"),
+						key.WithHelp("j/k", "next/prev message"),
+					),
+					key.NewBinding(
+						key.WithKeys("g"),
+						key.WithHelp("gg", "top"),
+					),
+					chat.InsertKey,
+					chat.VisualKey,
+					chat.SearchKey,
+					chat.NextMatchKey,
+					key.NewBinding(
+						key.WithKeys("I"),
+						key.WithHelp("I", "inspect tool"),
+					),
+				})
+			}
 		case PanelTypeEditor:
 			newLineBinding := key.NewBinding(
 				key.WithKeys("shift+enter", "ctrl+j"),
@@ -1365,8 +1679,34 @@ func (p *chatPage) Help() help.KeyMap {
 						key.WithHelp("@", "mention file"),
 					),
 					key.NewBinding(
-						key.WithKeys("ctrl+o"),
-						key.WithHelp("ctrl+o", "open editor"),
+						key.WithKeys("#"),
+						key.WithHelp("#", "mention symbol"),
+					),
+					key.NewBinding(
+						key.WithKeys("ctrl+e", "ctrl+o"),
+						key.WithHelp("ctrl+e", "open editor"),
+					),
+				},
+				[]key.Binding{
+					key.NewBinding(
+						key.WithKeys("ctrl+_", "ctrl+/"),
+						key.WithHelp("ctrl+/", "undo"),
+					),
+					key.NewBinding(
+						key.WithKeys("alt+_", "alt+/"),
+						key.WithHelp("alt+/", "redo"),
+					),
+					key.NewBinding(
+						key.WithKeys("ctrl+y"),
+						key.WithHelp("ctrl+y", "paste deleted text"),
+					),
+					key.NewBinding(
+						key.WithKeys("alt+y"),
+						key.WithHelp("alt+y", "paste older deleted text"),
+					),
+					key.NewBinding(
+						key.WithKeys("alt+s"),
+						key.WithHelp("alt+s", "correct spelling"),
 					),
 				})
 
@@ -1384,6 +1724,14 @@ func (p *chatPage) Help() help.KeyMap {
 						key.WithKeys("esc", "alt+esc"),
 						key.WithHelp("esc", "cancel delete mode"),
 					),
+					key.NewBinding(
+						key.WithKeys("alt+p"),
+						key.WithHelp("alt+p", "expand paste"),
+					),
+					key.NewBinding(
+						key.WithKeys("alt+e"),
+						key.WithHelp("alt+e", "edit paste"),
+					),
 				})
 			}
 		}
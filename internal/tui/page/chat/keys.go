@@ -9,6 +9,7 @@ type KeyMap struct {
 	AddAttachment key.Binding
 	Cancel        key.Binding
 	Tab           key.Binding
+	FocusPrevious key.Binding
 	Details       key.Binding
 	TogglePills   key.Binding
 	PillLeft      key.Binding
@@ -33,6 +34,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("tab"),
 			key.WithHelp("tab", "change focus"),
 		),
+		FocusPrevious: key.NewBinding(
+			key.WithKeys("shift+tab"),
+			key.WithHelp("shift+tab", "focus previous"),
+		),
 		Details: key.NewBinding(
 			key.WithKeys("ctrl+d"),
 			key.WithHelp("ctrl+d", "toggle details"),
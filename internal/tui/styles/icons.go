@@ -13,6 +13,8 @@ const (
 	ImageIcon         string = "■"
 	TextIcon          string = "☰"
 	ModelIcon         string = "◇"
+	PinIcon           string = "⚑"
+	UnreadIcon        string = "●"
 
 	// Tool call icons
 	ToolPending string = "●"
@@ -3,6 +3,7 @@ package styles
 import (
 	"fmt"
 	"image/color"
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/filepicker"
@@ -12,6 +13,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/glamour/v2/ansi"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/termcap"
 	"github.com/charmbracelet/crush/internal/tui/exp/diffview"
 	"github.com/charmbracelet/x/exp/charmtone"
 	"github.com/lucasb-eyer/go-colorful"
@@ -617,6 +619,11 @@ func ForegroundGrad(input string, bold bool, color1, color2 color.Color) []strin
 	}
 
 	ramp := blendColors(len(clusters), color1, color2)
+	if !termcap.Get().Colors256() {
+		// With 16 colors the steps of the gradient jump between unrelated
+		// colors, it reads better flat.
+		ramp = slices.Repeat([]color.Color{color1}, len(clusters))
+	}
 	for i, c := range ramp {
 		style := t.S().Base.Foreground(c)
 		if bold {
@@ -3,22 +3,38 @@ package tui
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/a11y"
+	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/event"
+	"github.com/charmbracelet/crush/internal/gh"
+	"github.com/charmbracelet/crush/internal/notify"
 	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/plan"
 	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/redact"
+	"github.com/charmbracelet/crush/internal/requestlog"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/sessionshare"
+	"github.com/charmbracelet/crush/internal/shell"
 	"github.com/charmbracelet/crush/internal/stringext"
+	"github.com/charmbracelet/crush/internal/termcap"
+	"github.com/charmbracelet/crush/internal/tui/components/anim"
 	cmpChat "github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/chat/splash"
 	"github.com/charmbracelet/crush/internal/tui/components/completions"
@@ -26,16 +42,49 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
 	"github.com/charmbracelet/crush/internal/tui/components/core/status"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/changelog"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/cistatus"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/codesearch"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/configerrors"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/configsources"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/credentials"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/dashboard"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/diagnostics"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/doctor"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/findreplace"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/ghlogin"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/gitcommit"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/ignorerules"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/issues"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/jobs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/logs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/lsps"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/mcps"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/messageactions"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/models"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/ollama"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/onboarding"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/permissions"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/plans"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/providers"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/pullrequest"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/quit"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/recovery"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/repos"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/requests"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/reviews"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/schedules"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/sessions"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/sharedsessions"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/symbols"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/tasks"
 	"github.com/charmbracelet/crush/internal/tui/page"
 	"github.com/charmbracelet/crush/internal/tui/page/chat"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
 	"golang.org/x/mod/semver"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -56,6 +105,19 @@ func MouseEventFilter(m tea.Model, msg tea.Msg) tea.Msg {
 	return msg
 }
 
+// ReducedMotionFPS is how often the screen is redrawn at most with reduced
+// motion.
+const ReducedMotionFPS = 10
+
+// ReducedMotionFilter drops the ticks of spinners, which keeps them still,
+// and filters mouse events like MouseEventFilter.
+func ReducedMotionFilter(m tea.Model, msg tea.Msg) tea.Msg {
+	if _, ok := msg.(spinner.TickMsg); ok {
+		return nil
+	}
+	return MouseEventFilter(m, msg)
+}
+
 // appModel represents the main application model that manages pages, dialogs, and UI state.
 type appModel struct {
 	wWidth, wHeight int // Window dimensions
@@ -79,6 +141,7 @@ type appModel struct {
 
 	// Chat Page Specific
 	selectedSessionID string // The ID of the currently selected session
+	sessionTitle      string // The title of the currently selected session
 
 	// sendProgressBar instructs the TUI to send progress bar updates to the
 	// terminal.
@@ -87,6 +150,14 @@ type appModel struct {
 	// QueryVersion instructs the TUI to query for the terminal version when it
 	// starts.
 	QueryVersion bool
+
+	// unfocused is set while the terminal reports it lost focus, which is
+	// when desktop notifications are sent.
+	unfocused bool
+
+	// announcements is where state changes are announced in the screen
+	// reader mode; nil otherwise.
+	announcements *a11y.Log
 }
 
 // Init initializes the application model and returns initial commands.
@@ -103,13 +174,39 @@ func (a appModel) Init() tea.Cmd {
 
 	cmd = a.status.Init()
 	cmds = append(cmds, cmd)
+	if !config.HasInitialDataConfig() {
+		cmds = append(cmds, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: onboarding.NewOnboardingDialogCmp(),
+		}))
+	} else if interrupted := a.app.InterruptedSessions(); len(interrupted) > 0 {
+		cmds = append(cmds, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: recovery.NewRecoveryDialogCmp(interrupted),
+		}))
+	}
 	if a.QueryVersion {
 		cmds = append(cmds, tea.RequestTerminalVersion)
 	}
+	cmds = append(cmds, a.autostartJobs())
+	cmds = append(cmds, syncOutput(config.Get().Options.TUI.SynchronizedOutput))
 
 	return tea.Batch(cmds...)
 }
 
+// autostartJobs starts the jobs of the config to start when Crush opens,
+// leaving them in the background.
+func (a appModel) autostartJobs() tea.Cmd {
+	return func() tea.Msg {
+		started, err := a.app.StartAutostartJobs()
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		if len(started) == 0 {
+			return nil
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Started " + strings.Join(started, ", ") + " in the background"}
+	}
+}
+
 // Update handles incoming messages and updates the application state.
 func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
@@ -118,9 +215,10 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.EnvMsg:
-		// Is this Windows Terminal?
+		// Is this Windows Terminal, ConEmu or WezTerm?
 		if !a.sendProgressBar {
-			a.sendProgressBar = slices.Contains(msg, "WT_SESSION")
+			_, wt := msg.LookupEnv("WT_SESSION")
+			a.sendProgressBar = wt || msg.Getenv("ConEmuANSI") == "ON" || msg.Getenv("TERM_PROGRAM") == "WezTerm"
 		}
 	case tea.TerminalVersionMsg:
 		if a.sendProgressBar {
@@ -138,7 +236,23 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		return a, nil
+	case tea.ColorProfileMsg:
+		termcap.Update(func(caps *termcap.Caps) {
+			caps.Color, caps.Probed.Color = msg.Profile, true
+		})
+		return a, nil
+	case tea.ModeReportMsg:
+		if msg.Mode == ansi.ModeSynchronizedOutput {
+			termcap.Update(func(caps *termcap.Caps) {
+				caps.SyncOutput = !msg.Value.IsNotRecognized()
+				caps.Probed.SyncOutput = true
+			})
+		}
+		return a, nil
 	case tea.KeyboardEnhancementsMsg:
+		termcap.Update(func(caps *termcap.Caps) {
+			caps.KittyKeyboard, caps.Probed.KittyKeyboard = msg.Flags > 0, true
+		})
 		// A non-zero value means we have key disambiguation support.
 		if msg.Flags > 0 {
 			a.keyMap.Models.SetHelp("ctrl+m", "models")
@@ -152,6 +266,47 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		return a, tea.Batch(cmds...)
+	case tea.FocusMsg:
+		a.unfocused = false
+		return a, nil
+	case tea.BlurMsg:
+		a.unfocused = true
+		return a, nil
+	case util.NotifyMsg:
+		a.announcements.Announce(msg.Title + ": " + msg.Body)
+		return a, a.notify(msg)
+	case pubsub.Event[shell.BackgroundShellExit]:
+		what := msg.Payload.Description
+		if what == "" {
+			what = msg.Payload.Command
+		}
+		return a, util.CmdHandler(util.NotifyMsg{
+			Event: notify.EventProcessExited,
+			Title: "Process exited",
+			Body:  fmt.Sprintf("%s exited with code %d", what, msg.Payload.ExitCode),
+		})
+	case pubsub.Event[shell.BackgroundShellRunaway]:
+		what := msg.Payload.Description
+		if what == "" {
+			what = msg.Payload.Command
+		}
+		return a, tea.Batch(
+			util.ReportWarn(fmt.Sprintf("%s is writing in a loop, it's slowed down", what)),
+			util.CmdHandler(dialogs.OpenDialogMsg{Model: jobs.NewJobDialogCmp(msg.Payload.ID)}),
+		)
+	case pubsub.Event[agent.FailoverEvent]:
+		return a, util.CmdHandler(failoverStatus(msg.Payload))
+	case pubsub.Event[redact.Event]:
+		return a, util.CmdHandler(redactionStatus(msg.Payload))
+	case pubsub.Event[config.ReloadEvent]:
+		if errs := msg.Payload.Errors; len(errs) > 0 {
+			return a, tea.Batch(
+				util.ReportWarn("Config not reloaded, it has mistakes"),
+				util.CmdHandler(dialogs.OpenDialogMsg{Model: configerrors.NewConfigErrorsDialogCmp(errs)}),
+			)
+		}
+		setTheme(config.Get().Options.TUI.Theme)
+		return a, util.ReportInfo("Config reloaded")
 	case tea.WindowSizeMsg:
 		a.wWidth, a.wHeight = msg.Width, msg.Height
 		a.completions.Update(msg)
@@ -165,6 +320,16 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, handleMCPPromptsEvent(context.Background(), msg.Payload.Name)
 		case mcp.EventToolsListChanged:
 			return a, handleMCPToolsEvent(context.Background(), msg.Payload.Name)
+		case mcp.EventAuthRequired:
+			id := mcps.AuthDialogID(msg.Payload.Name)
+			if slices.ContainsFunc(a.dialog.Dialogs(), func(d dialogs.DialogModel) bool { return d.ID() == id }) {
+				// The requests in flight fail the same way until the user
+				// signs in.
+				return a, nil
+			}
+			return a, util.CmdHandler(dialogs.OpenDialogMsg{
+				Model: mcps.NewMCPAuthDialogCmp(msg.Payload.Name),
+			})
 		}
 
 	// Completions messages
@@ -229,6 +394,9 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Status Messages
 	case util.InfoMsg, util.ClearStatusMsg:
+		if info, ok := msg.(util.InfoMsg); ok {
+			a.announcements.Announce(info.Msg)
+		}
 		s, statusCmd := a.status.Update(msg)
 		a.status = s.(status.StatusCmp)
 		cmds = append(cmds, statusCmd)
@@ -237,8 +405,21 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Session
 	case cmpChat.SessionSelectedMsg:
 		a.selectedSessionID = msg.ID
+		a.sessionTitle = msg.Title
+		if msg.Title != "" {
+			a.announcements.Announce("Session: " + msg.Title)
+		}
 	case cmpChat.SessionClearedMsg:
 		a.selectedSessionID = ""
+		a.sessionTitle = ""
+		// The status bar no longer shows the cost of the session.
+		s, _ := a.status.Update(session.Session{})
+		a.status = s.(status.StatusCmp)
+	case pubsub.Event[session.Session]:
+		// Keep the terminal title in sync once the session gets named.
+		if msg.Payload.ID == a.selectedSessionID && msg.Type == pubsub.UpdatedEvent {
+			a.sessionTitle = msg.Payload.Title
+		}
 	// Commands
 	case commands.SwitchSessionsMsg:
 		return a, func() tea.Msg {
@@ -263,12 +444,201 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return nil
 		}
+	case commands.OpenRequestsMsg:
+		cfg := a.app.Config()
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: requests.NewRequestsDialogCmp(requestlog.New(cfg.Options.DataDirectory), msg.SessionID, cfg.Options.LogRequests),
+		})
+	case commands.OpenTasksMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: tasks.NewTasksDialogCmp(a.app.Tasks, msg.SessionID),
+		})
+	case commands.RenameSessionMsg:
+		return a, a.renameSession(msg)
+	case commands.TagSessionMsg:
+		return a, a.tagSession(msg)
+	case commands.ShareSessionMsg:
+		return a, a.shareSession(msg)
+	case commands.OpenSharedSessionsMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: sharedsessions.NewSharedSessionsDialogCmp(a.app.Config().WorkingDir()),
+		})
+	case commands.PinProviderMsg:
+		if a.app.AgentCoordinator == nil {
+			return a, util.ReportError(fmt.Errorf("coder agent is not initialized"))
+		}
+		if err := a.app.AgentCoordinator.PinProvider(msg.SessionID, msg.Provider); err != nil {
+			return a, util.ReportError(err)
+		}
+		if msg.Provider == "" {
+			return a, util.ReportInfo("Unpinned the session from its provider")
+		}
+		return a, util.ReportInfo("Pinned the session to " + msg.Provider)
 	case commands.QuitMsg:
 		return a, util.CmdHandler(dialogs.OpenDialogMsg{
 			Model: quit.NewQuitDialog(),
 		})
 	case commands.ToggleYoloModeMsg:
 		a.app.Permissions.SetSkipRequests(!a.app.Permissions.SkipRequests())
+	case commands.OpenMCPManagerMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: mcps.NewMCPManagerDialogCmp(),
+		})
+	case commands.OpenSymbolPickerMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: symbols.NewPickerDialogCmp(a.app.Config().WorkingDir()),
+		})
+	case commands.OpenFindReplaceMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: findreplace.NewFindReplaceDialogCmp(a.app.Config().WorkingDir()),
+		})
+	case commands.OpenCommitMsg:
+		if a.app.AgentCoordinator == nil {
+			return a, util.ReportError(fmt.Errorf("coder agent is not initialized"))
+		}
+		coordinator := a.app.AgentCoordinator
+		return a, repos.Pick(a.app.Config().ActiveRoot(), func(dir string) tea.Cmd {
+			return util.CmdHandler(dialogs.OpenDialogMsg{
+				Model: gitcommit.NewCommitDialogCmp(dir, func(ctx context.Context) (string, error) {
+					return coordinator.GenerateCommitMessage(ctx, dir)
+				}),
+			})
+		})
+	case commands.OpenPullRequestMsg:
+		if a.app.AgentCoordinator == nil {
+			return a, util.ReportError(fmt.Errorf("coder agent is not initialized"))
+		}
+		if !gh.Installed() {
+			return a, util.ReportError(gh.ErrNotInstalled)
+		}
+		sessionID := a.selectedSessionID
+		coordinator := a.app.AgentCoordinator
+		return a, repos.Pick(a.app.Config().ActiveRoot(), func(dir string) tea.Cmd {
+			return ghlogin.Check(dir, dialogs.OpenDialogMsg{
+				Model: pullrequest.NewPullRequestDialogCmp(dir, func(ctx context.Context) (gh.PullRequest, error) {
+					return coordinator.GeneratePullRequest(ctx, dir, sessionID)
+				}),
+			})
+		})
+	case commands.OpenReviewsMsg:
+		if !gh.Installed() {
+			return a, util.ReportError(gh.ErrNotInstalled)
+		}
+		return a, repos.Pick(a.app.Config().ActiveRoot(), func(dir string) tea.Cmd {
+			return ghlogin.Check(dir, dialogs.OpenDialogMsg{
+				Model: reviews.NewReviewsDialogCmp(dir),
+			})
+		})
+	case commands.OpenCIStatusMsg:
+		if !a.app.CI.Available() {
+			return a, util.ReportWarn("No CI found, it needs gh for GitHub or glab for GitLab")
+		}
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: cistatus.NewCIStatusDialogCmp(a.app.CI),
+		})
+	case commands.OpenIssuesMsg:
+		return a, repos.Pick(a.app.Config().ActiveRoot(), func(dir string) tea.Cmd {
+			return util.CmdHandler(dialogs.OpenDialogMsg{
+				Model: issues.NewIssuesDialogCmp(dir),
+			})
+		})
+	case commands.OpenCodeSearchMsg:
+		if a.app.SemanticIndex == nil {
+			return a, util.ReportWarn("Semantic search failed to start, check the logs")
+		}
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: codesearch.NewCodeSearchDialogCmp(a.app.SemanticIndex),
+		})
+	case commands.OpenLSPManagerMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: lsps.NewLSPManagerDialogCmp(a.app),
+		})
+	case commands.PullOllamaModelMsg:
+		if strings.TrimSpace(msg.Model) == "" {
+			return a, util.ReportWarn("Enter the name of the model to pull")
+		}
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: ollama.NewPullDialogCmp(msg.Model),
+		})
+	case commands.OpenProviderHealthMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: providers.NewProviderHealthDialogCmp(),
+		})
+	case commands.OpenCredentialsMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: credentials.NewCredentialsDialogCmp(),
+		})
+	case commands.OpenOnboardingMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: onboarding.NewOnboardingDialogCmp(),
+		})
+	case onboarding.SetupCompleteMsg:
+		// On the first run, the agent starts once onboarding is complete.
+		if a.app.AgentCoordinator == nil {
+			return a, util.CmdHandler(splash.OnboardingCompleteMsg{})
+		}
+		if a.app.AgentCoordinator.IsBusy() {
+			return a, util.ReportWarn("Agent is busy, switch models from the model picker once it's done")
+		}
+		go a.app.UpdateAgentModel(context.TODO())
+		return a, nil
+	case commands.OpenConfigSourcesMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: configsources.NewConfigSourcesDialogCmp(),
+		})
+	case commands.OpenIgnoreRulesMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: ignorerules.NewIgnoreRulesDialogCmp(),
+		})
+	case commands.OpenDoctorMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: doctor.NewDoctorDialogCmp(a.app.LSPClients),
+		})
+	case commands.OpenLogsMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: logs.NewLogsDialogCmp(),
+		})
+	case commands.OpenDashboardMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: dashboard.NewDashboardDialogCmp(a.app.Stats, a.app.Config().WorkingDir()),
+		})
+	case commands.StartJobMsg:
+		job, err := a.app.StartJob(msg.Name)
+		if err != nil {
+			return a, util.ReportError(err)
+		}
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: jobs.NewJobDialogCmp(job.ID),
+		})
+	case commands.OpenJobsMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: jobs.NewJobsDialogCmp(),
+		})
+	case commands.OpenChangelogMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: changelog.NewChangelogDialogCmp(),
+		})
+	case commands.OpenSchedulesMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: schedules.NewSchedulesDialogCmp(a.app.Config().Options.DataDirectory, a.app.Sessions),
+		})
+	case cmpChat.OpenMessageActionsMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: messageactions.NewMessageActionsDialogCmp(msg.Message),
+		})
+	case commands.OpenMCPResourcesMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: mcps.NewMCPResourcesDialogCmp(),
+		})
+	case commands.OpenDiagnosticsMsg:
+		return a, a.openDiagnostics()
+	case commands.TogglePlanModeMsg:
+		enabled := !a.app.Plans.Enabled()
+		a.app.Plans.SetEnabled(enabled)
+		if enabled {
+			return a, util.ReportInfo("Plan mode enabled")
+		}
+		return a, util.ReportInfo("Plan mode disabled")
 	case commands.ToggleHelpMsg:
 		a.status.ToggleFullHelp()
 		a.showingFullHelp = !a.showingFullHelp
@@ -316,11 +686,18 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return a, itemCmd
 	case pubsub.Event[permission.PermissionRequest]:
-		return a, util.CmdHandler(dialogs.OpenDialogMsg{
-			Model: permissions.NewPermissionDialogCmp(msg.Payload, &permissions.Options{
-				DiffMode: config.Get().Options.TUI.DiffMode,
+		return a, tea.Batch(
+			util.CmdHandler(dialogs.OpenDialogMsg{
+				Model: permissions.NewPermissionDialogCmp(msg.Payload, &permissions.Options{
+					DiffMode: config.Get().Options.TUI.DiffMode,
+				}),
 			}),
-		})
+			util.CmdHandler(util.NotifyMsg{
+				Event: notify.EventPermissionRequested,
+				Title: "Permission required",
+				Body:  fmt.Sprintf("%s: %s", msg.Payload.ToolName, msg.Payload.Description),
+			}),
+		)
 	case permissions.PermissionResponseMsg:
 		switch msg.Action {
 		case permissions.PermissionAllow:
@@ -331,6 +708,18 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.app.Permissions.Deny(msg.Permission)
 		}
 		return a, nil
+	// Plans
+	case pubsub.Event[plan.Request]:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: plans.NewPlanDialogCmp(msg.Payload),
+		})
+	case plans.PlanResponseMsg:
+		if msg.Approved {
+			a.app.Plans.Approve(msg.Request, msg.Steps)
+		} else {
+			a.app.Plans.Reject(msg.Request)
+		}
+		return a, nil
 	case splash.OnboardingCompleteMsg:
 		item, ok := a.pages[a.currentPage]
 		if !ok {
@@ -387,7 +776,7 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Update Available
 	case pubsub.UpdateAvailableMsg:
 		// Show update notification in status bar
-		statusMsg := fmt.Sprintf("Crush update available: v%s → v%s.", msg.CurrentVersion, msg.LatestVersion)
+		statusMsg := fmt.Sprintf("Crush update available: v%s → v%s. See What's New in the commands.", msg.CurrentVersion, msg.LatestVersion)
 		if msg.IsDevelopment {
 			statusMsg = fmt.Sprintf("This is a development version of Crush. The latest version is v%s.", msg.LatestVersion)
 		}
@@ -399,12 +788,13 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.status = s.(status.StatusCmp)
 		return a, statusCmd
 	}
-	s, _ := a.status.Update(msg)
+	s, statusCmd := a.status.Update(msg)
 	a.status = s.(status.StatusCmp)
+	cmds = append(cmds, statusCmd)
 
 	item, ok := a.pages[a.currentPage]
 	if !ok {
-		return a, nil
+		return a, tea.Batch(cmds...)
 	}
 
 	updated, cmd := item.Update(msg)
@@ -562,6 +952,25 @@ func (a *appModel) handleKeyPressMsg(msg tea.KeyPressMsg) tea.Cmd {
 	}
 }
 
+// openDiagnostics opens the diagnostics panel for the files edited in the
+// current session.
+func (a *appModel) openDiagnostics() tea.Cmd {
+	sessionID := a.selectedSessionID
+	return func() tea.Msg {
+		files, err := a.app.History.ListLatestSessionFiles(context.Background(), sessionID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		paths := make([]string, 0, len(files))
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		return dialogs.OpenDialogMsg{
+			Model: diagnostics.NewDiagnosticsDialogCmp(diagnostics.Collect(a.app.LSPClients, paths), false),
+		}
+	}
+}
+
 // moveToPage handles navigation between different pages in the application.
 func (a *appModel) moveToPage(pageID page.PageID) tea.Cmd {
 	if a.app.AgentCoordinator.IsBusy() {
@@ -590,7 +999,10 @@ func (a *appModel) View() tea.View {
 	var view tea.View
 	t := styles.CurrentTheme()
 	view.AltScreen = true
-	view.MouseMode = tea.MouseModeCellMotion
+	view.ReportFocus = true
+	if !config.Get().Options.TUI.DisableMouse && termcap.Get().Mouse {
+		view.MouseMode = tea.MouseModeCellMotion
+	}
 	view.BackgroundColor = t.BgBase
 	if a.wWidth < 25 || a.wHeight < 15 {
 		view.Content = t.S().Base.Width(a.wWidth).Height(a.wHeight).
@@ -654,9 +1066,17 @@ func (a *appModel) View() tea.View {
 
 	comp := lipgloss.NewCompositor(layers...)
 	view.Content = comp.Render()
+	if a11y.Enabled() {
+		view.Content = a11y.Linearize(view.Content)
+	}
 	view.Cursor = cursor
 
-	if a.sendProgressBar && a.app != nil && a.app.AgentCoordinator != nil && a.app.AgentCoordinator.IsBusy() {
+	busy := a.app != nil && a.app.AgentCoordinator != nil && a.app.AgentCoordinator.IsBusy()
+	opts := config.Get().Options.TUI
+	if !opts.DisableTerminalTitle {
+		view.WindowTitle = a.windowTitle(busy)
+	}
+	if busy && a.reportProgress(opts.TerminalProgress) {
 		// HACK: use a random percentage to prevent ghostty from hiding it
 		// after a timeout.
 		view.ProgressBar = tea.NewProgressBar(tea.ProgressBarIndeterminate, rand.Intn(100))
@@ -664,6 +1084,112 @@ func (a *appModel) View() tea.View {
 	return view
 }
 
+// renameSession replaces the title of a session, which updates the terminal
+// title and the sidebar once it's saved.
+func (a *appModel) renameSession(msg commands.RenameSessionMsg) tea.Cmd {
+	title := strings.TrimSpace(msg.Title)
+	if title == "" {
+		return util.ReportWarn("The title of a session can't be empty")
+	}
+	return func() tea.Msg {
+		sess, err := a.app.Sessions.Get(context.Background(), msg.SessionID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		sess.Title = title
+		if _, err := a.app.Sessions.Save(context.Background(), sess); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Renamed the session to " + title}
+	}
+}
+
+// tagSession edits the tags of a session.
+func (a *appModel) tagSession(msg commands.TagSessionMsg) tea.Cmd {
+	return func() tea.Msg {
+		sess, err := a.app.Sessions.Get(context.Background(), msg.SessionID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		sess, err = a.app.Sessions.SetTags(context.Background(), sess.ID, session.EditTags(sess.Tags, msg.Edits))
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		if len(sess.Tags) == 0 {
+			return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "The session has no tags"}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Tagged the session #" + strings.Join(sess.Tags, " #")}
+	}
+}
+
+// shareSession writes a session, its secrets masked, to the shared
+// sessions of the repository.
+func (a *appModel) shareSession(msg commands.ShareSessionMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		sess, err := a.app.Sessions.Get(ctx, msg.SessionID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		msgs, err := a.app.Messages.List(ctx, sess.ID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		cfg := a.app.Config()
+		redactor, err := redact.New(cfg)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		path, err := sessionshare.Write(cfg.WorkingDir(), sessionshare.New(ctx, cfg.WorkingDir(), sess, msgs), redactor)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		if rel, err := filepath.Rel(cfg.WorkingDir(), path); err == nil {
+			path = rel
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Shared the session in " + path}
+	}
+}
+
+// windowTitle names the terminal tab after the session, marking it while
+// the agent works.
+func (a *appModel) windowTitle(busy bool) string {
+	title := "Crush"
+	if a.sessionTitle != "" {
+		title = a.sessionTitle + " · Crush"
+	}
+	if busy {
+		title = "● " + title
+	}
+	return title
+}
+
+// reportProgress reports whether progress is sent to the terminal, which
+// is done in the terminals known to support OSC 9;4 unless configured
+// otherwise.
+func (a *appModel) reportProgress(setting string) bool {
+	switch setting {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return a.sendProgressBar
+	}
+}
+
+// syncOutput has frames drawn at once with synchronized output (mode 2026),
+// so fast redraws don't tear. Bubble Tea turns it on when the terminal says
+// it supports it, but only asks outside SSH; asking again is harmless.
+// "always" turns it on without asking, for terminals that support it without
+// answering.
+func syncOutput(setting string) tea.Cmd {
+	if setting == "always" {
+		return util.CmdHandler(tea.ModeReportMsg{Mode: ansi.ModeSynchronizedOutput, Value: ansi.ModeReset})
+	}
+	return tea.Raw(ansi.RequestModeSynchronizedOutput)
+}
+
 func (a *appModel) handleStateChanged(ctx context.Context) tea.Cmd {
 	return func() tea.Msg {
 		a.app.UpdateAgentModel(ctx)
@@ -685,6 +1211,63 @@ func handleMCPToolsEvent(ctx context.Context, name string) tea.Cmd {
 	}
 }
 
+// failoverStatus tells which model a failed request is retried with, and
+// when, for as long as it waits.
+func failoverStatus(e agent.FailoverEvent) util.InfoMsg {
+	status := fmt.Sprintf("Retrying via %s (%s): %s", e.Model, e.Provider, e.Reason)
+	if e.Delay > 0 {
+		status = fmt.Sprintf("Retrying via %s in %s (attempt %d): %s", e.Model, e.Delay.Round(time.Second), e.Attempt, e.Reason)
+	}
+	return util.InfoMsg{
+		Type: util.InfoTypeWarn,
+		Msg:  status,
+		TTL:  e.Delay + 5*time.Second,
+	}
+}
+
+// redactionStatus warns that secrets were masked before being sent.
+func redactionStatus(e redact.Event) util.InfoMsg {
+	secrets := "a secret"
+	if e.Count > 1 {
+		secrets = fmt.Sprintf("%d secrets", e.Count)
+	}
+	return util.InfoMsg{
+		Type: util.InfoTypeWarn,
+		Msg:  fmt.Sprintf("Masked %s (%s) in what is sent to the provider", secrets, strings.Join(e.Kinds, ", ")),
+	}
+}
+
+// notify sends the desktop notification when the configuration wants it
+// and the terminal is not focused.
+func (a *appModel) notify(msg util.NotifyMsg) tea.Cmd {
+	cfg := config.Get().Options.TUI.Notifications
+	if !cfg.Wants(msg.Event) || (!a.unfocused && !cfg.Always) {
+		return nil
+	}
+	method := notify.Resolve(notify.Method(cfg.Method))
+	cmd := notify.Command(method, msg.Title, msg.Body)
+	if cmd == nil {
+		return tea.Raw(notify.Sequence(msg.Title, msg.Body))
+	}
+	return func() tea.Msg {
+		if err := cmd.Run(); err != nil {
+			slog.Warn("Failed to send notification", "method", method, "error", err)
+		}
+		return nil
+	}
+}
+
+// setTheme switches to the configured theme, keeping the current one when
+// it's unknown.
+func setTheme(name string) {
+	if name == "" || name == styles.CurrentTheme().Name {
+		return
+	}
+	if err := styles.DefaultManager().SetTheme(name); err != nil {
+		slog.Warn("Failed to set the theme", "theme", name, "error", err)
+	}
+}
+
 // New creates and initializes a new TUI application model.
 func New(app *app.App) *appModel {
 	chatPage := chat.New(app)
@@ -705,6 +1288,18 @@ func New(app *app.App) *appModel {
 		dialog:      dialogs.NewDialogCmp(),
 		completions: completions.New(),
 	}
+	opts := app.Config().Options.TUI
+	termcap.Set(termcap.Detect(os.Environ()))
+	setTheme(opts.Theme)
+	a11y.SetEnabled(opts.Accessibility.Enabled)
+	anim.SetReducedMotion(opts.ReducedMotion || opts.Accessibility.Enabled)
+	if opts.Accessibility.Enabled {
+		l, err := a11y.Open(opts.Accessibility.Log)
+		if err != nil {
+			slog.Warn("Failed to open the accessibility log", "path", opts.Accessibility.Log, "error", err)
+		}
+		model.announcements = l
+	}
 
 	return model
 }
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"fmt"
+	"log/slog"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/clipboard"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/termcap"
+)
+
+// CopyToClipboard copies text to the clipboard with the configured method,
+// falling back to OSC 52, and reports what was copied. When the terminal
+// isn't known to handle OSC 52, it says how to copy otherwise.
+func CopyToClipboard(text, what string) tea.Cmd {
+	method := clipboard.Auto
+	if cfg := config.Get(); cfg != nil && cfg.Options != nil && cfg.Options.TUI != nil && cfg.Options.TUI.Clipboard != "" {
+		method = clipboard.Method(cfg.Options.TUI.Clipboard)
+	}
+	return func() tea.Msg {
+		used, err := clipboard.Write(method, text)
+		if err != nil {
+			slog.Warn("Failed to write to the clipboard", "method", method, "error", err)
+			return InfoMsg{Type: InfoTypeError, Msg: fmt.Sprintf("Failed to copy with %s: %v", method, err)}
+		}
+		info := ReportInfo(what + " copied to clipboard")
+		if used == clipboard.OSC52 {
+			if !termcap.Get().OSC52 {
+				info = ReportWarn(what + " sent to the terminal, set options.tui.clipboard if it wasn't copied")
+			}
+			return tea.BatchMsg{tea.SetClipboard(text), info}
+		}
+		return info()
+	}
+}
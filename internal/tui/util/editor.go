@@ -0,0 +1,69 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+// Editor returns the command editing files: the configured one, then
+// $VISUAL, then $EDITOR, then the usual one of the platform.
+func Editor() string {
+	if cfg := config.Get(); cfg != nil && cfg.Options != nil && cfg.Options.TUI != nil && cfg.Options.TUI.Editor != "" {
+		return cfg.Options.TUI.Editor
+	}
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(env); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "nvim"
+}
+
+// OpenInEditor opens the file at the given 1-based line in the editor.
+func OpenInEditor(path string, line int) tea.Cmd {
+	return ExecShell(context.TODO(), config.ExternalToolEditor, editorCommand(Editor(), path, line), func(err error) tea.Msg {
+		if err != nil {
+			return InfoMsg{Type: InfoTypeError, Msg: err.Error()}
+		}
+		return nil
+	})
+}
+
+// editorCommand builds the command opening path at line, using the syntax
+// the editor understands.
+func editorCommand(editor, path string, line int) string {
+	quoted := shellQuote(path)
+	fields := strings.Fields(editor)
+	if len(fields) == 0 || line <= 0 {
+		return editor + " " + quoted
+	}
+	switch strings.TrimSuffix(filepath.Base(fields[0]), ".exe") {
+	case "code", "code-insiders", "codium", "cursor", "windsurf":
+		return fmt.Sprintf("%s --goto %s", editor, shellQuote(fmt.Sprintf("%s:%d", path, line)))
+	case "zed", "subl", "hx", "helix":
+		return fmt.Sprintf("%s %s", editor, shellQuote(fmt.Sprintf("%s:%d", path, line)))
+	case "notepad":
+		return editor + " " + quoted
+	default:
+		// vi, vim, nvim, nano, emacs, micro, kak and most other terminal
+		// editors accept +line.
+		return fmt.Sprintf("%s +%d %s", editor, line, quoted)
+	}
+}
+
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditorCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		editor string
+		path   string
+		line   int
+		want   string
+	}{
+		{"nvim", "/src/main.go", 12, "nvim +12 /src/main.go"},
+		{"emacs -nw", "/src/my file.go", 3, "emacs -nw +3 '/src/my file.go'"},
+		{"code --wait", "/src/main.go", 7, "code --wait --goto /src/main.go:7"},
+		{"/usr/local/bin/hx", "/src/main.go", 1, "/usr/local/bin/hx /src/main.go:1"},
+		{"vim", "/src/it's.go", 2, `vim +2 '/src/it'\''s.go'`},
+		{"vim", "/src/main.go", 0, "vim /src/main.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, editorCommand(tt.editor, tt.path, tt.line))
+		})
+	}
+}
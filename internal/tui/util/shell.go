@@ -2,14 +2,50 @@ package util
 
 import (
 	"context"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/multiplexer"
+	"github.com/charmbracelet/crush/internal/remote"
 	"github.com/charmbracelet/crush/internal/uiutil"
 )
 
 // ExecShell parses a shell command string and executes it with exec.Command.
 // Uses shell.Fields for proper handling of shell syntax like quotes and
-// arguments while preserving TTY handling for terminal editors.
-func ExecShell(ctx context.Context, cmdStr string, callback tea.ExecCallback) tea.Cmd {
-	return uiutil.ExecShell(ctx, cmdStr, callback)
+// arguments while preserving TTY handling for terminal editors. The command
+// gets the environment configured for tool, one of the config.ExternalTool
+// names.
+//
+// When external panes are configured and Crush runs in tmux or zellij, the
+// command runs in a new pane instead, and the callback gets called once it
+// exits.
+func ExecShell(ctx context.Context, tool, cmdStr string, callback tea.ExecCallback) tea.Cmd {
+	cfg := config.Get()
+	if cfg == nil || cfg.Options == nil || cfg.Options.TUI == nil {
+		return uiutil.ExecShell(ctx, cmdStr, nil, callback)
+	}
+	env := cfg.Options.TUI.ToolEnvFor(tool)
+	panes := cfg.Options.TUI.ExternalPanes
+	kind := multiplexer.Detect(panes.Multiplexer)
+	if kind == multiplexer.None {
+		return uiutil.ExecShell(ctx, cmdStr, env, callback)
+	}
+	dir := cfg.WorkingDir()
+	return func() tea.Msg {
+		// Only the exit status comes back from the pane, not the errors.
+		start := time.Now()
+		err := multiplexer.Run(ctx, kind, panes.Window, cmdStr, dir, env)
+		return callback(uiutil.NewExecError(cmdStr, err, "", time.Since(start)))
+	}
+}
+
+// ExecRemoteShell is ExecShell for commands working on the files of the
+// workspace: in a remote workspace, they run on its host, in its directory.
+// The environment of the tool is set for the local ssh then.
+func ExecRemoteShell(ctx context.Context, tool, cmdStr string, callback tea.ExecCallback) tea.Cmd {
+	if h := remote.Current(); h != nil {
+		cmdStr = h.ShellCommand(cmdStr)
+	}
+	return ExecShell(ctx, tool, cmdStr, callback)
 }
@@ -42,4 +42,13 @@ func ReportWarn(warn string) tea.Cmd {
 type (
 	InfoMsg        = uiutil.InfoMsg
 	ClearStatusMsg = uiutil.ClearStatusMsg
+	ExecError      = uiutil.ExecError
 )
+
+// NotifyMsg asks for a desktop notification of the given event, sent only
+// when the configuration wants it.
+type NotifyMsg struct {
+	Event string
+	Title string
+	Body  string
+}
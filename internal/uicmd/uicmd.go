@@ -247,6 +247,51 @@ func extractArgNames(content string) []string {
 	return args
 }
 
+// SaveUserCommand saves content as a user command named after its first
+// words, returning the ID of the command.
+func SaveUserCommand(content string) (string, error) {
+	dir := getXDGCommandsDir()
+	if dir == "" {
+		return "", fmt.Errorf("no config directory to save the command in")
+	}
+	if err := ensureDir(dir); err != nil {
+		return "", err
+	}
+	name := commandName(content)
+	path := filepath.Join(dir, name+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", name, i))
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", err
+	}
+	return buildCommandID(path, dir, userCommandPrefix), nil
+}
+
+// commandName returns a file name made of the first words of content.
+func commandName(content string) string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(content)) {
+		word = strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, word)
+		if word == "" {
+			continue
+		}
+		words = append(words, word)
+		if len(words) == 4 {
+			break
+		}
+	}
+	return cmp.Or(strings.Join(words, "-"), "template")
+}
+
 func ensureDir(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return os.MkdirAll(path, 0o755)
@@ -0,0 +1,79 @@
+package uiutil
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// earlyExit is how soon a command has to fail to be taken as one that
+	// didn't start: a missing binary, a bad config or the wrong directory.
+	earlyExit = 3 * time.Second
+	// maxStderr bounds what's kept of the errors of a command, its end.
+	maxStderr = 16 * 1024
+)
+
+// ExecError is the error of a command run with ExecShell that failed right
+// after starting, with what it wrote to stderr, which is gone from the
+// screen once Crush takes it back.
+type ExecError struct {
+	Command string
+	Stderr  string
+	Err     error
+}
+
+func (e *ExecError) Error() string {
+	if e.Stderr == "" {
+		return e.Err.Error()
+	}
+	lines := strings.Split(e.Stderr, "\n")
+	return e.Err.Error() + ": " + lines[len(lines)-1]
+}
+
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of the command, -1 when it couldn't be
+// started at all.
+func (e *ExecError) ExitCode() int {
+	var exitErr *exec.ExitError
+	if errors.As(e.Err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// NewExecError returns err as an ExecError when the command failed within
+// the first seconds, and as is otherwise.
+func NewExecError(command string, err error, stderr string, ran time.Duration) error {
+	if err == nil || ran > earlyExit {
+		return err
+	}
+	return &ExecError{Command: command, Stderr: strings.TrimSpace(stderr), Err: err}
+}
+
+// tailWriter keeps the end of what's written to it.
+type tailWriter struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > maxStderr {
+		w.buf = w.buf[len(w.buf)-maxStderr:]
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.buf)
+}
@@ -6,7 +6,9 @@ package uiutil
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
+	"os"
 	"os/exec"
 	"time"
 
@@ -65,10 +67,13 @@ type (
 	ClearStatusMsg struct{}
 )
 
-// ExecShell parses a shell command string and executes it with exec.Command.
+// ExecShell parses a shell command string and executes it with exec.Command,
+// with env added to the environment.
 // Uses shell.Fields for proper handling of shell syntax like quotes and
-// arguments while preserving TTY handling for terminal editors.
-func ExecShell(ctx context.Context, cmdStr string, callback tea.ExecCallback) tea.Cmd {
+// arguments while preserving TTY handling for terminal editors. When the
+// command fails right away, the callback gets an *ExecError with its
+// errors.
+func ExecShell(ctx context.Context, cmdStr string, env []string, callback tea.ExecCallback) tea.Cmd {
 	fields, err := shell.Fields(cmdStr, nil)
 	if err != nil {
 		return ReportError(err)
@@ -78,5 +83,18 @@ func ExecShell(ctx context.Context, cmdStr string, callback tea.ExecCallback) te
 	}
 
 	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
-	return tea.ExecProcess(cmd, callback)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	// The errors still show in the terminal while the command runs.
+	var stderr tailWriter
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	var start time.Time
+	run := tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return callback(NewExecError(cmdStr, err, stderr.String(), time.Since(start)))
+	})
+	return func() tea.Msg {
+		start = time.Now()
+		return run()
+	}
 }
@@ -0,0 +1,242 @@
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// checksumsName is the asset holding the SHA-256 sums of the archives.
+const checksumsName = "checksums.txt"
+
+// maxArchive bounds the size of the archives downloaded.
+const maxArchive = 200 << 20
+
+// ManagedInstall returns the package manager exe was installed with, empty
+// when it wasn't, in which case it can replace itself.
+func ManagedInstall(exe string) string {
+	exe = filepath.ToSlash(exe)
+	switch {
+	case strings.Contains(exe, "/Cellar/") || strings.Contains(exe, "/homebrew/"):
+		return "Homebrew"
+	case strings.HasPrefix(exe, "/nix/store/"):
+		return "Nix"
+	case strings.Contains(exe, "/scoop/"):
+		return "Scoop"
+	case strings.Contains(exe, "/node_modules/"):
+		return "npm"
+	case strings.HasPrefix(exe, "/usr/bin/"):
+		return "the system package manager"
+	}
+	return ""
+}
+
+// Install downloads the archive of the latest release for this platform,
+// checks it against the checksums of the release and replaces exe with the
+// binary it holds.
+func Install(ctx context.Context, info Info, exe string) error {
+	name := archiveName(info.Latest, runtime.GOOS, runtime.GOARCH, goarm())
+	archiveURL, checksumsURL := "", ""
+	for _, asset := range info.Assets {
+		switch asset.Name {
+		case name:
+			archiveURL = asset.URL
+		case checksumsName:
+			checksumsURL = asset.URL
+		}
+	}
+	if archiveURL == "" {
+		return fmt.Errorf("no release of v%s for %s/%s", info.Latest, runtime.GOOS, runtime.GOARCH)
+	}
+	if checksumsURL == "" {
+		return fmt.Errorf("the release of v%s has no checksums", info.Latest)
+	}
+
+	checksums, err := download(ctx, checksumsURL)
+	if err != nil {
+		return fmt.Errorf("failed to download the checksums: %w", err)
+	}
+	archive, err := download(ctx, archiveURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	if err := verify(archive, checksums, name); err != nil {
+		return err
+	}
+	binary, err := extract(archive, name)
+	if err != nil {
+		return err
+	}
+	return replace(exe, binary)
+}
+
+// archiveName names the archive of a platform as the releases do.
+func archiveName(version, goos, goarch, goarm string) string {
+	arch := goarch
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "386":
+		arch = "i386"
+	case "arm":
+		arch = "armv" + goarm
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("crush_%s_%s_%s%s", version, strings.ToUpper(goos[:1])+goos[1:], arch, ext)
+}
+
+// goarm returns the ARM version this binary was built for.
+func goarm() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "GOARM" {
+				return strings.TrimSuffix(setting.Value, ",softfloat")
+			}
+		}
+	}
+	return "7"
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	client := &http.Client{
+		Timeout: 5 * time.Minute,
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArchive+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArchive {
+		return nil, errors.New("file too large")
+	}
+	return data, nil
+}
+
+// verify checks archive against its sum in checksums, formatted as
+// sha256sum prints them.
+func verify(archive, checksums []byte, name string) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		sum, file, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok || file != name {
+			continue
+		}
+		got := sha256.Sum256(archive)
+		if hex.EncodeToString(got[:]) != sum {
+			return fmt.Errorf("the checksum of %s doesn't match, not installing it", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum for %s", name)
+}
+
+// extract returns the crush binary of an archive.
+func extract(archive []byte, name string) ([]byte, error) {
+	isBinary := func(file string) bool {
+		base := path.Base(file)
+		return base == "crush" || base == "crush.exe"
+	}
+
+	if strings.HasSuffix(name, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if !isBinary(f.Name) {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, maxArchive))
+		}
+		return nil, fmt.Errorf("no crush binary in %s", name)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no crush binary in %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && isBinary(hdr.Name) {
+			return io.ReadAll(io.LimitReader(tr, maxArchive))
+		}
+	}
+}
+
+// replace swaps exe for binary. The new binary is written next to exe and
+// renamed over it, so exe is never left half written. Windows doesn't let a
+// running executable be replaced, but it lets it be renamed out of the way.
+func replace(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".crush-update-*")
+	if err != nil {
+		return fmt.Errorf("can't write next to %s: %w", exe, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), exe)
+}
@@ -0,0 +1,87 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "crush_0.12.0_Linux_x86_64.tar.gz", archiveName("0.12.0", "linux", "amd64", ""))
+	require.Equal(t, "crush_0.12.0_Darwin_arm64.tar.gz", archiveName("0.12.0", "darwin", "arm64", ""))
+	require.Equal(t, "crush_0.12.0_Linux_armv6.tar.gz", archiveName("0.12.0", "linux", "arm", "6"))
+	require.Equal(t, "crush_0.12.0_Windows_i386.zip", archiveName("0.12.0", "windows", "386", ""))
+}
+
+func TestInstall(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the archives of windows are zips")
+	}
+	name := archiveName("0.12.0", runtime.GOOS, runtime.GOARCH, goarm())
+	archive := tarGz(t, "crush_0.12.0/crush", "new")
+	sum := sha256.Sum256(archive)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+name, func(w http.ResponseWriter, _ *http.Request) { w.Write(archive) })
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(hex.EncodeToString(sum[:]) + "  " + name + "\n"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	info := Info{Latest: "0.12.0", Assets: []Asset{
+		{Name: name, URL: srv.URL + "/" + name},
+		{Name: "checksums.txt", URL: srv.URL + "/checksums.txt"},
+	}}
+	exe := filepath.Join(t.TempDir(), "crush")
+	require.NoError(t, os.WriteFile(exe, []byte("old"), 0o755))
+
+	require.NoError(t, Install(t.Context(), info, exe))
+	got, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	require.Equal(t, "new", string(got))
+
+	t.Run("refuses a corrupted archive", func(t *testing.T) {
+		archive = tarGz(t, "crush_0.12.0/crush", "tampered")
+		require.ErrorContains(t, Install(t.Context(), info, exe), "checksum")
+		got, err := os.ReadFile(exe)
+		require.NoError(t, err)
+		require.Equal(t, "new", string(got))
+	})
+}
+
+func TestManagedInstall(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Homebrew", ManagedInstall("/opt/homebrew/Cellar/crush/0.11.0/bin/crush"))
+	require.Equal(t, "Nix", ManagedInstall("/nix/store/abc-crush/bin/crush"))
+	require.Empty(t, ManagedInstall("/home/carlos/.local/bin/crush"))
+}
+
+func tarGz(t *testing.T, name, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
@@ -24,6 +24,9 @@ type Info struct {
 	Current string
 	Latest  string
 	URL     string
+	// Changelog is the Markdown description of the latest release.
+	Changelog string
+	Assets    []Asset
 }
 
 // Matches a version string like:
@@ -69,13 +72,23 @@ func Check(ctx context.Context, current string, client Client) (Info, error) {
 	info.Latest = strings.TrimPrefix(release.TagName, "v")
 	info.Current = strings.TrimPrefix(info.Current, "v")
 	info.URL = release.HTMLURL
+	info.Changelog = release.Body
+	info.Assets = release.Assets
 	return info, nil
 }
 
 // Release represents a GitHub release.
 type Release struct {
-	TagName string `json:"tag_name"`
-	HTMLURL string `json:"html_url"`
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Body    string  `json:"body"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
 }
 
 // Client is a client that can get the latest release.